@@ -0,0 +1,167 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// A baseline is a set of known errors that are not reported. It allows
+// strict schemas to be adopted for existing configurations incrementally.
+type baseline struct {
+	file   string
+	update bool
+
+	known map[baselineEntry]bool
+	seen  map[baselineEntry]bool
+}
+
+// A baselineEntry identifies an error in a baseline file.
+type baselineEntry struct {
+	// Path is the path of the value at which the error occurred.
+	Path string `json:"path"`
+
+	// Code identifies the kind of error. It is the message of the error
+	// without its arguments, so that an entry remains valid if the
+	// offending values change.
+	Code string `json:"code"`
+}
+
+// withBaseline returns a runFunction that runs f with the errors recorded in
+// the file given by the --baseline flag suppressed. With --update-baseline,
+// all errors are suppressed and written to this file instead.
+func withBaseline(f runFunction) runFunction {
+	return func(cmd *Command, args []string) error {
+		b, err := openBaseline(cmd)
+		if err != nil {
+			return err
+		}
+		cmd.baseline = b
+
+		if err := f(cmd, args); err != nil {
+			exitOnErr(cmd, err, false)
+		}
+		if b == nil || cmd.hasErr {
+			return nil
+		}
+		return b.close(cmd)
+	}
+}
+
+// openBaseline reads the baseline file given by the --baseline flag. It
+// returns nil if the flag is not set.
+func openBaseline(cmd *Command) (*baseline, error) {
+	file := flagBaseline.String(cmd)
+	update := flagUpdateBaseline.Bool(cmd)
+	if file == "" {
+		if update {
+			return nil, errors.Newf(token.NoPos,
+				"--%s requires --%s", flagUpdateBaseline, flagBaseline)
+		}
+		return nil, nil
+	}
+
+	b := &baseline{
+		file:   file,
+		update: update,
+		known:  map[baselineEntry]bool{},
+		seen:   map[baselineEntry]bool{},
+	}
+
+	data, err := ioutil.ReadFile(file)
+	switch {
+	case os.IsNotExist(err) && update:
+		return b, nil
+	case err != nil:
+		return nil, err
+	}
+
+	var entries []baselineEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, errors.Wrapf(err, token.NoPos, "invalid baseline file %s", file)
+	}
+	for _, e := range entries {
+		b.known[e] = true
+	}
+	return b, nil
+}
+
+// filter returns err without the errors that are recorded in the baseline,
+// or nil if there are no other errors. Errors that are not associated with
+// a path, such as parse errors, are never suppressed.
+func (b *baseline) filter(err error) error {
+	var errs errors.Error
+	for _, e := range errors.Errors(err) {
+		format, _ := e.Msg()
+		key := baselineEntry{
+			Path: strings.Join(e.Path(), "."),
+			Code: format,
+		}
+		if key.Path != "" && (b.update || b.known[key]) {
+			b.seen[key] = true
+			continue
+		}
+		errs = errors.Append(errs, e)
+	}
+	if errs == nil {
+		return nil
+	}
+	return errs
+}
+
+// close writes the errors that occurred to the baseline file if it is to be
+// updated. Otherwise, it reports the recorded errors that no longer occur.
+func (b *baseline) close(cmd *Command) error {
+	if !b.update {
+		n := 0
+		for key := range b.known {
+			if !b.seen[key] {
+				n++
+			}
+		}
+		if n > 0 {
+			fmt.Fprintf(cmd.OutOrStderr(),
+				"%d of the errors recorded in %s no longer occur; use --%s to remove them\n",
+				n, b.file, flagUpdateBaseline)
+		}
+		return nil
+	}
+
+	entries := []baselineEntry{}
+	for key := range b.seen {
+		entries = append(entries, key)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Code < b.Code
+	})
+
+	data, err := json.MarshalIndent(entries, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(b.file, append(data, '\n'), 0666)
+}
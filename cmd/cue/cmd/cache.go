@@ -0,0 +1,215 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+
+	"github.com/spf13/pflag"
+
+	"cuelang.org/go/cue/build"
+)
+
+// This file implements a cache of command results that persists across
+// invocations of the cue tool.
+//
+// A cache entry holds the output of a successful command. It is keyed by a
+// hash of the version of the cue tool, the command line, the working
+// directory, and the contents of all files read by the command, including
+// those of imported packages. Commands whose results may depend on other
+// inputs, such as standard input, injected system variables, or files
+// referenced by flags, are not cached.
+
+// cacheEnv is the environment variable that enables the cache.
+const cacheEnv = "CUE_CACHE"
+
+// cacheDir reports the directory in which cache entries are stored or "" if
+// the cache is disabled.
+func cacheDir() string {
+	switch s := os.Getenv(cacheEnv); s {
+	case "", "off", "0":
+		return ""
+	case "on", "1":
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return ""
+		}
+		return filepath.Join(dir, "cue", "results")
+	default:
+		if !filepath.IsAbs(s) {
+			return ""
+		}
+		return s
+	}
+}
+
+// uncachedFlags lists the flags that refer to inputs that are not hashed
+// into the cache key.
+var uncachedFlags = []flagName{
+	flagProtoPath,
+	flagProtoModule,
+	flagSecret,
+}
+
+// A resultCache is the cache entry for a single command invocation. A nil
+// resultCache is valid and never holds a result.
+type resultCache struct {
+	file string
+}
+
+// openCache returns the cache entry for running cmd with args as planned by
+// b or nil if the cache is disabled or the result of cmd cannot be cached.
+func openCache(cmd *Command, args []string, b *buildPlan) *resultCache {
+	dir := cacheDir()
+	if dir == "" || flagInjectVars.Bool(cmd) || flagUpdateBaseline.Bool(cmd) {
+		return nil
+	}
+	// The files found through these flags are not part of the build plan,
+	// so changes to them would go unnoticed.
+	for _, f := range uncachedFlags {
+		if cmd.Flags().Changed(string(f)) {
+			return nil
+		}
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "cue %s\n", toolID())
+	fmt.Fprintf(h, "cmd %s\n", cmd.CommandPath())
+	for _, a := range args {
+		fmt.Fprintf(h, "arg %q\n", a)
+	}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		fmt.Fprintf(h, "flag %s=%s\n", f.Name, f.Value)
+	})
+	cwd, _ := os.Getwd()
+	fmt.Fprintf(h, "dir %s\n", cwd)
+
+	if f := flagBaseline.String(cmd); f != "" && !hashFile(h, f) {
+		return nil
+	}
+
+	for _, f := range b.sources {
+		if f.Filename == "-" || f.Source != nil {
+			return nil
+		}
+		if !hashFile(h, f.Filename) {
+			return nil
+		}
+	}
+
+	key := hex.EncodeToString(h.Sum(nil))
+	return &resultCache{file: filepath.Join(dir, key[:2], key)}
+}
+
+// hashFile adds the name and contents of the given file to h. It reports
+// false if the file cannot be read.
+func hashFile(h hash.Hash, filename string) bool {
+	b, err := ioutil.ReadFile(filename)
+	switch {
+	case os.IsNotExist(err):
+		fmt.Fprintf(h, "file %s missing\n", filename)
+		return true
+	case err != nil:
+		return false
+	}
+	fmt.Fprintf(h, "file %s %d\n", filename, len(b))
+	_, _ = h.Write(b)
+	return true
+}
+
+// toolID identifies the build of the cue tool. Development builds are
+// identified by their executable, as they do not have a distinguishing
+// version.
+func toolID() string {
+	v := version
+	if bi, ok := debug.ReadBuildInfo(); ok && v == defaultVersion {
+		v = bi.Main.Version + " " + bi.Main.Sum
+	}
+	if exe, err := os.Executable(); err == nil {
+		if fi, err := os.Stat(exe); err == nil {
+			v += fmt.Sprintf(" %s %d %d", exe, fi.Size(), fi.ModTime().UnixNano())
+		}
+	}
+	return v
+}
+
+// get reports the cached result, if any.
+func (c *resultCache) get() ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	b, err := ioutil.ReadFile(c.file)
+	return b, err == nil
+}
+
+// put records result. Failures to write the cache are ignored.
+func (c *resultCache) put(result []byte) {
+	if c == nil {
+		return
+	}
+	dir := filepath.Dir(c.file)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return
+	}
+	// Write to a temporary file first so that concurrent invocations never
+	// observe a partially written entry.
+	f, err := ioutil.TempFile(dir, "tmp-")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(result)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.file)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
+
+// sourceFiles reports all files of the given instances and the packages
+// they import.
+func sourceFiles(insts []*build.Instance) []*build.File {
+	var files []*build.File
+	seen := map[*build.Instance]bool{}
+	var add func(insts []*build.Instance)
+	add = func(insts []*build.Instance) {
+		for _, inst := range insts {
+			if seen[inst] {
+				continue
+			}
+			seen[inst] = true
+			files = append(files, inst.BuildFiles...)
+			files = append(files, inst.OrphanedFiles...)
+			if inst.Root != "" {
+				files = append(files, &build.File{
+					Filename: filepath.Join(inst.Root, "cue.mod", "module.cue"),
+				})
+			}
+			add(inst.Imports)
+		}
+	}
+	add(insts)
+	return files
+}
@@ -49,6 +49,11 @@ rewrites the instance, filling in the completed task, and
 reevaluates which other tasks can now start, and so on until all
 tasks have completed.
 
+Tasks of the tool/trigger package fire on external events, such as
+a timer expiring or a file changing. Each time a trigger fires, the
+tasks that depend on it are run again, so a command with triggers
+keeps running until it is interrupted.
+
 Available tasks can be found in the package documentation at
 
 	https://pkg.go.dev/cuelang.org/go/pkg/tool?tab=subdirectories
@@ -151,6 +156,8 @@ Ensure commands are defined in a "_tool.cue" file.
 	cmd.Flags().SetInterspersed(false)
 
 	addInjectionFlags(cmd.Flags(), true)
+	addSecretFlags(cmd.Flags())
+	addCacheFlags(cmd.Flags())
 
 	return cmd
 }
@@ -16,11 +16,14 @@ package cmd
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/spf13/pflag"
 	"golang.org/x/text/language"
@@ -29,13 +32,16 @@ import (
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/load"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/encoding"
 	"cuelang.org/go/internal/filetypes"
 	"cuelang.org/go/internal/value"
+	"cuelang.org/go/tools/secret"
 )
 
 // Disallow
@@ -81,10 +87,57 @@ func getLang() language.Tag {
 	return language.Make(loc)
 }
 
+// messagesEnv is the environment variable that names a file holding a catalog
+// of error messages, used to print errors in another language.
+const messagesEnv = "CUE_MESSAGES"
+
+var catalog struct {
+	once sync.Once
+	c    errors.Catalog
+}
+
+// messageCatalog returns the message catalog named by messagesEnv, if any.
+// It reports an error for an invalid catalog only once.
+func messageCatalog(w io.Writer) errors.Catalog {
+	catalog.once.Do(func() {
+		file := os.Getenv(messagesEnv)
+		if file == "" {
+			return
+		}
+		c, err := loadCatalog(file)
+		if err != nil {
+			fmt.Fprintf(w, "invalid %s: %v\n", messagesEnv, err)
+			return
+		}
+		catalog.c = c
+	})
+	return catalog.c
+}
+
+// loadCatalog reads a catalog from a CUE or JSON file holding a struct that
+// maps message keys to format strings.
+func loadCatalog(file string) (errors.Catalog, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	v := cuecontext.New().CompileBytes(b, cue.Filename(file))
+	var c errors.Catalog
+	if err := v.Decode(&c); err != nil {
+		return nil, errors.Wrapf(err, token.NoPos, "%s", file)
+	}
+	return c, nil
+}
+
 func exitOnErr(cmd *Command, err error, fatal bool) {
 	if err == nil {
 		return
 	}
+	if cmd.baseline != nil {
+		if err = cmd.baseline.filter(err); err == nil {
+			return
+		}
+	}
 
 	// Link x/text as our localizer.
 	p := message.NewPrinter(getLang())
@@ -99,16 +152,35 @@ func exitOnErr(cmd *Command, err error, fatal bool) {
 		Format:  format,
 		Cwd:     cwd,
 		ToSlash: inTest,
+		Catalog: messageCatalog(cmd.Stderr()),
 	})
 
 	b := w.Bytes()
+	if detectConsole(cmd.Command.OutOrStderr()).color {
+		b = colorErrors(b)
+	}
 	_, _ = cmd.Stderr().Write(b)
 	if fatal {
 		exit()
 	}
 }
 
+// remoteAllowEnv is the environment variable that lists the URL prefixes from
+// which remote files may be loaded.
+const remoteAllowEnv = "CUE_REMOTE_ALLOW"
+
 func loadFromArgs(cmd *Command, args []string, cfg *load.Config) []*build.Instance {
+	if cfg == nil {
+		cfg = &load.Config{}
+	}
+	if cfg.Remote == nil {
+		cfg.Remote = &load.RemoteConfig{}
+		for _, s := range strings.Split(os.Getenv(remoteAllowEnv), ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				cfg.Remote.Allow = append(cfg.Remote.Allow, s)
+			}
+		}
+	}
 	binst := load.Instances(args, cfg)
 	if len(binst) == 0 {
 		return nil
@@ -142,6 +214,10 @@ type buildPlan struct {
 	// flags.
 	imported []*ast.File
 
+	// sources holds all files loaded for the build, including those of
+	// imported packages.
+	sources []*build.File
+
 	expressions []ast.Expr // only evaluate these expressions within results
 	schema      ast.Expr   // selects schema in instance for orphaned values
 
@@ -155,6 +231,9 @@ type buildPlan struct {
 	outFile *build.File
 
 	encConfig *encoding.Config
+
+	// secrets holds the providers specified with the --secret flag, if any.
+	secrets *secret.Keyring
 }
 
 // instances iterates either over a list of instances, or a list of
@@ -292,13 +371,27 @@ func (i *streamingIterator) scan() bool {
 
 	// compose value
 	i.f = i.dec.File()
-	inst, err := i.r.CompileFile(i.f)
-	if err != nil {
-		i.e = err
-		return false
+	if i.b.secrets != nil {
+		if i.e = i.b.secrets.DecryptFile(i.f); i.e != nil {
+			return false
+		}
 	}
-	i.v = inst.Value()
-	if schema := i.b.encConfig.Schema; schema.Exists() {
+	if len(i.f.Imports) == 0 && i.f.PackageName() == "" {
+		// Build data values as expressions, as compiling them as instances
+		// would keep every value of the stream alive in the runtime.
+		i.v = value.ConvertToContext(i.r).BuildExpr(internal.ToExpr(i.f))
+		if i.e = i.v.Err(); i.e != nil {
+			return false
+		}
+	} else {
+		inst, err := i.r.CompileFile(i.f)
+		if err != nil {
+			i.e = err
+			return false
+		}
+		i.v = inst.Value()
+	}
+	if schema := i.b.encConfig.Schema; schema.Exists() && !i.b.cfg.noUnify {
 		i.e = schema.Err()
 		if i.e == nil {
 			i.v = i.v.Unify(schema) // TODO(required fields): don't merge in schema
@@ -377,6 +470,12 @@ type config struct {
 	overrideDefault bool
 
 	noMerge bool // do not merge individual data files.
+	noUnify bool // do not unify data files with the schema.
+
+	// metaSchema indicates that the --schema flag denotes a built-in
+	// meta-schema instead of a CUE expression. Data files are then not
+	// interpreted as OpenAPI or JSON Schema, as they are what is validated.
+	metaSchema bool
 
 	loadCfg *load.Config
 }
@@ -456,6 +555,9 @@ func (p *buildPlan) getDecoders(b *build.Instance) (schemas, values []*decoderIn
 			f.Encoding = p.cfg.encoding
 			f.Interpretation = p.cfg.interpretation
 		}
+		if p.cfg.metaSchema && f.Interpretation == build.Auto {
+			f.Interpretation = ""
+		}
 		switch f.Encoding {
 		case build.Protobuf, build.YAML, build.JSON, build.JSONL,
 			build.Text, build.Binary:
@@ -537,6 +639,7 @@ func parseArgs(cmd *Command, args []string, cfg *config) (p *buildPlan, err erro
 	if builds == nil {
 		return nil, errors.Newf(token.NoPos, "invalid args")
 	}
+	p.sources = sourceFiles(builds)
 
 	if err := p.parsePlacementFlags(); err != nil {
 		return nil, err
@@ -571,9 +674,18 @@ func parseArgs(cmd *Command, args []string, cfg *config) (p *buildPlan, err erro
 
 	if b := p.orphanInstance; b != nil {
 		schemas, values, err := p.getDecoders(b)
-		for _, d := range append(schemas, values...) {
-			defer d.close()
-		}
+		defer func() {
+			for _, d := range schemas {
+				d.close()
+			}
+			// Streamed values are decoded, and closed, by the streaming
+			// iterator. p is nil if an error occurred.
+			if p == nil || p.orphaned == nil {
+				for _, d := range values {
+					d.close()
+				}
+			}
+		}()
 		if err != nil {
 			return nil, err
 		}
@@ -643,7 +755,7 @@ func parseArgs(cmd *Command, args []string, cfg *config) (p *buildPlan, err erro
 		default:
 			fallthrough
 
-		case p.schema != nil:
+		case p.schema != nil && !p.importing:
 			p.orphaned = values
 
 		case p.mergeData, p.usePlacement(), p.importing:
@@ -692,7 +804,7 @@ func (b *buildPlan) parseFlags() (err error) {
 		}
 		b.expressions = append(b.expressions, expr)
 	}
-	if s := flagSchema.String(b.cmd); s != "" {
+	if s := flagSchema.String(b.cmd); s != "" && !b.cfg.metaSchema {
 		b.schema, err = parser.ParseExpr("--schema", s)
 		if err != nil {
 			return err
@@ -711,14 +823,68 @@ func (b *buildPlan) parseFlags() (err error) {
 		AllErrors: flagAllErrors.Bool(b.cmd),
 		PkgName:   flagPackage.String(b.cmd),
 		Strict:    flagStrict.Bool(b.cmd),
+		Annotate:  flagAnnotate.Bool(b.cmd),
+
+		EscapeHTML: flagEscape.Bool(b.cmd),
+	}
+	b.secrets, err = keyring(b.cmd.Flags())
+	return err
+}
+
+// keyring returns the providers specified with the --secret flag, or nil if
+// there are none.
+func keyring(f *pflag.FlagSet) (*secret.Keyring, error) {
+	specs, _ := f.GetStringArray(string(flagSecret))
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	return secret.NewKeyring(specs...)
+}
+
+// decryptFiles decrypts the secrets in the given instances with the
+// providers specified with the --secret flag.
+func decryptFiles(f *pflag.FlagSet, binst []*build.Instance) error {
+	k, err := keyring(f)
+	if k == nil {
+		return err
+	}
+	for _, b := range binst {
+		for _, f := range b.Files {
+			if err := k.DecryptFile(f); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
+// encrypt encrypts the fields of v that are marked with a secret attribute.
+// It reports an error if there are such fields, but no providers.
+func (b *buildPlan) encrypt(v cue.Value) (cue.Value, error) {
+	k := b.secrets
+	if k == nil {
+		k = &secret.Keyring{}
+	}
+	return k.Encrypt(v)
+}
+
+// relPos formats p with a file name relative to cwd, if possible.
+func relPos(cwd string, p token.Pos) string {
+	name := p.Filename()
+	if rel, err := filepath.Rel(cwd, name); err == nil && !strings.HasPrefix(rel, "..") {
+		name = rel
+	}
+	if inTest {
+		name = filepath.ToSlash(name)
+	}
+	return fmt.Sprintf("%s:%d:%d", name, p.Line(), p.Column())
+}
+
 func buildInstances(cmd *Command, binst []*build.Instance) []*cue.Instance {
 	// TODO:
 	// If there are no files and User is true, then use those?
 	// Always use all files in user mode?
+	exitOnErr(cmd, decryptFiles(cmd.Flags(), binst), true)
 	instances := cue.Build(binst)
 	for _, inst := range instances {
 		// TODO: consider merging errors of multiple files, but ensure
@@ -789,6 +955,10 @@ func buildTools(cmd *Command, args []string) (*cue.Instance, error) {
 		inst.Files = inst.Files[:k]
 	}
 
+	if err := decryptFiles(f, binst); err != nil {
+		return nil, err
+	}
+
 	insts, err := buildToolInstances(cmd, binst)
 	if err != nil {
 		return nil, err
@@ -825,3 +995,34 @@ func shortFile(root string, f *build.File) string {
 	}
 	return dir
 }
+
+// renameHidden returns the mapping given by the --rename-hidden flag.
+func renameHidden(cmd *Command) (map[string]string, error) {
+	a := flagRenameHidden.StringArray(cmd)
+	if len(a) == 0 {
+		return nil, nil
+	}
+	m := map[string]string{}
+	for _, s := range a {
+		p := strings.IndexByte(s, '=')
+		if p < 0 {
+			return nil, fmt.Errorf(
+				"invalid value %q for --rename-hidden: must be of the form _old=new", s)
+		}
+		from, to := s[:p], s[p+1:]
+		if !ast.IsValidIdent(from) || !internal.IsHidden(from) {
+			return nil, fmt.Errorf(
+				"invalid value %q for --rename-hidden: %q is not a hidden field", s, from)
+		}
+		if !ast.IsValidIdent(to) || internal.IsHidden(to) {
+			return nil, fmt.Errorf(
+				"invalid value %q for --rename-hidden: %q is not a regular identifier", s, to)
+		}
+		if internal.IsDef(from) != internal.IsDef(to) {
+			return nil, fmt.Errorf(
+				"invalid value %q for --rename-hidden: cannot rename between definition and field", s)
+		}
+		m[from] = to
+	}
+	return m, nil
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+)
+
+// A console describes the terminal, if any, to which output is written.
+type console struct {
+	// color reports whether the terminal supports ANSI color sequences and
+	// colors are not disabled by the user.
+	color bool
+
+	// width is the width of the terminal in columns, or 0 if unknown.
+	width int
+}
+
+// detectConsole reports the capabilities of the terminal to which w writes.
+// Colors are disabled if the NO_COLOR environment variable is set or TERM is
+// "dumb". The COLUMNS environment variable, if set, overrides the width of
+// the terminal.
+func detectConsole(w io.Writer) console {
+	var c console
+	f, ok := w.(*os.File)
+	if !ok || !isTerminal(f) {
+		return c
+	}
+	_, noColor := os.LookupEnv("NO_COLOR")
+	c.color = !noColor && os.Getenv("TERM") != "dumb" && enableColor(f)
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		c.width = n
+	} else {
+		c.width = termWidth(f)
+	}
+	return c
+}
+
+// isTerminal reports whether f is a terminal, or console on Windows.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+const (
+	colorRed   = "\x1b[31m"
+	colorReset = "\x1b[0m"
+)
+
+// colorErrors highlights the messages in the output of errors.Print, which
+// are followed by indented positions.
+func colorErrors(b []byte) []byte {
+	var buf bytes.Buffer
+	for _, line := range bytes.SplitAfter(b, []byte("\n")) {
+		if len(line) == 0 || bytes.HasPrefix(line, []byte("    ")) {
+			buf.Write(line)
+			continue
+		}
+		text := bytes.TrimRight(line, "\r\n")
+		buf.WriteString(colorRed)
+		buf.Write(text)
+		buf.WriteString(colorReset)
+		buf.Write(line[len(text):])
+	}
+	return buf.Bytes()
+}
+
+// truncate shortens s to at most n runes, marking truncation with an
+// ellipsis. It leaves s unchanged if n is not positive.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if n <= 0 || len(r) <= n {
+		return s
+	}
+	if n == 1 {
+		return "…"
+	}
+	return string(r[:n-1]) + "…"
+}
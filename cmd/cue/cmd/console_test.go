@@ -0,0 +1,46 @@
+// Copyright 2018 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import "testing"
+
+func TestColorErrors(t *testing.T) {
+	in := "a: conflicting values 1 and 2:\n    ./x.cue:1:4\n    ./x.cue:1:8\nb: incomplete\r\n"
+	want := colorRed + "a: conflicting values 1 and 2:" + colorReset + "\n" +
+		"    ./x.cue:1:4\n    ./x.cue:1:8\n" +
+		colorRed + "b: incomplete" + colorReset + "\r\n"
+	if got := string(colorErrors([]byte(in))); got != want {
+		t.Errorf("got %q; want %q", got, want)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	testCases := []struct {
+		in  string
+		n   int
+		out string
+	}{
+		{"description", 0, "description"},
+		{"description", 11, "description"},
+		{"description", 5, "desc…"},
+		{"ééééé", 3, "éé…"},
+		{"abc", 1, "…"},
+	}
+	for _, tc := range testCases {
+		if got := truncate(tc.in, tc.n); got != tc.out {
+			t.Errorf("truncate(%q, %d) = %q; want %q", tc.in, tc.n, got, tc.out)
+		}
+	}
+}
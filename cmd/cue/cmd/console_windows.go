@@ -0,0 +1,61 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                       = syscall.NewLazyDLL("kernel32.dll")
+	procSetConsoleMode             = kernel32.NewProc("SetConsoleMode")
+	procGetConsoleScreenBufferInfo = kernel32.NewProc("GetConsoleScreenBufferInfo")
+)
+
+const enableVirtualTerminalProcessing = 0x0004
+
+// enableColor enables the processing of ANSI escape sequences by the console
+// of f. This is supported as of Windows 10.
+func enableColor(f *os.File) bool {
+	h := syscall.Handle(f.Fd())
+	var mode uint32
+	if err := syscall.GetConsoleMode(h, &mode); err != nil {
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	r, _, _ := procSetConsoleMode.Call(uintptr(h),
+		uintptr(mode|enableVirtualTerminalProcessing))
+	return r != 0
+}
+
+func termWidth(f *os.File) int {
+	type coord struct{ x, y int16 }
+	var info struct {
+		size, cursorPosition     coord
+		attributes               uint16
+		left, top, right, bottom int16
+		maximumWindowSize        coord
+	}
+	r, _, _ := procGetConsoleScreenBufferInfo.Call(f.Fd(),
+		uintptr(unsafe.Pointer(&info)))
+	if r == 0 {
+		return 0
+	}
+	return int(info.right-info.left) + 1
+}
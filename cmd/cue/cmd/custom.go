@@ -19,28 +19,44 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
 	itask "cuelang.org/go/internal/task"
 	"cuelang.org/go/internal/value"
 	_ "cuelang.org/go/pkg/tool/cli" // Register tasks
 	_ "cuelang.org/go/pkg/tool/exec"
 	_ "cuelang.org/go/pkg/tool/file"
+	_ "cuelang.org/go/pkg/tool/helm"
 	_ "cuelang.org/go/pkg/tool/http"
+	_ "cuelang.org/go/pkg/tool/k8s"
 	_ "cuelang.org/go/pkg/tool/os"
+	_ "cuelang.org/go/pkg/tool/sql"
+	_ "cuelang.org/go/pkg/tool/trigger"
 	"cuelang.org/go/tools/flow"
 )
 
 const (
 	commandSection = "command"
+
+	// outputField is the field of a command that declares its results.
+	outputField = "$output"
+
+	// cacheField is the field of a task that marks its results as cacheable.
+	cacheField = "$cache"
 )
 
 func lookupString(obj cue.Value, key, def string) string {
@@ -104,10 +120,14 @@ outer:
 	if !strings.HasPrefix(usage, name+" ") {
 		usage = name
 	}
+	long = lookupString(o, "$long", long)
+	if s := contracts(tools.Value(), o); s != "" {
+		long = strings.TrimSpace(long + "\n\n" + s)
+	}
 	sub := &cobra.Command{
 		Use:   usage,
 		Short: lookupString(o, "$short", short),
-		Long:  lookupString(o, "$long", long),
+		Long:  long,
 		RunE: mkRunE(c, func(cmd *Command, args []string) error {
 			// TODO:
 			// - parse flags and env vars
@@ -117,18 +137,89 @@ outer:
 		}),
 	}
 	parent.AddCommand(sub)
+	c.hooks.addFlags(sub)
 
 	// TODO: implement var/flag handling.
 	return sub, nil
 }
 
+// contracts describes the inputs and outputs of command o, defined in the
+// tool instance root, for use in its help text. The inputs are the tags that
+// can be set with the --inject flag and the outputs are the results declared
+// by the $output field of o.
+func contracts(root, o cue.Value) string {
+	type tag struct{ name, typ, path string }
+	var tags []tag
+	seen := map[string]bool{}
+	var walk func(v cue.Value)
+	walk = func(v cue.Value) {
+		iter, err := v.Fields()
+		if err != nil {
+			return
+		}
+		for iter.Next() {
+			x := iter.Value()
+			a := x.Attribute("tag")
+			if name, err := a.String(0); err == nil && !seen[name] {
+				seen[name] = true
+				typ, found, _ := a.Lookup(1, "type")
+				if !found {
+					typ = "string"
+				}
+				tags = append(tags, tag{name, typ, x.Path().String()})
+			}
+			walk(x)
+		}
+	}
+	walk(root)
+	sort.Slice(tags, func(i, j int) bool { return tags[i].name < tags[j].name })
+
+	b := &strings.Builder{}
+	if len(tags) > 0 {
+		b.WriteString("Inputs (set with --inject name=value):\n")
+		w := tabwriter.NewWriter(b, 0, 8, 2, ' ', 0)
+		for _, t := range tags {
+			fmt.Fprintf(w, "  %s\t%s\t%s\n", t.name, t.typ, t.path)
+		}
+		w.Flush()
+	}
+
+	if out := o.LookupPath(cue.MakePath(cue.Str(outputField))); out.Exists() {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("Outputs:\n")
+		var n ast.Node = out.Syntax()
+		if s, ok := n.(*ast.StructLit); ok {
+			n = &ast.File{Decls: s.Elts}
+		}
+		src, err := format.Node(n)
+		if err != nil {
+			src = []byte(fmt.Sprint(out))
+		}
+		for _, line := range strings.Split(strings.TrimSpace(string(src)), "\n") {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	return b.String()
+}
+
 func doTasks(cmd *Command, typ, command string, root *cue.Instance) error {
 	cfg := &flow.Config{
 		Root:           cue.MakePath(cue.Str(commandSection), cue.Str(command)),
+		Output:         cue.MakePath(cue.Str(commandSection), cue.Str(command), cue.Str(outputField)),
 		InferTasks:     true,
 		IgnoreConcrete: true,
 	}
 
+	if cache := newCache(cmd); cache != nil {
+		cfg.Cache = cache
+		cfg.Cacheable = func(t *flow.Task) bool {
+			b, _ := t.Value().LookupPath(cue.MakePath(cue.Str(cacheField))).Bool()
+			return b
+		}
+	}
+
 	c := flow.New(cfg, root, newTaskFunc(cmd))
 
 	err := c.Run(context.Background())
@@ -137,6 +228,23 @@ func doTasks(cmd *Command, typ, command string, root *cue.Instance) error {
 	return err
 }
 
+// newCache returns the cache for the results of tasks, which is set with the
+// --cache flag to either a directory or an HTTP(S) URL, or nil if there is
+// none. It defaults to a subdirectory of the cache configured with CUE_CACHE.
+func newCache(cmd *Command) flow.Cache {
+	loc, _ := cmd.cmd.Flags().GetString(string(flagCache))
+	switch {
+	case strings.HasPrefix(loc, "http://"), strings.HasPrefix(loc, "https://"):
+		return &flow.HTTPCache{URL: loc}
+	case loc != "":
+		return flow.DirCache(loc)
+	}
+	if dir := cacheDir(); dir != "" {
+		return flow.DirCache(filepath.Join(dir, "tasks"))
+	}
+	return nil
+}
+
 // func (r *customRunner) tagReference(t *task, ref cue.Value) error {
 // 	inst, path := ref.Reference()
 // 	if len(path) == 0 {
@@ -210,7 +318,7 @@ func newTaskFunc(cmd *Command) flow.TaskFunc {
 			return nil, errors.Promote(err, "errors running task")
 		}
 
-		return flow.RunnerFunc(func(t *flow.Task) error {
+		r := flow.RunnerFunc(func(t *flow.Task) error {
 			c := &itask.Context{
 				Context: t.Context(),
 				Stdin:   cmd.InOrStdin(),
@@ -226,7 +334,11 @@ func newTaskFunc(cmd *Command) flow.TaskFunc {
 				_ = t.Fill(value)
 			}
 			return nil
-		}), nil
+		})
+		if _, ok := runner.(itask.Trigger); ok {
+			return flow.Trigger(r), nil
+		}
+		return r, nil
 	}
 }
 
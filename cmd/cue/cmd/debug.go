@@ -0,0 +1,379 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/core/debug"
+	"cuelang.org/go/internal/core/eval"
+	"cuelang.org/go/internal/filetypes"
+	"cuelang.org/go/internal/value"
+	"cuelang.org/go/tools/bench"
+)
+
+func newDebugCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "debug <cmd> [arguments]",
+		Short: "tools for debugging the cue tool and configurations",
+		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			stderr := cmd.Stderr()
+			if len(args) == 0 {
+				fmt.Fprintln(stderr, "debug must be run as one of its subcommands")
+			} else {
+				fmt.Fprintf(stderr, "debug must be run as one of its subcommands: unknown subcommand %q\n", args[0])
+			}
+			fmt.Fprintln(stderr, "Run 'cue help debug' for known subcommands.")
+			return ErrPrintedError
+		}),
+	}
+
+	cmd.AddCommand(newDebugADTCmd(c))
+	cmd.AddCommand(newDebugBenchCmd(c))
+	cmd.AddCommand(newDebugConjunctsCmd(c))
+	cmd.AddCommand(newDebugDisjunctionsCmd(c))
+	return cmd
+}
+
+func newDebugADTCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "adt [inputs]",
+		Short: "print the internal representation of a configuration",
+		Long: `Adt prints the evaluated internal representation of a configuration,
+or of the expressions given with --expression.
+
+Each value is printed with its kind, where a # marks closed structs and
+lists, followed by its fields. Errors are printed as comments, along with
+their error code. Values that have not been evaluated are printed as the
+conjunction of their expressions.
+
+The output is meant for debugging CUE and the cue tool. Its format is not
+valid CUE and may change between versions.
+`,
+		RunE: mkRunE(c, runDebugADT),
+	}
+	cmd.Flags().StringArrayP(string(flagExpression), "e", nil, "print this expression only")
+	return cmd
+}
+
+func runDebugADT(cmd *Command, args []string) error {
+	cwd, _ := os.Getwd()
+	return debugEach(cmd, args, func(w io.Writer, r adt.Runtime, v *adt.Vertex) {
+		fmt.Fprintln(w, debug.NodeString(r, v, &debug.Config{Cwd: cwd}))
+	})
+}
+
+func newDebugConjunctsCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "conjuncts [inputs]",
+		Short: "print the expressions that define each value",
+		Long: `Conjuncts prints, for each path of a configuration, or of the
+expressions given with --expression, the expressions that are unified to
+compute its value, along with their positions.
+
+  $ cat <<EOF > app.cue
+  #Port: int & <65536
+  port:  #Port
+  port:  8080
+  EOF
+
+  $ cue debug conjuncts app.cue
+  <root>
+      app.cue:1:1: {...}
+  #Port
+      app.cue:1:8: (int & <65536)
+  port
+      app.cue:2:8: #Port
+      app.cue:3:8: 8080
+`,
+		RunE: mkRunE(c, runDebugConjuncts),
+	}
+	cmd.Flags().StringArrayP(string(flagExpression), "e", nil, "print this expression only")
+	return cmd
+}
+
+func runDebugConjuncts(cmd *Command, args []string) error {
+	cwd, _ := os.Getwd()
+	return debugEach(cmd, args, func(w io.Writer, r adt.Runtime, v *adt.Vertex) {
+		walkVertex(r, v, nil, func(path []string, v *adt.Vertex) {
+			fmt.Fprintln(w, vertexPath(path))
+			for _, c := range v.Conjuncts {
+				fmt.Fprintf(w, "    %s: %s\n",
+					conjunctPos(cwd, c.Expr()), exprString(r, c.Expr()))
+			}
+		})
+	})
+}
+
+func newDebugDisjunctionsCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disjunctions [inputs]",
+		Short: "explain how disjunctions are resolved",
+		Long: `Disjunctions prints, for each path of a configuration, or of the
+expressions given with --expression, the disjunctions from which its value
+is computed. For each disjunct it reports whether it is retained or, if it
+is eliminated, the error that eliminates it when unified with the other
+expressions that define the value. Defaults are marked with a *. The
+resulting value is printed last.
+
+  $ cat <<EOF > app.cue
+  kind: *"Deployment" | "StatefulSet" | 3
+  kind: string
+  EOF
+
+  $ cue debug disjunctions app.cue
+  kind
+      app.cue:1:7: (*"Deployment"|"StatefulSet"|3)
+          *"Deployment": retained
+          "StatefulSet": retained
+          3: eliminated: conflicting values 3 and string (mismatched types int and string)
+      result: *"Deployment" | "StatefulSet"
+
+Disjuncts are evaluated independently of each other. A disjunct may
+therefore be reported as retained while it is later discarded as a
+duplicate of another.
+`,
+		RunE: mkRunE(c, runDebugDisjunctions),
+	}
+	cmd.Flags().StringArrayP(string(flagExpression), "e", nil, "print this expression only")
+	return cmd
+}
+
+func runDebugDisjunctions(cmd *Command, args []string) error {
+	cwd, _ := os.Getwd()
+	return debugEach(cmd, args, func(w io.Writer, r adt.Runtime, v *adt.Vertex) {
+		ctx := eval.NewContext(r, v)
+		walkVertex(r, v, nil, func(path []string, v *adt.Vertex) {
+			conjuncts := flattenConjuncts(v.Conjuncts)
+			printed := false
+			for i, c := range conjuncts {
+				d, ok := c.Expr().(*adt.DisjunctionExpr)
+				if !ok {
+					continue
+				}
+				if !printed {
+					fmt.Fprintln(w, vertexPath(path))
+					printed = true
+				}
+				fmt.Fprintf(w, "    %s: %s\n", conjunctPos(cwd, d), exprString(r, d))
+				for _, x := range d.Values {
+					mark := ""
+					if x.Default {
+						mark = "*"
+					}
+					status := "retained"
+					if b := tryDisjunct(ctx, v, conjuncts, i, x.Val); b != nil {
+						status = "eliminated: " + errorMsg(b.Err)
+					}
+					fmt.Fprintf(w, "        %s%s: %s\n", mark,
+						debug.NodeString(r, x.Val, &debug.Config{Compact: true}), status)
+				}
+			}
+			if printed {
+				fmt.Fprintf(w, "    result: %s\n", exprString(r, v))
+			}
+		})
+	})
+}
+
+// flattenConjuncts splits the conjunctions among the given conjuncts.
+func flattenConjuncts(a []adt.Conjunct) (result []adt.Conjunct) {
+	var add func(env *adt.Environment, x adt.Expr, ci adt.CloseInfo)
+	add = func(env *adt.Environment, x adt.Expr, ci adt.CloseInfo) {
+		if b, ok := x.(*adt.BinaryExpr); ok && b.Op == adt.AndOp {
+			add(env, b.X, ci)
+			add(env, b.Y, ci)
+			return
+		}
+		result = append(result, adt.MakeConjunct(env, x, ci))
+	}
+	for _, c := range a {
+		add(c.Env, c.Expr(), c.CloseInfo)
+	}
+	return result
+}
+
+// tryDisjunct evaluates the disjunct x in place of conjunct i of v and
+// returns the error that eliminates it, if any.
+func tryDisjunct(ctx *adt.OpContext, v *adt.Vertex, conjuncts []adt.Conjunct, i int, x adt.Expr) *adt.Bottom {
+	tmp := &adt.Vertex{Parent: v.Parent, Label: v.Label}
+	for j, c := range conjuncts {
+		if j == i {
+			c = adt.MakeConjunct(c.Env, x, c.CloseInfo)
+		}
+		tmp.AddConjunct(c)
+	}
+	tmp.Finalize(ctx)
+	b, ok := tmp.BaseValue.(*adt.Bottom)
+	if !ok || b.IsIncomplete() {
+		return nil
+	}
+	return b
+}
+
+// errorMsg returns the messages of err, without positions or paths.
+func errorMsg(err errors.Error) string {
+	var a []string
+	for _, e := range errors.Errors(err) {
+		format, args := e.Msg()
+		msg := fmt.Sprintf(format, args...)
+		if strings.HasSuffix(msg, ":") {
+			continue // header of a list of errors, like those of a disjunction
+		}
+		a = append(a, msg)
+	}
+	return strings.Join(a, "; ")
+}
+
+// debugEach calls f for the internal representation of each instance or
+// expression selected by args.
+func debugEach(cmd *Command, args []string, f func(w io.Writer, r adt.Runtime, v *adt.Vertex)) error {
+	b, err := parseArgs(cmd, args, &config{outMode: filetypes.Eval})
+	exitOnErr(cmd, err, true)
+
+	w := cmd.OutOrStdout()
+	iter := b.instances()
+	defer iter.close()
+	for i := 0; iter.scan(); i++ {
+		if len(b.insts) > 1 {
+			fmt.Fprintf(w, "// %s\n", iter.id())
+		}
+		if len(b.expressions) > 1 {
+			b, _ := format.Node(b.expressions[i%len(b.expressions)])
+			fmt.Fprintf(w, "// %s\n", b)
+		}
+		v := iter.value()
+		v.Validate(cue.All()) // evaluate all values
+		r, x := value.ToInternal(v)
+		f(w, r, x)
+	}
+	exitOnErr(cmd, iter.err(), true)
+	return nil
+}
+
+// walkVertex calls f for v and all values nested within it in depth-first
+// order.
+func walkVertex(r adt.Runtime, v *adt.Vertex, path []string, f func(path []string, v *adt.Vertex)) {
+	f(path, v)
+	for _, a := range v.Arcs {
+		walkVertex(r, a, append(path, a.Label.SelectorString(r)), f)
+	}
+}
+
+func vertexPath(path []string) string {
+	if len(path) == 0 {
+		return "<root>"
+	}
+	return strings.Join(path, ".")
+}
+
+func conjunctPos(cwd string, x adt.Node) string {
+	var pos token.Pos
+	if src := x.Source(); src != nil {
+		pos = src.Pos()
+	}
+	if !pos.IsValid() {
+		return "-"
+	}
+	return relPos(cwd, pos)
+}
+
+// exprString returns a compact representation of x, in which the contents
+// of struct and list literals are elided.
+func exprString(r adt.Runtime, x adt.Node) string {
+	switch x := x.(type) {
+	case *adt.StructLit:
+		if len(x.Decls) > 0 {
+			return "{...}"
+		}
+	case *adt.ListLit:
+		if len(x.Elems) > 0 {
+			return "[...]"
+		}
+	}
+	return debug.NodeString(r, x, &debug.Config{Compact: true})
+}
+
+func newDebugBenchCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bench [corpus ...]",
+		Short: "measure evaluation performance",
+		Long: `Bench measures the time and memory needed to evaluate the
+configurations of the given corpora. A corpus is a directory of txtar
+archives or a single archive. Each archive defines a benchmark, of which
+the CUE files in the root directory are evaluated. Other files, like those
+in a cue.mod directory, may be imported. The current directory is used if
+no corpus is given.
+
+Results are written in the format of Go benchmarks. Run the benchmarks
+multiple times with --count, and compare the results of different versions
+of the cue tool with tools such as benchstat, to detect regressions:
+
+	$ cue debug bench --count 10 testdata/bench > new.txt
+	$ benchstat old.txt new.txt
+`,
+		RunE: mkRunE(c, runDebugBench),
+	}
+
+	cmd.Flags().Duration(string(flagBenchTime), 0,
+		"minimum time to run each benchmark (default 1s)")
+	cmd.Flags().Int(string(flagCount), 1,
+		"number of times to run each benchmark")
+
+	return cmd
+}
+
+const (
+	flagBenchTime flagName = "benchtime"
+	flagCount     flagName = "count"
+)
+
+func runDebugBench(cmd *Command, args []string) error {
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	var cases []*bench.Case
+	for _, a := range args {
+		c, err := bench.Load(a)
+		if err != nil {
+			return err
+		}
+		cases = append(cases, c...)
+	}
+
+	d, _ := cmd.Flags().GetDuration(string(flagBenchTime))
+	cfg := &bench.Config{Time: d}
+	w := cmd.OutOrStdout()
+	for i := 0; i < flagCount.Int(cmd); i++ {
+		for _, c := range cases {
+			r, err := cfg.Run(c)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(w, r)
+		}
+	}
+	return nil
+}
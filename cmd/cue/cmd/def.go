@@ -15,10 +15,20 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/spf13/cobra"
 
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal"
+
 	"cuelang.org/go/internal/encoding"
 	"cuelang.org/go/internal/filetypes"
+	"cuelang.org/go/tools/fix"
 )
 
 // newDefCmd creates a new eval command
@@ -31,6 +41,37 @@ func newDefCmd(c *Command) *cobra.Command {
 Printing is skipped if validation fails.
 
 The --expression flag is used to only print parts of a configuration.
+
+The following flags control the form of the printed definitions, which is
+useful when the output is consumed by other tools:
+
+  --expand      resolve references and inline values from imported packages.
+                Imports of builtin packages are retained for validators that
+                cannot be expressed otherwise.
+  --closedness  either "open" or "closed". Open allows any additional fields
+                in all structs of definitions, while closed removes all
+                "..." from them, disallowing additional fields.
+  --flatten     move nested definitions to the top level, joining the
+                labels of their paths with an underscore. For instance,
+                #A: b: #C becomes #A_b_C. References are updated
+                accordingly.
+  --simplify    simplify constraints: remove duplicate conjuncts and
+                disjuncts, merge bounds, as in >=0 & >=10 to >=10, drop
+                types implied by literals, and fold constant expressions,
+                like 2 * 1024. This is the "simplify" fix of cue fix.
+
+The following flags control which fields are printed, which is useful to
+publish the schemas of a package while keeping some of its parts internal:
+
+  --definitions=false  omit definitions.
+  --hidden=false       omit hidden fields.
+  --rename-hidden      print a hidden field as a regular field or definition
+                       with another name, as in --rename-hidden _#Port=#Port.
+                       References are updated accordingly. The flag may be
+                       repeated.
+
+Omitted fields must not be referenced by the remaining fields, unless
+--expand is used to inline such references.
 `,
 		RunE: mkRunE(c, runDef),
 	}
@@ -44,10 +85,32 @@ The --expression flag is used to only print parts of a configuration.
 	cmd.Flags().BoolP(string(flagAttributes), "A", false,
 		"display field attributes")
 
+	cmd.Flags().Bool(string(flagExpand), false,
+		"resolve references and inline imported values")
+	cmd.Flags().String(string(flagClosedness), "",
+		`make all definitions "open" or "closed"`)
+	cmd.Flags().Bool(string(flagFlatten), false,
+		"move nested definitions to the top level")
+	cmd.Flags().Bool(string(flagDefinitions), true,
+		"include definitions")
+	cmd.Flags().Bool(string(flagHiddenFields), true,
+		"include hidden fields")
+	addRenameHiddenFlags(cmd.Flags())
+	addLiteralFlags(cmd.Flags())
+
 	// TODO: Option to include comments in output.
 	return cmd
 }
 
+const (
+	flagExpand     flagName = "expand"
+	flagClosedness flagName = "closedness"
+	flagFlatten    flagName = "flatten"
+
+	flagDefinitions  flagName = "definitions"
+	flagHiddenFields flagName = "hidden"
+)
+
 func runDef(cmd *Command, args []string) error {
 	b, err := parseArgs(cmd, args, &config{outMode: filetypes.Def})
 	exitOnErr(cmd, err, true)
@@ -55,11 +118,54 @@ func runDef(cmd *Command, args []string) error {
 	e, err := encoding.NewEncoder(b.outFile, b.encConfig)
 	exitOnErr(cmd, err, true)
 
+	closedness := flagClosedness.String(cmd)
+	switch closedness {
+	case "", "open", "closed":
+	default:
+		exitOnErr(cmd, fmt.Errorf(
+			`invalid value %q for --closedness: must be "open" or "closed"`,
+			closedness), true)
+	}
+	renames, err := renameHidden(cmd)
+	exitOnErr(cmd, err, true)
+	omitDefs := !flagDefinitions.Bool(cmd)
+	omitHidden := !flagHiddenFields.Bool(cmd)
+	transform := closedness != "" || flagFlatten.Bool(cmd) ||
+		omitDefs || omitHidden || renames != nil || flagSimplify.Bool(cmd)
+
 	iter := b.instances()
 	defer iter.close()
 	for i := 0; iter.scan(); i++ {
 		var err error
-		if f := iter.file(); f != nil {
+		if flagExpand.Bool(cmd) || transform {
+			var f *ast.File
+			if f = iter.file(); f == nil || flagExpand.Bool(cmd) || renames != nil {
+				f = internal.ToFile(iter.value().Syntax(
+					cue.Docs(true),
+					cue.Attributes(true),
+					cue.Optional(true),
+					cue.Definitions(true),
+					cue.ResolveReferences(flagExpand.Bool(cmd)),
+					cue.PreserveLiterals(flagPreserveLiterals.Bool(cmd)),
+					cue.RenameHidden(renames),
+				))
+			}
+			if omitDefs || omitHidden {
+				err = omitFields(f, omitDefs, omitHidden)
+				exitOnErr(cmd, err, true)
+			}
+			if closedness != "" {
+				setClosedness(f, closedness == "closed")
+			}
+			if flagFlatten.Bool(cmd) {
+				err = flattenDefinitions(f)
+				exitOnErr(cmd, err, true)
+			}
+			if flagSimplify.Bool(cmd) {
+				f = fix.File(f, fix.Only("simplify"))
+			}
+			err = e.EncodeFile(f)
+		} else if f := iter.file(); f != nil {
 			err = e.EncodeFile(f)
 		} else if i := iter.instance(); i != nil {
 			err = e.EncodeInstance(iter.instance())
@@ -75,3 +181,291 @@ func runDef(cmd *Command, args []string) error {
 
 	return nil
 }
+
+func isDefinition(label ast.Label) (string, bool) {
+	name, _, err := ast.LabelName(label)
+	if err != nil {
+		return "", false
+	}
+	return name, strings.HasPrefix(name, "#") || strings.HasPrefix(name, "_#")
+}
+
+// omitFields removes definitions, hidden fields, or both from f. It reports
+// an error if any of the removed fields is referenced by the remaining ones.
+func omitFields(f *ast.File, defs, hidden bool) error {
+	omitted := map[string]bool{}
+	filter := func(decls []ast.Decl) []ast.Decl {
+		k := 0
+		for _, d := range decls {
+			if x, ok := d.(*ast.Field); ok {
+				name, _, _ := ast.LabelName(x.Label)
+				if defs && internal.IsDef(name) || hidden && internal.IsHidden(name) {
+					omitted[name] = true
+					continue
+				}
+			}
+			decls[k] = d
+			k++
+		}
+		return decls[:k]
+	}
+	ast.Walk(f, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.File:
+			x.Decls = filter(x.Decls)
+		case *ast.StructLit:
+			x.Elts = filter(x.Elts)
+		}
+		return true
+	}, nil)
+
+	var err error
+	var refs func(n ast.Node) bool
+	refs = func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.Field:
+			// Only the value of a field can refer to other fields.
+			ast.Walk(x.Value, refs, nil)
+			return false
+		case *ast.Ident:
+			if omitted[x.Name] && err == nil {
+				err = fmt.Errorf(
+					"cannot omit %s: referenced by other fields; use --expand to inline references", x.Name)
+			}
+		}
+		return true
+	}
+	ast.Walk(f, refs, nil)
+	return err
+}
+
+// setClosedness opens or closes all structs within the definitions of f.
+func setClosedness(f *ast.File, closed bool) {
+	var defs []ast.Node
+	ast.Walk(f, func(n ast.Node) bool {
+		if x, ok := n.(*ast.Field); ok {
+			if _, ok := isDefinition(x.Label); ok {
+				defs = append(defs, x.Value)
+				return false
+			}
+		}
+		return true
+	}, nil)
+
+	for _, d := range defs {
+		ast.Walk(d, func(n ast.Node) bool {
+			s, ok := n.(*ast.StructLit)
+			if !ok {
+				return true
+			}
+			k := 0
+			open := false
+			for _, e := range s.Elts {
+				if _, ok := e.(*ast.Ellipsis); ok {
+					if closed {
+						continue
+					}
+					open = true
+				}
+				s.Elts[k] = e
+				k++
+			}
+			s.Elts = s.Elts[:k]
+			if !closed && !open {
+				s.Elts = append(s.Elts, &ast.Ellipsis{})
+			}
+			return true
+		}, nil)
+	}
+}
+
+// flattenDefinitions moves definitions nested within top-level definitions
+// of f to the top level, updating references to them.
+func flattenDefinitions(f *ast.File) error {
+	fl := &flattener{
+		flat:     map[*ast.Field]string{},
+		children: map[string]map[string]string{},
+		names:    map[string]bool{},
+	}
+	for _, d := range f.Decls {
+		if x, ok := d.(*ast.Field); ok {
+			if name, ok := isDefinition(x.Label); ok {
+				fl.names[name] = true
+			}
+		}
+	}
+	for _, d := range f.Decls {
+		if x, ok := d.(*ast.Field); ok {
+			if name, ok := isDefinition(x.Label); ok {
+				fl.collect(name, name, x.Value)
+			}
+		}
+	}
+	if fl.err != nil {
+		return fl.err
+	}
+
+	fl.rename(f)
+
+	var decls []ast.Decl
+	for _, d := range f.Decls {
+		decls = append(decls, d)
+		if x, ok := d.(*ast.Field); ok {
+			decls = append(decls, fl.extract(x.Value)...)
+		}
+	}
+	f.Decls = decls
+	return nil
+}
+
+type flattener struct {
+	// flat holds the new names of nested definitions.
+	flat map[*ast.Field]string
+
+	// children maps the flattened names of definitions to the new names of
+	// the definitions nested directly within them.
+	children map[string]map[string]string
+
+	names map[string]bool
+	err   errors.Error
+}
+
+// collect assigns new names to the definitions nested within x, where prefix
+// is the new name of the value at the path of x and def the new name of the
+// definition enclosing it.
+func (fl *flattener) collect(def, prefix string, x ast.Expr) {
+	s, ok := x.(*ast.StructLit)
+	if !ok {
+		return
+	}
+	for _, e := range s.Elts {
+		x, ok := e.(*ast.Field)
+		if !ok {
+			continue
+		}
+		name, _, err := ast.LabelName(x.Label)
+		if err != nil {
+			continue
+		}
+		flat := prefix + "_" + strings.TrimPrefix(strings.TrimPrefix(name, "_"), "#")
+		// Hidden definitions and those nested in hidden fields stay hidden.
+		if strings.HasPrefix(name, "_") && !strings.HasPrefix(flat, "_") {
+			flat = "_" + flat
+		}
+		if _, ok := isDefinition(x.Label); !ok {
+			fl.collect(def, flat, x.Value)
+			continue
+		}
+		if fl.names[flat] {
+			fl.err = errors.Append(fl.err, errors.Newf(x.Pos(),
+				"flattened definition %s conflicts with existing definition", flat))
+		}
+		fl.names[flat] = true
+		fl.flat[x] = flat
+		if prefix == def {
+			if fl.children[def] == nil {
+				fl.children[def] = map[string]string{}
+			}
+			fl.children[def][name] = flat
+		}
+		fl.collect(flat, flat, x.Value)
+	}
+}
+
+// rename updates the labels of nested definitions and references to them.
+func (fl *flattener) rename(f *ast.File) {
+	var scopes []map[string]string
+	push := func(decls []ast.Decl) {
+		scope := map[string]string{}
+		for _, d := range decls {
+			if x, ok := d.(*ast.Field); ok {
+				if name, ok := isDefinition(x.Label); ok {
+					scope[name] = name
+					if flat, ok := fl.flat[x]; ok {
+						scope[name] = flat
+					}
+				}
+			}
+		}
+		scopes = append(scopes, scope)
+	}
+	lookup := func(name string) string {
+		for i := len(scopes) - 1; i >= 0; i-- {
+			if flat, ok := scopes[i][name]; ok {
+				return flat
+			}
+		}
+		return ""
+	}
+
+	// Selectors are not references themselves.
+	selectors := map[*ast.Ident]bool{}
+
+	push(f.Decls)
+	astutil.Apply(f, func(c astutil.Cursor) bool {
+		switch x := c.Node().(type) {
+		case *ast.StructLit:
+			push(x.Elts)
+
+		case *ast.SelectorExpr:
+			if id, ok := x.Sel.(*ast.Ident); ok {
+				selectors[id] = true
+			}
+
+		case *ast.Ident:
+			if selectors[x] {
+				break
+			}
+			if flat := lookup(x.Name); flat != "" {
+				x.Name = flat
+			}
+		}
+		return true
+	}, func(c astutil.Cursor) bool {
+		switch x := c.Node().(type) {
+		case *ast.StructLit:
+			scopes = scopes[:len(scopes)-1]
+
+		case *ast.SelectorExpr:
+			// Rewrite references of the form #A.#B to #A_B. The operand
+			// has already been renamed at this point.
+			id, ok := x.X.(*ast.Ident)
+			if !ok {
+				break
+			}
+			if name, ok := isDefinition(x.Sel); ok {
+				if flat, ok := fl.children[id.Name][name]; ok {
+					c.Replace(ast.NewIdent(flat))
+				}
+			}
+		}
+		return true
+	})
+}
+
+// extract removes the nested definitions from x and returns them.
+func (fl *flattener) extract(x ast.Expr) (a []ast.Decl) {
+	s, ok := x.(*ast.StructLit)
+	if !ok {
+		return nil
+	}
+	k := 0
+	for _, e := range s.Elts {
+		x, ok := e.(*ast.Field)
+		if !ok {
+			s.Elts[k] = e
+			k++
+			continue
+		}
+		nested := fl.extract(x.Value)
+		if _, ok := fl.flat[x]; ok {
+			a = append(a, x)
+		} else {
+			s.Elts[k] = e
+			k++
+		}
+		a = append(a, nested...)
+	}
+	s.Elts = s.Elts[:k]
+	return a
+}
@@ -16,15 +16,24 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strconv"
 
 	"github.com/spf13/cobra"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/encoding"
 	"cuelang.org/go/internal/filetypes"
+	"cuelang.org/go/internal/value"
+	"cuelang.org/go/tools/provenance"
 )
 
 // newEvalCmd creates a new eval command
@@ -48,13 +57,74 @@ Examples:
   $ cue eval foo.cue -e a[0] -e a[2]
   "a"
   "c"
+
+The --trace-origin flag prints, instead of the value, the positions of the
+declarations that determined the value at the given path and all values
+nested within it. This is useful for finding out where a value came from in
+configurations spread across many files.
+
+  $ cat <<EOF > schema.cue
+  replicas: int & >0
+  EOF
+
+  $ cat <<EOF > data.cue
+  replicas: 3
+  EOF
+
+  $ cue eval schema.cue data.cue --trace-origin replicas
+  replicas
+      schema.cue:1:1
+      data.cue:1:1
+
+The --explain flag prints a derivation of the value at the given path: the
+conjuncts that were unified, the branches of disjunctions and why they were
+eliminated, and the default that was selected, if any.
+
+  $ cat <<EOF > kind.cue
+  kind: *"Deployment" | "StatefulSet" | int
+  kind: string
+  EOF
+
+  $ cue eval kind.cue --explain kind
+  kind
+  conjuncts:
+      kind.cue:1:7: *"Deployment" | "StatefulSet" | int
+          *"Deployment": accepted
+          "StatefulSet": accepted
+          int: eliminated: conflicting values int and string (mismatched types int and string)
+      kind.cue:2:7: string
+  default: "Deployment"
+  result: *"Deployment" | "StatefulSet"
+
+The --keep flag writes the expression from which the value at the given path
+is derived, instead of the value itself. Fields may also be marked with a
+@keep() attribute. The result is partially evaluated CUE: all other values
+are resolved, while the kept expressions retain their references, defaults,
+and constraints. References in kept expressions are written as is, so they
+should refer to fields that are part of the output.
+
+  $ cat <<EOF > app.cue
+  replicas: *3 | int & >0 @keep()
+  port:     *8080 | int
+  url:      "http://localhost:\(port)"
+  EOF
+
+  $ cue eval app.cue --keep url
+  replicas: *3 | int & >0
+  port:     8080
+  url:      "http://localhost:\(port)"
+
+The --baseline and --update-baseline flags suppress known errors as for
+vet. Run 'cue help vet' for more information.
 `,
-		RunE: mkRunE(c, runEval),
+		RunE: mkRunE(c, withBaseline(runEval)),
 	}
 
 	addOutFlags(cmd.Flags(), true)
 	addOrphanFlags(cmd.Flags())
 	addInjectionFlags(cmd.Flags(), false)
+	addSecretFlags(cmd.Flags())
+	addBaselineFlags(cmd.Flags())
 
 	cmd.Flags().StringArrayP(string(flagExpression), "e", nil, "evaluate this expression only")
 
@@ -73,26 +143,48 @@ Examples:
 	cmd.Flags().BoolP(string(flagAll), "a", false,
 		"show optional and hidden fields")
 
+	cmd.Flags().String(string(flagTraceOrigin), "",
+		"print the source positions of the values at this path")
+
+	cmd.Flags().String(string(flagExplain), "",
+		"explain how the value at this path was derived")
+
+	cmd.Flags().StringArray(string(flagKeep), nil,
+		"write the expression of the value at this path instead of the value")
+
+	addLiteralFlags(cmd.Flags())
+
 	// TODO: Option to include comments in output.
 	return cmd
 }
 
 const (
-	flagConcrete   flagName = "concrete"
-	flagHidden     flagName = "show-hidden"
-	flagOptional   flagName = "show-optional"
-	flagAttributes flagName = "show-attributes"
+	flagConcrete    flagName = "concrete"
+	flagHidden      flagName = "show-hidden"
+	flagOptional    flagName = "show-optional"
+	flagAttributes  flagName = "show-attributes"
+	flagTraceOrigin flagName = "trace-origin"
+	flagExplain     flagName = "explain"
+	flagKeep        flagName = "keep"
 )
 
 func runEval(cmd *Command, args []string) error {
 	b, err := parseArgs(cmd, args, &config{outMode: filetypes.Eval})
 	exitOnErr(cmd, err, true)
 
+	if cmd.Flags().Changed(string(flagTraceOrigin)) {
+		return traceOrigin(cmd, b, flagTraceOrigin.String(cmd))
+	}
+	if cmd.Flags().Changed(string(flagExplain)) {
+		return explain(cmd, b, flagExplain.String(cmd))
+	}
+
 	syn := []cue.Option{
 		cue.Final(), // for backwards compatibility
 		cue.Definitions(true),
 		cue.Attributes(flagAttributes.Bool(cmd)),
 		cue.Optional(flagAll.Bool(cmd) || flagOptional.Bool(cmd)),
+		cue.PreserveLiterals(flagPreserveLiterals.Bool(cmd)),
 	}
 
 	// Keep for legacy reasons. Note that `cue eval` is to be deprecated by
@@ -124,6 +216,9 @@ func runEval(cmd *Command, args []string) error {
 			}
 		}
 		if b.outFile.Encoding != build.CUE {
+			if len(flagKeep.StringArray(cmd)) > 0 {
+				exitOnErr(cmd, errors.New("--keep requires CUE output"), true)
+			}
 			err := e.Encode(v)
 			if err != nil {
 				errHeader()
@@ -160,6 +255,11 @@ func runEval(cmd *Command, args []string) error {
 
 		f := internal.ToFile(v.Syntax(syn...))
 		f.Filename = id
+		if err := keepExprs(f, v, flagKeep.StringArray(cmd)); err != nil {
+			errHeader()
+			exitOnErr(cmd, err, false)
+			continue
+		}
 		err := e.EncodeFile(f)
 		if err != nil {
 			errHeader()
@@ -173,3 +273,172 @@ func runEval(cmd *Command, args []string) error {
 
 	return nil
 }
+
+// keepExprs replaces the values in f, the syntax of v, of the fields at the
+// given paths or marked with a keep attribute with the expressions from which
+// they are derived. Imports used by these expressions are added to f.
+func keepExprs(f *ast.File, v cue.Value, paths []string) error {
+	var keep []cue.Path
+	for _, s := range paths {
+		p := cue.ParsePath(s)
+		if err := p.Err(); err != nil {
+			return err
+		}
+		if !v.LookupPath(p).Exists() {
+			return errors.Newf(token.NoPos, "path %q not found", s)
+		}
+		keep = append(keep, p)
+	}
+	var walk func(v cue.Value, sels []cue.Selector)
+	walk = func(v cue.Value, sels []cue.Selector) {
+		iter, err := v.Fields(cue.Definitions(true))
+		if err != nil {
+			return
+		}
+		for iter.Next() {
+			p := append(sels[:len(sels):len(sels)], iter.Selector())
+			if a := iter.Value().Attribute("keep"); a.Err() == nil {
+				keep = append(keep, cue.MakePath(p...))
+				continue
+			}
+			walk(iter.Value(), p)
+		}
+	}
+	walk(v, nil)
+
+	imports := map[string]*ast.ImportSpec{}
+	var specs []*ast.ImportSpec
+	for _, p := range keep {
+		x := sourceExpr(v.LookupPath(p))
+		if x == nil || !replaceValue(f.Decls, p.Selectors(), x) {
+			continue
+		}
+		ast.Walk(x, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok {
+				if spec, ok := id.Node.(*ast.ImportSpec); ok && imports[spec.Path.Value] == nil {
+					imports[spec.Path.Value] = spec
+					specs = append(specs, &ast.ImportSpec{Name: spec.Name, Path: spec.Path})
+				}
+			}
+			return true
+		}, nil)
+	}
+	if len(specs) > 0 {
+		f.Decls = append([]ast.Decl{&ast.ImportDecl{Specs: specs}}, f.Decls...)
+	}
+	return nil
+}
+
+// sourceExpr returns the conjunction of the expressions that define v.
+func sourceExpr(v cue.Value) ast.Expr {
+	_, vx := value.ToInternal(v)
+	var a []ast.Expr
+	for _, c := range vx.Conjuncts {
+		if x, ok := c.Expr().Source().(ast.Expr); ok {
+			a = append(a, x)
+		}
+	}
+	if len(a) == 0 {
+		return nil
+	}
+	return ast.NewBinExpr(token.AND, a...)
+}
+
+// replaceValue sets the value at the given path in decls to x. It reports
+// whether the value was found.
+func replaceValue(decls []ast.Decl, sels []cue.Selector, x ast.Expr) bool {
+	if len(sels) == 0 {
+		return false
+	}
+	label := sels[0].String()
+	if s, err := literal.Unquote(label); err == nil {
+		label = s
+	}
+	for _, d := range decls {
+		f, ok := d.(*ast.Field)
+		if !ok {
+			continue
+		}
+		if name, _, _ := ast.LabelName(f.Label); name != label {
+			continue
+		}
+		if len(sels) == 1 {
+			f.Value = x
+			return true
+		}
+		return replaceIn(f.Value, sels[1:], x)
+	}
+	return false
+}
+
+func replaceIn(v ast.Expr, sels []cue.Selector, x ast.Expr) bool {
+	switch v := v.(type) {
+	case *ast.StructLit:
+		return replaceValue(v.Elts, sels, x)
+	case *ast.ListLit:
+		i, err := strconv.Atoi(sels[0].String())
+		if err != nil || i >= len(v.Elts) {
+			return false
+		}
+		if len(sels) == 1 {
+			v.Elts[i] = x
+			return true
+		}
+		return replaceIn(v.Elts[i], sels[1:], x)
+	}
+	return false
+}
+
+func traceOrigin(cmd *Command, b *buildPlan, path string) error {
+	cwd, _ := os.Getwd()
+	return lookupEach(cmd, b, path, func(w io.Writer, p cue.Path, v cue.Value) {
+		for _, o := range provenance.Trace(v) {
+			fmt.Fprintln(w, pathName(cue.MakePath(append(p.Selectors(), o.Path.Selectors()...)...)))
+			for _, pos := range o.Positions {
+				fmt.Fprintf(w, "    %s\n", relPos(cwd, pos))
+			}
+		}
+	})
+}
+
+func explain(cmd *Command, b *buildPlan, path string) error {
+	cwd, _ := os.Getwd()
+	return lookupEach(cmd, b, path, func(w io.Writer, p cue.Path, v cue.Value) {
+		fmt.Fprintln(w, pathName(p))
+		fmt.Fprint(w, provenance.Explain(v).Format(func(pos token.Pos) string {
+			return relPos(cwd, pos)
+		}))
+	})
+}
+
+// lookupEach calls f for the value at path within each instance of b.
+func lookupEach(cmd *Command, b *buildPlan, path string, f func(w io.Writer, p cue.Path, v cue.Value)) error {
+	p := cue.ParsePath(path)
+	exitOnErr(cmd, p.Err(), true)
+
+	w := cmd.OutOrStdout()
+
+	iter := b.instances()
+	defer iter.close()
+	for iter.scan() {
+		if len(b.insts) > 1 {
+			fmt.Fprintf(w, "// %s\n", iter.id())
+		}
+		v := iter.value().LookupPath(p)
+		if !v.Exists() {
+			exitOnErr(cmd, errors.Newf(token.NoPos,
+				"path %q not found", path), false)
+			continue
+		}
+		f(w, p, v)
+	}
+	exitOnErr(cmd, iter.err(), true)
+	return nil
+}
+
+func pathName(p cue.Path) string {
+	if s := p.String(); s != "" {
+		return s
+	}
+	return "<root>"
+}
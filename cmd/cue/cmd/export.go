@@ -15,10 +15,28 @@
 package cmd
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/spf13/cobra"
 
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/encoding"
 	"cuelang.org/go/internal/filetypes"
+	"cuelang.org/go/tools/infer"
+	"cuelang.org/go/tools/writeback"
 )
 
 // newExportCmd creates and export command
@@ -85,8 +103,217 @@ json    output as JSON
 text    output as raw text
                 The evaluated value must be of type string.
 
+binary  output as raw bytes
+                The evaluated value must be of type bytes or string. This
+                is the default for files with the .bin extension.
+
 yaml    output as YAML
                 Outputs any CUE value.
+
+openapi output as an OpenAPI document
+                The definitions of the exported package are written as
+                component schemas.
+
+jsonschema  output as JSON Schema
+                The definitions of the exported package are written as
+                JSON Schema definitions. The schemas are those of the
+                OpenAPI output, which may include OpenAPI extensions to
+                JSON Schema, such as nullable. Packages imported from
+                JSON Schema are converted back to the schema they were
+                imported from, restoring the details recorded by
+                'cue import --annotate'.
+
+By default, references between definitions are written as references in
+OpenAPI and JSON Schema output. The --cycle-depth flag inlines them instead.
+As definitions may refer to themselves, for instance to describe trees,
+recursive references are expanded at most the given number of times, after
+which only the type of the value is written.
+
+	$ cue export schema.cue --out openapi --cycle-depth 2
+
+
+Exporting Go types
+
+The --from go flag interprets the arguments as Go package patterns. The types
+of the matched packages are converted to CUE as with "cue get go", after which
+the resulting CUE packages are exported. The converted files are kept in
+memory only: the cue.mod/gen directory is not modified. This must be run
+within a CUE module.
+
+	$ cue export --from go ./api --out jsonschema
+
+
+Writing results back to source files
+
+The --to-files flag writes concrete results back into the files from which
+they originate instead of writing them to the output. Only literals and
+placeholders like int are replaced; expressions that refer to other values
+are preserved, as are values that are determined by defaults. The formatting
+and comments of the files are otherwise untouched.
+
+	$ cat config.cue
+	hosts: ["a", "b", "c"]
+	replicas: int // computed
+	$ cat rules.cue
+	replicas: len(hosts)
+	$ cue export --to-files config.cue rules.cue
+	$ cat config.cue
+	hosts: ["a", "b", "c"]
+	replicas: 3 // computed
+
+
+Writing multiple files
+
+The --split flag writes each element of a struct or list to a separate file
+in the directory specified by --outdir, which defaults to the current
+directory. The file name of each element is determined by evaluating the
+given CUE expression within the scope of that element. The encoding is
+derived from the file extension, unless specified with --out or with a file
+type prefix in the name, as in "binary:" + name + ".der".
+
+	$ cat manifests.cue
+	objects: [{
+		kind: "Service"
+		metadata: name: "web"
+	}, {
+		kind: "Deployment"
+		metadata: name: "web"
+	}]
+	$ cue export manifests.cue -e objects --outdir out \
+		--split 'strings.ToLower(kind) + "-" + metadata.name + ".yaml"'
+	$ ls out
+	deployment-web.yaml	service-web.yaml
+
+
+Field order
+
+By default, fields are written in the order in which they are declared. The
+--field-order flag selects a different order:
+
+source   declaration order (default).
+lexical  fields are sorted by label.
+schema   fields with an @order(n) attribute are written first, sorted by n,
+         followed by the remaining fields in declaration order.
+
+	$ cat data.cue
+	metadata: name: "web"
+	kind:       "Service" @order(1)
+	apiVersion: "v1"      @order(0)
+	$ cue export data.cue --field-order schema
+	{
+	    "apiVersion": "v1",
+	    "kind": "Service",
+	    "metadata": {
+	        "name": "web"
+	    }
+	}
+
+
+Output attributes
+
+The @out attribute controls how a field is written:
+
+omit       the field is not written.
+validate   the field is only used for validation: it is not written and
+           need not be concrete.
+name=x     the field is written with label x.
+json=x     the field is written with label x in JSON output. Likewise for
+           yaml and cue. This takes precedence over name.
+
+	$ cat data.cue
+	port:     8080   @out(name=containerPort)
+	internal: "x"    @out(omit)
+	check:    <65536 @out(validate)
+	check:    port
+	$ cue export data.cue
+	{
+	    "containerPort": 8080
+	}
+
+
+Number format
+
+Floating-point numbers are written in JSON and YAML output as they are
+represented by CUE. The following flags change this:
+
+--integers     numbers with an integral value, like 2.0 or 1e3, are written
+               as integers.
+--decimals n   numbers are written with n decimals, rounding as needed.
+--exponent n   numbers are written in exponent notation if the exponent of
+               their most significant digit is at least n or at most -n.
+--exact        it is an error if --decimals would round a number.
+
+The same options can be set for a field and the values nested within it with
+the integers, decimals=n, exponent=n, and exact arguments of @out. Integer
+values are never modified.
+
+	$ cat price.cue
+	price: 10.5 @out(decimals=2)
+	ratio: 1e3
+	$ cue export price.cue --integers
+	{
+	    "price": 10.50,
+	    "ratio": 1000
+	}
+
+
+Provenance
+
+The --provenance flag writes a record of the export to the given file, so
+that its result can be audited and reproduced. The record lists the input
+files, including those of imported packages, with their SHA-256 digests,
+the output with its digest, the version of the cue tool, the command line,
+and the injected tags. With --provenance-format in-toto, the record is
+written as an in-toto statement with a SLSA provenance predicate, which
+can be signed as an attestation by tools such as cosign.
+
+	$ cue export config.cue -o config.json --provenance config.prov.json
+
+
+Reproducible output
+
+The output of export depends only on its inputs and flags: fields are
+written in a fixed order and lines end with a line feed on all platforms.
+The --newline flag selects the line ending of text output: lf (default) or
+crlf. Binary output is not affected. As --to-files retains the formatting
+of the files it updates, crlf cannot be used with --to-files.
+
+The --deterministic flag additionally rejects the options that make the
+output depend on the time, the machine, or randomness, so that the output
+is byte-for-byte identical across runs and operating systems:
+
+  - injecting system variables with --inject-vars,
+  - encrypting secrets with --secret, which uses random nonces,
+  - --split file names that differ only in case or that contain a
+    backslash, which collide or denote directories on some systems.
+
+	$ cue export ./deploy --out yaml --deterministic -o deploy.yaml
+
+
+Inferring schemas
+
+The --infer flag writes a schema that generalizes the given data, instead of
+the data itself, as a starting point for writing a schema. Each data file is
+a separate sample. Fields that do not occur in all samples are optional and
+values of different kinds result in a disjunction. The schema is written as
+the definition #Schema in CUE, or, with --out openapi or --out jsonschema,
+as an OpenAPI or JSON Schema.
+
+--infer-open       allow fields other than the observed ones.
+--infer-literals   constrain values to the observed ones instead of to
+                   their types.
+
+	$ cat web.json
+	{"name": "web", "port": 80, "tags": ["a"]}
+	$ cat db.json
+	{"name": "db", "port": 5432, "debug": true}
+	$ cue export --infer web.json db.json
+	#Schema: {
+	    name: string
+	    port: int
+	    tags?: [...string]
+	    debug?: bool
+	}
 `,
 
 		RunE: mkRunE(c, runExport),
@@ -98,13 +325,178 @@ yaml    output as YAML
 
 	cmd.Flags().Bool(string(flagEscape), false, "use HTML escaping")
 	cmd.Flags().StringArrayP(string(flagExpression), "e", nil, "export this expression only")
+	cmd.Flags().Bool(string(flagToFiles), false,
+		"write concrete results back into their source files")
+	cmd.Flags().String(string(flagSplit), "",
+		"write each element to a file named by this expression")
+	cmd.Flags().String(string(flagOutDir), "",
+		"directory to write files to for --split")
+	cmd.Flags().String(string(flagFieldOrder), "source",
+		"order of fields in the output: source, lexical, or schema")
+	cmd.Flags().String(string(flagFrom), "",
+		"interpret arguments as packages of this language: go")
+	cmd.Flags().Int(string(flagCycleDepth), 0,
+		"inline references in OpenAPI and JSON Schema output, expanding recursive ones at most this many times")
+	cmd.Flags().Bool(string(flagIntegers), false,
+		"write floating-point numbers with an integral value as integers")
+	cmd.Flags().Int(string(flagDecimals), 0,
+		"write floating-point numbers with this many decimals")
+	cmd.Flags().Int(string(flagExponent), 0,
+		"write floating-point numbers in exponent notation from this order of magnitude")
+	cmd.Flags().Bool(string(flagExact), false,
+		"report an error instead of rounding numbers for --decimals")
+	cmd.Flags().String(string(flagProvenance), "",
+		"write a provenance record of the export to this file")
+	cmd.Flags().String(string(flagProvenanceFormat), "cue",
+		"format of the provenance record: cue or in-toto")
+	cmd.Flags().Bool(string(flagInfer), false,
+		"write a schema inferred from the data instead of the data")
+	cmd.Flags().Bool(string(flagInferOpen), false,
+		"allow fields other than the observed ones in the inferred schema")
+	cmd.Flags().Bool(string(flagInferLiterals), false,
+		"constrain values to the observed ones in the inferred schema")
+	cmd.Flags().String(string(flagNewline), "lf",
+		"line ending of text output: lf or crlf")
+	cmd.Flags().Bool(string(flagDeterministic), false,
+		"reject options that make the output differ across runs or platforms")
+	addLiteralFlags(cmd.Flags())
+	addSecretFlags(cmd.Flags())
+	addRenameHiddenFlags(cmd.Flags())
 
 	return cmd
 }
 
+const (
+	flagToFiles    flagName = "to-files"
+	flagSplit      flagName = "split"
+	flagOutDir     flagName = "outdir"
+	flagFieldOrder flagName = "field-order"
+	flagFrom       flagName = "from"
+	flagCycleDepth flagName = "cycle-depth"
+	flagIntegers   flagName = "integers"
+	flagDecimals   flagName = "decimals"
+	flagExponent   flagName = "exponent"
+	flagExact      flagName = "exact"
+
+	flagProvenance       flagName = "provenance"
+	flagProvenanceFormat flagName = "provenance-format"
+
+	flagInfer         flagName = "infer"
+	flagInferOpen     flagName = "infer-open"
+	flagInferLiterals flagName = "infer-literals"
+
+	flagNewline       flagName = "newline"
+	flagDeterministic flagName = "deterministic"
+)
+
 func runExport(cmd *Command, args []string) error {
-	b, err := parseArgs(cmd, args, &config{outMode: filetypes.Export})
+	cfg := &config{outMode: filetypes.Export}
+	switch from := flagFrom.String(cmd); from {
+	case "":
+	case "go":
+		var err error
+		args, cfg.loadCfg, err = loadGo(cmd, args)
+		exitOnErr(cmd, err, true)
+	default:
+		exitOnErr(cmd, fmt.Errorf("unsupported --from language %q", from), true)
+	}
+
+	infer := flagInfer.Bool(cmd)
+	if infer {
+		// Each data file is a separate sample.
+		cfg.noMerge = true
+		if !cmd.Flags().Changed(string(flagOut)) {
+			exitOnErr(cmd, cmd.Flags().Set(string(flagOut), "cue"), true)
+		}
+	}
+
+	b, err := parseArgs(cmd, args, cfg)
+	exitOnErr(cmd, err, true)
+
+	b.encConfig.FieldOrder, err = encoding.ParseFieldOrder(flagFieldOrder.String(cmd))
+	exitOnErr(cmd, err, true)
+
+	switch n := flagCycleDepth.Int(cmd); {
+	case n < 0:
+		exitOnErr(cmd, errors.New("--cycle-depth must not be negative"), true)
+	case n > 0:
+		b.encConfig.ExpandReferences = true
+		b.encConfig.MaxCycleDepth = n
+	}
+
+	b.encConfig.PreserveLiterals = flagPreserveLiterals.Bool(cmd)
+	b.encConfig.Numbers = encoding.NumberFormat{
+		Integers: flagIntegers.Bool(cmd),
+		Decimals: flagDecimals.Int(cmd),
+		Exponent: flagExponent.Int(cmd),
+		Exact:    flagExact.Bool(cmd),
+	}
+	if b.encConfig.Numbers.Decimals < 0 || b.encConfig.Numbers.Exponent < 0 {
+		exitOnErr(cmd, errors.New("--decimals and --exponent must not be negative"), true)
+	}
+
+	switch nl := flagNewline.String(cmd); nl {
+	case "lf":
+	case "crlf":
+		if flagToFiles.Bool(cmd) {
+			exitOnErr(cmd, errors.New("--newline cannot be used with --to-files"), true)
+		}
+		b.encConfig.Newline = "\r\n"
+	default:
+		exitOnErr(cmd, fmt.Errorf("invalid --newline %q: must be lf or crlf", nl), true)
+	}
+
+	if flagDeterministic.Bool(cmd) {
+		switch {
+		case flagInjectVars.Bool(cmd):
+			exitOnErr(cmd, errors.New("--deterministic cannot be used with --inject-vars"), true)
+		case b.secrets != nil:
+			exitOnErr(cmd, errors.New("--deterministic cannot be used with --secret"), true)
+		}
+	}
+
+	provenance := flagProvenance.String(cmd)
+	if provenance != "" && (flagToFiles.Bool(cmd) || flagSplit.String(cmd) != "") {
+		exitOnErr(cmd, errors.New("--provenance cannot be used with --to-files or --split"), true)
+	}
+
+	renames, err := renameHidden(cmd)
 	exitOnErr(cmd, err, true)
+	if renames != nil && (flagToFiles.Bool(cmd) || flagSplit.String(cmd) != "") {
+		exitOnErr(cmd, errors.New("--rename-hidden cannot be used with --to-files or --split"), true)
+	}
+
+	if infer {
+		if flagToFiles.Bool(cmd) || flagSplit.String(cmd) != "" || provenance != "" {
+			exitOnErr(cmd, errors.New("--infer cannot be used with --to-files, --split, or --provenance"), true)
+		}
+		return exportInferred(cmd, b)
+	}
+	if flagToFiles.Bool(cmd) {
+		return exportToFiles(cmd, b)
+	}
+	if flagSplit.String(cmd) != "" {
+		return exportSplit(cmd, b)
+	}
+	if flagOutDir.String(cmd) != "" {
+		exitOnErr(cmd, errors.New("--outdir requires --split"), true)
+	}
+
+	var cache *resultCache
+	if b.outFile.Filename == "-" && flagFrom.String(cmd) == "" && b.secrets == nil {
+		cache = openCache(cmd, args, b)
+	}
+	if result, ok := cache.get(); ok {
+		_, err := cmd.OutOrStdout().Write(result)
+		if err == nil && provenance != "" {
+			err = writeProvenance(cmd, args, b, result)
+		}
+		return err
+	}
+	result := &bytes.Buffer{}
+	if cache != nil || provenance != "" {
+		b.encConfig.Stdout = io.MultiWriter(b.encConfig.Stdout, result)
+	}
 
 	enc, err := encoding.NewEncoder(b.outFile, b.encConfig)
 	exitOnErr(cmd, err, true)
@@ -114,9 +506,249 @@ func runExport(cmd *Command, args []string) error {
 	defer iter.close()
 	for iter.scan() {
 		v := iter.value()
+		if renames != nil {
+			v, err = exportHidden(v, renames)
+			exitOnErr(cmd, err, true)
+		}
+		v, err = b.encrypt(v)
+		exitOnErr(cmd, err, true)
 		err = enc.Encode(v)
 		exitOnErr(cmd, err, true)
 	}
 	exitOnErr(cmd, iter.err(), true)
+
+	if !cmd.hasErr {
+		cache.put(result.Bytes())
+	}
+	if provenance != "" && !cmd.hasErr {
+		exitOnErr(cmd, enc.Close(), true)
+		exitOnErr(cmd, writeProvenance(cmd, args, b, result.Bytes()), true)
+	}
+	return nil
+}
+
+// exportHidden returns v with the hidden fields named in renames turned into
+// regular fields, so that they are exported.
+func exportHidden(v cue.Value, renames map[string]string) (cue.Value, error) {
+	n := v.Syntax(
+		cue.Final(),
+		cue.Docs(true),
+		cue.Attributes(true),
+		cue.RenameHidden(renames),
+	)
+	w := v.Context().BuildFile(internal.ToFile(n))
+	return w, w.Err()
+}
+
+// exportInferred writes the schema inferred from the values of b.
+func exportInferred(cmd *Command, b *buildPlan) error {
+	var samples []cue.Value
+	iter := b.instances()
+	defer iter.close()
+	for iter.scan() {
+		samples = append(samples, iter.value())
+	}
+	exitOnErr(cmd, iter.err(), true)
+
+	f, err := infer.File("#Schema", samples, &infer.Config{
+		Open:     flagInferOpen.Bool(cmd),
+		Literals: flagInferLiterals.Bool(cmd),
+	})
+	exitOnErr(cmd, err, true)
+
+	enc, err := encoding.NewEncoder(b.outFile, b.encConfig)
+	exitOnErr(cmd, err, true)
+	defer enc.Close()
+
+	switch {
+	case b.outFile.Interpretation != "":
+		v := samples[0].Context().BuildFile(f)
+		exitOnErr(cmd, v.Err(), true)
+		err = enc.Encode(v)
+	case b.outFile.Encoding == build.CUE:
+		err = enc.EncodeFile(f)
+	default:
+		err = errors.Newf(token.NoPos,
+			"--infer requires CUE, OpenAPI, or JSON Schema output")
+	}
+	exitOnErr(cmd, err, true)
+	return nil
+}
+
+// loadGo converts the Go packages matching args to CUE. It returns the
+// arguments with which to load the converted packages and a load
+// configuration that provides the generated files as an overlay.
+func loadGo(cmd *Command, args []string) ([]string, *load.Config, error) {
+	binst := loadFromArgs(cmd, []string{"."}, nil)[0]
+	if binst.Module == "" {
+		return nil, nil, errors.New("--from go must be used within a CUE module")
+	}
+
+	overlay := map[string]load.Source{}
+	pkgs, err := extractGo(cmd, binst.Root, args, true, func(filename string, b []byte) error {
+		filename, err := filepath.Abs(filename)
+		if err != nil {
+			return err
+		}
+		overlay[filename] = load.FromBytes(b)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, nil, err
+	}
+	var paths []string
+	for _, p := range pkgs {
+		if p.Module == nil || !p.Module.Main {
+			paths = append(paths, p.PkgPath)
+			continue
+		}
+		// Packages of the main module are converted in place, as with
+		// "cue get go --local", and loaded by directory so that they do not
+		// depend on the module paths of the Go and CUE modules to agree.
+		rel, err := filepath.Rel(cwd, localDir(p))
+		if err != nil {
+			return nil, nil, err
+		}
+		paths = append(paths, "./"+filepath.ToSlash(rel))
+	}
+	cfg := *defaultConfig.loadCfg
+	cfg.Overlay = overlay
+	return paths, &cfg, nil
+}
+
+func exportToFiles(cmd *Command, b *buildPlan) error {
+	if len(b.expressions) > 0 {
+		return errors.New("--to-files may not be combined with --expression")
+	}
+
+	iter := b.instances()
+	defer iter.close()
+	for n := 0; iter.scan(); n++ {
+		var files []*ast.File
+		switch f := iter.file(); {
+		case f != nil:
+			files = []*ast.File{f}
+		case n < len(b.insts):
+			files = b.insts[n].Files
+		}
+
+		updated, err := writeback.Update(iter.value(), files, nil)
+		exitOnErr(cmd, err, true)
+
+		for _, f := range updated {
+			mode := os.FileMode(0644)
+			if info, err := os.Stat(f.Filename); err == nil {
+				mode = info.Mode()
+			}
+			err := ioutil.WriteFile(f.Filename, f.Data, mode)
+			exitOnErr(cmd, err, true)
+		}
+	}
+	exitOnErr(cmd, iter.err(), true)
+	return nil
+}
+
+func exportSplit(cmd *Command, b *buildPlan) error {
+	expr, err := parser.ParseExpr("--split", flagSplit.String(cmd))
+	exitOnErr(cmd, err, true)
+
+	dir := flagOutDir.String(cmd)
+	if dir == "" {
+		dir = "."
+	}
+	deterministic := flagDeterministic.Bool(cmd)
+	seen := map[string]string{}
+
+	write := func(v cue.Value) {
+		nv := v.Context().BuildExpr(expr,
+			cue.Scope(v),
+			cue.InferBuiltins(true),
+		)
+		name, err := nv.String()
+		if err != nil {
+			exitOnErr(cmd, errors.Wrapf(err, v.Pos(),
+				"invalid file name for %v", v.Path()), true)
+		}
+		// The name may be prefixed with a file type, as in binary:cert.der.
+		qualifier := flagOut.String(cmd)
+		if i := strings.IndexByte(name, ':'); i > 0 && !strings.ContainsAny(name[:i], `/\.`) {
+			qualifier, name = name[:i], name[i+1:]
+		}
+		if deterministic && strings.ContainsRune(name, '\\') {
+			exitOnErr(cmd, errors.Newf(v.Pos(),
+				"file name %q for %v contains a backslash", name, v.Path()), true)
+		}
+		name = filepath.Clean(name)
+		if filepath.IsAbs(name) || strings.HasPrefix(name, "..") {
+			exitOnErr(cmd, errors.Newf(v.Pos(),
+				"file name %q for %v must be relative to the output directory",
+				name, v.Path()), true)
+		}
+		key := name
+		if deterministic {
+			// Names that differ only in case denote the same file on
+			// case-insensitive file systems.
+			key = strings.ToLower(name)
+		}
+		if prev, ok := seen[key]; ok {
+			if prev != name {
+				exitOnErr(cmd, errors.Newf(v.Pos(),
+					"file name %q for %v differs only in case from %q", name, v.Path(), prev), true)
+			}
+			exitOnErr(cmd, errors.Newf(v.Pos(),
+				"duplicate file name %q for %v", name, v.Path()), true)
+		}
+		seen[key] = name
+
+		path := filepath.Join(dir, name)
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+		exitOnErr(cmd, err, true)
+
+		if qualifier != "" {
+			path = qualifier + ":" + path
+		}
+		f, err := filetypes.ParseFile(path, filetypes.Export)
+		exitOnErr(cmd, err, true)
+
+		v, err = b.encrypt(v)
+		exitOnErr(cmd, err, true)
+		enc, err := encoding.NewEncoder(f, b.encConfig)
+		exitOnErr(cmd, err, true)
+		err = enc.Encode(v)
+		exitOnErr(cmd, err, true)
+		err = enc.Close()
+		exitOnErr(cmd, err, true)
+	}
+
+	iter := b.instances()
+	defer iter.close()
+	for iter.scan() {
+		v := iter.value()
+		switch v.IncompleteKind() {
+		case cue.StructKind:
+			fields, err := v.Fields()
+			exitOnErr(cmd, err, true)
+			for fields.Next() {
+				write(fields.Value())
+			}
+
+		case cue.ListKind:
+			elems, err := v.List()
+			exitOnErr(cmd, err, true)
+			for elems.Next() {
+				write(elems.Value())
+			}
+
+		default:
+			exitOnErr(cmd, errors.Newf(v.Pos(),
+				"--split requires a struct or list, found %v", v.IncompleteKind()), true)
+		}
+	}
+	exitOnErr(cmd, iter.err(), true)
 	return nil
 }
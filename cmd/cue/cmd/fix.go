@@ -15,6 +15,7 @@
 package cmd
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -26,6 +27,7 @@ import (
 	"cuelang.org/go/cue/load"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/tools/fix"
+	"github.com/kylelemons/godebug/diff"
 	"github.com/spf13/cobra"
 )
 
@@ -38,26 +40,61 @@ After you update to a new CUE release, fix helps make the necessary changes
 to your program.
 
 Without any packages, fix applies to all files within a module.
+
+Fix applies the following fixes. Optional fixes are only applied when
+selected with the --fixes flag, which takes a comma-separated list of
+fix names and restricts fix to the named fixes.
+
+` + fixList() + `
+The --dryrun flag prints the changes as a diff instead of writing them.
 `,
 		RunE: mkRunE(c, runFixAll),
 	}
 
 	cmd.Flags().BoolP(string(flagForce), "f", false,
 		"rewrite even when there are errors")
+	cmd.Flags().String(string(flagFixes), "",
+		"comma-separated list of fixes to apply")
+	cmd.Flags().BoolP(string(flagDryrun), "n", false,
+		"print changes as a diff instead of writing files")
 
 	return cmd
 }
 
+const flagFixes flagName = "fixes"
+
+func fixList() string {
+	w := &strings.Builder{}
+	for _, f := range fix.Fixes() {
+		doc := f.Doc
+		if f.Optional {
+			doc += " (optional)"
+		}
+		fmt.Fprintf(w, "   %-9s %s\n", f.Name, doc)
+	}
+	return w.String()
+}
+
 func runFixAll(cmd *Command, args []string) error {
-	dir, err := os.Getwd()
+	cwd, err := os.Getwd()
 	if err != nil {
 		return err
 	}
+	dir := cwd
 
 	var opts []fix.Option
 	if flagSimplify.Bool(cmd) {
 		opts = append(opts, fix.Simplify())
 	}
+	if s := flagFixes.String(cmd); s != "" {
+		names := strings.Split(s, ",")
+		for _, name := range names {
+			if fix.Lookup(name) == nil {
+				return errors.Newf(token.NoPos, "unknown fix %q", name)
+			}
+		}
+		opts = append(opts, fix.Only(names...))
+	}
 
 	if len(args) == 0 {
 		args = []string{"./..."}
@@ -106,6 +143,18 @@ func runFixAll(cmd *Command, args []string) error {
 				errs = errors.Append(errs, errors.Promote(err, "format"))
 			}
 
+			if flagDryrun.Bool(cmd) {
+				old, err := ioutil.ReadFile(f.Filename)
+				if err != nil {
+					errs = errors.Append(errs, errors.Promote(err, "read"))
+					continue
+				}
+				name, _ := filepath.Rel(cwd, f.Filename)
+				fmt.Fprint(cmd.OutOrStdout(),
+					unifiedDiff(filepath.ToSlash(name), string(old), string(b)))
+				continue
+			}
+
 			err = ioutil.WriteFile(f.Filename, b, 0644)
 			if err != nil {
 				errs = errors.Append(errs, errors.Promote(err, "write"))
@@ -116,6 +165,87 @@ func runFixAll(cmd *Command, args []string) error {
 	return errs
 }
 
+// unifiedDiff reports the changes from a to b, which are the old and new
+// contents of the named file, in unified diff format with three lines of
+// context. It returns the empty string if a and b are equal.
+func unifiedDiff(name, a, b string) string {
+	if a == b {
+		return ""
+	}
+	const context = 3
+
+	type line struct {
+		op   byte // ' ', '-', or '+'
+		text string
+	}
+	var lines []line
+	split := func(s string) []string {
+		return strings.SplitAfter(strings.TrimSuffix(s, "\n"), "\n")
+	}
+	for _, c := range diff.DiffChunks(split(a), split(b)) {
+		for _, s := range c.Deleted {
+			lines = append(lines, line{'-', s})
+		}
+		for _, s := range c.Added {
+			lines = append(lines, line{'+', s})
+		}
+		for _, s := range c.Equal {
+			lines = append(lines, line{' ', s})
+		}
+	}
+
+	w := &strings.Builder{}
+	fmt.Fprintf(w, "--- a/%s\n+++ b/%s\n", name, name)
+
+	// Line numbers of the first line of lines[i] in a and b.
+	aLine, bLine := make([]int, len(lines)+1), make([]int, len(lines)+1)
+	aLine[0], bLine[0] = 1, 1
+	for i, l := range lines {
+		aLine[i+1], bLine[i+1] = aLine[i], bLine[i]
+		if l.op != '+' {
+			aLine[i+1]++
+		}
+		if l.op != '-' {
+			bLine[i+1]++
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		if lines[i].op == ' ' {
+			i++
+			continue
+		}
+		// Extend the hunk until there are more than 2*context equal lines.
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(lines) && j-end <= 2*context; j++ {
+			if lines[j].op != ' ' {
+				end = j + 1
+			}
+		}
+		stop := end + context
+		if stop > len(lines) {
+			stop = len(lines)
+		}
+
+		fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n",
+			aLine[start], aLine[stop]-aLine[start],
+			bLine[start], bLine[stop]-bLine[start])
+		for _, l := range lines[start:stop] {
+			w.WriteByte(l.op)
+			w.WriteString(l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				w.WriteString("\n")
+			}
+		}
+		i = stop
+	}
+	return w.String()
+}
+
 func appendDirs(a []string, base string) []string {
 	_ = filepath.Walk(base, func(path string, fi os.FileInfo, err error) error {
 		if err == nil && fi.IsDir() && path != base {
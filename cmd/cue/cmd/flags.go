@@ -29,6 +29,7 @@ const (
 	flagForce      flagName = "force"
 	flagIgnore     flagName = "ignore"
 	flagStrict     flagName = "strict"
+	flagAnnotate   flagName = "annotate"
 	flagSimplify   flagName = "simplify"
 	flagPackage    flagName = "package"
 	flagInject     flagName = "inject"
@@ -45,10 +46,26 @@ const (
 	flagFiles       flagName = "files"
 	flagProtoPath   flagName = "proto_path"
 	flagProtoEnum   flagName = "proto_enum"
+	flagProtoModule flagName = "proto_module"
 	flagExt         flagName = "ext"
 	flagWithContext flagName = "with-context"
 	flagOut         flagName = "out"
 	flagOutFile     flagName = "outfile"
+
+	flagPreserveLiterals flagName = "preserve-literals"
+
+	flagSecret flagName = "secret"
+
+	flagCache flagName = "cache"
+
+	flagCheck flagName = "check"
+
+	flagKey flagName = "key"
+
+	flagBaseline       flagName = "baseline"
+	flagUpdateBaseline flagName = "update-baseline"
+
+	flagRenameHidden flagName = "rename-hidden"
 )
 
 func addOutFlags(f *pflag.FlagSet, allowNonCUE bool) {
@@ -61,6 +78,11 @@ func addOutFlags(f *pflag.FlagSet, allowNonCUE bool) {
 	f.BoolP(string(flagForce), "f", false, "force overwriting existing files")
 }
 
+func addLiteralFlags(f *pflag.FlagSet) {
+	f.Bool(string(flagPreserveLiterals), false,
+		"write raw, multiline, and bytes literals in CUE output as they were written")
+}
+
 func addGlobalFlags(f *pflag.FlagSet) {
 	f.Bool(string(flagTrace), false,
 		"trace computation")
@@ -75,6 +97,23 @@ func addGlobalFlags(f *pflag.FlagSet) {
 	f.BoolP(string(flagAllErrors), "E", false, "print all available errors")
 }
 
+func addSecretFlags(f *pflag.FlagSet) {
+	f.StringArray(string(flagSecret), nil,
+		"decrypt and encrypt secrets with this provider (run 'cue help secrets' for more info)")
+}
+
+func addCacheFlags(f *pflag.FlagSet) {
+	f.String(string(flagCache), "",
+		"cache results of tasks marked with $cache in this directory or at this HTTP(S) URL (run 'cue help cache' for more info)")
+}
+
+func addBaselineFlags(f *pflag.FlagSet) {
+	f.String(string(flagBaseline), "",
+		"do not report the known errors recorded in this file")
+	f.Bool(string(flagUpdateBaseline), false,
+		"record all errors in the file given by --baseline instead of reporting them")
+}
+
 func addOrphanFlags(f *pflag.FlagSet) {
 	f.StringP(string(flagPackage), "p", "", "package name for non-CUE files")
 	f.StringP(string(flagSchema), "d", "",
@@ -84,10 +123,16 @@ func addOrphanFlags(f *pflag.FlagSet) {
 	f.Bool(string(flagWithContext), false, "import as object with contextual data")
 	f.StringArrayP(string(flagProtoPath), "I", nil, "paths in which to search for imports")
 	f.String(string(flagProtoEnum), "int", "mode for rendering enums (int|json)")
+	f.StringArray(string(flagProtoModule), nil, "Buf Schema Registry module from which to resolve proto imports")
 	f.StringP(string(flagGlob), "n", "", "glob filter for non-CUE file names in directories")
 	f.Bool(string(flagMerge), true, "merge non-CUE files")
 }
 
+func addRenameHiddenFlags(f *pflag.FlagSet) {
+	f.StringArray(string(flagRenameHidden), nil,
+		"output hidden field _old as regular field new, given as _old=new")
+}
+
 func addInjectionFlags(f *pflag.FlagSet, auto bool) {
 	f.StringArrayP(string(flagInject), "t", nil,
 		"set the value of a tagged field")
@@ -102,6 +147,11 @@ func (f flagName) Bool(cmd *Command) bool {
 	return v
 }
 
+func (f flagName) Int(cmd *Command) int {
+	v, _ := cmd.Flags().GetInt(string(f))
+	return v
+}
+
 func (f flagName) String(cmd *Command) string {
 	v, _ := cmd.Flags().GetString(string(f))
 	return v
@@ -15,6 +15,12 @@
 package cmd
 
 import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	"github.com/spf13/cobra"
 
 	"cuelang.org/go/cue/ast"
@@ -29,11 +35,22 @@ import (
 
 func newFmtCmd(c *Command) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "fmt [-s] [inputs]",
+		Use:   "fmt [-s] [--check] [inputs]",
 		Short: "formats CUE configuration files",
 		Long: `Fmt formats the given files or the files for the given packages in place
+
+If the input is -, fmt reads from standard input and writes the formatted
+result to standard output, which allows it to be used as a filter by editors.
+
+With the --check flag, fmt does not modify any files. Instead, it prints a
+diff, in unified format, of each file that is not formatted.
+
+Fmt exits with status 1 if --check is given and a file is not formatted,
+and with status 2 if a file cannot be parsed or another error occurs.
 `,
 		RunE: mkRunE(c, func(cmd *Command, args []string) error {
+			cmd.exitCode = 2
+
 			plan, err := newBuildPlan(cmd, args, &config{loadCfg: &load.Config{
 				Tests:       true,
 				Tools:       true,
@@ -56,6 +73,10 @@ func newFmtCmd(c *Command) *cobra.Command {
 			cfg.Format = opts
 			cfg.Force = true
 
+			check := flagCheck.Bool(cmd)
+			cwd, _ := os.Getwd()
+			unformatted := false
+
 			for _, inst := range builds {
 				if inst.Err != nil {
 					var p *load.PackageError
@@ -80,6 +101,12 @@ func newFmtCmd(c *Command) *cobra.Command {
 						files = append(files, f)
 					}
 
+					cfg := cfg
+					out := &bytes.Buffer{}
+					if check {
+						cfg.Out = out
+					}
+
 					e, err := encoding.NewEncoder(file, &cfg)
 					exitOnErr(cmd, err, true)
 
@@ -88,10 +115,49 @@ func newFmtCmd(c *Command) *cobra.Command {
 						exitOnErr(cmd, err, false)
 					}
 					e.Close()
+
+					if !check {
+						continue
+					}
+					old, err := source(file)
+					if err != nil {
+						exitOnErr(cmd, err, false)
+						continue
+					}
+					name := file.Filename
+					if rel, err := filepath.Rel(cwd, name); err == nil {
+						name = rel
+					}
+					diff := unifiedDiff(filepath.ToSlash(name), string(old), out.String())
+					if diff != "" {
+						fmt.Fprint(cmd.OutOrStdout(), diff)
+						unformatted = true
+					}
 				}
 			}
+
+			// Errors take precedence over unformatted files.
+			if unformatted && !cmd.hasErr {
+				cmd.exitCode = 1
+				cmd.hasErr = true
+			}
 			return nil
 		}),
 	}
+
+	cmd.Flags().Bool(string(flagCheck), false,
+		"print a diff of the files that are not formatted instead of formatting them")
+
 	return cmd
 }
+
+// source returns the original contents of file.
+func source(file *build.File) ([]byte, error) {
+	switch s := file.Source.(type) {
+	case []byte:
+		return s, nil
+	case string:
+		return []byte(s), nil
+	}
+	return ioutil.ReadFile(file.Filename)
+}
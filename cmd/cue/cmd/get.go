@@ -48,5 +48,6 @@ per language and are documented in the respective subcommands.
 		}),
 	}
 	cmd.AddCommand(newGoCmd(c))
+	cmd.AddCommand(newSQLCmd(c))
 	return cmd
 }
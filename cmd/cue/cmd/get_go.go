@@ -23,6 +23,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path"
 	"path/filepath"
 	"reflect"
@@ -74,6 +75,21 @@ are written to a CUE file named foo_go_gen.cue.
 It is safe for users to add additional files to the generated directories,
 as long as their name does not end with _gen.*.
 
+A package may be given with an explicit module version, as in
+
+	cue get go example.com/pkg@v1.2.3
+
+in which case the package is retrieved through the Go module proxy in a
+temporary module, without requiring it to be a dependency of the current
+Go module. Versioned and unversioned packages cannot be mixed in a single
+invocation.
+
+The --types flag restricts the extraction to the types of the given
+packages whose names match any of the given comma-separated regular
+expressions, along with the types of the same package they depend on and
+the constants of the selected types. Packages these types depend on are
+extracted in full.
+
 
 Rules of Converting Go types to CUE
 
@@ -212,6 +228,9 @@ restrictive enum interpretation of #Switch remains.
 	cmd.Flags().StringP(string(flagExclude), "e", "",
 		"comma-separated list of regexps of entries")
 
+	cmd.Flags().String(string(flagTypes), "",
+		"comma-separated list of regexps of types to extract")
+
 	cmd.Flags().Bool(string(flagLocal), false,
 		"generates files in the main module locally")
 
@@ -223,6 +242,7 @@ restrictive enum interpretation of #Switch remains.
 const (
 	flagExclude flagName = "exclude"
 	flagLocal   flagName = "local"
+	flagTypes   flagName = "types"
 )
 
 func (e *extractor) initExclusions(str string) {
@@ -240,7 +260,75 @@ func (e *extractor) filter(name string) bool {
 			return true
 		}
 	}
-	return false
+	return e.selected != nil && !e.selected[name]
+}
+
+// selectedConst reports whether the constant with the given name is to be
+// extracted, which is the case if its type was selected with --types.
+func (e *extractor) selectedConst(name *ast.Ident) bool {
+	if e.selected == nil {
+		return true
+	}
+	n, ok := e.pkg.TypesInfo.TypeOf(name).(*types.Named)
+	return ok && n.Obj().Pkg() == e.pkg.Types && e.selected[n.Obj().Name()]
+}
+
+func (e *extractor) initTypes(str string) {
+	e.types = str
+	for _, re := range strings.Split(str, ",") {
+		if re != "" {
+			e.typePatterns = append(e.typePatterns, regexp.MustCompile(re))
+		}
+	}
+}
+
+// selectTypes returns the names of the types of p that match the type
+// patterns, along with the types of p these depend on. It returns nil if
+// no patterns were given, indicating all types are to be extracted.
+func (e *extractor) selectTypes(p *packages.Package) map[string]bool {
+	if len(e.typePatterns) == 0 {
+		return nil
+	}
+	selected := map[string]bool{}
+	var visit func(t types.Type)
+	visit = func(t types.Type) {
+		switch x := t.(type) {
+		case *types.Named:
+			obj := x.Obj()
+			if obj.Pkg() != p.Types || selected[obj.Name()] {
+				return
+			}
+			selected[obj.Name()] = true
+			visit(x.Underlying())
+		case *types.Pointer:
+			visit(x.Elem())
+		case *types.Slice:
+			visit(x.Elem())
+		case *types.Array:
+			visit(x.Elem())
+		case *types.Map:
+			visit(x.Key())
+			visit(x.Elem())
+		case *types.Struct:
+			for i := 0; i < x.NumFields(); i++ {
+				visit(x.Field(i).Type())
+			}
+		}
+	}
+	scope := p.Types.Scope()
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		for _, re := range e.typePatterns {
+			if re.MatchString(name) {
+				visit(tn.Type())
+				break
+			}
+		}
+	}
+	return selected
 }
 
 type extractor struct {
@@ -262,6 +350,14 @@ type extractor struct {
 
 	exclusions []*regexp.Regexp
 	exclude    string
+
+	typePatterns []*regexp.Regexp
+	types        string
+	selected     map[string]bool // per package; nil means all types
+	roots        map[string]bool // packages given on the command line
+
+	writeFile func(filename string, b []byte) error
+	local     bool
 }
 
 type pkgInfo struct {
@@ -382,12 +478,33 @@ func extract(cmd *Command, args []string) error {
 	// determine module root:
 	binst := loadFromArgs(cmd, []string{"."}, nil)[0]
 
-	if err := initInterfaces(); err != nil {
+	// TODO: require explicitly set root.
+	_, err := extractGo(cmd, binst.Root, args, flagLocal.Bool(cmd), writeGenFile)
+	return err
+}
+
+// writeGenFile writes a generated file, creating its directory if needed.
+func writeGenFile(filename string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
 		return err
 	}
+	return ioutil.WriteFile(filename, b, 0666)
+}
+
+// extractGo converts the Go packages matching args, and their dependencies,
+// to CUE files in the cue.mod/gen directory of the module at root, writing
+// each file with write. If local is set, the files for packages of the main
+// Go module are written to the package directories instead. It returns the
+// packages matching args.
+func extractGo(cmd *Command, root string, args []string, local bool, write func(filename string, b []byte) error) ([]*packages.Package, error) {
+	versioned, err := versionedArgs(args)
+	if err != nil {
+		return nil, err
+	}
 
-	// TODO: require explicitly set root.
-	root := binst.Root
+	if err := initInterfaces(); err != nil {
+		return nil, err
+	}
 
 	cfg := &packages.Config{
 		Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
@@ -395,9 +512,21 @@ func extract(cmd *Command, args []string) error {
 			packages.NeedSyntax | packages.NeedTypesInfo | packages.NeedDeps |
 			packages.NeedModule,
 	}
+	if versioned {
+		dir, err := ioutil.TempDir("", "cuegetgo")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+
+		if args, err = fetchVersioned(dir, args); err != nil {
+			return nil, err
+		}
+		cfg.Dir = dir
+	}
 	pkgs, err := packages.Load(cfg, args...)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	var errs []string
 	for _, p := range pkgs {
@@ -410,30 +539,35 @@ func extract(cmd *Command, args []string) error {
 		}
 	}
 	if len(errs) > 0 {
-		return fmt.Errorf("could not load Go packages:\n%s", strings.Join(errs, "\n"))
+		return nil, fmt.Errorf("could not load Go packages:\n%s", strings.Join(errs, "\n"))
 	}
 
 	e := extractor{
-		cmd:    cmd,
-		stderr: cmd.Stderr(),
-		pkgs:   pkgs,
-		orig:   map[types.Type]*ast.StructType{},
+		cmd:       cmd,
+		stderr:    cmd.Stderr(),
+		pkgs:      pkgs,
+		orig:      map[types.Type]*ast.StructType{},
+		writeFile: write,
+		local:     local,
 	}
 
 	e.initExclusions(flagExclude.String(cmd))
+	e.initTypes(flagTypes.String(cmd))
 
 	e.done = map[string]bool{}
+	e.roots = map[string]bool{}
 
 	for _, p := range pkgs {
 		e.done[p.PkgPath] = true
+		e.roots[p.PkgPath] = true
 	}
 
 	for _, p := range pkgs {
 		if err := e.extractPkg(root, p); err != nil {
-			return err
+			return nil, err
 		}
 	}
-	return nil
+	return pkgs, nil
 }
 
 func (e *extractor) recordTypeInfo(p *packages.Package) {
@@ -454,6 +588,11 @@ func (e *extractor) extractPkg(root string, p *packages.Package) error {
 
 	e.recordTypeInfo(p)
 
+	e.selected = nil
+	if e.roots[p.PkgPath] {
+		e.selected = e.selectTypes(p)
+	}
+
 	e.consts = map[string][]string{}
 
 	for _, f := range p.Syntax {
@@ -468,17 +607,9 @@ func (e *extractor) extractPkg(root string, p *packages.Package) error {
 	pkg := p.PkgPath
 	dir := filepath.Join(load.GenPath(root), filepath.FromSlash(pkg))
 
-	isMain := flagLocal.Bool(e.cmd) && p.Module != nil && p.Module.Main
+	isMain := e.local && p.Module != nil && p.Module.Main
 	if isMain {
-		dir = p.Module.Dir
-		sub := p.PkgPath[len(p.Module.Path):]
-		if sub != "" {
-			dir = filepath.FromSlash(dir + sub)
-		}
-	}
-
-	if err := os.MkdirAll(dir, 0777); err != nil {
-		return err
+		dir = localDir(p)
 	}
 
 	e.usedPkgs = map[string]bool{}
@@ -487,6 +618,9 @@ func (e *extractor) extractPkg(root string, p *packages.Package) error {
 	if e.exclude != "" {
 		args += " --exclude=" + e.exclude
 	}
+	if e.selected != nil {
+		args += " --types=" + e.types
+	}
 
 	for i, f := range p.Syntax {
 		e.cmap = ast.NewCommentMap(p.Fset, f, f.Comments)
@@ -550,7 +684,7 @@ func (e *extractor) extractPkg(root string, p *packages.Package) error {
 		if err != nil {
 			return err
 		}
-		err = ioutil.WriteFile(filepath.Join(dir, file), b, 0666)
+		err = e.writeFile(filepath.Join(dir, file), b)
 		if err != nil {
 			return err
 		}
@@ -575,6 +709,57 @@ func (e *extractor) extractPkg(root string, p *packages.Package) error {
 	return nil
 }
 
+// versionedArgs reports whether the packages in args are given with a module
+// version. It is an error for only some of them to have a version.
+func versionedArgs(args []string) (bool, error) {
+	n := 0
+	for _, a := range args {
+		if strings.Contains(a, "@") {
+			n++
+		}
+	}
+	if n > 0 && n < len(args) {
+		return false, fmt.Errorf("cannot mix versioned and unversioned packages")
+	}
+	return n > 0, nil
+}
+
+// fetchVersioned creates a Go module in dir that requires the given
+// versioned packages, downloading them through the module proxy as needed.
+// It returns the package paths without their versions.
+func fetchVersioned(dir string, args []string) ([]string, error) {
+	goCmd := func(args ...string) error {
+		cmd := exec.Command("go", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("go %s: %v\n%s",
+				strings.Join(args, " "), err, bytes.TrimSpace(out))
+		}
+		return nil
+	}
+	if err := goCmd("mod", "init", "cuelang.org/cuegetgo"); err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(args))
+	for i, a := range args {
+		if err := goCmd("get", a); err != nil {
+			return nil, err
+		}
+		paths[i] = a[:strings.Index(a, "@")]
+	}
+	return paths, nil
+}
+
+// localDir reports the directory of package p of the main module.
+func localDir(p *packages.Package) string {
+	dir := p.Module.Dir
+	sub := p.PkgPath[len(p.Module.Path):]
+	if sub != "" {
+		dir = filepath.FromSlash(dir + sub)
+	}
+	return dir
+}
+
 func (e *extractor) importCUEFiles(p *packages.Package, dir, args string) error {
 	for _, o := range p.CompiledGoFiles {
 		root := filepath.Dir(o)
@@ -608,7 +793,7 @@ func (e *extractor) importCUEFiles(p *packages.Package, dir, args string) error
 				w.Write(b)
 
 				dst := filepath.Join(dir, file)
-				if err := ioutil.WriteFile(dst, w.Bytes(), 0666); err != nil {
+				if err := e.writeFile(dst, w.Bytes()); err != nil {
 					return err
 				}
 			}
@@ -768,7 +953,7 @@ func (e *extractor) reportDecl(x *ast.GenDecl) (a []cueast.Decl) {
 			}
 
 			for i, name := range v.Names {
-				if name.Name == "_" {
+				if name.Name == "_" || !e.selectedConst(name) {
 					continue
 				}
 				f := e.def(v.Doc, name.Name, nil, k == 0)
@@ -0,0 +1,114 @@
+// Copyright 2018 The CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	_ "github.com/lib/pq" // Register the postgres driver for get sql and tool/sql.
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/format"
+	cuesql "cuelang.org/go/encoding/sql"
+)
+
+func newSQLCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sql <driver> <dsn>",
+		Short: "generate CUE definitions from the schema of a SQL database",
+		Long: `sql connects to a database and converts the schema of its tables to
+CUE definitions, which can be used to validate data fixtures and migrations
+against the live schema. The cue tool provides the postgres driver. The
+format of the data source name depends on the driver.
+
+Each table is converted to a definition named after the table in camel case,
+with a field for each column. Columns that are nullable or have a default
+value are optional.
+
+	$ cue get sql postgres 'postgres://localhost/app?sslmode=disable' \
+		-p db --tables users,orders -o db/schema.cue
+	$ cat db/schema.cue
+	package db
+
+	// #Users is a row of table users.
+	#Users: {
+		id?:   int64
+		email: string & strings.MaxRunes(254)
+		name?: string | null
+	}
+	...
+
+The definitions are written to standard output unless --outfile is given.
+`,
+		RunE: mkRunE(c, runGetSQL),
+	}
+
+	cmd.Flags().StringP(string(flagPackage), "p", "", "package name for the generated CUE file")
+	cmd.Flags().String(string(flagSchema), "",
+		"schema, or database for MySQL, of the tables to convert")
+	cmd.Flags().String(string(flagTables), "",
+		"comma-separated list of tables to convert")
+	addOutFlags(cmd.Flags(), false)
+
+	return cmd
+}
+
+const flagTables flagName = "tables"
+
+func runGetSQL(cmd *Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("get sql requires a driver and a data source name")
+	}
+	cfg := &cuesql.Config{
+		PkgName: flagPackage.String(cmd),
+		Schema:  flagSchema.String(cmd),
+	}
+	for _, t := range strings.Split(flagTables.String(cmd), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			cfg.Tables = append(cfg.Tables, t)
+		}
+	}
+
+	db, err := sql.Open(args[0], args[1])
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	f, err := cuesql.Extract(context.Background(), db, cfg)
+	if err != nil {
+		return err
+	}
+	b, err := format.Node(f)
+	if err != nil {
+		return err
+	}
+
+	switch out := flagOutFile.String(cmd); out {
+	case "", "-":
+		_, err = cmd.OutOrStdout().Write(b)
+	default:
+		if _, err := os.Stat(out); err == nil && !flagForce.Bool(cmd) {
+			return fmt.Errorf("file %q already exists; use --force to overwrite", out)
+		}
+		err = ioutil.WriteFile(out, b, 0644)
+	}
+	return err
+}
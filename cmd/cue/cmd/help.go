@@ -27,77 +27,17 @@ import (
 
 func newHelpTopics(c *Command) []*cobra.Command {
 	return []*cobra.Command{
-		inputsHelp,
 		flagsHelp,
 		filetypeHelp,
 		injectHelp,
 		commandsHelp,
+		cacheHelp,
+		secretsHelp,
+		attributesHelp,
+		messagesHelp,
 	}
 }
 
-var inputsHelp = &cobra.Command{
-	Use:   "inputs",
-	Short: "package list, patterns, and files",
-	Long: `Many commands apply to a set of inputs:
-
-cue <command> [inputs]
-
-The list [inputs] may specify CUE packages, CUE files, non-CUE
-files or some combinations of those. An empty list specifies
-the package in the current directory, provided there is a single
-named package in this directory.
-
-CUE packages are specified as an import path. An import path
-that is a rooted path --one that begins with a "." or ".."
-element-- is interpreted as a file system path and denotes the
-package instance in that directory.
-
-Otherwise, the import path P denotes and external package found
-in cue.mod/{pkg|gen|usr}/P.
-
-An import path may contain one or more "..." to match any
-subdirectory: pkg/... matches all packages below pkg, including
-pkg itself, while foo/.../bar matches all directories named bar
-within foo. In all cases, directories containing cue.mod
-directories are excluded from the result.
-
-A package may also be specified as a list of .cue files.
-The special symbol '-' denotes stdin or stdout and defaults to
-the cue file type for stdin. For stdout, the default depends on
-the cue command. A .cue file package may not be combined with
-regular packages.
-
-Non-cue files are interpreted based on their file extension or,
-if present, an explicit file qualifier (see the "filetypes"
-help topic). By default, all recognized files are unified at
-their root value. See the "filetypes" and "flags" help topics
-on how to treat each file individually or how to combine them
-differently.
-
-If a data file has multiple values, such as allowed with JSON
-Lines or YAML, each value is interpreted as a separate file.
-
-If the --schema/-d is specified, data files are not merged, and
-are compared against the specified schema within a package or
-non-data file. For OpenAPI, the -d flag specifies a schema name.
-For JSON Schema the -d flag specifies a schema defined in
-"definitions". In all other cases, the -d flag is a CUE
-expression that is evaluated within the package.
-
-Examples (also see also "flags" and "filetypes" help topics):
-
-# Show the definition of each package named foo for each
-# directory dir under path.
-$ cue def ./path/.../dir:foo
-
-# Unify each document in foo.yaml with the value Foo in pkg.
-$ cue export ./pkg -d Foo foo.yaml
-
-# Unify data.json with schema.json.
-$ cue export data.json schema: schema.json
-`,
-}
-
 var flagsHelp = &cobra.Command{
 	Use:   "flags",
 	Short: "common flags for composing packages",
@@ -185,10 +125,14 @@ var filetypeHelp = &cobra.Command{
     jsonl       .jsonl/.ldjson  Line-separated JSON values.
     jsonschema                  JSON Schema.
     openapi                     OpenAPI schema.
+    helm                        Helm values schema (values.schema.json)
+                                for the definition #Values.
+    terraform   .tf.json        Terraform JSON configuration (output only).
 	pb                          Use Protobuf mappings (e.g. json+pb)
     textproto    .textproto     Text-based protocol buffers.
     proto        .proto         Protocol Buffer definitions.
     go           .go            Go source files.
+    starlark    .bzl/.star      Starlark data literals (output only).
     text         .txt           Raw text file; the evaluated value
                                 must be of type string.
     binary                      Raw binary file; the evaluated value
@@ -236,6 +180,28 @@ defaults as requested.
     graph       Like data, but allow references.
     schema      Export data and definitions.
 
+Tags of the form key=value set options for an encoding. YAML
+output supports the following options:
+
+    Option      Description
+    version=v   Quote strings that YAML v parsers read as other
+                values, where v is 1.1 (the default) or 1.2.
+    quote=all   Quote all string values.
+    indent=n    Indent with n spaces instead of 2.
+    flow=n      Write lists and structs of at most n scalar
+                values in flow style and others in block style.
+                With flow=0, block style is always used.
+
+JSON output supports the following options:
+
+    Option        Description
+    indent=n      Indent with n spaces instead of 4. With
+                  indent=0, the output is written compactly.
+    ascii=true    Escape all non-ASCII characters.
+    newline=false Omit the trailing newline of each value.
+
+HTML characters are only escaped with the --escape flag.
+
 Many commands also support the --out and --outfile/-o flags.
 The --out flag specifies the output type using a qualifier
 (without the ':'). The -o flag specifies an output file
@@ -253,6 +219,9 @@ $ cue def -o openapi+yaml:foo.openapi
 # Print the data for the current package as YAML.
 $ cue export --out=yaml
 
+# Print the data as YAML 1.2, indented with 4 spaces.
+$ cue export --out=yaml+version=1.2+indent=4
+
 # Print the string value of the "name" field as a string.
 $ cue export -e name --out=text
 
@@ -264,6 +233,156 @@ $ cue export -e name -o=text:foo
 `,
 }
 
+var cacheHelp = &cobra.Command{
+	Use:   "cache",
+	Short: "caching of results across invocations",
+	Long: `The export and vet commands can cache their results so that
+repeated invocations on unchanged inputs, as is common in scripts,
+do not redo identical work. The cache is enabled by setting the
+CUE_CACHE environment variable:
+
+   CUE_CACHE=on    cache results in the "cue/results" subdirectory of
+                   the user cache directory (for instance
+                   ~/.cache/cue/results).
+   CUE_CACHE=dir   cache results in the absolute directory dir.
+   CUE_CACHE=off   disable the cache (default).
+
+Results are keyed by the version of the cue tool, the command line,
+the working directory, and the contents of all files loaded by the
+command, including those of imported packages and cue.mod/module.cue.
+Only successful results written to standard output are cached.
+Commands that read from standard input, inject system variables with
+the -T flag, or write to files are never cached. Neither are commands
+that use the --proto_path, --proto_module, or --secret flags, as the
+files these flags refer to are not part of the key.
+
+The cmd command caches the results of tasks that are marked with
+"$cache: true" in the "tasks" subdirectory of this cache. Such results
+are keyed by the value of the task, including the results of the
+tasks it depends on, so a task is rerun only when its inputs change.
+The --cache flag of the cmd command selects a different location,
+which may also be an HTTP(S) URL of a remote cache that supports GET
+and PUT requests, for instance to share results between machines.
+
+The cache directory may be removed at any time.
+`,
+}
+
+var secretsHelp = &cobra.Command{
+	Use:   "secrets",
+	Short: "encrypting and decrypting secret fields",
+	Long: `Fields marked with a secret attribute are encrypted when
+they are exported, so that exported configurations, and the CUE files
+generated from them, can be stored safely alongside other configuration:
+
+	db: password: "hunter2" @secret()
+
+is exported as
+
+	{"db": {"password": "ENC[key,8tlUmDvuZfjo6ILRVKzBVQ...]"}}
+
+The encrypted string records the provider that encrypted the value and
+the ciphertext of its JSON encoding.
+
+Providers are specified with the --secret flag of the export, eval,
+import, and cmd commands, which may be repeated:
+
+   --secret key:FILE      AES-256-GCM with the base64-encoded 32-byte key
+                          in FILE, as generated by
+                          head -c 32 /dev/urandom | base64
+   --secret age:FILE      the age tool with the identity in FILE
+   --secret exec:COMMAND  run COMMAND encrypt or COMMAND decrypt, which
+                          read their input from stdin and write the
+                          result to stdout, for instance to use a KMS
+
+The attribute may name the provider to use, as in @secret(age). By
+default, the first provider is used. The export command reports an error
+for secret fields if no provider is specified.
+
+Encrypted strings in CUE and data files are decrypted when they are
+loaded by these commands, so that they can be used by tool/file and other
+tasks. The fields holding them are marked with a secret attribute, so that
+exporting the result encrypts them again:
+
+	$ cue export --secret key:cue.key config.cue > config.json
+	$ cue import --secret key:cue.key -o - config.json
+	db: password: "hunter2" @secret(key)
+`,
+}
+
+var attributesHelp = &cobra.Command{
+	Use:   "attributes",
+	Short: "declaring the attributes used by a package",
+	Long: `Attributes associate metadata with fields and structs. CUE
+itself ignores attributes it does not know, so that a misspelled
+attribute, such as @protobut(1), is silently ignored by the tools that
+consume them.
+
+A package can declare the attributes it uses with file-level @attribute
+attributes. Once a package declares at least one attribute, any other
+attribute used in the package is reported as an error when it is
+loaded by the cue tool, except for the attributes interpreted by the
+cue tool itself: @attribute, @example, @if, @secret, and @tag.
+
+An attribute declaration names the attribute and may describe its
+arguments and where it may be used:
+
+	@attribute(protobuf, args="int, string?, name=string?", on=field)
+	@attribute(policy, args="strict|lax, ...", on=decl)
+
+The args value lists the types of the positional arguments, followed
+by the keyed arguments as key=type. A type is one of
+
+   string      any value
+   int         an integer
+   number      any number
+   bool        true or false
+   a|b|c       one of the listed values
+
+A type followed by ? denotes an optional argument. A final ... allows
+any further positional or keyed arguments. An attribute declared with
+on=field may only be used as a field attribute, and one declared with
+on=decl only as a declaration within a struct or file. By default, both
+are allowed.
+`,
+}
+
+var messagesHelp = &cobra.Command{
+	Use:   "messages",
+	Short: "printing error messages in another language",
+	Long: `The cue tool can print error messages in another language, or
+with different wording, using a catalog of messages. The catalog is
+read from the CUE or JSON file named by the CUE_MESSAGES environment
+variable. It maps the keys of messages to printf-style format strings:
+
+	"eval.conflict":  "conflit entre les valeurs %s et %s"
+	"eval.closed":    "champ non autorisé : %s"
+	"eval.undefined": "champ non défini : %s"
+
+A format string receives the same arguments as the original message.
+Explicit argument indexes, as in %[2]s, can be used to change their
+order. Messages whose key is not in the catalog are printed as is.
+
+Common evaluation errors have a stable key that does not change when
+the wording of the message changes:
+
+   eval.conflict        conflicting values %s and %s
+   eval.conflict.types  conflicting values %s and %s (mismatched types %s and %s)
+   eval.conflict.type   conflicting value %s (mismatched types %s and %s)
+   eval.closed          field not allowed: %s
+   eval.undefined       undefined field: %s
+   eval.bound           invalid value %v (out of bound %s)
+   eval.validator       invalid value %s (does not satisfy %s)
+   eval.nonconcrete     non-concrete value %v in operand to %s
+   eval.index           index out of range [%d] with length %d
+   eval.divzero         division by zero
+   eval.disjunction     empty disjunction
+   eval.cycle           structural cycle
+
+The key of any other message is its English format string.
+`,
+}
+
 var injectHelp = &cobra.Command{
 	Use:   "injection",
 	Short: "inject files or values into specific fields for a build",
@@ -315,6 +434,7 @@ the flag "-t key=2" modifies the field to
    field: x & 2
 
 Valid values for type are "int", "number", "bool", and "string".
+It is an error to inject a value that is not valid for the type.
 
 A tag attribute can also define shorthand values, which can be
 injected into the fields without having to specify the key. For
@@ -332,6 +452,25 @@ field. For instance
 
 ensures the user may only specify "prod" or "staging".
 
+The "cue inputs" command lists the tags of a build, along with their
+types, defaults, and documentation. Document a tag by documenting
+the field that declares it.
+
+
+Parameterized imports
+
+Tags specified with --inject only apply to the packages named on the
+command line. A package can instantiate an imported package with its
+own tags by adding @tag attributes to the import:
+
+   import (
+       prod "example.com/env" @tag(env=prod, replicas=3)
+       dev  "example.com/env" @tag(dev)
+   )
+
+Each import with a different set of tags results in a separate
+instance of the imported package.
+
 
 Tag variables
 
@@ -382,6 +521,11 @@ rewrites the instance, filling in the completed task, and
 reevaluates which other tasks can now start, and so on until all
 tasks have completed.
 
+Tasks of the tool/trigger package fire on external events, such as
+a timer expiring or a file changing. Each time a trigger fires, the
+tasks that depend on it are run again, so a command with triggers
+keeps running until it is interrupted.
+
 Available tasks can be found in the package documentation at
 
 	https://pkg.go.dev/cuelang.org/go/pkg/tool?tab=subdirectories
@@ -492,6 +636,15 @@ cuelang.org/go/pkg/tool/tool.cue.
 		// long is a longer description that spans multiple lines and
 		// likely contain examples of usage of the command.
 		$long?: string
+
+		// output optionally declares the results of the command. Its fields
+		// mirror the tasks of the command: each field constrains the value of
+		// the task, or group of tasks, of the same name. A task fails if its
+		// value does not satisfy these constraints once it has completed.
+		//
+		// Example:
+		//     $output: ls: stdout: =~"main.go"
+		$output?: {...}
 	}
 
 	// Tasks defines a hierarchy of tasks. A command completes if all
@@ -514,6 +667,12 @@ cuelang.org/go/pkg/tool/tool.cue.
 		// $after can be used to specify a task is run after another one, when
 		// it does not otherwise refer to an output of that task.
 		$after?: Task | [...Task]
+
+		// $cache indicates that the results of the task depend only on its
+		// value, so that they can be reused from a cache, if one is set with
+		// the --cache flag of the cmd command, when the task is run again with
+		// the same inputs.
+		$cache?: bool
 	}
 `,
 }
@@ -17,13 +17,16 @@ package cmd
 import (
 	"fmt"
 	"io/ioutil"
+	"math/big"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 	"unicode"
 
 	"github.com/spf13/cobra"
 
+	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/ast/astutil"
 	"cuelang.org/go/cue/build"
@@ -104,6 +107,31 @@ subdirectories as well all dependencies.
 
 The module root is implicitly added as an import path.
 
+Imports that cannot be found in the import paths may be taken
+from modules of a Buf Schema Registry with the --proto_module
+flag, which may be repeated. Modules are of the form
+remote/owner/repository, optionally followed by :reference,
+and are downloaded once into the user's cache directory. The
+BUF_TOKEN environment variable, if set, authenticates requests.
+Well-known types, like google/protobuf/timestamp.proto, are
+mapped to CUE builtins and need not be available at all.
+
+   cue import proto --proto_module buf.build/googleapis/googleapis ./...
+
+
+JSON Schema mode
+
+With the --annotate flag, JSON Schema is imported with
+@jsonschema attributes recording the order of the keywords of
+the schema and the keywords that have no CUE equivalent, like
+title, format, and examples. Exporting the resulting CUE with
+--out jsonschema then reproduces the original schema, so that
+edits to the CUE result in minimal changes to it.
+
+   cue import --annotate jsonschema schema.json
+   # edit schema.cue
+   cue export --out jsonschema schema.cue > schema.json
+
 
 Binary mode
 
@@ -221,6 +249,42 @@ Examples:
   }]
 
 
+Schema-guided import
+
+The -d/--schema flag selects a schema from the CUE files passed along with
+the data files against which the imported data is interpreted. Data formats
+like JSON and YAML cannot express all of CUE's types: 1 and 1.0 are the same
+number in JSON, and whether an unquoted YAML scalar is a string, number, or
+boolean is determined by its syntax alone. Where the schema does not accept a
+literal as is, import tries the alternative interpretations of its text and
+uses the first the schema accepts. For instance, a YAML value 1.10 becomes
+the string "1.10" if the schema expects a string, 3 becomes 3.0 for a float
+field, a quoted "8080" becomes a number for an int field, and YAML
+timestamps are converted to RFC 3339 for fields constrained by time.Time.
+The imported data must validate against the schema.
+
+Example:
+  $ cat <<EOF > schema.cue
+  #Config: {
+      version: string
+      ratio:   float
+      port:    int
+  }
+  EOF
+
+  $ cat <<EOF > config.yaml
+  version: 1.10
+  ratio: 2
+  port: "8080"
+  EOF
+
+  $ cue import -d '#Config' schema.cue config.yaml
+  $ cat config.cue
+  version: "1.10"
+  ratio:   2.0
+  port:    8080
+
+
 Embedded data files
 
 The --recursive or -R flag enables the parsing of fields that are string
@@ -259,6 +323,9 @@ Example:
 	cmd.Flags().Bool(string(flagDryrun), false, "only run simulation")
 	cmd.Flags().BoolP(string(flagRecursive), "R", false, "recursively parse string values")
 	cmd.Flags().StringArray(string(flagExt), nil, "match files with these extensions")
+	cmd.Flags().Bool(string(flagAnnotate), false,
+		"record the details of JSON Schema needed to export it again")
+	addSecretFlags(cmd.Flags())
 
 	return cmd
 }
@@ -366,6 +433,13 @@ func protoMode(b *buildPlan) error {
 		PkgName:  b.encConfig.PkgName,
 		EnumMode: flagProtoEnum.String(b.cmd),
 	}
+	for _, name := range flagProtoModule.StringArray(b.cmd) {
+		m, err := protobuf.ParseBSRModule(name)
+		if err != nil {
+			return err
+		}
+		c.Resolvers = append(c.Resolvers, m)
+	}
 	if module != "" {
 		// We only allow imports from packages within the module if an actual
 		// module is allowed.
@@ -472,6 +546,12 @@ func handleFile(b *buildPlan, f *ast.File) (err error) {
 		return err
 	}
 
+	if b.secrets != nil {
+		if err := b.secrets.DecryptFile(f); err != nil {
+			return err
+		}
+	}
+
 	if flagRecursive.Bool(b.cmd) {
 		h := hoister{fields: map[string]bool{}}
 		h.hoist(f)
@@ -613,3 +693,162 @@ func (h *hoister) uniqueName(base, prefix, typ string) string {
 		typ += "x"
 	}
 }
+
+// yamlTimestampFormats are the timestamp formats recognized by YAML.
+var yamlTimestampFormats = []string{
+	"2006-1-2T15:4:5.999999999Z07:00",
+	"2006-1-2t15:4:5.999999999Z07:00",
+	"2006-1-2 15:4:5.999999999",
+	"2006-1-2",
+}
+
+// schemaTyper converts literals in imported data to the types expected by a
+// schema. Data formats like JSON and YAML cannot express all of CUE's types:
+// 1 and 1.0 are the same number in JSON, and whether an unquoted YAML scalar
+// is a string, number, or boolean is inferred from its syntax. Where the
+// schema does not accept a literal as is, the typer tries the alternative
+// interpretations of its text in turn and picks the first that the schema
+// accepts.
+type schemaTyper struct {
+	ctx *cue.Context
+}
+
+// applySchema rewrites the literals in f to match schema and reports an error
+// if the result does not validate against schema.
+func applySchema(f *ast.File, schema cue.Value) error {
+	t := &schemaTyper{ctx: schema.Context()}
+	t.decls(f.Decls, schema)
+
+	v := t.ctx.BuildFile(f)
+	if err := v.Err(); err != nil {
+		return err
+	}
+	return schema.Unify(v).Validate()
+}
+
+func (t *schemaTyper) decls(decls []ast.Decl, schema cue.Value) {
+	for _, d := range decls {
+		switch x := d.(type) {
+		case *ast.Field:
+			switch x.Label.(type) {
+			case *ast.Ident, *ast.BasicLit:
+			default:
+				continue
+			}
+			sel := cue.Label(x.Label)
+			s := schema.LookupPath(cue.MakePath(sel))
+			if !s.Exists() && !sel.IsDefinition() {
+				s = schema.LookupPath(cue.MakePath(cue.AnyString))
+			}
+			if s.Exists() {
+				x.Value = t.expr(x.Value, s)
+			}
+
+		case *ast.EmbedDecl:
+			x.Expr = t.expr(x.Expr, schema)
+		}
+	}
+}
+
+func (t *schemaTyper) expr(x ast.Expr, schema cue.Value) ast.Expr {
+	switch x := x.(type) {
+	case *ast.StructLit:
+		t.decls(x.Elts, schema)
+		return x
+
+	case *ast.ListLit:
+		for i, e := range x.Elts {
+			s := schema.LookupPath(cue.MakePath(cue.Index(i)))
+			if !s.Exists() {
+				s = schema.LookupPath(cue.MakePath(cue.AnyIndex))
+			}
+			if s.Exists() {
+				x.Elts[i] = t.expr(e, s)
+			}
+		}
+		return x
+	}
+
+	text, isString, ok := literalText(x)
+	if !ok || t.accepts(schema, x) {
+		return x
+	}
+	for _, alt := range alternatives(text, isString) {
+		if t.accepts(schema, alt) {
+			ast.SetPos(alt, x.Pos())
+			ast.SetComments(alt, ast.Comments(x))
+			return alt
+		}
+	}
+	return x
+}
+
+// accepts reports whether x is a valid instance of schema.
+func (t *schemaTyper) accepts(schema cue.Value, x ast.Expr) bool {
+	v := t.ctx.BuildExpr(x)
+	if v.Err() != nil {
+		return false
+	}
+	return schema.Unify(v).Validate() == nil
+}
+
+// literalText reports the text of a scalar literal and whether it is a
+// string.
+func literalText(x ast.Expr) (text string, isString, ok bool) {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		switch x.Kind {
+		case token.STRING:
+			s, err := literal.Unquote(x.Value)
+			if err != nil {
+				return "", false, false
+			}
+			return s, true, true
+		case token.INT, token.FLOAT:
+			return x.Value, false, true
+		}
+
+	case *ast.UnaryExpr:
+		if b, ok := x.X.(*ast.BasicLit); ok && x.Op == token.SUB {
+			return "-" + b.Value, false, true
+		}
+
+	case *ast.Ident:
+		switch x.Name {
+		case "true", "false", "null":
+			return x.Name, false, true
+		}
+	}
+	return "", false, false
+}
+
+// alternatives reports the possible interpretations of the given literal
+// text, in order of preference.
+func alternatives(text string, isString bool) []ast.Expr {
+	var a []ast.Expr
+	if r, ok := new(big.Rat).SetString(text); ok {
+		if r.IsInt() {
+			a = append(a, ast.NewLit(token.INT, r.Num().String()))
+		}
+		f := text
+		if !strings.ContainsAny(f, ".eE") || strings.HasPrefix(f, "0x") {
+			f = r.FloatString(1)
+		}
+		a = append(a, ast.NewLit(token.FLOAT, f))
+	}
+	switch text {
+	case "true", "false":
+		a = append(a, ast.NewBool(text == "true"))
+	}
+	if !isString {
+		a = append(a, ast.NewString(text))
+		return a
+	}
+	for _, layout := range yamlTimestampFormats {
+		if t, err := time.Parse(layout, text); err == nil {
+			a = append(a, ast.NewString(t.Format(time.RFC3339Nano)))
+			break
+		}
+	}
+	return a
+}
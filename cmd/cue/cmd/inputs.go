@@ -0,0 +1,209 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/load"
+)
+
+func newInputsCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inputs [inputs]",
+		Short: "list injectable tags; package list, patterns, and files",
+		Long: `Inputs lists the tags declared by the given inputs: the fields into
+which values can be injected with the --inject/-t flag, as explained
+in the "injection" help topic. For each tag it prints its name, the
+type as which injected values are interpreted, its default, and the
+documentation of its field:
+
+	$ cat deploy/deploy.cue
+	package deploy
+
+	// env is the environment to deploy to.
+	env: *"dev" | "prod" @tag(env,short=dev|prod)
+
+	// replicas is the number of replicas.
+	replicas: int & >0 @tag(replicas,type=int)
+
+	user: string @tag(user,var=username)
+
+	$ cue inputs ./deploy
+	NAME      TYPE    DEFAULT     DESCRIPTION
+	env       string  "dev"       env is the environment to deploy to.
+	                              Shorthands: dev, prod.
+	replicas  int     (required)  replicas is the number of replicas.
+	user      string  -T username
+
+A tag without a default must be set with -t unless it refers to a
+variable, which is injected with the --inject-vars/-T flag.
+
+
+Specifying inputs
+
+Many commands apply to a set of inputs:
+
+cue <command> [inputs]
+
+The list [inputs] may specify CUE packages, CUE files, non-CUE
+files or some combinations of those. An empty list specifies
+the package in the current directory, provided there is a single
+named package in this directory.
+
+CUE packages are specified as an import path. An import path
+that is a rooted path --one that begins with a "." or ".."
+element-- is interpreted as a file system path and denotes the
+package instance in that directory.
+
+Otherwise, the import path P denotes and external package found
+in cue.mod/{pkg|gen|usr}/P.
+
+An import path may contain one or more "..." to match any
+subdirectory: pkg/... matches all packages below pkg, including
+pkg itself, while foo/.../bar matches all directories named bar
+within foo. In all cases, directories containing cue.mod
+directories are excluded from the result.
+
+A package may also be specified as a list of .cue files.
+The special symbol '-' denotes stdin or stdout and defaults to
+the cue file type for stdin. For stdout, the default depends on
+the cue command. A .cue file package may not be combined with
+regular packages.
+
+A file may also be specified as an https URL, such as
+https://example.com/schema.cue. Remote files are cached and
+revalidated each time they are used. A URL ending in
+#sha256=<hex> pins the contents of the file to the given
+checksum; a pinned file is used from the cache without contacting
+the server. The CUE_REMOTE_ALLOW environment variable restricts
+the URLs from which files may be loaded to a comma-separated list
+of prefixes. Plain http URLs are only allowed if they are listed.
+
+Non-cue files are interpreted based on their file extension or,
+if present, an explicit file qualifier (see the "filetypes"
+help topic). By default, all recognized files are unified at
+their root value. See the "filetypes" and "flags" help topics
+on how to treat each file individually or how to combine them
+differently.
+
+If a data file has multiple values, such as allowed with JSON
+Lines or YAML, each value is interpreted as a separate file.
+
+If the --schema/-d is specified, data files are not merged, and
+are compared against the specified schema within a package or
+non-data file. For OpenAPI, the -d flag specifies a schema name.
+For JSON Schema the -d flag specifies a schema defined in
+"definitions". In all other cases, the -d flag is a CUE
+expression that is evaluated within the package.
+
+Examples (also see also "flags" and "filetypes" help topics):
+
+# Show the definition of each package named foo for each
+# directory dir under path.
+$ cue def ./path/.../dir:foo
+
+# Unify each document in foo.yaml with the value Foo in pkg.
+$ cue export ./pkg -d Foo foo.yaml
+
+# Unify data.json with schema.json.
+$ cue export data.json schema: schema.json
+`,
+		RunE: mkRunE(c, runInputs),
+	}
+	return cmd
+}
+
+func runInputs(cmd *Command, args []string) error {
+	binst := loadFromArgs(cmd, args, &load.Config{})
+	if binst == nil {
+		return nil
+	}
+	rows := [][4]string{{"NAME", "TYPE", "DEFAULT", "DESCRIPTION"}}
+
+	seen := map[string]bool{}
+	insts := buildInstances(cmd, binst)
+	for i, b := range binst {
+		tags, err := load.Tags(b)
+		exitOnErr(cmd, err, true)
+		v := insts[i].Value()
+		for _, t := range tags {
+			if seen[t.Name] {
+				continue
+			}
+			seen[t.Name] = true
+
+			doc := strings.Split(t.Doc, "\n")
+			if len(t.Shorthands) > 0 {
+				doc = append(doc, fmt.Sprintf("Shorthands: %s.",
+					strings.Join(t.Shorthands, ", ")))
+			}
+			rows = append(rows, [4]string{
+				t.Name, t.Kind.String(), tagDefault(v.LookupPath(t.Path), t), doc[0],
+			})
+			for _, line := range doc[1:] {
+				rows = append(rows, [4]string{3: line})
+			}
+		}
+	}
+
+	// Truncate descriptions that do not fit on a line of the terminal.
+	descWidth := 0
+	if c := detectConsole(cmd.OutOrStdout()); c.width > 0 {
+		descWidth = c.width
+		for col := 0; col < 3; col++ {
+			n := 0
+			for _, r := range rows {
+				if m := utf8.RuneCountInString(r[col]); m > n {
+					n = m
+				}
+			}
+			descWidth -= n + 2 // padding of tabwriter
+		}
+		if descWidth < 10 {
+			descWidth = 0 // too narrow to be useful
+		}
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	for _, r := range rows {
+		line := strings.Join(r[:3], "\t")
+		if r[3] != "" {
+			line += "\t" + truncate(r[3], descWidth)
+		}
+		fmt.Fprintln(w, line)
+	}
+	return w.Flush()
+}
+
+// tagDefault describes the value of a tagged field if no value is injected.
+func tagDefault(v cue.Value, t *load.Tag) string {
+	if d, ok := v.Default(); ok && d.IsConcrete() {
+		return fmt.Sprint(d)
+	}
+	if v.IsConcrete() {
+		return fmt.Sprint(v)
+	}
+	if t.Var != "" {
+		return "-T " + t.Var
+	}
+	return "(required)"
+}
@@ -23,6 +23,12 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
 )
 
 func newModCmd(c *Command) *cobra.Command {
@@ -44,7 +50,13 @@ func newModCmd(c *Command) *cobra.Command {
 		}),
 	}
 
+	cmd.AddCommand(newModGraphCmd(c))
 	cmd.AddCommand(newModInitCmd(c))
+	cmd.AddCommand(newModKeygenCmd(c))
+	cmd.AddCommand(newModRenameCmd(c))
+	cmd.AddCommand(newModSignCmd(c))
+	cmd.AddCommand(newModVerifyCmd(c))
+	cmd.AddCommand(newModWhyCmd(c))
 	return cmd
 }
 
@@ -85,12 +97,8 @@ func runModInit(cmd *Command, args []string) (err error) {
 			return fmt.Errorf("too many arguments")
 		}
 		module = args[0]
-		u, err := url.Parse("https://" + module)
-		if err != nil {
-			return fmt.Errorf("invalid module name: %v", module)
-		}
-		if h := u.Hostname(); !strings.Contains(h, ".") {
-			return fmt.Errorf("invalid host name %s", h)
+		if err := checkModulePath(module); err != nil {
+			return err
 		}
 	}
 
@@ -140,6 +148,18 @@ func runModInit(cmd *Command, args []string) (err error) {
 	return err
 }
 
+// checkModulePath reports an error if module is not a valid module path.
+func checkModulePath(module string) error {
+	u, err := url.Parse("https://" + module)
+	if err != nil {
+		return fmt.Errorf("invalid module name: %v", module)
+	}
+	if h := u.Hostname(); !strings.Contains(h, ".") {
+		return fmt.Errorf("invalid host name %s", h)
+	}
+	return nil
+}
+
 // backport backports an old cue.mod setup to a new one.
 func backport(mod, cwd string) error {
 	tmp := filepath.Join(cwd, fmt.Sprintf("_%x_cue.mod", rand.Int()))
@@ -173,3 +193,43 @@ func backport(mod, cwd string) error {
 
 	return nil
 }
+
+// findModuleRoot returns the closest ancestor of dir, including dir itself,
+// that contains a cue.mod directory.
+func findModuleRoot(dir string) (string, error) {
+	for {
+		if fi, err := os.Stat(filepath.Join(dir, "cue.mod")); err == nil && fi.IsDir() {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("no module root found")
+		}
+		dir = parent
+	}
+}
+
+// parseModulePath returns the module path defined by the module file with
+// the given name and contents, along with the literal defining it.
+func parseModulePath(filename string, src []byte) (string, *ast.BasicLit, error) {
+	f, err := parser.ParseFile(filename, src)
+	if err != nil {
+		return "", nil, err
+	}
+	var lit *ast.BasicLit
+	for _, d := range f.Decls {
+		if x, ok := d.(*ast.Field); ok {
+			if name, _, _ := ast.LabelName(x.Label); name == "module" {
+				lit, _ = x.Value.(*ast.BasicLit)
+			}
+		}
+	}
+	if lit == nil || lit.Kind != token.STRING {
+		return "", nil, errors.Newf(token.NoPos, "%s: no module path defined", filename)
+	}
+	path, err := literal.Unquote(lit.Value)
+	if err != nil {
+		return "", nil, errors.Newf(lit.Pos(), "invalid module path: %v", err)
+	}
+	return path, lit, nil
+}
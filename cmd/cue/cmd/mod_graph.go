@@ -0,0 +1,276 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+)
+
+func newModGraphCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "print the module dependency graph",
+		Long: `Graph prints the dependency graph of the current module. Each line
+holds two space-separated module paths: a module and one of the modules
+whose packages it imports.
+
+Dependencies are the packages in the cue.mod/pkg, cue.mod/gen, and
+cue.mod/usr directories. A dependency belongs to the closest enclosing
+directory with a cue.mod/module.cue file that defines its module path. A
+package without such a directory is reported as a module by itself.
+`,
+		RunE: mkRunE(c, runModGraph),
+	}
+	return cmd
+}
+
+func newModWhyCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "why <module> ...",
+		Short: "explain why modules are needed",
+		Long: `Why shows, for each of the given modules, the shortest chain of
+imports from a package of the current module to a package of the given
+module. Modules are determined as for "cue mod graph".
+
+	$ cue mod why example.com/k8s
+	# example.com/k8s
+	example.com/app/deploy
+	example.com/k8s/apps
+
+If a module is not needed, this is reported instead of an import chain.
+`,
+		RunE: mkRunE(c, runModWhy),
+	}
+	return cmd
+}
+
+func runModGraph(cmd *Command, args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf("mod graph takes no arguments")
+	}
+	g, err := loadModGraph(cmd)
+	if err != nil {
+		return err
+	}
+
+	edges := map[[2]string]bool{}
+	for _, p := range g.pkgs {
+		from := g.moduleOf(p)
+		for _, imp := range p.Imports {
+			if to := g.moduleOf(imp); to != from {
+				edges[[2]string{from, to}] = true
+			}
+		}
+	}
+	lines := make([]string, 0, len(edges))
+	for e := range edges {
+		lines = append(lines, e[0]+" "+e[1])
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		mi := strings.HasPrefix(lines[i], g.main+" ")
+		mj := strings.HasPrefix(lines[j], g.main+" ")
+		if mi != mj {
+			return mi
+		}
+		return lines[i] < lines[j]
+	})
+
+	w := cmd.OutOrStdout()
+	for _, l := range lines {
+		fmt.Fprintln(w, l)
+	}
+	return nil
+}
+
+func runModWhy(cmd *Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("mod why requires at least one module path")
+	}
+	g, err := loadModGraph(cmd)
+	if err != nil {
+		return err
+	}
+
+	// Find the shortest import chains from the packages of the main module
+	// with a breadth-first search.
+	parent := map[*build.Instance]*build.Instance{}
+	var queue []*build.Instance
+	for _, p := range g.pkgs {
+		if g.moduleOf(p) == g.main {
+			parent[p] = nil
+			queue = append(queue, p)
+		}
+	}
+	sort.Slice(queue, func(i, j int) bool {
+		return queue[i].ImportPath < queue[j].ImportPath
+	})
+	var order []*build.Instance
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		order = append(order, p)
+		for _, imp := range p.Imports {
+			if _, ok := parent[imp]; !ok {
+				parent[imp] = p
+				queue = append(queue, imp)
+			}
+		}
+	}
+
+	w := cmd.OutOrStdout()
+	for i, m := range args {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "# %s\n", m)
+		var target *build.Instance
+		for _, p := range order {
+			if g.moduleOf(p) == m {
+				target = p
+				break
+			}
+		}
+		if target == nil {
+			fmt.Fprintf(w, "(main module does not need module %s)\n", m)
+			continue
+		}
+		var chain []string
+		for p := target; p != nil; p = parent[p] {
+			chain = append(chain, p.ImportPath)
+		}
+		for i := len(chain) - 1; i >= 0; i-- {
+			fmt.Fprintln(w, chain[i])
+		}
+	}
+	return nil
+}
+
+// A modGraph holds the packages of the current module and all packages
+// they import, directly or indirectly.
+type modGraph struct {
+	root string // root directory of the current module
+	main string // path of the current module
+	pkgs []*build.Instance
+
+	modules map[string]string // directory to module path
+}
+
+// loadModGraph loads all packages of the module containing the current
+// directory.
+func loadModGraph(cmd *Command) (*modGraph, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	root, err := findModuleRoot(cwd)
+	if err != nil {
+		return nil, err
+	}
+	modFile := filepath.Join(root, "cue.mod", "module.cue")
+	src, err := ioutil.ReadFile(modFile)
+	if err != nil {
+		return nil, err
+	}
+	main, lit, err := parseModulePath(modFile, src)
+	if err != nil {
+		return nil, err
+	}
+	if main == "" {
+		return nil, errors.Newf(lit.Pos(), "module path is empty")
+	}
+
+	g := &modGraph{root: root, main: main, modules: map[string]string{}}
+	insts := load.Instances([]string{"./..."}, &load.Config{
+		Dir:   root,
+		Tests: true,
+		Tools: true,
+	})
+	seen := map[*build.Instance]bool{}
+	var add func(insts []*build.Instance) error
+	add = func(insts []*build.Instance) error {
+		for _, p := range insts {
+			if seen[p] {
+				continue
+			}
+			seen[p] = true
+			if p.Err != nil {
+				return p.Err
+			}
+			g.pkgs = append(g.pkgs, p)
+			if err := add(p.Imports); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := add(insts); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// moduleOf reports the path of the module to which package p belongs.
+func (g *modGraph) moduleOf(p *build.Instance) string {
+	importPath := p.ImportPath
+	if i := strings.LastIndexByte(importPath, ':'); i > 0 {
+		importPath = importPath[:i]
+	}
+	// The files of a dependency may be spread over the pkg, gen, and usr
+	// directories, in which case Dir only reports one of them.
+	pkgDir := p.Dir
+	if len(p.BuildFiles) > 0 {
+		pkgDir = filepath.Dir(p.BuildFiles[0].Filename)
+	}
+	for _, kind := range []string{"pkg", "gen", "usr"} {
+		base := filepath.Join(g.root, "cue.mod", kind)
+		rel, err := filepath.Rel(base, pkgDir)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		for dir := pkgDir; dir != base; dir = filepath.Dir(dir) {
+			if m := g.modulePath(dir); m != "" {
+				return m
+			}
+		}
+		return importPath
+	}
+	return g.main
+}
+
+// modulePath reports the module path defined in the cue.mod directory of
+// dir, if any.
+func (g *modGraph) modulePath(dir string) string {
+	if m, ok := g.modules[dir]; ok {
+		return m
+	}
+	m := ""
+	modFile := filepath.Join(dir, "cue.mod", "module.cue")
+	if src, err := ioutil.ReadFile(modFile); err == nil {
+		m, _, _ = parseModulePath(modFile, src)
+	}
+	g.modules[dir] = m
+	return m
+}
@@ -0,0 +1,257 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+)
+
+func newModRenameCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename <module>",
+		Short: "change the path of the current module",
+		Long: `Rename changes the path of the module containing the current
+directory to the given path. The module field in cue.mod/module.cue is
+updated and all imports of packages within the module are rewritten to use
+the new path. Files are otherwise left untouched.
+
+If the last element of an import path changes and the import does not
+specify a package name, the old package name is added to the import path,
+so that references to the package remain valid:
+
+	import "example.com/foo/schema"
+
+becomes, after renaming the module to example.com/bar/schemas,
+
+	import "example.com/bar/schemas:schema"
+
+Rename reports other uses of the old module path that it cannot update,
+such as strings that mention it, with positions relative to the module
+root. Packages in other modules that import
+the module must be updated separately.
+
+Directories starting with . or _, as well as nested modules and the
+cue.mod directory, are not modified.
+`,
+		RunE: mkRunE(c, runModRename),
+	}
+	return cmd
+}
+
+func runModRename(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("mod rename requires a single module path")
+	}
+	newPath := args[0]
+	if err := checkModulePath(newPath); err != nil {
+		return err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	root, err := findModuleRoot(cwd)
+	if err != nil {
+		return err
+	}
+
+	modFile := filepath.Join(root, "cue.mod", "module.cue")
+	src, err := ioutil.ReadFile(modFile)
+	if err != nil {
+		return err
+	}
+	oldPath, lit, err := parseModulePath(modFile, src)
+	if err != nil {
+		return err
+	}
+	if oldPath == "" {
+		return errors.Newf(lit.Pos(), "module path is empty")
+	}
+	if oldPath == newPath {
+		return nil
+	}
+
+	r := &moduleRenamer{
+		root:    root,
+		oldPath: oldPath,
+		newPath: newPath,
+		files: map[string][]byte{
+			modFile: replaceLits(src, []litEdit{{lit, strconv.Quote(newPath)}}),
+		},
+	}
+	if err := filepath.Walk(root, r.walk); err != nil {
+		return err
+	}
+	for _, dir := range []string{"gen", "pkg", "usr"} {
+		dir = filepath.Join(root, "cue.mod", dir, filepath.FromSlash(oldPath))
+		if _, err := os.Stat(dir); err == nil {
+			r.warnf("%s: directory is named after the old module path",
+				relPath(root, dir))
+		}
+	}
+
+	names := make([]string, 0, len(r.files))
+	for name := range r.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := ioutil.WriteFile(name, r.files[name], 0644); err != nil {
+			return err
+		}
+	}
+
+	stderr := cmd.OutOrStderr()
+	for _, w := range r.warnings {
+		fmt.Fprintln(stderr, w)
+	}
+	fmt.Fprintf(stderr, "renamed module %s to %s; modules importing %s must be updated\n",
+		oldPath, newPath, oldPath)
+	return nil
+}
+
+// A moduleRenamer computes the changes to the files of a module needed to
+// change its path from oldPath to newPath.
+type moduleRenamer struct {
+	root    string
+	oldPath string
+	newPath string
+
+	files    map[string][]byte // new contents of modified files
+	warnings []string
+}
+
+func (r *moduleRenamer) warnf(format string, args ...interface{}) {
+	r.warnings = append(r.warnings, fmt.Sprintf(format, args...))
+}
+
+func (r *moduleRenamer) walk(path string, info os.FileInfo, err error) error {
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		if path == r.root {
+			return nil
+		}
+		name := info.Name()
+		if name == "cue.mod" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(path, "cue.mod")); err == nil {
+			return filepath.SkipDir // nested module
+		}
+		return nil
+	}
+	if filepath.Ext(path) != ".cue" {
+		return nil
+	}
+
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	f, err := parser.ParseFile(path, src)
+	if err != nil {
+		return err
+	}
+
+	var edits []litEdit
+	imports := map[*ast.BasicLit]bool{}
+	for _, spec := range f.Imports {
+		imports[spec.Path] = true
+		info, err := astutil.ParseImportSpec(spec)
+		if err != nil {
+			continue
+		}
+		if p, ok := r.rename(info); ok {
+			edits = append(edits, litEdit{spec.Path, strconv.Quote(p)})
+		}
+	}
+	if len(edits) > 0 {
+		r.files[path] = replaceLits(src, edits)
+	}
+
+	ast.Walk(f, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.BasicLit:
+			if x.Kind == token.STRING && !imports[x] &&
+				strings.Contains(x.Value, r.oldPath) {
+				r.warnf("%s: string mentions the old module path", r.pos(x.Pos()))
+			}
+		case *ast.Attribute:
+			if strings.Contains(x.Text, r.oldPath) {
+				r.warnf("%s: attribute mentions the old module path", r.pos(x.Pos()))
+			}
+		}
+		return true
+	}, nil)
+	return nil
+}
+
+// rename reports the path with which to import the package described by
+// info after renaming the module, or false if the package does not belong
+// to the module.
+func (r *moduleRenamer) rename(info astutil.ImportInfo) (string, bool) {
+	dir := info.Dir
+	if dir != r.oldPath && !strings.HasPrefix(dir, r.oldPath+"/") {
+		return "", false
+	}
+	p := r.newPath + strings.TrimPrefix(dir, r.oldPath)
+	if info.ID != info.Dir || astutil.ImportPathName(p) != info.PkgName {
+		p += ":" + info.PkgName
+	}
+	return p, true
+}
+
+func (r *moduleRenamer) pos(p token.Pos) string {
+	return relPos(r.root, p)
+}
+
+// A litEdit replaces the source of a literal.
+type litEdit struct {
+	lit   *ast.BasicLit
+	value string
+}
+
+// replaceLits returns src with the given literals replaced.
+func replaceLits(src []byte, edits []litEdit) []byte {
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].lit.Pos().Offset() < edits[j].lit.Pos().Offset()
+	})
+	var b []byte
+	last := 0
+	for _, e := range edits {
+		start := e.lit.Pos().Offset()
+		b = append(b, src[last:start]...)
+		b = append(b, e.value...)
+		last = start + len(e.lit.Value)
+	}
+	return append(b, src[last:]...)
+}
@@ -0,0 +1,232 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/modsign"
+)
+
+const trustDoc = `Modules placed in cue.mod/pkg can be required to be signed by a trusted
+key. The trust field of cue.mod/module.cue lists, for patterns of module
+paths, the public keys that are trusted to sign these modules:
+
+	module: "example.com/app"
+
+	trust: [{
+		module: "example.com/schemas/..."
+		keys: ["RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"]
+	}]
+
+A pattern is a module path, which may end in /... to match all modules
+below it, or ... to match all modules. The path of a module in cue.mod/pkg
+is its location relative to that directory. Loading a package of a module
+that matches a pattern fails unless the module has a valid signature from
+one of the keys of the matching rules. Other modules need not be signed.
+`
+
+func newModKeygenCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keygen <name>",
+		Short: "generate a key pair for signing modules",
+		Long: `Keygen generates a key pair for signing modules. The secret key is
+written to <name>.key and the public key to <name>.pub. The public key is
+also printed, so that it can be added to the trust policy of modules that
+depend on the signed modules.
+
+Keys use Ed25519, and public keys use the format of minisign. The secret
+key is not encrypted and should be kept private.
+`,
+		RunE: mkRunE(c, runModKeygen),
+	}
+
+	cmd.Flags().BoolP(string(flagForce), "f", false, "overwrite existing key files")
+
+	return cmd
+}
+
+func runModKeygen(cmd *Command, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("mod keygen requires a single name")
+	}
+	name := args[0]
+
+	pub, sec, err := modsign.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	for _, f := range []string{name + ".key", name + ".pub"} {
+		if _, err := os.Stat(f); err == nil && !flagForce.Bool(cmd) {
+			return fmt.Errorf("%s already exists; use --force to overwrite", f)
+		}
+	}
+	if err := ioutil.WriteFile(name+".key", sec.Marshal(), 0600); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(name+".pub", pub.Marshal(), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), pub)
+	return nil
+}
+
+func newModSignCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign --key <file>",
+		Short: "sign the current module",
+		Long: `Sign signs the module containing the current directory with the
+secret key in the given file, as generated by cue mod keygen. The
+signature covers the module path and the contents of all files of the
+module, except for the dependencies in cue.mod/pkg, version control
+directories, and files starting with a dot. Modules containing symbolic
+links cannot be signed. The signature is written to cue.mod/module.sig and
+must be recreated whenever the module changes.
+
+` + trustDoc,
+		RunE: mkRunE(c, runModSign),
+	}
+
+	cmd.Flags().String(string(flagKey), "", "file holding the secret key")
+
+	return cmd
+}
+
+func runModSign(cmd *Command, args []string) error {
+	keyFile := flagKey.String(cmd)
+	if keyFile == "" {
+		return fmt.Errorf("mod sign requires a key; use --%s", flagKey)
+	}
+	data, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return err
+	}
+	key, err := modsign.ParseSecretKey(data)
+	if err != nil {
+		return fmt.Errorf("%s: %v", keyFile, err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	root, err := findModuleRoot(cwd)
+	if err != nil {
+		return err
+	}
+	modFile := filepath.Join(root, "cue.mod", "module.cue")
+	src, err := ioutil.ReadFile(modFile)
+	if err != nil {
+		return err
+	}
+	module, lit, err := parseModulePath(modFile, src)
+	if err != nil {
+		return err
+	}
+	if module == "" {
+		return errors.Newf(lit.Pos(), "module path is empty")
+	}
+
+	digest, err := modsign.Digest(root)
+	if err != nil {
+		return err
+	}
+	sig := modsign.Sign(key, module, digest)
+	return ioutil.WriteFile(filepath.Join(root, "cue.mod", modsign.SigFile), sig, 0644)
+}
+
+func newModVerifyCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "verify the signatures of dependencies",
+		Long: `Verify checks the signatures of the modules in cue.mod/pkg of the
+module containing the current directory against its trust policy. It
+reports each module that is required to be signed.
+
+` + trustDoc,
+		RunE: mkRunE(c, runModVerify),
+	}
+	return cmd
+}
+
+func runModVerify(cmd *Command, args []string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	root, err := findModuleRoot(cwd)
+	if err != nil {
+		return err
+	}
+	modFile := filepath.Join(root, "cue.mod", "module.cue")
+	src, err := ioutil.ReadFile(modFile)
+	if err != nil {
+		return err
+	}
+	v := cuecontext.New().CompileBytes(src, cue.Filename(modFile))
+	if err := v.Err(); err != nil {
+		return err
+	}
+	var policy modsign.Policy
+	if t := v.LookupPath(cue.MakePath(cue.Str("trust"))); t.Exists() {
+		if policy, err = modsign.ParsePolicy(t); err != nil {
+			return err
+		}
+	}
+	if len(policy) == 0 {
+		return fmt.Errorf("%s: no trust policy defined", relPath(cwd, modFile))
+	}
+
+	pkg := filepath.Join(root, "cue.mod", "pkg")
+	err = filepath.Walk(pkg, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) && path == pkg {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() || path == pkg {
+			return nil
+		}
+		if fi, err := os.Stat(filepath.Join(path, "cue.mod")); err != nil || !fi.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(pkg, path)
+		if err != nil {
+			return err
+		}
+		module := filepath.ToSlash(rel)
+		if keys, ok := policy.Keys(module); ok {
+			if err := modsign.VerifyDir(path, module, keys); err != nil {
+				exitOnErr(cmd, errors.Newf(token.NoPos, "%s: %v", module, err), false)
+			} else {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: verified\n", module)
+			}
+		}
+		// The directories below a module root belong to that module.
+		return filepath.SkipDir
+	})
+	return err
+}
@@ -66,7 +66,7 @@ func (b *buildPlan) parsePlacementFlags() error {
 				flagWithContext, flagPath, flagList, flagFiles,
 			)
 		}
-	} else if b.schema != nil {
+	} else if b.schema != nil && !b.importing {
 		return fmt.Errorf(
 			"cannot combine --%s flag with flag %q, %q, or %q",
 			flagSchema, flagPath, flagList, flagFiles,
@@ -101,6 +101,11 @@ func (b *buildPlan) placeOrphans(i *build.Instance, a []*decoderInfo) error {
 		for ; !d.Done(); d.Next() {
 			if f := d.File(); f != nil {
 				f.Filename = newName(d.Filename(), 0)
+				if b.importing && b.schema != nil {
+					if err := applySchema(f, b.encConfig.Schema); err != nil {
+						return err
+					}
+				}
 				objs = append(objs, f)
 			}
 		}
@@ -0,0 +1,221 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// This file implements the provenance records written by cue export.
+//
+// A provenance record lists the files from which an export was computed,
+// along with their SHA-256 digests, the version of the cue tool, and the
+// command line, so that the export can be audited and reproduced. The
+// record may also be written as an in-toto statement with a SLSA
+// provenance predicate.
+
+const (
+	inTotoStatementType = "https://in-toto.io/Statement/v0.1"
+	slsaPredicateType   = "https://slsa.dev/provenance/v0.2"
+	cueBuilderID        = "https://cuelang.org/cmd/cue"
+	cueExportBuildType  = "https://cuelang.org/cmd/cue/export@v1"
+)
+
+// A provenanceRecord is the record written for the cue format.
+type provenanceRecord struct {
+	CUEVersion string     `json:"cueVersion"`
+	Command    []string   `json:"command"`
+	Tags       []string   `json:"tags,omitempty"`
+	Inputs     []artifact `json:"inputs"`
+	Output     artifact   `json:"output"`
+}
+
+// An artifact is a file read or written by a command. Digest is empty for
+// data that is not read from a file, such as standard input.
+type artifact struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256,omitempty"`
+}
+
+type inTotoStatement struct {
+	Type          string          `json:"_type"`
+	Subject       []inTotoSubject `json:"subject"`
+	PredicateType string          `json:"predicateType"`
+	Predicate     slsaProvenance  `json:"predicate"`
+}
+
+type inTotoSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type slsaProvenance struct {
+	Builder struct {
+		ID string `json:"id"`
+	} `json:"builder"`
+	BuildType  string `json:"buildType"`
+	Invocation struct {
+		Parameters struct {
+			Command []string `json:"command"`
+			Tags    []string `json:"tags,omitempty"`
+		} `json:"parameters"`
+	} `json:"invocation"`
+	Materials []slsaMaterial `json:"materials"`
+}
+
+type slsaMaterial struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// writeProvenance writes the provenance record of the export planned by b
+// to the file given by the --provenance flag. The output of the export
+// must already be written: out holds the output if it was written to
+// standard output.
+func writeProvenance(cmd *Command, args []string, b *buildPlan, out []byte) error {
+	cwd, _ := os.Getwd()
+	p := provenanceRecord{
+		CUEVersion: cueVersion(),
+		Command:    commandLine(cmd, args),
+		Tags:       flagInject.StringArray(cmd),
+	}
+	seen := map[string]bool{}
+	for _, f := range b.sources {
+		a := artifact{Path: relPath(cwd, f.Filename)}
+		if seen[a.Path] {
+			continue
+		}
+		seen[a.Path] = true
+		if f.Filename != "-" && f.Source == nil {
+			data, err := ioutil.ReadFile(f.Filename)
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			a.SHA256 = digest(data)
+		}
+		p.Inputs = append(p.Inputs, a)
+	}
+	p.Output = artifact{Path: relPath(cwd, b.outFile.Filename)}
+	if b.outFile.Filename != "-" {
+		var err error
+		out, err = ioutil.ReadFile(b.outFile.Filename)
+		if err != nil {
+			return err
+		}
+	}
+	p.Output.SHA256 = digest(out)
+
+	var x interface{} = p
+	switch format := flagProvenanceFormat.String(cmd); format {
+	case "cue":
+	case "in-toto":
+		x = p.inToto()
+	default:
+		return fmt.Errorf("unsupported --provenance-format %q", format)
+	}
+	data, err := json.MarshalIndent(x, "", "    ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return ioutil.WriteFile(flagProvenance.String(cmd), data, 0644)
+}
+
+// inToto converts p to an in-toto statement with a SLSA provenance
+// predicate.
+func (p *provenanceRecord) inToto() *inTotoStatement {
+	s := &inTotoStatement{
+		Type: inTotoStatementType,
+		Subject: []inTotoSubject{{
+			Name:   p.Output.Path,
+			Digest: map[string]string{"sha256": p.Output.SHA256},
+		}},
+		PredicateType: slsaPredicateType,
+	}
+	pred := &s.Predicate
+	pred.Builder.ID = cueBuilderID + "@" + p.CUEVersion
+	pred.BuildType = cueExportBuildType
+	pred.Invocation.Parameters.Command = p.Command
+	pred.Invocation.Parameters.Tags = p.Tags
+	pred.Materials = []slsaMaterial{}
+	for _, a := range p.Inputs {
+		m := slsaMaterial{URI: a.Path}
+		if a.SHA256 != "" {
+			m.Digest = map[string]string{"sha256": a.SHA256}
+		}
+		pred.Materials = append(pred.Materials, m)
+	}
+	return s
+}
+
+// commandLine reconstructs the command line of cmd, excluding the flags
+// that select the provenance record itself.
+func commandLine(cmd *Command, args []string) []string {
+	line := strings.Fields(cmd.CommandPath())
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		switch flagName(f.Name) {
+		case flagProvenance, flagProvenanceFormat:
+			return
+		}
+		if f.Value.Type() == "stringArray" {
+			values, _ := cmd.Flags().GetStringArray(f.Name)
+			for _, v := range values {
+				line = append(line, "--"+f.Name+"="+v)
+			}
+			return
+		}
+		line = append(line, "--"+f.Name+"="+f.Value.String())
+	})
+	return append(line, args...)
+}
+
+// cueVersion reports the version of the cue tool.
+func cueVersion() string {
+	v := version
+	if bi, ok := debug.ReadBuildInfo(); ok && v == defaultVersion && bi.Main.Version != "" {
+		v = bi.Main.Version
+	}
+	return v
+}
+
+// relPath returns filename relative to dir, if possible, using forward
+// slashes.
+func relPath(dir, filename string) string {
+	if filename == "-" || !filepath.IsAbs(filename) {
+		return filepath.ToSlash(filename)
+	}
+	if rel, err := filepath.Rel(dir, filename); err == nil {
+		filename = rel
+	}
+	return filepath.ToSlash(filename)
+}
+
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/tools/refs"
+)
+
+func newRefsCmd(c *Command) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refs <path> [inputs]",
+		Short: "list the references to a field or definition",
+		Long: `Refs lists the references to the field or definition at the given
+path within the given inputs. The path is looked up in the first package
+that defines it, and references are reported for all packages, including
+references through imports. Run 'cue help inputs' for how to specify
+inputs.
+
+A syntactic reference resolves to the value at the path or to a value
+nested within it, and needs to be changed if the field is renamed. A
+semantic reference resolves to a value that is defined in terms of the
+value at the path, such as a field of a value that is unified with a
+definition, and may be affected if the value at the path is changed.
+
+  $ cat schema.cue
+  package app
+
+  #Port: int & >0 & <65536
+
+  #Service: port: #Port
+
+  web: #Service & {port: 80}
+  url: "http://localhost:\(web.port)"
+
+  $ cue refs '#Service' .
+  schema.cue:7:6: web: syntactic reference to #Service
+
+  $ cue refs '#Service.port' .
+  schema.cue:8:26: url: semantic reference to web.port
+
+Refs prints nothing if there are no references.
+`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: mkRunE(c, runRefs),
+	}
+
+	addInjectionFlags(cmd.Flags(), false)
+
+	return cmd
+}
+
+func runRefs(cmd *Command, args []string) error {
+	path := cue.ParsePath(args[0])
+	if err := path.Err(); err != nil {
+		return errors.Wrapf(err, token.NoPos, "invalid path %q", args[0])
+	}
+
+	cfg := *defaultConfig.loadCfg
+	exitOnErr(cmd, setTags(cmd.Flags(), &cfg), true)
+
+	binst := loadFromArgs(cmd, args[1:], &cfg)
+	if binst == nil {
+		return nil
+	}
+	insts := buildInstances(cmd, binst)
+
+	var target cue.Value
+	values := make([]cue.Value, len(insts))
+	for i, inst := range insts {
+		values[i] = inst.Value()
+		if v := values[i].LookupPath(path); !target.Exists() && v.Exists() {
+			target = v
+		}
+	}
+	if !target.Exists() {
+		return errors.Newf(token.NoPos, "no value found for path %v", path)
+	}
+
+	cwd, _ := os.Getwd()
+	w := cmd.OutOrStdout()
+	for _, r := range refs.Find(target, values...) {
+		fmt.Fprintf(w, "%s: %v\n", relPos(cwd, r.Pos), r)
+	}
+	return nil
+}
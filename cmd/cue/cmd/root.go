@@ -19,6 +19,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -43,7 +44,10 @@ type runFunction func(cmd *Command, args []string) error
 func mkRunE(c *Command, f runFunction) func(*cobra.Command, []string) error {
 	return func(cmd *cobra.Command, args []string) error {
 		c.Command = cmd
-		err := f(c, args)
+		err := c.hooks.start(c, args)
+		if err == nil {
+			err = f(c, args)
+		}
 		if err != nil {
 			exitOnErr(c, err, true)
 		}
@@ -52,7 +56,7 @@ func mkRunE(c *Command, f runFunction) func(*cobra.Command, []string) error {
 }
 
 // newRootCmd creates the base command when called without any subcommands
-func newRootCmd() *Command {
+func newRootCmd(h *Hooks) *Command {
 	cmd := &cobra.Command{
 		Use:   "cue",
 		Short: "cue emits configuration files to user-defined commands.",
@@ -81,7 +85,7 @@ For more information on writing CUE configuration files see cuelang.org.`,
 		SilenceUsage: true,
 	}
 
-	c := &Command{Command: cmd, root: cmd}
+	c := &Command{Command: cmd, root: cmd, hooks: h}
 
 	cmdCmd := newCmdCmd(c)
 	c.cmd = cmdCmd
@@ -89,6 +93,7 @@ For more information on writing CUE configuration files see cuelang.org.`,
 	subCommands := []*cobra.Command{
 		cmdCmd,
 		newCompletionCmd(c),
+		newDebugCmd(c),
 		newEvalCmd(c),
 		newDefCmd(c),
 		newExportCmd(c),
@@ -96,7 +101,9 @@ For more information on writing CUE configuration files see cuelang.org.`,
 		newFmtCmd(c),
 		newGetCmd(c),
 		newImportCmd(c),
+		newInputsCmd(c),
 		newModCmd(c),
+		newRefsCmd(c),
 		newTrimCmd(c),
 		newVersionCmd(c),
 		newVetCmd(c),
@@ -111,6 +118,7 @@ For more information on writing CUE configuration files see cuelang.org.`,
 	for _, sub := range subCommands {
 		cmd.AddCommand(sub)
 	}
+	h.addFlags(cmd)
 
 	return c
 }
@@ -123,22 +131,43 @@ func MainTest() int {
 
 // Main runs the cue tool and returns the code for passing to os.Exit.
 func Main() int {
+	return MainWithHooks(nil)
+}
+
+// MainWithHooks is like Main, but calls the given hooks while running the
+// cue tool. It allows programs that embed the cue tool to customize it.
+func MainWithHooks(h *Hooks) int {
 	cwd, _ := os.Getwd()
-	err := mainErr(context.Background(), os.Args[1:])
+	err := mainErr(context.Background(), os.Args[1:], h)
 	if err != nil {
+		code := 1
+		if e, ok := err.(*exitError); ok {
+			err, code = e.err, e.code
+		}
 		if err != ErrPrintedError {
 			errors.Print(os.Stderr, err, &errors.Config{
 				Cwd:     cwd,
 				ToSlash: inTest,
+				Catalog: messageCatalog(os.Stderr),
 			})
 		}
-		return 1
+		return code
 	}
 	return 0
 }
 
-func mainErr(ctx context.Context, args []string) error {
-	cmd, err := New(args)
+// An exitError is returned by Run for a command that failed and has a
+// specific exit status.
+type exitError struct {
+	err  error
+	code int
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+func mainErr(ctx context.Context, args []string, h *Hooks) error {
+	cmd, err := NewWithHooks(args, h)
 	if err != nil {
 		return err
 	}
@@ -154,7 +183,53 @@ type Command struct {
 	// Subcommands
 	cmd *cobra.Command
 
+	hooks *Hooks
+
 	hasErr bool
+
+	// baseline, if not nil, holds the errors that are not reported.
+	baseline *baseline
+
+	// exitCode is the status with which the cue tool exits if the command
+	// fails. Zero means 1.
+	exitCode int
+}
+
+// Hooks allows programs that embed the cue tool to observe and extend its
+// commands. Any of the hooks may be nil.
+type Hooks struct {
+	// Flags is called for each command when it is created, including the
+	// root command and user-defined commands. It may add flags to the
+	// command's flag set, which can then be read in Start.
+	Flags func(cmd *cobra.Command)
+
+	// Start is called just before a command runs, with the arguments that
+	// remain after parsing flags. If Start returns an error, the command is
+	// not run and the error is reported as if it were returned by the
+	// command.
+	Start func(cmd *Command, args []string) error
+
+	// Done is called when Run completes, with the time it took and the
+	// resulting error, if any. The Command field of cmd holds
+	// the command that ran, or the root command if no command ran.
+	Done func(cmd *Command, d time.Duration, err error)
+}
+
+func (h *Hooks) addFlags(cmd *cobra.Command) {
+	if h == nil || h.Flags == nil {
+		return
+	}
+	h.Flags(cmd)
+	for _, sub := range cmd.Commands() {
+		h.addFlags(sub)
+	}
+}
+
+func (h *Hooks) start(cmd *Command, args []string) error {
+	if h == nil || h.Start == nil {
+		return nil
+	}
+	return h.Start(cmd, args)
 }
 
 type errWriter Command
@@ -201,6 +276,15 @@ func (c *Command) Run(ctx context.Context) (err error) {
 	// - user defined
 	// - help
 	// For the latter two, we need to use the default loading.
+	if h := c.hooks; h != nil && h.Done != nil {
+		start := time.Now()
+		defer func() { h.Done(c, time.Since(start), err) }()
+	}
+	defer func() {
+		if err != nil && c.exitCode != 0 {
+			err = &exitError{err, c.exitCode}
+		}
+	}()
 	defer recoverError(&err)
 
 	if err := c.root.Execute(); err != nil {
@@ -223,10 +307,18 @@ func recoverError(err *error) {
 	// We use panic to escape, instead of os.Exit
 }
 
+// New creates the cue tool for the given command-line arguments, excluding
+// the program name.
 func New(args []string) (cmd *Command, err error) {
+	return NewWithHooks(args, nil)
+}
+
+// NewWithHooks is like New, but calls the given hooks while creating and
+// running the command.
+func NewWithHooks(args []string, h *Hooks) (cmd *Command, err error) {
 	defer recoverError(&err)
 
-	cmd = newRootCmd()
+	cmd = newRootCmd(h)
 	rootCmd := cmd.root
 	if len(args) == 0 {
 		return cmd, nil
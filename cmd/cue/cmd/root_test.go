@@ -14,7 +14,16 @@
 
 package cmd
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
 
 func TestHelp(t *testing.T) {
 	cmd, err := New([]string{"help"})
@@ -57,3 +66,60 @@ func TestHelp(t *testing.T) {
 		t.Error("help command failed unexpectedly")
 	}
 }
+
+func TestHooks(t *testing.T) {
+	var (
+		started []string
+		label   string
+		done    error
+		calls   int
+	)
+	h := &Hooks{
+		Flags: func(cmd *cobra.Command) {
+			if cmd.Name() == "version" {
+				cmd.Flags().String("label", "", "label for metrics")
+			}
+		},
+		Start: func(cmd *Command, args []string) error {
+			started = append(started, cmd.Name())
+			label, _ = cmd.Flags().GetString("label")
+			if label == "fail" {
+				return errors.New("start failed")
+			}
+			return nil
+		},
+		Done: func(cmd *Command, d time.Duration, err error) {
+			calls++
+			done = err
+		},
+	}
+
+	cmd, err := NewWithHooks([]string{"version", "--label", "x"}, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd.SetOutput(&bytes.Buffer{})
+	if err := cmd.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(started) != 1 || started[0] != "version" || label != "x" {
+		t.Errorf("got started %v with label %q; want [version] with label %q", started, label, "x")
+	}
+	if calls != 1 || done != nil {
+		t.Errorf("got %d calls to Done with error %v; want 1 call without error", calls, done)
+	}
+
+	cmd, err = NewWithHooks([]string{"version", "--label", "fail"}, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &bytes.Buffer{}
+	cmd.SetOutput(b)
+	err = cmd.Run(context.Background())
+	if err != ErrPrintedError || done != err {
+		t.Errorf("got error %v, Done error %v; want %v", err, done, ErrPrintedError)
+	}
+	if got := b.String(); !strings.Contains(got, "start failed") || strings.Contains(got, "version") {
+		t.Errorf("unexpected output %q", got)
+	}
+}
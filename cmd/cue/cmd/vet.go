@@ -15,11 +15,26 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/spf13/cobra"
 	"golang.org/x/text/message"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/cuecontext"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/jsonschema"
+	"cuelang.org/go/encoding/openapi"
+	"cuelang.org/go/tools/compat"
+	"cuelang.org/go/tools/policy"
+	"cuelang.org/go/tools/vet"
 )
 
 const vetDoc = `vet validates CUE and other data files
@@ -59,6 +74,148 @@ Examples:
   cue vet translations/*.yaml foo.cue -d '#Translation'
 
 If more than one expression is given, all must match all values.
+
+With the --select flag, each data value is checked against the schema that
+matches the values of the given comma-separated fields instead. The
+candidate schemas are the fields and definitions of the root of the loaded
+CUE files, or of the value selected with -d, for which these fields are
+concrete. This allows checking a directory of heterogeneous files, such
+as Kubernetes manifests:
+
+  #Deployment: {apiVersion: "apps/v1", kind: "Deployment", ...}
+  #Service:    {apiVersion: "v1", kind: "Service", ...}
+
+  cue vet --select apiVersion,kind schema.cue manifests/*.yaml
+
+It is an error if a data value matches none of the schemas.
+
+
+Checking OpenAPI and JSON Schema documents
+
+The -d flag may also denote one of the meta-schemas that are built into
+cue, in the form encoding:version. Data files are then checked to be valid
+documents of that kind, without the need for any CUE files. The following
+meta-schemas are available:
+
+  openapi:3.0          OpenAPI 3.0 documents
+  jsonschema:draft-04  JSON Schema draft-04 schemas
+  jsonschema:draft-07  JSON Schema draft-07 schemas
+
+Examples:
+
+  # Check that an OpenAPI specification is well-formed
+  cue vet --schema openapi:3.0 spec.yaml
+
+
+Checking examples
+
+Vet checks that the examples in CUE packages are valid. An example is
+either the contents of an @example attribute, which is checked against
+the field to which it is attached or the struct in which it is declared,
+or a hidden field starting with _example_ within a definition, which is
+checked against the definition:
+
+  #Service: {
+    @example({name: "web", port: 80})
+
+    name: string
+    port: int & >0 @example(8080)
+
+    _example_db: {name: "db", port: 5432}
+  }
+
+
+Enums
+
+An enum is a disjunction of concrete scalars. Vet checks that fields marked
+with @enum() are enums, and that structs marked with @exhaustive have a
+field for each member of an enum and no other regular fields. The argument
+is the path of the enum, and may be omitted if the struct has a pattern
+constraint on the enum:
+
+  #Color: "red" | "green" | "blue" @enum()
+
+  hex: {
+    [#Color]: string
+
+    red:   "#f00"
+    green: "#0f0"
+    blue:  "#00f"
+  } @exhaustive()
+
+  names: {red: "Red", green: "Green", blue: "Blue"} @exhaustive(#Color)
+
+
+Deprecated fields
+
+Fields and definitions can be marked as deprecated with an attribute:
+
+  oldName?: string @deprecated("use name instead")
+
+Vet prints a warning for each deprecated field that is set to a concrete
+value and for each reference to a deprecated field or definition. Warnings
+do not affect the exit code.
+
+
+Checking schema compatibility
+
+With the --compat flag, vet compares two versions of a schema instead of
+validating data. It takes exactly two arguments, the old and the new version,
+each of which may be a file or a package. Each change is reported per path
+and classified as
+
+  backward compatible   data valid for the old schema is valid for the new
+  forward compatible    data valid for the new schema is valid for the old
+  breaking              neither of the above
+
+Vet fails if the new schema is not backward compatible with the old one.
+The -d flag may be used to compare a particular definition.
+
+Examples:
+
+  # Check that v2 of a schema accepts all data accepted by v1
+  cue vet --compat v1/schema.cue v2/schema.cue -d '#Config'
+
+
+Checking policies
+
+With the --policy flag, vet applies the constraints of the given package to
+each of the data files and CUE packages specified on the command line and
+reports violations per policy rule. Rules are defined by annotating
+constraints with a policy attribute:
+
+  privileged: false @policy(id=SEC001, severity=error, msg="no privileged containers")
+
+The severity is one of error (the default), warning, or info. Vet only fails
+for violations of rules with severity error. Use --out json to report
+violations as a JSON list.
+
+Examples:
+
+  # Apply the policies in ./policy to all manifests
+  cue vet --policy ./policy manifests/*.yaml
+
+
+Adopting schemas gradually
+
+With the --baseline flag, vet does not report the known errors recorded in
+the given file. This allows a stricter schema to be adopted for a large
+configuration before all existing violations are fixed, while still
+reporting new ones. An error is identified by the path at which it occurs
+and its kind, so that it remains suppressed if the offending value changes.
+Errors that do not relate to a path, such as syntax errors, are always
+reported.
+
+The --update-baseline flag records all errors in the baseline file instead
+of reporting them, and removes the entries for errors that no longer occur.
+
+Examples:
+
+  # Record the current violations
+  cue vet --baseline baseline.json --update-baseline ./...
+
+  # Report new violations only
+  cue vet --baseline baseline.json ./...
 `
 
 func newVetCmd(c *Command) *cobra.Command {
@@ -66,18 +223,37 @@ func newVetCmd(c *Command) *cobra.Command {
 		Use:   "vet",
 		Short: "validate data",
 		Long:  vetDoc,
-		RunE:  mkRunE(c, doVet),
+		RunE:  mkRunE(c, withBaseline(doVet)),
 	}
 
 	addOrphanFlags(cmd.Flags())
 	addInjectionFlags(cmd.Flags(), false)
+	addBaselineFlags(cmd.Flags())
 
 	cmd.Flags().BoolP(string(flagConcrete), "c", false,
 		"require the evaluation to be concrete")
 
+	cmd.Flags().Bool(string(flagCompat), false,
+		"compare the compatibility of two versions of a schema")
+
+	cmd.Flags().String(string(flagSelect), "",
+		"comma-separated fields by which to select the schema for each data value")
+
+	cmd.Flags().String(string(flagPolicy), "",
+		"apply the policy rules of the given package")
+
+	cmd.Flags().String(string(flagOut), "",
+		"output format for policy violations (text|json)")
+
 	return cmd
 }
 
+const (
+	flagCompat flagName = "compat"
+	flagPolicy flagName = "policy"
+	flagSelect flagName = "select"
+)
+
 // doVet validates instances. There are two modes:
 // - Only packages: vet all these packages
 // - Data files: compare each data instance against a single package.
@@ -87,24 +263,49 @@ func newVetCmd(c *Command) *cobra.Command {
 // TODO: allow unrooted schema, such as JSON schema to compare against
 // other values.
 func doVet(cmd *Command, args []string) error {
+	if flagCompat.Bool(cmd) {
+		vetCompat(cmd, args)
+		return nil
+	}
+	if p := flagPolicy.String(cmd); p != "" {
+		vetPolicy(cmd, p, args)
+		return nil
+	}
+	if name, version, ok := builtinSchema(flagSchema.String(cmd)); ok {
+		vetBuiltin(cmd, name, version, args)
+		return nil
+	}
+
 	b, err := parseArgs(cmd, args, &config{
 		noMerge: true,
+		noUnify: flagSelect.String(cmd) != "",
 	})
 	exitOnErr(cmd, err, true)
 
+	// A cached result indicates that the same inputs were vetted
+	// successfully before.
+	cache := openCache(cmd, args, b)
+	if _, ok := cache.get(); ok {
+		return nil
+	}
+
 	// Go into a special vet mode if the user explicitly specified non-cue
 	// files on the command line.
 	// TODO: unify these two modes.
 	if len(b.orphaned) > 0 {
-		vetFiles(cmd, b)
+		warned := vetFiles(cmd, b)
+		if !cmd.hasErr && !warned {
+			cache.put(nil)
+		}
 		return nil
 	}
 
 	shown := false
+	warned := false
 
 	iter := b.instances()
 	defer iter.close()
-	for iter.scan() {
+	for i := 0; iter.scan(); i++ {
 		v := iter.value()
 		// TODO: use ImportPath or some other sanitized path.
 
@@ -133,26 +334,365 @@ func doVet(cmd *Command, args []string) error {
 			}
 		}
 		exitOnErr(cmd, err, false)
+		if i < len(b.insts) {
+			vetExamples(cmd, v, b.insts[i].Files)
+		}
+		vetEnums(cmd, v)
+		warned = printDeprecations(cmd, v) || warned
 	}
 	exitOnErr(cmd, iter.err(), true)
+
+	// Results with warnings are not cached so that the warnings are
+	// reported again.
+	if !cmd.hasErr && !warned {
+		cache.put(nil)
+	}
 	return nil
 }
 
-func vetFiles(cmd *Command, b *buildPlan) {
+// vetFiles validates the data files of b against the schema and reports
+// whether any warnings were printed.
+func vetFiles(cmd *Command, b *buildPlan) (warned bool) {
 	// Use -r type root, instead of -e
 
 	if !b.encConfig.Schema.Exists() {
 		exitOnErr(cmd, errors.New("data files specified without a schema"), true)
 	}
 
+	var sel *schemaSelector
+	if s := flagSelect.String(cmd); s != "" {
+		var err error
+		sel, err = newSchemaSelector(b.encConfig.Schema, strings.Split(s, ","))
+		exitOnErr(cmd, err, true)
+	}
+
 	iter := b.instances()
 	defer iter.close()
 	for iter.scan() {
 		v := iter.value()
+		if sel != nil {
+			schema, err := sel.schema(v)
+			if err != nil {
+				exitOnErr(cmd, err, false)
+				continue
+			}
+			v = v.Unify(schema)
+		}
 
 		// Always concrete when checking against concrete files.
 		err := v.Validate(cue.Concrete(true))
 		exitOnErr(cmd, err, false)
+		warned = printDeprecations(cmd, v) || warned
 	}
 	exitOnErr(cmd, iter.err(), false)
+	return warned
+}
+
+// A schemaSelector selects the schema for a data value by the values of a
+// set of discriminating fields.
+type schemaSelector struct {
+	fields  []cue.Path
+	schemas map[string]cue.Value
+}
+
+// newSchemaSelector collects the fields and definitions of v for which all
+// the given fields are concrete.
+func newSchemaSelector(v cue.Value, fields []string) (*schemaSelector, error) {
+	s := &schemaSelector{schemas: map[string]cue.Value{}}
+	for _, f := range fields {
+		p := cue.ParsePath(strings.TrimSpace(f))
+		if err := p.Err(); err != nil {
+			return nil, errors.Wrapf(err, token.NoPos, "invalid --select field %q", f)
+		}
+		s.fields = append(s.fields, p)
+	}
+
+	iter, err := v.Fields(cue.Definitions(true))
+	if err != nil {
+		return nil, err
+	}
+	for iter.Next() {
+		w := iter.Value()
+		key, ok := s.key(w)
+		if !ok {
+			continue
+		}
+		if prev, ok := s.schemas[key]; ok {
+			return nil, errors.Newf(w.Pos(),
+				"schemas %v and %v both select %s", prev.Path(), w.Path(), key)
+		}
+		s.schemas[key] = w
+	}
+	if len(s.schemas) == 0 {
+		return nil, errors.Newf(v.Pos(),
+			"no schema has concrete values for %v", s.names())
+	}
+	return s, nil
+}
+
+// key reports the values of the selected fields in v. It reports false if
+// any of them is not concrete.
+func (s *schemaSelector) key(v cue.Value) (string, bool) {
+	a := make([]string, len(s.fields))
+	for i, p := range s.fields {
+		w := v.LookupPath(p)
+		if !w.IsConcrete() || w.Validate(cue.Concrete(true)) != nil {
+			return "", false
+		}
+		a[i] = fmt.Sprintf("%v: %v", p, w)
+	}
+	return strings.Join(a, ", "), true
+}
+
+func (s *schemaSelector) names() string {
+	a := make([]string, len(s.fields))
+	for i, p := range s.fields {
+		a[i] = p.String()
+	}
+	return strings.Join(a, ", ")
+}
+
+// schema reports the schema selected by the data value v.
+func (s *schemaSelector) schema(v cue.Value) (cue.Value, error) {
+	key, ok := s.key(v)
+	if !ok {
+		return cue.Value{}, errors.Newf(v.Pos(),
+			"cannot select schema: %v must be concrete", s.names())
+	}
+	schema, ok := s.schemas[key]
+	if !ok {
+		return cue.Value{}, errors.Newf(v.Pos(), "no schema for %s", key)
+	}
+	return schema, nil
+}
+
+// printDeprecations prints a warning for each use of a deprecated field in v
+// and reports whether there were any.
+func printDeprecations(cmd *Command, v cue.Value) bool {
+	ds := vet.Deprecations(v)
+	cwd, _ := os.Getwd()
+	for _, d := range ds {
+		err := errors.Newf(d.Pos, "warning: %v", d)
+		errors.Print(cmd.OutOrStderr(), err, &errors.Config{
+			Cwd:     cwd,
+			ToSlash: inTest,
+			Catalog: messageCatalog(cmd.OutOrStderr()),
+		})
+	}
+	return len(ds) > 0
+}
+
+// vetExamples reports the examples in files that are not instances of the
+// values of v they document.
+func vetExamples(cmd *Command, v cue.Value, files []*ast.File) {
+	var errs errors.Error
+	for _, err := range vet.Examples(v, files...) {
+		errs = errors.Append(errs, err)
+	}
+	if errs != nil {
+		exitOnErr(cmd, errs, false)
+	}
+}
+
+// vetEnums reports the invalid enums and non-exhaustive enum mappings in v.
+func vetEnums(cmd *Command, v cue.Value) {
+	var errs errors.Error
+	for _, err := range vet.Enums(v) {
+		errs = errors.Append(errs, err)
+	}
+	if errs != nil {
+		exitOnErr(cmd, errs, false)
+	}
+}
+
+// vetCompat reports the compatibility of the schema in args[1] relative to
+// the schema in args[0].
+func vetCompat(cmd *Command, args []string) {
+	if len(args) != 2 {
+		exitOnErr(cmd, errors.Newf(token.NoPos,
+			"--compat requires exactly two arguments: the old and new schema"), true)
+	}
+
+	var expr ast.Expr
+	if s := flagSchema.String(cmd); s != "" {
+		var err error
+		expr, err = parser.ParseExpr("--schema", s)
+		exitOnErr(cmd, err, true)
+	}
+
+	ctx := cuecontext.New()
+	var v [2]cue.Value
+	for i, arg := range args {
+		cfg := *defaultConfig.loadCfg
+		exitOnErr(cmd, setTags(cmd.Flags(), &cfg), true)
+
+		binst := load.Instances([]string{arg}, &cfg)
+		if len(binst) != 1 {
+			exitOnErr(cmd, errors.Newf(token.NoPos,
+				"%s: --compat arguments must each denote a single instance", arg), true)
+		}
+		exitOnErr(cmd, binst[0].Err, true)
+
+		v[i] = ctx.BuildInstance(binst[0])
+		if expr != nil {
+			v[i] = ctx.BuildExpr(expr,
+				cue.Scope(v[i]),
+				cue.InferBuiltins(true),
+				cue.ImportPath(binst[0].ID()),
+			)
+		}
+		exitOnErr(cmd, v[i].Err(), true)
+	}
+
+	r := compat.Check(v[0], v[1])
+	fmt.Fprint(cmd.OutOrStdout(), r)
+
+	switch r.Level {
+	case compat.Forward, compat.Breaking:
+		exitOnErr(cmd, errors.Newf(token.NoPos,
+			"new schema is not backward compatible"), true)
+	}
+}
+
+// builtinSchemas maps the encodings that bundle meta-schemas to a function
+// returning the meta-schema for a version or dialect.
+var builtinSchemas = map[string]func(*cue.Context, string) (cue.Value, error){
+	"jsonschema": jsonschema.MetaSchema,
+	"openapi":    openapi.MetaSchema,
+}
+
+// builtinSchema reports whether s, the value of the --schema flag, is of the
+// form encoding:version and denotes a built-in meta-schema.
+func builtinSchema(s string) (name, version string, ok bool) {
+	i := strings.IndexByte(s, ':')
+	if i < 0 {
+		return "", "", false
+	}
+	name, version = s[:i], s[i+1:]
+	_, ok = builtinSchemas[name]
+	return name, version, ok
+}
+
+// vetBuiltin validates the data files denoted by args against the built-in
+// meta-schema for the given version of an encoding.
+func vetBuiltin(cmd *Command, name, version string, args []string) {
+	ctx := cuecontext.New()
+	schema, err := builtinSchemas[name](ctx, version)
+	exitOnErr(cmd, err, true)
+
+	b, err := parseArgs(cmd, args, &config{noMerge: true, metaSchema: true})
+	exitOnErr(cmd, err, true)
+	if len(b.insts) > 0 || b.encConfig.Schema.Exists() {
+		exitOnErr(cmd, errors.Newf(token.NoPos,
+			"--schema %s:%s may only be used with data files", name, version), true)
+	}
+
+	for _, di := range b.orphaned {
+		d := di.dec(b)
+		for ; !d.Done(); d.Next() {
+			v := ctx.BuildFile(d.File()).Unify(schema)
+			exitOnErr(cmd, v.Validate(cue.Concrete(true)), false)
+		}
+		exitOnErr(cmd, d.Err(), false)
+	}
+}
+
+// vetPolicy applies the policy rules of the package at path to the data files
+// and instances denoted by args.
+func vetPolicy(cmd *Command, path string, args []string) {
+	format := flagOut.String(cmd)
+	switch format {
+	case "", "text", "json":
+	default:
+		exitOnErr(cmd, errors.Newf(token.NoPos,
+			"unsupported output format %q for policy violations", format), true)
+	}
+
+	cfg := *defaultConfig.loadCfg
+	exitOnErr(cmd, setTags(cmd.Flags(), &cfg), true)
+
+	binst := load.Instances([]string{path}, &cfg)
+	if len(binst) != 1 {
+		exitOnErr(cmd, errors.Newf(token.NoPos,
+			"--policy must denote a single package"), true)
+	}
+	exitOnErr(cmd, binst[0].Err, true)
+
+	ctx := cuecontext.New()
+	bundle, err := policy.NewBundle(ctx.BuildInstance(binst[0]))
+	exitOnErr(cmd, err, true)
+
+	b, err := parseArgs(cmd, args, &config{noMerge: true})
+	exitOnErr(cmd, err, true)
+
+	var violations []*policy.Violation
+	check := func(v cue.Value) {
+		exitOnErr(cmd, v.Err(), true)
+		violations = append(violations, bundle.Evaluate(v)...)
+	}
+	for _, inst := range b.insts {
+		check(ctx.BuildInstance(inst))
+	}
+	for _, di := range b.orphaned {
+		d := di.dec(b)
+		for ; !d.Done(); d.Next() {
+			check(ctx.BuildFile(d.File()))
+		}
+		exitOnErr(cmd, d.Err(), true)
+	}
+
+	failed := false
+	for _, v := range violations {
+		failed = failed || v.Severity() == policy.Error
+	}
+
+	if format == "json" {
+		printPolicyJSON(cmd, violations)
+		if failed {
+			exit()
+		}
+		return
+	}
+
+	for _, v := range violations {
+		if v.Severity() == policy.Error {
+			exitOnErr(cmd, v, false)
+			continue
+		}
+		// Warnings and info do not affect the exit code.
+		cwd, _ := os.Getwd()
+		errors.Print(cmd.OutOrStderr(), v, &errors.Config{
+			Cwd:     cwd,
+			ToSlash: inTest,
+			Catalog: messageCatalog(cmd.OutOrStderr()),
+		})
+	}
+}
+
+type policyViolation struct {
+	ID        string   `json:"id,omitempty"`
+	Severity  string   `json:"severity"`
+	Message   string   `json:"message"`
+	Path      string   `json:"path"`
+	Positions []string `json:"positions,omitempty"`
+}
+
+func printPolicyJSON(cmd *Command, violations []*policy.Violation) {
+	cwd, _ := os.Getwd()
+	a := []policyViolation{}
+	for _, v := range violations {
+		pv := policyViolation{
+			ID:       v.ID(),
+			Severity: string(v.Severity()),
+			Message:  v.Message(),
+			Path:     strings.Join(v.Path(), "."),
+		}
+		for _, p := range errors.Positions(v) {
+			pv.Positions = append(pv.Positions, relPos(cwd, p))
+		}
+		a = append(a, pv)
+	}
+	b, err := json.MarshalIndent(a, "", "    ")
+	exitOnErr(cmd, err, true)
+	fmt.Fprintln(cmd.OutOrStdout(), string(b))
 }
@@ -838,9 +838,10 @@ func (id *Ident) String() string {
 
 // An ImportSpec node represents a single package import.
 type ImportSpec struct {
-	Name   *Ident    // local package name (including "."); or nil
-	Path   *BasicLit // import path
-	EndPos token.Pos // end of spec (overrides Path.Pos if nonzero)
+	Name   *Ident       // local package name (including "."); or nil
+	Path   *BasicLit    // import path
+	Attrs  []*Attribute // tags with which to instantiate the package
+	EndPos token.Pos    // end of spec (overrides Path.Pos if nonzero)
 
 	comments
 }
@@ -0,0 +1,284 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package astbuild provides constructors for building CUE syntax trees
+// programmatically.
+//
+// Unlike the constructors in package ast, the ones in this package take care
+// of the relative positions of nodes, so that the result formats as a human
+// would write it: declarations are placed on separate lines, documented
+// declarations are preceded by a blank line, and lists containing comments
+// place each element on its own line. Labels are quoted only when needed.
+//
+// Validate can be used to check a tree, whether built with this package or
+// otherwise, before passing it to format.Node or other consumers.
+package astbuild
+
+import (
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+)
+
+// File returns a file with the given package name and declarations. If pkg
+// is empty, the file has no package clause. Import declarations should
+// precede all other declarations.
+func File(pkg string, decls ...ast.Decl) *ast.File {
+	f := &ast.File{}
+	if pkg != "" {
+		f.Decls = append(f.Decls, &ast.Package{Name: ast.NewIdent(pkg)})
+	}
+	f.Decls = append(f.Decls, decls...)
+	spaceDecls(f.Decls)
+	return f
+}
+
+// Import returns an import spec for the given import path. If name is not
+// empty, it is used as the name of the imported package.
+func Import(name, path string) *ast.ImportSpec {
+	var ident *ast.Ident
+	if name != "" {
+		ident = ast.NewIdent(name)
+	}
+	return ast.NewImport(ident, path)
+}
+
+// Imports returns an import declaration for the given specs.
+func Imports(specs ...*ast.ImportSpec) *ast.ImportDecl {
+	d := &ast.ImportDecl{Specs: specs}
+	if len(specs) > 1 {
+		d.Lparen = token.Blank.Pos()
+		for _, s := range specs {
+			setRelPos(s, token.Newline)
+		}
+		d.Rparen = token.Newline.Pos()
+	}
+	return d
+}
+
+// Label returns the label for a regular field with the given name. The label
+// is an identifier if name is a valid identifier that does not denote a
+// definition or hidden field, and a quoted string otherwise.
+func Label(name string) ast.Label {
+	if ast.IsValidIdent(name) && !internal.IsDefOrHidden(name) {
+		return ast.NewIdent(name)
+	}
+	return ast.NewString(name)
+}
+
+// Field returns a regular field with the given name and value.
+func Field(name string, value ast.Expr, attrs ...*ast.Attribute) *ast.Field {
+	return &ast.Field{Label: Label(name), Value: value, Attrs: attrs}
+}
+
+// Optional returns an optional field with the given name and value.
+func Optional(name string, value ast.Expr, attrs ...*ast.Attribute) *ast.Field {
+	f := Field(name, value, attrs...)
+	f.Optional = token.NoSpace.Pos()
+	return f
+}
+
+// Def returns a definition with the given name and value. A "#" is added to
+// name if it does not already start with one.
+func Def(name string, value ast.Expr, attrs ...*ast.Attribute) *ast.Field {
+	if !strings.HasPrefix(name, "#") {
+		name = "#" + name
+	}
+	return &ast.Field{Label: ast.NewIdent(name), Value: value, Attrs: attrs}
+}
+
+// Attr returns an attribute with the given key and body, as in @key(body).
+func Attr(key, body string) *ast.Attribute {
+	return &ast.Attribute{Text: "@" + key + "(" + body + ")"}
+}
+
+// Embed returns a declaration embedding x.
+func Embed(x ast.Expr) *ast.EmbedDecl {
+	return &ast.EmbedDecl{Expr: x}
+}
+
+// Struct returns a struct literal with the given declarations, each on its
+// own line.
+func Struct(decls ...ast.Decl) *ast.StructLit {
+	s := &ast.StructLit{Lbrace: token.NoSpace.Pos(), Elts: decls}
+	if len(decls) > 0 {
+		spaceDecls(decls)
+		s.Rbrace = token.Newline.Pos()
+	}
+	return s
+}
+
+// List returns a list literal with the given elements. The elements are
+// placed on a single line, unless any of them has comments, in which case
+// each is placed on its own line.
+func List(elems ...ast.Expr) *ast.ListLit {
+	l := &ast.ListLit{Elts: elems}
+	multiline := false
+	for _, e := range elems {
+		if !isNil(e) && len(ast.Comments(e)) > 0 {
+			multiline = true
+		}
+	}
+	if multiline {
+		for _, e := range elems {
+			setRelPos(e, token.Newline)
+		}
+		l.Rbrack = token.Newline.Pos()
+	}
+	return l
+}
+
+// Ident returns an identifier with the given name.
+func Ident(name string) *ast.Ident {
+	return ast.NewIdent(name)
+}
+
+// Ref returns a reference to the identifier name followed by the given
+// selectors, as in name.sel1.sel2.
+func Ref(name string, sels ...string) ast.Expr {
+	return ast.NewSel(ast.NewIdent(name), sels...)
+}
+
+// String returns a string literal with the value s.
+func String(s string) *ast.BasicLit {
+	return ast.NewString(s)
+}
+
+// Int returns an integer literal with the value i.
+func Int(i int64) *ast.BasicLit {
+	return ast.NewLit(token.INT, strconv.FormatInt(i, 10))
+}
+
+// Float returns a floating point literal with the value f.
+func Float(f float64) *ast.BasicLit {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return ast.NewLit(token.FLOAT, s)
+}
+
+// Bool returns a boolean literal with the value b.
+func Bool(b bool) *ast.BasicLit {
+	return ast.NewBool(b)
+}
+
+// Null returns the null literal.
+func Null() *ast.BasicLit {
+	return ast.NewNull()
+}
+
+// Bottom returns the bottom literal _|_.
+func Bottom() *ast.BottomLit {
+	return &ast.BottomLit{}
+}
+
+// Call returns a call of fun with the given arguments.
+func Call(fun ast.Expr, args ...ast.Expr) *ast.CallExpr {
+	return ast.NewCall(fun, args...)
+}
+
+// Unary returns the unary expression op x.
+func Unary(op token.Token, x ast.Expr) *ast.UnaryExpr {
+	return &ast.UnaryExpr{Op: op, X: x}
+}
+
+// Binary returns the operands combined with the binary operator op, as in
+// (x1 op x2) op x3. It returns nil if there are no operands.
+func Binary(op token.Token, operands ...ast.Expr) ast.Expr {
+	return ast.NewBinExpr(op, operands...)
+}
+
+// And returns the unification of the given operands.
+func And(operands ...ast.Expr) ast.Expr {
+	return Binary(token.AND, operands...)
+}
+
+// Or returns the disjunction of the given operands.
+func Or(operands ...ast.Expr) ast.Expr {
+	return Binary(token.OR, operands...)
+}
+
+// Doc adds a doc comment with the given text to n. Long lines are wrapped.
+// It has no effect if text is empty.
+func Doc(n ast.Node, text string) {
+	if cg := internal.NewComment(true, text); cg != nil {
+		ast.AddComment(n, cg)
+	}
+}
+
+// LineComment adds a comment with the given text at the end of the line on
+// which n ends. It has no effect if text is empty.
+func LineComment(n ast.Node, text string) {
+	if cg := internal.NewComment(false, text); cg != nil {
+		ast.AddComment(n, cg)
+	}
+}
+
+// spaceDecls places each declaration on its own line and separates
+// documented declarations and sections of a file by a blank line. Positions
+// that were set explicitly are left untouched.
+func spaceDecls(decls []ast.Decl) {
+	var prev ast.Decl
+	for _, d := range decls {
+		rel := token.Newline
+		switch {
+		case prev == nil:
+		case hasDoc(d):
+			rel = token.NewSection
+		default:
+			_, isPkg := prev.(*ast.Package)
+			_, wasImport := prev.(*ast.ImportDecl)
+			_, isImport := d.(*ast.ImportDecl)
+			if isPkg || wasImport && !isImport {
+				rel = token.NewSection
+			}
+		}
+		setRelPos(d, rel)
+		prev = d
+	}
+}
+
+func hasDoc(n ast.Node) bool {
+	if isNil(n) {
+		return false
+	}
+	for _, cg := range ast.Comments(n) {
+		if cg.Doc {
+			return true
+		}
+	}
+	return false
+}
+
+// setRelPos sets the relative position of n, unless it was set before. The
+// relative position of a documented node is set on its doc comment, which
+// is printed first. Invalid nodes are left for Validate to report.
+func setRelPos(n ast.Node, rel token.RelPos) {
+	if isNil(n) {
+		return
+	}
+	for _, cg := range ast.Comments(n) {
+		if cg.Doc && len(cg.List) > 0 {
+			n = cg.List[0]
+			break
+		}
+	}
+	if n.Pos().RelPos() == token.NoRelPos {
+		ast.SetRelPos(n, rel)
+	}
+}
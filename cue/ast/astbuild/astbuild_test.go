@@ -0,0 +1,231 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package astbuild
+
+import (
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+)
+
+func TestBuild(t *testing.T) {
+	port := Field("port", And(Ident("int"), Unary(token.GTR, Int(0))), Attr("example", "8080"))
+	Doc(port, "port is the port on which the service listens.")
+	LineComment(port, "required")
+
+	first := String("a")
+	LineComment(first, "first")
+
+	service := Def("Service", Struct(
+		Field("name", Ident("string")),
+		port,
+		Optional("x-tags", List(Ident("string"))),
+		Field("replicas", Or(Unary(token.MUL, Int(1)), Ident("int"))),
+		Field("_hidden", Bool(true)),
+	))
+	Doc(service, "A Service describes a network service.")
+
+	f := File("svc",
+		Imports(Import("", "strings"), Import("l", "list")),
+		service,
+		Field("services", List(
+			Struct(Embed(Ref("#Service")), Field("name", String("web"))),
+			Struct(),
+		)),
+		Field("names", List(first, String("b"))),
+		Field("ratio", Float(2)),
+		Field("upper", Call(Ref("strings", "ToUpper"), String("x"))),
+		Field("none", Null()),
+	)
+
+	want := `package svc
+
+import (
+	"strings"
+	l "list"
+)
+
+// A Service describes a network service.
+#Service: {
+	name: string
+
+	// port is the port on which the service listens.
+	port: int & >0 @example(8080) // required
+	"x-tags"?: [string]
+	replicas:  *1 | int
+	"_hidden": true
+}
+services: [{
+	#Service
+	name: "web"
+}, {}]
+names: [
+	"a", // first
+	"b",
+]
+ratio: 2.0
+upper: strings.ToUpper("x")
+none:  null
+`
+	if err := Validate(f); err != nil {
+		t.Fatal(errors.Details(err, nil))
+	}
+	b, err := format.Node(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if _, err := parser.ParseFile("svc.cue", b); err != nil {
+		t.Errorf("output does not parse: %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	shared := Ident("x")
+	lateImport := File("p", Field("a", Int(1)))
+	lateImport.Decls = append(lateImport.Decls, Imports(Import("", "strings")))
+
+	testCases := []struct {
+		name string
+		node ast.Node
+		want string
+	}{{
+		name: "valid",
+		node: File("p", Field("a", Struct(Field("b", Int(1))))),
+	}, {
+		name: "parsed",
+		node: func() ast.Node {
+			f, _ := parser.ParseFile("in.cue", `
+			// doc
+			package p
+
+			import "strings"
+
+			a: "\(strings.ToUpper("x"))-y" // line
+			b: [for x in [1, 2] if x > 1 {x}]
+			c: [string]: int
+			`, parser.ParseComments)
+			return f
+		}(),
+	}, {
+		name: "nil",
+		node: nil,
+		want: "nil node",
+	}, {
+		name: "field without value",
+		node: &ast.Field{Label: Ident("a")},
+		want: "field without value",
+	}, {
+		name: "missing operand",
+		node: Struct(Field("a", &ast.BinaryExpr{Op: token.ADD, X: Int(1)})),
+		want: "binaryexpr without right operand",
+	}, {
+		name: "typed nil",
+		node: Struct(Field("a", (*ast.Ident)(nil))),
+		want: "field without value",
+	}, {
+		name: "nil element",
+		node: List(Int(1), nil),
+		want: "listlit without element",
+	}, {
+		name: "invalid identifier",
+		node: Field("a", Ident("a-b")),
+		want: `invalid identifier "a-b"`,
+	}, {
+		name: "invalid literal",
+		node: Field("a", &ast.BasicLit{Kind: token.STRING, Value: "abc"}),
+		want: "invalid string literal abc",
+	}, {
+		name: "invalid number",
+		node: Field("a", &ast.BasicLit{Kind: token.INT, Value: "1.5"}),
+		want: "invalid int literal 1.5",
+	}, {
+		name: "invalid operator",
+		node: Field("a", Binary(token.COLON, Int(1), Int(2))),
+		want: "invalid binary operator :",
+	}, {
+		name: "invalid field token",
+		node: &ast.Field{Label: Ident("a"), Token: token.ADD, Value: Int(1)},
+		want: "invalid field token +",
+	}, {
+		name: "invalid label",
+		node: &ast.Field{Label: Int(1), Value: Int(1)},
+		want: "invalid label 1",
+	}, {
+		name: "invalid comment",
+		node: func() ast.Node {
+			f := Field("a", Int(1))
+			ast.AddComment(f, &ast.CommentGroup{List: []*ast.Comment{{Text: "comment"}}})
+			return f
+		}(),
+		want: `invalid comment "comment"`,
+	}, {
+		name: "comment with newline",
+		node: func() ast.Node {
+			f := Field("a", Int(1))
+			ast.AddComment(f, &ast.CommentGroup{Doc: true, List: []*ast.Comment{{Text: "// a\nb"}}})
+			return f
+		}(),
+		want: `line comment "// a\nb" contains newline`,
+	}, {
+		name: "interpolation",
+		node: Field("a", &ast.Interpolation{Elts: []ast.Expr{String("a"), Int(1)}}),
+		want: "interpolation must have an odd number of elements",
+	}, {
+		name: "shared node",
+		node: Struct(Field("a", shared), Field("b", shared)),
+		want: "ident occurs more than once in tree",
+	}, {
+		name: "late package",
+		node: &ast.File{Decls: []ast.Decl{Field("a", Int(1)), &ast.Package{Name: Ident("p")}}},
+		want: "package clause must be first declaration",
+	}, {
+		name: "late import",
+		node: lateImport,
+		want: "imports must precede other declarations",
+	}, {
+		name: "bad expression",
+		node: Field("a", &ast.BadExpr{}),
+		want: "invalid badexpr",
+	}, {
+		name: "multiple errors",
+		node: Struct(Field("a", Ident("")), &ast.Field{Label: Ident("b")}),
+		want: `invalid identifier ""
+field without value`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.node)
+			got := ""
+			if err != nil {
+				var a []string
+				for _, e := range errors.Errors(err) {
+					a = append(a, e.Error())
+				}
+				got = strings.Join(a, "\n")
+			}
+			if got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package astbuild
+
+import (
+	"reflect"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
+)
+
+// Validate reports whether n is a well-formed syntax tree. It reports, among
+// others, missing operands and values, invalid identifiers, literals,
+// operators, and comments, misplaced package clauses and imports, and nodes
+// that occur more than once in the tree. The latter is not allowed as
+// positions and comments are stored in the nodes themselves.
+//
+// Trees that pass validation can be formatted with format.Node.
+func Validate(n ast.Node) errors.Error {
+	v := &validator{
+		seen:      map[ast.Node]bool{},
+		fragments: map[*ast.BasicLit]bool{},
+	}
+	if isNil(n) {
+		v.errf(nil, "nil node")
+		return v.errs
+	}
+	ast.Walk(n, v.before, nil)
+	return v.errs
+}
+
+type validator struct {
+	errs errors.Error
+
+	seen map[ast.Node]bool
+
+	// fragments holds the string fragments of interpolations, which are not
+	// valid literals by themselves.
+	fragments map[*ast.BasicLit]bool
+}
+
+func (v *validator) errf(n ast.Node, format string, args ...interface{}) {
+	v.errs = errors.Append(v.errs, errors.Newf(pos(n), format, args...))
+}
+
+// before checks the invariants of n. It returns false if n has nil children,
+// as ast.Walk cannot descend into these.
+func (v *validator) before(n ast.Node) bool {
+	switch n.(type) {
+	case *ast.CommentGroup, *ast.Comment:
+		// Walk may visit comments both as declarations and as comments.
+	default:
+		if v.seen[n] {
+			v.errf(n, "%s occurs more than once in tree", ast.Name(n))
+			return false
+		}
+		v.seen[n] = true
+	}
+
+	ok := true
+	require := func(name string, x ast.Node) {
+		if isNil(x) {
+			v.errf(n, "%s without %s", ast.Name(n), name)
+			ok = false
+		}
+	}
+
+	switch x := n.(type) {
+	case *ast.Comment:
+		switch {
+		case strings.HasPrefix(x.Text, "//"):
+			if strings.ContainsAny(x.Text, "\r\n") {
+				v.errf(n, "line comment %q contains newline", x.Text)
+			}
+		case strings.HasPrefix(x.Text, "/*") && strings.HasSuffix(x.Text, "*/"):
+		default:
+			v.errf(n, "invalid comment %q", x.Text)
+		}
+
+	case *ast.CommentGroup:
+		if len(x.List) == 0 {
+			v.errf(n, "empty comment group")
+		}
+		if x.Doc && x.Line {
+			v.errf(n, "comment group is both a doc and a line comment")
+		}
+		for _, c := range x.List {
+			require("comment", c)
+		}
+
+	case *ast.Attribute:
+		if !strings.HasPrefix(x.Text, "@") || !strings.HasSuffix(x.Text, ")") ||
+			!strings.Contains(x.Text, "(") {
+			v.errf(n, "invalid attribute %q", x.Text)
+		}
+
+	case *ast.Field:
+		require("label", x.Label)
+		require("value", x.Value)
+		switch l := x.Label.(type) {
+		case *ast.BasicLit:
+			if l.Kind != token.STRING {
+				v.errf(n, "invalid label %s", l.Value)
+			}
+		case *ast.Ident, *ast.Alias, *ast.ListLit, *ast.ParenExpr,
+			*ast.Interpolation, nil:
+		default:
+			v.errf(n, "invalid label type %T", l)
+		}
+		switch x.Token {
+		case token.ILLEGAL, token.COLON, token.ISA:
+		default:
+			v.errf(n, "invalid field token %s", x.Token)
+		}
+		for _, a := range x.Attrs {
+			require("attribute", a)
+		}
+
+	case *ast.Ident:
+		if !ast.IsValidIdent(x.Name) {
+			v.errf(n, "invalid identifier %q", x.Name)
+		}
+
+	case *ast.BasicLit:
+		if !v.fragments[x] {
+			v.checkLit(x)
+		}
+
+	case *ast.Interpolation:
+		if len(x.Elts)%2 == 0 {
+			v.errf(n, "interpolation must have an odd number of elements")
+			return false
+		}
+		for i, e := range x.Elts {
+			if i%2 == 1 {
+				require("expression", e)
+				continue
+			}
+			if lit, isLit := e.(*ast.BasicLit); isLit && lit != nil {
+				v.fragments[lit] = true
+			} else {
+				v.errf(n, "interpolation fragment must be a string literal")
+				ok = false
+			}
+		}
+
+	case *ast.StructLit:
+		for _, d := range x.Elts {
+			require("declaration", d)
+		}
+
+	case *ast.ListLit:
+		for _, e := range x.Elts {
+			require("element", e)
+		}
+
+	case *ast.ParenExpr:
+		require("expression", x.X)
+
+	case *ast.SelectorExpr:
+		require("operand", x.X)
+		require("selector", x.Sel)
+
+	case *ast.IndexExpr:
+		require("operand", x.X)
+		require("index", x.Index)
+
+	case *ast.SliceExpr:
+		require("operand", x.X)
+
+	case *ast.CallExpr:
+		require("function", x.Fun)
+		for _, a := range x.Args {
+			require("argument", a)
+		}
+
+	case *ast.UnaryExpr:
+		require("operand", x.X)
+		switch x.Op {
+		case token.ADD, token.SUB, token.NOT, token.MUL,
+			token.LSS, token.LEQ, token.GTR, token.GEQ,
+			token.NEQ, token.MAT, token.NMAT:
+		default:
+			v.errf(n, "invalid unary operator %s", x.Op)
+		}
+
+	case *ast.BinaryExpr:
+		require("left operand", x.X)
+		require("right operand", x.Y)
+		if !x.Op.IsOperator() || x.Op.Precedence() == 0 {
+			v.errf(n, "invalid binary operator %s", x.Op)
+		}
+
+	case *ast.Alias:
+		require("identifier", x.Ident)
+		require("expression", x.Expr)
+
+	case *ast.Comprehension:
+		if len(x.Clauses) == 0 {
+			v.errf(n, "comprehension without clauses")
+		}
+		for _, c := range x.Clauses {
+			require("clause", c)
+		}
+		require("value", x.Value)
+
+	case *ast.ForClause:
+		require("value", x.Value)
+		require("source", x.Source)
+
+	case *ast.IfClause:
+		require("condition", x.Condition)
+
+	case *ast.LetClause:
+		require("identifier", x.Ident)
+		require("expression", x.Expr)
+
+	case *ast.EmbedDecl:
+		require("expression", x.Expr)
+
+	case *ast.ImportDecl:
+		for _, s := range x.Specs {
+			require("import spec", s)
+		}
+
+	case *ast.ImportSpec:
+		require("path", x.Path)
+		if x.Path != nil && x.Path.Kind != token.STRING {
+			v.errf(n, "invalid import path %s", x.Path.Value)
+		}
+
+	case *ast.Package:
+		require("name", x.Name)
+
+	case *ast.File:
+		v.checkFile(x)
+		for _, d := range x.Decls {
+			require("declaration", d)
+		}
+
+	case *ast.BadExpr, *ast.BadDecl:
+		v.errf(n, "invalid %s", ast.Name(n))
+	}
+	return ok
+}
+
+func (v *validator) checkLit(x *ast.BasicLit) {
+	var err error
+	switch x.Kind {
+	case token.STRING:
+		_, err = literal.Unquote(x.Value)
+	case token.INT, token.FLOAT:
+		var info literal.NumInfo
+		err = literal.ParseNum(x.Value, &info)
+		if err == nil && x.Kind == token.INT && !info.IsInt() {
+			err = errors.Newf(x.Pos(), "not an integer")
+		}
+	case token.TRUE, token.FALSE, token.NULL:
+		if x.Value != x.Kind.String() {
+			v.errf(x, "invalid %s literal %q", x.Kind, x.Value)
+		}
+		return
+	default:
+		v.errf(x, "invalid literal kind %s", x.Kind)
+		return
+	}
+	if err != nil {
+		v.errf(x, "invalid %s literal %s", strings.ToLower(x.Kind.String()), x.Value)
+	}
+}
+
+// checkFile checks that a package clause and imports, if any, precede all
+// other declarations.
+func (v *validator) checkFile(f *ast.File) {
+	seenPkg := false
+	seenDecl := false
+	for _, d := range f.Decls {
+		switch x := d.(type) {
+		case *ast.CommentGroup, *ast.Attribute:
+
+		case *ast.Package:
+			if seenPkg || seenDecl {
+				v.errf(x, "package clause must be first declaration")
+			}
+			seenPkg = true
+
+		case *ast.ImportDecl:
+			seenPkg = true
+			if seenDecl {
+				v.errf(x, "imports must precede other declarations")
+			}
+
+		default:
+			seenPkg = true
+			seenDecl = true
+		}
+	}
+}
+
+func isNil(n ast.Node) bool {
+	if n == nil {
+		return true
+	}
+	v := reflect.ValueOf(n)
+	return v.Kind() == reflect.Ptr && v.IsNil()
+}
+
+// pos returns the position of n, if available. Computing the position of
+// some malformed nodes panics, in which case no position is reported.
+func pos(n ast.Node) (p token.Pos) {
+	if isNil(n) {
+		return token.NoPos
+	}
+	defer func() {
+		if recover() != nil {
+			p = token.NoPos
+		}
+	}()
+	return n.Pos()
+}
@@ -398,6 +398,9 @@ func applyCursor(v applyVisitor, c Cursor) {
 			apply(v, c, &n.Name)
 		}
 		apply(v, c, &n.Path)
+		for _, a := range n.Attrs {
+			apply(v, c, &a)
+		}
 
 	case *ast.BadDecl:
 		// nothing to do
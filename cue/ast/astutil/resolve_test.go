@@ -21,9 +21,9 @@ import (
 	"text/tabwriter"
 
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/cuetxtar"
 	"cuelang.org/go/internal/astinternal"
 	"cuelang.org/go/internal/cuetest"
-	"cuelang.org/go/internal/cuetxtar"
 )
 
 func TestResolve(t *testing.T) {
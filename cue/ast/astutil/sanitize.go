@@ -225,8 +225,9 @@ func (z *sanitizer) handleIdent(s *scope, n *ast.Ident) bool {
 		if spec == nil {
 			name := z.uniqueName(xi.Ident, false)
 			spec = z.addImport(&ast.ImportSpec{
-				Name: ast.NewIdent(name),
-				Path: x.Path,
+				Name:  ast.NewIdent(name),
+				Path:  x.Path,
+				Attrs: x.Attrs,
 			})
 			z.importMap[xi.ID] = spec
 			z.fileScope.insert(name, spec, spec)
@@ -16,6 +16,7 @@ package astutil
 
 import (
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -40,13 +41,18 @@ func ImportPathName(id string) string {
 
 // ImportInfo describes the information contained in an ImportSpec.
 type ImportInfo struct {
-	Ident   string // identifier used to refer to the import
-	PkgName string // name of the package
-	ID      string // full import path, including the name
-	Dir     string // import path, excluding the name
+	Ident   string   // identifier used to refer to the import
+	PkgName string   // name of the package
+	ID      string   // full import path, including the name and tags
+	Dir     string   // import path, excluding the name
+	Tags    []string // sorted tags with which the package is instantiated
 }
 
 // ParseImportSpec returns the name and full path of an ImportSpec.
+//
+// The ID of an import with @tag attributes includes its tags in normalized
+// form, so that each distinct instantiation of a package has its own ID.
+// Use SplitImportID to retrieve the import path and tags from an ID.
 func ParseImportSpec(spec *ast.ImportSpec) (info ImportInfo, err error) {
 	str, err := strconv.Unquote(spec.Path.Value)
 	if err != nil {
@@ -55,6 +61,20 @@ func ParseImportSpec(spec *ast.ImportSpec) (info ImportInfo, err error) {
 
 	info.ID = str
 
+	for _, a := range spec.Attrs {
+		if key, body := a.Split(); key == "tag" {
+			for _, t := range strings.Split(body, ",") {
+				if t = normalizeTag(t); t != "" {
+					info.Tags = append(info.Tags, t)
+				}
+			}
+		}
+	}
+	if len(info.Tags) > 0 {
+		sort.Strings(info.Tags)
+		info.ID += "@tag(" + strings.Join(info.Tags, ",") + ")"
+	}
+
 	if p := strings.LastIndexByte(str, ':'); p > 0 {
 		info.Dir = str[:p]
 		info.PkgName = str[p+1:]
@@ -72,6 +92,37 @@ func ParseImportSpec(spec *ast.ImportSpec) (info ImportInfo, err error) {
 	return info, nil
 }
 
+func normalizeTag(t string) string {
+	t = strings.TrimSpace(t)
+	if p := strings.IndexByte(t, '='); p > 0 {
+		t = strings.TrimSpace(t[:p]) + "=" + strings.TrimSpace(t[p+1:])
+	}
+	return t
+}
+
+// SplitImportID splits an import ID, as returned by ParseImportSpec, into
+// the import path and the tags with which the package is instantiated.
+func SplitImportID(id string) (path string, tags []string) {
+	p := strings.Index(id, "@tag(")
+	if p < 0 || !strings.HasSuffix(id, ")") {
+		return id, nil
+	}
+	return id[:p], strings.Split(id[p+len("@tag("):len(id)-1], ",")
+}
+
+// NewImport returns an ImportSpec for the given import ID, recreating the
+// @tag attribute of a parameterized import.
+func NewImport(name *ast.Ident, id string) *ast.ImportSpec {
+	path, tags := SplitImportID(id)
+	spec := ast.NewImport(name, path)
+	if len(tags) > 0 {
+		spec.Attrs = []*ast.Attribute{{
+			Text: "@tag(" + strings.Join(tags, ", ") + ")",
+		}}
+	}
+	return spec
+}
+
 // CopyComments associates comments of one node with another.
 // It may change the relative position of comments.
 func CopyComments(to, from ast.Node) {
@@ -26,32 +26,40 @@ func TestImportInfo(t *testing.T) {
 	testCases := []struct {
 		name string
 		path string
+		tags []string
 		want ImportInfo
 	}{
-		{"", "a.b/bar", ImportInfo{
+		{"", "a.b/bar", nil, ImportInfo{
 			Ident:   "bar",
 			PkgName: "bar",
 			ID:      "a.b/bar",
 			Dir:     "a.b/bar",
 		}},
-		{"foo", "a.b/bar", ImportInfo{
+		{"foo", "a.b/bar", nil, ImportInfo{
 			Ident:   "foo",
 			PkgName: "bar",
 			ID:      "a.b/bar",
 			Dir:     "a.b/bar",
 		}},
-		{"", "a.b/bar:foo", ImportInfo{
+		{"", "a.b/bar:foo", nil, ImportInfo{
 			Ident:   "foo",
 			PkgName: "foo",
 			ID:      "a.b/bar:foo",
 			Dir:     "a.b/bar",
 		}},
-		{"", "strings", ImportInfo{
+		{"", "strings", nil, ImportInfo{
 			Ident:   "strings",
 			PkgName: "strings",
 			ID:      "strings",
 			Dir:     "strings",
 		}},
+		{"prod", "a.b/bar:foo", []string{"@tag(x = 1, prod)", "@foo(y)", "@tag(a)"}, ImportInfo{
+			Ident:   "prod",
+			PkgName: "foo",
+			ID:      "a.b/bar:foo@tag(a,prod,x=1)",
+			Dir:     "a.b/bar",
+			Tags:    []string{"a", "prod", "x=1"},
+		}},
 	}
 	for _, tc := range testCases {
 		t.Run(path.Join(tc.name, tc.path), func(t *testing.T) {
@@ -59,16 +67,26 @@ func TestImportInfo(t *testing.T) {
 			if tc.name != "" {
 				ident = ast.NewIdent(tc.name)
 			}
-			got, err := ParseImportSpec(&ast.ImportSpec{
+			spec := &ast.ImportSpec{
 				Name: ident,
 				Path: ast.NewString(tc.path),
-			})
+			}
+			for _, text := range tc.tags {
+				spec.Attrs = append(spec.Attrs, &ast.Attribute{Text: text})
+			}
+			got, err := ParseImportSpec(spec)
 			if err != nil {
 				t.Fatal(err)
 			}
 			if !cmp.Equal(got, tc.want) {
 				t.Error(cmp.Diff(got, tc.want))
 			}
+
+			// The ID must round-trip.
+			spec = NewImport(ident, got.ID)
+			if info, _ := ParseImportSpec(spec); !cmp.Equal(info, tc.want) {
+				t.Error(cmp.Diff(info, tc.want))
+			}
 		})
 	}
 }
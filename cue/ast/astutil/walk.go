@@ -145,6 +145,9 @@ func walk(v visitor, node ast.Node) {
 			walk(v, n.Name)
 		}
 		walk(v, n.Path)
+		for _, a := range n.Attrs {
+			walk(v, a)
+		}
 
 	case *ast.BadDecl:
 		// nothing to do
@@ -150,6 +150,9 @@ func walk(v visitor, node Node) {
 			walk(v, n.Name)
 		}
 		walk(v, n.Path)
+		for _, a := range n.Attrs {
+			walk(v, a)
+		}
 
 	case *BadDecl:
 		// nothing to do
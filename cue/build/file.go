@@ -42,6 +42,7 @@ const (
 	Protobuf    Encoding = "proto"
 	TextProto   Encoding = "textproto"
 	BinaryProto Encoding = "pb"
+	Starlark    Encoding = "starlark"
 
 	// TODO:
 	// TOML
@@ -70,6 +71,15 @@ const (
 	JSONSchema   Interpretation = "jsonschema"
 	OpenAPI      Interpretation = "openapi"
 	ProtobufJSON Interpretation = "pb"
+
+	// Helm interprets a CUE definition, by default #Values, as the schema
+	// of the values of a Helm chart. It is only supported for output, as
+	// the contents of a values.schema.json file.
+	Helm Interpretation = "helm"
+
+	// Terraform interprets data as Terraform configuration. It is only
+	// supported for output in JSON.
+	Terraform Interpretation = "terraform"
 )
 
 // A Form specifies the form in which a program should be represented.
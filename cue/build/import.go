@@ -16,9 +16,9 @@ package build
 
 import (
 	"sort"
-	"strconv"
 
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/token"
 )
@@ -61,7 +61,7 @@ func (inst *Instance) complete() errors.Error {
 			}
 			for _, spec := range d.Specs {
 				quoted := spec.Path.Value
-				path, err := strconv.Unquote(quoted)
+				info, err := astutil.ParseImportSpec(spec)
 				if err != nil {
 					inst.Err = errors.Append(inst.Err,
 						errors.Newf(
@@ -69,6 +69,7 @@ func (inst *Instance) complete() errors.Error {
 							"%s: parser returned invalid quoted string: <%s>",
 							f.Filename, quoted))
 				}
+				path := info.ID
 				imported[path] = append(imported[path], spec.Pos())
 			}
 		}
@@ -100,6 +100,11 @@ type Instance struct {
 	// directory.
 	AllTags []string `api:"alpha"`
 
+	// Experiments lists the experiments enabled for all files of this
+	// instance, in addition to those enabled by @experiment attributes in
+	// the individual files.
+	Experiments []string `api:"alpha"`
+
 	// Incomplete reports whether any dependencies had an error.
 	Incomplete bool `api:"alpha"`
 
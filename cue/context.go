@@ -105,6 +105,68 @@ func InferBuiltins(elide bool) BuildOption {
 	}
 }
 
+// CacheKey sets the key under which BuildInstance looks up and stores the
+// Value of the instance in the BuildCache of the Context. The key must
+// identify all inputs of the build, such as the version of the module of
+// the instance and the injected tags. The cache is not used if no key is
+// given.
+func CacheKey(key string) BuildOption {
+	return func(o *runtime.Config) { o.CacheKey = key }
+}
+
+// A BuildCache caches the Values built for instances, allowing an embedding
+// server, for instance, to avoid repeatedly building the same schema for
+// different requests. A BuildCache is set for a Context with the
+// cuecontext.BuildCache option.
+//
+// Values are fully evaluated before they are stored and may be retrieved
+// from a Context other than the one with which they were built. A
+// BuildCache that is shared by Contexts used concurrently must be safe for
+// concurrent use.
+type BuildCache interface {
+	// Get reports the Value stored for key, if any.
+	Get(key string) (v Value, ok bool)
+
+	// Put stores v for key.
+	Put(key string, v Value)
+}
+
+// Metrics receives counts of the operations of a Context, allowing a
+// program that embeds CUE, such as a server that evaluates configurations
+// for each request, to export them. Metrics are set for a Context with the
+// cuecontext.Metrics option. Metrics that are shared by Contexts used
+// concurrently must be safe for concurrent use.
+//
+// The *expvar.Map type implements Metrics. A Prometheus counter vector
+// with a single label can be adapted with a function calling Add on the
+// counter for that label.
+//
+// The following counters are reported:
+//
+//    instances           instances built
+//    build_cache_hits    instances retrieved from the BuildCache
+//    unifications        unifications of values
+//    disjuncts           disjuncts evaluated
+//    builtin_cache_hits  builtin calls answered from the cache
+//    nodes_allocated     evaluation nodes allocated
+//    nodes_reused        evaluation nodes reused from a free list
+//    nodes_freed         evaluation nodes returned to a free list
+//
+// The number of allocated nodes less the number of freed nodes indicates
+// the memory retained by the evaluator.
+type Metrics interface {
+	// Add adds delta to the counter with the given name.
+	Add(name string, delta int64)
+}
+
+func (c *Context) buildCache(cfg *runtime.Config) BuildCache {
+	if cfg.CacheKey == "" {
+		return nil
+	}
+	cache, _ := c.runtime().BuildCache().(BuildCache)
+	return cache
+}
+
 func (c *Context) parseOptions(options []BuildOption) (cfg runtime.Config) {
 	cfg.Runtime = (*runtime.Runtime)(c)
 	for _, f := range options {
@@ -117,12 +179,32 @@ func (c *Context) parseOptions(options []BuildOption) (cfg runtime.Config) {
 //
 // The returned Value will represent an error, accessible through Err, if any
 // error occurred.
+//
+// If the CacheKey option is given and the Context has a BuildCache, the
+// Value is looked up in the cache before building the instance and stored
+// in the cache afterwards, unless it has errors.
 func (c *Context) BuildInstance(i *build.Instance, options ...BuildOption) Value {
 	cfg := c.parseOptions(options)
+	cache := c.buildCache(&cfg)
+	if cache != nil {
+		if v, ok := cache.Get(cfg.CacheKey); ok && v.v != nil {
+			if m := c.runtime().Metrics(); m != nil {
+				m.Add("build_cache_hits", 1)
+			}
+			return c.make(v.v)
+		}
+	}
 	v, err := c.runtime().Build(&cfg, i)
 	if err != nil {
 		return c.makeError(err)
 	}
+	if cache != nil {
+		ctx := c.ctx()
+		v.Finalize(ctx)
+		if ctx.Err() == nil && v.Err(ctx, adt.Finalized) == nil {
+			cache.Put(cfg.CacheKey, c.make(v))
+		}
+	}
 	return c.make(v)
 }
 
@@ -242,6 +324,61 @@ func (c *Context) CompileBytes(b []byte, options ...BuildOption) Value {
 // 	return c.compile(c.runtime().Compile("", b))
 // }
 
+// Release releases the data that c holds for the instance from which v
+// was built, such as the string or file compiled with CompileString or
+// BuildFile. Long-running processes that build many ad-hoc values should
+// release them once they are no longer needed, as c otherwise retains them
+// for its lifetime.
+//
+// Values of the instance remain valid after it is released. The instance,
+// however, is no longer available for import by path and is built anew if
+// it is passed to BuildInstance again. The instances imported by v are not
+// released. Field names are interned for the lifetime of the process and
+// are never released.
+func (c *Context) Release(v Value) {
+	if v.v == nil {
+		return
+	}
+	root := v.v
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	c.runtime().RemoveInst(root)
+}
+
+// Copy returns a copy of v that belongs to c, allowing v to be combined with
+// other Values of c even if v was created with a different Context. This
+// allows, for instance, a long-running server to periodically rebuild its
+// schemas in a new Context and migrate values from the old one.
+//
+// The value of v is fully evaluated before it is copied. Imported packages
+// referred to by v are resolved within c. The copy is a root value: its Path
+// is empty.
+func (c *Context) Copy(v Value) Value {
+	if v.v == nil {
+		return v
+	}
+	v.v.Finalize(v.ctx())
+	x := copyVertex(v.v)
+	x.Parent = nil
+	return c.make(x)
+}
+
+// copyVertex returns a deep copy of the arcs of the finalized vertex v.
+func copyVertex(v *adt.Vertex) *adt.Vertex {
+	x := v.Clone()
+	if b, ok := x.BaseValue.(*adt.Vertex); ok {
+		x.BaseValue = copyVertex(b)
+	}
+	x.Arcs = make([]*adt.Vertex, len(v.Arcs))
+	for i, a := range v.Arcs {
+		a = copyVertex(a)
+		a.Parent = x
+		x.Arcs[i] = a
+	}
+	return x
+}
+
 func (c *Context) make(v *adt.Vertex) Value {
 	return newValueRoot(c.runtime(), newContext(c.runtime()), v)
 }
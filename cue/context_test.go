@@ -80,3 +80,58 @@ func TestNewList(t *testing.T) {
 		})
 	}
 }
+
+func TestCopy(t *testing.T) {
+	old := cuecontext.New()
+	schema := old.CompileString(`
+	import "strings"
+
+	#A: {
+		name: string
+		id:   strings.ToUpper(name)
+		tags: [...string]
+	}
+	`).LookupPath(cue.ParsePath("#A"))
+
+	ctx := cuecontext.New()
+	a := ctx.Copy(schema)
+
+	testCases := []struct {
+		desc string
+		v    cue.Value
+		out  string
+	}{{
+		desc: "unify",
+		v:    a.Unify(ctx.CompileString(`{name: "web", tags: ["x"]}`)),
+		out:  "{\n\tname: \"web\"\n\tid:   \"WEB\"\n\ttags: [\"x\"]\n}",
+	}, {
+		desc: "closed",
+		v:    a.Unify(ctx.CompileString(`{foo: 1}`)),
+		out:  "_|_ // field not allowed: foo",
+	}, {
+		desc: "fill",
+		v:    ctx.CompileString(`x: _`).FillPath(cue.ParsePath("x"), a).LookupPath(cue.ParsePath("x.tags")),
+		out:  "[...string]",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := fmt.Sprint(tc.v)
+			if got != tc.out {
+				t.Errorf(" got: %v\nwant: %v", got, tc.out)
+			}
+		})
+	}
+}
+
+func TestRelease(t *testing.T) {
+	ctx := cuecontext.New()
+	v := ctx.CompileString(`a: b: 1, c: a.b + 1`)
+	ctx.Release(v.LookupPath(cue.ParsePath("a.b")))
+
+	// Values remain valid after their instance is released.
+	got := fmt.Sprint(v.Unify(ctx.CompileString(`d: 3`)))
+	want := "{\n\ta: {\n\t\tb: 1\n\t}\n\td: 3\n\tc: 2\n}"
+	if got != want {
+		t.Errorf(" got: %v\nwant: %v", got, want)
+	}
+}
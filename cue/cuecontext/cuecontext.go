@@ -24,8 +24,87 @@ import (
 // Option controls a build context.
 type Option interface{ buildOption() }
 
+type option func(r *runtime.Runtime)
+
+func (option) buildOption() {}
+
+// DisjunctionErrors limits the errors reported for a disjunction of which
+// all disjuncts fail to those of the n disjuncts that came closest to
+// succeeding, as measured by the number of fields for which they report
+// an error. By default, or if n is 0, the errors of all disjuncts are
+// reported.
+func DisjunctionErrors(n int) Option {
+	return option(func(r *runtime.Runtime) {
+		r.SetDisjunctionErrors(n)
+	})
+}
+
+// BuildCache sets the cache in which the Context stores the Values of
+// instances built with a cue.CacheKey option. The cache may be shared by
+// multiple Contexts.
+func BuildCache(c cue.BuildCache) Option {
+	return option(func(r *runtime.Runtime) {
+		r.SetBuildCache(c)
+	})
+}
+
+// Metrics sets the Metrics to which the Context adds the counts of its
+// operations. See cue.Metrics for the counters that are reported.
+func Metrics(m cue.Metrics) Option {
+	return option(func(r *runtime.Runtime) {
+		r.SetMetrics(m)
+	})
+}
+
+// AllowPackages restricts the builtin packages that may be used to those
+// matching any of the given patterns. A pattern is either an import path,
+// like "strings", or an import path followed by "/...", like "encoding/...",
+// which also matches all packages below it.
+//
+// Together with DenyPackages and MaxRegexpLen, this allows untrusted CUE to
+// be evaluated with only the capabilities it needs. Using a package that is
+// not allowed results in an error.
+func AllowPackages(patterns ...string) Option {
+	return option(func(r *runtime.Runtime) {
+		r.SetAllowedPackages(append([]string{}, patterns...))
+	})
+}
+
+// DenyPackages prohibits the use of the builtin packages matching any of the
+// given patterns, using the same patterns as AllowPackages. For instance,
+// "tool/..." denies all packages for running commands and "crypto/..."
+// denies the cryptographic packages.
+func DenyPackages(patterns ...string) Option {
+	return option(func(r *runtime.Runtime) {
+		r.SetDeniedPackages(append([]string{}, patterns...))
+	})
+}
+
+// MaxRegexpLen limits the length of the regular expressions used with the
+// =~ and !~ operators to n bytes. Using a longer regular expression results
+// in an error. The regular expressions used by package regexp are not
+// limited, but the package can be prohibited with DenyPackages.
+func MaxRegexpLen(n int) Option {
+	return option(func(r *runtime.Runtime) {
+		r.SetMaxRegexpLen(n)
+	})
+}
+
+// Seed sets the secret from which builtins, such as those of package
+// crypto/hkdf, derive pseudo-random values. Evaluation remains deterministic:
+// Contexts with the same seed derive the same values. The seed should be
+// kept secret if the derived values must not be predictable.
+func Seed(seed []byte) Option {
+	return option(func(r *runtime.Runtime) {
+		r.SetSeed(append([]byte(nil), seed...))
+	})
+}
+
 // New creates a new Context.
 func New(options ...Option) *cue.Context {
 	r := runtime.New()
+	for _, o := range options {
+		o.(option)(r)
+	}
 	return (*cue.Context)(r)
 }
@@ -15,11 +15,14 @@
 package cuecontext
 
 import (
+	"expvar"
 	"fmt"
 	"testing"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
 )
 
 func TestAPI(t *testing.T) {
@@ -72,3 +75,230 @@ func TestConcurrency(t *testing.T) {
 		`)
 	}()
 }
+
+func TestDisjunctionErrors(t *testing.T) {
+	const src = `
+#A: {kind: "a", x: string, y: int}
+#B: {kind: "b", x: string, y: int}
+#C: {kind: "c", x: string, y: string}
+v: #A | #B | #C
+v: {kind: "b", x: 1, y: 2}
+`
+	testCases := []struct {
+		name string
+		opts []Option
+		want string
+	}{{
+		name: "all",
+		want: `v: 3 errors in empty disjunction:
+v.kind: conflicting values "a" and "b":
+    2:12
+    5:4
+    6:11
+v.kind: conflicting values "c" and "b":
+    4:12
+    5:14
+    6:11
+v.x: conflicting values 1 and string (mismatched types int and string):
+    3:20
+    5:9
+    6:19
+`,
+	}, {
+		name: "closest",
+		opts: []Option{DisjunctionErrors(1)},
+		want: `v: 1 errors in empty disjunction (closest 1 of 3 disjuncts):
+v.x: conflicting values 1 and string (mismatched types int and string):
+    3:20
+    5:9
+    6:19
+`,
+	}, {
+		name: "closest two",
+		opts: []Option{DisjunctionErrors(2)},
+		want: `v: 2 errors in empty disjunction (closest 2 of 3 disjuncts):
+v.kind: conflicting values "a" and "b":
+    2:12
+    5:4
+    6:11
+v.x: conflicting values 1 and string (mismatched types int and string):
+    3:20
+    5:9
+    6:19
+`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := New(tc.opts...).CompileString(src)
+			err := v.LookupPath(cue.ParsePath("v")).Err()
+			got := errors.Details(err, nil)
+			if got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+type mapCache struct {
+	values map[string]cue.Value
+	gets   int
+}
+
+func (c *mapCache) Get(key string) (cue.Value, bool) {
+	c.gets++
+	v, ok := c.values[key]
+	return v, ok
+}
+
+func (c *mapCache) Put(key string, v cue.Value) {
+	c.values[key] = v
+}
+
+func TestBuildCache(t *testing.T) {
+	cache := &mapCache{values: map[string]cue.Value{}}
+	newInstance := func(src string) *build.Instance {
+		inst := build.NewContext().NewInstance("schema.cue", nil)
+		if err := inst.AddFile("schema.cue", src); err != nil {
+			t.Fatal(err)
+		}
+		return inst
+	}
+
+	ctx1 := New(BuildCache(cache))
+	v1 := ctx1.BuildInstance(newInstance(`#A: {x: int, y: x + 1}`), cue.CacheKey("v1"))
+	if err := v1.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(cache.values) != 1 {
+		t.Fatalf("got %d cached values; want 1", len(cache.values))
+	}
+
+	// The cached value is returned for the same key, regardless of the
+	// instance, and can be used in another Context.
+	ctx2 := New(BuildCache(cache))
+	v2 := ctx2.BuildInstance(newInstance(`#A: string`), cue.CacheKey("v1"))
+	x := ctx2.CompileString(`x: 2`)
+	got := fmt.Sprint(v2.LookupPath(cue.ParsePath("#A")).Unify(x))
+	if want := "{\n\tx: 2\n\ty: 3\n}"; got != want {
+		t.Errorf("got %s; want %s", got, want)
+	}
+
+	// Instances with errors are not cached.
+	v3 := ctx2.BuildInstance(newInstance(`a: 1, a: 2`), cue.CacheKey("v3"))
+	if v3.Err() == nil {
+		t.Error("expected error")
+	}
+	if _, ok := cache.values["v3"]; ok {
+		t.Error("instance with errors was cached")
+	}
+
+	// The cache is not used without a key.
+	gets := cache.gets
+	ctx2.BuildInstance(newInstance(`a: 1`))
+	if cache.gets != gets {
+		t.Error("cache used without key")
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	m := new(expvar.Map)
+	cache := &mapCache{values: map[string]cue.Value{}}
+	ctx := New(Metrics(m), BuildCache(cache))
+
+	inst := build.NewContext().NewInstance("schema.cue", nil)
+	if err := inst.AddFile("schema.cue", `a: *1 | 2, b: a + 1`); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		v := ctx.BuildInstance(inst, cue.CacheKey("schema"))
+		if err := v.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	get := func(name string) int64 {
+		x, _ := m.Get(name).(*expvar.Int)
+		if x == nil {
+			return 0
+		}
+		return x.Value()
+	}
+	if got := get("instances"); got != 1 {
+		t.Errorf("instances: got %d; want 1", got)
+	}
+	if got := get("build_cache_hits"); got != 1 {
+		t.Errorf("build_cache_hits: got %d; want 1", got)
+	}
+	for _, name := range []string{"unifications", "disjuncts", "nodes_allocated"} {
+		if get(name) == 0 {
+			t.Errorf("%s: got 0; want > 0", name)
+		}
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []Option
+		src  string
+		want string
+	}{{
+		name: "denied",
+		opts: []Option{DenyPackages("crypto/...", "tool/...")},
+		src: `import "crypto/sha256"
+		a: sha256.Sum256("x")`,
+		want: `use of builtin package "crypto/sha256" not allowed:
+    1:8
+`,
+	}, {
+		name: "not denied",
+		opts: []Option{DenyPackages("crypto/...", "tool/...")},
+		src: `import "strings"
+		a: strings.ToUpper("x")`,
+		want: `{
+	a: "X"
+}`,
+	}, {
+		name: "not allowed",
+		opts: []Option{AllowPackages("strings", "encoding/...")},
+		src: `import "list"
+		a: list.Sum([1])`,
+		want: `use of builtin package "list" not allowed:
+    1:8
+`,
+	}, {
+		name: "allowed",
+		opts: []Option{AllowPackages("strings", "encoding/...")},
+		src: `import "encoding/json"
+		a: json.Marshal(1)`,
+		want: `{
+	a: "1"
+}`,
+	}, {
+		name: "short regexp",
+		opts: []Option{MaxRegexpLen(5)},
+		src:  `a: "foo" =~ "^fo+$"`,
+		want: `{
+	a: true
+}`,
+	}, {
+		name: "long regexp",
+		opts: []Option{MaxRegexpLen(5)},
+		src:  `a: "foo" =~ "^(fo+)$"`,
+		want: `a: regular expression of 7 bytes exceeds limit of 5 bytes:
+    1:4
+`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := New(tc.opts...).CompileString(tc.src)
+			got := fmt.Sprint(v)
+			if err := v.Validate(); err != nil {
+				got = errors.Details(err, nil)
+			}
+			if got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
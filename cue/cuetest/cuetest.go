@@ -0,0 +1,81 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cuetest provides helpers for tests that use CUE values. It allows
+// Values to be created from CUE source or Go values without managing a
+// cue.Context:
+//
+//	func TestReplicas(t *testing.T) {
+//		v := cuetest.Value(t, "replicas: 3")
+//		...
+//	}
+//
+// Each test gets its own Context, which is released when the test completes.
+// As Values of different Contexts cannot always be combined, Values that are
+// used together should be created for the same test. In particular, a
+// subtest that combines its Values with those of its parent should create
+// them with the Context of the parent.
+package cuetest
+
+import (
+	"sync"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+)
+
+// contexts maps each test to its Context.
+var contexts sync.Map // map[testing.TB]*cue.Context
+
+// Context returns the Context used for t by the functions of this package.
+// It is created on first use and released when t and its subtests complete.
+func Context(t testing.TB) *cue.Context {
+	if c, ok := contexts.Load(t); ok {
+		return c.(*cue.Context)
+	}
+	c, loaded := contexts.LoadOrStore(t, cuecontext.New())
+	if !loaded {
+		t.Cleanup(func() { contexts.Delete(t) })
+	}
+	return c.(*cue.Context)
+}
+
+// Value compiles src, which may be CUE file or expression, like "x: 3" or
+// "[1, 2]", into a Value. It fails t if src does not compile or evaluates
+// to an error. Use the CompileString method of Context(t) to create Values
+// that are expected to have errors.
+func Value(t testing.TB, src string, options ...cue.BuildOption) cue.Value {
+	t.Helper()
+
+	v := Context(t).CompileString(src, options...)
+	if err := v.Err(); err != nil {
+		t.Fatalf("invalid CUE value %q: %s", src, errors.Details(err, nil))
+	}
+	return v
+}
+
+// Encode converts the Go value x, such as a map, slice, or struct, into a
+// Value as the Encode method of cue.Context does. It fails t if x cannot be
+// converted.
+func Encode(t testing.TB, x interface{}) cue.Value {
+	t.Helper()
+
+	v := Context(t).Encode(x)
+	if err := v.Err(); err != nil {
+		t.Fatalf("cannot encode %T value: %s", x, errors.Details(err, nil))
+	}
+	return v
+}
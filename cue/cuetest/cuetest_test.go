@@ -0,0 +1,99 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cuetest
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"cuelang.org/go/cue"
+)
+
+func TestValue(t *testing.T) {
+	testCases := []struct {
+		src  string
+		want string
+	}{{
+		src:  "x: 3",
+		want: "{\n\tx: 3\n}",
+	}, {
+		src:  "[1, 2]",
+		want: "[1, 2]",
+	}, {
+		src:  "1 + 2",
+		want: "3",
+	}, {
+		src:  "{a: 1} & {a: int}",
+		want: "{\n\ta: 1\n}",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.src, func(t *testing.T) {
+			if got := fmt.Sprint(Value(t, tc.src)); got != tc.want {
+				t.Errorf("got %s; want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	v := Encode(t, map[string]interface{}{"a": []int{1, 2}})
+	w := Value(t, "a: [...int]")
+	if err := w.Unify(v).Validate(cue.Concrete(true)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestContext(t *testing.T) {
+	var sub testing.TB
+	t.Run("sub", func(t *testing.T) {
+		sub = t
+		if Context(t) != Context(t) {
+			t.Error("different Contexts for the same test")
+		}
+	})
+	if _, ok := contexts.Load(sub); ok {
+		t.Error("Context of completed subtest not released")
+	}
+	if _, ok := contexts.Load(t); ok {
+		t.Error("Context created for parent test")
+	}
+}
+
+func TestFatal(t *testing.T) {
+	ft := &fakeT{TB: t}
+	done := make(chan bool)
+	go func() {
+		defer close(done)
+		Value(ft, "a: 1, a: 2")
+	}()
+	<-done
+	if want := "invalid CUE value \"a: 1, a: 2\": a: conflicting values 2 and 1:\n    1:4\n    1:10\n"; ft.msg != want {
+		t.Errorf("got %q; want %q", ft.msg, want)
+	}
+}
+
+// fakeT records the message passed to Fatalf.
+type fakeT struct {
+	testing.TB
+	msg string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Fatalf(format string, args ...interface{}) {
+	t.msg = fmt.Sprintf(format, args...)
+	runtime.Goexit()
+}
@@ -12,6 +12,35 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+// Package cuetxtar runs golden tests defined in txtar archives, as used for
+// the tests of the CUE project itself. It can be used to test the schemas,
+// exporters, and other tools of projects that use CUE in the same way.
+//
+// Each .txtar file in the directory tree of a test is a test case. The files
+// of an archive that are not in a subdirectory are loaded as the CUE
+// instance for the test. The results written by the test are compared to
+// the files named out/<name> in the archive, where name is the name of the
+// test:
+//
+//	func TestSchema(t *testing.T) {
+//		test := cuetxtar.TxTarTest{
+//			Root: "./testdata",
+//			Name: "schema",
+//		}
+//		test.Run(t, func(t *cuetxtar.Test) {
+//			v := cuecontext.New().BuildInstance(t.ValidInstances()[0])
+//			fmt.Fprint(t, v)
+//		})
+//	}
+//
+// The results of a test are compared to the contents of out/schema and the
+// results written to t.Writer("json") to those of out/schema/json. Missing
+// or outdated golden files are added or updated if Update is set or the
+// CUE_UPDATE environment variable is set, rather than reported as errors.
+//
+// The comment of an archive may hold lines of the form "#key" or
+// "#key: value" to configure a test case. These can be queried with the
+// HasTag, Value, and Bool methods of Test. The tag #skip skips a test case.
 package cuetxtar
 
 import (
@@ -48,9 +77,9 @@ type TxTarTest struct {
 	// TODO: by default derive from the current base directory name.
 	Name string
 
-	// If Update is true, TestTxTar will update the out/Name file if it differs
-	// from the original input. The user must set the output in Gold for this
-	// to be detected.
+	// If Update is true, Run updates the out/Name file if it differs from
+	// the output of the test rather than reporting an error. Run also does so
+	// if the CUE_UPDATE environment variable is set.
 	Update bool
 
 	// Skip is a map of tests to skip to their skip message.
@@ -84,6 +113,8 @@ type Test struct {
 	hasGold bool
 }
 
+// Write writes b to the default output of the test, which is compared to the
+// out/<name> file of the archive.
 func (t *Test) Write(b []byte) (n int, err error) {
 	if t.buf == nil {
 		t.buf = &bytes.Buffer{}
@@ -97,6 +128,7 @@ type file struct {
 	buf  *bytes.Buffer
 }
 
+// HasTag reports whether the comment of the archive has a line #key.
 func (t *Test) HasTag(key string) bool {
 	prefix := []byte("#" + key)
 	s := bufio.NewScanner(bytes.NewReader(t.Archive.Comment))
@@ -109,6 +141,8 @@ func (t *Test) HasTag(key string) bool {
 	return false
 }
 
+// Value searches for a line starting with #key: value in the comment of the
+// archive and returns the value if found.
 func (t *Test) Value(key string) (value string, ok bool) {
 	prefix := []byte("#" + key + ":")
 	s := bufio.NewScanner(bytes.NewReader(t.Archive.Comment))
@@ -205,13 +239,13 @@ func (t *Test) ValidInstances(args ...string) []*build.Instance {
 	return a
 }
 
-// RawInstances returns the intstances represented by this .txtar file. The
+// RawInstances returns the instances represented by this .txtar file. The
 // returned instances are not checked for errors.
 func (t *Test) RawInstances(args ...string) []*build.Instance {
 	return Load(t.Archive, t.Dir, args...)
 }
 
-// Load loads the intstances of a txtar file. By default, it only loads
+// Load loads the instances of a txtar file. By default, it only loads
 // files in the root directory. Relative files in the archive are given an
 // absolution location by prefixing it with dir.
 func Load(a *txtar.Archive, dir string, args ...string) []*build.Instance {
@@ -252,8 +286,13 @@ func (x *TxTarTest) Run(t *testing.T, f func(tc *Test)) {
 		}
 
 		str := filepath.ToSlash(fullpath)
-		p := strings.Index(str, "/testdata/")
-		testName := str[p+len("/testdata/") : len(str)-len(".txtar")]
+		testName := str[:len(str)-len(".txtar")]
+		if p := strings.Index(str, "/testdata/"); p >= 0 {
+			testName = testName[p+len("/testdata/"):]
+		} else if rel, err := filepath.Rel(root, fullpath); err == nil {
+			rel = filepath.ToSlash(rel)
+			testName = rel[:len(rel)-len(".txtar")]
+		}
 
 		t.Run(testName, func(t *testing.T) {
 			a, err := txtar.ParseFile(fullpath)
@@ -0,0 +1,46 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cuetxtar_test
+
+import (
+	"fmt"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/cuetxtar"
+)
+
+func TestTxTar(t *testing.T) {
+	test := cuetxtar.TxTarTest{
+		Root: "./testdata",
+		Name: "test",
+	}
+
+	var names []string
+	test.Run(t, func(t *cuetxtar.Test) {
+		names = append(names, t.Name())
+
+		v := cuecontext.New().BuildInstance(t.ValidInstances()[0])
+		fmt.Fprintln(t, v)
+
+		if s, ok := t.Value("answer"); ok {
+			fmt.Fprintln(t.Writer("answer"), s)
+		}
+	})
+
+	if got, want := fmt.Sprint(names), "[TestTxTar/simple]"; got != want {
+		t.Errorf("got tests %v; want %v", got, want)
+	}
+}
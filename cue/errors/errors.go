@@ -71,6 +71,7 @@ func As(err error, target interface{}) bool {
 // internationalized messages. A Message is typically used as an embedding
 // in a CUE message.
 type Message struct {
+	key    string
 	format string
 	args   []interface{}
 }
@@ -82,6 +83,22 @@ func NewMessage(format string, args []interface{}) Message {
 	return Message{format: format, args: args}
 }
 
+// NewKeyedMessage is like NewMessage, but identifies the message by the given
+// key instead of by its format string. Keys are used to look up messages in a
+// Catalog and should remain unchanged when the wording of a message changes.
+func NewKeyedMessage(key, format string, args []interface{}) Message {
+	return Message{key: key, format: format, args: args}
+}
+
+// Key returns the key that identifies the message in a Catalog. This is the
+// format string of the message unless it was created with NewKeyedMessage.
+func (m *Message) Key() string {
+	if m.key != "" {
+		return m.key
+	}
+	return m.format
+}
+
 // Msg returns a printf-style format string and its arguments for human
 // consumption.
 func (m *Message) Msg() (format string, args []interface{}) {
@@ -115,6 +132,24 @@ type Error interface {
 	Msg() (format string, args []interface{})
 }
 
+// Key returns the key that identifies the message of err in a Catalog. This
+// is the format string of the message, unless the message was created with an
+// explicit key.
+func Key(err Error) string {
+	if k, ok := err.(interface{ Key() string }); ok {
+		return k.Key()
+	}
+	format, _ := err.Msg()
+	return format
+}
+
+// A Catalog maps message keys, as reported by Key, to printf-style format
+// strings with which to print the corresponding messages, for instance to
+// print them in another language. A format string is passed the same
+// arguments as the original and may use explicit argument indexes, as in
+// %[2]s, to reorder them.
+type Catalog map[string]string
+
 // Positions returns all positions returned by an error, sorted
 // by relevance when possible and with duplicates removed.
 func Positions(err error) []token.Pos {
@@ -224,6 +259,10 @@ func (e *wrapped) Msg() (format string, args []interface{}) {
 	return e.main.Msg()
 }
 
+func (e *wrapped) Key() string {
+	return Key(e.main)
+}
+
 func (e *wrapped) Path() []string {
 	if p := Path(e.main); p != nil {
 		return p
@@ -482,6 +521,15 @@ func (p list) Msg() (format string, args []interface{}) {
 	return "%s (and %d more errors)", []interface{}{p[0], len(p) - 1}
 }
 
+// Key reports the key of the message for the first error, if any.
+func (p list) Key() string {
+	if len(p) != 1 {
+		format, _ := p.Msg()
+		return format
+	}
+	return Key(p[0])
+}
+
 // Position reports the primary position for the first error, if any.
 func (p list) Position() token.Pos {
 	if len(p) == 0 {
@@ -527,6 +575,11 @@ type Config struct {
 
 	// ToSlash sets whether to use Unix paths. Mostly used for testing.
 	ToSlash bool
+
+	// Catalog, if not nil, holds the format strings with which to print
+	// messages instead of their own. Messages whose key is not in the
+	// catalog are printed as is.
+	Catalog Catalog
 }
 
 // Print is a utility function that prints a list of errors to w,
@@ -556,11 +609,11 @@ func Details(err error, cfg *Config) string {
 // String generates a short message from a given Error.
 func String(err Error) string {
 	w := &strings.Builder{}
-	writeErr(w, err)
+	writeErr(w, err, nil)
 	return w.String()
 }
 
-func writeErr(w io.Writer, err Error) {
+func writeErr(w io.Writer, err Error, c Catalog) {
 	if path := strings.Join(err.Path(), "."); path != "" {
 		_, _ = io.WriteString(w, path)
 		_, _ = io.WriteString(w, ": ")
@@ -571,6 +624,9 @@ func writeErr(w io.Writer, err Error) {
 
 		printed := false
 		msg, args := err.Msg()
+		if format, ok := c[Key(err)]; ok {
+			msg = format
+		}
 		if msg != "" || u == nil { // print at least something
 			fmt.Fprintf(w, msg, args...)
 			printed = true
@@ -635,7 +691,7 @@ func printError(w io.Writer, err error, cfg *Config) {
 	}
 
 	if e, ok := err.(Error); ok {
-		writeErr(w, e)
+		writeErr(w, e, cfg.Catalog)
 	} else {
 		fprintf(w, "%v", err)
 	}
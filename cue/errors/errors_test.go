@@ -190,3 +190,60 @@ func TestPrintError(t *testing.T) {
 		}
 	}
 }
+
+func TestCatalog(t *testing.T) {
+	catalog := Catalog{
+		"conflict":       "conflit entre %[2]v et %[1]v",
+		"unknown %s":     "inconnu : %s",
+		"unused message": "unused",
+	}
+	keyed := &posError{
+		Message: NewKeyedMessage("conflict", "conflicting values %v and %v",
+			[]interface{}{1, 2}),
+	}
+	tests := []struct {
+		name    string
+		err     Error
+		key     string
+		want    string
+		wantCat string
+	}{{
+		name:    "keyed",
+		err:     keyed,
+		key:     "conflict",
+		want:    "conflicting values 1 and 2\n",
+		wantCat: "conflit entre 2 et 1\n",
+	}, {
+		name:    "format as key",
+		err:     Newf(token.NoPos, "unknown %s", "x"),
+		key:     "unknown %s",
+		want:    "unknown x\n",
+		wantCat: "inconnu : x\n",
+	}, {
+		name:    "wrapped",
+		err:     Wrap(keyed, Newf(token.NoPos, "unknown %s", "y")),
+		key:     "conflict",
+		want:    "conflicting values 1 and 2: unknown y\n",
+		wantCat: "conflit entre 2 et 1: inconnu : y\n",
+	}, {
+		name:    "not in catalog",
+		err:     Newf(token.NoPos, "other %d", 3),
+		key:     "other %d",
+		want:    "other 3\n",
+		wantCat: "other 3\n",
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Key(tt.err); got != tt.key {
+				t.Errorf("Key() = %q, want %q", got, tt.key)
+			}
+			if got := Details(tt.err, nil); got != tt.want {
+				t.Errorf("without catalog: got %q, want %q", got, tt.want)
+			}
+			got := Details(tt.err, &Config{Catalog: catalog})
+			if got != tt.wantCat {
+				t.Errorf("with catalog: got %q, want %q", got, tt.wantCat)
+			}
+		})
+	}
+}
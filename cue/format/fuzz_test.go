@@ -0,0 +1,82 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package format
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"cuelang.org/go/cue/parser"
+)
+
+// maxFuzzSize bounds the size of fuzz inputs.
+const maxFuzzSize = 1 << 12
+
+// FuzzFormat checks that formatting a parsed file yields a file that parses
+// and that, disregarding comments, formatting is idempotent. The seed corpus
+// consists of the inputs in the testdata directory.
+func FuzzFormat(f *testing.F) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.input"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		if len(b) > maxFuzzSize {
+			t.Skip()
+		}
+		file, err := parser.ParseFile("fuzz.cue", b, parser.ParseComments)
+		if err != nil {
+			t.Skip()
+		}
+		out, err := Node(file)
+		if err != nil {
+			t.Skip()
+		}
+		if _, err := parser.ParseFile("fuzz.cue", out, parser.ParseComments); err != nil {
+			t.Fatalf("formatted output does not parse: %v\n%s", err, out)
+		}
+
+		// TODO: also check idempotency for comments once the placement of
+		// comments is stable.
+		file, _ = parser.ParseFile("fuzz.cue", b)
+		out, err = Node(file)
+		if err != nil {
+			t.Skip()
+		}
+		file, err = parser.ParseFile("fuzz.cue", out)
+		if err != nil {
+			t.Fatalf("formatted output does not parse: %v\n%s", err, out)
+		}
+		again, err := Node(file)
+		if err != nil {
+			t.Fatalf("formatting formatted output failed: %v\n%s", err, out)
+		}
+		if !bytes.Equal(out, again) {
+			t.Errorf("formatting is not idempotent:\n%s\n---\n%s", out, again)
+		}
+	})
+}
@@ -17,6 +17,7 @@ package format
 import (
 	"sort"
 	"strconv"
+	"strings"
 
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/token"
@@ -85,6 +86,14 @@ func importName(s *ast.ImportSpec) string {
 	return n.Name
 }
 
+func importAttrs(s *ast.ImportSpec) string {
+	var a []string
+	for _, attr := range s.Attrs {
+		a = append(a, attr.Text)
+	}
+	return strings.Join(a, " ")
+}
+
 func importComment(s *ast.ImportSpec) string {
 	for _, c := range s.Comments() {
 		if c.Line {
@@ -96,7 +105,8 @@ func importComment(s *ast.ImportSpec) string {
 
 // collapse indicates whether prev may be removed, leaving only next.
 func collapse(prev, next *ast.ImportSpec) bool {
-	if importPath(next) != importPath(prev) || importName(next) != importName(prev) {
+	if importPath(next) != importPath(prev) || importName(next) != importName(prev) ||
+		importAttrs(next) != importAttrs(prev) {
 		return false
 	}
 	for _, c := range prev.Comments() {
@@ -163,5 +173,8 @@ func (x byImportSpec) Less(i, j int) bool {
 	if iname != jname {
 		return iname < jname
 	}
+	if iattrs, jattrs := importAttrs(x[i]), importAttrs(x[j]); iattrs != jattrs {
+		return iattrs < jattrs
+	}
 	return importComment(x[i]) < importComment(x[j])
 }
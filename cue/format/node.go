@@ -444,6 +444,12 @@ func (f *formatter) importSpec(x *ast.ImportSpec) {
 		f.visitComments(f.current.pos)
 	}
 	f.expr(x.Path)
+	for _, a := range x.Attrs {
+		if f.before(a) {
+			f.print(blank, a.At, a)
+		}
+		f.after(a)
+	}
 	f.print(newline)
 }
 
@@ -0,0 +1,65 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package cue_test
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/format"
+)
+
+// maxFuzzSize bounds the size of fuzz inputs.
+const maxFuzzSize = 1 << 12
+
+// FuzzEval checks that the evaluator does not crash when evaluating,
+// validating, and exporting arbitrary configurations.
+func FuzzEval(f *testing.F) {
+	for _, s := range []string{
+		`a: 1, b: a + 1`,
+		`#A: {x: int, y: x + 1}, a: #A & {x: 2}`,
+		`a: [for x in [1, 2, 3] if x > 1 {x}]`,
+		`a: b, b: a`,
+		`a: {b: a}`,
+		`a: *1 | int, b: a & 2`,
+		`a: =~"^a" & "abc", b: string | *"x"`,
+		`x: {[string]: int}, x: {a: 1, b: "c"}`,
+		`let l = 3, a: "\(l)"`,
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		if len(s) > maxFuzzSize {
+			t.Skip()
+		}
+		v := cuecontext.New().CompileString(s)
+		_ = v.Validate()
+		_ = v.Validate(cue.Concrete(true))
+		for _, opts := range [][]cue.Option{
+			{cue.Raw()},
+			{cue.Final()},
+			{cue.Docs(true), cue.Definitions(true)},
+		} {
+			if _, err := format.Node(v.Syntax(opts...)); err != nil {
+				t.Errorf("cannot format exported value: %v", err)
+			}
+		}
+		_, _ = v.MarshalJSON()
+	})
+}
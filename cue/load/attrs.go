@@ -0,0 +1,324 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package load
+
+import (
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+)
+
+// builtinAttrs holds the attributes that are interpreted by the loader and
+// the cue tool itself. They need not be declared.
+var builtinAttrs = map[string]bool{
+	"attribute":  true,
+	"example":    true,
+	"experiment": true,
+	"if":         true,
+	"secret":     true,
+	"tag":        true,
+}
+
+// An attrSchema describes the attribute declared by an @attribute
+// attribute.
+//
+// An attribute schema is declared at the file level:
+//
+//	@attribute(protobuf, args="int, string?, name=string?", on=field)
+//
+// The args value lists the types of the positional arguments, followed by
+// the keyed arguments as key=type. A type is string, int, number, bool, or
+// a list of allowed values separated by "|". A "?" suffix marks an argument
+// as optional and a final "..." allows any further arguments. The on value
+// restricts the attribute to fields (field) or declarations (decl).
+type attrSchema struct {
+	name string
+	pos  token.Pos
+	kind internal.AttrKind
+
+	args     []*argSchema // positional arguments
+	keys     []*argSchema // keyed arguments
+	variadic bool
+}
+
+type argSchema struct {
+	key      string
+	typ      string
+	values   []string // allowed values for enumerations
+	optional bool
+}
+
+// checkAttributes checks the attributes used in the files of b against the
+// attribute schemas declared in these files. Attributes are only checked if
+// the package declares at least one schema.
+func checkAttributes(b *build.Instance) (errs errors.Error) {
+	schemas := map[string]*attrSchema{}
+	for _, f := range b.Files {
+		for _, d := range f.Decls {
+			a, ok := d.(*ast.Attribute)
+			if !ok {
+				continue
+			}
+			key, body := a.Split()
+			if key != "attribute" {
+				continue
+			}
+			s, err := parseAttrSchema(a.Pos(), body)
+			if err != nil {
+				errs = errors.Append(errs, err)
+				continue
+			}
+			if prev, ok := schemas[s.name]; ok {
+				errs = errors.Append(errs, errors.Newf(s.pos,
+					"attribute @%s declared more than once", s.name))
+				errs = errors.Append(errs,
+					errors.Newf(prev.pos, "previous declaration here"))
+				continue
+			}
+			schemas[s.name] = s
+		}
+	}
+	if len(schemas) == 0 {
+		return errs
+	}
+
+	check := func(a *ast.Attribute, kind internal.AttrKind) {
+		key, body := a.Split()
+		if builtinAttrs[key] {
+			return
+		}
+		s, ok := schemas[key]
+		if !ok {
+			errs = errors.Append(errs, errors.Newf(a.Pos(),
+				"unknown attribute @%s", key))
+			return
+		}
+		if err := s.check(a.Pos(), kind, body); err != nil {
+			errs = errors.Append(errs, err)
+		}
+	}
+	for _, f := range b.Files {
+		ast.Walk(f, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.Field:
+				for _, a := range x.Attrs {
+					check(a, internal.FieldAttr)
+				}
+			case *ast.File:
+				for _, d := range x.Decls {
+					if a, ok := d.(*ast.Attribute); ok {
+						check(a, internal.DeclAttr)
+					}
+				}
+			case *ast.StructLit:
+				for _, d := range x.Elts {
+					if a, ok := d.(*ast.Attribute); ok {
+						check(a, internal.DeclAttr)
+					}
+				}
+			}
+			return true
+		}, nil)
+	}
+	return errs
+}
+
+func parseAttrSchema(pos token.Pos, body string) (*attrSchema, errors.Error) {
+	a := internal.ParseAttrBody(pos, body)
+	if a.Err != nil {
+		return nil, errors.Promote(a.Err, "invalid @attribute")
+	}
+	name := a.Fields[0].Text()
+	if !ast.IsValidIdent(name) {
+		return nil, errors.Newf(pos, "invalid attribute name %q", name)
+	}
+	s := &attrSchema{
+		name: name,
+		pos:  pos,
+		kind: internal.FieldAttr | internal.DeclAttr,
+	}
+	for _, kv := range a.Fields[1:] {
+		switch key, value := kv.Key(), kv.Value(); key {
+		case "on":
+			switch value {
+			case "field":
+				s.kind = internal.FieldAttr
+			case "decl":
+				s.kind = internal.DeclAttr
+			default:
+				return nil, errors.Newf(pos,
+					"invalid location %q for @%s: must be field or decl", value, name)
+			}
+
+		case "args":
+			if err := s.parseArgs(pos, value); err != nil {
+				return nil, err
+			}
+
+		default:
+			return nil, errors.Newf(pos,
+				"unknown argument %q in @attribute(%s)", kv.Text(), name)
+		}
+	}
+	return s, nil
+}
+
+func (s *attrSchema) parseArgs(pos token.Pos, spec string) errors.Error {
+	elems := strings.Split(spec, ",")
+	for i, e := range elems {
+		e = strings.TrimSpace(e)
+		if e == "..." && i == len(elems)-1 {
+			s.variadic = true
+			break
+		}
+		arg := &argSchema{}
+		if p := strings.IndexByte(e, '='); p >= 0 {
+			arg.key = strings.TrimSpace(e[:p])
+			e = strings.TrimSpace(e[p+1:])
+		}
+		if strings.HasSuffix(e, "?") {
+			arg.optional = true
+			e = e[:len(e)-1]
+		}
+		arg.typ = e
+		switch e {
+		case "string", "int", "number", "bool":
+		default:
+			arg.values = strings.Split(e, "|")
+			for _, v := range arg.values {
+				if v == "" {
+					return errors.Newf(pos,
+						"invalid argument type %q for @%s", e, s.name)
+				}
+			}
+		}
+		switch {
+		case arg.key != "":
+			s.keys = append(s.keys, arg)
+		case len(s.keys) > 0:
+			return errors.Newf(pos,
+				"positional argument of @%s must precede keyed arguments", s.name)
+		case len(s.args) > 0 && s.args[len(s.args)-1].optional && !arg.optional:
+			return errors.Newf(pos,
+				"required argument of @%s follows optional argument", s.name)
+		default:
+			s.args = append(s.args, arg)
+		}
+	}
+	return nil
+}
+
+// check checks the body of an attribute found at the given kind of location
+// against s.
+func (s *attrSchema) check(pos token.Pos, kind internal.AttrKind, body string) errors.Error {
+	if kind&s.kind == 0 {
+		where := "field"
+		if kind == internal.DeclAttr {
+			where = "declaration"
+		}
+		return errors.Newf(pos, "@%s not allowed as %s attribute", s.name, where)
+	}
+	a := internal.ParseAttrBody(pos, body)
+	if a.Err != nil {
+		return errors.Promote(a.Err, "invalid @"+s.name)
+	}
+	var args []string
+	seen := map[string]bool{}
+	for _, kv := range a.Fields {
+		if kv.Text() == "" && len(a.Fields) == 1 {
+			break // no arguments
+		}
+		if !strings.ContainsRune(kv.Text(), '=') {
+			args = append(args, kv.Text())
+			continue
+		}
+		key := kv.Key()
+		arg := s.key(key)
+		switch {
+		case arg == nil && s.variadic:
+			continue
+		case arg == nil:
+			return errors.Newf(pos, "unknown argument %q for @%s", key, s.name)
+		case seen[key]:
+			return errors.Newf(pos, "duplicate argument %q for @%s", key, s.name)
+		}
+		seen[key] = true
+		if err := arg.check(kv.Value()); err != nil {
+			return errors.Newf(pos, "invalid value for argument %q of @%s: %v",
+				key, s.name, err)
+		}
+	}
+	for i, arg := range s.args {
+		if i >= len(args) {
+			if !arg.optional {
+				return errors.Newf(pos, "missing argument %d of @%s", i+1, s.name)
+			}
+			break
+		}
+		if err := arg.check(args[i]); err != nil {
+			return errors.Newf(pos, "invalid argument %d of @%s: %v", i+1, s.name, err)
+		}
+	}
+	if len(args) > len(s.args) && !s.variadic {
+		return errors.Newf(pos, "too many arguments for @%s: got %d; want at most %d",
+			s.name, len(args), len(s.args))
+	}
+	for _, arg := range s.keys {
+		if !arg.optional && !seen[arg.key] {
+			return errors.Newf(pos, "missing argument %q of @%s", arg.key, s.name)
+		}
+	}
+	return nil
+}
+
+func (s *attrSchema) key(name string) *argSchema {
+	for _, arg := range s.keys {
+		if arg.key == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+func (a *argSchema) check(value string) error {
+	var err error
+	switch a.typ {
+	case "string":
+	case "int":
+		_, err = strconv.ParseInt(value, 0, 64)
+	case "number":
+		var info literal.NumInfo
+		err = literal.ParseNum(value, &info)
+	case "bool":
+		_, err = strconv.ParseBool(value)
+	default:
+		for _, v := range a.values {
+			if v == value {
+				return nil
+			}
+		}
+		return errors.Newf(token.NoPos, "%q is not one of %s", value, a.typ)
+	}
+	if err != nil {
+		return errors.Newf(token.NoPos, "%q is not of type %s", value, a.typ)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package load
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/errors"
+)
+
+func TestAttributeSchemas(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "")
+	defer os.RemoveAll(dir)
+
+	const schemas = `package foo
+
+	@attribute(protobuf, args="int, string?, name=string?", on=field)
+	@attribute(go, args="string")
+	@attribute(policy, args="strict|lax, ...", on=decl)
+	`
+
+	testCases := []struct {
+		name string
+		in   string
+		err  string
+	}{{
+		name: "no schemas",
+		in:   `a: int @protobut(1) @whatever()`,
+	}, {
+		name: "valid",
+		in: schemas + `
+		@policy(strict, owner=me)
+
+		a: int @protobuf(1) @tag(a)
+		b: string @protobuf(2, string, name=bee) @go(B)
+		c: {
+			@go(C)
+			@policy(lax)
+		}
+		`,
+	}, {
+		name: "unknown attribute",
+		in: schemas + `
+		a: int @protobut(1)
+		`,
+		err: "unknown attribute @protobut",
+	}, {
+		name: "location",
+		in: schemas + `
+		a: {
+			@protobuf(1)
+		}
+		b: int @policy(lax)
+		`,
+		err: `@protobuf not allowed as declaration attribute
+@policy not allowed as field attribute`,
+	}, {
+		name: "arguments",
+		in: schemas + `
+		a: int @protobuf()
+		b: int @protobuf(x)
+		c: int @protobuf(1, string, extra)
+		d: int @protobuf(1, type=int)
+		e: int @go(A, B)
+		f: {
+			@policy(loose)
+		}
+		`,
+		err: `missing argument 1 of @protobuf
+invalid argument 1 of @protobuf: "x" is not of type int
+too many arguments for @protobuf: got 3; want at most 2
+unknown argument "type" for @protobuf
+too many arguments for @go: got 2; want at most 1
+invalid argument 1 of @policy: "loose" is not one of strict|lax`,
+	}, {
+		name: "invalid schema",
+		in: `package foo
+
+		@attribute(go, args="string", on=value)
+		@attribute(json, args="string?, string")
+		@attribute(yaml)
+		@attribute(yaml)
+		`,
+		err: `invalid location "value" for @go: must be field or decl
+required argument of @json follows optional argument
+attribute @yaml declared more than once
+previous declaration here`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{
+				Dir: dir,
+				Overlay: map[string]Source{
+					filepath.Join(dir, "foo.cue"): FromString(tc.in),
+				},
+			}
+			b := Instances([]string{"foo.cue"}, cfg)[0]
+			var a []string
+			for _, e := range errors.Errors(b.Err) {
+				a = append(a, e.Error())
+			}
+			if got := strings.Join(a, "\n"); got != tc.err {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.err)
+			}
+		})
+	}
+}
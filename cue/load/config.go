@@ -31,6 +31,8 @@ import (
 	"cuelang.org/go/internal/core/compile"
 	"cuelang.org/go/internal/core/eval"
 	"cuelang.org/go/internal/core/runtime"
+	"cuelang.org/go/internal/modsign"
+	"cuelang.org/go/internal/value"
 )
 
 const (
@@ -135,6 +137,10 @@ type Config struct {
 
 	loader *loader
 
+	// trust lists the keys that must have signed the modules in cue.mod/pkg,
+	// as configured by the trust field of the cue.mod file.
+	trust modsign.Policy
+
 	// A Module is a collection of packages and instances that are within the
 	// directory hierarchy rooted at the module root. The module root can be
 	// marked with a cue.mod file.
@@ -219,6 +225,22 @@ type Config struct {
 	//    environment: "prod" | "staging" @tag(env,short=prod|staging)
 	//
 	// ensures the user may only specify "prod" or "staging".
+	//
+	// Use the Tags function to discover the tags declared by an instance.
+	//
+	//
+	// Parameterized imports
+	//
+	// Tags only apply to the instances named in the call to Instances. An
+	// import can instantiate a package with its own tags using @tag
+	// attributes on the import spec:
+	//
+	//    import prod "example.com/env" @tag(env=prod, replicas=3)
+	//
+	// Each @tag attribute holds a comma-separated list of entries with the
+	// same format as Tags. They are injected only into the instance created
+	// for this import. Importing a package with different tags results in
+	// distinct instances.
 	Tags []string
 
 	// TagVars defines a set of key value pair the values of which may be
@@ -227,6 +249,22 @@ type Config struct {
 	// Use DefaultTagVars to get a pre-loaded map with supported values.
 	TagVars map[string]TagVar
 
+	// Experiments lists the experiments to enable for all files of the
+	// instances named in the call to Instances, as if each of these files
+	// started with an attribute
+	//
+	//    @experiment(structcmp)
+	//
+	// Like Tags, experiments do not apply to imported packages. This allows
+	// the packages of a large configuration to be migrated to new semantics
+	// one at a time.
+	//
+	// The following experiments are currently defined:
+	//
+	//    structcmp   allow structs to be compared with == and !=
+	//
+	Experiments []string
+
 	// Include all files, regardless of tags.
 	AllCUEFiles bool
 
@@ -273,6 +311,11 @@ type Config struct {
 	// alternative file contents provided by the map.
 	Overlay map[string]Source
 
+	// Remote configures the loading of files that are specified as an http
+	// or https URL, such as https://example.com/schema.cue. If Remote is
+	// nil, the defaults described for RemoteConfig are used.
+	Remote *RemoteConfig
+
 	// Stdin defines an alternative for os.Stdin for the file "-". When used,
 	// the corresponding build.File will be associated with the full buffer.
 	Stdin io.Reader
@@ -371,13 +414,12 @@ func (c *Config) importPathFromAbsDir(absDir fsPath, key string) (importPath, er
 			"cannot determine import path for %q (root undefined)", key)
 	}
 
-	dir := filepath.Clean(string(absDir))
-	if !strings.HasPrefix(dir, c.ModuleRoot) {
+	pkg, ok := trimRoot(c.ModuleRoot, string(absDir))
+	if !ok {
 		return "", errors.Newf(token.NoPos,
 			"cannot determine import path for %q (dir outside of root)", key)
 	}
 
-	pkg := filepath.ToSlash(dir[len(c.ModuleRoot):])
 	switch {
 	case strings.HasPrefix(pkg, "/cue.mod/"):
 		pkg = pkg[len("/cue.mod/"):]
@@ -569,6 +611,12 @@ func (c Config) complete() (cfg *Config, err error) {
 			}
 			c.Module = name
 		}
+		if t := v.Lookup(ctx.StringLabel("trust")); t != nil {
+			c.trust, err = modsign.ParsePolicy(value.Make(ctx, t))
+			if err != nil {
+				return &c, errors.Wrapf(err, token.NoPos, "invalid cue.mod file")
+			}
+		}
 	}
 
 	c.loadFunc = c.loader.loadFunc()
@@ -27,6 +27,7 @@ import (
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
 )
 
 type overlayFile struct {
@@ -127,7 +128,7 @@ func (fs *fileSystem) isDir(path string) bool {
 	if fs.getDir(path, false) != nil {
 		return true
 	}
-	fi, err := os.Stat(path)
+	fi, err := os.Stat(internal.LongPath(path))
 	return err == nil && fi.IsDir()
 }
 
@@ -152,6 +153,30 @@ func (fs *fileSystem) hasSubdir(root, dir string) (rel string, ok bool) {
 	return hasSubdir(rootSym, dirSym)
 }
 
+// trimRoot reports the path of dir relative to root, in slash-separated form
+// and with a leading slash unless dir equals root. It reports false if dir is
+// not root or a path within root. On Windows, where file names are case
+// insensitive, the paths are compared accordingly.
+func trimRoot(root, dir string) (rel string, ok bool) {
+	root = strings.TrimSuffix(filepath.Clean(root), string(filepath.Separator))
+	dir = filepath.Clean(dir)
+	if len(dir) < len(root) || !samePath(dir[:len(root)], root) {
+		return "", false
+	}
+	rel = dir[len(root):]
+	if rel != "" && rel[0] != filepath.Separator {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+func samePath(a, b string) bool {
+	if filepath.Separator == '\\' {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
 func hasSubdir(root, dir string) (rel string, ok bool) {
 	const sep = string(filepath.Separator)
 	root = filepath.Clean(root)
@@ -168,7 +193,7 @@ func hasSubdir(root, dir string) (rel string, ok bool) {
 func (fs *fileSystem) readDir(path string) ([]os.FileInfo, errors.Error) {
 	path = fs.makeAbs(path)
 	m := fs.getDir(path, false)
-	items, err := ioutil.ReadDir(path)
+	items, err := ioutil.ReadDir(internal.LongPath(path))
 	if err != nil {
 		if !os.IsNotExist(err) || m == nil {
 			return nil, errors.Wrapf(err, token.NoPos, "readDir")
@@ -207,7 +232,7 @@ func (fs *fileSystem) stat(path string) (os.FileInfo, errors.Error) {
 	if fi := fs.getOverlay(path); fi != nil {
 		return fi, nil
 	}
-	fi, err := os.Stat(path)
+	fi, err := os.Stat(internal.LongPath(path))
 	if err != nil {
 		return nil, errors.Wrapf(err, token.NoPos, "stat")
 	}
@@ -219,7 +244,7 @@ func (fs *fileSystem) lstat(path string) (os.FileInfo, errors.Error) {
 	if fi := fs.getOverlay(path); fi != nil {
 		return fi, nil
 	}
-	fi, err := os.Lstat(path)
+	fi, err := os.Lstat(internal.LongPath(path))
 	if err != nil {
 		return nil, errors.Wrapf(err, token.NoPos, "stat")
 	}
@@ -232,7 +257,7 @@ func (fs *fileSystem) openFile(path string) (io.ReadCloser, errors.Error) {
 		return ioutil.NopCloser(bytes.NewReader(fi.contents)), nil
 	}
 
-	f, err := os.Open(path)
+	f, err := os.Open(internal.LongPath(path))
 	if err != nil {
 		return nil, errors.Wrapf(err, token.NoPos, "load")
 	}
@@ -0,0 +1,44 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package load
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestTrimRoot(t *testing.T) {
+	testCases := []struct {
+		root, dir string
+		rel       string
+		ok        bool
+	}{
+		{"/a/b", "/a/b", "", true},
+		{"/a/b", "/a/b/c/d", "/c/d", true},
+		{"/a/b/", "/a/b/c", "/c", true},
+		{"/a/b", "/a/b/./c/../d", "/d", true},
+		{"/a/b", "/a/bc", "", false},
+		{"/a/b", "/a", "", false},
+		{"/", "/a/b", "/a/b", true},
+	}
+	for _, tc := range testCases {
+		root, dir := filepath.FromSlash(tc.root), filepath.FromSlash(tc.dir)
+		rel, ok := trimRoot(root, dir)
+		if rel != tc.rel || ok != tc.ok {
+			t.Errorf("trimRoot(%q, %q) = %q, %v; want %q, %v",
+				root, dir, rel, ok, tc.rel, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package load
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/internal"
+)
+
+func TestTrimRootWindows(t *testing.T) {
+	testCases := []struct {
+		root, dir string
+		rel       string
+		ok        bool
+	}{
+		{`C:\mod`, `c:\MOD\pkg`, "/pkg", true},
+		{`C:\`, `C:\pkg\sub`, "/pkg/sub", true},
+		{`C:\mod`, `D:\mod\pkg`, "", false},
+		{`\\server\share\mod`, `\\server\share\mod\pkg`, "/pkg", true},
+		{`\\server\share\mod`, `\\SERVER\share\mod`, "", true},
+		{`\\server\share\mod`, `\\server\share\module`, "", false},
+	}
+	for _, tc := range testCases {
+		rel, ok := trimRoot(tc.root, tc.dir)
+		if rel != tc.rel || ok != tc.ok {
+			t.Errorf("trimRoot(%q, %q) = %q, %v; want %q, %v",
+				tc.root, tc.dir, rel, ok, tc.rel, tc.ok)
+		}
+	}
+}
+
+func TestLoadLongPath(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "cue-load")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(internal.LongPath(tmp))
+
+	// Create a module with a package in a directory exceeding MAX_PATH.
+	root := filepath.Join(tmp, "mod")
+	dir := root
+	for len(dir) < 300 {
+		dir = filepath.Join(dir, strings.Repeat("x", 50))
+	}
+	files := map[string]string{
+		filepath.Join(root, "cue.mod", "module.cue"): `module: "example.com"`,
+		filepath.Join(dir, "a.cue"):                  "package a\r\n\r\na: 1\r\n",
+	}
+	for name, contents := range files {
+		if err := os.MkdirAll(internal.LongPath(filepath.Dir(name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		err := ioutil.WriteFile(internal.LongPath(name), []byte(contents), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	insts := Instances([]string{"."}, &Config{Dir: dir})
+	if err := insts[0].Err; err != nil {
+		t.Fatal(err)
+	}
+	want := "example.com/" + filepath.ToSlash(dir[len(root)+1:]) + ":a"
+	if got := insts[0].ImportPath; got != want {
+		t.Errorf("got import path %q; want %q", got, want)
+	}
+}
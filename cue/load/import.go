@@ -25,12 +25,14 @@ import (
 	"unicode/utf8"
 
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/filetypes"
+	"cuelang.org/go/internal/modsign"
 )
 
 // An importMode controls the behavior of the Import method.
@@ -157,6 +159,17 @@ func (l *loader) importPkg(pos token.Pos, p *build.Instance) []*build.Instance {
 			})
 	}
 
+	if len(cfg.trust) > 0 {
+		pkg := filepath.Join(cfg.ModuleRoot, modDir, pkgDir)
+		for _, d := range dirs {
+			if d[0] == pkg && ctxt.isDir(d[1]) {
+				if err := l.verifyModule(pos, pkg, d[1]); err != nil {
+					return retErr(err)
+				}
+			}
+		}
+	}
+
 	// This algorithm assumes that multiple directories within cue.mod/*/
 	// have the same module scope and that there are no invalid modules.
 	inModule := false // if pkg == "_"
@@ -242,6 +255,8 @@ func (l *loader) loadFunc() build.LoadFunc {
 	return func(pos token.Pos, path string) *build.Instance {
 		cfg := l.cfg
 
+		path, tags := astutil.SplitImportID(path)
+
 		impPath := importPath(path)
 		if isLocalImport(path) {
 			return cfg.newErrInstance(pos, impPath,
@@ -250,6 +265,10 @@ func (l *loader) loadFunc() build.LoadFunc {
 
 		// is it a builtin?
 		if strings.IndexByte(strings.Split(path, "/")[0], '.') == -1 {
+			if len(tags) > 0 {
+				return cfg.newErrInstance(pos, impPath,
+					errors.Newf(pos, "cannot use tags with builtin package %q", path))
+			}
 			if l.cfg.StdRoot != "" {
 				p := cfg.newInstance(pos, impPath)
 				_ = l.importPkg(pos, p)
@@ -260,10 +279,28 @@ func (l *loader) loadFunc() build.LoadFunc {
 
 		p := cfg.newInstance(pos, impPath)
 		_ = l.importPkg(pos, p)
+		if len(tags) > 0 {
+			l.injectImportTags(pos, p, tags)
+		}
 		return p
 	}
 }
 
+// injectImportTags injects the tags of a parameterized import into the
+// instance p of the imported package. Unlike the tags of Config.Tags, these
+// tags only apply to this instance.
+func (l *loader) injectImportTags(pos token.Pos, p *build.Instance, tags []string) {
+	found, err := findTags(p)
+	if err == nil {
+		il := &loader{cfg: &Config{}, tags: found}
+		if err = injectTags(tags, il); err == nil {
+			il.replaceNodes(p)
+			return
+		}
+	}
+	p.ReportError(errors.Wrapf(err, pos, "import %q", p.ImportPath))
+}
+
 func rewriteFiles(p *build.Instance, root string, isLocal bool) {
 	p.Root = root
 
@@ -342,7 +379,7 @@ func (fp *fileProcessor) finalize(p *build.Instance) errors.Error {
 
 func (fp *fileProcessor) add(pos token.Pos, root string, file *build.File, mode importMode) (added bool) {
 	fullPath := file.Filename
-	if fullPath != "-" {
+	if fullPath != "-" && !isURL(fullPath) {
 		if !filepath.IsAbs(fullPath) {
 			fullPath = filepath.Join(root, fullPath)
 		}
@@ -476,14 +513,14 @@ func (fp *fileProcessor) add(pos token.Pos, root string, file *build.File, mode
 		}
 		for _, spec := range d.Specs {
 			quoted := spec.Path.Value
-			path, err := strconv.Unquote(quoted)
+			info, err := astutil.ParseImportSpec(spec)
 			if err != nil {
 				badFile(errors.Newf(
 					spec.Path.Pos(),
 					"%s: parser returned invalid quoted string: <%s>", fullPath, quoted,
 				))
 			}
-			if !isTest || fp.c.Tests {
+			if path := info.ID; !isTest || fp.c.Tests {
 				fp.imported[path] = append(fp.imported[path], spec.Pos())
 			}
 		}
@@ -615,3 +652,46 @@ func isLocalImport(path string) bool {
 	return path == "." || path == ".." ||
 		strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../")
 }
+
+// verifyModule checks the signature of the module in the pkg directory that
+// contains dir against the trust policy of the main module. The module is
+// rooted at the closest directory that has a cue.mod directory and its path
+// is the location of this directory relative to pkg.
+func (l *loader) verifyModule(pos token.Pos, pkg, dir string) errors.Error {
+	root := ""
+	for d := dir; d != pkg && strings.HasPrefix(d, pkg); d = filepath.Dir(d) {
+		if l.cfg.isRoot(d) {
+			root = d
+			break
+		}
+	}
+	module := root
+	if module == "" {
+		module = dir
+	}
+	rel, err := filepath.Rel(pkg, module)
+	if err != nil {
+		return errors.Wrapf(err, pos, "invalid path")
+	}
+	module = filepath.ToSlash(rel)
+
+	keys, ok := l.cfg.trust.Keys(module)
+	if !ok {
+		return nil
+	}
+	if root == "" {
+		return errors.Newf(pos, "module %s: module is not signed", module)
+	}
+	if err, ok := l.verified[root]; ok {
+		return err
+	}
+	var e errors.Error
+	if err := modsign.VerifyDir(root, module, keys); err != nil {
+		e = errors.Newf(pos, "module %s: %v", module, err)
+	}
+	if l.verified == nil {
+		l.verified = map[string]errors.Error{}
+	}
+	l.verified[root] = e
+	return e
+}
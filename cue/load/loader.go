@@ -30,6 +30,7 @@ import (
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/experiment"
 	"cuelang.org/go/internal/filetypes"
 
 	// Trigger the unconditional loading of all core builtin packages if load
@@ -76,10 +77,19 @@ func Instances(args []string, c *Config) []*build.Instance {
 	}
 
 	if args = args[i:]; len(args) > 0 {
+		var urls map[string]string
+		var rerr errors.Error
+		args, urls, rerr = c.fetchRemote(args)
+		if rerr != nil {
+			return []*build.Instance{c.newErrInstance(token.NoPos, "", rerr)}
+		}
 		files, err := filetypes.ParseArgs(args)
 		if err != nil {
 			return []*build.Instance{c.newErrInstance(token.NoPos, "", err)}
 		}
+		if rerr := setRemoteSources(files, urls); rerr != nil {
+			return []*build.Instance{c.newErrInstance(token.NoPos, "", rerr)}
+		}
 		a = append(a, l.cueFilesPackage(files))
 	}
 
@@ -89,6 +99,17 @@ func Instances(args []string, c *Config) []*build.Instance {
 			p.ReportError(err)
 		}
 		l.tags = append(l.tags, tags...)
+
+		if err := checkAttributes(p); err != nil {
+			p.ReportError(err)
+		}
+
+		if len(c.Experiments) > 0 {
+			if _, err := experiment.Parse(token.NoPos, c.Experiments); err != nil {
+				p.ReportError(err)
+			}
+			p.Experiments = append(p.Experiments, c.Experiments...)
+		}
 	}
 
 	// TODO(api): have API call that returns an error which is the aggregate
@@ -105,20 +126,26 @@ func Instances(args []string, c *Config) []*build.Instance {
 	}
 
 	for _, p := range a {
-		for _, f := range p.Files {
-			ast.Walk(f, nil, func(n ast.Node) {
-				if ident, ok := n.(*ast.Ident); ok {
-					if v, ok := l.replacements[ident.Node]; ok {
-						ident.Node = v
-					}
-				}
-			})
-		}
+		l.replaceNodes(p)
 	}
 
 	return a
 }
 
+// replaceNodes updates the references in p to fields values that were
+// replaced by injected tags.
+func (l *loader) replaceNodes(p *build.Instance) {
+	for _, f := range p.Files {
+		ast.Walk(f, nil, func(n ast.Node) {
+			if ident, ok := n.(*ast.Ident); ok {
+				if v, ok := l.replacements[ident.Node]; ok {
+					ident.Node = v
+				}
+			}
+		})
+	}
+}
+
 // Mode flags for loadImport and download (in get.go).
 const (
 	// resolveImport means that loadImport should do import path expansion.
@@ -139,6 +166,10 @@ type loader struct {
 	tags         []*tag // tags found in files
 	buildTags    map[string]bool
 	replacements map[ast.Node]ast.Node
+
+	// verified records the result of verifying the signature of each
+	// module in cue.mod/pkg, by module root.
+	verified map[string]errors.Error
 }
 
 func (l *loader) abs(filename string) string {
@@ -165,7 +196,7 @@ func (l *loader) cueFilesPackage(files []*build.File) *build.Instance {
 
 	for _, bf := range files {
 		f := bf.Filename
-		if f == "-" {
+		if f == "-" || isURL(f) {
 			continue
 		}
 		if !filepath.IsAbs(f) {
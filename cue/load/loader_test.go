@@ -27,6 +27,7 @@ import (
 	"github.com/kylelemons/godebug/diff"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/internal/str"
 )
@@ -401,3 +402,71 @@ func TestOverlays(t *testing.T) {
 		}
 	}
 }
+
+func TestExperiments(t *testing.T) {
+	dir, _ := os.Getwd()
+	dir = filepath.Join(dir, "testdata", "experiments")
+
+	testCases := []struct {
+		experiments []string
+		in          string
+		out         string
+		err         string
+	}{{
+		experiments: []string{"structcmp"},
+		in:          `a: {x: 1} == {x: 1}`,
+		out:         `{"a":true}`,
+	}, {
+		in: `@experiment(structcmp)
+		a: {x: 1} == {x: 1}`,
+		out: `{"a":true}`,
+	}, {
+		in:  `a: {x: 1} == {x: 1}`,
+		err: `a: invalid operands {x:1} and {x:1} to '==' (type struct and struct)`,
+	}, {
+		// Experiments do not apply to imported packages.
+		experiments: []string{"structcmp"},
+		in: `import "example.org/test/pkg"
+		a: pkg.a`,
+		err: `a: invalid operands {x:1} and {x:1} to '==' (type struct and struct)`,
+	}, {
+		experiments: []string{"nosuchthing"},
+		in:          `a: 1`,
+		err:         `unknown experiment "nosuchthing"; known experiments are: structcmp`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			cfg := &Config{
+				Dir:         dir,
+				Experiments: tc.experiments,
+				Overlay: map[string]Source{
+					filepath.Join(dir, "cue.mod", "module.cue"): FromString(`module: "example.org/test"`),
+					filepath.Join(dir, "pkg", "pkg.cue"):        FromString("package pkg\na: {x: 1} == {x: 1}"),
+					filepath.Join(dir, "foo.cue"):               FromString(tc.in),
+				},
+			}
+			b := Instances([]string{"foo.cue"}, cfg)[0]
+
+			v := cuecontext.New().BuildInstance(b)
+			switch err := v.Validate(); {
+			case (err == nil) != (tc.err == ""):
+				t.Fatalf("error: got %v; want %v", err, tc.err)
+
+			case err != nil:
+				if got := err.Error(); got != tc.err {
+					t.Fatalf("error: got %v; want %v", got, tc.err)
+				}
+
+			default:
+				b, err := v.MarshalJSON()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(b) != tc.out {
+					t.Errorf("got %s; want %s", b, tc.out)
+				}
+			}
+		})
+	}
+}
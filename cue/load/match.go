@@ -15,6 +15,8 @@
 package load
 
 import (
+	"bytes"
+	"io"
 	"io/ioutil"
 	"path/filepath"
 	"strings"
@@ -77,8 +79,10 @@ func matchFile(cfg *Config, file *build.File, returnImports, allFiles bool, allT
 		}
 	}
 
-	f, err := cfg.fileSystem.openFile(file.Filename)
-	if err != nil {
+	var f io.ReadCloser
+	if b, ok := file.Source.([]byte); ok {
+		f = ioutil.NopCloser(bytes.NewReader(b))
+	} else if f, err = cfg.fileSystem.openFile(file.Filename); err != nil {
 		return false, nil, err
 	}
 
@@ -0,0 +1,304 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package load
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// A RemoteConfig configures the loading of files named by an http or https
+// URL.
+//
+// Remote files are downloaded into a cache directory. A cached file is
+// revalidated using its ETag each time it is loaded, so that it is only
+// downloaded again if it changed. A cached file with a pinned checksum is
+// used without contacting the server at all.
+type RemoteConfig struct {
+	// Allow lists the URL prefixes from which files may be loaded, such as
+	// "https://example.com/schemas/". A prefix matches URLs with the same
+	// scheme and host whose path starts with the path of the prefix at a
+	// path element boundary. If Allow is empty, files may be
+	// loaded from any https URL. Files served over plain http are only
+	// loaded if their URL is explicitly allowed.
+	Allow []string
+
+	// Checksums pins the contents of remote files. It maps a URL to a
+	// checksum of the form "sha256:" followed by the hexadecimal SHA-256
+	// hash of the file. A checksum may also be specified as a fragment of
+	// the URL itself:
+	//
+	//     https://example.com/schema.cue#sha256=<hex>
+	//
+	// Loading a file that does not match its checksum is an error.
+	Checksums map[string]string
+
+	// CacheDir is the directory in which remote files are cached. If
+	// CacheDir is empty, the directory cue/remote within the user's cache
+	// directory is used.
+	CacheDir string
+
+	// Client is used to download remote files. If Client is nil, a client
+	// with the default settings of http.Client is used. Redirects are only
+	// followed to URLs that are allowed by Allow, and never from https to
+	// http.
+	Client *http.Client
+}
+
+// isURL reports whether the given argument names a remote file.
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+// fetchRemote replaces the URLs in args with the names of the files in which
+// their contents are cached. It returns a map from these files to the URLs
+// they were loaded from.
+func (c *Config) fetchRemote(args []string) ([]string, map[string]string, errors.Error) {
+	r := c.Remote
+	if r == nil {
+		r = &RemoteConfig{}
+	}
+	a := make([]string, len(args))
+	urls := map[string]string{}
+	for i, s := range args {
+		a[i] = s
+		if !isURL(s) {
+			continue
+		}
+		file, loc, err := r.fetch(s)
+		if err != nil {
+			return nil, nil, err
+		}
+		a[i] = file
+		urls[file] = loc
+	}
+	return a, urls, nil
+}
+
+// setRemoteSources sets the source of the files that were loaded from the
+// given URLs, as returned by fetchRemote, to the contents of their cached
+// files, and names them by their URL, so that positions within these files
+// refer to the URL rather than the cache.
+func setRemoteSources(files []*build.File, urls map[string]string) errors.Error {
+	for _, f := range files {
+		loc, ok := urls[f.Filename]
+		if !ok {
+			continue
+		}
+		data, err := ioutil.ReadFile(f.Filename)
+		if err != nil {
+			return errors.Wrapf(err, token.NoPos, "cannot load %s", loc)
+		}
+		f.Filename = loc
+		f.Source = data
+	}
+	return nil
+}
+
+func (r *RemoteConfig) fetch(s string) (file, loc string, err errors.Error) {
+	u, err := r.parseURL(s)
+	if err != nil {
+		return "", "", err
+	}
+	sum := u.Fragment
+	if p := strings.IndexByte(sum, '='); p > 0 {
+		sum = sum[:p] + ":" + sum[p+1:]
+	}
+	u.Fragment = ""
+	loc = u.String()
+	file, err = r.fetchURL(loc, u, sum)
+	return file, loc, err
+}
+
+func (r *RemoteConfig) fetchURL(loc string, u *url.URL, sum string) (file string, err errors.Error) {
+	if x, ok := r.Checksums[loc]; ok {
+		sum = x
+	}
+	if sum != "" && !strings.HasPrefix(sum, "sha256:") {
+		return "", errors.Newf(token.NoPos,
+			"unsupported checksum %q for %s: must be of the form sha256:<hex>", sum, loc)
+	}
+
+	dir := r.CacheDir
+	if dir == "" {
+		d, err := os.UserCacheDir()
+		if err != nil {
+			return "", errors.Wrapf(err, token.NoPos, "cannot determine cache directory")
+		}
+		dir = filepath.Join(d, "cue", "remote")
+	}
+	key := sha256.Sum256([]byte(loc))
+	dir = filepath.Join(dir, hex.EncodeToString(key[:])[:32])
+	file = filepath.Join(dir, path.Base(u.Path))
+	etagFile := filepath.Join(dir, "etag")
+
+	cached, _ := ioutil.ReadFile(file)
+	if cached != nil && sum != "" && checksum(cached) == sum {
+		return file, nil
+	}
+
+	req, rerr := http.NewRequest("GET", loc, nil)
+	if rerr != nil {
+		return "", errors.Wrapf(rerr, token.NoPos, "invalid URL %s", loc)
+	}
+	if etag, _ := ioutil.ReadFile(etagFile); cached != nil && etag != nil {
+		req.Header.Set("If-None-Match", string(etag))
+	}
+	resp, rerr := r.client().Do(req)
+	if rerr != nil {
+		return "", errors.Wrapf(rerr, token.NoPos, "cannot load %s", loc)
+	}
+	defer resp.Body.Close()
+
+	var data []byte
+	switch resp.StatusCode {
+	case http.StatusOK:
+		data, rerr = ioutil.ReadAll(resp.Body)
+		if rerr != nil {
+			return "", errors.Wrapf(rerr, token.NoPos, "cannot load %s", loc)
+		}
+	case http.StatusNotModified:
+		data = cached
+	default:
+		return "", errors.Newf(token.NoPos, "cannot load %s: %s", loc, resp.Status)
+	}
+
+	if got := checksum(data); sum != "" && got != sum {
+		return "", errors.Newf(token.NoPos,
+			"checksum mismatch for %s:\n\tdownloaded: %s\n\twant:       %s", loc, got, sum)
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		return file, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", errors.Wrapf(err, token.NoPos, "cannot cache %s", loc)
+	}
+	if !bytes.Equal(data, cached) {
+		if err := ioutil.WriteFile(file, data, 0644); err != nil {
+			return "", errors.Wrapf(err, token.NoPos, "cannot cache %s", loc)
+		}
+	}
+	if etag := resp.Header.Get("ETag"); etag == "" {
+		_ = os.Remove(etagFile)
+	} else if err := ioutil.WriteFile(etagFile, []byte(etag), 0644); err != nil {
+		return "", errors.Wrapf(err, token.NoPos, "cannot cache %s", loc)
+	}
+	return file, nil
+}
+
+// parseURL parses s and verifies that it names a file that may be loaded.
+func (r *RemoteConfig) parseURL(s string) (*url.URL, errors.Error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, errors.Wrapf(err, token.NoPos, "invalid URL %q", s)
+	}
+	if base := path.Base(u.Path); base == "/" || base == "." || path.Ext(base) == "" {
+		return nil, errors.Newf(token.NoPos,
+			"URL %q must name a file with an extension", s)
+	}
+
+	if !r.allowed(u) {
+		return nil, errors.Newf(token.NoPos, "loading from %q not allowed", s)
+	}
+	return u, nil
+}
+
+// allowed reports whether files may be loaded from u.
+func (r *RemoteConfig) allowed(u *url.URL) bool {
+	if len(r.Allow) == 0 {
+		return u.Scheme == "https"
+	}
+	for _, prefix := range r.Allow {
+		if allowedBy(u, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// client returns the client with which to download files. Redirects are
+// only followed to URLs from which files may be loaded, and never from https
+// to http.
+func (r *RemoteConfig) client() *http.Client {
+	c := http.Client{}
+	if r.Client != nil {
+		c = *r.Client
+	}
+	check := c.CheckRedirect
+	c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		prev := via[len(via)-1].URL
+		if prev.Scheme == "https" && req.URL.Scheme != "https" {
+			return errors.Newf(token.NoPos,
+				"redirect from %q to insecure %q not allowed", prev, req.URL)
+		}
+		if !r.allowed(req.URL) {
+			return errors.Newf(token.NoPos,
+				"redirect to %q not allowed", req.URL)
+		}
+		if check != nil {
+			return check(req, via)
+		}
+		if len(via) >= 10 {
+			return errors.Newf(token.NoPos, "stopped after 10 redirects")
+		}
+		return nil
+	}
+	return &c
+}
+
+// allowedBy reports whether u is allowed by the prefix of an Allow entry.
+// The scheme, user information, and host must match exactly, and the path
+// of the prefix must match entire path elements of u, so that, for
+// instance, "https://example.com/schemas" allows neither
+// "https://example.com.evil.org/schemas/x.cue" nor
+// "https://example.com/schemas-old/x.cue".
+func allowedBy(u *url.URL, prefix string) bool {
+	p, err := url.Parse(prefix)
+	if err != nil || p.Host == "" {
+		return false
+	}
+	if u.Scheme != p.Scheme ||
+		!strings.EqualFold(u.Host, p.Host) ||
+		u.User.String() != p.User.String() {
+		return false
+	}
+	dir := p.Path
+	if dir == "" {
+		dir = "/"
+	}
+	file := path.Clean(u.Path)
+	if strings.HasSuffix(dir, "/") {
+		return strings.HasPrefix(file, dir)
+	}
+	return file == dir || strings.HasPrefix(file, dir+"/")
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,249 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package load
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+)
+
+func TestRemote(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "")
+	defer os.RemoveAll(dir)
+
+	content := `#Schema: {name: string}`
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/schema.cue":
+			etag := fmt.Sprintf("%q", checksum([]byte(content)))
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			fmt.Fprint(w, content)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	schema := srv.URL + "/schema.cue"
+	sum := checksum([]byte(content))
+
+	testCases := []struct {
+		name     string
+		args     []string
+		remote   RemoteConfig
+		requests int
+		err      string
+	}{{
+		name:     "download",
+		args:     []string{schema},
+		remote:   RemoteConfig{Allow: []string{srv.URL}},
+		requests: 1,
+	}, {
+		name:     "revalidate",
+		args:     []string{schema},
+		remote:   RemoteConfig{Allow: []string{srv.URL}},
+		requests: 1,
+	}, {
+		name:     "pinned in cache",
+		args:     []string{schema + "#sha256=" + strings.TrimPrefix(sum, "sha256:")},
+		remote:   RemoteConfig{Allow: []string{srv.URL}},
+		requests: 0,
+	}, {
+		name: "checksum mismatch",
+		args: []string{schema},
+		remote: RemoteConfig{
+			Allow:     []string{srv.URL},
+			Checksums: map[string]string{schema: "sha256:1234"},
+		},
+		err: "checksum mismatch for " + schema,
+	}, {
+		name: "not found",
+		args: []string{srv.URL + "/missing.cue"},
+		remote: RemoteConfig{
+			Allow: []string{srv.URL},
+		},
+		err: "cannot load " + srv.URL + "/missing.cue: 404 Not Found",
+	}, {
+		name: "not allowed",
+		args: []string{schema},
+		err:  fmt.Sprintf("loading from %q not allowed", schema),
+	}, {
+		name: "not allowed by prefix",
+		args: []string{schema},
+		remote: RemoteConfig{
+			Allow: []string{"https://example.com/"},
+		},
+		err: fmt.Sprintf("loading from %q not allowed", schema),
+	}, {
+		name: "no file",
+		args: []string{srv.URL + "/"},
+		err:  fmt.Sprintf("URL %q must name a file with an extension", srv.URL+"/"),
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests = 0
+			tc.remote.CacheDir = dir
+			cfg := &Config{Dir: dir, Remote: &tc.remote}
+			b := Instances(tc.args, cfg)[0]
+			if b.Err != nil {
+				if got := b.Err.Error(); !strings.Contains(got, tc.err) || tc.err == "" {
+					t.Fatalf("got error %q; want %q", got, tc.err)
+				}
+				return
+			}
+			if tc.err != "" {
+				t.Fatalf("got no error; want %q", tc.err)
+			}
+			if requests != tc.requests {
+				t.Errorf("got %d requests; want %d", requests, tc.requests)
+			}
+			v := cuecontext.New().BuildInstance(b)
+			if err := v.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if !v.LookupDef("#Schema").Exists() {
+				t.Errorf("schema not loaded: %v", v)
+			}
+		})
+	}
+}
+
+func TestRemoteAllow(t *testing.T) {
+	allow := []string{
+		"https://example.com/schemas",
+		"https://cue.example.org/",
+		"http://localhost:8080/",
+	}
+	testCases := []struct {
+		url     string
+		allowed bool
+	}{
+		{"https://example.com/schemas/app.cue", true},
+		{"https://example.com/schemas/v1/app.cue", true},
+		{"https://EXAMPLE.com/schemas/app.cue", true},
+		{"https://cue.example.org/app.cue", true},
+		{"http://localhost:8080/app.cue", true},
+
+		{"https://example.com/schemas-old/app.cue", false},
+		{"https://example.com/schemas/../private/app.cue", false},
+		{"https://example.com/app.cue", false},
+		{"http://example.com/schemas/app.cue", false},
+		{"https://example.com.evil.org/schemas/app.cue", false},
+		{"https://example.com@evil.org/schemas/app.cue", false},
+		{"https://example.com:8443/schemas/app.cue", false},
+		{"https://cue.example.org.evil.org/app.cue", false},
+		{"https://cue.example.org@evil.org/app.cue", false},
+		{"http://localhost:8080.evil.org/app.cue", false},
+		{"http://localhost:80801/app.cue", false},
+	}
+	r := &RemoteConfig{Allow: allow}
+	for _, tc := range testCases {
+		_, err := r.parseURL(tc.url)
+		if allowed := err == nil; allowed != tc.allowed {
+			t.Errorf("%s: got allowed %v (%v); want %v", tc.url, allowed, err, tc.allowed)
+		}
+	}
+}
+
+func TestRemoteRedirect(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "")
+	defer os.RemoveAll(dir)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "a: 1")
+	})
+	other := httptest.NewTLSServer(handler)
+	defer other.Close()
+	insecure := httptest.NewServer(handler)
+	defer insecure.Close()
+
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/schema.cue":
+			fmt.Fprint(w, "a: 1\nb: a & 2\n")
+		case "/moved.cue":
+			http.Redirect(w, r, srv.URL+"/schema.cue", http.StatusFound)
+		case "/other.cue":
+			http.Redirect(w, r, other.URL+"/schema.cue", http.StatusFound)
+		case "/insecure.cue":
+			http.Redirect(w, r, insecure.URL+"/schema.cue", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	testCases := []struct {
+		name  string
+		url   string
+		allow []string
+		err   string
+	}{{
+		name:  "allowed",
+		url:   srv.URL + "/moved.cue",
+		allow: []string{srv.URL},
+		// Positions refer to the URL, not the cached file.
+		err: "b: conflicting values 2 and 1:\n" +
+			"    " + srv.URL + "/moved.cue:1:4\n" +
+			"    " + srv.URL + "/moved.cue:2:4\n" +
+			"    " + srv.URL + "/moved.cue:2:8\n",
+	}, {
+		name:  "not allowed",
+		url:   srv.URL + "/other.cue",
+		allow: []string{srv.URL},
+		err:   fmt.Sprintf("redirect to %q not allowed", other.URL+"/schema.cue"),
+	}, {
+		name:  "insecure",
+		url:   srv.URL + "/insecure.cue",
+		allow: []string{srv.URL, insecure.URL},
+		err: fmt.Sprintf("redirect from %q to insecure %q not allowed",
+			srv.URL+"/insecure.cue", insecure.URL+"/schema.cue"),
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &Config{Dir: dir, Remote: &RemoteConfig{
+				Allow:    tc.allow,
+				CacheDir: dir,
+				Client:   srv.Client(),
+			}}
+			b := Instances([]string{tc.url}, cfg)[0]
+			var err error = b.Err
+			if b.Err == nil {
+				err = cuecontext.New().BuildInstance(b).Validate()
+			}
+			if err == nil {
+				t.Fatalf("got no error; want %q", tc.err)
+			}
+			if got := errors.Details(err, nil); !strings.Contains(got, tc.err) {
+				t.Errorf("got error %q; want %q", got, tc.err)
+			}
+		})
+	}
+}
@@ -124,6 +124,63 @@ type tag struct {
 	hasReplacement bool
 
 	field *ast.Field
+	path  []cue.Selector
+	pos   token.Pos
+}
+
+// A Tag describes a field into which values can be injected with the Tags
+// and TagVars options of Config.
+type Tag struct {
+	// Name is the key with which values are injected, as in name=value.
+	Name string
+
+	// Kind is the kind as which injected values are interpreted: one of
+	// string, int, number, or bool.
+	Kind cue.Kind
+
+	// Shorthands lists the values that may be injected without a key.
+	Shorthands []string
+
+	// Var is the name of the tag variable that is injected if no value is
+	// set explicitly and tag variables are enabled.
+	Var string
+
+	// Doc is the documentation of the field.
+	Doc string
+
+	// Path is the path of the field within the instance.
+	Path cue.Path
+
+	// Pos is the position of the @tag attribute.
+	Pos token.Pos
+}
+
+// Tags reports the tags declared in the files of b, in the order in which
+// they appear.
+func Tags(b *build.Instance) ([]*Tag, error) {
+	tags, err := findTags(b)
+	if err != nil {
+		return nil, err
+	}
+	a := make([]*Tag, 0, len(tags))
+	for _, t := range tags {
+		var doc []string
+		for _, cg := range ast.Comments(t.field) {
+			if cg.Doc {
+				doc = append(doc, strings.TrimSpace(cg.Text()))
+			}
+		}
+		a = append(a, &Tag{
+			Name:       t.key,
+			Kind:       t.kind,
+			Shorthands: t.shorthands,
+			Var:        t.vars,
+			Doc:        strings.Join(doc, "\n"),
+			Path:       cue.MakePath(t.path...),
+			Pos:        t.pos,
+		})
+	}
+	return a, nil
 }
 
 func parseTag(pos token.Pos, body string) (t *tag, err errors.Error) {
@@ -168,7 +225,7 @@ func parseTag(pos token.Pos, body string) (t *tag, err errors.Error) {
 }
 
 func (t *tag) inject(value string, l *loader) errors.Error {
-	e, err := cli.ParseValue(token.NoPos, t.key, value, t.kind)
+	e, err := cli.ParseValue(t.pos, t.key, value, t.kind)
 	t.injectValue(e, l)
 	return err
 }
@@ -199,6 +256,11 @@ func findTags(b *build.Instance) (tags []*tag, errs errors.Error) {
 			}
 		})
 	}
+	pkg := b.ImportPath
+	if pkg == "" {
+		pkg = "_"
+	}
+	var path []cue.Selector
 	for _, f := range b.Files {
 		ast.Walk(f, func(n ast.Node) bool {
 			switch x := n.(type) {
@@ -212,11 +274,12 @@ func findTags(b *build.Instance) (tags []*tag, errs errors.Error) {
 
 			case *ast.Field:
 				// TODO: allow optional fields?
-				_, _, err := ast.LabelName(x.Label)
+				name, _, err := ast.LabelName(x.Label)
 				if err != nil || x.Optional != token.NoPos {
 					findInvalidTags(n, "@tag not allowed within optional fields")
 					return false
 				}
+				path = append(path, selector(x.Label, name, pkg))
 
 				for _, a := range x.Attrs {
 					key, body := a.Split()
@@ -229,15 +292,33 @@ func findTags(b *build.Instance) (tags []*tag, errs errors.Error) {
 						continue
 					}
 					t.field = x
+					t.path = append([]cue.Selector(nil), path...)
+					t.pos = a.Pos()
 					tags = append(tags, t)
 				}
 			}
 			return true
-		}, nil)
+		}, func(n ast.Node) {
+			if _, ok := n.(*ast.Field); ok {
+				path = path[:len(path)-1]
+			}
+		})
 	}
 	return tags, errs
 }
 
+func selector(label ast.Label, name, pkg string) cue.Selector {
+	if _, ok := label.(*ast.Ident); ok {
+		switch {
+		case strings.HasPrefix(name, "#"):
+			return cue.Def(name)
+		case strings.HasPrefix(name, "_"):
+			return cue.Hid(name, pkg)
+		}
+	}
+	return cue.Str(name)
+}
+
 func injectTags(tags []string, l *loader) errors.Error {
 	// Parses command line args
 	for _, s := range tags {
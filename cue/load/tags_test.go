@@ -16,6 +16,7 @@ package load
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -124,3 +125,169 @@ func TestTags(t *testing.T) {
 		})
 	}
 }
+
+func TestTagDeclarations(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "")
+	defer os.RemoveAll(dir)
+
+	in := `
+	// env is the environment to deploy to.
+	env: *"dev" | "prod" @tag(env,short=dev|prod)
+
+	deploy: {
+		// replicas is the number of replicas.
+		// It must be positive.
+		replicas: int & >0 @tag(replicas,type=int)
+		#user:    string   @tag(user,var=username)
+	}
+	"other-field": bool @tag(debug,type=bool)
+	`
+	cfg := &Config{
+		Dir: dir,
+		Overlay: map[string]Source{
+			filepath.Join(dir, "foo.cue"): FromString(in),
+		},
+	}
+	b := Instances([]string{"foo.cue"}, cfg)[0]
+	tags, err := Tags(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got bytes.Buffer
+	for _, tag := range tags {
+		fmt.Fprintf(&got, "%s %v %v %s %s %q %d\n", tag.Name, tag.Kind,
+			tag.Shorthands, tag.Var, tag.Path, tag.Doc, tag.Pos.Line())
+	}
+	want := `env string [dev prod]  env "env is the environment to deploy to." 3
+replicas int []  deploy.replicas "replicas is the number of replicas.\nIt must be positive." 8
+user string [] username deploy.#user "" 9
+debug bool []  "other-field" "" 11
+`
+	if got.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", &got, want)
+	}
+}
+
+func TestTagTypes(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "")
+	defer os.RemoveAll(dir)
+
+	testCases := []struct {
+		tag string
+		err string
+	}{
+		{tag: "port=80"},
+		{tag: "port=-1"},
+		{tag: "port=1.5", err: `invalid int "1.5" for environment variable port`},
+		{tag: "port=x", err: `invalid int "x" for environment variable port`},
+		{tag: "ratio=1.5"},
+		{tag: "ratio=2"},
+		{tag: "ratio=a.b", err: `invalid number "a.b" for environment variable ratio`},
+		{tag: "debug=true"},
+		{tag: "debug=yes", err: `invalid boolean value "yes" for environment variable debug`},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.tag, func(t *testing.T) {
+			cfg := &Config{
+				Dir: dir,
+				Overlay: map[string]Source{
+					filepath.Join(dir, "foo.cue"): FromString(`
+					port:  int    @tag(port,type=int)
+					ratio: number @tag(ratio,type=number)
+					debug: bool   @tag(debug,type=bool)
+					`),
+				},
+				Tags: []string{tc.tag},
+			}
+			b := Instances([]string{"foo.cue"}, cfg)[0]
+			err := ""
+			if b.Err != nil {
+				err = b.Err.Error()
+			}
+			if err != tc.err {
+				t.Errorf("got error %q; want %q", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestParameterizedImports(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "")
+	defer os.RemoveAll(dir)
+
+	env := `package env
+
+	env:      *"dev" | "prod" @tag(env, short=dev|prod)
+	replicas: *1 | int         @tag(replicas, type=int)
+	host:     "\(env).example.com"
+	`
+
+	testCases := []struct {
+		in  string
+		out string
+		err string
+	}{{
+		in: `
+		import (
+			prod "example.org/test/env" @tag(prod, replicas=3)
+			stage "example.org/test/env" @tag(env=dev, replicas = 2)
+			dev "example.org/test/env"
+		)
+		a: prod
+		b: stage
+		c: dev
+		`,
+		out: `{"a":{"env":"prod","replicas":3,"host":"prod.example.com"},` +
+			`"b":{"env":"dev","replicas":2,"host":"dev.example.com"},` +
+			`"c":{"env":"dev","replicas":1,"host":"dev.example.com"}}`,
+	}, {
+		in: `
+		import e "example.org/test/env" @tag(region=us)
+		a: e
+		`,
+		err: `import failed: import "example.org/test/env": no tag for "region"`,
+	}, {
+		in: `
+		import s "strings" @tag(x)
+		a: s.ToUpper("x")
+		`,
+		err: `import failed: cannot use tags with builtin package "strings"`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			cfg := &Config{
+				Dir: dir,
+				Overlay: map[string]Source{
+					filepath.Join(dir, "cue.mod", "module.cue"): FromString(`module: "example.org/test"`),
+					filepath.Join(dir, "env", "env.cue"):        FromString(env),
+					filepath.Join(dir, "foo.cue"):               FromString(tc.in),
+				},
+			}
+			b := Instances([]string{"foo.cue"}, cfg)[0]
+
+			c := cuecontext.New()
+			got := c.BuildInstance(b)
+			switch err := got.Err(); {
+			case (err == nil) != (tc.err == ""):
+				t.Fatalf("error: got %v; want %v", err, tc.err)
+
+			case err != nil:
+				got := err.Error()
+				if got != tc.err {
+					t.Fatalf("error: got %v; want %v", got, tc.err)
+				}
+
+			default:
+				b, err := got.MarshalJSON()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(b) != tc.out {
+					t.Errorf("got %s; want %s", b, tc.out)
+				}
+			}
+		})
+	}
+}
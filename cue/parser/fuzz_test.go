@@ -0,0 +1,52 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package parser
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+// maxFuzzSize bounds the size of fuzz inputs.
+const maxFuzzSize = 1 << 12
+
+// FuzzParseFile checks that the parser does not crash on arbitrary input.
+// The seed corpus consists of the files in the corpus directory.
+func FuzzParseFile(f *testing.F) {
+	files, err := filepath.Glob(filepath.Join("corpus", "*.cue"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+	f.Fuzz(func(t *testing.T, b []byte) {
+		if len(b) > maxFuzzSize {
+			t.Skip()
+		}
+		f, err := ParseFile("fuzz.cue", b, ParseComments)
+		if err == nil && f == nil {
+			t.Fatal("ParseFile returned neither a file nor an error")
+		}
+	})
+}
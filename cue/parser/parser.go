@@ -1541,12 +1541,14 @@ func (p *parser) parseImportSpec(_ int) *ast.ImportSpec {
 
 	pos := p.pos
 	var path string
+	var attrs []*ast.Attribute
 	if p.tok == token.STRING {
 		path = p.lit
 		if !isValidImport(path) {
 			p.errf(pos, "invalid import path: %s", path)
 		}
 		p.next()
+		attrs = p.parseAttributes()
 		p.expectComma() // call before accessing p.linecomment
 	} else {
 		p.expect(token.STRING) // use expect() error handling
@@ -1556,8 +1558,9 @@ func (p *parser) parseImportSpec(_ int) *ast.ImportSpec {
 	}
 	// collect imports
 	spec := &ast.ImportSpec{
-		Name: ident,
-		Path: &ast.BasicLit{ValuePos: pos, Kind: token.STRING, Value: path},
+		Name:  ident,
+		Path:  &ast.BasicLit{ValuePos: pos, Kind: token.STRING, Value: path},
+		Attrs: attrs,
 	}
 	c.closeNode(p, spec)
 	p.imports = append(p.imports, spec)
@@ -210,6 +210,16 @@ func TestParse(t *testing.T) {
 		import "bar/baz"
 			`,
 		`package k8s, import a "foo", import "bar/baz"`,
+	}, {
+		"imports with tags",
+		`package k8s
+
+		import (
+			prod "foo" @tag(prod, replicas=3)
+			"bar/baz" @tag(x) @tag(y)
+		)
+		`,
+		`package k8s, import ( prod "foo" @tag(prod, replicas=3), "bar/baz" @tag(x) @tag(y) )`,
 	}, {
 		"collapsed fields",
 		`a: b:: c?: [Name=_]: d: 1
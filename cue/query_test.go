@@ -21,7 +21,7 @@ import (
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/cuecontext"
-	"cuelang.org/go/internal/cuetxtar"
+	"cuelang.org/go/cue/cuetxtar"
 	"cuelang.org/go/internal/diff"
 	"github.com/rogpeppe/go-internal/txtar"
 )
@@ -174,6 +174,48 @@ func TestSyntax(t *testing.T) {
 	}
 }
 		`,
+	}, {
+		name: "rename hidden fields",
+		in: `
+		_#Internal: {name: string}
+		_port: 8080
+		_secret: "x"
+		service: _#Internal & {name: "api", port: _port}
+		`,
+		options: o(cue.RenameHidden(map[string]string{
+			"_#Internal": "#Service",
+			"_port":      "port",
+		})),
+		out: `
+{
+	#Service: {
+		name: string
+	}
+	port_1=port: 8080
+	_secret:     "x"
+	service:     #Service & {
+		name: "api"
+		port: port_1
+	}
+}`,
+	}, {
+		name: "rename hidden fields in final value",
+		in: `
+		_port: 8080
+		_secret: "x"
+		service: {name: "api", port: _port}
+		`,
+		options: o(cue.Final(), cue.RenameHidden(map[string]string{
+			"_port": "port",
+		})),
+		out: `
+{
+	port: 8080
+	service: {
+		name: "api"
+		port: 8080
+	}
+}`,
 	}}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -15,46 +15,11 @@
 package benchmarks
 
 import (
-	"io/ioutil"
-	"path/filepath"
 	"testing"
 
-	"cuelang.org/go/cue"
-	"cuelang.org/go/internal/cuetxtar"
-	"github.com/rogpeppe/go-internal/txtar"
+	"cuelang.org/go/tools/bench"
 )
 
 func Benchmark(b *testing.B) {
-	files, err := ioutil.ReadDir(".")
-	if err != nil {
-		b.Fatal(err)
-	}
-
-	for _, fi := range files {
-		name := fi.Name()
-		if fi.IsDir() || filepath.Ext(name) != ".txtar" {
-			continue
-		}
-
-		a, err := txtar.ParseFile(name)
-		if err != nil {
-			b.Fatal(err)
-		}
-
-		inst := cue.Build(cuetxtar.Load(a, "/cuetest"))[0]
-		if inst.Err != nil {
-			b.Fatal(inst.Err)
-		}
-
-		b.Run(name, func(b *testing.B) {
-			for i := 0; i < b.N; i++ {
-				inst := cue.Build(cuetxtar.Load(a, "/cuetest"))[0]
-				if inst.Err != nil {
-					b.Fatal(inst.Err)
-				}
-
-				inst.Value().Validate()
-			}
-		})
-	}
+	bench.Benchmark(b, ".")
 }
@@ -21,6 +21,7 @@ import (
 	"io"
 	"math"
 	"math/big"
+	"sort"
 	"strings"
 
 	"github.com/cockroachdb/apd/v2"
@@ -151,13 +152,13 @@ func (o *structValue) marshalJSON() (b []byte, err errors.Error) {
 	n := o.Len()
 	for i := 0; i < n; i++ {
 		k, v := o.At(i)
-		s, err := json.Marshal(k)
+		s, err := marshalJSON(k)
 		if err != nil {
 			return nil, unwrapJSONError(err)
 		}
 		b = append(b, s...)
 		b = append(b, ':')
-		bb, err := json.Marshal(v)
+		bb, err := marshalJSON(v)
 		if err != nil {
 			return nil, unwrapJSONError(err)
 		}
@@ -298,7 +299,7 @@ func marshalList(l *Iterator) (b []byte, err errors.Error) {
 	b = append(b, '[')
 	if l.Next() {
 		for i := 0; ; i++ {
-			x, err := json.Marshal(l.Value())
+			x, err := marshalJSON(l.Value())
 			if err != nil {
 				return nil, unwrapJSONError(err)
 			}
@@ -903,6 +904,19 @@ func (v Value) IncompleteKind() Kind {
 	return v.v.Kind()
 }
 
+// marshalJSON is like json.Marshal, but does not escape HTML characters.
+// Escaping is left to the encoder of the top-level value, so that it can be
+// disabled with json.Encoder.SetEscapeHTML.
+func marshalJSON(x interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(x); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
 // MarshalJSON marshalls this value into valid JSON.
 func (v Value) MarshalJSON() (b []byte, err error) {
 	b, err = v.marshalJSON()
@@ -938,7 +952,7 @@ func (v Value) marshalJSON() (b []byte, err error) {
 		b = bytes.TrimLeft(b, "+")
 		return b, err
 	case adt.StringKind:
-		return json.Marshal(x.(*adt.String).Str)
+		return marshalJSON(x.(*adt.String).Str)
 	case adt.BytesKind:
 		return json.Marshal(x.(*adt.Bytes).B)
 	case adt.ListKind:
@@ -974,10 +988,13 @@ func (v Value) Syntax(opts ...Option) ast.Node {
 		TakeDefaults:    o.final,
 		ShowOptional:    !o.omitOptional && !o.concrete,
 		ShowDefinitions: !o.omitDefinitions && !o.concrete,
-		ShowHidden:      !o.omitHidden && !o.concrete,
+		ShowHidden:      !o.omitHidden && !o.concrete || len(o.renameHidden) > 0,
 		ShowAttributes:  !o.omitAttrs,
 		ShowDocs:        o.docs,
 		ShowErrors:      o.showErrors,
+
+		PreserveLiterals: o.literals,
+		Partial:          o.partial,
 	}
 
 	pkgID := v.instance().ID()
@@ -1008,7 +1025,8 @@ You could file a bug with the above information at:
 	// var expr ast.Expr
 	var err error
 	var f *ast.File
-	if o.concrete || o.final || o.resolveReferences {
+	valueMode := o.concrete || o.final || o.resolveReferences
+	if valueMode {
 		// inst = v.instance()
 		var expr ast.Expr
 		expr, err = p.Value(v.idx, pkgID, v.v)
@@ -1029,6 +1047,13 @@ You could file a bug with the above information at:
 		}
 	}
 
+	if len(o.renameHidden) > 0 {
+		// References are resolved in value mode, so hidden fields that were
+		// only shown to be renamed can be dropped safely.
+		drop := valueMode && (o.omitHidden || o.concrete)
+		renameHidden(f, o.renameHidden, drop)
+	}
+
 outer:
 	for _, d := range f.Decls {
 		switch d.(type) {
@@ -1050,6 +1075,46 @@ outer:
 	}
 }
 
+// renameHidden renames the hidden fields of f, and references to them,
+// according to names. If drop is true, hidden fields that are not renamed
+// are removed.
+func renameHidden(f *ast.File, names map[string]string, drop bool) {
+	filter := func(decls []ast.Decl) []ast.Decl {
+		if !drop {
+			return decls
+		}
+		k := 0
+		for _, d := range decls {
+			if x, ok := d.(*ast.Field); ok {
+				name, _, _ := ast.LabelName(x.Label)
+				if _, ok := names[name]; !ok && internal.IsHidden(name) {
+					continue
+				}
+			}
+			decls[k] = d
+			k++
+		}
+		return decls[:k]
+	}
+	// Resolve references before renaming, so that Sanitize can unshadow
+	// references that are captured by fields with the new names.
+	astutil.Resolve(f, func(token.Pos, string, ...interface{}) {})
+	ast.Walk(f, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.File:
+			x.Decls = filter(x.Decls)
+		case *ast.StructLit:
+			x.Elts = filter(x.Elts)
+		case *ast.Ident:
+			if name, ok := names[x.Name]; ok {
+				x.Name = name
+			}
+		}
+		return true
+	}, nil)
+	_ = astutil.Sanitize(f)
+}
+
 // Doc returns all documentation comments associated with the field from which
 // the current value originates.
 func (v Value) Doc() []*ast.CommentGroup {
@@ -1131,6 +1196,498 @@ func (v Value) Allows(sel Selector) bool {
 	return v.v.Accept(c, f)
 }
 
+// A FieldDescription describes a field of a struct.
+type FieldDescription struct {
+	Selector Selector
+	Value    Value
+
+	// IsOptional reports whether the field is declared as optional.
+	IsOptional bool
+
+	// IsRequired reports whether the field is a regular field for which a
+	// concrete value must still be given: it is not optional, has no
+	// default, and is not concrete.
+	IsRequired bool
+
+	// IsDefinition reports whether the field is a definition.
+	IsDefinition bool
+
+	// IsHidden reports whether the field is a hidden field.
+	IsHidden bool
+
+	// HasDefault reports whether the value of the field has a default.
+	HasDefault bool
+
+	// IsData reports whether the value of the field, with defaults
+	// applied, is fully concrete, as opposed to a value that only
+	// constrains data.
+	IsData bool
+
+	// Doc holds the doc comments associated with the field.
+	Doc []*ast.CommentGroup
+}
+
+// DescribeFields reports a description of each field of struct v, including
+// optional fields, definitions, and hidden fields, in order. It reports an
+// error if v is not a struct.
+func (v Value) DescribeFields() ([]FieldDescription, error) {
+	iter, err := v.Fields(Optional(true), Definitions(true), Hidden(true))
+	if err != nil {
+		return nil, err
+	}
+	var a []FieldDescription
+	for iter.Next() {
+		w := iter.Value()
+		sel := iter.Selector()
+		_, hasDefault := w.Default()
+		d := FieldDescription{
+			Selector:     sel,
+			Value:        w,
+			IsOptional:   iter.IsOptional(),
+			IsDefinition: sel.IsDefinition(),
+			IsHidden:     sel.PkgPath() != "",
+			HasDefault:   hasDefault,
+			IsData:       w.Validate(Concrete(true)) == nil,
+			Doc:          w.Doc(),
+		}
+		d.IsRequired = !d.IsOptional && !d.IsDefinition && !d.IsHidden &&
+			!d.HasDefault && !w.IsConcrete()
+		a = append(a, d)
+	}
+	return a, nil
+}
+
+// A FieldSet describes the fields that may be defined in a struct.
+type FieldSet struct {
+	// Fields holds the selectors of the fields that are declared in the
+	// struct, including optional fields and definitions, in order.
+	Fields []Selector
+
+	// Patterns holds the label constraints of the pattern constraints of
+	// the struct, such as =~"^x-" for [=~"^x-"]: string. A regular field
+	// is allowed if its label matches any of these.
+	Patterns []Value
+
+	// Open reports whether any regular field is allowed.
+	Open bool
+}
+
+// AllowedFields reports the fields that may be defined in v. This can be
+// used, for instance, to suggest field names. It reports an error if v is
+// not a struct.
+//
+// Hidden fields are always allowed and are not included.
+func (v Value) AllowedFields() (*FieldSet, error) {
+	iter, err := v.Fields(Optional(true), Definitions(true))
+	if err != nil {
+		return nil, err
+	}
+	s := &FieldSet{Open: v.Allows(AnyString)}
+	for iter.Next() {
+		s.Fields = append(s.Fields, iter.Selector())
+	}
+	for _, p := range v.patterns() {
+		s.Patterns = append(s.Patterns, remakeValue(v, p.env, p.Filter))
+	}
+	return s, nil
+}
+
+// A PatternConstraint is a constraint of the form [Pattern]: Value, which
+// applies Value to all fields of a struct whose label matches Pattern.
+type PatternConstraint struct {
+	// Pattern is the constraint on the labels of the fields.
+	Pattern Value
+
+	// Value is the constraint on the values of the matching fields.
+	// References to an alias of the label are not resolved.
+	Value Value
+}
+
+// PatternConstraints reports the pattern constraints of v in the order in
+// which they are declared. It reports an error if v is not a struct.
+//
+// Use AllowedFields to determine whether v allows fields that match none of
+// its patterns and AdditionalConstraint for the constraint on such fields.
+func (v Value) PatternConstraints() ([]PatternConstraint, error) {
+	if _, err := v.Fields(); err != nil {
+		return nil, err
+	}
+	var a []PatternConstraint
+	for _, p := range v.patterns() {
+		a = append(a, PatternConstraint{
+			Pattern: remakeValue(v, p.env, p.Filter),
+			Value:   remakeValue(v, p.env, p.Value),
+		})
+	}
+	return a, nil
+}
+
+// AdditionalConstraint reports the constraint T of a declaration ...T in
+// struct v, which applies to all fields that are neither declared nor
+// matched by a pattern constraint. It reports top (_) for a declaration
+// ... without a constraint and false if v has no such declaration.
+func (v Value) AdditionalConstraint() (Value, bool) {
+	if v.v == nil {
+		return Value{}, false
+	}
+	n := &adt.Vertex{Label: v.v.Label}
+	for _, s := range v.v.Structs {
+		if s.Disable {
+			continue
+		}
+		s.Init()
+		for _, x := range s.Additional {
+			n.AddConjunct(adt.MakeRootConjunct(s.Env, x))
+		}
+	}
+	if len(n.Conjuncts) == 0 {
+		return Value{}, false
+	}
+	n = manifest(v.ctx(), n)
+	n.Parent = v.v.Parent
+	return makeChildValue(v.parent(), n), true
+}
+
+// ListConstraints describes the constraints on the elements and length of a
+// list.
+type ListConstraints struct {
+	// Elems holds the constraints on the elements of the list that are
+	// declared explicitly, in order.
+	Elems []Value
+
+	// Elem holds the constraint T of an ellipsis ...T, which applies to all
+	// elements beyond Elems. It is top (_) for an ellipsis without a
+	// constraint and does not exist if the list is closed.
+	Elem Value
+
+	// MinLen is the minimum number of elements of the list.
+	MinLen int64
+
+	// MaxLen is the maximum number of elements of the list, or -1 if the
+	// number of elements is not bounded.
+	MaxLen int64
+}
+
+// Index reports the constraint on the element at position i of the list. It
+// reports false if the list cannot have an element at this position.
+func (l *ListConstraints) Index(i int) (Value, bool) {
+	switch {
+	case i < 0, l.MaxLen >= 0 && int64(i) >= l.MaxLen:
+		return Value{}, false
+	case i < len(l.Elems):
+		return l.Elems[i], true
+	}
+	return l.Elem, l.Elem.Exists()
+}
+
+// ListConstraints reports the constraints on the elements and length of list
+// v. The length bounds take into account the builtin validators
+// list.MinItems and list.MaxItems. If v is a disjunction, the constraints of
+// its default are reported. It reports an error if v is not a list.
+func (v Value) ListConstraints() (*ListConstraints, error) {
+	if v.v != nil {
+		// The default of an open list is the closed list of its elements.
+		if _, ok := v.v.BaseValue.(*adt.Disjunction); ok {
+			v, _ = v.Default()
+		}
+	}
+	iter, err := v.List()
+	if err != nil {
+		return nil, err
+	}
+	l := &ListConstraints{MaxLen: -1}
+	for iter.Next() {
+		l.Elems = append(l.Elems, iter.Value())
+	}
+	l.MinLen = int64(len(l.Elems))
+	if v.v.IsClosedList() {
+		l.MaxLen = l.MinLen
+	} else {
+		l.Elem = v.LookupPath(MakePath(AnyIndex))
+	}
+	v.lenBounds(l)
+	return l, nil
+}
+
+// lenBounds narrows the length bounds of l using the list.MinItems and
+// list.MaxItems validators of v.
+func (v Value) lenBounds(l *ListConstraints) {
+	switch op, a := v.Expr(); op {
+	case AndOp:
+		for _, x := range a {
+			x.lenBounds(l)
+		}
+
+	case CallOp:
+		b, ok := a[0].eval(v.ctx()).(*adt.Builtin)
+		if !ok || len(a) != 2 || b.Package.SelectorString(v.idx) != "list" {
+			return
+		}
+		n, err := a[1].Int64()
+		if err != nil {
+			return
+		}
+		switch b.Name {
+		case "MinItems":
+			if n > l.MinLen {
+				l.MinLen = n
+			}
+		case "MaxItems":
+			if l.MaxLen < 0 || n < l.MaxLen {
+				l.MaxLen = n
+			}
+		}
+	}
+}
+
+// NumberBounds describes the constraints on the range of a number.
+type NumberBounds struct {
+	// Min holds the lower bound of the number. It does not exist if the
+	// number has no lower bound.
+	Min Value
+
+	// MinExclusive reports whether the lower bound is exclusive, as in >x,
+	// rather than inclusive, as in >=x.
+	MinExclusive bool
+
+	// Max holds the upper bound of the number. It does not exist if the
+	// number has no upper bound.
+	Max Value
+
+	// MaxExclusive reports whether the upper bound is exclusive, as in <x,
+	// rather than inclusive, as in <=x.
+	MaxExclusive bool
+
+	// MultipleOf holds the values of which the number must be a multiple,
+	// as given by the math.MultipleOf validator.
+	MultipleOf []Value
+}
+
+// Bounds reports the bounds of number v. If v is constrained by several
+// bounds, as in >=0 & >2 & <10, only the tightest lower and upper bound are
+// reported. A concrete number is its own inclusive lower and upper bound.
+// Bounds within disjunctions are not reported. It reports an error if v
+// cannot be a number.
+func (v Value) Bounds() (*NumberBounds, error) {
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	if k := v.IncompleteKind(); k&NumberKind == 0 {
+		return nil, v.toErr(mkErr(v.idx, v.v,
+			"cannot use value %v (type %s) as number", v, k))
+	}
+	b := &NumberBounds{}
+	b.add(v, v.eval(v.ctx()))
+	return b, nil
+}
+
+// add adds the bounds of the evaluated value x of v to b.
+func (b *NumberBounds) add(v Value, x adt.Value) {
+	switch x := x.(type) {
+	case *adt.Conjunction:
+		for _, y := range x.Values {
+			b.add(v, y)
+		}
+
+	case *adt.Num:
+		n := remakeFinal(v, nil, x)
+		b.setMin(n, false)
+		b.setMax(n, false)
+
+	case *adt.BoundValue:
+		n := remakeFinal(v, nil, x.Value)
+		switch x.Op {
+		case adt.GreaterThanOp, adt.GreaterEqualOp:
+			b.setMin(n, x.Op == adt.GreaterThanOp)
+		case adt.LessThanOp, adt.LessEqualOp:
+			b.setMax(n, x.Op == adt.LessThanOp)
+		}
+
+	case *adt.BuiltinValidator:
+		f := x.Builtin
+		if len(x.Args) == 1 && f.Name == "MultipleOf" &&
+			f.Package.SelectorString(v.idx) == "math" {
+			b.MultipleOf = append(b.MultipleOf, remakeFinal(v, nil, x.Args[0]))
+		}
+	}
+}
+
+func (b *NumberBounds) setMin(x Value, exclusive bool) {
+	if c, ok := cmpNum(x, b.Min); !ok || c > 0 || c == 0 && exclusive {
+		b.Min, b.MinExclusive = x, exclusive
+	}
+}
+
+func (b *NumberBounds) setMax(x Value, exclusive bool) {
+	if c, ok := cmpNum(x, b.Max); !ok || c < 0 || c == 0 && exclusive {
+		b.Max, b.MaxExclusive = x, exclusive
+	}
+}
+
+// cmpNum compares the numbers x and y. It reports false if y is not a
+// number.
+func cmpNum(x, y Value) (int, bool) {
+	if !y.Exists() {
+		return 0, false
+	}
+	m, err := x.getNum(adt.NumKind)
+	if err != nil {
+		return 0, true
+	}
+	n, err := y.getNum(adt.NumKind)
+	if err != nil {
+		return 0, false
+	}
+	return m.X.Cmp(&n.X), true
+}
+
+// StringConstraints describes the constraints on a string.
+type StringConstraints struct {
+	// Patterns holds the regular expressions that the string must match, as
+	// given by =~.
+	Patterns []string
+
+	// NotPatterns holds the regular expressions that the string must not
+	// match, as given by !~.
+	NotPatterns []string
+
+	// MinRunes is the minimum number of runes of the string, as given by
+	// strings.MinRunes.
+	MinRunes int64
+
+	// MaxRunes is the maximum number of runes of the string, as given by
+	// strings.MaxRunes, or -1 if the number of runes is not bounded.
+	MaxRunes int64
+
+	// Enum holds the allowed values of the string if it is a concrete string
+	// or a disjunction of concrete strings, including defaults, and is nil
+	// otherwise.
+	Enum []string
+}
+
+// StringConstraints reports the constraints on string v. It reports an error
+// if v cannot be a string.
+func (v Value) StringConstraints() (*StringConstraints, error) {
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	if k := v.IncompleteKind(); k&StringKind == 0 {
+		return nil, v.toErr(mkErr(v.idx, v.v,
+			"cannot use value %v (type %s) as string", v, k))
+	}
+	s := &StringConstraints{MaxRunes: -1}
+	if d, ok := v.v.BaseValue.(*adt.Disjunction); ok {
+		s.Enum = []string{}
+		for _, x := range d.Values {
+			str, ok := x.Value().(*adt.String)
+			if !ok {
+				s.Enum = nil
+				break
+			}
+			s.Enum = append(s.Enum, str.Str)
+		}
+		return s, nil
+	}
+	s.add(v, v.eval(v.ctx()))
+	return s, nil
+}
+
+// add adds the constraints of the evaluated value x of v to s.
+func (s *StringConstraints) add(v Value, x adt.Value) {
+	switch x := x.(type) {
+	case *adt.Conjunction:
+		for _, y := range x.Values {
+			s.add(v, y)
+		}
+
+	case *adt.String:
+		s.Enum = []string{x.Str}
+
+	case *adt.BoundValue:
+		str, ok := x.Value.(*adt.String)
+		if !ok {
+			break
+		}
+		switch x.Op {
+		case adt.MatchOp:
+			s.Patterns = append(s.Patterns, str.Str)
+		case adt.NotMatchOp:
+			s.NotPatterns = append(s.NotPatterns, str.Str)
+		}
+
+	case *adt.BuiltinValidator:
+		f := x.Builtin
+		if len(x.Args) != 1 || f.Package.SelectorString(v.idx) != "strings" {
+			break
+		}
+		n, err := remakeFinal(v, nil, x.Args[0]).Int64()
+		if err != nil {
+			break
+		}
+		switch f.Name {
+		case "MinRunes":
+			if n > s.MinRunes {
+				s.MinRunes = n
+			}
+		case "MaxRunes":
+			if s.MaxRunes < 0 || n < s.MaxRunes {
+				s.MaxRunes = n
+			}
+		}
+	}
+}
+
+// EnumValues reports the members of enum v. An enum is a disjunction of
+// concrete scalars, that is, null, booleans, numbers, strings, and bytes, as
+// in "red" | "green" | "blue". A default is reported as a regular member. A
+// concrete scalar is an enum with a single member. It reports an error if v
+// is not an enum.
+func (v Value) EnumValues() ([]Value, error) {
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	a := []*adt.Vertex{v.v}
+	if d, ok := v.v.BaseValue.(*adt.Disjunction); ok {
+		a = d.Values
+	}
+	members := make([]Value, 0, len(a))
+	for _, w := range a {
+		x := w.Value()
+		if x == nil || !adt.IsConcrete(x) || x.Kind()&adt.ScalarKinds == 0 {
+			return nil, v.toErr(mkErr(v.idx, v.v,
+				"value %v is not a disjunction of concrete scalars", v))
+		}
+		members = append(members, remakeFinal(v, nil, x))
+	}
+	return members, nil
+}
+
+type pattern struct {
+	*adt.BulkOptionalField
+	env *adt.Environment
+}
+
+// patterns reports the unique pattern constraints of v.
+func (v Value) patterns() (a []pattern) {
+	if v.v == nil {
+		return nil
+	}
+	seen := map[*adt.BulkOptionalField]bool{}
+	for _, s := range v.v.Structs {
+		if s.Disable {
+			continue
+		}
+		s.Init()
+		for _, b := range s.Bulk {
+			if !seen[b] {
+				seen[b] = true
+				a = append(a, pattern{b, s.Env})
+			}
+		}
+	}
+	return a
+}
+
 // IsConcrete reports whether the current value is a concrete scalar value
 // (not relying on default values), a terminal error, a list, or a struct.
 // It does not verify that values of lists or structs are concrete themselves.
@@ -1716,6 +2273,45 @@ func (v Value) FillPath(p Path, x interface{}) Value {
 	return v.Unify(w)
 }
 
+// Specialize partially evaluates v for the given inputs and returns the
+// residual configuration. Each key of fills is a path, as accepted by
+// ParsePath, and each value is filled in at that path as with FillPath.
+//
+// Values that become concrete, such as the results of comprehensions and
+// arithmetic that depend only on the given inputs, are written as values.
+// The expressions of the remaining values are retained, but with their
+// subexpressions that evaluate to concrete values substituted, so that,
+// for instance, "\(env).\(region)" becomes "prod.\(region)" if only env is
+// given. The residual configuration is typically cheaper to evaluate than
+// the original. The options are passed to Syntax to generate it.
+//
+// An error is returned if a key of fills is not a valid path or if any of
+// the inputs conflicts with v.
+func (v Value) Specialize(fills map[string]interface{}, opts ...Option) (ast.Node, error) {
+	keys := make([]string, 0, len(fills))
+	for k := range fills {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	w := v
+	for _, k := range keys {
+		p := ParsePath(k)
+		if err := p.Err(); err != nil {
+			return nil, errors.Wrapf(err, token.NoPos, "invalid path %q", k)
+		}
+		w = w.FillPath(p, fills[k])
+	}
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+	opts = append([]Option{
+		ResolveReferences(true),
+		func(o *options) { o.partial = true },
+	}, opts...)
+	return w.Syntax(opts...), nil
+}
+
 // Template returns a function that represents the template definition for a
 // struct in a configuration file. It returns nil if v is not a struct kind or
 // if there is no template associated with the struct.
@@ -2013,6 +2609,9 @@ type options struct {
 	docs              bool
 	disallowCycles    bool // implied by concrete
 	allowScalar       bool
+	literals          bool
+	partial           bool
+	renameHidden      map[string]string
 }
 
 // An Option defines modes of evaluation.
@@ -2121,6 +2720,17 @@ func Hidden(include bool) Option {
 	}
 }
 
+// RenameHidden indicates that the hidden fields named by the keys of names
+// are to be output as regular fields, named by the corresponding values,
+// even if hidden fields are otherwise omitted. References to these fields are
+// updated accordingly. The new names must be valid identifiers.
+//
+// This is useful for publishing schemas of which some parts are hidden
+// within the package defining them.
+func RenameHidden(names map[string]string) Option {
+	return func(p *options) { p.renameHidden = names }
+}
+
 // Optional indicates that optional fields should be included.
 func Optional(include bool) Option {
 	return func(p *options) { p.omitOptional = !include }
@@ -2131,6 +2741,13 @@ func Attributes(include bool) Option {
 	return func(p *options) { p.omitAttrs = !include }
 }
 
+// PreserveLiterals indicates that strings and bytes that are defined by a
+// literal should be written as they were written, for instance as a raw or
+// multiline string, instead of in a normalized form.
+func PreserveLiterals(preserve bool) Option {
+	return func(p *options) { p.literals = preserve }
+}
+
 func getOptions(opts []Option) (o options) {
 	o.updateOptions(opts)
 	return
@@ -1230,6 +1230,90 @@ func TestFillPathError(t *testing.T) {
 	}
 }
 
+func TestSpecialize(t *testing.T) {
+	r := &Runtime{}
+
+	testCases := []struct {
+		in    string
+		fills map[string]interface{}
+		out   string
+		err   string
+	}{{
+		in: `
+		env:      "prod" | "dev"
+		replicas: [ if env == "prod" {3}, 1][0]
+		host:     "\(env).\(region).example.com"
+		region:   string
+		`,
+		fills: map[string]interface{}{"env": "prod"},
+		out:   `{env: "prod", replicas: 3, host: "prod.\(region).example.com", region: string}`,
+	}, {
+		in: `
+		env:    string
+		region: string
+		host:   "\(env).\(region).example.com"
+		`,
+		fills: map[string]interface{}{"env": "dev", "region": "eu"},
+		out:   `{env: "dev", region: "eu", host: "dev.eu.example.com"}`,
+	}, {
+		in: `
+		a: b: int
+		c: {
+			if a.b > 1 { size: "large" }
+			if a.b <= 1 { size: "small" }
+			name: string
+		}
+		`,
+		fills: map[string]interface{}{"a.b": 2},
+		out:   `{a: {b: 2}, c: {name: string, size: "large"}}`,
+	}, {
+		in: `
+		n:     int
+		scale: int
+		size:  n * scale + 1
+		tags:  [ "n=\(n)", "scale=\(scale)" ]
+		ratio: n * 2 / scale
+		`,
+		fills: map[string]interface{}{"n": 3},
+		out:   `{n: 3, scale: int, size: 3*scale+1, tags: ["n=3", "scale=\(scale)"], ratio: 6/scale}`,
+	}, {
+		in:    `env: "prod" | "dev"`,
+		fills: map[string]interface{}{"env": "qa"},
+		err:   "env: 2 errors in empty disjunction",
+	}, {
+		in:    `#A: {a: int}, x: #A`,
+		fills: map[string]interface{}{"x.b": 1},
+		err:   "x: field not allowed: b",
+	}, {
+		in:    `a: int`,
+		fills: map[string]interface{}{"a..b": 1},
+		err:   `invalid path "a..b"`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			v := compileT(t, r, tc.in).Value()
+			n, err := v.Specialize(tc.fills)
+			if tc.err != "" {
+				if err == nil {
+					t.Fatalf("unexpected success")
+				}
+				if got := err.Error(); !strings.Contains(got, tc.err) {
+					t.Errorf("\ngot:  %s\nwant: %s", got, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := astinternal.DebugStr(n)
+			if got != tc.out {
+				t.Errorf("\ngot:  %s\nwant: %s", got, tc.out)
+			}
+		})
+	}
+}
+
 func TestAllows(t *testing.T) {
 	r := &Runtime{}
 
@@ -1469,6 +1553,467 @@ func TestAllows(t *testing.T) {
 	}
 }
 
+func TestDescribeFields(t *testing.T) {
+	r := &Runtime{}
+	v := compileT(t, r, `
+	// The name.
+	name: string
+	port: *80 | int
+	tag?: string
+	#Def: {a: int}
+	_h: 1
+	data: {a: 1, b: [2]}
+	cfg: {a: int}
+	`).Value()
+
+	a, err := v.DescribeFields()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, d := range a {
+		var flags []string
+		for _, f := range []struct {
+			name string
+			set  bool
+		}{
+			{"optional", d.IsOptional},
+			{"required", d.IsRequired},
+			{"definition", d.IsDefinition},
+			{"hidden", d.IsHidden},
+			{"default", d.HasDefault},
+			{"data", d.IsData},
+		} {
+			if f.set {
+				flags = append(flags, f.name)
+			}
+		}
+		doc := ""
+		for _, cg := range d.Doc {
+			doc += strings.TrimSpace(cg.Text())
+		}
+		got = append(got, fmt.Sprintf("%v: %s %q", d.Selector, strings.Join(flags, ","), doc))
+	}
+	want := `name: required "The name."
+port: default,data ""
+tag: optional ""
+#Def: definition ""
+_h: hidden,data ""
+data: data ""
+cfg:  ""`
+	if s := strings.Join(got, "\n"); s != want {
+		t.Errorf("got:\n%s\nwant:\n%s", s, want)
+	}
+
+	if _, err := v.LookupPath(ParsePath("name")).DescribeFields(); err == nil {
+		t.Error("expected error for non-struct value")
+	}
+}
+
+func TestAllowedFields(t *testing.T) {
+	r := &Runtime{}
+
+	testCases := []struct {
+		desc string
+		in   string
+		want string
+	}{{
+		desc: "open struct",
+		in: `
+		x: {a: int, b?: int}
+		`,
+		want: `fields: a b; patterns: ; open: true`,
+	}, {
+		desc: "definition",
+		in: `
+		x: #Def & {a: 1}
+		#Def: {
+			a:   int
+			b?:  string
+			#c:  int
+			_h:  int
+			[=~"^x-"]: string
+		}
+		`,
+		want: `fields: a b #c; patterns: =~"^x-"; open: false`,
+	}, {
+		desc: "open definition",
+		in: `
+		x: #Def
+		#Def: {a: int, ...}
+		`,
+		want: `fields: a; patterns: ; open: true`,
+	}, {
+		desc: "not a struct",
+		in: `
+		x: 1
+		`,
+		want: `error`,
+	}}
+
+	path := ParsePath("x")
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			v := compileT(t, r, tc.in).Value()
+			v = v.LookupPath(path)
+
+			s, err := v.AllowedFields()
+			got := "error"
+			if err == nil {
+				got = fmt.Sprintf("fields: %s; patterns: %s; open: %v",
+					strings.Trim(fmt.Sprint(s.Fields), "[]"),
+					strings.Trim(fmt.Sprint(s.Patterns), "[]"),
+					s.Open)
+			}
+			if got != tc.want {
+				t.Errorf("got %v; want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPatternConstraints(t *testing.T) {
+	r := &Runtime{}
+
+	testCases := []struct {
+		desc       string
+		in         string
+		patterns   string
+		additional string
+	}{{
+		desc: "none",
+		in: `
+		x: {a: int}
+		`,
+		patterns:   ``,
+		additional: `none`,
+	}, {
+		desc: "patterns",
+		in: `
+		x: #Def
+		#Def: {
+			a: int
+			[=~"^x-"]: string
+			[=~"^n-"]: int & >0
+		}
+		`,
+		patterns:   `=~"^x-": string; =~"^n-": >0 & int`,
+		additional: `none`,
+	}, {
+		desc: "additional",
+		in: `
+		x: {
+			[string]: int
+			...
+		}
+		`,
+		patterns:   `string: int`,
+		additional: `_`,
+	}, {
+		desc: "closed with additional",
+		in: `
+		x: #Def
+		#Def: {a: int, ...}
+		`,
+		patterns:   ``,
+		additional: `_`,
+	}}
+
+	path := ParsePath("x")
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			v := compileT(t, r, tc.in).Value()
+			v = v.LookupPath(path)
+
+			a, err := v.PatternConstraints()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var patterns []string
+			for _, p := range a {
+				patterns = append(patterns, fmt.Sprintf("%v: %v", p.Pattern, p.Value))
+			}
+			if got := strings.Join(patterns, "; "); got != tc.patterns {
+				t.Errorf("patterns: got %v; want %v", got, tc.patterns)
+			}
+
+			got := "none"
+			if w, ok := v.AdditionalConstraint(); ok {
+				got = fmt.Sprint(w)
+			}
+			if got != tc.additional {
+				t.Errorf("additional: got %v; want %v", got, tc.additional)
+			}
+		})
+	}
+}
+
+func TestListConstraints(t *testing.T) {
+	r := &Runtime{}
+
+	testCases := []struct {
+		desc string
+		in   string
+		out  string
+		err  string
+	}{{
+		desc: "closed",
+		in:   `x: [1, string]`,
+		out:  `elems: [1 string]; elem: none; len: 2..2; index 2: none`,
+	}, {
+		desc: "open",
+		in:   `x: [int, ...string]`,
+		out:  `elems: [int]; elem: string; len: 1..-1; index 2: string`,
+	}, {
+		desc: "open without constraint",
+		in:   `x: [...]`,
+		out:  `elems: []; elem: _; len: 0..-1; index 2: _`,
+	}, {
+		desc: "length validators",
+		in: `
+		import "list"
+
+		x: [int, ...int] & list.MinItems(0) & list.MaxItems(2)
+		`,
+		out: `elems: [int]; elem: int; len: 1..2; index 2: none`,
+	}, {
+		desc: "definition",
+		in: `
+		x: #L
+		#L: [...{a: int}]
+		`,
+		out: `elems: []; elem: { a: int }; len: 0..-1; index 2: { a: int }`,
+	}, {
+		desc: "default",
+		in:   `x: *[1] | [...int]`,
+		out:  `elems: [1]; elem: none; len: 1..1; index 2: none`,
+	}, {
+		desc: "not a list",
+		in:   `x: {a: 1}`,
+		err:  `x: cannot use value {a:1} (type struct) as list`,
+	}}
+
+	path := ParsePath("x")
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			v := compileT(t, r, tc.in).Value()
+			v = v.LookupPath(path)
+
+			l, err := v.ListConstraints()
+			if err != nil || tc.err != "" {
+				if got := fmt.Sprint(err); got != tc.err {
+					t.Errorf("error: got %v; want %v", got, tc.err)
+				}
+				return
+			}
+			str := func(v Value, ok bool) string {
+				if !ok {
+					return "none"
+				}
+				return strings.Join(strings.Fields(fmt.Sprint(v)), " ")
+			}
+			got := fmt.Sprintf("elems: %v; elem: %s; len: %d..%d; index 2: %s",
+				l.Elems, str(l.Elem, l.Elem.Exists()), l.MinLen, l.MaxLen,
+				str(l.Index(2)))
+			if got != tc.out {
+				t.Errorf("got %v; want %v", got, tc.out)
+			}
+		})
+	}
+}
+
+func TestBounds(t *testing.T) {
+	r := &Runtime{}
+
+	testCases := []struct {
+		in  string
+		out string
+		err string
+	}{{
+		in:  `x: number`,
+		out: `none none []`,
+	}, {
+		in:  `x: int & >=0 & >2 & <10 & <=10`,
+		out: `>2 <10 []`,
+	}, {
+		in:  `x: >=2 & >2 & <=3`,
+		out: `>2 <=3 []`,
+	}, {
+		in:  `x: uint8`,
+		out: `>=0 <=255 []`,
+	}, {
+		in:  `x: 5`,
+		out: `>=5 <=5 []`,
+	}, {
+		in: `
+		import "math"
+
+		x: math.MultipleOf(3) & >0 & <=100.5
+		`,
+		out: `>0 <=100.5 [3]`,
+	}, {
+		in: `
+		x: #D & >1
+		#D: int & <5
+		`,
+		out: `>1 <5 []`,
+	}, {
+		in:  `x: *1 | >0`,
+		out: `none none []`,
+	}, {
+		in:  `x: string`,
+		err: `x: cannot use value string (type string) as number`,
+	}}
+
+	path := ParsePath("x")
+
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			v := compileT(t, r, tc.in).Value()
+			v = v.LookupPath(path)
+
+			b, err := v.Bounds()
+			if err != nil || tc.err != "" {
+				if got := fmt.Sprint(err); got != tc.err {
+					t.Errorf("error: got %v; want %v", got, tc.err)
+				}
+				return
+			}
+			bound := func(v Value, op, exclusiveOp string, exclusive bool) string {
+				if !v.Exists() {
+					return "none"
+				}
+				if exclusive {
+					op = exclusiveOp
+				}
+				return fmt.Sprint(op, v)
+			}
+			got := fmt.Sprint(
+				bound(b.Min, ">=", ">", b.MinExclusive), " ",
+				bound(b.Max, "<=", "<", b.MaxExclusive), " ",
+				b.MultipleOf)
+			if got != tc.out {
+				t.Errorf("got %v; want %v", got, tc.out)
+			}
+		})
+	}
+}
+
+func TestStringConstraints(t *testing.T) {
+	r := &Runtime{}
+
+	testCases := []struct {
+		in  string
+		out string
+		err string
+	}{{
+		in:  `x: string`,
+		out: `patterns: []; not: []; runes: 0..-1; enum: []`,
+	}, {
+		in: `
+		import "strings"
+
+		x: =~"^a" & !~"b$" & strings.MinRunes(2) & strings.MaxRunes(5)
+		x: strings.MaxRunes(4)
+		`,
+		out: `patterns: ["^a"]; not: ["b$"]; runes: 2..4; enum: []`,
+	}, {
+		in:  `x: "foo"`,
+		out: `patterns: []; not: []; runes: 0..-1; enum: ["foo"]`,
+	}, {
+		in:  `x: *"a" | "b" | "c"`,
+		out: `patterns: []; not: []; runes: 0..-1; enum: ["a" "b" "c"]`,
+	}, {
+		in: `
+		x: #Enum
+		#Enum: "x" | "y"
+		`,
+		out: `patterns: []; not: []; runes: 0..-1; enum: ["x" "y"]`,
+	}, {
+		in:  `x: "a" | =~"^b"`,
+		out: `patterns: []; not: []; runes: 0..-1; enum: []`,
+	}, {
+		in:  `x: int`,
+		err: `x: cannot use value int (type int) as string`,
+	}}
+
+	path := ParsePath("x")
+
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			v := compileT(t, r, tc.in).Value()
+			v = v.LookupPath(path)
+
+			s, err := v.StringConstraints()
+			if err != nil || tc.err != "" {
+				if got := fmt.Sprint(err); got != tc.err {
+					t.Errorf("error: got %v; want %v", got, tc.err)
+				}
+				return
+			}
+			got := fmt.Sprintf("patterns: %q; not: %q; runes: %d..%d; enum: %q",
+				s.Patterns, s.NotPatterns, s.MinRunes, s.MaxRunes, s.Enum)
+			if got != tc.out {
+				t.Errorf("got %v; want %v", got, tc.out)
+			}
+		})
+	}
+}
+
+func TestEnumValues(t *testing.T) {
+	r := &Runtime{}
+	path := ParsePath("x")
+
+	testCases := []struct {
+		in  string
+		out string
+		err string
+	}{{
+		in:  `x: "red" | "green" | "blue"`,
+		out: `["red" "green" "blue"]`,
+	}, {
+		in:  `x: *"red" | "green" | "blue"`,
+		out: `["red" "green" "blue"]`,
+	}, {
+		in: `
+		x: #Level
+		#Level: 1 | 2 | 3 | null
+		`,
+		out: `[1 2 3 null]`,
+	}, {
+		in:  `x: true`,
+		out: `[true]`,
+	}, {
+		in:  `x: "a" | "b" | string`,
+		err: `x: value "a" | "b" | string is not a disjunction of concrete scalars`,
+	}, {
+		in:  `x: [1] | [2]`,
+		err: `x: value [1] | [2] is not a disjunction of concrete scalars`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			v := compileT(t, r, tc.in).Value()
+			v = v.LookupPath(path)
+
+			members, err := v.EnumValues()
+			if err != nil || tc.err != "" {
+				if got := fmt.Sprint(err); got != tc.err {
+					t.Errorf("error: got %v; want %v", got, tc.err)
+				}
+				return
+			}
+			got := fmt.Sprint(members)
+			if got != tc.out {
+				t.Errorf("got %v; want %v", got, tc.out)
+			}
+		})
+	}
+}
+
 func TestFillFloat(t *testing.T) {
 	// This tests panics for issue #749
 
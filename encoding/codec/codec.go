@@ -0,0 +1,307 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package codec converts CUE from and to the file formats supported by the
+// cue command line tool.
+//
+// Files are described by a build.File, which is typically obtained from a file
+// specification as accepted on the command line, such as "data.yaml",
+// "json: -", or "openapi+yaml:schema.txt". See "cue help filetypes" for the
+// supported qualifiers. Encoders and decoders select the format,
+// interpretation, and encoding options based on this description in the
+// same way as the cue tool does.
+//
+// Example:
+//
+//	f, err := codec.ParseFile("yaml:-", codec.Export)
+//	if err != nil {
+//		return err
+//	}
+//	enc, err := codec.NewEncoder(f, &codec.Config{Stdout: w})
+//	if err != nil {
+//		return err
+//	}
+//	defer enc.Close()
+//	return enc.Encode(v)
+package codec
+
+import (
+	"io"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/internal/encoding"
+	"cuelang.org/go/internal/filetypes"
+)
+
+// A Mode indicates the operation for which a file is used. It determines the
+// defaults for file types that are not fully qualified, such as whether
+// output must be concrete.
+type Mode int
+
+const (
+	// Input is the mode for files that are read.
+	Input Mode = iota
+
+	// Export is the mode for concrete output, as in cue export.
+	Export
+
+	// Def is the mode for output of definitions, as in cue def.
+	Def
+
+	// Eval is the mode for output of possibly incomplete values, as in
+	// cue eval.
+	Eval
+)
+
+func (m Mode) internal() filetypes.Mode {
+	switch m {
+	case Export:
+		return filetypes.Export
+	case Def:
+		return filetypes.Def
+	case Eval:
+		return filetypes.Eval
+	}
+	return filetypes.Input
+}
+
+func (m Mode) String() string {
+	return m.internal().String()
+}
+
+// ParseFile parses a file specification, such as "data.yaml" or
+// "jsonschema:schema.json", into a build.File for the given mode. The file
+// name "-" denotes standard input or output.
+func ParseFile(spec string, mode Mode) (*build.File, error) {
+	return filetypes.ParseFile(spec, mode.internal())
+}
+
+// ParseArgs parses a sequence of file specifications, where a qualifier
+// without a file name, as in "yaml:", applies to all subsequent files.
+func ParseArgs(args []string) ([]*build.File, error) {
+	return filetypes.ParseArgs(args)
+}
+
+// A FieldOrder specifies the order in which fields are written.
+type FieldOrder string
+
+const (
+	// SourceOrder writes fields in the order in which they are declared.
+	SourceOrder FieldOrder = ""
+
+	// LexicalOrder sorts fields by their label.
+	LexicalOrder FieldOrder = "lexical"
+
+	// SchemaOrder sorts fields by the position specified in an order
+	// attribute, for instance @order(1). Fields without such an attribute
+	// are written after the ones that have one, in source order.
+	SchemaOrder FieldOrder = "schema"
+)
+
+// Config configures encoders and decoders.
+type Config struct {
+	// Mode is used to determine the file type for files that do not fully
+	// specify one.
+	Mode Mode
+
+	// Stdin and Stdout are used for files named "-". They default to
+	// os.Stdin and os.Stdout.
+	Stdin  io.Reader
+	Stdout io.Writer
+
+	// PkgName is the package name for generated CUE files.
+	PkgName string
+
+	// Force allows existing files to be overwritten.
+	Force bool
+
+	// Stream indicates that more than one value may be written to a file.
+	Stream bool
+
+	// Strict reports errors for lossy mappings.
+	Strict bool
+
+	// Annotate records, when decoding JSON Schema, the keywords that are not
+	// represented by CUE constraints in @jsonschema attributes, so that the
+	// schema can be reproduced when it is encoded. See the corresponding
+	// option of jsonschema.Config.
+	Annotate bool
+
+	// AllErrors reports all errors instead of only the first.
+	AllErrors bool
+
+	// Schema is used for decoding formats, such as textproto, that
+	// require a schema.
+	Schema cue.Value
+
+	// EscapeHTML escapes HTML characters in JSON strings.
+	EscapeHTML bool
+
+	// FieldOrder determines the order of fields in concrete output.
+	FieldOrder FieldOrder
+
+	// ProtoPath lists the directories in which to search for imports of
+	// protocol buffer definitions.
+	ProtoPath []string
+
+	// ExpandReferences inlines references in OpenAPI and JSON Schema output.
+	// Recursive references are expanded at most MaxCycleDepth times. See the
+	// corresponding options of openapi.Config.
+	ExpandReferences bool
+	MaxCycleDepth    int
+
+	// Numbers defines how floating-point numbers are written in JSON and
+	// YAML output.
+	Numbers NumberFormat
+
+	// PreserveLiterals writes string and bytes values in CUE output in the
+	// form in which their literals were written, for instance as a raw or
+	// multiline string.
+	PreserveLiterals bool
+
+	// Newline is the line ending of text output, such as "\r\n". It
+	// defaults to "\n". Binary output is written unmodified.
+	Newline string
+}
+
+// NumberFormat defines how floating-point numbers are written. The zero value
+// writes numbers as they are represented by CUE. Integer values are never
+// modified.
+type NumberFormat struct {
+	// Integers writes numbers with an integral value, such as 2.0 or 1e3, as
+	// integers.
+	Integers bool
+
+	// Decimals, if positive, is the number of digits written after the
+	// decimal point. Numbers are rounded or padded with zeros as needed.
+	Decimals int
+
+	// Exponent, if positive, writes numbers in exponent notation if the
+	// exponent of their most significant digit is at least Exponent or at
+	// most -Exponent, and without an exponent otherwise.
+	Exponent int
+
+	// Exact reports an error for numbers that cannot be written with the
+	// given number of decimals without loss of precision.
+	Exact bool
+}
+
+func (c *Config) internal() *encoding.Config {
+	if c == nil {
+		return &encoding.Config{}
+	}
+	return &encoding.Config{
+		Mode:       c.Mode.internal(),
+		Stdin:      c.Stdin,
+		Stdout:     c.Stdout,
+		PkgName:    c.PkgName,
+		Force:      c.Force,
+		Stream:     c.Stream,
+		Strict:     c.Strict,
+		Annotate:   c.Annotate,
+		AllErrors:  c.AllErrors,
+		Schema:     c.Schema,
+		EscapeHTML: c.EscapeHTML,
+		FieldOrder: encoding.FieldOrder(c.FieldOrder),
+		ProtoPath:  c.ProtoPath,
+
+		ExpandReferences: c.ExpandReferences,
+		MaxCycleDepth:    c.MaxCycleDepth,
+		Numbers:          encoding.NumberFormat(c.Numbers),
+		PreserveLiterals: c.PreserveLiterals,
+		Newline:          c.Newline,
+	}
+}
+
+// An Encoder writes CUE values to a file.
+type Encoder struct {
+	e *encoding.Encoder
+}
+
+// NewEncoder returns an Encoder that writes to the file described by f.
+// Files other than "-" are written when the Encoder is closed.
+func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
+	e, err := encoding.NewEncoder(f, cfg.internal())
+	if err != nil {
+		return nil, err
+	}
+	return &Encoder{e: e}, nil
+}
+
+// Encode writes v. It reports an error if v is not concrete and the file
+// requires concrete values.
+func (e *Encoder) Encode(v cue.Value) error {
+	return e.e.Encode(v)
+}
+
+// EncodeFile writes the given CUE syntax.
+func (e *Encoder) EncodeFile(f *ast.File) error {
+	return e.e.EncodeFile(f)
+}
+
+// Close flushes any pending output.
+func (e *Encoder) Close() error {
+	return e.e.Close()
+}
+
+// A Decoder reads a stream of CUE files from a file in any of the supported
+// formats.
+//
+// A Decoder is used as follows:
+//
+//	for d := codec.NewDecoder(f, cfg); !d.Done(); d.Next() {
+//		f := d.File()
+//		...
+//	}
+//	if err := d.Err(); err != nil {
+//		...
+//	}
+type Decoder struct {
+	d *encoding.Decoder
+}
+
+// NewDecoder returns a Decoder for the file described by f. If the
+// Source field of f is set, it is used instead of reading the file.
+func NewDecoder(f *build.File, cfg *Config) *Decoder {
+	return &Decoder{d: encoding.NewDecoder(f, cfg.internal())}
+}
+
+// Done reports whether the stream is exhausted or an error occurred.
+func (d *Decoder) Done() bool { return d.d.Done() }
+
+// Next advances to the next file in the stream.
+func (d *Decoder) Next() { d.d.Next() }
+
+// File returns the current file.
+func (d *Decoder) File() *ast.File { return d.d.File() }
+
+// Err reports the first error encountered, if any.
+func (d *Decoder) Err() error { return d.d.Err() }
+
+// Filename reports the name of the file of the current value.
+func (d *Decoder) Filename() string { return d.d.Filename() }
+
+// Index reports the position of the current file in the stream.
+func (d *Decoder) Index() int { return d.d.Index() }
+
+// Interpretation reports the interpretation of the input, which may have been
+// detected automatically, as for JSON Schema and OpenAPI.
+func (d *Decoder) Interpretation() build.Interpretation {
+	return d.d.Interpretation()
+}
+
+// Close closes the underlying reader.
+func (d *Decoder) Close() { d.d.Close() }
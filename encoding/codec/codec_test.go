@@ -0,0 +1,187 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/format"
+)
+
+func TestRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		data string
+		out  string
+		cfg  Config
+		want string
+	}{{
+		name: "yaml to json",
+		in:   "yaml:-",
+		data: "a: 1\nb: [x, y]\n",
+		out:  "json:-",
+		want: `{
+    "a": 1,
+    "b": [
+        "x",
+        "y"
+    ]
+}
+`,
+	}, {
+		name: "json stream to yaml",
+		in:   "jsonl:-",
+		data: `{"a": 1}` + "\n" + `{"a": 2}`,
+		out:  "yaml:-",
+		cfg:  Config{Stream: true},
+		want: "a: 1\n---\na: 2\n",
+	}, {
+		name: "escape html",
+		in:   "json:-",
+		data: `{"a": "<b>"}`,
+		out:  "json:-",
+		cfg:  Config{EscapeHTML: true},
+		want: "{\n    \"a\": \"\\u003cb\\u003e\"\n}\n",
+	}, {
+		name: "field order",
+		in:   "json:-",
+		data: `{"b": 1, "a": 2}`,
+		out:  "cue:-",
+		cfg:  Config{FieldOrder: LexicalOrder},
+		want: "a: 2\nb: 1\n",
+	}, {
+		name: "text",
+		in:   "json:-",
+		data: `"hello"`,
+		out:  "text:-",
+		want: "hello\n",
+	}, {
+		name: "preserve literals",
+		in:   "cue:-",
+		data: `a: #"x\y"#`,
+		out:  "cue:-",
+		cfg:  Config{PreserveLiterals: true},
+		want: "a: #\"x\\y\"#\n",
+	}, {
+		name: "crlf",
+		in:   "json:-",
+		data: `{"a": 1, "b": [1]}`,
+		out:  "yaml:-",
+		cfg:  Config{Newline: "\r\n"},
+		want: "a: 1\r\nb:\r\n  - 1\r\n",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			in, err := ParseFile(tc.in, Input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := ParseFile(tc.out, Export)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w := &bytes.Buffer{}
+			cfg := tc.cfg
+			cfg.Mode = Export
+			cfg.Stdin = strings.NewReader(tc.data)
+			cfg.Stdout = w
+
+			enc, err := NewEncoder(out, &cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ctx := cuecontext.New()
+			d := NewDecoder(in, &cfg)
+			defer d.Close()
+			for ; !d.Done(); d.Next() {
+				v := ctx.BuildFile(d.File())
+				if err := enc.Encode(v); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := d.Err(); err != nil {
+				t.Fatal(err)
+			}
+			if err := enc.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := w.String(); got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	testCases := []struct {
+		spec string
+		mode Mode
+		want string
+	}{
+		{"data.yaml", Input, "yaml"},
+		{"openapi+yaml:schema.txt", Def, "yaml"},
+		{"-", Export, "json"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			f, err := ParseFile(tc.spec, tc.mode)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(f.Encoding); got != tc.want {
+				t.Errorf("got encoding %q; want %q", got, tc.want)
+			}
+		})
+	}
+
+	if _, err := ParseFile("foo.unknown", Input); err == nil {
+		t.Error("expected error for unknown file type")
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	const schema = `{
+	"$schema": "http://json-schema.org/draft-07/schema#",
+	"type": "object",
+	"properties": {"a": {"type": "string", "title": "A"}}
+}`
+	f, err := ParseFile("jsonschema:-", Input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, annotate := range []bool{false, true} {
+		d := NewDecoder(f, &Config{
+			Stdin:    strings.NewReader(schema),
+			Annotate: annotate,
+		})
+		if err := d.Err(); err != nil {
+			t.Fatal(err)
+		}
+		b, err := format.Node(d.File())
+		d.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(string(b), `@jsonschema(order="type title",title="A")`); got != annotate {
+			t.Errorf("annotate %v: got:\n%s", annotate, b)
+		}
+	}
+}
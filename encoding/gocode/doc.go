@@ -15,6 +15,11 @@
 // Package gocode defines functions for extracting CUE definitions from Go code
 // and generating Go code from CUE values.
 //
+// Generate produces code for an entire instance. Expr and Constraint convert
+// individual values to Go expressions for use in custom code generators.
+// Validators compiles definitions to a standalone Go package that validates
+// data without depending on CUE.
+//
 // This package is used for offline processing. For converting Go values to and
 // from CUE at runtime, use the gocodec package.
 package gocode
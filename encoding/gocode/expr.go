@@ -0,0 +1,280 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gocode
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+)
+
+// Expr returns a Go expression for the value of v, with defaults applied.
+// It reports an error if v is not concrete.
+//
+// Scalars map to untyped Go literals and null maps to nil. Lists map to
+// slice literals and structs map to map literals with string keys. The
+// element type of these is the Go type of their elements if all elements
+// are scalars of the same kind and interface{} otherwise. Only regular
+// fields are included.
+func Expr(v cue.Value) (ast.Expr, error) {
+	x, _, err := goExpr(v)
+	return x, err
+}
+
+// goExpr returns the Go expression for v along with its Go type.
+func goExpr(v cue.Value) (x ast.Expr, typ string, err error) {
+	v, _ = v.Default()
+	if err := v.Err(); err != nil {
+		return nil, "", err
+	}
+	switch k := v.Kind(); k {
+	case cue.NullKind:
+		return ast.NewIdent("nil"), "interface{}", nil
+
+	case cue.BoolKind:
+		b, _ := v.Bool()
+		return ast.NewIdent(strconv.FormatBool(b)), "bool", nil
+
+	case cue.StringKind, cue.BytesKind, cue.IntKind, cue.FloatKind:
+		x, err := goLit(v)
+		return x, goTypes[k], err
+
+	case cue.ListKind:
+		iter, _ := v.List()
+		var elts []ast.Expr
+		var types []string
+		for iter.Next() {
+			x, typ, err := goExpr(iter.Value())
+			if err != nil {
+				return nil, "", err
+			}
+			elts = append(elts, x)
+			types = append(types, typ)
+		}
+		typ := "[]" + elemType(types)
+		return &ast.CompositeLit{Type: ast.NewIdent(typ), Elts: elts}, typ, nil
+
+	case cue.StructKind:
+		iter, _ := v.Fields()
+		var elts []ast.Expr
+		var types []string
+		for iter.Next() {
+			x, typ, err := goExpr(iter.Value())
+			if err != nil {
+				return nil, "", err
+			}
+			elts = append(elts, &ast.KeyValueExpr{
+				Key:   goString(iter.Selector().String()),
+				Value: x,
+			})
+			types = append(types, typ)
+		}
+		typ := "map[string]" + elemType(types)
+		return &ast.CompositeLit{Type: ast.NewIdent(typ), Elts: elts}, typ, nil
+	}
+	return nil, "", errors.Newf(v.Pos(), "gocode: value %v is not concrete", v)
+}
+
+var goTypes = map[cue.Kind]string{
+	cue.StringKind: "string",
+	cue.BytesKind:  "[]byte",
+	cue.IntKind:    "int",
+	cue.FloatKind:  "float64",
+}
+
+// elemType reports the common scalar type of the given types or interface{}
+// if there is none.
+func elemType(types []string) string {
+	if len(types) == 0 {
+		return "interface{}"
+	}
+	for _, t := range types[1:] {
+		if t != types[0] {
+			return "interface{}"
+		}
+	}
+	switch types[0] {
+	case "bool", "string", "[]byte", "int", "float64":
+		return types[0]
+	}
+	return "interface{}"
+}
+
+// goLit returns a Go literal for the concrete scalar v.
+func goLit(v cue.Value) (ast.Expr, error) {
+	switch v.Kind() {
+	case cue.StringKind:
+		s, _ := v.String()
+		return goString(s), nil
+
+	case cue.BytesKind:
+		b, _ := v.Bytes()
+		return &ast.CallExpr{
+			Fun:  ast.NewIdent("[]byte"),
+			Args: []ast.Expr{goString(string(b))},
+		}, nil
+
+	case cue.IntKind:
+		i, err := v.Int(nil)
+		if err != nil {
+			return nil, err
+		}
+		return &ast.BasicLit{Kind: token.INT, Value: i.String()}, nil
+
+	case cue.FloatKind:
+		f, err := v.Float64()
+		if err != nil {
+			return nil, err
+		}
+		s := strconv.FormatFloat(f, 'g', -1, 64)
+		return &ast.BasicLit{Kind: token.FLOAT, Value: s}, nil
+
+	case cue.BoolKind:
+		b, _ := v.Bool()
+		return ast.NewIdent(strconv.FormatBool(b)), nil
+
+	case cue.NullKind:
+		return ast.NewIdent("nil"), nil
+	}
+	return nil, errors.Newf(v.Pos(), "gocode: value %v is not a concrete scalar", v)
+}
+
+func goString(s string) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(s)}
+}
+
+// Constraint returns a Go boolean expression that reports whether the Go
+// value x satisfies the constraints of v. The type of x is assumed to
+// correspond to the type of v, so that type constraints, such as int or
+// string, are satisfied by definition.
+//
+// Supported constraints are bounds, regular expressions, concrete scalar
+// values, and conjunctions and disjunctions of these. Regular expressions
+// use the regexp package, which the generated code must import. An error is
+// reported for any other constraint.
+func Constraint(v cue.Value, x ast.Expr) (ast.Expr, error) {
+	return constraint(v, x)
+}
+
+func constraint(v cue.Value, x ast.Expr) (ast.Expr, error) {
+	op, args := v.Expr()
+	switch op {
+	case cue.AndOp, cue.OrOp:
+		tok := token.LAND
+		if op == cue.OrOp {
+			tok = token.LOR
+		}
+		var expr ast.Expr
+		for _, a := range args {
+			e, err := constraint(a, x)
+			if err != nil {
+				return nil, err
+			}
+			if isTrue(e) {
+				if op == cue.OrOp {
+					return e, nil
+				}
+				continue
+			}
+			if b, ok := e.(*ast.BinaryExpr); ok && b.Op != tok &&
+				(b.Op == token.LAND || b.Op == token.LOR) {
+				e = &ast.ParenExpr{X: e}
+			}
+			if expr == nil {
+				expr = e
+			} else {
+				expr = &ast.BinaryExpr{X: expr, Op: tok, Y: e}
+			}
+		}
+		if expr == nil {
+			return ast.NewIdent("true"), nil
+		}
+		return expr, nil
+
+	case cue.LessThanOp, cue.LessThanEqualOp,
+		cue.GreaterThanOp, cue.GreaterThanEqualOp, cue.NotEqualOp:
+		y, err := goLit(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return &ast.BinaryExpr{X: x, Op: compareOps[op], Y: y}, nil
+
+	case cue.RegexMatchOp, cue.NotRegexMatchOp:
+		re, err := args[0].String()
+		if err != nil {
+			return nil, err
+		}
+		var e ast.Expr = &ast.CallExpr{
+			Fun: &ast.SelectorExpr{
+				X: &ast.CallExpr{
+					Fun: &ast.SelectorExpr{
+						X:   ast.NewIdent("regexp"),
+						Sel: ast.NewIdent("MustCompile"),
+					},
+					Args: []ast.Expr{goString(re)},
+				},
+				Sel: ast.NewIdent("MatchString"),
+			},
+			Args: []ast.Expr{x},
+		}
+		if op == cue.NotRegexMatchOp {
+			e = &ast.UnaryExpr{Op: token.NOT, X: e}
+		}
+		return e, nil
+
+	case cue.NoOp:
+		if v.IsConcrete() {
+			switch v.Kind() {
+			case cue.ListKind, cue.StructKind:
+			default:
+				y, err := goLit(v)
+				if err != nil {
+					return nil, err
+				}
+				return &ast.BinaryExpr{X: x, Op: token.EQL, Y: y}, nil
+			}
+		}
+		if isType(v) {
+			return ast.NewIdent("true"), nil
+		}
+	}
+	return nil, errors.Newf(v.Pos(), "gocode: unsupported constraint %v", v)
+}
+
+var compareOps = map[cue.Op]token.Token{
+	cue.LessThanOp:         token.LSS,
+	cue.LessThanEqualOp:    token.LEQ,
+	cue.GreaterThanOp:      token.GTR,
+	cue.GreaterThanEqualOp: token.GEQ,
+	cue.NotEqualOp:         token.NEQ,
+}
+
+// isType reports whether v is a basic type, such as int or string, or top.
+func isType(v cue.Value) bool {
+	switch fmt.Sprint(v) {
+	case "_", "bool", "string", "bytes", "int", "float", "number":
+		return true
+	}
+	return false
+}
+
+func isTrue(e ast.Expr) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == "true"
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gocode
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestExpr(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want string
+	}{{
+		in:   `"foo"`,
+		want: `"foo"`,
+	}, {
+		in:   `*3 | int`,
+		want: `3`,
+	}, {
+		in:   `1.5`,
+		want: `1.5`,
+	}, {
+		in:   `null`,
+		want: `nil`,
+	}, {
+		in:   `'ab'`,
+		want: `[]byte("ab")`,
+	}, {
+		in:   `[1, 2]`,
+		want: `[]int{1, 2}`,
+	}, {
+		in:   `{a: "x", b: [true, "y"], c?: int}`,
+		want: `map[string]interface{}{"a": "x", "b": []interface{}{true, "y"}}`,
+	}, {
+		in:   `{a: int}`,
+		want: `error: gocode: value int is not concrete`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			v := cuecontext.New().CompileString(tc.in)
+			x, err := Expr(v)
+			if got := exprString(t, x, err); got != tc.want {
+				t.Errorf("got %s; want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConstraint(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want string
+	}{{
+		in:   `int & >0 & <=10`,
+		want: `x > 0 && x <= 10`,
+	}, {
+		in:   `string & =~"^[a-z]+$" & !="admin"`,
+		want: `regexp.MustCompile("^[a-z]+$").MatchString(x) && x != "admin"`,
+	}, {
+		in:   `"a" | "b"`,
+		want: `x == "a" || x == "b"`,
+	}, {
+		in:   `<0 | >100 & <200`,
+		want: `x < 0 || (x > 100 && x < 200)`,
+	}, {
+		in:   `!~"^x"`,
+		want: `!regexp.MustCompile("^x").MatchString(x)`,
+	}, {
+		in:   `string`,
+		want: `true`,
+	}, {
+		in:   `[...int]`,
+		want: `error: gocode: unsupported constraint [...int]`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			v := cuecontext.New().CompileString(tc.in)
+			x, err := Constraint(v, ast.NewIdent("x"))
+			if got := exprString(t, x, err); got != tc.want {
+				t.Errorf("got %s; want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func exprString(t *testing.T, x ast.Expr, err error) string {
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	var buf bytes.Buffer
+	if err := format.Node(&buf, token.NewFileSet(), x); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
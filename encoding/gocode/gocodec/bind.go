@@ -0,0 +1,150 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gocodec
+
+import (
+	"reflect"
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+)
+
+// A Binding ties a Go value to a set of CUE constraints. It keeps the Go value
+// valid with respect to the constraints and tracks which fields were set as a
+// result of completion.
+//
+// A Binding is typically used to keep a configuration struct in sync with a
+// CUE schema that may change over time, such as a configuration that is
+// reloaded while a server is running.
+type Binding struct {
+	codec *Codec
+
+	mutex sync.Mutex
+	v     cue.Value   // constraints
+	x     interface{} // pointer to bound Go value
+
+	// last is the CUE representation of x after the last successful Sync.
+	last  cue.Value
+	dirty bool // constraints changed since last Sync
+}
+
+// Bind binds the Go value x to the constraints v, completes x, and returns the
+// resulting Binding. x must be a non-nil pointer.
+//
+// The given value must be created using the same Runtime with which c was
+// initialized.
+func (c *Codec) Bind(v cue.Value, x interface{}) (*Binding, error) {
+	if rv := reflect.ValueOf(x); rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, errors.Newf(v.Pos(), "gocodec: Bind requires a non-nil pointer, found %T", x)
+	}
+	b := &Binding{codec: c, v: v, x: x, dirty: true}
+	if _, err := b.Sync(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Value reports the constraints to which the Go value is currently bound.
+func (b *Binding) Value() cue.Value {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.v
+}
+
+// SetValue replaces the constraints of b with v and synchronizes the Go value
+// with the new constraints. It reports the paths of the fields that were
+// modified as a result. The constraints are not replaced if the Go value does
+// not satisfy v.
+func (b *Binding) SetValue(v cue.Value) (changed []cue.Path, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	old, dirty := b.v, b.dirty
+	b.v = v
+	b.dirty = true
+	changed, err = b.sync()
+	if err != nil {
+		b.v, b.dirty = old, dirty
+	}
+	return changed, err
+}
+
+// Sync validates the bound Go value against its constraints and sets any
+// undefined values that can be uniquely determined from these constraints. It
+// reports the paths of the fields that were set by completion.
+//
+// Validation is skipped if neither the Go value nor the constraints have
+// changed since the last successful call to Sync. The Go value is not modified
+// if validation fails.
+//
+// Like Complete, Sync does a JSON round trip.
+func (b *Binding) Sync() (changed []cue.Path, err error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return b.sync()
+}
+
+func (b *Binding) sync() (changed []cue.Path, err error) {
+	c := b.codec
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	r := checkAndForkContext(c.runtime, b.v)
+	before, err := fromGoValue(r, b.x, true)
+	if err != nil {
+		return nil, err
+	}
+	if !b.dirty && b.last.Exists() && before.Equals(b.last) {
+		return nil, nil
+	}
+
+	w := before.Unify(b.v)
+	if err := w.Validate(cue.Concrete(true)); err != nil {
+		return nil, err
+	}
+	if err := w.Decode(b.x); err != nil {
+		return nil, err
+	}
+
+	after, err := fromGoValue(r, b.x, true)
+	if err != nil {
+		return nil, err
+	}
+	b.last = after
+	b.dirty = false
+
+	return diffPaths(before, after), nil
+}
+
+// diffPaths reports the paths of the leaf values in after that are either
+// not present in before or that have a different value.
+func diffPaths(before, after cue.Value) (changed []cue.Path) {
+	after.Walk(func(v cue.Value) bool {
+		switch v.Kind() {
+		case cue.StructKind, cue.ListKind:
+			return true
+		}
+		p := v.Path()
+		w := before.LookupPath(p)
+		if !w.Exists() || !w.Equals(v) {
+			changed = append(changed, p)
+		}
+		return false
+	}, nil)
+	return changed
+}
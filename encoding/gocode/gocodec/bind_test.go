@@ -0,0 +1,102 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gocodec
+
+import (
+	"fmt"
+	"testing"
+
+	"cuelang.org/go/cue"
+)
+
+func TestBind(t *testing.T) {
+	type Config struct {
+		Host  string `json:"host,omitempty"`
+		Port  int    `json:"port,omitempty"`
+		Debug bool   `json:"debug,omitempty"`
+	}
+
+	r := &cue.Runtime{}
+	codec := New(r, nil)
+
+	compile := func(s string) cue.Value {
+		t.Helper()
+		inst, err := r.Compile("test", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return inst.Value()
+	}
+
+	cfg := &Config{Host: "localhost"}
+	b, err := codec.Bind(compile(`host: string, port: int | *8080`), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 8080 {
+		t.Errorf("port: got %d; want 8080", cfg.Port)
+	}
+
+	// No changes: nothing to report.
+	changed, err := b.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 0 {
+		t.Errorf("got %v; want no changes", changed)
+	}
+
+	// Reload with new constraints.
+	changed, err = b.SetValue(compile(`host: string, port: int | *8080, debug: bool | *true`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(changed); got != "[debug]" {
+		t.Errorf("changed: got %v; want [debug]", got)
+	}
+	if !cfg.Debug {
+		t.Error("debug: got false; want true")
+	}
+
+	// Invalid constraints are rejected and leave the binding intact.
+	old := b.Value()
+	_, err = b.SetValue(compile(`host: "example.com"`))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !b.Value().Equals(old) {
+		t.Error("constraints were replaced after failed update")
+	}
+	if b.dirty {
+		t.Error("binding marked as changed after failed update")
+	}
+	if cfg.Host != "localhost" {
+		t.Errorf("host: got %q; want %q", cfg.Host, "localhost")
+	}
+
+	// Changes to the Go value are validated on the next Sync.
+	cfg.Port = 0
+	changed, err = b.Sync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(changed); got != "[port]" {
+		t.Errorf("changed: got %v; want [port]", got)
+	}
+
+	if _, err := codec.Bind(compile(`port: int`), Config{}); err == nil {
+		t.Error("expected error binding non-pointer value")
+	}
+}
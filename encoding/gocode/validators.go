@@ -0,0 +1,541 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gocode
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/printer"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/tools/go/ast/astutil"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/value"
+)
+
+// Validators returns the source of a Go package named pkgName that
+// validates data against the top-level definitions of v. The generated
+// code does not depend on CUE. For each definition #X it defines a
+// function
+//
+//	func ValidateX(x interface{}) error
+//
+// that reports an error if x, a value as decoded by encoding/json into an
+// interface{}, is not an instance of #X. This allows validation in hot paths
+// for which evaluating CUE is too slow.
+//
+// Validators supports definitions composed of structs, including pattern
+// constraints and ..., lists, disjunctions, references to definitions of v,
+// basic types, and the constraints supported by Constraint. Fields that
+// have a default or are fully concrete are not required to be present in
+// the data. An error is reported for any other constraint, such as calls to
+// builtins, as the generated code would otherwise accept invalid data.
+func Validators(pkgName string, v cue.Value) ([]byte, error) {
+	if err := v.Validate(); err != nil {
+		return nil, err
+	}
+	_, root := value.ToInternal(v)
+	g := &validatorGen{
+		root:    v,
+		rootV:   root,
+		funcs:   map[string]string{},
+		names:   map[string]bool{},
+		regexps: map[string]string{},
+		imports: map[string]bool{"fmt": true},
+	}
+
+	iter, err := v.Fields(cue.Definitions(true))
+	if err != nil {
+		return nil, err
+	}
+	var exported bytes.Buffer
+	for iter.Next() {
+		sel := iter.Selector()
+		if !sel.IsDefinition() {
+			continue
+		}
+		p := cue.MakePath(sel)
+		name := g.def(p, iter.Value())
+		export := g.uniqueName("Validate" + goName(sel.String()))
+		fmt.Fprintf(&exported, "// %s reports an error if x does not satisfy %s.\n", export, sel)
+		fmt.Fprintf(&exported, "func %s(x interface{}) error {\n", export)
+		fmt.Fprintf(&exported, "return %s(x, \"\")\n}\n\n", name)
+	}
+	if g.errs != nil {
+		return nil, g.errs
+	}
+
+	var b bytes.Buffer
+	b.WriteString("// Code generated by gocode.Validators; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkgName)
+	b.WriteString("import (\n")
+	var imports []string
+	for p := range g.imports {
+		imports = append(imports, p)
+	}
+	sort.Strings(imports)
+	for _, p := range imports {
+		fmt.Fprintf(&b, "%q\n", p)
+	}
+	b.WriteString(")\n\n")
+	if len(g.regexps) > 0 {
+		var vars []string
+		for re, name := range g.regexps {
+			vars = append(vars, fmt.Sprintf("%s = regexp.MustCompile(%s)\n", name, re))
+		}
+		sort.Strings(vars)
+		b.WriteString("var (\n")
+		for _, v := range vars {
+			b.WriteString(v)
+		}
+		b.WriteString(")\n\n")
+	}
+	b.Write(exported.Bytes())
+	b.Write(g.buf.Bytes())
+	b.WriteString(validatorHelpers)
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, v.Pos(), "gocode: generated invalid code")
+	}
+	return src, nil
+}
+
+const validatorHelpers = `
+func joinPath(path, sel string) string {
+	if path == "" {
+		return sel
+	}
+	return path + "." + sel
+}
+
+func errorf(path, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if path == "" {
+		return fmt.Errorf("%s", msg)
+	}
+	return fmt.Errorf("%s: %s", path, msg)
+}
+
+func typeError(path, want string, x interface{}) error {
+	return errorf(path, "conflicting values %v and %s (mismatched types %s and %s)",
+		x, want, kindOf(x), want)
+}
+
+func kindOf(x interface{}) string {
+	switch x.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "struct"
+	}
+	return fmt.Sprintf("%T", x)
+}
+`
+
+type validatorGen struct {
+	root  cue.Value
+	rootV *adt.Vertex
+
+	// funcs maps the paths of definitions to the names of their functions.
+	funcs map[string]string
+	names map[string]bool
+
+	// regexps maps the quoted regular expressions to the names of the
+	// variables holding their compiled form.
+	regexps map[string]string
+	imports map[string]bool
+
+	buf  bytes.Buffer
+	errs errors.Error
+}
+
+func (g *validatorGen) addErr(v cue.Value, format string, args ...interface{}) {
+	g.errs = errors.Append(g.errs, errors.Newf(v.Pos(), format, args...))
+}
+
+func (g *validatorGen) uniqueName(name string) string {
+	s := name
+	for i := 1; g.names[s]; i++ {
+		s = fmt.Sprintf("%s%d", name, i)
+	}
+	g.names[s] = true
+	return s
+}
+
+// def returns the name of the function validating the definition at path
+// p, generating it if needed.
+func (g *validatorGen) def(p cue.Path, v cue.Value) string {
+	key := p.String()
+	if name, ok := g.funcs[key]; ok {
+		return name
+	}
+	var parts []string
+	for _, sel := range p.Selectors() {
+		parts = append(parts, goName(sel.String()))
+	}
+	name := g.uniqueName("validate" + strings.Join(parts, "_"))
+	g.funcs[key] = name
+	g.function(name, v)
+	return name
+}
+
+// value returns the name of a function validating v. A function named
+// after name is generated unless v refers to a definition.
+func (g *validatorGen) value(v cue.Value, name string) string {
+	if fn, ok := g.ref(v); ok {
+		return fn
+	}
+	name = g.uniqueName(name)
+	g.function(name, v)
+	return name
+}
+
+// ref returns the function for the definition of the root to which v
+// refers, if any.
+func (g *validatorGen) ref(v cue.Value) (string, bool) {
+	r, p := v.ReferencePath()
+	if !r.Exists() || len(p.Selectors()) == 0 {
+		return "", false
+	}
+	if _, rv := value.ToInternal(r); rv != g.rootV {
+		return "", false
+	}
+	for _, sel := range p.Selectors() {
+		if !sel.IsDefinition() {
+			return "", false
+		}
+	}
+	return g.def(p, g.root.LookupPath(p)), true
+}
+
+func (g *validatorGen) function(name string, v cue.Value) {
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "func %s(x interface{}, path string) error {\n", name)
+	if !g.body(b, v, name) {
+		b.WriteString("return nil\n")
+	}
+	b.WriteString("}\n\n")
+	g.buf.Write(b.Bytes())
+}
+
+// body writes the statements validating x against v. It reports whether
+// the statements end with a return statement.
+func (g *validatorGen) body(b *bytes.Buffer, v cue.Value, name string) bool {
+	if fn, ok := g.ref(v); ok {
+		fmt.Fprintf(b, "return %s(x, path)\n", fn)
+		return true
+	}
+	op, args := v.Expr()
+	if op == cue.OrOp {
+		if members, err := v.EnumValues(); err == nil {
+			return g.enum(b, v, members)
+		}
+	}
+	if op == cue.AndOp {
+		for _, a := range args {
+			if op, _ := a.Expr(); op == cue.CallOp {
+				g.addErr(v, "gocode: unsupported validator %v", a)
+				return false
+			}
+		}
+	}
+
+	switch k := v.IncompleteKind(); {
+	case k == cue.TopKind:
+		return false
+
+	case k == cue.NullKind:
+		b.WriteString("if x != nil {\nreturn typeError(path, \"null\", x)\n}\n")
+
+	case k == cue.BoolKind:
+		g.scalar(b, v, "bool", "bool")
+
+	case k == cue.StringKind:
+		g.scalar(b, v, "string", "string")
+
+	case k == cue.IntKind, k == cue.FloatKind, k == cue.NumberKind:
+		g.number(b, v, k)
+
+	case op == cue.OrOp:
+		var fns []string
+		for i, a := range args {
+			fns = append(fns, g.value(a, fmt.Sprintf("%s_%d", name, i)))
+		}
+		for _, fn := range fns {
+			fmt.Fprintf(b, "if %s(x, path) == nil {\nreturn nil\n}\n", fn)
+		}
+		fmt.Fprintf(b, "return errorf(path, %q, x)\n",
+			"%v does not match any of "+strconv.Itoa(len(fns))+" disjuncts")
+		return true
+
+	case k == cue.StructKind:
+		g.structBody(b, v, name)
+
+	case k == cue.ListKind:
+		g.listBody(b, v, name)
+
+	default:
+		g.addErr(v, "gocode: unsupported value %v of kind %v", v, k)
+	}
+	return false
+}
+
+// enum writes a switch statement validating x against the members of an
+// enum. It reports whether the statements end with a return statement.
+func (g *validatorGen) enum(b *bytes.Buffer, v cue.Value, members []cue.Value) bool {
+	var cases []string
+	seen := map[string]bool{}
+	for _, m := range members {
+		var c string
+		switch m.Kind() {
+		case cue.NullKind:
+			c = "nil"
+		case cue.BoolKind:
+			x, _ := m.Bool()
+			c = strconv.FormatBool(x)
+		case cue.StringKind:
+			x, _ := m.String()
+			c = strconv.Quote(x)
+		case cue.IntKind, cue.FloatKind:
+			x, _ := m.Float64()
+			c = fmt.Sprintf("float64(%s)", strconv.FormatFloat(x, 'g', -1, 64))
+		default:
+			g.addErr(v, "gocode: unsupported enum member %v", m)
+			return false
+		}
+		if !seen[c] {
+			seen[c] = true
+			cases = append(cases, c)
+		}
+	}
+	fmt.Fprintf(b, "switch x {\ncase %s:\nreturn nil\n}\n", strings.Join(cases, ", "))
+	fmt.Fprintf(b, "return errorf(path, \"invalid value %%v (does not satisfy %%s)\", x, %q)\n",
+		fmt.Sprint(v))
+	return true
+}
+
+func (g *validatorGen) scalar(b *bytes.Buffer, v cue.Value, kind, goType string) {
+	cond := g.constraint(v, "y")
+	if cond == "" {
+		fmt.Fprintf(b, "if _, ok := x.(%s); !ok {\n", goType)
+		fmt.Fprintf(b, "return typeError(path, %q, x)\n}\n", kind)
+		return
+	}
+	fmt.Fprintf(b, "y, ok := x.(%s)\n", goType)
+	fmt.Fprintf(b, "if !ok {\nreturn typeError(path, %q, x)\n}\n", kind)
+	g.check(b, v, cond)
+}
+
+func (g *validatorGen) number(b *bytes.Buffer, v cue.Value, k cue.Kind) {
+	cond := g.constraint(v, "y")
+	if cond == "" && k != cue.IntKind {
+		b.WriteString("if _, ok := x.(float64); !ok {\nreturn typeError(path, \"number\", x)\n}\n")
+		return
+	}
+	b.WriteString("y, ok := x.(float64)\n")
+	b.WriteString("if !ok {\nreturn typeError(path, \"number\", x)\n}\n")
+	if k == cue.IntKind {
+		g.imports["math"] = true
+		b.WriteString("if y != math.Trunc(y) {\nreturn typeError(path, \"int\", x)\n}\n")
+	}
+	if cond != "" {
+		g.check(b, v, cond)
+	}
+}
+
+func (g *validatorGen) check(b *bytes.Buffer, v cue.Value, cond string) {
+	fmt.Fprintf(b, "if !(%s) {\n", cond)
+	fmt.Fprintf(b, "return errorf(path, \"invalid value %%v (does not satisfy %%s)\", x, %q)\n}\n",
+		fmt.Sprint(v))
+}
+
+// constraint returns the Go condition for the constraints of v on the
+// variable x or the empty string if v imposes no constraints beyond its
+// type.
+func (g *validatorGen) constraint(v cue.Value, x string) string {
+	// Defaults do not affect validation.
+	if op, args := v.Expr(); op == cue.NoOp && len(args) == 1 {
+		v = args[0]
+	}
+	e, err := Constraint(v, ast.NewIdent(x))
+	if err != nil {
+		g.errs = errors.Append(g.errs, errors.Promote(err, "gocode"))
+		return ""
+	}
+	if isTrue(e) {
+		return ""
+	}
+	// Compile regular expressions only once.
+	e = astutil.Apply(e, func(c *astutil.Cursor) bool {
+		call, ok := c.Node().(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "MustCompile" {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok {
+			return true
+		}
+		name, ok := g.regexps[lit.Value]
+		if !ok {
+			name = fmt.Sprintf("re%d", len(g.regexps))
+			g.regexps[lit.Value] = name
+			g.imports["regexp"] = true
+		}
+		c.Replace(ast.NewIdent(name))
+		return false
+	}, nil).(ast.Expr)
+
+	var buf bytes.Buffer
+	_ = printer.Fprint(&buf, token.NewFileSet(), e)
+	return buf.String()
+}
+
+func (g *validatorGen) structBody(b *bytes.Buffer, v cue.Value, name string) {
+	b.WriteString("m, ok := x.(map[string]interface{})\n")
+	b.WriteString("if !ok {\nreturn typeError(path, \"struct\", x)\n}\n")
+
+	iter, _ := v.Fields(cue.Optional(true))
+	var labels []string
+	for iter.Next() {
+		label := iter.Label()
+		w := iter.Value()
+		required := !iter.IsOptional() && w.Validate(cue.Concrete(true)) != nil
+		fn := g.value(w, name+"_"+goName(label))
+		labels = append(labels, strconv.Quote(label))
+
+		fmt.Fprintf(b, "if v, ok := m[%q]; ok {\n", label)
+		fmt.Fprintf(b, "if err := %s(v, joinPath(path, %q)); err != nil {\nreturn err\n}\n", fn, label)
+		if required {
+			fmt.Fprintf(b, "} else {\nreturn errorf(path, \"missing field %%q\", %q)\n", label)
+		}
+		b.WriteString("}\n")
+	}
+
+	patterns, _ := v.PatternConstraints()
+	additional, hasAdditional := v.AdditionalConstraint()
+	open := v.Allows(cue.AnyString)
+	if hasAdditional {
+		open = additional.IncompleteKind() == cue.TopKind
+	}
+	if len(patterns) == 0 && open {
+		return
+	}
+
+	if len(patterns) == 0 && !hasAdditional {
+		b.WriteString("for k := range m {\n")
+		if len(labels) > 0 {
+			fmt.Fprintf(b, "switch k {\ncase %s:\ncontinue\n}\n", strings.Join(labels, ", "))
+		}
+		b.WriteString("return errorf(path, \"field not allowed: %s\", k)\n}\n")
+		return
+	}
+
+	b.WriteString("for k, v := range m {\n")
+	if len(labels) > 0 {
+		fmt.Fprintf(b, "switch k {\ncase %s:\ncontinue\n}\n", strings.Join(labels, ", "))
+	}
+	b.WriteString("matched := false\n")
+	for i, p := range patterns {
+		cond := g.constraint(p.Pattern, "k")
+		if cond == "" {
+			cond = "true"
+		}
+		fn := g.value(p.Value, fmt.Sprintf("%s_pattern%d", name, i))
+		fmt.Fprintf(b, "if %s {\nmatched = true\n", cond)
+		fmt.Fprintf(b, "if err := %s(v, joinPath(path, k)); err != nil {\nreturn err\n}\n}\n", fn)
+	}
+	switch {
+	case open:
+		b.WriteString("_ = matched\n")
+	case hasAdditional:
+		fn := g.value(additional, name+"_additional")
+		fmt.Fprintf(b, "if !matched {\nif err := %s(v, joinPath(path, k)); err != nil {\nreturn err\n}\n}\n", fn)
+	default:
+		b.WriteString("if !matched {\nreturn errorf(path, \"field not allowed: %s\", k)\n}\n")
+	}
+	b.WriteString("}\n")
+}
+
+func (g *validatorGen) listBody(b *bytes.Buffer, v cue.Value, name string) {
+	b.WriteString("l, ok := x.([]interface{})\n")
+	b.WriteString("if !ok {\nreturn typeError(path, \"list\", x)\n}\n")
+
+	var fns []string
+	iter, _ := v.List()
+	for i := 0; iter.Next(); i++ {
+		fns = append(fns, g.value(iter.Value(), fmt.Sprintf("%s_%d", name, i)))
+	}
+	elem := v.LookupPath(cue.MakePath(cue.AnyIndex))
+	n := len(fns)
+	switch {
+	case !elem.Exists():
+		fmt.Fprintf(b, "if len(l) != %d {\n", n)
+		fmt.Fprintf(b, "return errorf(path, \"incompatible list lengths (%d and %%d)\", len(l))\n}\n", n)
+	case n > 0:
+		fmt.Fprintf(b, "if len(l) < %d {\n", n)
+		fmt.Fprintf(b, "return errorf(path, \"incompatible list lengths (%d and %%d)\", len(l))\n}\n", n)
+	}
+	for i, fn := range fns {
+		fmt.Fprintf(b, "if err := %s(l[%d], joinPath(path, %q)); err != nil {\nreturn err\n}\n",
+			fn, i, strconv.Itoa(i))
+	}
+	if elem.Exists() && elem.IncompleteKind() != cue.TopKind {
+		fn := g.value(elem, name+"_elem")
+		fmt.Fprintf(b, "for i := %d; i < len(l); i++ {\n", n)
+		fmt.Fprintf(b, "if err := %s(l[i], joinPath(path, fmt.Sprint(i))); err != nil {\nreturn err\n}\n}\n", fn)
+	}
+}
+
+// goName converts a CUE label to an exported Go identifier.
+func goName(label string) string {
+	label = strings.TrimLeft(label, "#_")
+	var b strings.Builder
+	for i, r := range label {
+		switch {
+		case i == 0 && unicode.IsLetter(r):
+			b.WriteRune(unicode.ToUpper(r))
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	if b.Len() == 0 || !unicode.IsLetter([]rune(b.String())[0]) {
+		return "X" + b.String()
+	}
+	return b.String()
+}
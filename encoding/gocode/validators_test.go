@@ -0,0 +1,187 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gocode
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/encoding/json"
+)
+
+const validatorsSchema = `
+#Port: int & >0 & <65536
+
+#Service: {
+	name:     =~"^[a-z][a-z0-9-]*$" & !="admin"
+	port:     #Port
+	protocol: *"tcp" | "udp"
+	kind:     "Service"
+	weight?:  number & >=0
+	tags?: [...string]
+	labels?: {[=~"^x-"]: string}
+	next?: #Service | null
+}
+
+#Pair: [string, bool]
+
+#Level: 1 | 2 | 3 | null
+
+#Open: {
+	id: int
+	...
+}
+
+#Any: _
+`
+
+// TestValidators checks that the generated validators accept exactly the
+// data that CUE accepts.
+func TestValidators(t *testing.T) {
+	if testing.Short() {
+		t.Skip("requires building Go code")
+	}
+	testCases := []struct {
+		def  string
+		data string
+	}{
+		{"#Port", `80`},
+		{"#Port", `0`},
+		{"#Port", `1.5`},
+		{"#Port", `"80"`},
+		{"#Service", `{"name": "web", "port": 80}`},
+		{"#Service", `{"name": "web", "port": 80, "protocol": "udp", "kind": "Service"}`},
+		{"#Service", `{"name": "web", "port": 80, "protocol": "http"}`},
+		{"#Service", `{"name": "web", "port": 80, "protocol": 1}`},
+		{"#Service", `{"name": "web", "port": 80, "kind": "Pod"}`},
+		{"#Service", `{"name": "admin", "port": 80}`},
+		{"#Service", `{"name": "Web", "port": 80}`},
+		{"#Service", `{"port": 80}`},
+		{"#Service", `{"name": "web", "port": 80, "weight": 0.5, "tags": ["a", "b"]}`},
+		{"#Service", `{"name": "web", "port": 80, "weight": -1}`},
+		{"#Service", `{"name": "web", "port": 80, "tags": ["a", 1]}`},
+		{"#Service", `{"name": "web", "port": 80, "labels": {"x-a": "b"}}`},
+		{"#Service", `{"name": "web", "port": 80, "labels": {"y": "b"}}`},
+		{"#Service", `{"name": "web", "port": 80, "labels": {"x-a": 1}}`},
+		{"#Service", `{"name": "web", "port": 80, "other": 1}`},
+		{"#Service", `{"name": "web", "port": 80, "next": {"name": "db", "port": 5432}}`},
+		{"#Service", `{"name": "web", "port": 80, "next": {"name": "db"}}`},
+		{"#Service", `{"name": "web", "port": 80, "next": null}`},
+		{"#Service", `[]`},
+		{"#Pair", `["a", true]`},
+		{"#Pair", `["a", true, 1]`},
+		{"#Pair", `["a", "b"]`},
+		{"#Level", `2`},
+		{"#Level", `null`},
+		{"#Level", `4`},
+		{"#Level", `"1"`},
+		{"#Open", `{"id": 1, "foo": [1]}`},
+		{"#Open", `{"foo": 1}`},
+		{"#Any", `{"foo": [1, null]}`},
+	}
+
+	ctx := cuecontext.New()
+	v := ctx.CompileString(validatorsSchema)
+	src, err := Validators("main", v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "validators")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var main strings.Builder
+	main.WriteString("package main\n\nimport \"encoding/json\"\n\nfunc main() {\n")
+	for _, tc := range testCases {
+		fmt.Fprintf(&main, "{\nvar x interface{}\n_ = json.Unmarshal([]byte(%q), &x)\n", tc.data)
+		fmt.Fprintf(&main, "println(Validate%s(x) == nil)\n}\n", goName(tc.def))
+	}
+	main.WriteString("}\n")
+	files := map[string]string{
+		"go.mod":        "module example.com/validators\n",
+		"validators.go": string(src),
+		"main.go":       main.String(),
+	}
+	for name, content := range files {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s\n%s", err, out, src)
+	}
+	got := strings.Fields(string(out))
+
+	for i, tc := range testCases {
+		expr, err := json.Extract("data.json", []byte(tc.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data := ctx.BuildExpr(expr)
+		w := v.LookupPath(cue.ParsePath(tc.def)).Unify(data)
+		want := w.Validate(cue.Concrete(true)) == nil
+		if got[i] != fmt.Sprint(want) {
+			t.Errorf("%s %s: got valid=%s; want %v (%v)", tc.def, tc.data, got[i], want, w.Validate(cue.Concrete(true)))
+		}
+	}
+}
+
+func TestValidatorsUnsupported(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want string
+	}{{
+		in: `
+		import "strings"
+
+		#A: {a: strings.MinRunes(3)}
+		`,
+		want: `gocode: unsupported constraint strings.MinRunes(3)`,
+	}, {
+		in: `
+		import "list"
+
+		#A: [...int] & list.UniqueItems()
+		`,
+		want: `gocode: unsupported validator list.UniqueItems()`,
+	}, {
+		in:   `#A: {a: 'foo'}`,
+		want: `gocode: unsupported value 'foo' of kind bytes`,
+	}}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			v := cuecontext.New().CompileString(tc.in)
+			_, err := Validators("p", v)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("got %v; want %s", err, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package helm supports the use of CUE schemas with Helm charts.
+//
+// Helm validates the values passed to a chart against the JSON Schema in
+// the chart's values.schema.json file. ValuesSchema generates this file
+// from a CUE definition, by default #Values.
+//
+// The tool/helm package provides a task to compute and validate the values
+// of a chart within cue cmd.
+package helm
+
+import (
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/openapi"
+)
+
+// A Config defines options for generating a values schema.
+type Config struct {
+	// Root is the name of the definition, without the leading #, that
+	// describes the values of the chart. The default is "Values".
+	Root string
+
+	// ExpandReferences replaces references to other definitions with their
+	// schema. By default, other definitions are included in the definitions
+	// section of the values schema.
+	ExpandReferences bool
+
+	// MaxCycleDepth bounds the expansion of recursive references when
+	// ExpandReferences is set.
+	MaxCycleDepth int
+}
+
+const (
+	schemaDraft      = "http://json-schema.org/draft-07/schema#"
+	openAPIRefPrefix = "#/components/schemas/"
+	defRefPrefix     = "#/definitions/"
+)
+
+// ValuesSchema returns the contents of a values.schema.json file for the
+// root definition of inst. All other definitions of inst are included in
+// the definitions section of the schema, unless ExpandReferences is set.
+func ValuesSchema(inst *cue.Instance, c *Config) (*ast.File, error) {
+	if c == nil {
+		c = &Config{}
+	}
+	root := c.Root
+	if root == "" {
+		root = "Values"
+	}
+	schemas, err := (&openapi.Config{
+		ExpandReferences: c.ExpandReferences,
+		MaxCycleDepth:    c.MaxCycleDepth,
+	}).Schemas(inst)
+	if err != nil {
+		return nil, err
+	}
+
+	var rootSchema ast.Expr
+	defs := &ast.StructLit{}
+	for _, d := range (*ast.StructLit)(schemas).Elts {
+		f, ok := d.(*ast.Field)
+		if !ok {
+			continue
+		}
+		if name, _, _ := ast.LabelName(f.Label); name == root {
+			rootSchema = f.Value
+			continue
+		}
+		defs.Elts = append(defs.Elts, f)
+	}
+	s, ok := rootSchema.(*ast.StructLit)
+	if !ok {
+		return nil, errors.Newf(token.NoPos,
+			"helm: no definition #%s found", root)
+	}
+
+	rewriteRefs(s, root)
+	rewriteRefs(defs, root)
+
+	f := &ast.File{Decls: []ast.Decl{&ast.Field{
+		Label: ast.NewString("$schema"),
+		Value: ast.NewString(schemaDraft),
+	}}}
+	f.Decls = append(f.Decls, s.Elts...)
+	if len(defs.Elts) > 0 && !c.ExpandReferences {
+		f.Decls = append(f.Decls, &ast.Field{
+			Label: ast.NewString("definitions"),
+			Value: defs,
+		})
+	}
+	return f, nil
+}
+
+// rewriteRefs converts OpenAPI references in n to references within the
+// values schema.
+func rewriteRefs(n ast.Node, root string) {
+	ast.Walk(n, nil, func(n ast.Node) {
+		f, ok := n.(*ast.Field)
+		if !ok {
+			return
+		}
+		if name, _, _ := ast.LabelName(f.Label); name != "$ref" {
+			return
+		}
+		lit, ok := f.Value.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return
+		}
+		ref, err := strconv.Unquote(lit.Value)
+		if err != nil || !strings.HasPrefix(ref, openAPIRefPrefix) {
+			return
+		}
+		name := strings.TrimPrefix(ref, openAPIRefPrefix)
+		if name == root {
+			ref = "#"
+		} else {
+			ref = defRefPrefix + name
+		}
+		lit.Value = strconv.Quote(ref)
+	})
+}
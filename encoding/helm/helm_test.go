@@ -0,0 +1,82 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/internal/encoding/json"
+)
+
+func TestValuesSchema(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		cfg  *Config
+		out  string
+	}{{
+		name: "references",
+		in: `
+		#Values: {
+			replicas: int & >=1
+			image:    #Image
+			sidecars?: [...#Image]
+		}
+		#Image: {
+			repository: string
+			tag:        *"latest" | string
+		}`,
+		out: `{"$schema":"http://json-schema.org/draft-07/schema#","type":"object","required":["replicas","image"],"properties":{"replicas":{"type":"integer","minimum":1},"image":{"$ref":"#/definitions/Image"},"sidecars":{"type":"array","items":{"$ref":"#/definitions/Image"}}},"definitions":{"Image":{"type":"object","required":["repository","tag"],"properties":{"repository":{"type":"string"},"tag":{"type":"string","default":"latest"}}}}}`,
+	}, {
+		name: "expand",
+		in: `
+		#Chart: {
+			image: #Image
+		}
+		#Image: {
+			repository: string
+		}`,
+		cfg: &Config{Root: "Chart", ExpandReferences: true},
+		out: `{"$schema":"http://json-schema.org/draft-07/schema#","type":"object","required":["image"],"properties":{"image":{"type":"object","required":["repository"],"properties":{"repository":{"type":"string"}}}}}`,
+	}, {
+		name: "missing root",
+		in:   `#Foo: {a: int}`,
+		out:  `helm: no definition #Values found`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var r cue.Runtime
+			inst, err := r.Compile("test", tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := ValuesSchema(inst, tc.cfg)
+			var got string
+			if err != nil {
+				got = err.Error()
+			} else {
+				b, err := json.Encode(f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = string(b)
+			}
+			if got != tc.out {
+				t.Errorf("\ngot:  %s\nwant: %s", got, tc.out)
+			}
+		})
+	}
+}
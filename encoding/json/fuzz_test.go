@@ -0,0 +1,69 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package json
+
+import (
+	"bytes"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+)
+
+// maxFuzzSize bounds the size of fuzz inputs.
+const maxFuzzSize = 1 << 12
+
+// FuzzRoundTrip checks that exporting a concrete CUE value to JSON and
+// importing the result yields a value with the same JSON encoding.
+func FuzzRoundTrip(f *testing.F) {
+	for _, s := range []string{
+		`a: 1, b: "foo", c: [true, null, 1.5e10]`,
+		`a: b: c: " <>&"`,
+		`[{x: 1}, {y: -0.0}, "\t"]`,
+		`#D: {a: int | *3}, d: #D & {}`,
+		`"foo\(1+2)": [for x in [1, 2] {x * x}]`,
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		if len(s) > maxFuzzSize {
+			t.Skip()
+		}
+		ctx := cuecontext.New()
+		v := ctx.CompileString(s)
+		if v.Validate(cue.Concrete(true)) != nil {
+			t.Skip()
+		}
+		b, err := v.MarshalJSON()
+		if err != nil {
+			t.Skip()
+		}
+		expr, err := Extract("fuzz.json", b)
+		if err != nil {
+			t.Fatalf("cannot import exported JSON: %v\n%s", err, b)
+		}
+		w := ctx.BuildExpr(expr)
+		got, err := w.MarshalJSON()
+		if err != nil {
+			t.Fatalf("cannot export imported JSON: %v\n%s", err, b)
+		}
+		if !bytes.Equal(b, got) {
+			t.Errorf("round trip mismatch:\n%s\n---\n%s", b, got)
+		}
+	})
+}
@@ -16,9 +16,13 @@
 package json
 
 import (
+	"bytes"
 	gojson "encoding/json"
+	"fmt"
 	"io"
 	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
@@ -42,6 +46,105 @@ func Validate(b []byte, v cue.Value) error {
 	return err
 }
 
+// A Config defines options for encoding JSON. The zero value writes compact
+// JSON without escaping HTML characters.
+type Config struct {
+	// Indent is the indentation used for each level of nesting. Values are
+	// written in compact form if Indent is empty.
+	Indent string
+
+	// EscapeHTML escapes the characters <, >, and & in strings, as is done
+	// by the json.Marshal function of the Go standard library, so that the
+	// output can be safely embedded in HTML.
+	EscapeHTML bool
+
+	// ASCII escapes all non-ASCII characters in strings with \u escape
+	// sequences.
+	ASCII bool
+
+	// SortKeys writes the fields of objects in lexical order, rather than
+	// in the order in which they are defined.
+	SortKeys bool
+
+	// TrailingNewline terminates the output with a newline.
+	TrailingNewline bool
+}
+
+// Marshal returns the JSON encoding of the concrete value v.
+func (c *Config) Marshal(v cue.Value) ([]byte, error) {
+	b, err := v.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return c.Format(b)
+}
+
+// Format rewrites the valid JSON data b according to the options of c.
+func (c *Config) Format(b []byte) ([]byte, error) {
+	if c.SortKeys {
+		// Maps are encoded with sorted keys. Numbers are kept as they are.
+		d := gojson.NewDecoder(bytes.NewReader(b))
+		d.UseNumber()
+		var x interface{}
+		if err := d.Decode(&x); err != nil {
+			return nil, err
+		}
+		buf := &bytes.Buffer{}
+		e := gojson.NewEncoder(buf)
+		e.SetEscapeHTML(false)
+		if err := e.Encode(x); err != nil {
+			return nil, err
+		}
+		b = buf.Bytes()
+	}
+
+	buf := &bytes.Buffer{}
+	var err error
+	if c.Indent != "" {
+		err = gojson.Indent(buf, b, "", c.Indent)
+	} else {
+		err = gojson.Compact(buf, b)
+	}
+	if err != nil {
+		return nil, err
+	}
+	b = buf.Bytes()
+
+	if c.EscapeHTML {
+		buf := &bytes.Buffer{}
+		gojson.HTMLEscape(buf, b)
+		b = buf.Bytes()
+	}
+	if c.ASCII {
+		b = escapeNonASCII(b)
+	}
+	if c.TrailingNewline {
+		b = append(b, '\n')
+	}
+	return b, nil
+}
+
+// escapeNonASCII escapes the non-ASCII characters of the JSON data b, which
+// can only occur within strings.
+func escapeNonASCII(b []byte) []byte {
+	var out []byte
+	for i := 0; i < len(b); {
+		r, size := utf8.DecodeRune(b[i:])
+		if r < utf8.RuneSelf {
+			out = append(out, b[i])
+			i++
+			continue
+		}
+		if r1, r2 := utf16.EncodeRune(r); r1 != utf8.RuneError {
+			out = append(out, fmt.Sprintf(`\u%04x\u%04x`, r1, r2)...)
+		} else {
+			out = append(out, fmt.Sprintf(`\u%04x`, r)...)
+		}
+		i += size
+	}
+	return out
+}
+
 // Extract parses JSON-encoded data to a CUE expression, using path for
 // position information.
 func Extract(path string, data []byte) (ast.Expr, error) {
@@ -85,20 +188,21 @@ func extract(path string, b []byte) (ast.Expr, error) {
 //
 // The runtime may be nil if Decode isn't used.
 func NewDecoder(r *cue.Runtime, path string, src io.Reader) *Decoder {
+	lr := &lineReader{r: src, line: 1}
 	return &Decoder{
-		r:      r,
-		path:   path,
-		dec:    gojson.NewDecoder(src),
-		offset: 1,
+		r:    r,
+		path: path,
+		dec:  gojson.NewDecoder(lr),
+		src:  lr,
 	}
 }
 
 // A Decoder converts JSON values to CUE.
 type Decoder struct {
-	r      *cue.Runtime
-	path   string
-	dec    *gojson.Decoder
-	offset int
+	r    *cue.Runtime
+	path string
+	dec  *gojson.Decoder
+	src  *lineReader
 }
 
 // Extract converts the current JSON value to a CUE ast. It returns io.EOF
@@ -118,19 +222,78 @@ func (d *Decoder) extract() (ast.Expr, error) {
 	if err == io.EOF {
 		return nil, err
 	}
-	offset := d.offset
-	d.offset += len(raw)
+	end := int(d.dec.InputOffset())
 	if err != nil {
-		pos := token.NewFile(d.path, offset, len(raw)).Pos(0, 0)
+		if serr, ok := err.(*gojson.SyntaxError); ok && serr.Offset > 0 {
+			// Offset is just past the offending byte.
+			end = int(serr.Offset) - 1
+		}
+		line, col := d.src.advance(end)
+		pos := token.NewFile(d.path, end-col+2, col).Pos(col-1, 0)
+		pos.File().AddLineInfo(0, d.path, line)
 		return nil, errors.Wrapf(err, pos, "invalid JSON for file %q", d.path)
 	}
-	expr, err := parser.ParseExpr(d.path, []byte(raw), parser.FileOffset(offset))
+
+	// Parse the value at its position within the input. Preceding lines are
+	// accounted for with line information, rather than by padding, so that
+	// decoding large streams does not require quadratic time.
+	start := end - len(raw)
+	line, col := d.src.advance(start)
+	d.src.advance(end)
+	src := []byte(raw)
+	if col > 1 {
+		src = append([]byte(strings.Repeat(" ", col-1)), src...)
+	}
+	expr, err := parser.ParseExpr(d.path, src, parser.FileOffset(start-col+2))
 	if err != nil {
+		if pos := errors.Positions(err); len(pos) > 0 && pos[0].File() != nil {
+			pos[0].File().AddLineInfo(0, d.path, line)
+		}
 		return nil, err
 	}
+	if f := expr.Pos().File(); f != nil {
+		f.AddLineInfo(0, d.path, line)
+	}
 	return expr, nil
 }
 
+// A lineReader tracks the line and column of the input read by a JSON
+// decoder, which may read ahead of the value it decodes.
+type lineReader struct {
+	r      io.Reader
+	buf    []byte // data read, but not yet accounted for
+	offset int    // offset of buf in the input
+	line   int    // line at offset
+	col    int    // zero-based byte column at offset
+}
+
+func (r *lineReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.buf = append(r.buf, b[:n]...)
+	return n, err
+}
+
+// advance moves the current position to the given offset in the input and
+// returns the line and one-based column of this offset.
+func (r *lineReader) advance(offset int) (line, col int) {
+	n := offset - r.offset
+	if n > len(r.buf) {
+		n = len(r.buf)
+	}
+	if n > 0 {
+		for _, c := range r.buf[:n] {
+			r.col++
+			if c == '\n' {
+				r.line++
+				r.col = 0
+			}
+		}
+		r.buf = r.buf[:copy(r.buf, r.buf[n:])]
+		r.offset += n
+	}
+	return r.line, r.col + 1
+}
+
 // Decode converts the current JSON value to a CUE instance. It returns io.EOF
 // if the input has been exhausted.
 //
@@ -23,7 +23,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
 )
 
@@ -150,3 +152,104 @@ func toString(w *bytes.Buffer, e ast.Expr, err error) {
 	}
 	fmt.Fprint(w, string(b))
 }
+
+func TestDecoderPositions(t *testing.T) {
+	in := "{\"a\": 1}\n\n{\"b\": \"ü\", \"c\": 2}\n  {\n\t\"d\": 3}  [4]\n"
+	d := NewDecoder(nil, "test.json", strings.NewReader(in))
+
+	var got []string
+	for {
+		e, err := d.Extract()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Walk(e, func(n ast.Node) bool {
+			switch x := n.(type) {
+			case *ast.BasicLit:
+				got = append(got, fmt.Sprintf("%s@%s", x.Value, x.Pos()))
+			}
+			return true
+		}, nil)
+	}
+	want := []string{
+		`1@test.json:1:7`,
+		`"ü"@test.json:3:7`,
+		`2@test.json:3:18`,
+		`3@test.json:5:7`,
+		`4@test.json:5:12`,
+	}
+	assert.Equal(t, want, got)
+
+	d = NewDecoder(nil, "test.json", strings.NewReader("{}\n{\"a\": 1,}"))
+	_, err := d.Extract()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = d.Extract()
+	pos := errors.Positions(err)
+	if len(pos) != 1 || pos[0].String() != "test.json:2:9" {
+		t.Errorf("got error positions %v; want test.json:2:9", pos)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	const src = `{
+		z: "<a&b>"
+		a: ["ü", 1.50]
+		m: {y: 1, x: {}}
+	}`
+	testCases := []struct {
+		name string
+		cfg  Config
+		out  string
+	}{{
+		name: "zero",
+		out:  `{"z":"<a&b>","a":["ü",1.50],"m":{"y":1,"x":{}}}`,
+	}, {
+		name: "indent",
+		cfg:  Config{Indent: "  ", TrailingNewline: true},
+		out: `{
+  "z": "<a&b>",
+  "a": [
+    "ü",
+    1.50
+  ],
+  "m": {
+    "y": 1,
+    "x": {}
+  }
+}
+`,
+	}, {
+		name: "escape",
+		cfg:  Config{EscapeHTML: true, ASCII: true},
+		out:  `{"z":"\u003ca\u0026b\u003e","a":["\u00fc",1.50],"m":{"y":1,"x":{}}}`,
+	}, {
+		name: "sort",
+		cfg:  Config{SortKeys: true},
+		out:  `{"a":["ü",1.50],"m":{"x":{},"y":1},"z":"<a&b>"}`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var r cue.Runtime
+			inst, err := r.Compile("test", src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := tc.cfg.Marshal(inst.Value())
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tc.out, string(b))
+		})
+	}
+
+	b, err := (&Config{ASCII: true}).Format([]byte(`"😀"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `"\ud83d\ude00"`, string(b))
+}
@@ -0,0 +1,256 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/json"
+	"cuelang.org/go/encoding/yaml"
+)
+
+// A Resolver retrieves the contents of a JSON Schema document.
+type Resolver interface {
+	// Resolve returns the document at u, which has no fragment.
+	Resolve(u *url.URL) ([]byte, error)
+}
+
+// HTTPResolver is a Resolver that fetches http and https URLs and reads
+// file URLs, and URLs without a scheme, from the local file system.
+type HTTPResolver struct {
+	// Client is used for fetching documents. If nil, http.DefaultClient is
+	// used.
+	Client *http.Client
+}
+
+// Resolve implements Resolver.
+func (r *HTTPResolver) Resolve(u *url.URL) ([]byte, error) {
+	switch u.Scheme {
+	case "", "file":
+		return ioutil.ReadFile(u.Path)
+
+	case "http", "https":
+		c := r.Client
+		if c == nil {
+			c = http.DefaultClient
+		}
+		resp, err := c.Get(u.String())
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+		}
+		return ioutil.ReadAll(resp.Body)
+	}
+	return nil, fmt.Errorf("unsupported scheme %q", u.Scheme)
+}
+
+// A Bundle is a Resolver for an offline set of documents, keyed by their
+// URL. Resolving a URL not in the bundle fails, so that extracting a bundle
+// never accesses the network.
+type Bundle map[string][]byte
+
+// Resolve implements Resolver.
+func (b Bundle) Resolve(u *url.URL) ([]byte, error) {
+	data, ok := b[u.String()]
+	if !ok {
+		return nil, fmt.Errorf("document %s not in bundle", u)
+	}
+	return data, nil
+}
+
+// A BundleFile is the conversion of a single document of a bundle.
+type BundleFile struct {
+	// URL is the URL of the document.
+	URL string
+
+	// ImportPath is the import path of the package of the converted
+	// document. It is empty for the root document, unless its ID refers to
+	// it as an external document.
+	ImportPath string
+
+	File *ast.File
+}
+
+// ExtractBundle converts JSON Schema data, and all the documents it refers
+// to directly or indirectly, into CUE. Each document is converted into a
+// separate file with the package name and import path determined by
+// cfg.ImportPath, so that the packages mirror the reference graph. The
+// first file is the conversion of data itself.
+//
+// Documents are retrieved with cfg.Resolver, and parsed as YAML if their
+// path has a .yaml or .yml extension, or as JSON otherwise. References
+// between documents must not form a cycle, as imports between CUE packages
+// may not.
+func ExtractBundle(data cue.InstanceOrValue, cfg *Config) ([]*BundleFile, error) {
+	if cfg.Resolver == nil {
+		return nil, errors.Newf(token.NoPos, "jsonschema: bundle requires a resolver")
+	}
+	root := data.Value()
+	ctx := root.Context()
+
+	var errs errors.Error
+	var files []*BundleFile
+	edges := map[string][]string{} // from importing to imported URLs
+	done := map[string]bool{}
+
+	extract := func(v cue.Value, cfg *Config) (*BundleFile, []*url.URL) {
+		d := &decoder{cfg: cfg}
+		f := d.decode(v)
+		errs = errors.Append(errs, d.errs)
+		done[cfg.ID] = true
+
+		var refs []*url.URL
+		for _, u := range d.external {
+			refs = append(refs, u)
+			edges[cfg.ID] = append(edges[cfg.ID], u.String())
+		}
+		sort.Slice(refs, func(i, j int) bool {
+			return refs[i].String() < refs[j].String()
+		})
+		sort.Strings(edges[cfg.ID])
+		return &BundleFile{URL: cfg.ID, File: f}, refs
+	}
+
+	file, queue := extract(root, cfg)
+	if u, err := url.Parse(cfg.ID); cfg.ID != "" && err == nil {
+		file.ImportPath, _ = importPath(cfg, u)
+	}
+	files = append(files, file)
+
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		if done[u.String()] {
+			continue
+		}
+		done[u.String()] = true
+
+		v, err := resolve(ctx, cfg.Resolver, u)
+		if err != nil {
+			errs = errors.Append(errs, errors.Promote(err, ""))
+			continue
+		}
+		p, err := importPath(cfg, u)
+		if err != nil {
+			errs = errors.Append(errs, errors.Promote(err, ""))
+			continue
+		}
+		spec := &ast.ImportSpec{Path: ast.NewString(p)}
+		info, _ := astutil.ParseImportSpec(spec)
+
+		sub := *cfg
+		sub.ID = u.String()
+		sub.PkgName = info.Ident
+		file, refs := extract(v, &sub)
+		file.ImportPath = p
+		files = append(files, file)
+		queue = append(queue, refs...)
+	}
+
+	if cycle := findCycle(cfg.ID, edges); cycle != nil {
+		errs = errors.Append(errs, errors.Newf(token.NoPos,
+			"import cycle in references: %s", strings.Join(cycle, " -> ")))
+	}
+	if errs != nil {
+		return nil, errs
+	}
+	return files, nil
+}
+
+// importPath returns the import path for the document at u.
+func importPath(cfg *Config, u *url.URL) (string, error) {
+	if cfg.ImportPath != nil {
+		return cfg.ImportPath(u)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("unknown domain for document %q", u)
+	}
+	return defaultImportPath(u), nil
+}
+
+// resolve retrieves and parses the document at u.
+func resolve(ctx *cue.Context, r Resolver, u *url.URL) (cue.Value, error) {
+	b, err := r.Resolve(u)
+	if err != nil {
+		return cue.Value{}, err
+	}
+	var v cue.Value
+	switch path.Ext(u.Path) {
+	case ".yaml", ".yml":
+		f, err := yaml.Extract(u.String(), b)
+		if err != nil {
+			return cue.Value{}, err
+		}
+		v = ctx.BuildFile(f)
+
+	default:
+		expr, err := json.Extract(u.String(), b)
+		if err != nil {
+			return cue.Value{}, err
+		}
+		v = ctx.BuildExpr(expr)
+	}
+	return v, v.Err()
+}
+
+// findCycle returns a cycle in the graph of references reachable from
+// start, or nil if there is none.
+func findCycle(start string, edges map[string][]string) []string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var stack []string
+
+	var visit func(u string) []string
+	visit = func(u string) []string {
+		switch state[u] {
+		case visiting:
+			for i, x := range stack {
+				if x == u {
+					return append(append([]string{}, stack[i:]...), u)
+				}
+			}
+		case visited:
+			return nil
+		}
+		state[u] = visiting
+		stack = append(stack, u)
+		for _, x := range edges[u] {
+			if c := visit(x); c != nil {
+				return c
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[u] = visited
+		return nil
+	}
+	return visit(start)
+}
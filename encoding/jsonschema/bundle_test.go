@@ -0,0 +1,212 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/encoding/json"
+)
+
+func TestExtractBundle(t *testing.T) {
+	const (
+		root   = "https://example.com/schemas/root.json"
+		types  = "https://example.com/schemas/types.json"
+		common = "https://example.com/schemas/common/id.yaml"
+	)
+	docs := Bundle{
+		types: []byte(`{
+			"$defs": {
+				"name": {"type": "string"},
+				"user": {
+					"type": "object",
+					"properties": {
+						"id": {"$ref": "common/id.yaml"},
+						"name": {"$ref": "#/$defs/name"}
+					}
+				}
+			}
+		}`),
+		common: []byte("type: integer\nminimum: 1\n"),
+	}
+
+	testCases := []struct {
+		name    string
+		id      string
+		in      string
+		docs    Bundle
+		mapPath func(u *url.URL) (string, error)
+		want    string
+		err     string
+	}{{
+		name: "relative and absolute",
+		id:   root,
+		in: `{
+			"type": "object",
+			"properties": {
+				"owner": {"$ref": "types.json#/$defs/user"},
+				"id": {"$ref": "https://example.com/schemas/common/id.yaml"}
+			}
+		}`,
+		docs: docs,
+		want: `
+-- https://example.com/schemas/root.json (example.com/schemas/root.json:root)
+package root
+
+import (
+	"example.com/schemas/types.json:types"
+	"example.com/schemas/common/id.yaml:schema"
+)
+
+owner?: types.#user
+id?:    schema
+...
+
+-- https://example.com/schemas/common/id.yaml (example.com/schemas/common/id.yaml:schema)
+package schema
+
+int & >=1
+
+-- https://example.com/schemas/types.json (example.com/schemas/types.json:types)
+package types
+
+import "example.com/schemas/common/id.yaml:schema"
+
+_
+
+#name: string
+
+#user: {
+	id?:   schema
+	name?: #name
+	...
+}`,
+	}, {
+		name: "local files",
+		id:   "schemas/root.json",
+		in:   `{"$ref": "types.json#/$defs/name"}`,
+		docs: Bundle{
+			"schemas/types.json": []byte(`{"$defs": {"name": {"type": "string"}}}`),
+		},
+		mapPath: func(u *url.URL) (string, error) {
+			return "example.com/" + strings.TrimSuffix(u.Path, ".json"), nil
+		},
+		want: `
+-- schemas/root.json (example.com/schemas/root)
+package root
+
+import "example.com/schemas/types"
+
+types.#name
+
+-- schemas/types.json (example.com/schemas/types)
+package types
+
+_
+
+#name: string`,
+	}, {
+		name: "not in bundle",
+		id:   root,
+		in:   `{"$ref": "missing.json"}`,
+		docs: docs,
+		err:  "document https://example.com/schemas/missing.json not in bundle",
+	}, {
+		name: "unknown domain",
+		id:   "root.json",
+		in:   `{"$ref": "types.json"}`,
+		docs: docs,
+		err:  `unknown domain for reference "types.json"`,
+	}, {
+		name: "cycle",
+		id:   root,
+		in:   `{"$ref": "loop.json"}`,
+		docs: Bundle{
+			"https://example.com/schemas/loop.json": []byte(`{"$ref": "root.json"}`),
+		},
+		err: "import cycle in references: " + root +
+			" -> https://example.com/schemas/loop.json -> " + root,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			expr, err := json.Extract("in.json", []byte(tc.in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := cuecontext.New().BuildExpr(expr)
+			files, err := ExtractBundle(v, &Config{
+				ID:         tc.id,
+				PkgName:    "root",
+				ImportPath: tc.mapPath,
+				Resolver:   tc.docs,
+			})
+			if err != nil || tc.err != "" {
+				if err == nil || tc.err == "" || !strings.Contains(errors.Details(err, nil), tc.err) {
+					t.Fatalf("got error %v; want %s", errors.Details(err, nil), tc.err)
+				}
+				return
+			}
+			b := &strings.Builder{}
+			for _, f := range files {
+				fmt.Fprintf(b, "\n-- %s (%s)\n", f.URL, f.ImportPath)
+				out, err := format.Node(f.File, format.Simplify())
+				if err != nil {
+					t.Fatal(err)
+				}
+				b.Write(out)
+			}
+			got := strings.TrimSpace(b.String())
+			if want := strings.TrimSpace(tc.want); got != want {
+				t.Error(cmp.Diff(got, want))
+			}
+		})
+	}
+}
+
+func TestHTTPResolver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/schema.json" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"type": "string"}`)
+	}))
+	defer srv.Close()
+
+	r := &HTTPResolver{}
+	u, _ := url.Parse(srv.URL + "/schema.json")
+	b, err := r.Resolve(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"type": "string"}`; got != want {
+		t.Errorf("got %s; want %s", got, want)
+	}
+
+	u, _ = url.Parse(srv.URL + "/missing.json")
+	if _, err := r.Resolve(u); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("got error %v; want 404", err)
+	}
+}
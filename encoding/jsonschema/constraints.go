@@ -96,11 +96,15 @@ func addDefinitions(n cue.Value, s *state) {
 
 		ident := "#" + name
 		if ast.IsValidIdent(ident) {
-			f = &ast.Field{Value: s.schema(n, label{ident, true})}
+			expr, state := s.schemaState(n, allTypes, []label{{ident, true}}, false)
+			f = &ast.Field{Value: expr}
 			f.Label = ast.NewIdent(ident)
+			state.annotate(f)
 		} else {
-			f = &ast.Field{Value: s.schema(n, label{"#", true}, label{name: name})}
+			expr, state := s.schemaState(n, allTypes, []label{{"#", true}, {name: name}}, false)
+			f = &ast.Field{Value: expr}
 			f.Label = ast.NewString(name)
+			state.annotate(f)
 			ident = "#"
 			f = &ast.Field{
 				Label: ast.NewIdent("#"),
@@ -481,6 +485,7 @@ var constraints = []*constraint{
 					f.Attrs = append(f.Attrs, internal.NewAttr("deprecated", ""))
 				}
 			}
+			state.annotate(f)
 			obj.Elts = append(obj.Elts, f)
 			s.setField(label{name: key}, f)
 		})
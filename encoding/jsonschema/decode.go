@@ -28,6 +28,7 @@ import (
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/ast/astutil"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal"
 )
@@ -43,6 +44,10 @@ type decoder struct {
 	cfg   *Config
 	errs  errors.Error
 	numID int // for creating unique numbers: increment on each use
+
+	// external records the documents referred to by references to other
+	// documents, keyed by their URL.
+	external map[string]*url.URL
 }
 
 // addImport registers
@@ -101,6 +106,13 @@ func (d *decoder) decode(v cue.Value) *ast.File {
 
 func (d *decoder) schema(ref []ast.Label, v cue.Value) (a []ast.Decl) {
 	root := state{decoder: d}
+	if d.cfg.ID != "" {
+		// The ID of the configuration acts as the $id of the document, so
+		// that relative references can be resolved against it.
+		if u, err := url.Parse(d.cfg.ID); err == nil {
+			root.id = u
+		}
+	}
 
 	var name ast.Label
 	inner := len(ref) - 1
@@ -116,6 +128,9 @@ func (d *decoder) schema(ref []ast.Label, v cue.Value) (a []ast.Decl) {
 	if state.jsonschema != "" {
 		tags = append(tags, fmt.Sprintf("schema=%q", state.jsonschema))
 	}
+	if a := state.annotation(); a != "" {
+		tags = append(tags, a)
+	}
 
 	if name == nil {
 		if len(tags) > 0 {
@@ -349,6 +364,11 @@ type state struct {
 	patterns    []ast.Expr
 
 	list *ast.ListLit
+
+	// keys and extra hold the annotations recorded if Config.Annotate is
+	// set.
+	keys  []string
+	extra []string
 }
 
 type label struct {
@@ -560,6 +580,9 @@ func (s *state) schemaState(n cue.Value, types cue.Kind, idRef []label, isLogica
 	// do multiple passes over the constraints to ensure they are done in order.
 	for pass := 0; pass < 4; pass++ {
 		state.processMap(n, func(key string, value cue.Value) {
+			if pass == 0 && s.cfg.Annotate {
+				state.record(key, value)
+			}
 			// Convert each constraint into a either a value or a functor.
 			c := constraintMap[key]
 			if c == nil {
@@ -578,6 +601,86 @@ func (s *state) schemaState(n cue.Value, types cue.Kind, idRef []label, isLogica
 	return state.finalize(), state
 }
 
+// annotated lists the keywords that are recorded as annotations in addition
+// to, or instead of, being converted to CUE constraints.
+var annotated = map[string]bool{
+	"title":             true,
+	"$comment":          true,
+	"examples":          true,
+	"dependencies":      true,
+	"patternProperties": true,
+	"propertyNames":     true,
+}
+
+// stringKeywords lists the keywords with string values. These are recorded
+// as is, rather than as JSON.
+var stringKeywords = map[string]bool{
+	"id":               true, // draft-04
+	"title":            true,
+	"$comment":         true,
+	"format":           true,
+	"contentMediaType": true,
+	"contentEncoding":  true,
+}
+
+// record records key as the next keyword of the schema and, if its value n
+// is not represented by CUE constraints, the value itself.
+func (s *state) record(key string, n cue.Value) {
+	s.keys = append(s.keys, key)
+
+	switch {
+	case constraintMap[key] == nil, annotated[key]:
+	case key == "additionalItems" && n.Kind() == cue.BoolKind:
+	case key == "additionalProperties" && n.Kind() == cue.StructKind:
+	default:
+		return
+	}
+
+	var str string
+	if stringKeywords[key] && n.Kind() == cue.StringKind {
+		str, _ = n.String()
+	} else {
+		b, err := n.MarshalJSON()
+		if err != nil {
+			s.errf(n, "cannot record %q: %v", key, err)
+			return
+		}
+		str = string(b)
+	}
+	s.extra = append(s.extra, key+"="+quoteAttr(str))
+}
+
+// quoteAttr quotes s for use in an attribute. Attribute strings may not
+// contain escaped quotes, so strings with double quotes, like JSON values,
+// are quoted as raw single-quoted strings.
+func quoteAttr(s string) string {
+	if !strings.Contains(s, `"`) {
+		return literal.String.Quote(s)
+	}
+	hash := "#"
+	for strings.Contains(s, `'`+hash) || strings.Contains(s, `\`+hash) {
+		hash += "#"
+	}
+	return hash + `'` + s + `'` + hash
+}
+
+// annotation returns the body of the @jsonschema attribute holding the
+// recorded annotations, or "" if there are none.
+func (s *state) annotation() string {
+	if len(s.keys) == 0 {
+		return ""
+	}
+	order := "order=" + quoteAttr(strings.Join(s.keys, " "))
+	return strings.Join(append([]string{order}, s.extra...), ",")
+}
+
+// annotate adds the recorded annotations, if any, to f.
+func (s *state) annotate(f *ast.Field) {
+	if a := s.annotation(); a != "" {
+		f.Attrs = append(f.Attrs, &ast.Attribute{Text: "@jsonschema(" + a + ")"})
+	}
+}
+
 func (s *state) value(n cue.Value) ast.Expr {
 	k := n.Kind()
 	s.usedTypes |= k
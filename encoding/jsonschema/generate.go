@@ -0,0 +1,696 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/json"
+	"cuelang.org/go/internal"
+)
+
+// Generate converts the CUE schema at the root of data to a JSON Schema.
+// Definitions are converted to definitions, references to them to $ref, and
+// regular fields to properties.
+//
+// Generate is the inverse of Extract. It uses the annotations recorded by
+// Extract if Config.Annotate is set to restore the keywords of the original
+// schema that have no CUE equivalent and the original order of keywords.
+// Extracting a schema with annotations and generating it again thus
+// reproduces the schema, and edits to the CUE schema in between result in
+// minimal changes to it. Only properties and definitions, and not other
+// subschemas, are annotated.
+func Generate(data cue.InstanceOrValue, cfg *Config) (*ast.File, error) {
+	v := data.Value()
+	g := &generator{cfg: cfg, root: v, defs: "definitions"}
+
+	ann := parseAnnotation(v.Attributes(cue.DeclAttr))
+	if ann != nil {
+		g.draft04 = strings.Contains(ann.schema, "draft-04")
+		if ann.has("$defs") {
+			g.defs = "$defs"
+		}
+	}
+
+	o := &object{}
+	if ann != nil && ann.schema != "" {
+		o.set("$schema", ast.NewString(ann.schema))
+	}
+	if ann != nil && ann.id != "" {
+		key := "$id"
+		if ann.has("id") || (g.draft04 && !ann.has("$id")) {
+			key = "id"
+		}
+		o.set(key, ast.NewString(ann.id))
+	}
+	g.fill(o, v, ann, rootDoc(v))
+
+	if g.errs != nil {
+		return nil, g.errs
+	}
+	f := &ast.File{}
+	for _, x := range o.sorted(ann) {
+		f.Decls = append(f.Decls, x)
+	}
+	return f, nil
+}
+
+type generator struct {
+	cfg     *Config
+	root    cue.Value
+	draft04 bool
+	defs    string // keyword for definitions
+	errs    errors.Error
+}
+
+func (g *generator) errf(v cue.Value, format string, args ...interface{}) {
+	g.errs = errors.Append(g.errs, errors.Newf(v.Pos(), format, args...))
+}
+
+func (g *generator) unsupported(v cue.Value) {
+	if g.cfg.Strict {
+		g.errf(v, "jsonschema: unsupported expression %v", v)
+	}
+}
+
+// An annotation holds the information recorded in @jsonschema attributes.
+type annotation struct {
+	order  []string // keywords in their original order
+	extra  []keyValue
+	schema string
+	id     string
+}
+
+type keyValue struct {
+	key, value string
+}
+
+func parseAnnotation(attrs []cue.Attribute) *annotation {
+	var ann *annotation
+	for _, a := range attrs {
+		if a.Name() != "jsonschema" {
+			continue
+		}
+		if ann == nil {
+			ann = &annotation{}
+		}
+		for i := 0; i < a.NumArgs(); i++ {
+			switch key, value := a.Arg(i); key {
+			case "order":
+				ann.order = strings.Fields(value)
+			case "schema":
+				ann.schema = value
+			case "id":
+				ann.id = value
+			default:
+				ann.extra = append(ann.extra, keyValue{key, value})
+			}
+		}
+	}
+	return ann
+}
+
+// has reports whether the original schema had the given keyword.
+func (a *annotation) has(key string) bool {
+	if a == nil {
+		return false
+	}
+	for _, k := range a.order {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *annotation) lookup(key string) (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	for _, kv := range a.extra {
+		if kv.key == key {
+			return kv.value, true
+		}
+	}
+	return "", false
+}
+
+// An object holds the keywords of a schema in the order in which they are
+// generated.
+type object struct {
+	fields []*ast.Field
+}
+
+func (o *object) set(key string, x ast.Expr) {
+	for _, f := range o.fields {
+		if name, _, _ := ast.LabelName(f.Label); name == key {
+			f.Value = x
+			return
+		}
+	}
+	o.fields = append(o.fields, &ast.Field{Label: ast.NewString(key), Value: x})
+}
+
+func (o *object) has(key string) bool {
+	for _, f := range o.fields {
+		if name, _, _ := ast.LabelName(f.Label); name == key {
+			return true
+		}
+	}
+	return false
+}
+
+// sorted returns the keywords of o with the keywords of the original schema
+// in their original order, followed by any new ones.
+func (o *object) sorted(ann *annotation) []*ast.Field {
+	if ann == nil {
+		return o.fields
+	}
+	rank := map[string]int{}
+	for i, k := range ann.order {
+		rank[k] = i
+	}
+	a := append([]*ast.Field(nil), o.fields...)
+	sort.SliceStable(a, func(i, j int) bool {
+		return keyRank(rank, a[i]) < keyRank(rank, a[j])
+	})
+	return a
+}
+
+func keyRank(rank map[string]int, f *ast.Field) int {
+	name, _, _ := ast.LabelName(f.Label)
+	if r, ok := rank[name]; ok {
+		return r
+	}
+	return len(rank)
+}
+
+func (o *object) expr(ann *annotation) ast.Expr {
+	s := &ast.StructLit{}
+	for _, f := range o.sorted(ann) {
+		s.Elts = append(s.Elts, f)
+	}
+	return s
+}
+
+// schema converts v, which has the given annotation and documentation, to a
+// JSON Schema.
+func (g *generator) schema(v cue.Value, ann *annotation, doc string) ast.Expr {
+	o := &object{}
+	g.fill(o, v, ann, doc)
+	return o.expr(ann)
+}
+
+func (g *generator) fill(o *object, v cue.Value, ann *annotation, doc string) {
+	if ann != nil {
+		if title, ok := ann.lookup("title"); ok {
+			if doc == title {
+				doc = ""
+			}
+			doc = strings.TrimPrefix(doc, title+"\n\n")
+		}
+	}
+	if doc != "" {
+		o.set("description", ast.NewString(doc))
+	}
+
+	if ref := g.ref(v); ref != "" {
+		o.set("$ref", ast.NewString(ref))
+	} else {
+		for _, c := range appendSplit(nil, cue.AndOp, v) {
+			g.conjunct(o, v, c, ann)
+		}
+		g.setType(o, v, ann)
+	}
+
+	if d, ok := v.Default(); ok && isData(d) {
+		// An empty list is the default of any list.
+		if d.Kind() != cue.ListKind || !isEmptyList(d) {
+			o.set("default", g.value(d))
+		}
+	}
+
+	for _, a := range v.Attributes(cue.FieldAttr | cue.DeclAttr) {
+		if a.Name() == "deprecated" {
+			o.set("deprecated", ast.NewBool(true))
+		}
+	}
+
+	if ann == nil {
+		return
+	}
+	for _, kv := range ann.extra {
+		if stringKeywords[kv.key] {
+			o.set(kv.key, ast.NewString(kv.value))
+			continue
+		}
+		x, err := json.Extract(kv.key, []byte(kv.value))
+		if err != nil {
+			g.errf(v, "invalid annotation for %q: %v", kv.key, err)
+			continue
+		}
+		o.set(kv.key, x)
+	}
+}
+
+// setType sets the type keyword for the kinds of values allowed by v. Unless
+// the original schema had one, the type is only set if it is not already
+// implied by other keywords.
+func (g *generator) setType(o *object, v cue.Value, ann *annotation) {
+	if ann != nil && !ann.has("type") {
+		return
+	}
+	if ann == nil {
+		for _, k := range []string{"$ref", "const", "enum", "anyOf", "oneOf"} {
+			if o.has(k) {
+				return
+			}
+		}
+	}
+
+	k := v.IncompleteKind()
+	if k == cue.TopKind || k == cue.BottomKind {
+		return
+	}
+	var types []ast.Expr
+	add := func(kind cue.Kind, name string) {
+		if k&kind != 0 {
+			types = append(types, ast.NewString(name))
+		}
+	}
+	add(cue.StructKind, "object")
+	add(cue.ListKind, "array")
+	add(cue.StringKind, "string")
+	switch {
+	case k&cue.NumberKind == cue.IntKind:
+		add(cue.IntKind, "integer")
+	default:
+		add(cue.NumberKind, "number")
+	}
+	add(cue.BoolKind, "boolean")
+	add(cue.NullKind, "null")
+	if k&cue.BytesKind != 0 {
+		g.unsupported(v)
+	}
+
+	switch len(types) {
+	case 0:
+	case 1:
+		o.set("type", types[0])
+	default:
+		o.set("type", ast.NewList(types...))
+	}
+}
+
+// ref returns the JSON reference for v if v refers to a definition of the
+// schema, or "" otherwise.
+func (g *generator) ref(v cue.Value) string {
+	root, p := v.ReferencePath()
+	sels := p.Selectors()
+	if len(sels) == 0 || !sels[len(sels)-1].IsDefinition() && !isDefinitionName(sels) {
+		return ""
+	}
+	// References to other packages are not converted.
+	if x := g.root.LookupPath(p); !x.Exists() || root.Pos().Filename() != g.root.Pos().Filename() {
+		return ""
+	}
+
+	ref := "#"
+	for i, sel := range sels {
+		name := sel.String()
+		switch {
+		case name == "#":
+			ref += "/" + g.defs
+			continue
+		case sel.IsDefinition():
+			ref += "/" + g.defs
+			name = name[1:]
+		case i > 0 && sels[i-1].String() == "#":
+		default:
+			ref += "/properties"
+		}
+		if s, err := literal.Unquote(name); err == nil {
+			name = s
+		}
+		name = strings.ReplaceAll(name, "~", "~0")
+		ref += "/" + strings.ReplaceAll(name, "/", "~1")
+	}
+	return ref
+}
+
+// isDefinitionName reports whether sels refers to a definition whose name is
+// not a valid identifier, as in #."foo-bar".
+func isDefinitionName(sels []cue.Selector) bool {
+	n := len(sels)
+	return n >= 2 && sels[n-2].String() == "#"
+}
+
+func (g *generator) conjunct(o *object, v, c cue.Value, ann *annotation) {
+	if ref := g.ref(c); ref != "" {
+		o.set("$ref", ast.NewString(ref))
+		return
+	}
+	if isData(c) && c.Kind() != cue.StructKind {
+		g.constant(o, c, ann)
+		return
+	}
+
+	switch op, a := c.Expr(); op {
+	case cue.NoOp, cue.SelectorOp:
+		switch c.IncompleteKind() {
+		case cue.StructKind:
+			g.object(o, v, c, ann)
+		case cue.ListKind:
+			g.list(o, c)
+		}
+
+	case cue.OrOp:
+		g.disjunction(o, c, ann)
+
+	case cue.LessThanOp:
+		g.bound(o, "maximum", "exclusiveMaximum", a[0], true)
+	case cue.LessThanEqualOp:
+		g.bound(o, "maximum", "exclusiveMaximum", a[0], false)
+	case cue.GreaterThanOp:
+		g.bound(o, "minimum", "exclusiveMinimum", a[0], true)
+	case cue.GreaterThanEqualOp:
+		g.bound(o, "minimum", "exclusiveMinimum", a[0], false)
+
+	case cue.NotEqualOp:
+		x := &object{}
+		g.constant(x, a[0], nil)
+		o.set("not", x.expr(nil))
+
+	case cue.RegexMatchOp:
+		o.set("pattern", g.value(a[0]))
+	case cue.NotRegexMatchOp:
+		x := &object{}
+		x.set("pattern", g.value(a[0]))
+		o.set("not", x.expr(nil))
+
+	case cue.CallOp:
+		switch name := fmt.Sprint(a[0]); {
+		case name == "strings.MinRunes" && len(a) == 2:
+			o.set("minLength", g.value(a[1]))
+		case name == "strings.MaxRunes" && len(a) == 2:
+			o.set("maxLength", g.value(a[1]))
+		case name == "math.MultipleOf" && len(a) == 2:
+			o.set("multipleOf", g.value(a[1]))
+		case name == "list.MinItems" && len(a) == 2:
+			o.set("minItems", g.value(a[1]))
+		case name == "list.MaxItems" && len(a) == 2:
+			o.set("maxItems", g.value(a[1]))
+		case name == "list.Contains" && len(a) == 2:
+			o.set("contains", g.schema(a[1], nil, ""))
+		case strings.HasPrefix(name, "list.UniqueItems"):
+			o.set("uniqueItems", ast.NewBool(true))
+		case name == "struct.MinFields" && len(a) == 2:
+			o.set("minProperties", g.value(a[1]))
+		case name == "struct.MaxFields" && len(a) == 2:
+			o.set("maxProperties", g.value(a[1]))
+		default:
+			g.unsupported(c)
+		}
+
+	default:
+		g.unsupported(c)
+	}
+}
+
+// constant sets the keywords for the concrete value v. A null value is
+// represented by the type keyword, unless the original schema used const or
+// enum.
+func (g *generator) constant(o *object, v cue.Value, ann *annotation) {
+	switch {
+	case v.Kind() == cue.NullKind && ann != nil && !ann.has("const") && !ann.has("enum"):
+	case g.draft04 || ann.has("enum"):
+		o.set("enum", ast.NewList(g.value(v)))
+	default:
+		o.set("const", g.value(v))
+	}
+}
+
+func (g *generator) bound(o *object, key, exclusive string, x cue.Value, excl bool) {
+	switch {
+	case !excl:
+		o.set(key, g.value(x))
+	case g.draft04:
+		o.set(key, g.value(x))
+		o.set(exclusive, ast.NewBool(true))
+	default:
+		o.set(exclusive, g.value(x))
+	}
+}
+
+// disjunction converts a disjunction. Enumerations of values are converted
+// to enum, disjunctions of values of different kinds, as in null | string,
+// to a single schema, and other disjunctions to anyOf, or oneOf if used in
+// the original schema.
+func (g *generator) disjunction(o *object, v cue.Value, ann *annotation) {
+	a := appendSplit(nil, cue.OrOp, v)
+
+	enum := true
+	for _, d := range a {
+		if !isData(d) || d.Kind() == cue.StructKind || g.ref(d) != "" {
+			enum = false
+		}
+	}
+	if enum {
+		var values []ast.Expr
+		for _, d := range a {
+			values = append(values, g.value(d))
+		}
+		o.set("enum", ast.NewList(values...))
+		return
+	}
+
+	var kinds cue.Kind
+	merge := true
+	for _, d := range a {
+		k := d.IncompleteKind()
+		if k == cue.NumberKind {
+			k = cue.IntKind | cue.FloatKind
+		}
+		if g.ref(d) != "" || k&kinds != 0 || k == cue.TopKind ||
+			isData(d) && d.Kind() != cue.NullKind {
+			merge = false
+		}
+		kinds |= k
+	}
+	if merge {
+		for _, d := range a {
+			if d.Kind() == cue.NullKind {
+				continue // represented by type
+			}
+			for _, c := range appendSplit(nil, cue.AndOp, d) {
+				g.conjunct(o, d, c, nil)
+			}
+		}
+		return
+	}
+
+	key := "anyOf"
+	if ann.has("oneOf") && !ann.has("anyOf") {
+		key = "oneOf"
+	}
+	var schemas []ast.Expr
+	for _, d := range a {
+		schemas = append(schemas, g.schema(d, nil, ""))
+	}
+	o.set(key, ast.NewList(schemas...))
+}
+
+func (g *generator) object(o *object, v, c cue.Value, ann *annotation) {
+	props := &object{}
+	defs := &object{}
+	var required []ast.Expr
+
+	for i, _ := c.Fields(cue.Optional(true), cue.Definitions(true)); i.Next(); {
+		x := i.Value()
+		label := i.Label()
+		fa := parseAnnotation(x.Attributes(cue.FieldAttr))
+
+		if i.IsDefinition() {
+			if label != "#" {
+				defs.set(label[1:], g.schema(x, fa, docOf(x)))
+				continue
+			}
+			for j, _ := x.Fields(cue.Optional(true)); j.Next(); {
+				y := j.Value()
+				fa := parseAnnotation(y.Attributes(cue.FieldAttr))
+				defs.set(j.Label(), g.schema(y, fa, docOf(y)))
+			}
+			continue
+		}
+
+		if !i.IsOptional() {
+			required = append(required, ast.NewString(label))
+			// Required fields without properties are not listed as
+			// properties.
+			if fa == nil && allowsAny(x) && len(x.Doc()) == 0 {
+				continue
+			}
+		}
+		props.set(label, g.schema(x, fa, docOf(x)))
+	}
+
+	if len(props.fields) > 0 {
+		o.set("properties", props.expr(nil))
+	}
+	if len(required) > 0 {
+		o.set("required", ast.NewList(required...))
+	}
+
+	// Schemas for additional properties are restored from the annotation.
+	if _, ok := ann.lookup("additionalProperties"); !ok && !ann.has("patternProperties") {
+		if !v.Allows(cue.AnyString) {
+			o.set("additionalProperties", ast.NewBool(false))
+		} else if e, ok := v.Elem(); ok && !allowsAny(e) {
+			o.set("additionalProperties", g.schema(e, nil, ""))
+		}
+	}
+
+	if len(defs.fields) > 0 {
+		o.set(g.defs, defs.expr(nil))
+	}
+}
+
+func (g *generator) list(o *object, v cue.Value) {
+	var items []cue.Value
+	minItems := true
+	for i, _ := v.List(); i.Next(); {
+		items = append(items, i.Value())
+		if !allowsAny(i.Value()) {
+			minItems = false
+		}
+	}
+	elem, hasElem := v.Elem()
+	if hasElem && allowsAny(elem) {
+		hasElem = false
+	}
+
+	switch {
+	case len(items) > 0 && minItems:
+		o.set("minItems", ast.NewLit(token.INT, fmt.Sprint(len(items))))
+		if hasElem {
+			o.set("items", g.schema(elem, nil, ""))
+		}
+
+	case len(items) > 0:
+		var a []ast.Expr
+		for _, x := range items {
+			a = append(a, g.schema(x, nil, ""))
+		}
+		o.set("items", ast.NewList(a...))
+		if hasElem {
+			o.set("additionalItems", g.schema(elem, nil, ""))
+		}
+
+	case hasElem:
+		o.set("items", g.schema(elem, nil, ""))
+	}
+}
+
+// value converts the concrete value v to JSON.
+func (g *generator) value(v cue.Value) ast.Expr {
+	b, err := v.MarshalJSON()
+	if err == nil {
+		var x ast.Expr
+		if x, err = json.Extract("", b); err == nil {
+			return x
+		}
+	}
+	g.errf(v, "jsonschema: %v", err)
+	return ast.NewNull()
+}
+
+// isData reports whether v is a concrete JSON value.
+func isData(v cue.Value) bool {
+	return v.IsConcrete() && v.Validate(cue.Concrete(true)) == nil
+}
+
+func isEmptyList(v cue.Value) bool {
+	i, _ := v.List()
+	return !i.Next()
+}
+
+// allowsAny reports whether v allows any value.
+func allowsAny(v cue.Value) bool {
+	if v.IncompleteKind() != cue.TopKind {
+		return false
+	}
+	_, p := v.ReferencePath()
+	return len(p.Selectors()) == 0
+}
+
+// docOf returns the documentation of the field from which v originates.
+func docOf(v cue.Value) string {
+	var a []string
+	for _, cg := range v.Doc() {
+		a = append(a, strings.TrimSpace(cg.Text()))
+	}
+	return strings.Join(a, "\n\n")
+}
+
+// rootDoc returns the documentation of the file holding v. This includes a
+// comment preceding the first attribute of the file, where Extract places
+// the title and description of a schema.
+func rootDoc(v cue.Value) string {
+	if doc := docOf(v); doc != "" {
+		return doc
+	}
+	f, ok := v.Source().(*ast.File)
+	if !ok {
+		return ""
+	}
+	for _, d := range f.Decls {
+		switch d.(type) {
+		case *ast.Package, *ast.ImportDecl, *ast.CommentGroup:
+			continue
+		case *ast.Attribute:
+			if cg := internal.FileComment(&ast.File{Decls: []ast.Decl{d}}); cg != nil {
+				return strings.TrimSpace(cg.Text())
+			}
+		}
+		break
+	}
+	return ""
+}
+
+// appendSplit splits v into its conjuncts or disjuncts, depending on splitBy.
+func appendSplit(a []cue.Value, splitBy cue.Op, v cue.Value) []cue.Value {
+	op, args := v.Expr()
+	if op == cue.NoOp && len(args) == 1 {
+		// The value without its default, as in *0 | int & >=0.
+		if op, _ := args[0].Expr(); op != cue.NoOp {
+			return appendSplit(a, splitBy, args[0])
+		}
+		return append(a, args...)
+	}
+	if op != splitBy {
+		return append(a, v)
+	}
+	for _, v := range args {
+		a = appendSplit(a, splitBy, v)
+	}
+	return a
+}
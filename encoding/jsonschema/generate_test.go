@@ -0,0 +1,225 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"bytes"
+	gojson "encoding/json"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/encoding/json"
+)
+
+// TestRoundTrip checks that extracting a schema with annotations and
+// generating it again reproduces the schema.
+func TestRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name   string
+		schema string
+	}{{
+		name: "draft-07",
+		schema: `{
+			"$schema": "http://json-schema.org/draft-07/schema#",
+			"$id": "https://example.com/person.json",
+			"title": "Person",
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {
+					"type": "string",
+					"description": "The name.",
+					"minLength": 1,
+					"examples": ["Jo \"the\" name"]
+				},
+				"age": {"type": "integer", "minimum": 0, "$comment": "years"},
+				"email": {"type": "string", "format": "email"},
+				"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true},
+				"address": {"$ref": "#/definitions/address"},
+				"kind": {"enum": ["a", "b"], "default": "a"},
+				"score": {"type": "number", "exclusiveMinimum": 0, "maximum": 10},
+				"x-extension": {"type": "boolean"}
+			},
+			"definitions": {
+				"address": {
+					"type": "object",
+					"properties": {
+						"street": {"type": "string"},
+						"zip": {"type": ["string", "null"], "pattern": "^[0-9]{5}$"}
+					},
+					"additionalProperties": false
+				}
+			}
+		}`,
+	}, {
+		name: "draft-04",
+		schema: `{
+			"$schema": "http://json-schema.org/draft-04/schema#",
+			"id": "https://example.com/shape.json",
+			"type": "object",
+			"properties": {
+				"size": {"type": "number", "minimum": 0, "exclusiveMinimum": true},
+				"point": {
+					"type": "array",
+					"items": [{"type": "number"}, {"type": "number"}],
+					"additionalItems": false
+				},
+				"shape": {
+					"oneOf": [
+						{"$ref": "#/definitions/circle"},
+						{"$ref": "#/definitions/square"}
+					]
+				},
+				"labels": {
+					"type": "object",
+					"additionalProperties": {"type": "string"}
+				}
+			},
+			"definitions": {
+				"circle": {
+					"type": "object",
+					"properties": {"radius": {"type": "number"}}
+				},
+				"square": {
+					"type": "object",
+					"properties": {"side": {"type": "number"}}
+				}
+			}
+		}`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := cuecontext.New()
+			src := extractAnnotated(t, ctx, tc.schema)
+			got := generate(t, ctx, src)
+			if want := compactJSON(t, tc.schema); got != want {
+				t.Errorf("got\n%s\nwant\n%s\nCUE:\n%s", got, want, src)
+			}
+		})
+	}
+}
+
+// TestRoundTripEdit checks that edits to an extracted schema result in
+// minimal changes to the original schema.
+func TestRoundTripEdit(t *testing.T) {
+	const schema = `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title": "Person",
+		"type": "object",
+		"properties": {
+			"age": {"type": "integer", "minimum": 0, "$comment": "years"},
+			"email": {"type": "string", "format": "email"}
+		}
+	}`
+	ctx := cuecontext.New()
+	src := extractAnnotated(t, ctx, schema)
+	src = strings.Replace(src, ">=0", ">=18", 1)
+	src = strings.Replace(src, "\n...", "\n\"nickname\"?: string\n...", 1)
+
+	got := generate(t, ctx, src)
+	want := compactJSON(t, `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title": "Person",
+		"type": "object",
+		"properties": {
+			"age": {"type": "integer", "minimum": 18, "$comment": "years"},
+			"email": {"type": "string", "format": "email"},
+			"nickname": {"type": "string"}
+		}
+	}`)
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s\nCUE:\n%s", got, want, src)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	ctx := cuecontext.New()
+	got := generate(t, ctx, `
+	import "strings"
+
+	// A person.
+	#Person: {
+		name: strings.MaxRunes(20)
+		age?: *0 | int & >=0
+		role?: "admin" | "user"
+	}
+	`)
+	want := compactJSON(t, `{
+		"definitions": {
+			"Person": {
+				"description": "A person.",
+				"properties": {
+					"name": {"maxLength": 20, "type": "string"},
+					"age": {"minimum": 0, "type": "integer", "default": 0},
+					"role": {"enum": ["admin", "user"]}
+				},
+				"required": ["name"],
+				"additionalProperties": false,
+				"type": "object"
+			}
+		},
+		"type": "object"
+	}`)
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func extractAnnotated(t *testing.T, ctx *cue.Context, schema string) string {
+	t.Helper()
+	expr, err := json.Extract("schema.json", []byte(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := Extract(ctx.BuildExpr(expr), &Config{Annotate: true})
+	if err != nil {
+		t.Fatal(errors.Details(err, nil))
+	}
+	b, err := format.Node(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func generate(t *testing.T, ctx *cue.Context, src string) string {
+	t.Helper()
+	v := ctx.CompileString(src)
+	if err := v.Err(); err != nil {
+		t.Fatal(errors.Details(err, nil))
+	}
+	f, err := Generate(v, &Config{})
+	if err != nil {
+		t.Fatal(errors.Details(err, nil))
+	}
+	b, err := ctx.BuildFile(f).MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func compactJSON(t *testing.T, s string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := gojson.Compact(&buf, []byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
@@ -31,6 +31,8 @@
 package jsonschema
 
 import (
+	"net/url"
+
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/token"
@@ -75,6 +77,19 @@ type Config struct {
 	//    {"$defs", foo}         {#foo} or {#, foo}
 	Map func(pos token.Pos, path []string) ([]ast.Label, error)
 
+	// ImportPath maps the URL of a document referred to by a reference to
+	// the import path of the CUE package holding its conversion. The URL
+	// has no fragment. The last element of the returned path, or its
+	// qualifier, must be a valid package name.
+	//
+	// By default, references to documents are only allowed for URLs with a
+	// host, which are mapped to the host followed by the path of the URL.
+	ImportPath func(u *url.URL) (string, error)
+
+	// Resolver retrieves the documents referred to by references to other
+	// documents. It is used by ExtractBundle.
+	Resolver Resolver
+
 	// TODO: configurability to make it compatible with OpenAPI, such as
 	// - locations of definitions: #/components/schemas, for instance.
 	// - selection and definition of formats
@@ -84,5 +99,12 @@ type Config struct {
 	// them.
 	Strict bool
 
+	// Annotate records, in @jsonschema attributes of the root, definitions,
+	// and properties, the order of the keywords of their schemas and the
+	// keywords that are not represented by CUE constraints, like title,
+	// examples, and format. Generate uses these annotations to reproduce the
+	// original schema as closely as possible.
+	Annotate bool
+
 	_ struct{} // prohibit casting from different type.
 }
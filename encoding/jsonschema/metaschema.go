@@ -0,0 +1,111 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// MetaSchema returns a schema for JSON Schema documents of the given
+// dialect, which is one of draft-04 or draft-07.
+//
+// The schema is a CUE rendition of the meta-schema of the dialect. Like the
+// meta-schema, it allows keywords that are not defined by the dialect.
+func MetaSchema(ctx *cue.Context, dialect string) (cue.Value, error) {
+	src, ok := metaSchemas[dialect]
+	if !ok {
+		return cue.Value{}, errors.Newf(token.NoPos,
+			"jsonschema: unsupported dialect %q for meta-schema", dialect)
+	}
+	v := ctx.CompileString(metaSchemaCore+src, cue.Filename(dialect+".cue"))
+	v = v.LookupPath(cue.ParsePath("#Schema"))
+	return v, v.Err()
+}
+
+var metaSchemas = map[string]string{
+	"draft-04": `
+#Schema: {
+	#core
+	id?:               string
+	exclusiveMaximum?: bool
+	exclusiveMinimum?: bool
+	required?: [string, ...string]
+}
+`,
+	"draft-07": `
+#Schema: bool | {
+	#core
+	$id?:              string
+	$comment?:         string
+	exclusiveMaximum?: number
+	exclusiveMinimum?: number
+	required?: [...string]
+	readOnly?:  bool
+	writeOnly?: bool
+	examples?: [...]
+	contains?:         #Schema
+	propertyNames?:    #Schema
+	const?:            _
+	contentMediaType?: string
+	contentEncoding?:  string
+	if?:               #Schema
+	then?:             #Schema
+	else?:             #Schema
+}
+`,
+}
+
+// metaSchemaCore holds the keywords that are common to all dialects.
+const metaSchemaCore = `
+#core: {
+	$schema?:     string
+	$ref?:        string
+	title?:       string
+	description?: string
+	default?:     _
+	multipleOf?:  number & >0
+	maximum?:     number
+	minimum?:     number
+	maxLength?:   #nonNegativeInt
+	minLength?:   #nonNegativeInt
+	pattern?:     string
+	additionalItems?: bool | #Schema
+	items?:           #Schema | [...#Schema]
+	maxItems?:        #nonNegativeInt
+	minItems?:        #nonNegativeInt
+	uniqueItems?:     bool
+	maxProperties?:   #nonNegativeInt
+	minProperties?:   #nonNegativeInt
+	additionalProperties?: bool | #Schema
+	definitions?: [string]:       #Schema
+	properties?: [string]:        #Schema
+	patternProperties?: [string]: #Schema
+	dependencies?: [string]:      #Schema | [...string]
+	enum?: [_, ...]
+	type?:   #simpleTypes | [#simpleTypes, ...#simpleTypes]
+	format?: string
+	allOf?: [#Schema, ...#Schema]
+	anyOf?: [#Schema, ...#Schema]
+	oneOf?: [#Schema, ...#Schema]
+	not?: #Schema
+	...
+}
+
+#nonNegativeInt: int & >=0
+
+#simpleTypes: "array" | "boolean" | "integer" | "null" | "number" | "object" | "string"
+`
@@ -0,0 +1,78 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/encoding/json"
+)
+
+func TestMetaSchema(t *testing.T) {
+	testCases := []struct {
+		dialect string
+		in      string
+		err     string
+	}{{
+		dialect: "draft-07",
+		in:      `{"type": ["string", "null"], "properties": {"a": true}, "x-ext": 1}`,
+	}, {
+		dialect: "draft-07",
+		in:      `{"exclusiveMinimum": true}`,
+		err:     "exclusiveMinimum",
+	}, {
+		dialect: "draft-07",
+		in:      `{"properties": {"a": {"minLength": -1}}}`,
+		err:     "properties.a.minLength",
+	}, {
+		dialect: "draft-04",
+		in:      `{"id": "x", "maximum": 3, "exclusiveMaximum": true}`,
+	}, {
+		dialect: "draft-04",
+		in:      `{"properties": {"a": true}}`,
+		err:     "properties.a",
+	}, {
+		dialect: "draft-04",
+		in:      `{"required": []}`,
+		err:     "required",
+	}, {
+		dialect: "2020-12",
+		err:     `unsupported dialect "2020-12"`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.dialect, func(t *testing.T) {
+			ctx := cuecontext.New()
+			schema, err := MetaSchema(ctx, tc.dialect)
+			if err == nil {
+				expr, jerr := json.Extract("in.json", []byte(tc.in))
+				if jerr != nil {
+					t.Fatal(jerr)
+				}
+				v := ctx.BuildExpr(expr).Unify(schema)
+				err = v.Validate(cue.Concrete(true))
+			}
+			switch {
+			case tc.err == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tc.err != "" && (err == nil || !strings.Contains(errors.Details(err, nil), tc.err)):
+				t.Errorf("got error %v; want %s", err, tc.err)
+			}
+		})
+	}
+}
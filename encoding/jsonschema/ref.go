@@ -22,6 +22,7 @@ import (
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal"
@@ -81,7 +82,7 @@ func (s *state) resolveURI(n cue.Value) *url.URL {
 
 	for {
 		if s.id != nil {
-			u = s.id.ResolveReference(u)
+			u = resolveReference(s.id, u)
 			break
 		}
 		if s.up == nil {
@@ -93,6 +94,22 @@ func (s *state) resolveURI(n cue.Value) *url.URL {
 	return u
 }
 
+// resolveReference resolves u against base. Unlike url.URL.ResolveReference,
+// it keeps the result relative if base is a relative path, as is common for
+// the IDs of local files.
+func resolveReference(base, u *url.URL) *url.URL {
+	if base.Scheme != "" || base.Host != "" || strings.HasPrefix(base.Path, "/") {
+		return base.ResolveReference(u)
+	}
+	b := *base
+	b.Path = "/" + b.Path
+	r := b.ResolveReference(u)
+	if r.Scheme == "" && r.Host == "" && !strings.HasPrefix(u.Path, "/") {
+		r.Path = strings.TrimPrefix(r.Path, "/")
+	}
+	return r
+}
+
 const topSchema = "_schema"
 
 // makeCUERef converts a URI into a CUE reference for the current location.
@@ -157,34 +174,18 @@ func (s *state) makeCUERef(n cue.Value, u *url.URL) ast.Expr {
 
 				ident, a = s.getNextIdent(n, a)
 
-			case u.Host != "":
+			case u.Host != "" || s.cfg.ImportPath != nil:
 				// Reference not found within scope. Create an import reference.
 
-				// TODO: allow the configuration to specify a map from
-				// URI domain+paths to CUE packages.
-
 				// TODO: currently only $ids that are in scope can be
 				// referenced. We could consider doing an extra pass to record
 				// all '$id's in a file to be able to link to them even if they
 				// are not in scope.
-				p := u.Path
-
-				base := path.Base(p)
-				if !ast.IsValidIdent(base) {
-					if strings.HasSuffix(base, ".json") {
-						base = base[:len(base)-len(".json")]
-					}
-					if !ast.IsValidIdent(base) {
-						// Find something more clever to do there. For now just
-						// pick "schema" as the package name.
-						base = "schema"
-					}
-					p += ":" + base
+				ident = s.importRef(n, u)
+				if ident == nil {
+					return nil
 				}
 
-				ident = ast.NewIdent(base)
-				ident.Node = &ast.ImportSpec{Path: ast.NewString(u.Host + p)}
-
 			default:
 				// Just a path, not sure what that means.
 				s.errf(n, "unknown domain for reference %q", u)
@@ -243,6 +244,55 @@ func (s *state) makeCUERef(n cue.Value, u *url.URL) ast.Expr {
 	return s.newSel(ident, n, a)
 }
 
+// importRef returns an identifier for the package holding the document
+// referred to by u. The document is recorded so that it can be converted by
+// ExtractBundle.
+func (d *decoder) importRef(n cue.Value, u *url.URL) *ast.Ident {
+	doc := *u
+	doc.Fragment = ""
+
+	p, err := importPath(d.cfg, &doc)
+	if err != nil {
+		d.errf(n, "invalid reference %q: %v", u, err)
+		return nil
+	}
+
+	spec := &ast.ImportSpec{Path: ast.NewString(p)}
+	info, err := astutil.ParseImportSpec(spec)
+	if err != nil || !ast.IsValidIdent(info.Ident) {
+		d.errf(n, "invalid import path %q for reference %q", p, u)
+		return nil
+	}
+
+	if d.external == nil {
+		d.external = map[string]*url.URL{}
+	}
+	d.external[doc.String()] = &doc
+
+	ident := ast.NewIdent(info.Ident)
+	ident.Node = spec
+	return ident
+}
+
+// defaultImportPath derives an import path from the host and path of u.
+func defaultImportPath(u *url.URL) string {
+	p := u.Path
+
+	base := path.Base(p)
+	if !ast.IsValidIdent(base) {
+		if strings.HasSuffix(base, ".json") {
+			base = base[:len(base)-len(".json")]
+		}
+		if !ast.IsValidIdent(base) {
+			// Find something more clever to do there. For now just
+			// pick "schema" as the package name.
+			base = "schema"
+		}
+		p += ":" + base
+	}
+	return u.Host + p
+}
+
 // getNextSelector translates a JSON Reference path into a CUE path by consuming
 // the first path elements and returning the corresponding CUE label.
 func (s *state) getNextSelector(v cue.Value, a []string) (l label, tail []string) {
@@ -58,6 +58,13 @@ type buildContext struct {
 	// TODO: consider an option in the CUE API where optional fields are
 	// recursively evaluated.
 	cycleNodes []*adt.Vertex
+
+	// maxCycleDepth is the number of times a recursive reference may be
+	// expanded. expanded records, by path, the referenced values that were
+	// expanded and deps caches the values referenced by a node.
+	maxCycleDepth int
+	expanded      map[string][]string
+	deps          map[*adt.Vertex][]string
 }
 
 type externalType struct {
@@ -94,16 +101,19 @@ func schemas(g *Generator, inst *cue.Instance) (schemas *ast.StructLit, err erro
 	}
 
 	c := buildContext{
-		inst:         inst,
-		instExt:      inst,
-		refPrefix:    "components/schemas",
-		expandRefs:   g.ExpandReferences,
-		structural:   g.ExpandReferences,
-		nameFunc:     g.ReferenceFunc,
-		descFunc:     g.DescriptionFunc,
-		schemas:      &OrderedMap{},
-		externalRefs: map[string]*externalType{},
-		fieldFilter:  fieldFilter,
+		inst:          inst,
+		instExt:       inst,
+		refPrefix:     "components/schemas",
+		expandRefs:    g.ExpandReferences,
+		structural:    g.ExpandReferences,
+		nameFunc:      g.ReferenceFunc,
+		descFunc:      g.DescriptionFunc,
+		schemas:       &OrderedMap{},
+		externalRefs:  map[string]*externalType{},
+		fieldFilter:   fieldFilter,
+		maxCycleDepth: g.MaxCycleDepth,
+		expanded:      map[string][]string{},
+		deps:          map[*adt.Vertex][]string{},
 	}
 
 	switch g.Version {
@@ -255,6 +265,7 @@ func (b *builder) fillSchema(v cue.Value) *ast.StructLit {
 	b.setValueType(v)
 	b.format = extractFormat(v)
 	b.deprecated = getDeprecated(v)
+	b.discriminator = getDiscriminator(v)
 
 	if b.core == nil || len(b.core.values) > 1 {
 		isRef := b.value(v, nil)
@@ -269,6 +280,7 @@ func (b *builder) fillSchema(v cue.Value) *ast.StructLit {
 
 	schema := b.finish()
 	s := (*ast.StructLit)(schema)
+	s.Elts = append(s.Elts, extensions(v)...)
 
 	simplify(b, s)
 
@@ -323,6 +335,10 @@ var fieldOrder = map[string]int{
 }
 
 func (b *builder) value(v cue.Value, f typeFunc) (isRef bool) {
+	if !b.enterCycle(v) {
+		return false
+	}
+
 	b.pushNode(v)
 	defer b.popNode()
 
@@ -612,6 +628,38 @@ func (b *builder) disjunction(a []cue.Value, f typeFunc) {
 	}
 
 	b.set("oneOf", ast.NewList(anyOf...))
+
+	if b.discriminator != "" && !b.isNonCore() {
+		b.setDiscriminator(disjuncts)
+	}
+}
+
+// setDiscriminator adds a discriminator object for the given disjuncts. Each
+// disjunct that refers to a schema is added to the mapping using the value
+// of its discriminating property.
+func (b *builder) setDiscriminator(disjuncts []cue.Value) {
+	name := b.discriminator
+	mapping := &OrderedMap{}
+	for _, v := range disjuncts {
+		str, err := v.LookupPath(cue.MakePath(cue.Str(name))).String()
+		if err != nil {
+			b.failf(v, "discriminator %q must be a concrete string in all disjuncts", name)
+		}
+		inst, r := v.Reference()
+		if len(r) == 0 {
+			continue
+		}
+		if ref := b.ctx.makeRef(inst, r); ref != "" {
+			mapping.Set(str, ast.NewString(path.Join("#", b.ctx.refPrefix, ref)))
+		}
+	}
+
+	d := &OrderedMap{}
+	d.Set("propertyName", ast.NewString(name))
+	if len(mapping.Elts) > 0 {
+		d.Set("mapping", (*ast.StructLit)(mapping))
+	}
+	b.set("discriminator", (*ast.StructLit)(d))
 }
 
 func (b *builder) setValueType(v cue.Value) {
@@ -1102,6 +1150,10 @@ type builder struct {
 	allOf        []*ast.StructLit
 	deprecated   bool
 
+	// discriminator is the property by which the disjuncts of a oneOf are
+	// distinguished, as given by a discriminator attribute.
+	discriminator string
+
 	// Building structural schema
 	core       *builder
 	kind       cue.Kind
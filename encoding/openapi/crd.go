@@ -109,6 +109,10 @@ func (b *builder) coreSchema() *ast.StructLit {
 // To this extent, all fields of both conjunctions and disjunctions are
 // collected in a single properties map.
 func (b *builder) buildCore(v cue.Value) {
+	if !b.enterCycle(v) {
+		return
+	}
+
 	b.pushNode(v)
 	defer b.popNode()
 
@@ -135,7 +139,7 @@ func (b *builder) buildCore(v cue.Value) {
 				if b.items == nil {
 					b.items = newCoreBuilder(b.ctx)
 				}
-				b.items.buildCore(typ)
+				b.items.buildCoreWithName("*", typ)
 			}
 			b.buildCoreStruct(v)
 
@@ -147,7 +151,7 @@ func (b *builder) buildCore(v cue.Value) {
 				if b.items == nil {
 					b.items = newCoreBuilder(b.ctx)
 				}
-				b.items.buildCore(typ)
+				b.items.buildCoreWithName("*", typ)
 			}
 		}
 	}
@@ -176,6 +180,13 @@ func (b *builder) buildCoreStruct(v cue.Value) {
 			b.properties[label] = sub
 			b.keys = append(b.keys, label)
 		}
-		sub.buildCore(i.Value())
+		sub.buildCoreWithName(label, i.Value())
 	}
 }
+
+func (b *builder) buildCoreWithName(name string, v cue.Value) {
+	oldPath := b.ctx.path
+	b.ctx.path = append(b.ctx.path, name)
+	b.buildCore(v)
+	b.ctx.path = oldPath
+}
@@ -15,6 +15,8 @@
 package openapi
 
 import (
+	"strings"
+
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/token"
@@ -33,7 +35,8 @@ func (b *builder) popNode() {
 }
 
 func (b *builder) checkCycle(v cue.Value) bool {
-	if !b.ctx.expandRefs {
+	if !b.ctx.expandRefs || b.ctx.maxCycleDepth > 0 {
+		// Recursion is bounded by enterCycle.
 		return true
 	}
 	r, n := internalvalue.ToInternal(v)
@@ -57,3 +60,77 @@ func (b *builder) checkCycle(v cue.Value) bool {
 
 	return err == nil
 }
+
+// enterCycle reports whether v, which is generated at the current path, may
+// be expanded. This is not the case if v refers to a value that has already
+// been expanded MaxCycleDepth times at the current path or any of its
+// ancestors.
+//
+// Paths are used to track the expansions, rather than a stack of nodes, as
+// the core and the remainder of a structural schema are generated in
+// separate passes.
+func (b *builder) enterCycle(v cue.Value) bool {
+	if !b.ctx.expandRefs || b.ctx.maxCycleDepth == 0 {
+		return true
+	}
+	deps := b.deps(v)
+	if len(deps) == 0 {
+		return true
+	}
+	for _, d := range deps {
+		n := 1
+		for i := range b.ctx.path {
+			if contains(b.ctx.expanded[pathKey(b.ctx.path[:i])], d) {
+				n++
+			}
+		}
+		if n > b.ctx.maxCycleDepth {
+			return false
+		}
+	}
+	key := pathKey(b.ctx.path)
+	for _, d := range deps {
+		if !contains(b.ctx.expanded[key], d) {
+			b.ctx.expanded[key] = append(b.ctx.expanded[key], d)
+		}
+	}
+	return true
+}
+
+func pathKey(path []string) string {
+	return strings.Join(path, "\x00")
+}
+
+func contains(a []string, s string) bool {
+	for _, x := range a {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// deps reports the paths of the nodes referred to by v. Paths are used
+// instead of the nodes themselves, as the same value may be represented by
+// different nodes during generation.
+func (b *builder) deps(v cue.Value) []string {
+	r, n := internalvalue.ToInternal(v)
+	if n == nil {
+		return nil
+	}
+	if nodes, ok := b.ctx.deps[n]; ok {
+		return nodes
+	}
+	ctx := eval.NewContext(r, n)
+	var nodes []string
+	_ = dep.Visit(ctx, n, func(d dep.Dependency) error {
+		var path []string
+		for _, f := range d.Node.Path() {
+			path = append(path, f.SelectorString(ctx))
+		}
+		nodes = append(nodes, strings.Join(path, "."))
+		return nil
+	})
+	b.ctx.deps[n] = nodes
+	return nodes
+}
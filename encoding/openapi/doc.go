@@ -15,7 +15,30 @@
 // Package openapi provides functionality for mapping CUE to and from
 // OpenAPI v3.0.0.
 //
-// It currently handles OpenAPI Schema components only.
+// Definitions are converted to OpenAPI Schema components. The other parts
+// of a document are taken from the regular top-level fields: info, servers,
+// paths, security, tags, externalDocs, components other than schemas, such
+// as securitySchemes, and any extensions starting with x-. Except for info,
+// these fields must be concrete.
+//
+// An @openapi attribute on a path item or operation may add metadata to it:
+//
+//	paths: "/pets": get: {...} @openapi(tag=pets, summary="List pets", security=apiKey)
+//
+// An @openapi attribute on a definition or field may add extensions to its
+// schema:
+//
+//	#Pet: {...} @openapi(x-go-type="pets.Pet")
+//
+// Fields marked with a deprecated attribute are marked as deprecated in the
+// generated schema. A disjunction marked with a discriminator attribute is
+// generated as a oneOf with a discriminator object naming the given property:
+//
+//	#Shape: #Circle | #Square @discriminator(kind)
+//
+// Each disjunct must have a concrete string value for this property.
+// Disjuncts that refer to a named schema are listed in the mapping of the
+// discriminator.
 //
 // See https://github.com/OAI/OpenAPI-Specification/blob/master/versions/3.0.0.md#schemaObject.
 package openapi
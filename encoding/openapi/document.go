@@ -0,0 +1,188 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	cuejson "cuelang.org/go/encoding/json"
+)
+
+// operations lists the fields of a Path Item Object that are operations.
+var operations = map[string]bool{
+	"get":     true,
+	"put":     true,
+	"post":    true,
+	"delete":  true,
+	"options": true,
+	"head":    true,
+	"patch":   true,
+	"trace":   true,
+}
+
+// concrete returns the syntax of the concrete value v.
+func concrete(v cue.Value) (ast.Expr, errors.Error) {
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return nil, errors.Promote(err, "")
+	}
+	return v.Syntax(cue.Final(), cue.Concrete(true), cue.Attributes(false)).(ast.Expr), nil
+}
+
+// paths returns the Paths Object for v, adding the metadata specified by
+// @openapi attributes on path items and their operations.
+func paths(v cue.Value) (*ast.StructLit, errors.Error) {
+	var errs errors.Error
+	m := &OrderedMap{}
+	for i, _ := v.Fields(); i.Next(); {
+		item, err := concrete(i.Value())
+		if err != nil {
+			errs = errors.Append(errs, err)
+			continue
+		}
+		s, ok := item.(*ast.StructLit)
+		if !ok {
+			errs = errors.Append(errs, errors.Newf(i.Value().Pos(),
+				"openapi: path %s must be a struct", i.Label()))
+			continue
+		}
+		itemMap := (*OrderedMap)(s)
+		errs = errors.Append(errs, setAttrs(itemMap, i.Value(), false))
+
+		for j, _ := i.Value().Fields(); j.Next(); {
+			if !operations[j.Label()] {
+				continue
+			}
+			op := itemMap.getMap(j.Label())
+			if op == nil {
+				errs = errors.Append(errs, errors.Newf(j.Value().Pos(),
+					"openapi: operation %s %s must be a struct", j.Label(), i.Label()))
+				continue
+			}
+			errs = errors.Append(errs, setAttrs(op, j.Value(), true))
+		}
+		m.setExpr(i.Label(), s)
+	}
+	return (*ast.StructLit)(m), errs
+}
+
+// setAttrs adds the metadata of the @openapi attribute of v to m. Extensions,
+// keys starting with x-, are allowed for any object. For operations, the
+// following keys are supported as well:
+//
+//	tag=name          adds name to the tags of the operation
+//	summary=text      sets the summary
+//	description=text  sets the description
+//	operationId=id    sets the operation ID
+//	security=scheme   adds a security requirement for the given scheme
+//	server=url        adds a server with the given URL
+//	deprecated        marks the operation as deprecated
+//
+// It is an error to set a field both in CUE and with an attribute.
+func setAttrs(m *OrderedMap, v cue.Value, isOperation bool) (errs errors.Error) {
+	attr := v.Attribute("openapi")
+	if attr.Err() != nil {
+		return nil
+	}
+	set := map[string]bool{}
+	add := func(key string, x ast.Expr, isList bool) {
+		if !set[key] && m.exists(key) {
+			errs = errors.Append(errs, errors.Newf(v.Pos(),
+				"openapi: %s set by both field and attribute", key))
+			return
+		}
+		set[key] = true
+		if !isList {
+			m.setExpr(key, x)
+			return
+		}
+		if f := m.find(key); f != nil {
+			list := f.Value.(*ast.ListLit)
+			list.Elts = append(list.Elts, x)
+			return
+		}
+		m.setExpr(key, ast.NewList(x))
+	}
+	for i := 0; i < attr.NumArgs(); i++ {
+		key, value := attr.Arg(i)
+		switch {
+		case strings.HasPrefix(key, "x-"):
+			add(key, attrValue(value), false)
+
+		case !isOperation:
+			errs = errors.Append(errs, errors.Newf(v.Pos(),
+				"openapi: unsupported attribute key %q for path item", key))
+
+		case key == "tag":
+			add("tags", ast.NewString(attrString(value)), true)
+		case key == "summary", key == "description", key == "operationId":
+			add(key, ast.NewString(attrString(value)), false)
+		case key == "security":
+			add("security", ast.NewStruct(attrString(value), ast.NewList()), true)
+		case key == "server":
+			add("servers", ast.NewStruct("url", ast.NewString(attrString(value))), true)
+		case key == "deprecated":
+			add(key, ast.NewBool(true), false)
+
+		default:
+			errs = errors.Append(errs, errors.Newf(v.Pos(),
+				"openapi: unsupported attribute key %q for operation", key))
+		}
+	}
+	return errs
+}
+
+// extensions returns the extensions specified by the @openapi attribute of v
+// as fields.
+func extensions(v cue.Value) (a []ast.Decl) {
+	attr := v.Attribute("openapi")
+	if attr.Err() != nil {
+		return nil
+	}
+	for i := 0; i < attr.NumArgs(); i++ {
+		key, value := attr.Arg(i)
+		if strings.HasPrefix(key, "x-") {
+			a = append(a, &ast.Field{
+				Label: ast.NewString(key),
+				Value: attrValue(value),
+			})
+		}
+	}
+	return a
+}
+
+// attrValue interprets the value of an attribute argument as JSON, or as a
+// string if it is not valid JSON.
+func attrValue(s string) ast.Expr {
+	if json.Valid([]byte(s)) {
+		if x, err := cuejson.Extract("attribute", []byte(s)); err == nil {
+			return x
+		}
+	}
+	return ast.NewString(s)
+}
+
+// attrString returns the value of an attribute argument as a string,
+// unquoting it if it is quoted.
+func attrString(s string) string {
+	if u, err := strconv.Unquote(s); err == nil {
+		return u
+	}
+	return s
+}
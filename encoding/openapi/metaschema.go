@@ -0,0 +1,346 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package openapi
+
+import (
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// MetaSchema returns a schema for OpenAPI documents of the given version of
+// the specification. Only version 3.0 is currently supported.
+//
+// The schema is a CUE rendition of the published schema for the version.
+// It checks the structure of a document, but not, for instance, whether its
+// references resolve.
+func MetaSchema(ctx *cue.Context, version string) (cue.Value, error) {
+	if version != "3.0" {
+		return cue.Value{}, errors.Newf(token.NoPos,
+			"openapi: unsupported version %q for meta-schema", version)
+	}
+	v := ctx.CompileString(metaSchema30, cue.Filename("openapi-3.0.cue"))
+	v = v.LookupPath(cue.ParsePath("#Document"))
+	return v, v.Err()
+}
+
+const metaSchema30 = `
+#Document: {
+	openapi:       =~"^3\\.0\\.\\d+(-.+)?$"
+	info:          #Info
+	externalDocs?: #ExternalDocumentation
+	servers?: [...#Server]
+	security?: [...#SecurityRequirement]
+	tags?: [...#Tag]
+	paths:       #Paths
+	components?: #Components
+	[=~"^x-"]:   _
+}
+
+#Info: {
+	title:           string
+	description?:    string
+	termsOfService?: string
+	contact?:        #Contact
+	license?:        #License
+	version:         string
+	[=~"^x-"]:       _
+}
+
+#Contact: {
+	name?:     string
+	url?:      string
+	email?:    string
+	[=~"^x-"]: _
+}
+
+#License: {
+	name:      string
+	url?:      string
+	[=~"^x-"]: _
+}
+
+#Server: {
+	url:          string
+	description?: string
+	variables?: [string]: #ServerVariable
+	[=~"^x-"]: _
+}
+
+#ServerVariable: {
+	enum?: [...string]
+	default:      string
+	description?: string
+	[=~"^x-"]:    _
+}
+
+#Components: {
+	schemas?: [=~#name]:         #Schema | #Reference
+	responses?: [=~#name]:       #Response | #Reference
+	parameters?: [=~#name]:      #Parameter | #Reference
+	examples?: [=~#name]:        #Example | #Reference
+	requestBodies?: [=~#name]:   #RequestBody | #Reference
+	headers?: [=~#name]:         #Header | #Reference
+	securitySchemes?: [=~#name]: #SecurityScheme | #Reference
+	links?: [=~#name]:           #Link | #Reference
+	callbacks?: [=~#name]:       #Callback | #Reference
+	[=~"^x-"]:                   _
+
+	#name: "^[a-zA-Z0-9._-]+$"
+}
+
+#Schema: {
+	title?:                string
+	multipleOf?:           number & >0
+	maximum?:              number
+	exclusiveMaximum?:     bool
+	minimum?:              number
+	exclusiveMinimum?:     bool
+	maxLength?:            int & >=0
+	minLength?:            int & >=0
+	pattern?:              string
+	maxItems?:             int & >=0
+	minItems?:             int & >=0
+	uniqueItems?:          bool
+	maxProperties?:        int & >=0
+	minProperties?:        int & >=0
+	required?: [string, ...string]
+	enum?: [_, ...]
+	type?:                 "array" | "boolean" | "integer" | "number" | "object" | "string"
+	not?:                  #Schema | #Reference
+	allOf?: [...(#Schema | #Reference)]
+	oneOf?: [...(#Schema | #Reference)]
+	anyOf?: [...(#Schema | #Reference)]
+	items?:                #Schema | #Reference
+	properties?: [string]: #Schema | #Reference
+	additionalProperties?: #Schema | #Reference | bool
+	description?:          string
+	format?:               string
+	default?:              _
+	nullable?:             bool
+	discriminator?:        #Discriminator
+	readOnly?:             bool
+	writeOnly?:            bool
+	example?:              _
+	externalDocs?:         #ExternalDocumentation
+	deprecated?:           bool
+	xml?:                  #XML
+	[=~"^x-"]:             _
+}
+
+#Discriminator: {
+	propertyName: string
+	mapping?: [string]: string
+}
+
+#XML: {
+	name?:      string
+	namespace?: string
+	prefix?:    string
+	attribute?: bool
+	wrapped?:   bool
+	[=~"^x-"]:  _
+}
+
+#Reference: $ref: string
+
+#Paths: {
+	[=~"^/"]:  #PathItem
+	[=~"^x-"]: _
+}
+
+#PathItem: {
+	$ref?:        string
+	summary?:     string
+	description?: string
+	servers?: [...#Server]
+	parameters?: [...(#Parameter | #Reference)]
+	get?:      #Operation
+	put?:      #Operation
+	post?:     #Operation
+	delete?:   #Operation
+	options?:  #Operation
+	head?:     #Operation
+	patch?:    #Operation
+	trace?:    #Operation
+	[=~"^x-"]: _
+}
+
+#Operation: {
+	tags?: [...string]
+	summary?:      string
+	description?:  string
+	externalDocs?: #ExternalDocumentation
+	operationId?:  string
+	parameters?: [...(#Parameter | #Reference)]
+	requestBody?: #RequestBody | #Reference
+	responses:    #Responses
+	callbacks?: [string]: #Callback | #Reference
+	deprecated?: bool
+	security?: [...#SecurityRequirement]
+	servers?: [...#Server]
+	[=~"^x-"]: _
+}
+
+#Responses: {
+	default?:                   #Response | #Reference
+	[=~"^[1-5](?:\\d{2}|XX)$"]: #Response | #Reference
+	[=~"^x-"]:                  _
+}
+
+#Response: {
+	description: string
+	headers?: [string]: #Header | #Reference
+	content?: [string]: #MediaType
+	links?: [string]:   #Link | #Reference
+	[=~"^x-"]: _
+}
+
+#MediaType: {
+	schema?:  #Schema | #Reference
+	example?: _
+	examples?: [string]: #Example | #Reference
+	encoding?: [string]: #Encoding
+	[=~"^x-"]: _
+}
+
+#Example: {
+	summary?:       string
+	description?:   string
+	value?:         _
+	externalValue?: string
+	[=~"^x-"]:      _
+}
+
+#Encoding: {
+	contentType?: string
+	headers?: [string]: #Header | #Reference
+	style?:         "form" | "spaceDelimited" | "pipeDelimited" | "deepObject"
+	explode?:       bool
+	allowReserved?: bool
+	[=~"^x-"]:      _
+}
+
+#Header: {
+	description?:     string
+	required?:        bool
+	deprecated?:      bool
+	allowEmptyValue?: bool
+	style?:           "simple"
+	explode?:         bool
+	allowReserved?:   bool
+	schema?:          #Schema | #Reference
+	content?: [string]:  #MediaType
+	example?: _
+	examples?: [string]: #Example | #Reference
+	[=~"^x-"]: _
+}
+
+#Parameter: {
+	name:             string
+	in:               "query" | "header" | "path" | "cookie"
+	description?:     string
+	required?:        bool
+	deprecated?:      bool
+	allowEmptyValue?: bool
+	style?:           "matrix" | "label" | "form" | "simple" | "spaceDelimited" | "pipeDelimited" | "deepObject"
+	explode?:         bool
+	allowReserved?:   bool
+	schema?:          #Schema | #Reference
+	content?: [string]:  #MediaType
+	example?: _
+	examples?: [string]: #Example | #Reference
+	[=~"^x-"]: _
+}
+
+#RequestBody: {
+	description?: string
+	content: [string]: #MediaType
+	required?: bool
+	[=~"^x-"]: _
+}
+
+#SecurityScheme: {
+	type:              "apiKey" | "http" | "oauth2" | "openIdConnect"
+	description?:      string
+	name?:             string
+	in?:               "query" | "header" | "cookie"
+	scheme?:           string
+	bearerFormat?:     string
+	flows?:            #OAuthFlows
+	openIdConnectUrl?: string
+	[=~"^x-"]:         _
+
+	if type == "apiKey" {
+		name: string
+		in:   "query" | "header" | "cookie"
+	}
+	if type == "http" {
+		scheme: string
+	}
+	if type == "oauth2" {
+		flows: #OAuthFlows
+	}
+	if type == "openIdConnect" {
+		openIdConnectUrl: string
+	}
+}
+
+#OAuthFlows: {
+	implicit?:          #OAuthFlow & {authorizationUrl: string}
+	password?:          #OAuthFlow & {tokenUrl: string}
+	clientCredentials?: #OAuthFlow & {tokenUrl: string}
+	authorizationCode?: #OAuthFlow & {authorizationUrl: string, tokenUrl: string}
+	[=~"^x-"]:          _
+}
+
+#OAuthFlow: {
+	authorizationUrl?: string
+	tokenUrl?:         string
+	refreshUrl?:       string
+	scopes: [string]: string
+	[=~"^x-"]: _
+}
+
+#Link: {
+	operationRef?: string
+	operationId?:  string
+	parameters?: [string]: _
+	requestBody?: _
+	description?: string
+	server?:      #Server
+	[=~"^x-"]:    _
+}
+
+#Callback: {
+	[!~"^x-"]: #PathItem
+	[=~"^x-"]: _
+}
+
+#Tag: {
+	name:          string
+	description?:  string
+	externalDocs?: #ExternalDocumentation
+	[=~"^x-"]:     _
+}
+
+#ExternalDocumentation: {
+	description?: string
+	url:          string
+	[=~"^x-"]:    _
+}
+
+#SecurityRequirement: [string]: [...string]
+`
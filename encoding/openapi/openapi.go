@@ -64,8 +64,14 @@ type Config struct {
 
 	// ExpandReferences replaces references with actual objects when generating
 	// OpenAPI Schema. It is an error for an CUE value to refer to itself
-	// if this option is used.
+	// if this option is used, unless MaxCycleDepth is set.
 	ExpandReferences bool
+
+	// MaxCycleDepth bounds the expansion of recursive references when
+	// ExpandReferences is set. A value that refers to itself is expanded at
+	// most MaxCycleDepth times, after which the schema is truncated to its
+	// type, if known, accepting any value of that type.
+	MaxCycleDepth int
 }
 
 type Generator = Config
@@ -131,6 +137,9 @@ func (c *Config) compose(inst *cue.Instance, schemas *ast.StructLit) (x *ast.Str
 
 	var title, version string
 	var info *ast.StructLit
+	var pathsLit *ast.StructLit
+	var components, exts []ast.Decl
+	top := map[string]ast.Expr{}
 
 	for i, _ := inst.Value().Fields(cue.Definitions(true)); i.Next(); {
 		if i.IsDefinition() {
@@ -138,8 +147,11 @@ func (c *Config) compose(inst *cue.Instance, schemas *ast.StructLit) (x *ast.Str
 		}
 		label := i.Label()
 		attr := i.Value().Attribute("openapi")
-		if s, _ := attr.String(0); s != "" {
-			label = s
+		if attr.NumArgs() > 0 {
+			// Only an argument without a key renames the field.
+			if key, value := attr.Arg(0); value == "" && !strings.HasPrefix(key, "x-") {
+				label = key
+			}
 		}
 		switch label {
 		case "$version":
@@ -153,9 +165,50 @@ func (c *Config) compose(inst *cue.Instance, schemas *ast.StructLit) (x *ast.Str
 			title, _ = i.Value().Lookup("title").String()
 			version, _ = i.Value().Lookup("version").String()
 
+		case "servers", "security", "tags", "externalDocs":
+			if x, err := concrete(i.Value()); err != nil {
+				errs = errors.Append(errs, err)
+			} else {
+				top[label] = x
+			}
+
+		case "paths":
+			var err errors.Error
+			pathsLit, err = paths(i.Value())
+			errs = errors.Append(errs, err)
+
+		case "components":
+			// Schemas are generated from definitions, but other components,
+			// like security schemes, may be given verbatim.
+			for j, _ := i.Value().Fields(); j.Next(); {
+				if j.Label() == "schemas" {
+					errs = errors.Append(errs, errors.Newf(j.Value().Pos(),
+						"openapi: components.schemas is generated from definitions"))
+					continue
+				}
+				x, err := concrete(j.Value())
+				if err != nil {
+					errs = errors.Append(errs, err)
+					continue
+				}
+				components = append(components, &ast.Field{
+					Label: ast.NewString(j.Label()),
+					Value: x,
+				})
+			}
+
 		default:
-			errs = errors.Append(errs, errors.Newf(i.Value().Pos(),
-				"openapi: unsupported top-level field %q", label))
+			if !strings.HasPrefix(label, "x-") {
+				errs = errors.Append(errs, errors.Newf(i.Value().Pos(),
+					"openapi: unsupported top-level field %q", label))
+				break
+			}
+			x, err := concrete(i.Value())
+			if err != nil {
+				errs = errors.Append(errs, err)
+				break
+			}
+			exts = append(exts, &ast.Field{Label: ast.NewString(label), Value: x})
 		}
 	}
 
@@ -207,12 +260,27 @@ func (c *Config) compose(inst *cue.Instance, schemas *ast.StructLit) (x *ast.Str
 			"Info field supplied must be an *ast.StructLit"))
 	}
 
-	return ast.NewStruct(
-		"openapi", ast.NewString(c.Version),
-		"info", info,
-		"paths", ast.NewStruct(),
-		"components", ast.NewStruct("schemas", schemas),
-	), errs
+	if pathsLit == nil {
+		pathsLit = ast.NewStruct()
+	}
+	comps := ast.NewStruct("schemas", schemas)
+	comps.Elts = append(comps.Elts, components...)
+
+	doc := &OrderedMap{}
+	doc.setExpr("openapi", ast.NewString(c.Version))
+	doc.setExpr("info", info)
+	if x, ok := top["servers"]; ok {
+		doc.setExpr("servers", x)
+	}
+	doc.setExpr("paths", pathsLit)
+	doc.setExpr("components", comps)
+	for _, key := range []string{"security", "tags", "externalDocs"} {
+		if x, ok := top[key]; ok {
+			doc.setExpr(key, x)
+		}
+	}
+	doc.Elts = append(doc.Elts, exts...)
+	return (*ast.StructLit)(doc), errs
 }
 
 // Schemas extracts component/schemas from the CUE top-level types.
@@ -96,6 +96,14 @@ func TestParseDefinitions(t *testing.T) {
 		in:     "oneof.cue",
 		out:    "oneof-resolve.json",
 		config: resolveRefs,
+	}, {
+		in:     "discriminator.cue",
+		out:    "discriminator.json",
+		config: defaultConfig,
+	}, {
+		in:     "discriminator.cue",
+		out:    "discriminator-resolve.json",
+		config: resolveRefs,
 	}, {
 		in:     "openapi.cue",
 		out:    "openapi.json",
@@ -104,6 +112,10 @@ func TestParseDefinitions(t *testing.T) {
 		in:     "openapi.cue",
 		out:    "openapi-norefs.json",
 		config: resolveRefs,
+	}, {
+		in:     "operations.cue",
+		out:    "operations.json",
+		config: defaultConfig,
 	}, {
 		in:     "embed.cue",
 		out:    "embed.json",
@@ -151,6 +163,14 @@ func TestParseDefinitions(t *testing.T) {
 		in:     "cycle.cue",
 		config: &openapi.Config{Info: info, ExpandReferences: true},
 		err:    "cycle",
+	}, {
+		in:     "cycle.cue",
+		out:    "cycle-depth.json",
+		config: &openapi.Config{Info: info, ExpandReferences: true, MaxCycleDepth: 1},
+	}, {
+		in:     "recursive.cue",
+		out:    "recursive.json",
+		config: &openapi.Config{Info: info, ExpandReferences: true, MaxCycleDepth: 2},
 	}}
 	for _, tc := range testCases {
 		t.Run(tc.out, func(t *testing.T) {
@@ -234,6 +254,44 @@ func TestIssue1234(t *testing.T) {
 	}
 }
 
+func TestDocumentErrors(t *testing.T) {
+	testCases := []struct {
+		in  string
+		err string
+	}{{
+		in:  `servers: [{url: string}]`,
+		err: "incomplete value string",
+	}, {
+		in:  `components: schemas: Foo: {}`,
+		err: "components.schemas is generated from definitions",
+	}, {
+		in:  `paths: "/pets": get: {summary: "x"} @openapi(summary=y)`,
+		err: "summary set by both field and attribute",
+	}, {
+		in:  `paths: "/pets": get: {} @openapi(foo=bar)`,
+		err: `unsupported attribute key "foo" for operation`,
+	}, {
+		in:  `paths: "/pets": {} @openapi(tag=pets)`,
+		err: `unsupported attribute key "tag" for path item`,
+	}, {
+		in:  `foo: 1`,
+		err: `unsupported top-level field "foo"`,
+	}}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			var r cue.Runtime
+			inst, err := r.Compile("test", tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = openapi.Generate(inst, &openapi.Config{})
+			if err == nil || !strings.Contains(errors.Details(err, nil), tc.err) {
+				t.Errorf("got error %v; want %s", err, tc.err)
+			}
+		})
+	}
+}
+
 // This is for debugging purposes. Do not remove.
 func TestX(t *testing.T) {
 	t.Skip()
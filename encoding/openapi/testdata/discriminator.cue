@@ -0,0 +1,17 @@
+// A shape is selected by its kind.
+#Shape: #Circle | #Square @discriminator(kind)
+
+#Circle: {
+	kind:   "circle"
+	radius: number
+}
+
+#Square: {
+	kind: "square"
+	side: number
+}
+
+#Drawing: {
+	shapes: [...#Shape]
+	background?: #Circle | #Square @discriminator(kind)
+}
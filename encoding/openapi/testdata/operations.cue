@@ -0,0 +1,43 @@
+// A pet store.
+package operations
+
+info: version: "v1"
+
+servers: [{url: "https://pets.example.com/v1"}]
+
+security: [{apiKey: []}]
+
+tags: [{name: "pets", description: "Everything about pets"}]
+
+externalDocs: url: "https://pets.example.com/docs"
+
+"x-audience": "public"
+
+components: securitySchemes: apiKey: {
+	type: "apiKey"
+	name: "X-API-Key"
+	in:   "header"
+}
+
+paths: "/pets": {
+	get: {
+		responses: "200": {
+			description: "A list of pets."
+			content: "application/json": schema: {
+				type: "array"
+				items: $ref: "#/components/schemas/Pet"
+			}
+		}
+	} @openapi(tag=pets, summary="List all pets", operationId=listPets, x-rate-limit=100)
+
+	post: {
+		tags: ["pets"]
+		responses: "201": description: "Pet created."
+	} @openapi(security=apiKey, server="https://admin.example.com/v1", deprecated, x-internal=true)
+} @openapi(x-owner=pets-team)
+
+// A pet.
+#Pet: {
+	name: string @openapi(x-order=1)
+	tag?: string
+} @openapi(x-go-type="pets.Pet")
@@ -0,0 +1,8 @@
+#Node: {
+	name: string
+
+	// next is expanded up to the maximum depth.
+	next?: #Node
+
+	children?: [...#Node]
+}
@@ -6,4 +6,5 @@
 	double: float64
 
 	deprecatedField: string @protobuf(5,deprecated)
+	oldField:        string @deprecated("use deprecatedField")
 }
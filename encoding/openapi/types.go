@@ -76,12 +76,27 @@ func extractFormat(v cue.Value) string {
 }
 
 func getDeprecated(v cue.Value) bool {
-	// only looking at protobuf attribute for now.
+	if a := v.Attribute("deprecated"); a.Err() == nil {
+		return true
+	}
 	a := v.Attribute("protobuf")
 	r, _ := a.Flag(1, "deprecated")
 	return r
 }
 
+// getDiscriminator reports the property given by a discriminator attribute,
+// such as
+//
+//	#Shape: #Circle | #Square @discriminator(kind)
+func getDiscriminator(v cue.Value) string {
+	a := v.Attribute("discriminator")
+	if a.Err() != nil {
+		return ""
+	}
+	s, _ := a.String(0)
+	return s
+}
+
 func simplify(b *builder, t *ast.StructLit) {
 	if b.format == "" {
 		return
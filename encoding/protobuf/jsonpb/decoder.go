@@ -123,6 +123,15 @@ func (r *rewriter) rewriteDecls(schema cue.Value, decls []ast.Decl) {
 		}
 
 		v := schema.LookupPath(cue.MakePath(sel))
+		if !v.Exists() {
+			// The Protobuf JSON mapping also accepts the original name of a
+			// field, which is recorded in its attribute if it differs from
+			// the label.
+			if label, x := lookupProtoName(schema, sel.String()); x.Exists() {
+				field.Label = label
+				v = x
+			}
+		}
 		if !v.Exists() {
 			f := schema.Template()
 			if f == nil {
@@ -138,6 +147,22 @@ func (r *rewriter) rewriteDecls(schema cue.Value, decls []ast.Decl) {
 	}
 }
 
+// lookupProtoName returns the label and value of the field of schema with
+// the given Protobuf name, if it differs from its label.
+func lookupProtoName(schema cue.Value, name string) (ast.Label, cue.Value) {
+	for i, _ := schema.Fields(cue.Optional(true)); i.Next(); {
+		info, err := pbinternal.FromIter(i)
+		if err != nil || info.Name != name || info.Name == info.CUEName {
+			continue
+		}
+		if ast.IsValidIdent(info.CUEName) {
+			return ast.NewIdent(info.CUEName), i.Value()
+		}
+		return ast.NewString(info.CUEName), i.Value()
+	}
+	return nil, cue.Value{}
+}
+
 var enumValuePath = cue.ParsePath("#enumValue").Optional()
 
 func (r *rewriter) rewrite(schema cue.Value, expr ast.Expr) (x ast.Expr) {
@@ -21,6 +21,7 @@ import (
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/cuetxtar"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/cue/parser"
@@ -28,7 +29,6 @@ import (
 	"cuelang.org/go/encoding/protobuf/jsonpb"
 	"cuelang.org/go/encoding/yaml"
 	"cuelang.org/go/internal/cuetest"
-	"cuelang.org/go/internal/cuetxtar"
 )
 
 func TestParse(t *testing.T) {
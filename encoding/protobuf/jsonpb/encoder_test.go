@@ -19,12 +19,12 @@ import (
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/cuetxtar"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/encoding/protobuf/jsonpb"
 	"cuelang.org/go/internal/cuetest"
-	"cuelang.org/go/internal/cuetxtar"
 )
 
 func TestEncoder(t *testing.T) {
@@ -312,16 +312,31 @@ func (p *protoConverter) doImport(v *proto.Import) error {
 		break
 	}
 
+	for _, r := range p.state.resolve {
+		if filename != "" {
+			break
+		}
+		name, err := r.ResolveImport(v.Filename)
+		if err != nil {
+			err := errors.Newf(p.toCUEPos(v.Position),
+				"could not resolve import %q: %v", v.Filename, err)
+			p.state.addErr(err)
+			return err
+		}
+		filename = name
+	}
+
+	if !p.mapBuiltinPackage(v.Position, v.Filename, false) {
+		// Well-known types are mapped to CUE builtins and need no file.
+		return nil
+	}
+
 	if filename == "" {
 		err := errors.Newf(p.toCUEPos(v.Position), "could not find import %q", v.Filename)
 		p.state.addErr(err)
 		return err
 	}
 
-	if !p.mapBuiltinPackage(v.Position, v.Filename, filename == "") {
-		return nil
-	}
-
 	imp, err := p.state.parse(filename, nil)
 	if err != nil {
 		fail(v.Position, err)
@@ -388,6 +403,24 @@ func (p *protoConverter) ident(pos scanner.Position, name string) *ast.Ident {
 	return &ast.Ident{NamePos: p.toCUEPos(pos), Name: labelName(name)}
 }
 
+// fieldLabel returns the label for a field with the given name and options,
+// along with its name. The label is the JSON name of the field: the
+// camel-case version of name, unless set by the json_name option.
+func (p *protoConverter) fieldLabel(pos scanner.Position, name string, options []*proto.Option) (ast.Label, string) {
+	s := labelName(name)
+	for _, o := range options {
+		if o.Name == "json_name" {
+			s = o.Constant.Source
+		}
+	}
+	if !ast.IsValidIdent(s) || strings.HasPrefix(s, "#") || strings.HasPrefix(s, "_") {
+		l := ast.NewString(s)
+		l.ValuePos = p.toCUEPos(pos)
+		return l, s
+	}
+	return &ast.Ident{NamePos: p.toCUEPos(pos), Name: s}, s
+}
+
 func (p *protoConverter) ref(pos scanner.Position) *ast.Ident {
 	name := "#" + p.path[len(p.path)-1]
 	return &ast.Ident{NamePos: p.toCUEPos(pos), Name: name}
@@ -511,16 +544,16 @@ func (p *protoConverter) messageField(s *ast.StructLit, i int, v proto.Visitee)
 		f.Label = ast.NewList(ast.NewIdent("string"))
 		f.Value = p.resolve(x.Position, x.Type, x.Options)
 
-		name := p.ident(x.Position, x.Name)
+		label, name := p.fieldLabel(x.Position, x.Name, x.Options)
 		f = &ast.Field{
-			Label: name,
+			Label: label,
 			Value: ast.NewStruct(f),
 		}
 		addComments(f, i, x.Comment, x.InlineComment)
 
 		o := optionParser{message: s, field: f}
 		o.tags = fmt.Sprintf(`%d,map[%s]%s`, x.Sequence, x.KeyType, x.Type)
-		if x.Name != name.Name {
+		if x.Name != name {
 			o.tags += "," + x.Name
 		}
 		s.Elts = append(s.Elts, f)
@@ -544,11 +577,7 @@ func (p *protoConverter) messageField(s *ast.StructLit, i int, v proto.Visitee)
 		// no need to handle
 
 	case *proto.Option:
-		opt := fmt.Sprintf("@protobuf(option %s=%s)", x.Name, x.Constant.Source)
-		attr := &ast.Attribute{
-			At:   p.toCUEPos(x.Position),
-			Text: opt,
-		}
+		attr := p.optionAttr(x)
 		addComments(attr, i, x.Doc(), x.InlineComment)
 		s.Elts = append(s.Elts, attr)
 
@@ -557,6 +586,14 @@ func (p *protoConverter) messageField(s *ast.StructLit, i int, v proto.Visitee)
 	}
 }
 
+// optionAttr converts an option declaration to an attribute.
+func (p *protoConverter) optionAttr(x *proto.Option) *ast.Attribute {
+	return &ast.Attribute{
+		At:   p.toCUEPos(x.Position),
+		Text: fmt.Sprintf("@protobuf(option %s=%s)", x.Name, optionSource(x.Constant)),
+	}
+}
+
 // enum converts a proto enum definition to CUE.
 //
 // An enum will generate two top-level definitions:
@@ -635,6 +672,15 @@ func (p *protoConverter) enum(x *proto.Enum) {
 				Label: p.stringLit(y.Position, y.Name),
 				Value: intValue,
 			}
+			var o optionParser
+			for _, e := range y.Elements {
+				if opt, ok := e.(*proto.Option); ok {
+					o.addOption(opt)
+				}
+			}
+			if o.tags != "" {
+				p.addTag(f, o.tags[1:])
+			}
 			valueMap.Elts = append(valueMap.Elts, f)
 
 			var e ast.Expr
@@ -690,6 +736,11 @@ func (p *protoConverter) enum(x *proto.Enum) {
 
 			// a := fmt.Sprintf("@protobuf(enum,name=%s)", y.Name)
 			// f.Attrs = append(f.Attrs, &ast.Attribute{Text: a})
+
+		case *proto.Option:
+			// Enum options are recorded in the value map, as the enum
+			// itself is not a struct.
+			valueMap.Elts = append(valueMap.Elts, p.optionAttr(y))
 		}
 	}
 	p.addDecl(d)
@@ -743,8 +794,8 @@ func (p *protoConverter) parseField(s *ast.StructLit, i int, x *proto.Field) *as
 	f := &ast.Field{}
 	addComments(f, i, x.Comment, x.InlineComment)
 
-	name := p.ident(x.Position, x.Name)
-	f.Label = name
+	label, name := p.fieldLabel(x.Position, x.Name, x.Options)
+	f.Label = label
 	typ := p.resolve(x.Position, x.Type, x.Options)
 	f.Value = typ
 	s.Elts = append(s.Elts, f)
@@ -753,7 +804,7 @@ func (p *protoConverter) parseField(s *ast.StructLit, i int, x *proto.Field) *as
 
 	// body of @protobuf tag: sequence,type[,name=<name>][,...]
 	o.tags += fmt.Sprintf("%v,%s", x.Sequence, x.Type)
-	if x.Name != name.Name {
+	if x.Name != name {
 		o.tags += ",name=" + x.Name
 	}
 	o.parse(x.Options)
@@ -797,20 +848,54 @@ func (p *optionParser) parse(options []*proto.Option) {
 				constraint.Optional = token.NoSpace.Pos()
 			}
 
+		case "json_name":
+			// The JSON name is used as the label of the field.
+			name := o.Constant.Source
+			if ast.IsValidIdent(name) {
+				p.tags += ",json_name=" + name
+			} else {
+				p.tags += "," + quoteOption("json_name="+name)
+			}
+
 		default:
 			// TODO: dropping comments. Maybe add dummy tag?
+			p.addOption(o)
+		}
+	}
+}
 
-			// TODO: should CUE support nested attributes?
-			source := o.Constant.SourceRepresentation()
-			p.tags += ","
-			switch source {
-			case "true":
-				p.tags += quoteOption(o.Name)
-			default:
-				p.tags += quoteOption(o.Name + "=" + source)
-			}
+// addOption adds option o to the tags.
+func (p *optionParser) addOption(o *proto.Option) {
+	// TODO: should CUE support nested attributes?
+	source := optionSource(o.Constant)
+	p.tags += ","
+	switch source {
+	case "true":
+		p.tags += quoteOption(o.Name)
+	default:
+		p.tags += quoteOption(o.Name + "=" + source)
+	}
+}
+
+// optionSource returns the source representation of an option value,
+// including the fields of aggregate values.
+func optionSource(l proto.Literal) string {
+	switch {
+	case len(l.OrderedMap) > 0:
+		a := []string{}
+		for _, x := range l.OrderedMap {
+			a = append(a, x.Name+": "+optionSource(*x.Literal))
+		}
+		return "{" + strings.Join(a, ", ") + "}"
+
+	case l.Array != nil:
+		a := []string{}
+		for _, x := range l.Array {
+			a = append(a, optionSource(*x))
 		}
+		return "[" + strings.Join(a, ", ") + "]"
 	}
+	return l.SourceRepresentation()
 }
 
 func quoteOption(s string) string {
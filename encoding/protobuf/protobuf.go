@@ -75,6 +75,20 @@
 //       required   bool          Defines the field is required. Use with
 //                                caution.
 //
+// Options
+//
+// Other options are preserved in @protobuf attributes. Field options,
+// including custom options with aggregate values, follow the sequence number
+// and type of a field, with options set to true reduced to their name:
+//
+//   oldName?: string @protobuf(2,string,name=old_name,deprecated)
+//
+// A field with a json_name option uses the given name as its label. The
+// json+pb interpretation accepts both this name and the original field name.
+// Message and enum options are recorded as declaration attributes, like
+// @protobuf(option deprecated=true), and options of enum values as
+// attributes of the fields in the map of numeric values.
+//
 package protobuf
 
 // TODO mappings:
@@ -130,6 +144,11 @@ type Config struct {
 	// Paths defines the include directory in which to search for imports.
 	Paths []string
 
+	// Resolvers are consulted, in order, for imports that cannot be found
+	// in Paths. This allows imports to be taken from, for instance, a module
+	// of a Buf Schema Registry. See BSRModule.
+	Resolvers []ImportResolver
+
 	// PkgName specifies the package name for a generated CUE file. A value
 	// will be derived from the Go package name if undefined.
 	PkgName string
@@ -164,6 +183,7 @@ type Extractor struct {
 	cwd      string
 	module   string
 	paths    []string
+	resolve  []ImportResolver
 	pkgName  string
 	enumMode string
 
@@ -188,6 +208,7 @@ func NewExtractor(c *Config) *Extractor {
 		root:      c.Root,
 		cwd:       cwd,
 		paths:     c.Paths,
+		resolve:   c.Resolvers,
 		pkgName:   c.PkgName,
 		module:    c.Module,
 		enumMode:  c.EnumMode,
@@ -37,6 +37,7 @@ func TestExtractDefinitions(t *testing.T) {
 		"mixer/v1/attributes.proto",
 		"mixer/v1/config/client/client_config.proto",
 		"other/trailcomment.proto",
+		"other/options.proto",
 	}
 	for _, file := range testCases {
 		t.Run(file, func(t *testing.T) {
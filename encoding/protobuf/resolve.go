@@ -0,0 +1,240 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protobuf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// An ImportResolver locates imported proto files that cannot be found in the
+// import paths.
+type ImportResolver interface {
+	// ResolveImport returns the filename of the file imported with the given
+	// path, like "google/type/date.proto", or "" if it does not provide the
+	// file. The returned file must exist on disk.
+	ResolveImport(path string) (filename string, err error)
+}
+
+// A BSRModule is an ImportResolver for the files of a module of a Buf Schema
+// Registry. The files of the module are downloaded once into a cache
+// directory, after which they are resolved from there without accessing the
+// network.
+type BSRModule struct {
+	// Remote is the host of the registry. It defaults to buf.build. Requests
+	// use https, unless Remote includes a scheme.
+	Remote string
+
+	Owner      string
+	Repository string
+
+	// Reference is the commit, tag, or branch of the module. It defaults to
+	// main.
+	Reference string
+
+	// CacheDir is the directory in which downloaded modules are stored.
+	// It defaults to the cue/bsr directory within os.UserCacheDir.
+	CacheDir string
+
+	// Token authenticates requests to the registry. It defaults to the value
+	// of the BUF_TOKEN environment variable.
+	Token string
+
+	// Client is used for requests to the registry. If nil,
+	// http.DefaultClient is used.
+	Client *http.Client
+
+	dir string // directory holding the downloaded files
+	err error
+}
+
+// ParseBSRModule parses a module name of the form remote/owner/repository,
+// optionally followed by :reference, as in buf.build/googleapis/googleapis.
+func ParseBSRModule(name string) (*BSRModule, error) {
+	m := &BSRModule{}
+	if i := strings.LastIndexByte(name, ':'); i > strings.LastIndexByte(name, '/') {
+		name, m.Reference = name[:i], name[i+1:]
+	}
+	parts := strings.Split(name, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf(
+			"invalid module %q: must be of the form remote/owner/repository[:reference]", name)
+	}
+	m.Remote, m.Owner, m.Repository = parts[0], parts[1], parts[2]
+	return m, nil
+}
+
+// String returns the name of the module in the form accepted by
+// ParseBSRModule.
+func (m *BSRModule) String() string {
+	s := m.remote() + "/" + m.Owner + "/" + m.Repository
+	if m.Reference != "" {
+		s += ":" + m.Reference
+	}
+	return s
+}
+
+func (m *BSRModule) remote() string {
+	if m.Remote == "" {
+		return "buf.build"
+	}
+	return m.Remote
+}
+
+func (m *BSRModule) reference() string {
+	if m.Reference == "" {
+		return "main"
+	}
+	return m.Reference
+}
+
+// ResolveImport implements ImportResolver.
+func (m *BSRModule) ResolveImport(path string) (string, error) {
+	if m.dir == "" && m.err == nil {
+		m.dir, m.err = m.download()
+	}
+	if m.err != nil {
+		return "", m.err
+	}
+	filename := filepath.Join(m.dir, filepath.FromSlash(path))
+	if _, err := os.Stat(filename); err != nil {
+		return "", nil
+	}
+	return filename, nil
+}
+
+// download downloads the files of m into the cache, unless they are already
+// there, and returns the directory holding them.
+func (m *BSRModule) download() (string, error) {
+	cacheDir := m.CacheDir
+	if cacheDir == "" {
+		d, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot determine cache directory: %v", err)
+		}
+		cacheDir = filepath.Join(d, "cue", "bsr")
+	}
+	host := m.remote()
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+len("://"):]
+	}
+	dir := filepath.Join(cacheDir, host, m.Owner, m.Repository, m.reference())
+	if _, err := os.Stat(dir); err == nil {
+		return dir, nil
+	}
+
+	files, err := m.fetch()
+	if err != nil {
+		return "", fmt.Errorf("downloading %s: %v", m, err)
+	}
+
+	// Write the files to a temporary directory first, so that an
+	// interrupted download does not leave an incomplete module in the cache.
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return "", err
+	}
+	tmp, err := ioutil.TempDir(filepath.Dir(dir), "download")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+	for _, f := range files {
+		name := filepath.Join(tmp, filepath.FromSlash(f.Path))
+		if !strings.HasPrefix(name, tmp+string(filepath.Separator)) {
+			return "", fmt.Errorf("downloading %s: invalid file path %q", m, f.Path)
+		}
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return "", err
+		}
+		if err := ioutil.WriteFile(name, f.Content, 0644); err != nil {
+			return "", err
+		}
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+type bsrFile struct {
+	Path    string `json:"path"`
+	Content []byte `json:"content"`
+}
+
+// fetch retrieves the files of the module with the Download method of the
+// registry API, using the JSON encoding of the Connect protocol.
+func (m *BSRModule) fetch() ([]bsrFile, error) {
+	body, err := json.Marshal(map[string]string{
+		"owner":      m.Owner,
+		"repository": m.Repository,
+		"reference":  m.reference(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	url := "https://" + m.remote() + "/buf.alpha.registry.v1alpha1.DownloadService/Download"
+	if strings.Contains(m.remote(), "://") {
+		url = m.remote() + "/buf.alpha.registry.v1alpha1.DownloadService/Download"
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	token := m.Token
+	if token == "" {
+		token = os.Getenv("BUF_TOKEN")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	c := m.Client
+	if c == nil {
+		c = http.DefaultClient
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(b, &e) != nil || e.Message == "" {
+			e.Message = resp.Status
+		}
+		return nil, fmt.Errorf("%s", e.Message)
+	}
+	var x struct {
+		Module struct {
+			Files []bsrFile `json:"files"`
+		} `json:"module"`
+	}
+	if err := json.Unmarshal(b, &x); err != nil {
+		return nil, err
+	}
+	return x.Module.Files, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package protobuf
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+)
+
+func TestBSRModule(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/buf.alpha.registry.v1alpha1.DownloadService/Download" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct{ Owner, Repository, Reference string }
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Owner != "acme" || req.Repository != "types" || req.Reference != "v1" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":"not_found","message":"repository not found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"module": map[string]interface{}{
+				"files": []interface{}{map[string]interface{}{
+					"path": "acme/type/money.proto",
+					"content": []byte(`syntax = "proto3";
+package acme.type;
+option go_package = "acme.com/type";
+message Money {
+  string currency_code = 1;
+  int64 units = 2;
+}
+`),
+				}},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "bsr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	m := &BSRModule{
+		Remote:     srv.URL,
+		Owner:      "acme",
+		Repository: "types",
+		Reference:  "v1",
+		CacheDir:   dir,
+	}
+
+	src := `syntax = "proto3";
+package acme.shop;
+option go_package = "acme.com/shop";
+import "acme/type/money.proto";
+import "google/protobuf/timestamp.proto";
+message Order {
+  acme.type.Money price = 1;
+  google.protobuf.Timestamp created = 2;
+}
+`
+	f, err := Extract("order.proto", src, &Config{Resolvers: []ImportResolver{m}})
+	if err != nil {
+		t.Fatal(errors.Details(err, nil))
+	}
+	b, err := format.Node(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"acme.com/type"`, "type.#Money @protobuf(1,acme.type.Money)", "time.Time"} {
+		if !strings.Contains(string(b), want) {
+			t.Errorf("output does not contain %q:\n%s", want, b)
+		}
+	}
+
+	// A second module with the same cache uses the downloaded files.
+	m2 := &BSRModule{Remote: srv.URL, Owner: "acme", Repository: "types", Reference: "v1", CacheDir: dir}
+	name, err := m2.ResolveImport("acme/type/money.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := strings.TrimPrefix(srv.URL, "http://")
+	if want := filepath.Join(dir, host, "acme/types/v1/acme/type/money.proto"); name != want {
+		t.Errorf("got %s; want %s", name, want)
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests; want 1", requests)
+	}
+	if name, _ := m2.ResolveImport("acme/type/other.proto"); name != "" {
+		t.Errorf("got %s for missing file; want none", name)
+	}
+
+	m3 := &BSRModule{Remote: srv.URL, Owner: "acme", Repository: "missing", CacheDir: dir}
+	_, err = Extract("order.proto", src, &Config{Resolvers: []ImportResolver{m3}})
+	if err == nil || !strings.Contains(err.Error(), "repository not found") {
+		t.Errorf("got error %v; want repository not found", err)
+	}
+}
+
+func TestParseBSRModule(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want string
+		err  bool
+	}{
+		{in: "buf.build/googleapis/googleapis", want: "buf.build/googleapis/googleapis"},
+		{in: "buf.build/acme/types:v1.2", want: "buf.build/acme/types:v1.2"},
+		{in: "acme/types", err: true},
+		{in: "buf.build//types", err: true},
+	}
+	for _, tc := range testCases {
+		m, err := ParseBSRModule(tc.in)
+		if tc.err {
+			if err == nil {
+				t.Errorf("%s: expected error", tc.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.in, err)
+			continue
+		}
+		if got := m.String(); got != tc.want {
+			t.Errorf("%s: got %s; want %s", tc.in, got, tc.want)
+		}
+	}
+}
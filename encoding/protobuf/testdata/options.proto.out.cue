@@ -0,0 +1,31 @@
+package other
+
+#Rules: {
+	minLen?:  int32  @protobuf(1,int32,name=min_len)
+	pattern?: string @protobuf(2,string)
+}
+
+// A user of the system.
+#User: {
+	@protobuf(option deprecated=true)
+
+	// The name of the user.
+	name?: string @protobuf(1,string,name=full_name,json_name=name,#"(label)="Name""#)
+
+	// Replaced by full_name.
+	oldName?: string  @protobuf(2,string,name=old_name,deprecated)
+	email?:   string  @protobuf(3,string,#"(rules)={min_len: 3, pattern: ".*@.*"}"#)
+	status?:  #Status @protobuf(4,Status)
+}
+#Status: {"UNKNOWN", #enumValue: 0} |
+	{"ACTIVE", #enumValue: 1} |
+	{"RETIRED", #enumValue: 2} |
+	{"ENABLED", #enumValue: 1}
+
+#Status_value: {
+	@protobuf(option allow_alias=true)
+	UNKNOWN: 0
+	ACTIVE:  1
+	RETIRED: 2 @protobuf(deprecated)
+	ENABLED: 1
+}
@@ -20,11 +20,11 @@ import (
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/cuetxtar"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/encoding/protobuf/textproto"
 	"cuelang.org/go/internal/cuetest"
-	"cuelang.org/go/internal/cuetxtar"
 )
 
 func TestParse(t *testing.T) {
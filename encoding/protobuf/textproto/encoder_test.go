@@ -19,10 +19,10 @@ import (
 	"testing"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuetxtar"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/encoding/protobuf/textproto"
 	"cuelang.org/go/internal/cuetest"
-	"cuelang.org/go/internal/cuetxtar"
 )
 
 func TestEncode(t *testing.T) {
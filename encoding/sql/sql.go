@@ -0,0 +1,275 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sql converts the schema of a SQL database to CUE definitions.
+//
+// Each table is converted to a definition with a field for each column.
+// Definitions are named after their table in camel case, so that the rows
+// of table order_items can be validated with #OrderItems. Columns that may
+// be omitted when inserting a row, because they are nullable or have a
+// default, are optional fields. Nullable columns also allow null.
+//
+// The column types are mapped as follows:
+//
+//	smallint, integer, bigint    int16, int32, int64
+//	real, double precision       float32, float64
+//	numeric, decimal             number
+//	char(n), varchar(n)          string & strings.MaxRunes(n)
+//	text, uuid, enumerations     string
+//	boolean                      bool
+//	bytea, blob                  bytes
+//	timestamp, datetime          time.Time
+//	date                         time.Format("2006-01-02")
+//
+// Columns of any other type, like json, accept any value.
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// A Config configures the conversion of a database schema.
+type Config struct {
+	// PkgName is the package name of the generated file.
+	PkgName string
+
+	// Schema selects the schema, or database for MySQL, of the tables to
+	// convert. By default, the tables of all schemas other than the system
+	// schemas are converted.
+	Schema string
+
+	// Tables selects the tables to convert. By default, all tables are
+	// converted.
+	Tables []string
+}
+
+// A Column describes a column of a table.
+type Column struct {
+	Table    string
+	Name     string
+	Type     string // the data type, as reported by information_schema
+	Nullable bool
+	Default  bool // whether the column has a default value
+
+	// MaxLength is the maximum length of character columns, or 0.
+	MaxLength int
+}
+
+var systemSchemas = map[string]bool{
+	"information_schema": true,
+	"pg_catalog":         true,
+	"mysql":              true,
+	"performance_schema": true,
+	"sys":                true,
+}
+
+// Extract reads the columns of the tables of db from its information
+// schema and converts them to CUE definitions.
+func Extract(ctx context.Context, db *sql.DB, cfg *Config) (*ast.File, error) {
+	// Placeholders differ between drivers, so the columns are selected here
+	// rather than in the query.
+	rows, err := db.QueryContext(ctx, `
+		SELECT table_schema, table_name, column_name, data_type,
+			is_nullable, column_default, character_maximum_length
+		FROM information_schema.columns
+		ORDER BY table_schema, table_name, ordinal_position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := map[string]bool{}
+	for _, t := range cfg.Tables {
+		tables[t] = true
+	}
+	var cols []Column
+	for rows.Next() {
+		var (
+			schema, nullable string
+			def              sql.NullString
+			maxLen           sql.NullInt64
+			c                Column
+		)
+		err := rows.Scan(&schema, &c.Table, &c.Name, &c.Type, &nullable, &def, &maxLen)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case cfg.Schema != "" && schema != cfg.Schema,
+			cfg.Schema == "" && systemSchemas[strings.ToLower(schema)],
+			len(tables) > 0 && !tables[c.Table]:
+			continue
+		}
+		c.Nullable = strings.EqualFold(nullable, "YES")
+		c.Default = def.Valid
+		c.MaxLength = int(maxLen.Int64)
+		cols = append(cols, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return Generate(cols, cfg)
+}
+
+// Generate converts the given columns to CUE definitions, one for each
+// table. The definitions are sorted by name; the fields are in the order of
+// the columns.
+func Generate(cols []Column, cfg *Config) (*ast.File, error) {
+	f := &ast.File{}
+	if cfg.PkgName != "" {
+		f.Decls = append(f.Decls, &ast.Package{Name: ast.NewIdent(cfg.PkgName)})
+	}
+
+	var names []string
+	defs := map[string]*ast.StructLit{}
+	tables := map[string]string{} // definition name to table
+	reported := map[string]bool{}
+	var errs errors.Error
+	for _, c := range cols {
+		name := "#" + camelCase(c.Table)
+		s, ok := defs[name]
+		switch {
+		case !ok:
+			s = ast.NewStruct()
+			defs[name] = s
+			tables[name] = c.Table
+			names = append(names, name)
+		case tables[name] != c.Table:
+			if !reported[c.Table] {
+				errs = errors.Append(errs, errors.Newf(token.NoPos,
+					"tables %q and %q both map to %s", tables[name], c.Table, name))
+				reported[c.Table] = true
+			}
+			continue
+		}
+		typ := columnType(c)
+		if c.Nullable {
+			typ = ast.NewBinExpr(token.OR, typ, ast.NewNull())
+		}
+		field := &ast.Field{Label: label(c.Name), Value: typ}
+		if c.Nullable || c.Default {
+			field.Optional = token.NoSpace.Pos()
+		}
+		s.Elts = append(s.Elts, field)
+	}
+	if errs != nil {
+		return nil, errs
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		field := &ast.Field{Label: ast.NewIdent(name), Value: defs[name]}
+		ast.SetComments(field, []*ast.CommentGroup{docComment(
+			"// " + name + " is a row of table " + tables[name] + ".")})
+		f.Decls = append(f.Decls, field)
+	}
+	if err := astutil.Sanitize(f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func docComment(text string) *ast.CommentGroup {
+	return &ast.CommentGroup{Doc: true, List: []*ast.Comment{{Text: text}}}
+}
+
+// columnType returns the CUE type of values of column c.
+func columnType(c Column) ast.Expr {
+	typ := strings.ToLower(c.Type)
+	if i := strings.IndexByte(typ, '('); i >= 0 {
+		typ = strings.TrimSpace(typ[:i])
+	}
+	switch typ {
+	case "tinyint":
+		return ast.NewIdent("int8")
+	case "smallint", "int2", "smallserial":
+		return ast.NewIdent("int16")
+	case "integer", "int", "int4", "mediumint", "serial":
+		return ast.NewIdent("int32")
+	case "bigint", "int8", "bigserial":
+		return ast.NewIdent("int64")
+	case "real", "float", "float4":
+		return ast.NewIdent("float32")
+	case "double precision", "double", "float8":
+		return ast.NewIdent("float64")
+	case "numeric", "decimal":
+		return ast.NewIdent("number")
+	case "boolean", "bool":
+		return ast.NewIdent("bool")
+	case "bytea", "blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary":
+		return ast.NewIdent("bytes")
+	case "character varying", "varchar", "character", "char":
+		if c.MaxLength > 0 {
+			return ast.NewBinExpr(token.AND, ast.NewIdent("string"),
+				ast.NewCall(pkgSel("strings", "MaxRunes"),
+					ast.NewLit(token.INT, strconv.Itoa(c.MaxLength))))
+		}
+		return ast.NewIdent("string")
+	case "text", "tinytext", "mediumtext", "longtext", "uuid", "enum":
+		return ast.NewIdent("string")
+	case "date":
+		return ast.NewCall(pkgSel("time", "Format"), ast.NewString("2006-01-02"))
+	}
+	if strings.HasPrefix(typ, "timestamp") || typ == "datetime" {
+		return pkgSel("time", "Time")
+	}
+	return ast.NewIdent("_")
+}
+
+// pkgSel returns a reference to name in the builtin package pkg.
+func pkgSel(pkg, name string) ast.Expr {
+	ident := ast.NewIdent(pkg)
+	ident.Node = ast.NewImport(nil, pkg)
+	return ast.NewSel(ident, name)
+}
+
+func label(name string) ast.Label {
+	if ast.IsValidIdent(name) && !strings.HasPrefix(name, "#") && !strings.HasPrefix(name, "_") {
+		return ast.NewIdent(name)
+	}
+	return ast.NewString(name)
+}
+
+// camelCase converts a table name like order_items to OrderItems.
+func camelCase(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		switch {
+		case r == '_' || r == '-' || r == ' ' || r == '.':
+			upper = true
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+		case upper:
+			b.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			b.WriteRune(r)
+		}
+	}
+	s := b.String()
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		s = "T" + s
+	}
+	return s
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue/format"
+)
+
+func TestGenerate(t *testing.T) {
+	testCases := []struct {
+		name string
+		cols []Column
+		out  string
+	}{{
+		name: "types",
+		cols: []Column{
+			{Table: "order_items", Name: "id", Type: "bigint", Default: true},
+			{Table: "order_items", Name: "quantity", Type: "integer"},
+			{Table: "order_items", Name: "price", Type: "numeric"},
+			{Table: "order_items", Name: "sku", Type: "character varying", MaxLength: 12},
+			{Table: "order_items", Name: "note", Type: "text", Nullable: true},
+			{Table: "order_items", Name: "created_at", Type: "timestamp with time zone"},
+			{Table: "order_items", Name: "shipped", Type: "date", Nullable: true},
+			{Table: "order_items", Name: "attrs", Type: "jsonb"},
+			{Table: "order_items", Name: "Unit Price", Type: "double"},
+			{Table: "accounts", Name: "active", Type: "tinyint(1)"},
+			{Table: "accounts", Name: "avatar", Type: "blob"},
+		},
+		out: `package db
+
+import (
+	"strings"
+	"time"
+)
+
+// #Accounts is a row of table accounts.
+#Accounts: {
+	active: int8
+	avatar: bytes
+}
+
+// #OrderItems is a row of table order_items.
+#OrderItems: {
+	id?:          int64
+	quantity:     int32
+	price:        number
+	sku:          string & strings.MaxRunes(12)
+	note?:        string | null
+	created_at:   time.Time
+	shipped?:     time.Format("2006-01-02") | null
+	attrs:        _
+	"Unit Price": float64
+}
+`,
+	}, {
+		name: "conflict",
+		cols: []Column{
+			{Table: "order_items", Name: "id", Type: "bigint"},
+			{Table: "OrderItems", Name: "id", Type: "bigint"},
+			{Table: "OrderItems", Name: "name", Type: "text"},
+		},
+		out: `tables "order_items" and "OrderItems" both map to #OrderItems`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var got string
+			f, err := Generate(tc.cols, &Config{PkgName: "db"})
+			if err != nil {
+				got = err.Error()
+			} else {
+				b, err := format.Node(f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = string(b)
+			}
+			if got != tc.out {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.out)
+			}
+		})
+	}
+}
+
+func TestCamelCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"users":       "Users",
+		"order_items": "OrderItems",
+		"public.logs": "PublicLogs",
+		"2fa_codes":   "T2faCodes",
+		"$$":          "T",
+	} {
+		if got := camelCase(in); got != want {
+			t.Errorf("camelCase(%q) = %q; want %q", in, got, want)
+		}
+	}
+}
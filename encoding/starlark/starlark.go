@@ -0,0 +1,182 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package starlark converts concrete CUE values to Starlark data literals,
+// as used in Bazel BUILD and .bzl files.
+//
+// Structs map to dicts, lists to lists, null to None, and booleans to True
+// and False. Bytes are not supported, as Bazel's dialect of Starlark has no
+// bytes type.
+package starlark
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+)
+
+// Marshal returns the Starlark expression for the concrete value v.
+func Marshal(v cue.Value) ([]byte, error) {
+	e := &encoder{}
+	if err := e.value(v, 0); err != nil {
+		return nil, err
+	}
+	e.WriteByte('\n')
+	return e.Bytes(), nil
+}
+
+// MarshalFile returns a sequence of Starlark assignments, one for each
+// regular field of the struct v, which can be loaded from a .bzl file. All
+// field names must be valid Starlark identifiers.
+func MarshalFile(v cue.Value) ([]byte, error) {
+	iter, err := v.Fields()
+	if err != nil {
+		return nil, errors.Newf(v.Pos(), "starlark: top-level value must be a struct")
+	}
+	e := &encoder{}
+	for iter.Next() {
+		name := iter.Label()
+		if !isIdent(name) {
+			return nil, errors.Newf(iter.Value().Pos(),
+				"starlark: field %q is not a valid identifier", name)
+		}
+		e.WriteString(name)
+		e.WriteString(" = ")
+		if err := e.value(iter.Value(), 0); err != nil {
+			return nil, err
+		}
+		e.WriteByte('\n')
+	}
+	return e.Bytes(), nil
+}
+
+type encoder struct {
+	bytes.Buffer
+}
+
+func (e *encoder) indent(n int) {
+	e.WriteString(strings.Repeat("    ", n))
+}
+
+func (e *encoder) value(v cue.Value, depth int) error {
+	v, _ = v.Default()
+	if err := v.Err(); err != nil {
+		return err
+	}
+	switch v.Kind() {
+	case cue.NullKind:
+		e.WriteString("None")
+
+	case cue.BoolKind:
+		b, _ := v.Bool()
+		if b {
+			e.WriteString("True")
+		} else {
+			e.WriteString("False")
+		}
+
+	case cue.IntKind:
+		i, err := v.Int(nil)
+		if err != nil {
+			return err
+		}
+		e.WriteString(i.String())
+
+	case cue.FloatKind:
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		s := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		e.WriteString(s)
+
+	case cue.StringKind:
+		s, _ := v.String()
+		e.WriteString(strconv.Quote(s))
+
+	case cue.ListKind:
+		iter, _ := v.List()
+		e.WriteByte('[')
+		n := 0
+		for ; iter.Next(); n++ {
+			e.WriteByte('\n')
+			e.indent(depth + 1)
+			if err := e.value(iter.Value(), depth+1); err != nil {
+				return err
+			}
+			e.WriteByte(',')
+		}
+		if n > 0 {
+			e.WriteByte('\n')
+			e.indent(depth)
+		}
+		e.WriteByte(']')
+
+	case cue.StructKind:
+		iter, _ := v.Fields()
+		e.WriteByte('{')
+		n := 0
+		for ; iter.Next(); n++ {
+			e.WriteByte('\n')
+			e.indent(depth + 1)
+			e.WriteString(strconv.Quote(iter.Label()))
+			e.WriteString(": ")
+			if err := e.value(iter.Value(), depth+1); err != nil {
+				return err
+			}
+			e.WriteByte(',')
+		}
+		if n > 0 {
+			e.WriteByte('\n')
+			e.indent(depth)
+		}
+		e.WriteByte('}')
+
+	case cue.BytesKind:
+		return errors.Newf(v.Pos(), "starlark: bytes are not supported")
+
+	default:
+		return errors.Newf(v.Pos(), "starlark: cannot encode incomplete value %v", v)
+	}
+	return nil
+}
+
+// isIdent reports whether s is a valid Starlark identifier.
+func isIdent(s string) bool {
+	if s == "" || keywords[s] {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case i > 0 && '0' <= r && r <= '9':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+var keywords = map[string]bool{
+	"and": true, "break": true, "continue": true, "def": true, "elif": true,
+	"else": true, "for": true, "if": true, "in": true, "lambda": true,
+	"load": true, "not": true, "or": true, "pass": true, "return": true,
+	"True": true, "False": true, "None": true,
+}
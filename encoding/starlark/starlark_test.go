@@ -0,0 +1,77 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package starlark
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestMarshal(t *testing.T) {
+	testCases := []struct {
+		in   string
+		out  string
+		file bool
+	}{{
+		in:  `null`,
+		out: "None\n",
+	}, {
+		in:  `[true, false, 1, 2.0, 1e30, "a\"b"]`,
+		out: "[\n    True,\n    False,\n    1,\n    2.0,\n    1e+30,\n    \"a\\\"b\",\n]\n",
+	}, {
+		in:  `{a: *"x" | string, "b-c": {}, c: []}`,
+		out: "{\n    \"a\": \"x\",\n    \"b-c\": {},\n    \"c\": [],\n}\n",
+	}, {
+		in:  `{a: int}`,
+		out: "starlark: cannot encode incomplete value int",
+	}, {
+		in:  `'abc'`,
+		out: "starlark: bytes are not supported",
+	}, {
+		in:   `{name: "web", deps: [":lib"], opts: {debug: false}}`,
+		out:  "name = \"web\"\ndeps = [\n    \":lib\",\n]\nopts = {\n    \"debug\": False,\n}\n",
+		file: true,
+	}, {
+		in:   `{"foo-bar": 1}`,
+		out:  `starlark: field "foo-bar" is not a valid identifier`,
+		file: true,
+	}, {
+		in:   `{load: 1}`,
+		out:  `starlark: field "load" is not a valid identifier`,
+		file: true,
+	}, {
+		in:   `[1]`,
+		out:  "starlark: top-level value must be a struct",
+		file: true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			v := cuecontext.New().CompileString(tc.in)
+			marshal := Marshal
+			if tc.file {
+				marshal = MarshalFile
+			}
+			b, err := marshal(v)
+			got := string(b)
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tc.out {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.out)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package terraform converts concrete CUE values to Terraform JSON
+// configuration, as read from .tf.json files.
+//
+// The top-level fields of a configuration must be Terraform block types,
+// such as resource, variable, or output. Blocks with labels map to nested
+// structs in the same way as in Terraform's JSON syntax:
+//
+//	resource: aws_instance: web: {
+//		ami:           "ami-a1b2c3d4"
+//		instance_type: "t2.micro"
+//	}
+//
+// Terraform interprets most strings in JSON configuration as string
+// templates. To ensure that strings defined in CUE are passed as is, any
+// template sequences ${ and %{ are escaped. Fields with a
+// @terraform(expr) attribute are written verbatim, allowing them to hold
+// Terraform expressions:
+//
+//	instance: "${aws_instance.web.id}" @terraform(expr)
+//
+// Strings within terraform and variable blocks, which Terraform does not
+// interpret as templates, are never escaped.
+//
+// Nested blocks that occur more than once are represented as a list of
+// objects. As lists do not unify well in CUE, such blocks may also be
+// defined as a struct of blocks marked with @terraform(blocks). The struct
+// is then written as a list of its values, with the labels only serving to
+// identify the blocks within CUE:
+//
+//	ingress: {
+//		http:  {from_port: 80, to_port: 80}
+//		https: {from_port: 443, to_port: 443}
+//	} @terraform(blocks)
+//
+// Provisioner blocks, whose order is significant, are always written as a
+// list of single-field objects, as required by Terraform.
+package terraform
+
+import (
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+)
+
+// blockTypes lists the top-level block types of a Terraform configuration
+// and whether strings within them are interpreted as templates.
+var blockTypes = map[string]bool{
+	"check":     true,
+	"data":      true,
+	"import":    true,
+	"locals":    true,
+	"module":    true,
+	"moved":     true,
+	"output":    true,
+	"provider":  true,
+	"resource":  true,
+	"terraform": false,
+	"variable":  false,
+}
+
+// Generate returns the Terraform JSON configuration for the concrete value v
+// as a CUE file, which can be written out by a JSON encoder.
+func Generate(v cue.Value) (*ast.File, error) {
+	iter, err := v.Fields()
+	if err != nil {
+		return nil, errors.Newf(v.Pos(),
+			"terraform: configuration must be a struct")
+	}
+	g := &generator{}
+	f := &ast.File{}
+	for iter.Next() {
+		name := iter.Label()
+		template, ok := blockTypes[name]
+		if !ok {
+			g.addErr(errors.Newf(iter.Value().Pos(),
+				"terraform: unknown block type %q", name))
+			continue
+		}
+		f.Decls = append(f.Decls, g.field(name, iter.Value(), template))
+	}
+	if g.errs != nil {
+		return nil, g.errs
+	}
+	return f, nil
+}
+
+type generator struct {
+	path []string
+	errs errors.Error
+}
+
+func (g *generator) addErr(err error) {
+	g.errs = errors.Append(g.errs, errors.Promote(err, "terraform"))
+}
+
+// value converts v. If escape is set, template sequences in strings are
+// escaped.
+func (g *generator) value(v cue.Value, escape bool) ast.Expr {
+	v, _ = v.Default()
+	if err := v.Err(); err != nil {
+		g.addErr(err)
+		return ast.NewNull()
+	}
+	switch v.Kind() {
+	case cue.StringKind:
+		s, _ := v.String()
+		if escape {
+			s = Escape(s)
+		}
+		return ast.NewString(s)
+
+	case cue.ListKind:
+		list := &ast.ListLit{}
+		iter, _ := v.List()
+		for iter.Next() {
+			list.Elts = append(list.Elts, g.value(iter.Value(), escape))
+		}
+		return list
+
+	case cue.StructKind:
+		return g.object(v, escape)
+
+	case cue.BytesKind:
+		g.addErr(errors.Newf(v.Pos(), "%s: bytes are not supported",
+			strings.Join(g.path, ".")))
+		return ast.NewNull()
+	}
+
+	x, ok := v.Syntax(cue.Final(), cue.Concrete(true)).(ast.Expr)
+	if !ok || !v.IsConcrete() {
+		g.addErr(errors.Newf(v.Pos(), "%s: cannot encode incomplete value %v",
+			strings.Join(g.path, "."), v))
+		return ast.NewNull()
+	}
+	return x
+}
+
+func (g *generator) object(v cue.Value, escape bool) ast.Expr {
+	s := &ast.StructLit{}
+	iter, _ := v.Fields()
+	for iter.Next() {
+		s.Elts = append(s.Elts, g.field(iter.Label(), iter.Value(), escape))
+	}
+	return s
+}
+
+// field converts the field with the given name and value, taking into
+// account its terraform attribute.
+func (g *generator) field(name string, v cue.Value, escape bool) *ast.Field {
+	g.path = append(g.path, name)
+	defer func() { g.path = g.path[:len(g.path)-1] }()
+
+	blocks := false
+	a := v.Attribute("terraform")
+	for i := 0; a.Err() == nil && i < a.NumArgs(); i++ {
+		switch key, _ := a.Arg(i); key {
+		case "expr":
+			escape = false
+		case "blocks":
+			blocks = true
+		default:
+			g.addErr(errors.Newf(v.Pos(),
+				"%s: unknown key %q in terraform attribute",
+				strings.Join(g.path, "."), key))
+		}
+	}
+
+	var x ast.Expr
+	switch {
+	case blocks:
+		x = g.blocks(v, escape, false)
+	case name == "provisioner" && v.Kind() == cue.StructKind:
+		x = g.blocks(v, escape, true)
+	default:
+		x = g.value(v, escape)
+	}
+	return &ast.Field{Label: ast.NewString(name), Value: x}
+}
+
+// blocks converts a struct of blocks to a list. If keepLabels is set, each
+// block is written as a single-field object with its label as the field
+// name.
+func (g *generator) blocks(v cue.Value, escape, keepLabels bool) ast.Expr {
+	iter, err := v.Fields()
+	if err != nil {
+		g.addErr(errors.Newf(v.Pos(), "%s: blocks must be a struct",
+			strings.Join(g.path, ".")))
+		return ast.NewNull()
+	}
+	list := &ast.ListLit{}
+	for iter.Next() {
+		name := iter.Label()
+		g.path = append(g.path, name)
+		x := g.value(iter.Value(), escape)
+		if keepLabels {
+			x = ast.NewStruct(ast.NewString(name), x)
+		}
+		list.Elts = append(list.Elts, x)
+		g.path = g.path[:len(g.path)-1]
+	}
+	return list
+}
+
+// Escape escapes the template sequences ${ and %{ in s, so that Terraform
+// interprets s as a literal string.
+func Escape(s string) string {
+	s = strings.Replace(s, "${", "$${", -1)
+	return strings.Replace(s, "%{", "%%{", -1)
+}
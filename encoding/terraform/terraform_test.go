@@ -0,0 +1,106 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package terraform
+
+import (
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/encoding/json"
+)
+
+func TestGenerate(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		out  string
+	}{{
+		name: "escape",
+		in: `
+		resource: null_resource: a: triggers: {
+			literal: "${x} and %{if y}"
+			expr:    "${var.x}" @terraform(expr)
+		}`,
+		out: `{"resource":{"null_resource":{"a":{"triggers":{"literal":"$${x} and %%{if y}","expr":"${var.x}"}}}}}`,
+	}, {
+		name: "no escape in variable and terraform blocks",
+		in: `
+		variable: v: default: "${x}"
+		terraform: required_version: "1.0.0"`,
+		out: `{"variable":{"v":{"default":"${x}"}},"terraform":{"required_version":"1.0.0"}}`,
+	}, {
+		name: "expr applies to nested values",
+		in:  `locals: {a: ["${b}", {c: "${d}"}]} @terraform(expr)`,
+		out: `{"locals":{"a":["${b}",{"c":"${d}"}]}}`,
+	}, {
+		name: "blocks",
+		in: `
+		resource: aws_security_group: sg: ingress: {
+			http:  {from_port: 80, to_port:  80}
+			https: {from_port: 443, to_port: 443}
+		} @terraform(blocks)`,
+		out: `{"resource":{"aws_security_group":{"sg":{"ingress":[{"from_port":80,"to_port":80},{"from_port":443,"to_port":443}]}}}}`,
+	}, {
+		name: "provisioner",
+		in: `
+		resource: null_resource: a: provisioner: {
+			"local-exec": command: "echo a"
+			"remote-exec": inline: ["echo b"]
+		}`,
+		out: `{"resource":{"null_resource":{"a":{"provisioner":[{"local-exec":{"command":"echo a"}},{"remote-exec":{"inline":["echo b"]}}]}}}}`,
+	}, {
+		name: "defaults",
+		in:  `output: o: value: *"a" | string`,
+		out: `{"output":{"o":{"value":"a"}}}`,
+	}, {
+		name: "unknown block type",
+		in:   `resources: a: b: {}`,
+		out:  `terraform: unknown block type "resources"`,
+	}, {
+		name: "unknown attribute key",
+		in:   `locals: a: 1 @terraform(foo)`,
+		out:  `locals.a: unknown key "foo" in terraform attribute`,
+	}, {
+		name: "incomplete",
+		in:   `locals: a: int`,
+		out:  `locals.a: cannot encode incomplete value int`,
+	}, {
+		name: "not a struct",
+		in:   `[]`,
+		out:  `terraform: configuration must be a struct`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := cuecontext.New().CompileString(tc.in)
+			f, err := Generate(v)
+			var got string
+			if err != nil {
+				got = strings.TrimSpace(errors.Details(err, nil))
+				got = strings.SplitN(got, ":\n", 2)[0]
+			} else {
+				b, err := json.Encode(f)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = string(b)
+			}
+			if got != tc.out {
+				t.Errorf("\ngot:  %s\nwant: %s", got, tc.out)
+			}
+		})
+	}
+}
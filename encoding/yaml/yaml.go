@@ -78,22 +78,56 @@ func Decode(r *cue.Runtime, filename string, src interface{}) (*cue.Instance, er
 
 // Encode returns the YAML encoding of v.
 func Encode(v cue.Value) ([]byte, error) {
-	n := v.Syntax(cue.Final())
-	b, err := cueyaml.Encode(n)
-	return b, err
+	return (&Config{}).Encode(v)
 }
 
 // EncodeStream returns the YAML encoding of iter, where consecutive values
 // of iter are separated with a `---`.
 func EncodeStream(iter cue.Iterator) ([]byte, error) {
+	return (&Config{}).EncodeStream(iter)
+}
+
+// A Config defines options for encoding YAML. The zero value writes YAML
+// that can be read by both YAML 1.1 and YAML 1.2 parsers.
+type Config struct {
+	// Version is the version of YAML for which strings that could be
+	// mistaken for other values are quoted: "1.1", the default, or "1.2".
+	// YAML 1.1 parsers interpret strings such as yes, no, on and off as
+	// booleans and strings such as 1:20 as numbers. Strings that YAML 1.2
+	// parsers interpret as other values are always quoted.
+	Version string
+
+	// QuoteStrings quotes all string values, rather than only those that
+	// could be mistaken for other values. Field names are not affected.
+	QuoteStrings bool
+
+	// Indent is the number of spaces used for indentation. The default is 2.
+	Indent int
+
+	// FlowThreshold determines whether lists and structs are written in
+	// flow or block style. If it is zero, lists and structs written on a
+	// single line in CUE are written in flow style. If it is positive, lists
+	// and structs of at most FlowThreshold scalar values are written in flow
+	// style. If it is negative, block style is always used.
+	FlowThreshold int
+}
+
+// Encode returns the YAML encoding of v.
+func (c *Config) Encode(v cue.Value) ([]byte, error) {
+	n := v.Syntax(cue.Final())
+	return (*cueyaml.Config)(c).Encode(n)
+}
+
+// EncodeStream returns the YAML encoding of iter, where consecutive values
+// of iter are separated with a `---`.
+func (c *Config) EncodeStream(iter cue.Iterator) ([]byte, error) {
 	// TODO: return an io.Reader and allow asynchronous processing.
 	buf := &bytes.Buffer{}
 	for i := 0; iter.Next(); i++ {
 		if i > 0 {
 			buf.WriteString("---\n")
 		}
-		n := iter.Value().Syntax(cue.Final())
-		b, err := cueyaml.Encode(n)
+		b, err := c.Encode(iter.Value())
 		if err != nil {
 			return nil, err
 		}
@@ -245,3 +245,37 @@ func TestYAMLValues(t *testing.T) {
 		})
 	}
 }
+
+func TestEncodeConfig(t *testing.T) {
+	testCases := []struct {
+		cfg  Config
+		cue  string
+		yaml string
+	}{{
+		cfg:  Config{Version: "1.2"},
+		cue:  `{a: "no", on: "2222:22"}`,
+		yaml: "a: no\non: 2222:22",
+	}, {
+		cfg:  Config{QuoteStrings: true},
+		cue:  `{a: "b", c: 1}`,
+		yaml: "a: \"b\"\nc: 1",
+	}, {
+		cfg:  Config{Indent: 4, FlowThreshold: 2},
+		cue:  `{a: {b: [1, 2, 3], c: [1, 2]}}`,
+		yaml: "a:\n    b:\n        - 1\n        - 2\n        - 3\n    c: [1, 2]",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.cue, func(t *testing.T) {
+			c := cuecontext.New()
+			v := c.CompileString(tc.cue)
+
+			b, err := tc.cfg.Encode(v)
+			if err != nil {
+				t.Error(err)
+			}
+			if got := strings.TrimSpace(string(b)); got != tc.yaml {
+				t.Errorf("Encode:\ngot  %q\nwant %q", got, tc.yaml)
+			}
+		})
+	}
+}
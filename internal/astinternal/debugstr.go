@@ -224,6 +224,10 @@ func DebugStr(x interface{}) (out string) {
 			out += " "
 		}
 		out += DebugStr(v.Path)
+		for _, a := range v.Attrs {
+			out += " "
+			out += DebugStr(a)
+		}
 		return out
 
 	case []ast.Decl:
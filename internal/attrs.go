@@ -150,14 +150,14 @@ func ParseAttrBody(pos token.Pos, s string) (a Attr) {
 		// always scan at least one, possibly empty element.
 		n, err := scanAttributeElem(pos, s[i:], &a)
 		if err != nil {
-			return Attr{Err: err}
+			return Attr{Body: s, Err: err}
 		}
 		if i += n; i >= len(s) {
 			break
 		}
 		i += skipSpace(s[i:])
 		if s[i] != ',' {
-			return Attr{Err: errors.Newf(pos, "invalid attribute: expected comma")}
+			return Attr{Body: s, Err: errors.Newf(pos, "invalid attribute: expected comma")}
 		}
 		i++
 	}
@@ -30,9 +30,18 @@ func ParseValue(pos token.Pos, name, str string, k cue.Kind) (x ast.Expr, errs e
 	if k&cue.NumberKind != 0 {
 		var err error
 		expr, err = parser.ParseExpr(name, str)
-		if err != nil {
+		switch {
+		case err != nil:
 			errs = errors.Wrapf(err, pos,
 				"invalid number for environment variable %s", name)
+		case !isNumber(expr, k):
+			typ := "number"
+			if k&cue.NumberKind == cue.IntKind {
+				typ = "int"
+			}
+			errs = errors.Newf(pos,
+				"invalid %s %q for environment variable %s", typ, str, name)
+			expr = nil
 		}
 	}
 
@@ -75,6 +84,23 @@ func ParseValue(pos token.Pos, name, str string, k cue.Kind) (x ast.Expr, errs e
 	return nil, errs
 }
 
+// isNumber reports whether x is a number literal of a kind allowed by k.
+func isNumber(x ast.Expr, k cue.Kind) bool {
+	if u, ok := x.(*ast.UnaryExpr); ok && (u.Op == token.SUB || u.Op == token.ADD) {
+		x = u.X
+	}
+	lit, ok := x.(*ast.BasicLit)
+	switch {
+	case !ok:
+		return false
+	case lit.Kind == token.INT:
+		return k&cue.IntKind != 0
+	case lit.Kind == token.FLOAT:
+		return k&cue.FloatKind != 0
+	}
+	return false
+}
+
 var boolValues = map[string]bool{
 	"1":     true,
 	"0":     false,
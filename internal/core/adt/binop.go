@@ -17,6 +17,8 @@ package adt
 import (
 	"bytes"
 	"strings"
+
+	"cuelang.org/go/internal/experiment"
 )
 
 // BinOp handles all operations except AndOp and OrOp. This includes processing
@@ -82,6 +84,10 @@ func BinOp(c *OpContext, op Op, left, right Value) Value {
 				}
 			}
 			return c.newBool(true)
+
+		case leftKind == StructKind && rightKind == StructKind &&
+			c.experiments&experiment.StructCmp != 0:
+			return equalStructs(c, left, right, op)
 		}
 
 	case NotEqualOp:
@@ -120,6 +126,14 @@ func BinOp(c *OpContext, op Op, left, right Value) Value {
 				}
 			}
 			return c.newBool(false)
+
+		case leftKind == StructKind && rightKind == StructKind &&
+			c.experiments&experiment.StructCmp != 0:
+			v := equalStructs(c, left, right, op)
+			if b, ok := v.(*Bool); ok {
+				return c.newBool(!b.B)
+			}
+			return v
 		}
 
 	case LessThanOp, LessEqualOp, GreaterEqualOp, GreaterThanOp:
@@ -311,3 +325,55 @@ func cmpTonode(c *OpContext, op Op, r int) Value {
 	}
 	return c.newBool(result)
 }
+
+// equalStructs reports whether x and y have the same regular fields and
+// whether the values of these fields are equal. It returns a Bool or, if
+// the value of a field is not concrete or is an error, the resulting error.
+func equalStructs(c *OpContext, x, y Value, op Op) Value {
+	v, ok := x.(*Vertex)
+	if !ok {
+		return c.newBool(false)
+	}
+	w, ok := y.(*Vertex)
+	if !ok {
+		return c.newBool(false)
+	}
+	c.Unify(v, Finalized)
+	c.Unify(w, Finalized)
+
+	n := 0
+	for _, arc := range v.Arcs {
+		if !arc.Label.IsRegular() {
+			continue
+		}
+		n++
+		other := w.Lookup(arc.Label)
+		if other == nil {
+			return c.newBool(false)
+		}
+		a, _ := c.Concrete(nil, arc, op)
+		if b, ok := a.(*Bottom); ok {
+			return b
+		}
+		b, _ := c.Concrete(nil, other, op)
+		if b, ok := b.(*Bottom); ok {
+			return b
+		}
+		switch r := BinOp(c, EqualOp, a, b).(type) {
+		case *Bool:
+			if !r.B {
+				return r
+			}
+		case *Bottom:
+			return r
+		default:
+			return c.newBool(false)
+		}
+	}
+	for _, arc := range w.Arcs {
+		if arc.Label.IsRegular() {
+			n--
+		}
+	}
+	return c.newBool(n == 0)
+}
@@ -27,6 +27,7 @@ import (
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/experiment"
 )
 
 // Debug sets whether extra aggressive checking should be done.
@@ -123,6 +124,41 @@ type Runtime interface {
 	LoadType(t reflect.Type) (src ast.Expr, expr Expr, ok bool)
 }
 
+// An ErrorConfig may be implemented by a Runtime to configure how errors
+// are reported.
+type ErrorConfig interface {
+	// DisjunctionErrors reports the maximum number of failed disjuncts that
+	// are reported for an empty disjunction. The disjuncts that came closest
+	// to succeeding, those with the fewest errors, are reported first. Zero
+	// means that all failed disjuncts are reported.
+	DisjunctionErrors() int
+}
+
+// A CapabilityConfig may be implemented by a Runtime to restrict the
+// resources that may be used by the CUE it evaluates, for instance because
+// it comes from an untrusted source.
+type CapabilityConfig interface {
+	// MaxRegexpLen reports the maximum length in bytes of a regular
+	// expression. Zero means there is no limit.
+	MaxRegexpLen() int
+}
+
+// A MetricsConfig may be implemented by a Runtime to receive counts of the
+// operations of the OpContexts created for it.
+type MetricsConfig interface {
+	// Metrics returns the Metrics to which the counts are added, or nil if
+	// they are not collected.
+	Metrics() Metrics
+}
+
+// Metrics receives counts of evaluator operations, such as unifications and
+// allocations of the nodes used for evaluation. Implementations must be
+// safe for concurrent use.
+type Metrics interface {
+	// Add adds delta to the counter with the given name.
+	Add(name string, delta int64)
+}
+
 type Config struct {
 	Runtime
 	Format func(Node) string
@@ -141,6 +177,9 @@ func New(v *Vertex, cfg *Config) *OpContext {
 	if v != nil {
 		ctx.e = &Environment{Up: nil, Vertex: v}
 	}
+	if m, ok := cfg.Runtime.(MetricsConfig); ok {
+		ctx.metrics = m.Metrics()
+	}
 	return ctx
 }
 
@@ -154,6 +193,7 @@ type OpContext struct {
 	Format func(Node) string
 
 	stats        Stats
+	metrics      Metrics
 	freeListNode *nodeContext
 
 	e         *Environment
@@ -171,6 +211,10 @@ type OpContext struct {
 	nonMonotonicInsertNest int32
 	nonMonotonicGeneration int32
 
+	// experiments holds the experiments enabled for the binary expression
+	// that is currently being evaluated.
+	experiments experiment.Flags
+
 	// These fields are used associate scratch fields for computing closedness
 	// of a Vertex. These fields could have been included in StructInfo (like
 	// Tomabechi's unification algorithm), but we opted for an indirection to
@@ -195,6 +239,20 @@ type OpContext struct {
 	// inConstaint overrides inDisjunct as field matching should always be
 	// enabled.
 	inConstraint int
+
+	// builtinCache holds the results of calls to builtins with concrete
+	// scalar arguments made within this context.
+	builtinCache map[builtinKey]Expr
+
+	// validating is set while a builtin is used as a validator, as in
+	// strings.MinRunes(3), rather than called as a function.
+	validating bool
+}
+
+// IsValidating reports whether the builtin that is being called is used as a
+// validator rather than called as a function.
+func (c *OpContext) IsValidating() bool {
+	return c.validating
 }
 
 func (n *nodeContext) skipNonMonotonicChecks() bool {
@@ -1127,6 +1185,9 @@ func (c *OpContext) regexp(v Value) *regexp.Regexp {
 		if x.RE != nil {
 			return x.RE
 		}
+		if !c.checkRegexpLen(len(x.Str)) {
+			return matchNone
+		}
 		// TODO: synchronization
 		p, err := regexp.Compile(x.Str)
 		if err != nil {
@@ -1142,6 +1203,9 @@ func (c *OpContext) regexp(v Value) *regexp.Regexp {
 		if x.RE != nil {
 			return x.RE
 		}
+		if !c.checkRegexpLen(len(x.B)) {
+			return matchNone
+		}
 		// TODO: synchronization
 		p, err := regexp.Compile(string(x.B))
 		if err != nil {
@@ -1158,6 +1222,18 @@ func (c *OpContext) regexp(v Value) *regexp.Regexp {
 	}
 }
 
+// checkRegexpLen reports whether a regular expression of n bytes is within
+// the limit set by the Runtime, if any, and reports an error otherwise.
+func (c *OpContext) checkRegexpLen(n int) bool {
+	x, ok := c.Runtime.(CapabilityConfig)
+	if !ok || x.MaxRegexpLen() == 0 || n <= x.MaxRegexpLen() {
+		return true
+	}
+	c.AddErrf("regular expression of %d bytes exceeds limit of %d bytes",
+		n, x.MaxRegexpLen())
+	return false
+}
+
 // newNum creates a new number of the given kind. It reports an error value
 // instead if any error occurred.
 func (c *OpContext) newNum(d *apd.Decimal, k Kind, sources ...Node) Value {
@@ -15,6 +15,9 @@
 package adt
 
 import (
+	"sort"
+	"strings"
+
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/token"
 )
@@ -125,6 +128,7 @@ func (n *nodeContext) expandDisjuncts(
 	recursive, last bool) {
 
 	n.ctx.stats.DisjunctCount++
+	n.ctx.count("disjuncts")
 
 	node := n.node
 	defer func() {
@@ -171,6 +175,11 @@ func (n *nodeContext) expandDisjuncts(
 				break
 			}
 			if err != nil {
+				if n.ctx.disjunctionErrors() > 0 {
+					// Report all failures of the disjunct, so that they
+					// can be ranked.
+					err = arcErrors(x, err)
+				}
 				parent.disjunctErrs = append(parent.disjunctErrs, err)
 			}
 			if recursive {
@@ -521,11 +530,26 @@ func combineDefault(a, b defaultMode) defaultMode {
 func (n *nodeContext) disjunctError() (errs errors.Error) {
 	ctx := n.ctx
 
-	disjuncts := selectErrors(n.disjunctErrs)
+	a := n.disjunctErrs
+	omitted := 0
+	if max := ctx.disjunctionErrors(); max > 0 && len(a) > max {
+		a = rankErrors(a)
+		omitted = len(a) - max
+		a = a[:max]
+	}
+
+	disjuncts := selectErrors(a)
 
-	if disjuncts == nil {
+	switch {
+	case disjuncts == nil:
 		errs = ctx.Newf("empty disjunction") // XXX: add space to sort first
-	} else {
+	case omitted > 0:
+		disjuncts = errors.Sanitize(disjuncts)
+		k := len(errors.Errors(disjuncts))
+		errs = ctx.Newf(
+			"%d errors in empty disjunction (closest %d of %d disjuncts):",
+			k, len(a), len(a)+omitted)
+	default:
 		disjuncts = errors.Sanitize(disjuncts)
 		k := len(errors.Errors(disjuncts))
 		// prefix '-' to sort to top
@@ -537,6 +561,58 @@ func (n *nodeContext) disjunctError() (errs errors.Error) {
 	return errs
 }
 
+// disjunctionErrors reports the maximum number of failed disjuncts to report
+// for an empty disjunction, or 0 if all should be reported.
+func (c *OpContext) disjunctionErrors() int {
+	if x, ok := c.Runtime.(ErrorConfig); ok {
+		return x.DisjunctionErrors()
+	}
+	return 0
+}
+
+// arcErrors returns an error combining the errors of all arcs of v, or err
+// if there are none.
+func arcErrors(v *Vertex, err *Bottom) *Bottom {
+	var errs errors.Error
+	var walk func(v *Vertex)
+	walk = func(v *Vertex) {
+		for _, a := range v.Arcs {
+			if b, ok := a.BaseValue.(*Bottom); ok && !b.IsIncomplete() {
+				if !b.ChildError {
+					errs = errors.Append(errs, b.Err)
+				}
+			}
+			walk(a)
+		}
+	}
+	walk(v)
+	if errs == nil {
+		return err
+	}
+	b := *err
+	b.Err = errs
+	return &b
+}
+
+// rankErrors returns the errors of failed disjuncts ordered by how close the
+// disjuncts came to succeeding, which is approximated by the number of
+// distinct paths for which they reported an error.
+func rankErrors(a []*Bottom) []*Bottom {
+	a = append([]*Bottom(nil), a...)
+	score := make(map[*Bottom]int, len(a))
+	for _, b := range a {
+		paths := map[string]bool{}
+		for _, e := range errors.Errors(b.Err) {
+			paths[strings.Join(e.Path(), ".")] = true
+		}
+		score[b] = len(paths)
+	}
+	sort.SliceStable(a, func(i, j int) bool {
+		return score[a[i]] < score[a[j]]
+	})
+	return a
+}
+
 func selectErrors(a []*Bottom) (errs errors.Error) {
 	// return all errors if less than a certain number.
 	if len(a) <= 2 {
@@ -271,6 +271,27 @@ func appendNodePositions(a []token.Pos, n Node) []token.Pos {
 	return a
 }
 
+// messageKeys assigns stable keys to the messages of common evaluation errors,
+// so that they can be translated independently of their wording. When
+// changing the wording of one of these messages, update its format here, but
+// keep its key.
+//
+// See errors.Catalog.
+var messageKeys = map[string]string{
+	"conflicting values %s and %s":                              "eval.conflict",
+	"conflicting values %s and %s (mismatched types %s and %s)": "eval.conflict.types",
+	"conflicting value %s (mismatched types %s and %s)":         "eval.conflict.type",
+	"field not allowed: %s":                                     "eval.closed",
+	"undefined field: %s":                                       "eval.undefined",
+	"invalid value %v (out of bound %s)":                        "eval.bound",
+	"invalid value %s (does not satisfy %s)":                    "eval.validator",
+	"non-concrete value %v in operand to %s":                    "eval.nonconcrete",
+	"index out of range [%d] with length %d":                    "eval.index",
+	"division by zero":                                          "eval.divzero",
+	"empty disjunction":                                         "eval.disjunction",
+	"structural cycle":                                          "eval.cycle",
+}
+
 func (c *OpContext) NewPosf(p token.Pos, format string, args ...interface{}) *ValueError {
 	var a []token.Pos
 	if len(c.positions) > 0 {
@@ -299,7 +320,7 @@ func (c *OpContext) NewPosf(p token.Pos, format string, args ...interface{}) *Va
 		v:       c.errNode(),
 		pos:     p,
 		auxpos:  a,
-		Message: errors.NewMessage(format, args),
+		Message: errors.NewKeyedMessage(messageKeys[format], format, args),
 	}
 }
 
@@ -51,6 +51,10 @@ type Stats struct {
 	Retained int
 	Reused   int
 	Allocs   int
+
+	// BuiltinCacheHits is the number of calls to builtins that were
+	// answered from the cache.
+	BuiltinCacheHits int
 }
 
 // Leaks reports the number of nodeContext structs leaked. These are typically
@@ -83,6 +87,14 @@ func (c *OpContext) Stats() *Stats {
 	return &c.stats
 }
 
+// count adds one to the counter with the given name of the Metrics of the
+// Runtime, if any.
+func (c *OpContext) count(name string) {
+	if c.metrics != nil {
+		c.metrics.Add(name, 1)
+	}
+}
+
 // TODO: Note: NewContext takes essentially a cue.Value. By making this
 // type more central, we can perhaps avoid context creation.
 
@@ -214,6 +226,7 @@ func (c *OpContext) Unify(v *Vertex, state VertexStatus) {
 		defer c.PopArc(c.PushArc(v))
 
 		c.stats.UnifyCount++
+		c.count("unifications")
 
 		// Clear any remaining error.
 		if err := c.Err(); err != nil {
@@ -882,6 +895,7 @@ func (n *nodeContext) clone() *nodeContext {
 func (c *OpContext) newNodeContext(node *Vertex) *nodeContext {
 	if n := c.freeListNode; n != nil {
 		c.stats.Reused++
+		c.count("nodes_reused")
 		c.freeListNode = n.nextFree
 
 		*n = nodeContext{
@@ -908,6 +922,7 @@ func (c *OpContext) newNodeContext(node *Vertex) *nodeContext {
 		return n
 	}
 	c.stats.Allocs++
+	c.count("nodes_allocated")
 
 	return &nodeContext{
 		ctx:  c,
@@ -966,6 +981,7 @@ func (n *nodeContext) free() {
 
 func (c *OpContext) freeNodeContext(n *nodeContext) {
 	c.stats.Freed++
+	c.count("nodes_freed")
 	n.nextFree = c.freeListNode
 	c.freeListNode = n
 	n.node = nil
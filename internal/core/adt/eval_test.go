@@ -24,13 +24,13 @@ import (
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/cuetxtar"
 	"cuelang.org/go/internal/core/adt"
 	"cuelang.org/go/internal/core/debug"
 	"cuelang.org/go/internal/core/eval"
 	"cuelang.org/go/internal/core/runtime"
 	"cuelang.org/go/internal/core/validate"
 	"cuelang.org/go/internal/cuetest"
-	"cuelang.org/go/internal/cuetxtar"
 	_ "cuelang.org/go/pkg"
 )
 
@@ -98,6 +98,82 @@ var needFix = map[string]string{
 	"DIR/NAME": "reason",
 }
 
+func TestBuiltinCache(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		hits int
+		out  string
+	}{{
+		name: "repeated scalar arguments",
+		in: `
+		import "regexp"
+
+		names: ["a-1", "b-2", "a-1", "a-1"]
+		out: [for n in names { regexp.Find(#"\d"#, n) }]
+		`,
+		hits: 2,
+		out:  `{names:["a-1","b-2","a-1","a-1"],out:["1","2","1","1"]}`,
+	}, {
+		name: "int and float arguments differ",
+		in: `
+		import "strconv"
+
+		a: strconv.FormatFloat(1, 102, 1, 64)
+		b: strconv.FormatFloat(1.0, 102, 1, 64)
+		c: strconv.FormatFloat(1, 102, 1, 64)
+		`,
+		hits: 1,
+		out:  `{a:"1.0",b:"1.0",c:"1.0"}`,
+	}, {
+		name: "errors are not cached",
+		in: `
+		import "strconv"
+
+		a: strconv.Atoi("x") | 1
+		b: strconv.Atoi("x") | 2
+		`,
+		out: `{a:1,b:2}`,
+	}, {
+		name: "list arguments are not cached",
+		in: `
+		import "strings"
+
+		a: strings.Join(["a", "b"], "-")
+		b: strings.Join(["a", "b"], "-")
+		`,
+		out: `{a:"a-b",b:"a-b"}`,
+	}, {
+		name: "list results are not cached",
+		in: `
+		import "strings"
+
+		a: strings.Split("a,b", ",")
+		b: strings.Split("a,b", ",")
+		`,
+		out: `{a:["a","b"],b:["a","b"]}`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := runtime.New()
+			v, inst := r.Compile(nil, tc.in)
+			if inst.Err != nil {
+				t.Fatal(inst.Err)
+			}
+			ctx := eval.NewContext(r, v)
+			v.Finalize(ctx)
+
+			if got := ctx.Stats().BuiltinCacheHits; got != tc.hits {
+				t.Errorf("hits: got %d; want %d", got, tc.hits)
+			}
+			got := debug.NodeString(r, v, &debug.Config{Compact: true})
+			if got != tc.out {
+				t.Errorf("got %s; want %s", got, tc.out)
+			}
+		})
+	}
+}
+
 // TestX is for debugging. Do not delete.
 func TestX(t *testing.T) {
 	in := `
@@ -19,12 +19,14 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"strings"
 
 	"github.com/cockroachdb/apd/v2"
 
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/experiment"
 )
 
 // A StructLit represents an unevaluated struct literal or file body.
@@ -1138,6 +1140,10 @@ type BinaryExpr struct {
 	Op  Op
 	X   Expr
 	Y   Expr
+
+	// Experiments holds the experiments enabled for the file in which the
+	// expression is defined.
+	Experiments experiment.Flags
 }
 
 func (x *BinaryExpr) Source() ast.Node {
@@ -1184,7 +1190,11 @@ func (x *BinaryExpr) evaluate(c *OpContext) Value {
 		return err
 	}
 
-	return BinOp(c, x.Op, left, right)
+	saved := c.experiments
+	c.experiments = x.Experiments
+	v := BinOp(c, x.Op, left, right)
+	c.experiments = saved
+	return v
 }
 
 func (c *OpContext) validate(env *Environment, src ast.Node, x Expr, op Op) (r Value) {
@@ -1310,7 +1320,7 @@ func (x *CallExpr) evaluate(c *OpContext) Value {
 	if b.IsValidator(len(args)) {
 		return &BuiltinValidator{x, b, args}
 	}
-	result := b.call(c, pos(x), args)
+	result := b.call(c, pos(x), false, args)
 	if result == nil {
 		return nil
 	}
@@ -1324,6 +1334,11 @@ type Builtin struct {
 	Result Kind
 	Func   func(c *OpContext, args []Value) Expr
 
+	// Pure indicates that the result of Func depends only on its arguments,
+	// so that the scalar results of calls with equal arguments may be
+	// reused.
+	Pure bool
+
 	Package Feature
 	Name    string
 }
@@ -1380,7 +1395,7 @@ func bottom(v Value) *Bottom {
 	return b
 }
 
-func (x *Builtin) call(c *OpContext, p token.Pos, args []Value) Expr {
+func (x *Builtin) call(c *OpContext, p token.Pos, validate bool, args []Value) Expr {
 	fun := x // right now always x.
 	if len(args) > len(x.Params) {
 		c.addErrf(0, p,
@@ -1431,7 +1446,93 @@ func (x *Builtin) call(c *OpContext, p token.Pos, args []Value) Expr {
 			args[i] = n
 		}
 	}
-	return x.Func(c, args)
+	saved := c.validating
+	c.validating = validate
+	defer func() { c.validating = saved }()
+
+	if !x.Pure {
+		return x.Func(c, args)
+	}
+	key, ok := builtinCallKey(x, args)
+	if !ok {
+		return x.Func(c, args)
+	}
+	key.validate = validate
+	if r, ok := c.builtinCache[key]; ok {
+		c.stats.BuiltinCacheHits++
+		c.count("builtin_cache_hits")
+		return atSource(r, c.src)
+	}
+	r := x.Func(c, args)
+	switch r.(type) {
+	case *String, *Bytes, *Num, *Bool, *Null:
+		// Lists and structs are not cached, as each call site needs its own
+		// Vertex. Errors are not cached, as they refer to the position of
+		// the call.
+		if c.builtinCache == nil {
+			c.builtinCache = map[builtinKey]Expr{}
+		}
+		c.builtinCache[key] = r
+	}
+	return r
+}
+
+// atSource returns a copy of the cached scalar x with source src, so that
+// errors involving the result of a call refer to the call site.
+func atSource(x Expr, src ast.Node) Expr {
+	switch x := x.(type) {
+	case *String:
+		return &String{Src: src, Str: x.Str, RE: x.RE}
+	case *Bytes:
+		return &Bytes{Src: src, B: x.B, RE: x.RE}
+	case *Num:
+		return &Num{Src: src, K: x.K, X: x.X}
+	case *Bool:
+		return &Bool{Src: src, B: x.B}
+	case *Null:
+		return &Null{Src: src}
+	}
+	return x
+}
+
+// A builtinKey identifies a call to a builtin by the builtin, the values of
+// its arguments, and whether it is used as a validator.
+type builtinKey struct {
+	builtin  *Builtin
+	args     string
+	validate bool
+}
+
+// builtinCallKey returns the key under which the result of calling x with
+// args is cached. As x is pure, calls with equal arguments yield equal
+// results. Only calls with concrete scalar arguments, such as those to
+// regular expression, hashing, and parsing functions, are cached. It
+// reports false if the call may not be cached.
+func builtinCallKey(x *Builtin, args []Value) (key builtinKey, ok bool) {
+	var b strings.Builder
+	for _, a := range args {
+		if v, ok := a.(*Vertex); ok {
+			if v.status != Finalized || len(v.Arcs) > 0 {
+				return key, false
+			}
+			a = v.Value()
+		}
+		switch a := a.(type) {
+		case *String:
+			fmt.Fprintf(&b, "s%d:%s", len(a.Str), a.Str)
+		case *Bytes:
+			fmt.Fprintf(&b, "b%d:%s", len(a.B), a.B)
+		case *Num:
+			fmt.Fprintf(&b, "n%d:%s;", a.K, a.X.String())
+		case *Bool:
+			fmt.Fprintf(&b, "t%v;", a.B)
+		case *Null:
+			b.WriteString("z;")
+		default:
+			return key, false
+		}
+	}
+	return builtinKey{builtin: x, args: b.String()}, true
 }
 
 func (x *Builtin) Source() ast.Node { return nil }
@@ -1477,7 +1578,7 @@ func validateWithBuiltin(c *OpContext, src token.Pos, b *Builtin, args []Value)
 	var severeness ErrorCode
 	var err errors.Error
 
-	res := b.call(c, src, args)
+	res := b.call(c, src, true, args)
 	switch v := res.(type) {
 	case nil:
 		return nil
@@ -18,12 +18,14 @@ import (
 	"strings"
 
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/literal"
 	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/astinternal"
 	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/experiment"
 )
 
 // A Scope represents a nested scope of Vertices.
@@ -46,6 +48,10 @@ type Config struct {
 	// automatically resolve identifiers to imports.
 	Imports func(x *ast.Ident) (pkgPath string)
 
+	// Experiments holds the experiments enabled for all files. A file may
+	// enable additional experiments with an @experiment attribute.
+	Experiments experiment.Flags
+
 	// pkgPath is used to qualify the scope of hidden fields. The default
 	// scope is "_".
 	pkgPath string
@@ -107,6 +113,9 @@ type compiler struct {
 
 	fileScope map[adt.Feature]bool
 
+	// experiments holds the experiments enabled for the file being compiled.
+	experiments experiment.Flags
+
 	num literal.NumInfo
 
 	errs errors.Error
@@ -280,6 +289,10 @@ func (c *compiler) compileFiles(a []*ast.File) *adt.Vertex { // Or value?
 	}
 
 	for _, file := range a {
+		x, err := experiment.File(file)
+		c.errs = errors.Append(c.errs, err)
+		c.experiments = c.Config.Experiments | x
+
 		c.pushScope(nil, 0, file) // File scope
 		v := &adt.StructLit{Src: file}
 		c.addDecls(v, file.Decls)
@@ -291,6 +304,8 @@ func (c *compiler) compileFiles(a []*ast.File) *adt.Vertex { // Or value?
 }
 
 func (c *compiler) compileExpr(x ast.Expr) adt.Conjunct {
+	c.experiments = c.Config.Experiments
+
 	expr := c.expr(x)
 
 	env := &adt.Environment{}
@@ -314,9 +329,15 @@ func (c *compiler) resolve(n *ast.Ident) adt.Expr {
 	// X in import "path/X"
 	// X in import X "path"
 	if imp, ok := n.Node.(*ast.ImportSpec); ok {
+		importPath := c.label(imp.Path)
+		if len(imp.Attrs) > 0 {
+			// The import ID of a parameterized import includes its tags.
+			info, _ := astutil.ParseImportSpec(imp)
+			importPath = adt.MakeStringLabel(c.index, info.ID)
+		}
 		return &adt.ImportReference{
 			Src:        n,
-			ImportPath: c.label(imp.Path),
+			ImportPath: importPath,
 			Label:      c.label(n),
 		}
 	}
@@ -959,7 +980,9 @@ func (c *compiler) expr(expr ast.Expr) adt.Expr {
 				c.assertConcreteIsPossible(n.Y, op, y)
 			}
 			// return updateBin(c,
-			return &adt.BinaryExpr{Src: n, Op: op, X: x, Y: y} // )
+			b := &adt.BinaryExpr{Src: n, Op: op, X: x, Y: y} // )
+			b.Experiments = c.experiments
+			return b
 		}
 
 	default:
@@ -164,7 +164,7 @@ func (e *exporter) adt(expr adt.Expr, conjuncts []adt.Conjunct) ast.Expr {
 
 	case *adt.ImportReference:
 		importPath := x.ImportPath.StringValue(e.index)
-		spec := ast.NewImport(nil, importPath)
+		spec := astutil.NewImport(nil, importPath)
 
 		info, _ := astutil.ParseImportSpec(spec)
 		name := info.PkgName
@@ -48,6 +48,17 @@ type Profile struct {
 	ShowDocs       bool
 	ShowAttributes bool
 
+	// PreserveLiterals writes string and bytes values that are defined by a
+	// literal in the form in which they were written, for instance as a raw
+	// or multiline string, instead of normalizing them.
+	PreserveLiterals bool
+
+	// Partial writes the expressions of values that are incomplete with the
+	// subexpressions that evaluate to concrete scalar values replaced by
+	// these values, so that only references to values that are not yet
+	// known remain.
+	Partial bool
+
 	// ShowErrors treats errors as values and will not percolate errors up.
 	//
 	// TODO: convert this option to an error level instead, showing only
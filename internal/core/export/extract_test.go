@@ -18,13 +18,13 @@ import (
 	"fmt"
 	"testing"
 
+	"cuelang.org/go/cue/cuetxtar"
 	"cuelang.org/go/internal/core/adt"
 	"cuelang.org/go/internal/core/compile"
 	"cuelang.org/go/internal/core/eval"
 	"cuelang.org/go/internal/core/export"
 	"cuelang.org/go/internal/core/runtime"
 	"cuelang.org/go/internal/cuetest"
-	"cuelang.org/go/internal/cuetxtar"
 )
 
 func TestExtract(t *testing.T) {
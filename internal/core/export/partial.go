@@ -0,0 +1,130 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package export
+
+import (
+	"bytes"
+
+	"cuelang.org/go/internal/core/adt"
+)
+
+// partial replaces the subexpressions of x that evaluate to a concrete
+// scalar value in env with that value. Subexpressions that introduce a new
+// scope, such as structs and comprehensions, are left as is.
+func (e *exporter) partial(env *adt.Environment, x adt.Expr) adt.Expr {
+	if _, ok := x.(adt.Value); ok {
+		return x
+	}
+	if v := e.concreteScalar(env, x); v != nil {
+		return v
+	}
+
+	switch x := x.(type) {
+	case *adt.Interpolation:
+		return e.partialInterpolation(env, x)
+
+	case *adt.UnaryExpr:
+		y := *x
+		y.X = e.partial(env, x.X)
+		return &y
+
+	case *adt.BinaryExpr:
+		y := *x
+		y.X = e.partial(env, x.X)
+		y.Y = e.partial(env, x.Y)
+		return &y
+
+	case *adt.CallExpr:
+		y := *x
+		y.Args = make([]adt.Expr, len(x.Args))
+		for i, a := range x.Args {
+			y.Args[i] = e.partial(env, a)
+		}
+		return &y
+
+	case *adt.ListLit:
+		y := *x
+		y.Elems = make([]adt.Elem, len(x.Elems))
+		for i, a := range x.Elems {
+			if a, ok := a.(adt.Expr); ok {
+				y.Elems[i] = e.partial(env, a)
+			} else {
+				y.Elems[i] = x.Elems[i]
+			}
+		}
+		return &y
+	}
+	return x
+}
+
+// concreteScalar returns the value of x in env if it is a concrete scalar
+// value, or nil otherwise.
+func (e *exporter) concreteScalar(env *adt.Environment, x adt.Expr) adt.Value {
+	v, complete := e.ctx.Evaluate(env, x)
+	if !complete {
+		return nil
+	}
+	if n, ok := v.(*adt.Vertex); ok {
+		v = n.Value()
+	}
+	switch v.(type) {
+	case *adt.Null, *adt.Bool, *adt.Num, *adt.String, *adt.Bytes:
+		return v
+	}
+	return nil
+}
+
+// partialInterpolation merges the parts of x that evaluate to a concrete
+// value into the surrounding literal parts.
+func (e *exporter) partialInterpolation(env *adt.Environment, x *adt.Interpolation) adt.Expr {
+	lit := func(b []byte) adt.Expr {
+		if x.K == adt.BytesKind {
+			return &adt.Bytes{B: b}
+		}
+		return &adt.String{Str: string(b)}
+	}
+	write := func(buf *bytes.Buffer, v adt.Value) {
+		if x.K == adt.BytesKind {
+			buf.Write(e.ctx.ToBytes(v))
+		} else {
+			buf.WriteString(e.ctx.ToString(v))
+		}
+	}
+
+	var parts []adt.Expr
+	buf := bytes.Buffer{}
+	for i, p := range x.Parts {
+		if i%2 == 0 {
+			if v, ok := p.(adt.Value); ok {
+				write(&buf, v)
+			}
+			continue
+		}
+		p = e.partial(env, p)
+		switch v := p.(type) {
+		case *adt.Bool, *adt.Num, *adt.String, *adt.Bytes:
+			write(&buf, v.(adt.Value))
+		default:
+			parts = append(parts, lit(append([]byte(nil), buf.Bytes()...)), p)
+			buf.Reset()
+		}
+	}
+	if parts == nil {
+		return lit(buf.Bytes())
+	}
+	y := *x
+	y.Parts = append(parts, lit(buf.Bytes()))
+	return &y
+}
@@ -99,7 +99,11 @@ func (e *exporter) vertex(n *adt.Vertex) (result ast.Expr) {
 		// fall back to expression mode
 		a := []ast.Expr{}
 		for _, c := range n.Conjuncts {
-			a = append(a, e.expr(c.Expr()))
+			x := c.Expr()
+			if e.cfg.Partial {
+				x = e.partial(c.Env, x)
+			}
+			a = append(a, e.expr(x))
 		}
 		result = ast.NewBinExpr(token.AND, a...)
 	}
@@ -263,6 +267,21 @@ func extractBasic(a []adt.Conjunct) *ast.BasicLit {
 	return nil
 }
 
+// extractLiteral returns a copy of the string or bytes literal that defines
+// the value of a field, if PreserveLiterals is set and there is one.
+func (e *exporter) extractLiteral(a []adt.Conjunct) *ast.BasicLit {
+	if !e.cfg.PreserveLiterals {
+		return nil
+	}
+	for _, c := range a {
+		b, ok := c.Expr().Source().(*ast.BasicLit)
+		if ok && b.Kind == token.STRING {
+			return &ast.BasicLit{Kind: b.Kind, Value: b.Value}
+		}
+	}
+	return nil
+}
+
 func (e *exporter) num(n *adt.Num, orig []adt.Conjunct) *ast.BasicLit {
 	// TODO: take original formatting into account.
 	if b := extractBasic(orig); b != nil {
@@ -284,6 +303,9 @@ func (e *exporter) string(n *adt.String, orig []adt.Conjunct) *ast.BasicLit {
 	if b := extractBasic(orig); b != nil {
 		return b
 	}
+	if b := e.extractLiteral(orig); b != nil {
+		return b
+	}
 	s := literal.String.WithOptionalTabIndent(len(e.stack)).Quote(n.Str)
 	return &ast.BasicLit{
 		Kind:  token.STRING,
@@ -296,6 +318,9 @@ func (e *exporter) bytes(n *adt.Bytes, orig []adt.Conjunct) *ast.BasicLit {
 	if b := extractBasic(orig); b != nil {
 		return b
 	}
+	if b := e.extractLiteral(orig); b != nil {
+		return b
+	}
 	s := literal.Bytes.WithOptionalTabIndent(len(e.stack)).Quote(string(n.B))
 	return &ast.BasicLit{
 		Kind:  token.STRING,
@@ -25,12 +25,14 @@ import (
 	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/core/adt"
 	"cuelang.org/go/internal/core/compile"
+	"cuelang.org/go/internal/experiment"
 )
 
 type Config struct {
 	Runtime    *Runtime
 	Filename   string
 	ImportPath string
+	CacheKey   string
 
 	compile.Config
 }
@@ -70,12 +72,23 @@ func (x *Runtime) Build(cfg *Config, b *build.Instance) (v *adt.Vertex, errs err
 	if cfg != nil {
 		cc = &cfg.Config
 	}
+	if len(b.Experiments) > 0 {
+		flags, err := experiment.Parse(token.NoPos, b.Experiments)
+		errs = errors.Append(errs, err)
+		c := compile.Config{}
+		if cc != nil {
+			c = *cc
+		}
+		c.Experiments |= flags
+		cc = &c
+	}
 	if cfg != nil && cfg.ImportPath != "" {
 		b.ImportPath = cfg.ImportPath
 		b.PkgName = astutil.ImportPathName(b.ImportPath)
 	}
 	v, err = compile.Files(cc, x, b.ID(), b.Files...)
 	errs = errors.Append(errs, err)
+	x.count("instances")
 
 	if errs != nil {
 		v = adt.ToVertex(&adt.Bottom{Err: errs})
@@ -134,6 +147,8 @@ func (x *Runtime) buildSpec(cfg *Config, b *build.Instance, spec *ast.ImportSpec
 		} else if x.index.builtinPaths[info.ID] == nil {
 			return errors.Newf(spec.Pos(),
 				"builtin package %q undefined", info.ID)
+		} else if !x.PackageAllowed(info.ID) {
+			return errPackageNotAllowed(spec.Pos(), info.ID)
 		}
 		return nil
 	}
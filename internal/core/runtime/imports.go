@@ -20,6 +20,7 @@ import (
 
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal/core/adt"
 )
 
@@ -104,6 +105,27 @@ func (r *Runtime) AddInst(path string, key *adt.Vertex, p *build.Instance) {
 	}
 }
 
+// RemoveInst removes the instance with root key from the index and the
+// build data of r. Builtin packages are never removed.
+func (r *Runtime) RemoveInst(key *adt.Vertex) {
+	r.index.lock.Lock()
+	defer r.index.lock.Unlock()
+
+	x := r.index
+	p := x.imports[key]
+	if p == nil || x.builtinPaths[p.ImportPath] != nil {
+		return
+	}
+	delete(x.imports, key)
+	if x.importsByBuild[p] == key {
+		delete(x.importsByBuild, p)
+	}
+	if x.importsByPath[p.ImportPath] == key {
+		delete(x.importsByPath, p.ImportPath)
+	}
+	delete(r.loaded, p)
+}
+
 func (r *Runtime) GetInstanceFromNode(key *adt.Vertex) *build.Instance {
 	r.index.lock.RLock()
 	defer r.index.lock.RUnlock()
@@ -124,6 +146,10 @@ func (r *Runtime) LoadImport(importPath string) *adt.Vertex {
 
 	x := r.index
 
+	if x.builtinPaths[importPath] != nil && !r.PackageAllowed(importPath) {
+		return adt.ToVertex(&adt.Bottom{Err: errPackageNotAllowed(token.NoPos, importPath)})
+	}
+
 	key := x.importsByPath[importPath]
 	if key != nil {
 		return key
@@ -148,3 +174,7 @@ func (r *Runtime) LoadImport(importPath string) *adt.Vertex {
 
 	return key
 }
+
+func errPackageNotAllowed(pos token.Pos, importPath string) errors.Error {
+	return errors.Newf(pos, "use of builtin package %q not allowed", importPath)
+}
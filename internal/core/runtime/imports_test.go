@@ -0,0 +1,65 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runtime
+
+import (
+	"testing"
+
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/core/adt"
+)
+
+func TestRemoveInst(t *testing.T) {
+	r := New()
+
+	p := build.NewContext().NewInstance("foo.cue", nil)
+	p.ImportPath = "acme.com/foo"
+	if err := p.AddFile("foo.cue", "package foo\na: 1"); err != nil {
+		t.Fatal(err)
+	}
+	v, err := r.Build(nil, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetBuildData(p, "data")
+	if r.LoadImport("acme.com/foo") != v {
+		t.Fatal("instance not registered by path")
+	}
+
+	r.RemoveInst(v)
+	if r.GetInstanceFromNode(v) != nil {
+		t.Error("instance still registered by node")
+	}
+	if r.getNodeFromInstance(p) != nil {
+		t.Error("instance still registered by build instance")
+	}
+	if r.LoadImport("acme.com/foo") != nil {
+		t.Error("instance still registered by path")
+	}
+	if _, ok := r.BuildData(p); ok {
+		t.Error("build data not removed")
+	}
+
+	// Builtin packages are never removed.
+	RegisterBuiltin("acme.com/builtin", func(adt.Runtime) (*adt.Vertex, errors.Error) {
+		return &adt.Vertex{}, nil
+	})
+	builtin := r.LoadImport("acme.com/builtin")
+	r.RemoveInst(builtin)
+	if r.LoadImport("acme.com/builtin") != builtin {
+		t.Error("builtin package removed")
+	}
+}
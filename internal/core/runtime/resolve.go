@@ -15,10 +15,8 @@
 package runtime
 
 import (
-	"path"
-	"strconv"
-
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
 	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/internal"
@@ -76,11 +74,12 @@ func resolveFile(
 	specs := []*ast.ImportSpec{}
 
 	for _, spec := range f.Imports {
-		id, err := strconv.Unquote(spec.Path.Value)
+		info, err := astutil.ParseImportSpec(spec)
 		if err != nil {
 			continue // quietly ignore the error
 		}
-		name := path.Base(id)
+		id := info.ID
+		name := info.PkgName
 		if imp := p.LookupImport(id); imp != nil {
 			name = imp.PkgName
 		} else if _, ok := idx.builtinPaths[id]; !ok {
@@ -15,7 +15,10 @@
 package runtime
 
 import (
+	"strings"
+
 	"cuelang.org/go/cue/build"
+	"cuelang.org/go/internal/core/adt"
 )
 
 // A Runtime maintains data structures for indexing and resuse for evaluation.
@@ -23,6 +26,125 @@ type Runtime struct {
 	index *index
 
 	loaded map[*build.Instance]interface{}
+
+	disjunctionErrors int
+
+	// seed is the secret from which builtins derive pseudo-random values.
+	seed []byte
+
+	// buildCache holds a cue.BuildCache, which cannot be referred to from
+	// this package.
+	buildCache interface{}
+
+	metrics adt.Metrics
+
+	// allowed and denied hold the patterns of the builtin packages that may
+	// and may not be used. See PackageAllowed.
+	allowed []string
+	denied  []string
+
+	// maxRegexpLen is the maximum length of a regular expression, or 0 if
+	// there is no limit.
+	maxRegexpLen int
+}
+
+// SetDisjunctionErrors sets the maximum number of failed disjuncts reported
+// for an empty disjunction. Zero, the default, reports all of them.
+func (r *Runtime) SetDisjunctionErrors(n int) {
+	r.disjunctionErrors = n
+}
+
+// DisjunctionErrors implements adt.ErrorConfig.
+func (r *Runtime) DisjunctionErrors() int {
+	return r.disjunctionErrors
+}
+
+// SetSeed sets the secret from which builtins derive pseudo-random values.
+func (r *Runtime) SetSeed(seed []byte) {
+	r.seed = seed
+}
+
+// Seed reports the secret set with SetSeed, or nil if there is none.
+func (r *Runtime) Seed() []byte {
+	return r.seed
+}
+
+// SetBuildCache sets the cache of built instances used by the cue package.
+func (r *Runtime) SetBuildCache(c interface{}) {
+	r.buildCache = c
+}
+
+// BuildCache reports the cache set with SetBuildCache.
+func (r *Runtime) BuildCache() interface{} {
+	return r.buildCache
+}
+
+// SetMetrics sets the Metrics to which the counts of operations are added.
+func (r *Runtime) SetMetrics(m adt.Metrics) {
+	r.metrics = m
+}
+
+// Metrics implements adt.MetricsConfig.
+func (r *Runtime) Metrics() adt.Metrics {
+	return r.metrics
+}
+
+// count adds one to the counter with the given name of the Metrics, if any.
+func (r *Runtime) count(name string) {
+	if r.metrics != nil {
+		r.metrics.Add(name, 1)
+	}
+}
+
+// SetAllowedPackages restricts the builtin packages that may be used to
+// those matching any of the given patterns. See PackageAllowed.
+func (r *Runtime) SetAllowedPackages(patterns []string) {
+	r.allowed = patterns
+}
+
+// SetDeniedPackages prohibits the use of the builtin packages matching any
+// of the given patterns. See PackageAllowed.
+func (r *Runtime) SetDeniedPackages(patterns []string) {
+	r.denied = patterns
+}
+
+// PackageAllowed reports whether the builtin package with the given import
+// path may be used. A package may be used if it matches a pattern set with
+// SetAllowedPackages, if there are any, and does not match a pattern set
+// with SetDeniedPackages. A pattern is either an import path or an import
+// path followed by "/...", which also matches all packages below it. The
+// pattern "..." matches all packages.
+func (r *Runtime) PackageAllowed(importPath string) bool {
+	if r.allowed != nil && !matchPackage(r.allowed, importPath) {
+		return false
+	}
+	return !matchPackage(r.denied, importPath)
+}
+
+func matchPackage(patterns []string, importPath string) bool {
+	for _, p := range patterns {
+		switch {
+		case p == "...", p == importPath:
+			return true
+		case strings.HasSuffix(p, "/..."):
+			p = strings.TrimSuffix(p, "/...")
+			if importPath == p || strings.HasPrefix(importPath, p+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetMaxRegexpLen limits the length of the regular expressions used in
+// CUE to n bytes. Zero, the default, means there is no limit.
+func (r *Runtime) SetMaxRegexpLen(n int) {
+	r.maxRegexpLen = n
+}
+
+// MaxRegexpLen implements adt.CapabilityConfig.
+func (r *Runtime) MaxRegexpLen() int {
+	return r.maxRegexpLen
 }
 
 func (r *Runtime) SetBuildData(b *build.Instance, x interface{}) {
@@ -16,12 +16,14 @@ package encoding
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
@@ -29,12 +31,18 @@ import (
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/helm"
+	"cuelang.org/go/encoding/json"
+	"cuelang.org/go/encoding/jsonschema"
 	"cuelang.org/go/encoding/openapi"
 	"cuelang.org/go/encoding/protobuf/jsonpb"
 	"cuelang.org/go/encoding/protobuf/textproto"
+	"cuelang.org/go/encoding/starlark"
+	"cuelang.org/go/encoding/terraform"
 	"cuelang.org/go/internal"
+	jsonenc "cuelang.org/go/internal/encoding/json"
+	yamlenc "cuelang.org/go/internal/encoding/yaml"
 	"cuelang.org/go/internal/filetypes"
-	"cuelang.org/go/pkg/encoding/yaml"
 )
 
 // An Encoder converts CUE to various file formats, including CUE itself.
@@ -45,8 +53,10 @@ type Encoder struct {
 	interpret    func(cue.Value) (*ast.File, error)
 	encFile      func(*ast.File) error
 	encValue     func(cue.Value) error
+	encSyntax    func(ast.Expr) error // used for formatted numbers
 	autoSimplify bool
 	concrete     bool
+	encoding     build.Encoding
 	instance     *cue.Instance
 }
 
@@ -72,16 +82,26 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 	if err != nil {
 		return nil, err
 	}
+	switch f.Encoding {
+	case build.Binary, build.BinaryProto:
+	default:
+		if cfg.Newline != "" && cfg.Newline != "\n" {
+			w = &newlineWriter{w: w, newline: []byte(cfg.Newline)}
+		}
+	}
 	e := &Encoder{
-		cfg:   cfg,
-		close: close,
+		cfg:      cfg,
+		close:    close,
+		encoding: f.Encoding,
 	}
 
 	switch f.Interpretation {
 	case "":
 	case build.OpenAPI:
-		// TODO: get encoding options
-		cfg := &openapi.Config{}
+		cfg := &openapi.Config{
+			ExpandReferences: cfg.ExpandReferences,
+			MaxCycleDepth:    cfg.MaxCycleDepth,
+		}
 		e.interpret = func(v cue.Value) (*ast.File, error) {
 			i := e.instance
 			if i == nil {
@@ -95,12 +115,43 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 			return f, jsonpb.NewEncoder(v).RewriteFile(f)
 		}
 
-	// case build.JSONSchema:
-	// 	// TODO: get encoding options
-	// 	cfg := openapi.Config{}
-	// 	i.interpret = func(inst *cue.Instance) (*ast.File, error) {
-	// 		return jsonschmea.Generate(inst, cfg)
-	// 	}
+	case build.JSONSchema:
+		strict := cfg.Strict
+		cfg := &openapi.Config{
+			ExpandReferences: cfg.ExpandReferences,
+			MaxCycleDepth:    cfg.MaxCycleDepth,
+		}
+		e.interpret = func(v cue.Value) (*ast.File, error) {
+			// Schemas imported from JSON Schema are converted back directly.
+			if fromJSONSchema(v) {
+				return jsonschema.Generate(v, &jsonschema.Config{Strict: strict})
+			}
+			i := e.instance
+			if i == nil {
+				i = internal.MakeInstance(v).(*cue.Instance)
+			}
+			f, err := openapi.Generate(i, cfg)
+			if err != nil {
+				return nil, err
+			}
+			return openAPIToJSONSchema(f), nil
+		}
+	case build.Helm:
+		cfg := &helm.Config{
+			ExpandReferences: cfg.ExpandReferences,
+			MaxCycleDepth:    cfg.MaxCycleDepth,
+		}
+		e.interpret = func(v cue.Value) (*ast.File, error) {
+			i := e.instance
+			if i == nil {
+				i = internal.MakeInstance(v).(*cue.Instance)
+			}
+			return helm.ValuesSchema(i, cfg)
+		}
+	case build.Terraform:
+		e.interpret = func(v cue.Value) (*ast.File, error) {
+			return terraform.Generate(v)
+		}
 	default:
 		return nil, fmt.Errorf("unsupported interpretation %q", f.Interpretation)
 	}
@@ -126,6 +177,7 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 			cue.Definitions(fi.Definitions),
 			cue.ResolveReferences(!fi.References),
 			cue.DisallowCycles(!fi.Cycles),
+			cue.PreserveLiterals(cfg.PreserveLiterals),
 		)
 
 		opts := []format.Option{}
@@ -162,19 +214,36 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 
 	case build.JSON, build.JSONL:
 		e.concrete = true
-		d := json.NewEncoder(w)
-		d.SetIndent("", "    ")
-		d.SetEscapeHTML(cfg.EscapeHTML)
+		jc, err := jsonConfig(f, cfg)
+		if err != nil {
+			return nil, err
+		}
 		e.encValue = func(v cue.Value) error {
-			err := d.Encode(v)
-			if x, ok := err.(*json.MarshalerError); ok {
-				err = x.Err
+			b, err := jc.Marshal(v)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(b)
+			return err
+		}
+		e.encSyntax = func(n ast.Expr) error {
+			b, err := jsonenc.Encode(n)
+			if err == nil {
+				b, err = jc.Format(b)
+			}
+			if err != nil {
+				return err
 			}
+			_, err = w.Write(b)
 			return err
 		}
 
 	case build.YAML:
 		e.concrete = true
+		yc, err := yamlConfig(f)
+		if err != nil {
+			return nil, err
+		}
 		streamed := false
 		e.encValue = func(v cue.Value) error {
 			if streamed {
@@ -182,11 +251,27 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 			}
 			streamed = true
 
-			str, err := yaml.Marshal(v)
+			if err := v.Validate(cue.Concrete(true)); err != nil {
+				return err
+			}
+			b, err := yc.Encode(v.Syntax(cue.Final(), cue.Concrete(true)))
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(b)
+			return err
+		}
+		e.encSyntax = func(n ast.Expr) error {
+			if streamed {
+				fmt.Fprintln(w, "---")
+			}
+			streamed = true
+
+			b, err := yc.Encode(n)
 			if err != nil {
 				return err
 			}
-			_, err = fmt.Fprint(w, str)
+			_, err = w.Write(b)
 			return err
 		}
 
@@ -204,6 +289,17 @@ func NewEncoder(f *build.File, cfg *Config) (*Encoder, error) {
 			return err
 		}
 
+	case build.Starlark:
+		e.concrete = true
+		e.encValue = func(v cue.Value) error {
+			b, err := starlark.MarshalFile(v)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(b)
+			return err
+		}
+
 	case build.Text:
 		e.concrete = true
 		e.encValue = func(v cue.Value) error {
@@ -260,9 +356,30 @@ func (e *Encoder) Encode(v cue.Value) error {
 		}
 		return e.encodeFile(f, nil)
 	}
+	if e.concrete {
+		var err error
+		if v, err = applyOutAttrs(v, e.encoding); err != nil {
+			return err
+		}
+	}
 	if err := v.Validate(cue.Concrete(e.concrete)); err != nil {
 		return err
 	}
+	if e.concrete && e.cfg.FieldOrder != SourceOrder {
+		var err error
+		if v, err = reorder(v, e.cfg.FieldOrder); err != nil {
+			return err
+		}
+	}
+	if e.concrete && e.encSyntax != nil {
+		n, changed, err := formatNumbers(v, e.cfg.Numbers)
+		if err != nil {
+			return err
+		}
+		if changed {
+			return e.encSyntax(n)
+		}
+	}
 	if e.encValue != nil {
 		return e.encValue(v)
 	}
@@ -307,10 +424,209 @@ func writer(f *build.File, cfg *Config) (_ io.Writer, close func() error, err er
 		}
 	}
 	// Delay opening the file until we can write it to completion. This will
-	// prevent clobbering the file in case of a crash.
+	// prevent clobbering the file in case of a crash. The file is written
+	// only once, even if the encoder is closed multiple times.
 	b := &bytes.Buffer{}
+	written := false
 	fn := func() error {
+		if written {
+			return nil
+		}
+		written = true
 		return ioutil.WriteFile(path, b.Bytes(), 0644)
 	}
 	return b, fn, nil
 }
+
+// A newlineWriter writes its output with each line feed replaced by newline.
+type newlineWriter struct {
+	w       io.Writer
+	newline []byte
+}
+
+func (w *newlineWriter) Write(b []byte) (n int, err error) {
+	for len(b) > 0 {
+		i := bytes.IndexByte(b, '\n')
+		if i < 0 {
+			m, err := w.w.Write(b)
+			return n + m, err
+		}
+		m, err := w.w.Write(b[:i])
+		n += m
+		if err != nil {
+			return n, err
+		}
+		if _, err := w.w.Write(w.newline); err != nil {
+			return n, err
+		}
+		n++
+		b = b[i+1:]
+	}
+	return n, nil
+}
+
+const (
+	jsonSchemaDraft   = "http://json-schema.org/draft-04/schema#"
+	openAPIRefPrefix  = "#/components/schemas/"
+	definitionsPrefix = "#/definitions/"
+)
+
+// fromJSONSchema reports whether v was imported from JSON Schema, as indicated
+// by a @jsonschema declaration attribute.
+func fromJSONSchema(v cue.Value) bool {
+	for _, a := range v.Attributes(cue.DeclAttr) {
+		if a.Name() == "jsonschema" {
+			return true
+		}
+	}
+	return false
+}
+
+// openAPIToJSONSchema converts an OpenAPI document to a JSON Schema holding
+// the document's component schemas as definitions. The Schema Object of
+// OpenAPI 3.0 is an extended subset of JSON Schema draft 4, so, aside from
+// references, the schemas are retained as is.
+func openAPIToJSONSchema(f *ast.File) *ast.File {
+	schemas := &ast.StructLit{}
+	for _, d := range f.Decls {
+		if x, ok := lookupField(d, "components"); ok {
+			if s, ok := x.(*ast.StructLit); ok {
+				for _, d := range s.Elts {
+					if x, ok := lookupField(d, "schemas"); ok {
+						if s, ok := x.(*ast.StructLit); ok {
+							schemas = s
+						}
+					}
+				}
+			}
+		}
+	}
+
+	ast.Walk(schemas, nil, func(n ast.Node) {
+		f, ok := n.(*ast.Field)
+		if !ok {
+			return
+		}
+		if name, _, _ := ast.LabelName(f.Label); name != "$ref" {
+			return
+		}
+		lit, ok := f.Value.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return
+		}
+		ref, err := strconv.Unquote(lit.Value)
+		if err == nil && strings.HasPrefix(ref, openAPIRefPrefix) {
+			ref = definitionsPrefix + strings.TrimPrefix(ref, openAPIRefPrefix)
+			lit.Value = strconv.Quote(ref)
+		}
+	})
+
+	return &ast.File{Decls: []ast.Decl{
+		&ast.Field{
+			Label: ast.NewString("$schema"),
+			Value: ast.NewString(jsonSchemaDraft),
+		},
+		&ast.Field{
+			Label: ast.NewString("definitions"),
+			Value: schemas,
+		},
+	}}
+}
+
+func lookupField(d ast.Decl, name string) (ast.Expr, bool) {
+	f, ok := d.(*ast.Field)
+	if !ok {
+		return nil, false
+	}
+	if s, _, _ := ast.LabelName(f.Label); s != name {
+		return nil, false
+	}
+	return f.Value, true
+}
+
+// jsonConfig returns the JSON encoding options selected by cfg and the tags
+// of f.
+func jsonConfig(f *build.File, cfg *Config) (*json.Config, error) {
+	c := &json.Config{
+		Indent:          "    ",
+		EscapeHTML:      cfg.EscapeHTML,
+		TrailingNewline: true,
+	}
+	// Check the options in a fixed order for reproducible errors.
+	keys := make([]string, 0, len(f.Tags))
+	for key := range f.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := f.Tags[key]
+		var err error
+		switch key {
+		case "indent":
+			var n int
+			n, err = strconv.Atoi(value)
+			if err != nil || n < 0 {
+				err = fmt.Errorf("must be a non-negative number")
+				break
+			}
+			c.Indent = strings.Repeat(" ", n)
+		case "ascii":
+			c.ASCII, err = strconv.ParseBool(value)
+		case "newline":
+			c.TrailingNewline, err = strconv.ParseBool(value)
+		}
+		if _, ok := err.(*strconv.NumError); ok {
+			err = fmt.Errorf("must be true or false")
+		}
+		if err != nil {
+			return nil, errors.Newf(token.NoPos,
+				"invalid value %q for json option %s: %v", value, key, err)
+		}
+	}
+	return c, nil
+}
+
+// yamlConfig returns the YAML encoding options selected by the tags of f.
+func yamlConfig(f *build.File) (*yamlenc.Config, error) {
+	c := &yamlenc.Config{}
+	// Check the options in a fixed order for reproducible errors.
+	keys := make([]string, 0, len(f.Tags))
+	for key := range f.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := f.Tags[key]
+		var err error
+		switch key {
+		case "version":
+			c.Version = value
+			if value != "1.1" && value != "1.2" {
+				err = fmt.Errorf("must be 1.1 or 1.2")
+			}
+		case "quote":
+			c.QuoteStrings = value == "all"
+			if value != "all" && value != "auto" {
+				err = fmt.Errorf("must be all or auto")
+			}
+		case "indent":
+			c.Indent, err = strconv.Atoi(value)
+			if err != nil || c.Indent <= 0 {
+				err = fmt.Errorf("must be a positive number")
+			}
+		case "flow":
+			c.FlowThreshold, err = strconv.Atoi(value)
+			if err != nil || c.FlowThreshold < 0 {
+				err = fmt.Errorf("must be a non-negative number")
+			}
+			if c.FlowThreshold == 0 {
+				c.FlowThreshold = -1 // always use block style
+			}
+		}
+		if err != nil {
+			return nil, errors.Newf(token.NoPos,
+				"invalid value %q for yaml option %s: %v", value, key, err)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,75 @@
+// Copyright 2020 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"bytes"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestNewline(t *testing.T) {
+	v := cuecontext.New().CompileString(`a: 1, b: "x\ny"`)
+
+	testCases := []struct {
+		encoding build.Encoding
+		newline  string
+		out      string
+	}{{
+		encoding: build.YAML,
+		out:      "a: 1\nb: |-\n  x\n  y\n",
+	}, {
+		encoding: build.YAML,
+		newline:  "\r\n",
+		out:      "a: 1\r\nb: |-\r\n  x\r\n  y\r\n",
+	}, {
+		encoding: build.JSON,
+		newline:  "\r\n",
+		out:      "{\r\n    \"a\": 1,\r\n    \"b\": \"x\\ny\"\r\n}\r\n",
+	}, {
+		encoding: build.Text,
+		newline:  "\r\n",
+		out:      "x\r\ny\r\n",
+	}, {
+		encoding: build.Binary,
+		newline:  "\r\n",
+		out:      "x\ny",
+	}}
+	for _, tc := range testCases {
+		t.Run(string(tc.encoding)+tc.newline, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			enc, err := NewEncoder(&build.File{
+				Filename: "-",
+				Encoding: tc.encoding,
+			}, &Config{Out: w, Newline: tc.newline})
+			if err != nil {
+				t.Fatal(err)
+			}
+			x := v
+			if tc.encoding == build.Text || tc.encoding == build.Binary {
+				x = v.LookupPath(cue.ParsePath("b"))
+			}
+			if err := enc.Encode(x); err != nil {
+				t.Fatal(err)
+			}
+			if got := w.String(); got != tc.out {
+				t.Errorf("got %q; want %q", got, tc.out)
+			}
+		})
+	}
+}
@@ -47,6 +47,10 @@ import (
 	"golang.org/x/text/transform"
 )
 
+// A Decoder decodes the values of a file one at a time. For streaming
+// formats, such as YAML streams and JSON Lines, values are read from the
+// input as they are requested, so that large streams can be processed with
+// bounded memory. A Decoder must be closed after use.
 type Decoder struct {
 	cfg            *Config
 	closer         io.Closer
@@ -138,7 +142,9 @@ func (i *Decoder) Err() error {
 }
 
 func (i *Decoder) Close() {
-	i.closer.Close()
+	if i.closer != nil {
+		i.closer.Close()
+	}
 }
 
 type Config struct {
@@ -153,15 +159,33 @@ type Config struct {
 
 	Force     bool // overwrite existing files.
 	Strict    bool
+	Annotate  bool // record JSON Schema details for round trips
 	Stream    bool // will potentially write more than one document per file
 	AllErrors bool
 
 	Schema cue.Value // used for schema-based decoding
 
 	EscapeHTML bool
+	FieldOrder FieldOrder // only applies to concrete output
 	ProtoPath  []string
 	Format     []format.Option
 	ParseFile  func(name string, src interface{}) (*ast.File, error)
+
+	// Numbers defines how numbers are written in JSON and YAML output.
+	Numbers NumberFormat
+
+	// PreserveLiterals writes string and bytes literals in CUE output as
+	// they were written.
+	PreserveLiterals bool
+
+	// ExpandReferences and MaxCycleDepth apply to OpenAPI and JSON Schema
+	// output. See the corresponding options of openapi.Config.
+	ExpandReferences bool
+	MaxCycleDepth    int
+
+	// Newline is the line ending written for text output. It defaults to
+	// "\n". Binary output is written unmodified.
+	Newline string
 }
 
 // NewDecoder returns a stream of non-rooted data expressions. The encoding
@@ -244,9 +268,7 @@ func NewDecoder(f *build.File, cfg *Config) *Decoder {
 		i.next = json.NewDecoder(nil, path, r).Extract
 		i.Next()
 	case build.YAML:
-		d, err := yaml.NewDecoder(path, r)
-		i.err = err
-		i.next = d.Decode
+		i.next = yaml.NewStreamDecoder(path, r).Decode
 		i.Next()
 	case build.Text:
 		b, err := ioutil.ReadAll(r)
@@ -295,7 +317,8 @@ func jsonSchemaFunc(cfg *Config, f *build.File) interpretFunc {
 			ID:      id,
 			PkgName: cfg.PkgName,
 
-			Strict: cfg.Strict,
+			Strict:   cfg.Strict,
+			Annotate: cfg.Annotate,
 		}
 		file, err = jsonschema.Extract(i, cfg)
 		// TODO: simplify currently erases file line info. Reintroduce after fix.
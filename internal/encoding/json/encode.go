@@ -185,7 +185,7 @@ func (e *encoder) encodeScalar(l *ast.BasicLit, allowMinus bool) error {
 		if err != nil {
 			return err
 		}
-		b, err := json.Marshal(str)
+		b, err := marshalString(str)
 		if err != nil {
 			return err
 		}
@@ -275,7 +275,7 @@ func (e *encoder) encodeDecls(decls []ast.Decl, endPos token.Pos) error {
 		if err != nil {
 			return errors.Newf(x.Label.Pos(), "json: only literal labels allowed")
 		}
-		b, err := json.Marshal(name)
+		b, err := marshalString(name)
 		if err != nil {
 			return err
 		}
@@ -304,3 +304,16 @@ func foldNewline(pos token.Pos) token.Pos {
 	}
 	return pos
 }
+
+// marshalString returns the JSON encoding of s. HTML characters are not
+// escaped: escaping, if needed, is left to the encoder of the top-level
+// value.
+func marshalString(s string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
@@ -0,0 +1,229 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/apd/v2"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+)
+
+// NumberFormat defines how floating-point numbers are written in concrete
+// output. The zero value writes numbers as they are represented by CUE.
+// Integer values are never modified.
+type NumberFormat struct {
+	// Integers writes floating-point numbers with an integral value, such
+	// as 2.0 or 1e3, as integers.
+	Integers bool
+
+	// Decimals, if positive, is the number of digits written after the
+	// decimal point. Numbers are rounded or padded with zeros as needed.
+	Decimals int
+
+	// Exponent, if positive, writes numbers in exponent notation if the
+	// exponent of their most significant digit is at least Exponent or at
+	// most -Exponent, and without an exponent otherwise.
+	Exponent int
+
+	// Exact reports an error for numbers that cannot be written with the
+	// given number of decimals without loss of precision.
+	Exact bool
+}
+
+func (f NumberFormat) isZero() bool {
+	return f == NumberFormat{}
+}
+
+// applyNumAttr updates f with the number arguments of an out attribute.
+func (f *NumberFormat) applyNumAttr(a *internal.Attr, pos token.Pos) errors.Error {
+	var errs errors.Error
+	for _, kv := range a.Fields {
+		switch kv.Key() {
+		case "integers":
+			f.Integers = true
+		case "exact":
+			f.Exact = true
+		case "decimals", "exponent":
+			n, err := strconv.Atoi(kv.Value())
+			if err != nil || n < 0 {
+				errs = errors.Append(errs, errors.Newf(pos,
+					"invalid value %q for %s in out attribute", kv.Value(), kv.Key()))
+				continue
+			}
+			if kv.Key() == "decimals" {
+				f.Decimals = n
+			} else {
+				f.Exponent = n
+			}
+		}
+	}
+	return errs
+}
+
+// formatNumbers returns the syntax of v with all floating-point numbers
+// written according to f. The format of a field and all values nested
+// within it may be overridden with an out attribute, for instance
+// @out(decimals=2). It reports false if no number was modified.
+func formatNumbers(v cue.Value, f NumberFormat) (ast.Expr, bool, error) {
+	n := v.Syntax(
+		cue.Final(),
+		cue.Concrete(true),
+		cue.Attributes(true),
+	)
+	p := &numPrinter{}
+	expr, _ := n.(ast.Expr)
+	if file, ok := n.(*ast.File); ok {
+		expr = internal.ToExpr(file)
+	}
+	p.expr(expr, f)
+	if p.errs != nil {
+		return nil, false, p.errs
+	}
+	return expr, p.changed, nil
+}
+
+type numPrinter struct {
+	path    []string
+	changed bool
+	errs    errors.Error
+}
+
+func (p *numPrinter) expr(x ast.Expr, f NumberFormat) {
+	switch x := x.(type) {
+	case *ast.StructLit:
+		for _, d := range x.Elts {
+			p.decl(d, f)
+		}
+
+	case *ast.ListLit:
+		for i, e := range x.Elts {
+			p.path = append(p.path, strconv.Itoa(i))
+			p.expr(e, f)
+			p.path = p.path[:len(p.path)-1]
+		}
+
+	case *ast.UnaryExpr:
+		if x.Op == token.SUB {
+			if lit, ok := x.X.(*ast.BasicLit); ok {
+				p.lit(lit, true, f)
+			}
+		}
+
+	case *ast.BasicLit:
+		p.lit(x, false, f)
+	}
+}
+
+func (p *numPrinter) decl(d ast.Decl, f NumberFormat) {
+	switch x := d.(type) {
+	case *ast.Field:
+		for _, attr := range x.Attrs {
+			key, body := attr.Split()
+			if key != "out" {
+				continue
+			}
+			a := internal.ParseAttrBody(attr.Pos(), body)
+			if a.Err != nil {
+				// Reported by applyOutAttrs.
+				continue
+			}
+			p.errs = errors.Append(p.errs, f.applyNumAttr(&a, attr.Pos()))
+		}
+		x.Attrs = nil
+		name, _, _ := ast.LabelName(x.Label)
+		p.path = append(p.path, name)
+		p.expr(x.Value, f)
+		p.path = p.path[:len(p.path)-1]
+
+	case *ast.EmbedDecl:
+		p.expr(x.Expr, f)
+	}
+}
+
+func (p *numPrinter) lit(x *ast.BasicLit, neg bool, f NumberFormat) {
+	if x.Kind != token.FLOAT || f.isZero() {
+		return
+	}
+	var ni literal.NumInfo
+	var d apd.Decimal
+	if literal.ParseNum(x.Value, &ni) != nil || ni.Decimal(&d) != nil {
+		return
+	}
+	s, ok := f.format(&d)
+	if !ok {
+		v := x.Value
+		if neg {
+			v = "-" + v
+		}
+		p.errs = errors.Append(p.errs, errors.Newf(x.Pos(),
+			"%s: cannot write %s with %d decimals without loss of precision",
+			strings.Join(p.path, "."), v, f.Decimals))
+		return
+	}
+	if s != "" && s != x.Value {
+		x.Value = s
+		p.changed = true
+	}
+}
+
+// format writes d according to f. It returns the empty string if d should
+// be written as is and reports false if d cannot be written exactly while
+// Exact is set.
+func (f NumberFormat) format(d *apd.Decimal) (s string, ok bool) {
+	var r apd.Decimal
+	r.Reduce(d)
+	adj := int(r.Exponent) + int(r.NumDigits()) - 1
+	if f.Exponent > 0 && !r.IsZero() {
+		if adj >= f.Exponent || adj <= -f.Exponent {
+			return r.Text('e'), true
+		}
+	}
+	switch {
+	case f.Integers && r.Exponent >= 0:
+		return r.Text('f'), true
+
+	case f.Decimals > 0:
+		var q apd.Decimal
+		prec := f.Decimals + 1
+		if adj > 0 {
+			prec += adj
+		}
+		ctx := apd.BaseContext.WithPrecision(uint32(prec))
+		ctx.Rounding = apd.RoundHalfUp
+		if _, err := ctx.Quantize(&q, d, -int32(f.Decimals)); err != nil {
+			return "", false
+		}
+		if f.Exact && q.Cmp(d) != 0 {
+			return "", false
+		}
+		return q.Text('f'), true
+
+	case f.Exponent > 0:
+		s := d.Text('f')
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return s, true
+	}
+	return "", true
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"sort"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+)
+
+// FieldOrder defines the order in which fields are written.
+type FieldOrder string
+
+const (
+	// SourceOrder writes fields in the order in which they are declared.
+	SourceOrder FieldOrder = ""
+
+	// LexicalOrder sorts fields by their label.
+	LexicalOrder FieldOrder = "lexical"
+
+	// SchemaOrder sorts fields by the position specified in an order
+	// attribute, for instance @order(1). Fields without such an attribute
+	// are written after the ones that have one, in source order.
+	SchemaOrder FieldOrder = "schema"
+)
+
+// ParseFieldOrder converts a field order name to a FieldOrder. The name
+// "source" is accepted for SourceOrder.
+func ParseFieldOrder(s string) (FieldOrder, error) {
+	switch o := FieldOrder(s); o {
+	case "source":
+		return SourceOrder, nil
+	case SourceOrder, LexicalOrder, SchemaOrder:
+		return o, nil
+	}
+	return "", errors.Newf(token.NoPos,
+		"invalid field order %q: must be source, lexical, or schema", s)
+}
+
+// reorder returns v with all fields ordered according to o.
+func reorder(v cue.Value, o FieldOrder) (cue.Value, error) {
+	if o == SourceOrder {
+		return v, nil
+	}
+	n := v.Syntax(
+		cue.Final(),
+		cue.Concrete(true),
+		cue.Docs(true),
+		cue.Attributes(true),
+	)
+
+	var errs errors.Error
+	ast.Walk(n, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.StructLit:
+			errs = errors.Append(errs, sortDecls(x.Elts, o))
+		case *ast.File:
+			errs = errors.Append(errs, sortDecls(x.Decls, o))
+		}
+		return true
+	}, nil)
+	if errs != nil {
+		return cue.Value{}, errs
+	}
+
+	w := v.Context().BuildFile(internal.ToFile(n))
+	return w, w.Err()
+}
+
+// sortDecls sorts the fields in a in place. Other declarations are moved to
+// the front.
+func sortDecls(a []ast.Decl, o FieldOrder) errors.Error {
+	type key struct {
+		name  string
+		order int
+		has   bool
+	}
+	keys := map[ast.Decl]key{}
+	var errs errors.Error
+	for _, d := range a {
+		f, ok := d.(*ast.Field)
+		if !ok {
+			continue
+		}
+		k := key{}
+		switch o {
+		case LexicalOrder:
+			k.name, _, _ = ast.LabelName(f.Label)
+		case SchemaOrder:
+			for _, attr := range f.Attrs {
+				name, body := attr.Split()
+				if name != "order" {
+					continue
+				}
+				a := internal.ParseAttrBody(attr.Pos(), body)
+				i, err := a.Int(0)
+				if err != nil {
+					errs = errors.Append(errs, errors.Newf(attr.Pos(),
+						"invalid order attribute %s: must be an integer", attr.Text))
+					continue
+				}
+				k.order, k.has = int(i), true
+			}
+		}
+		keys[d] = k
+	}
+
+	sort.SliceStable(a, func(i, j int) bool {
+		ki, iField := keys[a[i]]
+		kj, jField := keys[a[j]]
+		switch {
+		case iField != jField:
+			return !iField
+		case !iField:
+			return false
+		case ki.has != kj.has:
+			return ki.has
+		case ki.order != kj.order:
+			return ki.order < kj.order
+		}
+		return ki.name < kj.name
+	})
+	return errs
+}
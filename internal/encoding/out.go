@@ -0,0 +1,124 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encoding
+
+import (
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal"
+)
+
+// applyOutAttrs interprets the out attributes of the fields of v for the
+// given encoding. The following arguments are recognized:
+//
+//	omit       the field is not written.
+//	validate   the field is only used for validation; it is not written and
+//	           need not be concrete.
+//	name=x     the field is written with label x.
+//	json=x     the field is written with label x in JSON output. Similarly
+//	           for yaml and cue.
+//
+// The number format arguments integers, decimals, exponent, and exact are
+// interpreted by formatNumbers.
+//
+// If v does not contain any out attributes, it is returned unmodified.
+func applyOutAttrs(v cue.Value, enc build.Encoding) (cue.Value, error) {
+	n := v.Syntax(
+		cue.Final(),
+		cue.Docs(true),
+		cue.Attributes(true),
+	)
+
+	found := false
+	var errs errors.Error
+	ast.Walk(n, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.StructLit:
+			x.Elts = applyOut(x.Elts, enc, &found, &errs)
+		case *ast.File:
+			x.Decls = applyOut(x.Decls, enc, &found, &errs)
+		}
+		return true
+	}, nil)
+	if errs != nil {
+		return cue.Value{}, errs
+	}
+	if !found {
+		return v, nil
+	}
+	// Omitted fields must still be valid.
+	if err := v.Validate(); err != nil {
+		return cue.Value{}, err
+	}
+
+	w := v.Context().BuildFile(internal.ToFile(n))
+	return w, w.Err()
+}
+
+func applyOut(a []ast.Decl, enc build.Encoding, found *bool, errs *errors.Error) []ast.Decl {
+	if enc == build.JSONL {
+		enc = build.JSON
+	}
+	k := 0
+outer:
+	for _, d := range a {
+		f, ok := d.(*ast.Field)
+		if !ok {
+			a[k] = d
+			k++
+			continue
+		}
+		for _, attr := range f.Attrs {
+			key, body := attr.Split()
+			if key != "out" {
+				continue
+			}
+			*found = true
+
+			x := internal.ParseAttrBody(attr.Pos(), body)
+			if x.Err != nil {
+				*errs = errors.Append(*errs, errors.Promote(x.Err, ""))
+				continue
+			}
+			for _, kv := range x.Fields {
+				switch kv.Key() {
+				case "omit", "validate", "name", "json", "yaml", "cue",
+					"integers", "decimals", "exponent", "exact":
+				default:
+					*errs = errors.Append(*errs, errors.Newf(attr.Pos(),
+						"unknown argument %q in out attribute", kv.Key()))
+				}
+			}
+			if omit, _ := x.Flag(0, "omit"); omit {
+				continue outer
+			}
+			if validate, _ := x.Flag(0, "validate"); validate {
+				continue outer
+			}
+			name, ok, _ := x.Lookup(0, string(enc))
+			if !ok {
+				name, ok, _ = x.Lookup(0, "name")
+			}
+			if ok {
+				f.Label = ast.NewString(name)
+			}
+		}
+		a[k] = d
+		k++
+	}
+	return a[:k]
+}
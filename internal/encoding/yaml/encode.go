@@ -45,46 +45,88 @@ import (
 //
 //    TODO: support anchors through Ident.
 func Encode(n ast.Node) (b []byte, err error) {
-	y, err := encode(n)
+	return (&Config{}).Encode(n)
+}
+
+// A Config defines options for encoding YAML. The zero value writes YAML
+// that can be read by both YAML 1.1 and YAML 1.2 parsers.
+type Config struct {
+	// Version is the version of YAML for which strings that could be
+	// mistaken for other values are quoted: "1.1", the default, or "1.2".
+	// YAML 1.1 parsers interpret strings such as yes, no, on and off as
+	// booleans and strings such as 1:20 as numbers. Strings that YAML 1.2
+	// parsers interpret as other values are always quoted.
+	Version string
+
+	// QuoteStrings quotes all string values, rather than only those that
+	// could be mistaken for other values. Field names are not affected.
+	QuoteStrings bool
+
+	// Indent is the number of spaces used for indentation. The default is 2.
+	Indent int
+
+	// FlowThreshold determines whether lists and structs are written in
+	// flow or block style. If it is zero, lists and structs written on a
+	// single line in CUE are written in flow style. If it is positive, lists
+	// and structs of at most FlowThreshold scalar values are written in flow
+	// style. If it is negative, block style is always used. The top-level
+	// value is always written in block style if FlowThreshold is non-zero.
+	FlowThreshold int
+}
+
+// Encode converts a CUE AST to YAML using the options of c. See Encode for
+// the restrictions on n.
+func (c *Config) Encode(n ast.Node) (b []byte, err error) {
+	switch c.Version {
+	case "", "1.1", "1.2":
+	default:
+		return nil, errors.Newf(n.Pos(), "yaml: unsupported version %q", c.Version)
+	}
+	y, err := c.encode(n)
 	if err != nil {
 		return nil, err
 	}
+	if c.FlowThreshold != 0 {
+		y.Style &^= yaml.FlowStyle
+	}
 	w := &bytes.Buffer{}
 	enc := yaml.NewEncoder(w)
 	// Use idiomatic indentation.
-	enc.SetIndent(2)
+	indent := 2
+	if c.Indent > 0 {
+		indent = c.Indent
+	}
+	enc.SetIndent(indent)
 	if err = enc.Encode(y); err != nil {
 		return nil, err
 	}
 	return w.Bytes(), nil
 }
 
-func encode(n ast.Node) (y *yaml.Node, err error) {
+func (c *Config) encode(n ast.Node) (y *yaml.Node, err error) {
 	switch x := n.(type) {
 	case *ast.BasicLit:
-		y, err = encodeScalar(x)
+		y, err = c.encodeScalar(x)
 
 	case *ast.ListLit:
-		y, err = encodeExprs(x.Elts)
-		line := x.Lbrack.Line()
-		if err == nil && line > 0 && line == x.Rbrack.Line() {
-			y.Style = yaml.FlowStyle
+		y, err = c.encodeExprs(x.Elts)
+		if err == nil {
+			c.setStyle(y, x.Lbrack, x.Rbrack)
 		}
 
 	case *ast.StructLit:
-		y, err = encodeDecls(x.Elts)
-		line := x.Lbrace.Line()
-		if err == nil && line > 0 && line == x.Rbrace.Line() {
-			y.Style = yaml.FlowStyle
+		y, err = c.encodeDecls(x.Elts)
+		if err == nil {
+			c.setStyle(y, x.Lbrace, x.Rbrace)
 		}
 
 	case *ast.File:
-		y, err = encodeDecls(x.Decls)
+		y, err = c.encodeDecls(x.Decls)
 
 	case *ast.UnaryExpr:
 		b, ok := x.X.(*ast.BasicLit)
 		if ok && x.Op == token.SUB && (b.Kind == token.INT || b.Kind == token.FLOAT) {
-			y, err = encodeScalar(b)
+			y, err = c.encodeScalar(b)
 			if !strings.HasPrefix(y.Value, "-") {
 				y.Value = "-" + y.Value
 				break
@@ -101,7 +143,37 @@ func encode(n ast.Node) (y *yaml.Node, err error) {
 	return y, nil
 }
 
-func encodeScalar(b *ast.BasicLit) (n *yaml.Node, err error) {
+// setStyle sets the style of the list or struct y, which spans from open to
+// close in CUE.
+func (c *Config) setStyle(y *yaml.Node, open, close token.Pos) {
+	if y.Kind != yaml.SequenceNode && y.Kind != yaml.MappingNode {
+		return // embedded scalar
+	}
+	switch {
+	case c.FlowThreshold == 0:
+		line := open.Line()
+		if line > 0 && line == close.Line() {
+			y.Style = yaml.FlowStyle
+		}
+
+	case c.FlowThreshold > 0:
+		n := len(y.Content)
+		if y.Kind == yaml.MappingNode {
+			n /= 2
+		}
+		if n > c.FlowThreshold {
+			return
+		}
+		for _, e := range y.Content {
+			if e.Kind != yaml.ScalarNode {
+				return
+			}
+		}
+		y.Style = yaml.FlowStyle
+	}
+}
+
+func (c *Config) encodeScalar(b *ast.BasicLit) (n *yaml.Node, err error) {
 	n = &yaml.Node{Kind: yaml.ScalarNode}
 
 	// TODO: use cue.Value and support attributes for setting YAML tags.
@@ -143,7 +215,7 @@ func encodeScalar(b *ast.BasicLit) (n *yaml.Node, err error) {
 			n.Style = yaml.LiteralStyle
 
 		default:
-			if shouldQuote(str) {
+			if c.QuoteStrings || c.shouldQuote(str) {
 				n.Style = yaml.DoubleQuotedStyle
 			}
 		}
@@ -156,7 +228,10 @@ func encodeScalar(b *ast.BasicLit) (n *yaml.Node, err error) {
 
 // shouldQuote indicates that a string may be a YAML 1.1. legacy value and that
 // the string should be quoted.
-func shouldQuote(str string) bool {
+func (c *Config) shouldQuote(str string) bool {
+	if c.Version == "1.2" {
+		return false
+	}
 	return legacyStrings[str] || useQuote.MatchString(str)
 }
 
@@ -216,11 +291,11 @@ func setNum(n *yaml.Node, s string, x interface{}) error {
 	return nil
 }
 
-func encodeExprs(exprs []ast.Expr) (n *yaml.Node, err error) {
+func (c *Config) encodeExprs(exprs []ast.Expr) (n *yaml.Node, err error) {
 	n = &yaml.Node{Kind: yaml.SequenceNode}
 
 	for _, elem := range exprs {
-		e, err := encode(elem)
+		e, err := c.encode(elem)
 		if err != nil {
 			return nil, err
 		}
@@ -233,7 +308,7 @@ func encodeExprs(exprs []ast.Expr) (n *yaml.Node, err error) {
 // an embedded value, it will return this expression. This is more relaxed for
 // structs than is currently allowed for CUE, but the expectation is that this
 // will be allowed at some point. The input would still be illegal CUE.
-func encodeDecls(decls []ast.Decl) (n *yaml.Node, err error) {
+func (c *Config) encodeDecls(decls []ast.Decl) (n *yaml.Node, err error) {
 	n = &yaml.Node{Kind: yaml.MappingNode}
 
 	docForNext := strings.Builder{}
@@ -276,11 +351,11 @@ func encodeDecls(decls []ast.Decl) (n *yaml.Node, err error) {
 			label := &yaml.Node{}
 			addDocs(x.Label, label, label)
 			label.SetString(name)
-			if shouldQuote(name) {
+			if c.shouldQuote(name) {
 				label.Style = yaml.DoubleQuotedStyle
 			}
 
-			value, err := encode(x.Value)
+			value, err := c.encode(x.Value)
 			if err != nil {
 				return nil, err
 			}
@@ -295,7 +370,7 @@ func encodeDecls(decls []ast.Decl) (n *yaml.Node, err error) {
 				return nil, errors.Newf(x.Pos(), "yaml: multiple embedded values")
 			}
 			hasEmbed = true
-			e, err := encode(x.Expr)
+			e, err := c.encode(x.Expr)
 			if err != nil {
 				return nil, err
 			}
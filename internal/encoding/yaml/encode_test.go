@@ -262,6 +262,128 @@ route:
 	}
 }
 
+func TestEncodeConfig(t *testing.T) {
+	const in = `
+	country: "no"
+	time:    "1:20"
+	version: "1.10"
+	name:    "foo"
+	list: [1, 2, 3]
+	short: [1, 2]
+	nested: {
+		a: [{b: 1}]
+		c: "d"
+	}
+	`
+	testCases := []struct {
+		name string
+		cfg  Config
+		out  string
+	}{{
+		name: "default",
+		out: `
+country: "no"
+time: "1:20"
+version: "1.10"
+name: foo
+list: [1, 2, 3]
+short: [1, 2]
+nested:
+  a: [{b: 1}]
+  c: d
+`,
+	}, {
+		name: "version 1.2",
+		cfg:  Config{Version: "1.2"},
+		out: `
+country: no
+time: 1:20
+version: "1.10"
+name: foo
+list: [1, 2, 3]
+short: [1, 2]
+nested:
+  a: [{b: 1}]
+  c: d
+`,
+	}, {
+		name: "quote strings",
+		cfg:  Config{QuoteStrings: true, Version: "1.2"},
+		out: `
+country: "no"
+time: "1:20"
+version: "1.10"
+name: "foo"
+list: [1, 2, 3]
+short: [1, 2]
+nested:
+  a: [{b: 1}]
+  c: "d"
+`,
+	}, {
+		name: "indent and block style",
+		cfg:  Config{Indent: 4, FlowThreshold: -1},
+		out: `
+country: "no"
+time: "1:20"
+version: "1.10"
+name: foo
+list:
+    - 1
+    - 2
+    - 3
+short:
+    - 1
+    - 2
+nested:
+    a:
+        - b: 1
+    c: d
+`,
+	}, {
+		name: "flow threshold",
+		cfg:  Config{FlowThreshold: 2},
+		out: `
+country: "no"
+time: "1:20"
+version: "1.10"
+name: foo
+list:
+  - 1
+  - 2
+  - 3
+short: [1, 2]
+nested:
+  a:
+    - {b: 1}
+  c: d
+`,
+	}, {
+		name: "unsupported version",
+		cfg:  Config{Version: "1.3"},
+		out:  `yaml: unsupported version "1.3"`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := parser.ParseFile(tc.name, in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := tc.cfg.Encode(f)
+			var got string
+			if err != nil {
+				got = err.Error()
+			} else {
+				got = strings.TrimSpace(string(b))
+			}
+			want := strings.TrimSpace(tc.out)
+			if got != want {
+				t.Error(cmp.Diff(got, want))
+			}
+		})
+	}
+}
+
 func TestEncodeAST(t *testing.T) {
 	comment := func(s string) *ast.CommentGroup {
 		return &ast.CommentGroup{List: []*ast.Comment{
@@ -306,7 +428,7 @@ true
 	}}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			n, err := encode(tc.in)
+			n, err := (&Config{}).encode(tc.in)
 			if err != nil {
 				t.Fatal(err)
 			}
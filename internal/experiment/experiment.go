@@ -0,0 +1,110 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package experiment defines the experimental language and evaluator
+// features that can be enabled for individual files and packages.
+//
+// Experiments allow a change in semantics to be adopted one package, or even
+// one file, at a time. A file enables experiments with a file-level
+// attribute:
+//
+//	@experiment(structcmp)
+//
+//	package foo
+//
+// All files of a package can be opted in at once with the Experiments field
+// of the cue/load Config.
+package experiment
+
+import (
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
+)
+
+// Flags is a set of experiments.
+type Flags uint32
+
+const (
+	// StructCmp allows structs to be compared with == and !=. Two structs
+	// are equal if they have the same regular fields and the values of these
+	// fields are equal.
+	StructCmp Flags = 1 << iota
+)
+
+var names = map[string]Flags{
+	"structcmp": StructCmp,
+}
+
+// Parse returns the experiments with the given names.
+func Parse(pos token.Pos, list []string) (f Flags, errs errors.Error) {
+	for _, name := range list {
+		x, ok := names[strings.TrimSpace(name)]
+		if !ok {
+			errs = errors.Append(errs, errors.Newf(pos,
+				"unknown experiment %q; known experiments are: %s",
+				strings.TrimSpace(name), strings.Join(Names(), ", ")))
+			continue
+		}
+		f |= x
+	}
+	return f, errs
+}
+
+// File returns the experiments enabled by the @experiment attributes at the
+// top level of f.
+func File(f *ast.File) (flags Flags, errs errors.Error) {
+	for _, d := range f.Decls {
+		a, ok := d.(*ast.Attribute)
+		if !ok {
+			continue
+		}
+		key, body := a.Split()
+		if key != "experiment" {
+			continue
+		}
+		attr := internal.ParseAttrBody(a.Pos(), body)
+		if attr.Err != nil {
+			errs = errors.Append(errs, errors.Promote(attr.Err, "invalid @experiment"))
+			continue
+		}
+		var list []string
+		for _, kv := range attr.Fields {
+			if kv.Text() == "" || strings.ContainsRune(kv.Text(), '=') {
+				errs = errors.Append(errs, errors.Newf(a.Pos(),
+					"invalid @experiment: expected experiment name, found %q", kv.Text()))
+				continue
+			}
+			list = append(list, kv.Text())
+		}
+		x, err := Parse(a.Pos(), list)
+		errs = errors.Append(errs, err)
+		flags |= x
+	}
+	return flags, errs
+}
+
+// Names returns the names of all known experiments in sorted order.
+func Names() []string {
+	a := make([]string, 0, len(names))
+	for name := range names {
+		a = append(a, name)
+	}
+	sort.Strings(a)
+	return a
+}
@@ -17,7 +17,6 @@
 package filetypes
 
 import (
-	"path/filepath"
 	"strings"
 
 	"cuelang.org/go/cue"
@@ -102,7 +101,7 @@ func FromFile(b *build.File, mode Mode) (*FileInfo, error) {
 	v = v.Fill(b)
 
 	if b.Encoding == "" {
-		ext := i.Lookup("extensions", filepath.Ext(b.Filename))
+		ext := i.Lookup("extensions", fileExt(b.Filename))
 		if ext.Exists() {
 			v = v.Unify(ext)
 		}
@@ -260,7 +259,7 @@ func toFile(i, v cue.Value, filename string) (*build.File, error) {
 			if !hasDefault {
 				v = v.Unify(i.LookupDef("Default"))
 			}
-		} else if ext := filepath.Ext(filename); ext != "" {
+		} else if ext := fileExt(filename); ext != "" {
 			if x := i.Lookup("extensions", ext); x.Exists() || !hasDefault {
 				v = v.Unify(x)
 				if err := v.Err(); err != nil {
@@ -152,6 +152,10 @@ extensions: {
 	".proto":     tags.proto
 	".textproto": tags.textproto
 	".textpb":    tags.textproto // perhaps also pbtxt
+	".bin":       tags.binary
+	".tf.json":   tags.terraform
+	".bzl":       tags.starlark
+	".star":      tags.starlark
 
 	// TODO: jsonseq,
 	// ".pb":        tags.binpb // binarypb
@@ -186,6 +190,7 @@ tags: {
 	yaml: encoding:      "yaml"
 	proto: encoding:     "proto"
 	textproto: encoding: "textproto"
+	starlark: encoding:  "starlark"
 	// "binpb":  encodings.binproto
 
 	// pb is used either to indicate binary encoding, or to indicate
@@ -228,6 +233,14 @@ tags: {
 		interpretation: "openapi"
 		encoding:       *"json" | _
 	}
+	helm: {
+		interpretation: "helm"
+		encoding:       *"json" | _
+	}
+	terraform: {
+		interpretation: "terraform"
+		encoding:       *"json" | _
+	}
 }
 
 // forms defines schema for all forms. It does not include the form ID.
@@ -319,6 +332,11 @@ encodings: binary: {
 	stream: false
 }
 
+encodings: starlark: {
+	forms.data
+	stream: false
+}
+
 encodings: toml: {
 	forms.data
 	stream: false
@@ -369,6 +387,17 @@ interpretations: openapi: {
 	encoding: *"json" | _
 }
 
+interpretations: helm: {
+	forms.schema
+	encoding: *"json" | _
+}
+
+interpretations: terraform: {
+	forms.data
+	encoding: *"json" | _
+	stream:   false
+}
+
 interpretations: pb: {
 	forms.data
 	stream: true
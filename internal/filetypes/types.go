@@ -41,5 +41,5 @@ func cuegenMake(name string, x interface{}) cue.Value {
 	return v
 }
 
-// Data size: 1708 bytes.
-var cuegenInstanceData = []byte("\x01\x1f\x8b\b\x00\x00\x00\x00\x00\x00\xff\xc4X\xddo\xe4\xb6\x11\x97|W\xa0R\xd3>\xe6\xad\xc0\x9c\xae\bR\xe3*#\x1f@\x81\x05\x8cC\u047b+\xee\xa5)\x8a\xf4)\b\f\xae4\xdae#\x91*I%6\xe2}h\x9b\xa6\xfd\xab\xb3\u0150\x94(je\xfb\f\xa4\xa8_\xbc;?\xcepf8\x9f\xfb\xf3\xe3\xbf\xcf\u04b3\xe3\u007f\x92\xf4\xf8\x8f$\xf9\xed\xf1\xefO\xd2\xf4=.\xb4a\xa2\xc2W\xcc0\xa2\xa7O\u04a7\u007f\x96\u04a4gI\xfa\xf4O\xcc\xec\xd3\xf7\x92\xf4'ox\x8b:=~\x9f$\xc9/\x8f\xff:K\xd3_|\xf1e5`\xd9\xf0\xd6s~\x9f\xa4\xc7\xef\x92\xe4\xc3\xe3?\x9f\xa4\xe9O\x03\xfd\xbb$=K\x9f\xfe\x91uH\x82\x9eZb\x9e$\xc9\x0f\xef\xff\x8a4I\u04f34\xcd\xccM\x8f\xba\xac\x06L\u007fx\xffg=\xab\xbeb;\x84\xed\xc0\xdb:\xcf/.\xe0w@\xf7C%\x95B\xddKQk0\x12\x18\xfcA\xbaC%\xc1e\xfe\x9c\xfem\xe0\xdb<\xa3\xeb\x05\xebp\x03\xfeO\x1b\xc5\xc5.\xcfPT\xb2\xe6b7\x01\xcf_{J\x9eqaP\xf5\n\r3\\\x8a\x97\x1bx\xfe6\xa2\xe4Y#U\xf7rb%\xee7Ruyf\xd8N\xbf\xb4\x17g_\xb8\x9b\xbe\xdcLW\x1e\xf2\x835\xe2\x156lh\rp\rf\x8f@*\u00a0\xb1\x86F*\u0426\xe6\x02\x98\xa8\xe9\x93\x1cL\t\x9f\xef\x114\x1a\xc3\xc5NC\x8d=\x8a\x9a\xa4H\x11\xb8;Y\x93\xd5^\xf0\x06\xac\xfd\xf0A\xec\x80\xf3\xe27\x05\u070e\xda\x1cf\xfe|+\x1a\t56\\\xa0\x86\xbd\xfc\x06\x98\x13\xcb5X7am\x15\x9a\u0702\xb5w11Zk\xed\xb7<\xab\x99a\xc1+\xe7F\r\b\xb7\u0430Vc\x9e)lP\xa1\xa8PoN\xc1\xea\xa6j\x1d\xb0\xc2iU\xe3\xe4y:\xb1\x95\xb2\xcd3\xd9\xd3w\xd6:\x16G\xab\xa4\xd0F1.L8\xf7\x15b\xef\xfd\xa27\x9e\xc6E%\xbb\xbeEc\xc3\xc2\u04fa^*3j\xe0h\xda(d\u0768\x94\xa3\u0572\xd2\xc1DGc\xc6(\xbe\x1d\x8c3\xc0\u049c{\xe9]4=\x1e=\x9c\xd3\xc1>r\xcd\x1b\xeb\v\x03\xb2G\u015c%\xeet\x99_\\\x10\xeb\xe7{\xd4\b\x06\xbb\xbee\x0650\x85\xf6\x01\x04\xbd\x86\x91\xb0E\x18\x04o8\u04bb\x0036\x18\x94\x94\x06d\x03f\xcf5\t\xa9\xa4h\xf8np7\x94\xb9\xbd\xc0\xbe\x17\x17\xfd`\\\x9c\xb6h\xe0\x1a.\xed\xe7\u023a\xc5#d\x91\x99K\xf0\x90gY\x88?++d\xd8yQ\rH\xb1wE\xf4\xb2,G\x86\x10C\xd7y`\xd0^@5P\xd4R\xaa\xe9RW{\xec\x98\x17A\xbcxmPh\x17\x12\xf6tQ\xfeUKQ\xf8o\x8b\x1c&\x1d\xd8`\xe4\xa4\xc4\xc1\xb1\u0730\xae},\xcb\xe38\x0e\x94\xf7\x19^St\xcd\x1c~\xf5\u045a\u02fdS\xcfW]\xbe\x04\x1fp\xb9\xf5\xc6\xfd>\xbf\xfa\xe8\x01\xafS>\a\x9f\x1f\xf2L\x0e\xbd\x89\x02\xe7\xea\xe3\x1f\u01ce\xb9V\x1f?V+\xfc\x9a\xea@\xd0\xe9\x93\xff\xb5o\x1f\x0e\xe7\xabO\x1e0\xa2\xe1\x94\xf2s+jl\xe6F|\xfa\xff\xcf\u026bO\x1f\x99\x95c\x87{=&'t\xac\u05ee\x99\x84\x84\xa5\xf2\xe5\u02e1\x83zEe\xd0p\xaa~\x8b\xbc.\x8ay\x97\xbd\u02b3\x82\x86\x83\x89H\xfd\x96\byH\xff@'\xc2\b\xb4\x1e\x99\x80\x96\x90\xb6\x0eL1\"\xeeD|\xc9\b\u0488\x90O\x85a\x050\xd7&\x06\f^\x1b\x02v2Xg\x81\x9d$r\xaf\xa4\x91s}-\xc1J\xc2k3\xa2\x93\xa4\x18\xdd\xcet\x0eh\x9eQK\xf9\xec\xd5g\x1b C4\xfe\xed\x85%\x15\xe5\xc801m\xb9\xe8\xb7pq\x01[.\x98\xba\xe9\xb7\u04e80\x0eH\xc0E\xcd+\u05d5\xdc\x03R40c[\x9b\xc2^\xa1FA\xe3\n0z\u069db]\x99O\xe3\xd5\x06\x9e]\x16\x85\x13) \x1e\xac\xa0F\x83\xaa\x9b\xcd!\x15*\u00f8\x18\xe5\x80\xde\u02e1\xad\xa9\xfbE\xd3\xc8\xc5\x05\xbc\x91\n\xc6\x11\xf6\x05\xd8\x1a\u0471\x9b\xc5I`\u0509u\xa5\xf8\xd6\xe9\xe7\"\xf8\x05|\xb3\xe7\xd5\x1e\xb8\xd1\xd86\xb6s2A\xac\x95\x14_\xa32\xae\xe52\xf8\xfd_^{\x8e2_\u0304\u04d8g'\xc1y\xd0zzcG\xd2hd\x1cG\xaf\u0160V4R\xbahv\x83\xa6\xe3*\xdc\u0145\u007f\x0ez+\x97]\x95\xec:\x1a\xcfZ.\u0411\x8d<\xcd+\x02lF91.\x99\x9d\xf4I2\xa5\xf0N\xb1~\x1f\xa1\x96\xe2\xc0\x9a\xed\"\xa8f\xbb\x110l\x81\x18/\xd0\u058bo\xf3y\xed\xb1\xa5\u01c2d\xe5\t\xeaM\xf7p\xbb\x8a\xb7\xee\x00\xa5\xd8\tn3\xd4\xc26\xf8Op\x97A\xf6\xc0\x94!'\x87B\xaa\xd1A\x9b,\xfd\x96&b;\xa9#7{T\xe4\xe81\x17|\xba\xc0(\xe2\x05\xc8\b\u03f3~\xbb\x81\xf3\xf8\x16\xf7W\x8c\x99V\xe4\xa7#EA\xf7\xc3-\xac1>\xbb\xbc\x9f\u0552\xbd\x95\xab\x06\x16\u04c3Y=\u00a39\xb1'<\x8e|'\xd7\xee\u010d\xde@\xda!\xee2.\x9b\"3\xcbZf\xaf\u0651\xd3}W$\xd6\x1fE\uae05y\xb94\xa89\xfc\x84\xdd\xcep+\x17F3\x95\x8f\xcey6\x9d\b\n\a\xdeE\x9c\xecQ\xb0\x9e\xdf!\u02e3\xef \xc8\xd5\a\u06e0\xa7\xa5\xce7j*\u042cm\x1dX\xc2[\x03\xb5D\rB\x1a\xe0\xa2j\x87\x1a\xddN)U\ao_\x95\xb9=g\x15\xb2\x1b-\xed\xee\x97\xd3Z;\xd5/\xab=5\uaaf5\xea\x02\x93\x96\xde\x15p\v\x85\x9d~\uc9f1\xba,\x96\xad\xe5@\x16\xafl\xcbI'^\x10\x97h\xbc*~\x18\xc1\xbf\x86\x0f\x96\x94<[,\x92Ky\xf1J\xb9D\xe3Er\x81\x1e\xa8\u038bqZ\x9d\x0fQ'\xfe\xf2>:\xb9o\u076a \xff\xa4\x80\x87\ap\xbe&\xafS\xe1v\xffm\xee.\x16w\xd2\xf9\xc4\xe7\ubfbeW\x9b\x85\x1f\xd7\xfd\xb7\xee\xb7`O\xd4stim\x98\xd9\xf6\xec2\x84\xd0\xf8#\u009cy\u0797hu\xd8-\xfd\xf2\xec\u04b7\xb1X\xdbQ\xad\xe8W\x8b\u026e\xf9\xaf\x15\xab\x06\xac\xfae\xd2\xeb\x90\xc7S\xf5\xd4#\xc7$\b\x16\x84\x0e\x19\x96\x9fE\xb6\xb8$\x81\xdb\xf1\xdd\xe6\v\u00e8\xc7|O\b\xc2C\xfb\x8c\x9d\x1b\xa9Ai\xe8$\xc7\x1dyU\x9f\xe9`\xe89\xab\xe7\x82\x0e\xf3V\xf3\xc0Q#\xbb\xfb\xee\x0e\ag-}\x91c\xef4\x06D\xd2\xef\x98\tf/\xb0\xb4\x85\x1a\xfd}b\xe6={MJhy\v\xe5O\f=\xe4q\x9fxD\xad\xb6\u02d4k\x82\xf1-\u02eev\xa7\x03\xef\xed_\xef\u0335\xea\xace4\x1d\xf2$\xf9o\x00\x00\x00\xff\xff\x85i\xbeS\xb4\x16\x00\x00")
+// Data size: 1812 bytes.
+var cuegenInstanceData = []byte("\x01\x1f\x8b\b\x00\x00\x00\x00\x00\x00\xff\xc4X\u074f\u0736\x11_\x9d]\xa0\x12\u04be\xe6\xa9\xc0D\x06\x82t\xe1\xea\x90\x0f\xf4a\x01\xc3(j\xbb\xf0KS\x14\xe9\x93\x11,(i\xb4\xcbZ\"U\x92J\xee\x1a\x1f\u06a6i\xff\xce\xfe\x1b}\xc9\x15\xc3\x0fI\xd4\uaf80\x14\xb1\x1fnw~\x9c\x1fg\x863\x9c\xe1\xfe\xec\xfa_g\xc9\xd9\xf5\xbf7\xc9\xf5\xdf7\x9b_\xff\xedQ\x92\xbc\u01c56LT\xf8\x82\x19F\xe2\xe4Q\xf2\xf8\x8fR\x9a\xe4l\x93<\xfe\x033\xc7\xe4\xbdM\xf2\x93W\xbcE\x9d\\\x7f\xb7\xd9l~q\xfd\u03f3$\xf9\xf9\x9b/\xab\x01\x8b\x86\xb7^\xf3\xbbMr\xfd\xedf\xf3\xd1\xf5?\x1e%\xc9O'\xf9\xb7\x9b\xe4,y\xfc{\xd6!\x11=\xb6\xc2l\xb3\xd9|\xff\xfe\x7f\u0250$9K\x92\xd4\\\xf6\xa8\x8bj\xc0\xe4\xfb\xf7\xff\u04f3\xea-; \x94\x03o\xeb,;?\x87\xdf\x00\xed\x0f\x95T\nu/E\xad\xc1H`\xf0;\xe9\x16\x15\x04\x17\xd9\x13\xfa\xb3\x83o\xb2\x94\xb6\x17\xac\xc3\x1d\xf8\x7f\xda(.\x0eY\x8a\xa2\x925\x17\x87\x11x\xf2\xd2K\xb2\x94\v\x83\xaaWh\x98\xe1R<\xdf\xc1\x93\u05d1$K\x1b\xa9\xba\xe7\xa3*i\xbf\x92\xaa\xcbR\xc3\x0e\xfa\xb9\xdd8}\xe3v\xfar7ny\x95]Y'^`\u00c6\xd6\x00\xd7`\x8e\bd\"\f\x1akh\xa4\x02mj.\x80\x89\x9a>\xc9\xc1\x14\xf0\xc5\x11A\xa31\\\x1c4\xd4\u0623\xa8\x89E\x8aI\xbb\x935\x16\xd9\x13O\xbc\x03\xeb?|\x18\a`\x9b\xff*\x87w\xc1\x9a\xabY<_\x8bFB\x8d\r\x17\xa8\xe1(\xbf\x06\xe6h\xb9\x06\x1b&\xac\xadAcX\xb0\xf6!&E\xeb\xad\xfd\x96\xa553l\x8a\xca\u05a8\x01\xe1\x1d4\xac\u0558\xa5\n\x1bT(*\u053bS\xb0\xba\xacZ\a\xachZ\xd38\x9d\x05\xad(\xa5l\xb3T\xf6\xf4\x9d\xb5N\xc5\xc9*)\xb4Q\x8c\v3\xad{\x8b\xd8\xfb\xb8\u8757qQ\u026eo\xd1\u0634\U00032b97\xca\x04\v\x9cL\x1b\x85\xac\vF9Y-\xab\xd1\xcc c\xc6(^\x0e\xc69`e.\xbct.\x9a\x0e\x8f\x0e\xce\xd9`\x0f\xb9\u634d\x85\x01\u0663\xb29\xc5Z\xb7\xba\xc8\xce\xcfI\xf5\x8b#j\x04\x83]\xdf2\x83\x1a\x98B{\x00\xa2\u019ar\xbeD\x18\x04o8\xd6@\xf9bl2()\r\xc8\x06\u0311k\"\xa9\xa4h\xf8ap;\x14\x99\xdd\xc0\x9e\u05de\x92\xbc\x1f\x8c\xfd\x92\xb6h\xe0\x02\x9e\xd9\u03d1\x83\x8bsH#O\x97\xe0U\x96\xa6S\nZ\xae\xa9\u0236y5 \xa5\u07de\xe4EQ\x04\x85)\x8d.\xb2IA{\x82j\xc0\x1dl\xa9\xdat\xa1\xab#v\xccS\xd0fxaPh\x97\x15vu^\xfcYK\x91\xfbo\x8b2&\x1b\xd8`\xe4h\x04Q\xa4yq\u027a\xf6\xa1*\x0f\u04f8\xa2\xd2O\xf1\x82\x12l\x16\xf0\xfd\xc7k!\xf7A\u076e\x86|\t\xde\x11r\x1b\x8d\xdbc\xbe\xff\xf8\x8e\xa8SI{\n\xe7\x87\x1cz\x13%\xce\xfe\x93\x1f\u018f\xb9U\x9f<\xd4*\xfc\x8a\xb5s\x9b>\xfd\x7f\xc7\xf6\xeet\xde\x7fz\x87\x13\r\x17\xac\x8d\xbc\xa8\xb1\x99;\xf1\u064f_\x93\xfb\xcf\x1eX\x95\xa1\u027d\f\xc5\t\x1d\xeb\xb5\xeb'S\xc1\xd2\r\xe6oD\a\xf5\x8anB\xc3Q\x17\u0662\xae\xf3<\xb8N\xff\xf7Y\x9a\xd3|0\n\xa9\xe5\x92 \x9b\xca\x7f\x92\x93 \x00m\xbe\x8b\x81\x96\x90\xb6\x9e\x94bD\u0708\xf8+cb#A6^\f+\x80\xb901`\xf0\u0090\xc6A\x8er\a\x1c$\x89{%M@\xac\xd8\n\b!\u0140\x8eL1Z\xcel\x8e\u0412{w\x02Zr\xc1\u0525\xa5m\xa6\xe0yE\xa5\x18]\xbc\x84\x96\x7f]\xf8\xa5\rS-So\t\xa4\xcf\xf9n\r\xccRjd\x9f\xbf\xf8|\aD\xae\xf1/O\xad(/\x82\x8d\xa3N\xc9E_\xc2\xf998\x93\xfar\x1cP\xc2X\x06\\\u053cr\xbd\xd0\xe5\fY\u01ccm\xa8\n{\x85\x1a\x05\rI\xc0\xa0W\xf2\xa0XWd\xe3P\xb7\x83\x0f\x9e\u5e63\x14\x10\x8fsP\xa3A\xd5\u0366\x9f\n\x95a\\\x04\x1e\xd0G9\xb45\x94\x18\xcf@\xe7\xe7\xf0J*\b\x83\xf3S\xb0\x97e\xc7.\x17+\x81Q\xff\u05d5\u2973\u03f5\xb2\xa7\xf0\xf5\x91WG\xe0Fc\u06d0i\x15\x13\xa4ZI\xf1\x15*R\xb4\xc3\xedo\xff\xf4\xd2k\x14\xd9b\x12\x1d\x87K;\x7f\x8e!\x9d\xe6\\\n\xd4\\\fcq/\xc7\u00fc\x91\xd2\xe5\x80\x1bo\x9dV\xee6\xce\xfdq\xd0Y\xb9\x82\xaed\xd7\xd1P\xd8r\x816\x99\xa8\xa4OJ\x99\x00[\u010e\xc6~\xf4\xec#3]R\a\xc5\xfac\x84ZI\xeenEv\x88\xa0\x9a\x1d\x02`bJ\x128\xc8\x0e\x0e\xdf\xcc\xee\xae\x1d\xd8\t\u0102\xe4\xe5\t\xea]\xf7p\xbb\x8a\xb7n\xc1%\xebNq\x12:\xd8\xd6\xdb\tn\xa5n\xc1X\x94'\x8bF\xc4-\f\x95t\xb2.\x00v\x99\xad\xa9\xbe\xa4\xf7\x84}F 7GTt\x1e\xa1d|UA`x\n2\u00b3\xb4/w\xb0\x8d7\xa1\xe3\a\xc8CA\xe6\xd9\u9c13\xd3\xfe\xf0na\x1d\xa9\x01\xd5\u06ed\xaa}9\x05c5\x0e\xf9x\xaeD7;[G{\xa2\xe3\xc47j\x1d\xe4\x0eV\x1d\xa4\a\xceM\u03a5c\x02\xa7i\xcbH)?\xc8|\xec\u05e4\xfa\x83\xb0\xfaj\r\xbc4\xa7:\xfcD\x9d\xa0|e\xc3h\xda\xf3I</\xba\x13\xa2i\xc1}\xe8d\x8f\x82\xf5\xfc\x06.\x8f\u0787\xe8\x88mw\x03\vA\xf7\xa1\x18[\xd3\r<#~\x0f2w\xadQ\xc2\xe8\xf1\x05\xecG\x1a\xea+\xacm\xa9\xbft\xba\x80\xd7\x06j\x89\x1a\x844\xc0E\xd5\x0e5\xda7\x17\xc1\xf0\xfaE\x91\xd1\a\x97+d\xd5\x1b\xfa\xa1\xe3\xd9\xf8\x1b\xc0x\xed\xda\\\xa4\x91f\xbfv)\x86\x7f\xdbp;\xc2;\xc8\xed\x9cH\x16\x8f\x97\xe2\xe2e\xba\x1c]\xe3\xf7\xedr&\x8c_\xd3K4~W\x7f\x14\xc1\xbf\x84\x0f\x97\x92,]\xbc\xba#8K\x17\xef\xef%\x1a\xbf\xba\x17\xe8\x15\xb5'\x11\xe6\xfa\xf9\xb8y\x12/\x1f\xa3\x93\xfd\u05bd\x9a\xf8O\xfaN \xdc\xfaXS\u0529\u07f8\xbf\xf6\x06Z\xfc\xcaA6\x9f\xc4|=\u05b7Z\xb3\x88\xe3z\xfc\xd6\xe3\xe6\xa5\xcbV\xa9\v\xeb\xc3\u0337\x0f\x9eM)\x14~q\x99+\xcf\u06e9.jv\x98\xe9\x86K\x9d\xa2\xb1\xb4\xd6s\xc4?\xf1\x04a\xd8(r6r`5.^H\xef\x89P\u00ee\xba\xc6\xd6\x1e\x8a`\\9k\xec\xd33qQ-[\xbb\x1a\u0785s\x9b?\xad<Q\xf4\xa2\x9a\u0227\xae\x1f\a72\x83\xca\xd01{s\xda[\xec\x19\x17N=pu\xddd\u00fc\xf5\u07714\x9e\x1d\xeeXld\xd7\xdek\xe1llY\x14\xe4t\xd1\xde2\xeaD\xec7\xcc=\xe3\xaep\xe2x_\xdeN3\x1f8\xd6X\xa6~\xbd0>,\x1e\x97^eq[y\xc0\xc5n\u07e8\u0526w\x10\xef\xb2l\xc9\v\x1b&?\x96\r/j\xbe\xf7\u059a:\xed\xbdU\x16\x9d\xf5\x860GZ\xabq^=\xa7e\xd6_e\x9b\xcd\xff\x06\x00\xf9\xb2\xdb\xe2\x88\x18\x00\x00")
@@ -21,6 +21,16 @@ import (
 	"cuelang.org/go/cue/ast"
 )
 
+// fileExt returns the extension of filename as used for looking up its
+// default file type. It is the same as filepath.Ext, except for compound
+// extensions such as .tf.json.
+func fileExt(filename string) string {
+	if strings.HasSuffix(filename, ".tf.json") {
+		return ".tf.json"
+	}
+	return filepath.Ext(filename)
+}
+
 // IsPackage reports whether a command-line argument is a package based on its
 // lexical representation alone.
 func IsPackage(s string) bool {
@@ -43,6 +43,21 @@ type Decimal = apd.Decimal
 // incomplete.
 var ErrIncomplete = errors.New("incomplete value")
 
+// A ValidationError may be returned, along with false, by a builtin that
+// reports whether a value is valid, to explain why it is not. The explanation
+// is reported if the builtin is used as a validator, as in net.IPv4 & "::1".
+// If the builtin is called as a function, it returns false.
+type ValidationError struct {
+	Msg string
+}
+
+func (e *ValidationError) Error() string { return e.Msg }
+
+// Invalidf returns a ValidationError with the given message.
+func Invalidf(format string, args ...interface{}) error {
+	return &ValidationError{Msg: fmt.Sprintf(format, args...)}
+}
+
 // MakeInstance makes a new instance from a value.
 var MakeInstance func(value interface{}) (instance interface{})
 
@@ -380,14 +395,14 @@ func IsEllipsis(x ast.Decl) bool {
 
 // GenPath reports the directory in which to store generated files.
 func GenPath(root string) string {
-	info, err := os.Stat(filepath.Join(root, "cue.mod"))
+	info, err := os.Stat(LongPath(filepath.Join(root, "cue.mod")))
 	if os.IsNotExist(err) || !info.IsDir() {
 		// Try legacy pkgDir mode
 		pkgDir := filepath.Join(root, "pkg")
 		if err == nil && !info.IsDir() {
 			return pkgDir
 		}
-		if info, err := os.Stat(pkgDir); err == nil && info.IsDir() {
+		if info, err := os.Stat(LongPath(pkgDir)); err == nil && info.IsDir() {
 			return pkgDir
 		}
 	}
@@ -0,0 +1,86 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"runtime"
+	"strings"
+)
+
+// LongPath returns path in a form that is not subject to the maximum path
+// length of the Windows API. On Windows, an absolute path that exceeds this
+// limit, including a path on a UNC share, is converted to an extended-length
+// path, as in \\?\C:\dir or \\?\UNC\server\share\dir. Other paths, and all
+// paths on other systems, are returned unchanged.
+//
+// The result should only be passed to the operating system; it should not be
+// shown to users or used to compute other paths.
+func LongPath(path string) string {
+	if runtime.GOOS != "windows" {
+		return path
+	}
+	return winLongPath(path)
+}
+
+// maxShortPath is the maximum length of a path that the Windows API accepts
+// for all operations. The limit for directories is MAX_PATH (260) minus the
+// room for an 8.3 file name.
+const maxShortPath = 248
+
+func winLongPath(path string) string {
+	if len(path) < maxShortPath ||
+		strings.HasPrefix(path, `\\?\`) || strings.HasPrefix(path, `\\.\`) {
+		return path
+	}
+
+	var prefix string
+	minElems := 0
+	switch {
+	case len(path) >= 3 && isLetter(path[0]) && path[1] == ':' && isSlash(path[2]):
+		prefix, path = `\\?\`+path[:2], path[2:]
+
+	case len(path) >= 3 && isSlash(path[0]) && isSlash(path[1]) && !isSlash(path[2]):
+		// Do not allow .. to remove the server or share name.
+		prefix, path, minElems = `\\?\UNC`, path[2:], 2
+
+	default:
+		return path // relative or otherwise not supported
+	}
+
+	// Windows does not normalize extended-length paths, so clean the path
+	// here. This includes converting slashes to backslashes.
+	var elems []string
+	for _, e := range strings.FieldsFunc(path, func(r rune) bool {
+		return r < 0x80 && isSlash(byte(r))
+	}) {
+		switch {
+		case e == ".":
+		case e == ".." && len(elems) > minElems:
+			elems = elems[:len(elems)-1]
+		case e == "..":
+		default:
+			elems = append(elems, e)
+		}
+	}
+	return prefix + `\` + strings.Join(elems, `\`)
+}
+
+func isSlash(c byte) bool {
+	return c == '\\' || c == '/'
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,67 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWinLongPath(t *testing.T) {
+	long := strings.Repeat("d", 250)
+
+	testCases := []struct {
+		in  string
+		out string
+	}{{
+		in:  `C:\short\path`,
+		out: `C:\short\path`,
+	}, {
+		in:  `C:\` + long + `\file.cue`,
+		out: `\\?\C:\` + long + `\file.cue`,
+	}, {
+		in:  `c:/` + long + `/./a/../file.cue`,
+		out: `\\?\c:\` + long + `\file.cue`,
+	}, {
+		in:  `C:\` + long + `\\a\`,
+		out: `\\?\C:\` + long + `\a`,
+	}, {
+		in:  `C:\..\` + long,
+		out: `\\?\C:\` + long,
+	}, {
+		in:  `\\server\share\` + long + `\file.cue`,
+		out: `\\?\UNC\server\share\` + long + `\file.cue`,
+	}, {
+		in:  `//server/share/../../` + long,
+		out: `\\?\UNC\server\share\` + long,
+	}, {
+		in:  `\\?\C:\` + long,
+		out: `\\?\C:\` + long,
+	}, {
+		in:  `\\.\pipe\` + long,
+		out: `\\.\pipe\` + long,
+	}, {
+		in:  long + `\relative`,
+		out: long + `\relative`,
+	}, {
+		in:  `\` + long + `\rooted`,
+		out: `\` + long + `\rooted`,
+	}}
+	for _, tc := range testCases {
+		if got := winLongPath(tc.in); got != tc.out {
+			t.Errorf("winLongPath(%q):\ngot  %q\nwant %q", tc.in, got, tc.out)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package modsign signs modules and verifies their signatures.
+//
+// A module is signed by signing its path and the digest of its files with
+// an Ed25519 key. The signature is stored in cue.mod/module.sig. Public
+// keys and signatures use the format of minisign, so that a signature can
+// also be checked with minisign for the signed message returned by
+// Message. Secret keys are stored unencrypted, in a format specific to CUE.
+package modsign
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	cueerrors "cuelang.org/go/cue/errors"
+)
+
+// SigFile is the name of the file in the cue.mod directory of a module that
+// holds the signature of the module.
+const SigFile = "module.sig"
+
+// algorithm identifies signatures of the full message with Ed25519, which
+// minisign calls legacy signatures.
+const algorithm = "Ed"
+
+// A PublicKey verifies the signatures of modules.
+type PublicKey struct {
+	id  [8]byte
+	key ed25519.PublicKey
+}
+
+// ParsePublicKey parses a public key in minisign format: the base64 encoding
+// of the algorithm, key ID, and key. The untrusted comment line of a minisign
+// public key file is allowed to precede the key.
+func ParsePublicKey(s string) (*PublicKey, error) {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		s = strings.TrimSpace(s[i+1:])
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(b) != 2+8+ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid public key %q", s)
+	}
+	if string(b[:2]) != algorithm {
+		return nil, fmt.Errorf("unsupported signature algorithm %q", b[:2])
+	}
+	k := &PublicKey{key: ed25519.PublicKey(b[10:])}
+	copy(k.id[:], b[2:10])
+	return k, nil
+}
+
+// ID returns the key ID in the hexadecimal form used by minisign.
+func (k *PublicKey) ID() string {
+	return keyID(k.id)
+}
+
+func (k *PublicKey) String() string {
+	return base64.StdEncoding.EncodeToString(
+		append(append([]byte(algorithm), k.id[:]...), k.key...))
+}
+
+// Marshal returns the contents of a minisign public key file for k.
+func (k *PublicKey) Marshal() []byte {
+	return []byte(fmt.Sprintf("untrusted comment: minisign public key %s\n%s\n", k.ID(), k))
+}
+
+// A SecretKey signs modules.
+type SecretKey struct {
+	id  [8]byte
+	key ed25519.PrivateKey
+}
+
+// GenerateKey generates a key pair using entropy from rand.
+func GenerateKey(rand io.Reader) (*PublicKey, *SecretKey, error) {
+	pub, priv, err := ed25519.GenerateKey(rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	var id [8]byte
+	if _, err := io.ReadFull(rand, id[:]); err != nil {
+		return nil, nil, err
+	}
+	return &PublicKey{id, pub}, &SecretKey{id, priv}, nil
+}
+
+// ParseSecretKey parses the contents of a file written by Marshal.
+func ParseSecretKey(data []byte) (*SecretKey, error) {
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[len(lines)-1]))
+	if err != nil || len(b) != 2+8+ed25519.PrivateKeySize || string(b[:2]) != algorithm {
+		return nil, errors.New("invalid secret key")
+	}
+	k := &SecretKey{key: ed25519.PrivateKey(b[10:])}
+	copy(k.id[:], b[2:10])
+	return k, nil
+}
+
+// Marshal returns the contents of a secret key file for k. The key is not
+// encrypted.
+func (k *SecretKey) Marshal() []byte {
+	b := append(append([]byte(algorithm), k.id[:]...), k.key...)
+	return []byte(fmt.Sprintf("untrusted comment: cue secret key %s\n%s\n",
+		keyID(k.id), base64.StdEncoding.EncodeToString(b)))
+}
+
+// Public returns the public key of k.
+func (k *SecretKey) Public() *PublicKey {
+	return &PublicKey{k.id, k.key.Public().(ed25519.PublicKey)}
+}
+
+// Message returns the message that is signed for a module with the given
+// path and digest.
+func Message(module, digest string) []byte {
+	return []byte(fmt.Sprintf("%s %s\n", module, digest))
+}
+
+// Sign returns the contents of a signature file for the module with the
+// given path and digest.
+func Sign(k *SecretKey, module, digest string) []byte {
+	sig := ed25519.Sign(k.key, Message(module, digest))
+	comment := fmt.Sprintf("module: %s digest: %s", module, digest)
+	global := ed25519.Sign(k.key, append(append([]byte{}, sig...), comment...))
+
+	b := &bytes.Buffer{}
+	fmt.Fprintf(b, "untrusted comment: signature from cue secret key %s\n", keyID(k.id))
+	fmt.Fprintln(b, base64.StdEncoding.EncodeToString(
+		append(append([]byte(algorithm), k.id[:]...), sig...)))
+	fmt.Fprintf(b, "trusted comment: %s\n", comment)
+	fmt.Fprintln(b, base64.StdEncoding.EncodeToString(global))
+	return b.Bytes()
+}
+
+// Verify checks that data is a signature file for the module with the given
+// path and digest that was created with one of the given keys.
+func Verify(data []byte, module, digest string, keys []*PublicKey) error {
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) != 4 ||
+		!strings.HasPrefix(lines[0], "untrusted comment: ") ||
+		!strings.HasPrefix(lines[2], "trusted comment: ") {
+		return errors.New("malformed signature file")
+	}
+	b, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(b) != 2+8+ed25519.SignatureSize {
+		return errors.New("malformed signature")
+	}
+	if string(b[:2]) != algorithm {
+		return fmt.Errorf("unsupported signature algorithm %q", b[:2])
+	}
+	var id [8]byte
+	copy(id[:], b[2:10])
+	sig := b[10:]
+
+	var key *PublicKey
+	for _, k := range keys {
+		if k.id == id {
+			key = k
+			break
+		}
+	}
+	if key == nil {
+		return fmt.Errorf("signed with untrusted key %s", keyID(id))
+	}
+	if !ed25519.Verify(key.key, Message(module, digest), sig) {
+		return errors.New("signature does not match module contents")
+	}
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil {
+		return errors.New("malformed signature")
+	}
+	comment := strings.TrimPrefix(lines[2], "trusted comment: ")
+	if !ed25519.Verify(key.key, append(append([]byte{}, sig...), comment...), global) {
+		return errors.New("invalid signature of trusted comment")
+	}
+	return nil
+}
+
+// VerifyDir checks the signature of the module with the given path that is
+// rooted at dir.
+func VerifyDir(dir, module string, keys []*PublicKey) error {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "cue.mod", SigFile))
+	if os.IsNotExist(err) {
+		return errors.New("module is not signed")
+	}
+	if err != nil {
+		return err
+	}
+	digest, err := Digest(dir)
+	if err != nil {
+		return err
+	}
+	return Verify(data, module, digest, keys)
+}
+
+// Digest computes the digest of the files of the module rooted at dir. The
+// dependencies in cue.mod/pkg, the signature file, version control
+// directories, and files starting with a dot, which are never loaded, are
+// excluded. It is an error for the module to contain symbolic links or
+// other files that are not regular files.
+//
+// The digest has the same form as the h1 hashes of Go modules: the base64
+// encoding of the SHA-256 hash of a summary that lists the SHA-256 hash and
+// slash-separated relative path of each file, ordered by path.
+func Digest(dir string) (string, error) {
+	skip := map[string]bool{
+		filepath.Join(dir, "cue.mod", "pkg"):   true,
+		filepath.Join(dir, "cue.mod", SigFile): true,
+	}
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		switch {
+		case path == dir:
+			return nil
+		case skip[path], info.IsDir() && vcsDirs[info.Name()]:
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		case info.IsDir(), strings.HasPrefix(info.Name(), "."):
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			// The loader follows symbolic links, so the contents of a
+			// module may otherwise change without invalidating its
+			// signature.
+			return fmt.Errorf("%s: signed modules may only contain regular files", filepath.ToSlash(rel))
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	summary := sha256.New()
+	for _, f := range files {
+		data, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(f)))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(summary, "%x  %s\n", sha256.Sum256(data), f)
+	}
+	return "h1:" + base64.StdEncoding.EncodeToString(summary.Sum(nil)), nil
+}
+
+// vcsDirs holds the names of the directories of version control systems.
+var vcsDirs = map[string]bool{
+	".bzr": true,
+	".git": true,
+	".hg":  true,
+	".svn": true,
+}
+
+// A Rule lists the keys that are trusted to sign the modules that match a
+// pattern.
+type Rule struct {
+	// Pattern is a module path, which may end in "/..." to also match all
+	// modules below it. The pattern "..." matches all modules.
+	Pattern string
+
+	Keys []*PublicKey
+}
+
+// A Policy lists the keys that are trusted to sign modules.
+type Policy []Rule
+
+// Keys reports the keys of all rules that match module. It reports false if
+// no rule matches, in which case the module need not be signed.
+func (p Policy) Keys(module string) (keys []*PublicKey, ok bool) {
+	for _, r := range p {
+		if match(r.Pattern, module) {
+			keys = append(keys, r.Keys...)
+			ok = true
+		}
+	}
+	return keys, ok
+}
+
+// ParsePolicy parses the trust field of a cue.mod/module.cue file, which
+// is a list of rules of the form
+//
+//	{module: "example.com/...", keys: ["RWQ..."]}
+func ParsePolicy(v cue.Value) (Policy, error) {
+	var rules []struct {
+		Module string   `json:"module"`
+		Keys   []string `json:"keys"`
+	}
+	if err := v.Decode(&rules); err != nil {
+		return nil, err
+	}
+	var p Policy
+	for _, r := range rules {
+		rule := Rule{Pattern: r.Module}
+		if rule.Pattern == "" {
+			return nil, cueerrors.Newf(v.Pos(), "trust rule without module")
+		}
+		for _, s := range r.Keys {
+			k, err := ParsePublicKey(s)
+			if err != nil {
+				return nil, cueerrors.Wrapf(err, v.Pos(), "trust rule for %s", r.Module)
+			}
+			rule.Keys = append(rule.Keys, k)
+		}
+		p = append(p, rule)
+	}
+	return p, nil
+}
+
+func match(pattern, module string) bool {
+	switch {
+	case pattern == "...":
+		return true
+	case strings.HasSuffix(pattern, "/..."):
+		prefix := strings.TrimSuffix(pattern, "/...")
+		return module == prefix || strings.HasPrefix(module, prefix+"/")
+	}
+	return pattern == module
+}
+
+func keyID(id [8]byte) string {
+	return fmt.Sprintf("%016X", binary.LittleEndian.Uint64(id[:]))
+}
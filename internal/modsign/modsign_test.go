@@ -0,0 +1,242 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package modsign
+
+import (
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestSignVerify(t *testing.T) {
+	pub, sec, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const digest = "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+	sig := Sign(sec, "example.com/lib", digest)
+
+	testCases := []struct {
+		name   string
+		sig    []byte
+		module string
+		digest string
+		keys   []*PublicKey
+		err    string
+	}{{
+		name:   "valid",
+		sig:    sig,
+		module: "example.com/lib",
+		digest: digest,
+		keys:   []*PublicKey{other, pub},
+	}, {
+		name:   "other digest",
+		sig:    sig,
+		module: "example.com/lib",
+		digest: "h1:AAAA",
+		keys:   []*PublicKey{pub},
+		err:    "signature does not match module contents",
+	}, {
+		name:   "other module",
+		sig:    sig,
+		module: "example.com/other",
+		digest: digest,
+		keys:   []*PublicKey{pub},
+		err:    "signature does not match module contents",
+	}, {
+		name:   "untrusted key",
+		sig:    sig,
+		module: "example.com/lib",
+		digest: digest,
+		keys:   []*PublicKey{other},
+		err:    "signed with untrusted key " + pub.ID(),
+	}, {
+		name: "trusted comment",
+		sig: []byte(strings.Replace(string(sig),
+			"trusted comment: module:", "trusted comment: module: x", 1)),
+		module: "example.com/lib",
+		digest: digest,
+		keys:   []*PublicKey{pub},
+		err:    "invalid signature of trusted comment",
+	}, {
+		name:   "malformed",
+		sig:    []byte("untrusted comment: x\n"),
+		module: "example.com/lib",
+		digest: digest,
+		keys:   []*PublicKey{pub},
+		err:    "malformed signature file",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Verify(tc.sig, tc.module, tc.digest, tc.keys)
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tc.err {
+				t.Errorf("got error %q; want %q", got, tc.err)
+			}
+		})
+	}
+}
+
+func TestKeys(t *testing.T) {
+	pub, sec, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := ParsePublicKey(string(pub.Marshal()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.String() != pub.String() || p.ID() != pub.ID() {
+		t.Errorf("got public key %s (%s); want %s (%s)", p, p.ID(), pub, pub.ID())
+	}
+
+	s, err := ParseSecretKey(sec.Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Public().String(); got != pub.String() {
+		t.Errorf("got public key %s of secret key; want %s", got, pub)
+	}
+
+	if _, err := ParsePublicKey("RWQ"); err == nil {
+		t.Error("ParsePublicKey succeeded for invalid key")
+	}
+}
+
+func TestVerifyDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "modsign")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, data string) {
+		t.Helper()
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("cue.mod/module.cue", `module: "example.com/lib"`)
+	write("lib.cue", "package lib\n")
+
+	pub, sec, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []*PublicKey{pub}
+
+	if err := VerifyDir(dir, "example.com/lib", keys); err == nil ||
+		err.Error() != "module is not signed" {
+		t.Fatalf("got error %v; want module is not signed", err)
+	}
+
+	digest, err := Digest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write("cue.mod/"+SigFile, string(Sign(sec, "example.com/lib", digest)))
+
+	// Dependencies, version control directories, and dot files are not
+	// part of the module.
+	write("cue.mod/pkg/example.com/dep/dep.cue", "package dep\n")
+	write(".git/config", "")
+	write(".lib.cue", "package lib\n\nx: 1\n")
+
+	if err := VerifyDir(dir, "example.com/lib", keys); err != nil {
+		t.Fatal(err)
+	}
+
+	write(".hidden/lib.cue", "package lib\n")
+	if err := VerifyDir(dir, "example.com/lib", keys); err == nil {
+		t.Error("VerifyDir succeeded for module with new directory")
+	}
+	os.RemoveAll(filepath.Join(dir, ".hidden"))
+
+	// The target of a symbolic link may change without changing the link.
+	if err := os.Symlink(filepath.Join(dir, "lib.cue"), filepath.Join(dir, "link.cue")); err != nil {
+		t.Fatal(err)
+	}
+	err = VerifyDir(dir, "example.com/lib", keys)
+	if want := "link.cue: signed modules may only contain regular files"; err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %s", err, want)
+	}
+	os.Remove(filepath.Join(dir, "link.cue"))
+
+	write("lib.cue", "package lib\n\nx: 1\n")
+	if err := VerifyDir(dir, "example.com/lib", keys); err == nil {
+		t.Error("VerifyDir succeeded for modified module")
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	pub, _, err := GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := cuecontext.New().CompileString(`[{
+		module: "example.com/schemas/..."
+		keys: ["` + pub.String() + `"]
+	}, {
+		module: "example.com/lib"
+		keys: []
+	}]`)
+	p, err := ParsePolicy(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		module string
+		keys   int
+		ok     bool
+	}{
+		{"example.com/schemas", 1, true},
+		{"example.com/schemas/k8s", 1, true},
+		{"example.com/schemasx", 0, false},
+		{"example.com/lib", 0, true},
+		{"example.com/lib/sub", 0, false},
+		{"other.org/x", 0, false},
+	}
+	for _, tc := range testCases {
+		keys, ok := p.Keys(tc.module)
+		if len(keys) != tc.keys || ok != tc.ok {
+			t.Errorf("%s: got %d keys, %v; want %d keys, %v",
+				tc.module, len(keys), ok, tc.keys, tc.ok)
+		}
+	}
+
+	v = cuecontext.New().CompileString(`[{module: "x", keys: ["RWQ"]}]`)
+	if _, err := ParsePolicy(v); err == nil {
+		t.Error("ParsePolicy succeeded for invalid key")
+	}
+}
@@ -139,6 +139,17 @@ type Runner interface {
 	Run(ctx *Context) (results interface{}, err error)
 }
 
+// A Trigger is a Runner that waits for an external event, such as a timer
+// expiring or a request coming in, and returns the details of the event as
+// its results. A command runs a Trigger again, along with the tasks that
+// depend on it, each time these tasks have completed.
+type Trigger interface {
+	Runner
+
+	// Trigger distinguishes a Trigger from other Runners.
+	Trigger()
+}
+
 // Register registers a task for cue commands.
 func Register(key string, f RunnerFunc) {
 	runners.Store(key, f)
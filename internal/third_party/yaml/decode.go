@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/literal"
@@ -48,8 +49,14 @@ type parser struct {
 	event    yaml_event_t
 	doc      *node
 	info     *token.File
+	src      []byte
+	lines    []int // byte offsets of the start of each line in src
 	last     *node
 	doneInit bool
+
+	// lineOffset is the number of lines of the input preceding the source,
+	// for sources that are part of a larger stream.
+	lineOffset int
 }
 
 func readSource(filename string, src interface{}) ([]byte, error) {
@@ -83,7 +90,7 @@ func newParser(filename string, src interface{}) (*parser, error) {
 	}
 	info := token.NewFile(filename, -1, len(b)+2)
 	info.SetLinesForContent(b)
-	p := parser{info: info}
+	p := parser{info: info, src: b, lines: lineStarts(b)}
 	if !yaml_parser_initialize(&p.parser, filename) {
 		panic("failed to initialize YAML emitter")
 	}
@@ -295,7 +302,7 @@ func (d *decoder) terror(n *node, tag string) string {
 			value = " `" + value + "`"
 		}
 	}
-	msg := fmt.Sprintf("line %d: cannot unmarshal %s%s", n.startPos.line+1, shortTag(tag), value)
+	msg := fmt.Sprintf("line %d: cannot unmarshal %s%s", n.startPos.line+1+d.p.lineOffset, shortTag(tag), value)
 	d.terrors = append(d.terrors, msg)
 	return msg
 }
@@ -363,8 +370,33 @@ func (d *decoder) attachLineComment(m yaml_mark_t, pos int8, expr ast.Node) {
 	}
 }
 
+// lineStarts returns the byte offsets of the start of each line in b.
+func lineStarts(b []byte) []int {
+	lines := []int{0}
+	for i, c := range b {
+		if c == '\n' {
+			lines = append(lines, i+1)
+		}
+	}
+	return lines
+}
+
+// offset returns the byte offset in the source of m. The index and column of
+// a mark count characters, rather than bytes, so they cannot be used as is.
+func (p *parser) offset(m yaml_mark_t) int {
+	if m.line >= len(p.lines) {
+		return len(p.src)
+	}
+	offset := p.lines[m.line]
+	for i := 0; i < m.column && offset < len(p.src); i++ {
+		_, n := utf8.DecodeRune(p.src[offset:])
+		offset += n
+	}
+	return offset
+}
+
 func (d *decoder) pos(m yaml_mark_t) token.Pos {
-	pos := d.p.info.Pos(m.index+1, token.NoRelPos)
+	pos := d.layoutPos(m)
 
 	if d.forceNewline {
 		d.forceNewline = false
@@ -388,11 +420,18 @@ func (d *decoder) pos(m yaml_mark_t) token.Pos {
 	}
 
 	d.prev = pos
-	return pos
+	return d.absPos(m).WithRel(pos.RelPos())
 }
 
 func (d *decoder) absPos(m yaml_mark_t) token.Pos {
-	return d.p.info.Pos(m.index+1, token.NoRelPos)
+	return d.p.info.Pos(d.p.offset(m), token.NoRelPos)
+}
+
+// layoutPos returns the position used to determine the relative layout of
+// nodes. It is one past the position of m, so that a mark at the end of a
+// line is considered to be on the next line.
+func (d *decoder) layoutPos(m yaml_mark_t) token.Pos {
+	return d.p.info.Pos(d.p.offset(m)+1, token.NoRelPos)
 }
 
 func (d *decoder) start(n *node) token.Pos {
@@ -633,9 +672,9 @@ func (d *decoder) sequence(n *node) ast.Expr {
 	list.Lbrack = d.pos(n.startPos).WithRel(token.Blank)
 	switch ln := len(n.children); ln {
 	case 0:
-		d.prev = list.Lbrack
+		d.prev = d.layoutPos(n.startPos)
 	default:
-		d.prev = d.pos(n.children[ln-1].endPos)
+		d.prev = d.layoutPos(n.children[ln-1].endPos)
 	}
 	list.Rbrack = d.pos(n.endPos)
 
@@ -654,8 +693,8 @@ func (d *decoder) sequence(n *node) ast.Expr {
 }
 
 func (d *decoder) isOneLiner(start, end yaml_mark_t) bool {
-	s := d.absPos(start).Position()
-	e := d.absPos(end).Position()
+	s := d.layoutPos(start).Position()
+	e := d.layoutPos(end).Position()
 	return s.Line == e.Line
 }
 
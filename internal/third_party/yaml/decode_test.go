@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -832,6 +833,108 @@ func TestDecoder(t *testing.T) {
 	}
 }
 
+var streamDecoderTests = []struct {
+	data string
+	want string
+}{{
+	"# comment\n---\na: 1\n---\n# comment\n\n---\nb: 2\n",
+	"// comment\n\na: 1\nnull\nb: 2",
+}, {
+	"%YAML 1.1\n---\na: |\n  ---\n  b\n",
+	`a: """` + "\n\t---\n\tb\n\n\t\"\"\"",
+}, {
+	"a: 1\n---\n---\nb: 2",
+	"a: 1\nnull\nb: 2",
+}}
+
+func TestStreamDecoder(t *testing.T) {
+	for i, item := range append(decoderTests, streamDecoderTests...) {
+		t.Run(fmt.Sprintf("test %d: %q", i, item.data), func(t *testing.T) {
+			var values []string
+			dec := yaml.NewStreamDecoder("test.yaml", strings.NewReader(item.data))
+			for {
+				expr, err := dec.Decode()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("err should be nil, was %v", err)
+				}
+				values = append(values, cueStr(expr))
+			}
+			got := strings.Join(values, "\n")
+			if got != item.want {
+				t.Errorf("\n got: %v;\nwant: %v", got, item.want)
+			}
+		})
+	}
+}
+
+func TestStreamDecoderErrors(t *testing.T) {
+	for i, item := range unmarshalErrorTests {
+		t.Run(fmt.Sprintf("test %d: %q", i, item.data), func(t *testing.T) {
+			dec := yaml.NewStreamDecoder("test.yaml", strings.NewReader(item.data))
+			_, err := dec.Decode()
+			if err == nil || err.Error() != item.error {
+				t.Errorf("got %v; want %v", err, item.error)
+			}
+		})
+	}
+}
+
+func TestStreamDecoderPositions(t *testing.T) {
+	data := "a: 1\n---\n# comment\nb: 2\n---\nc: [\n"
+	dec := yaml.NewStreamDecoder("test.yaml", strings.NewReader(data))
+
+	var lines []int
+	for i := 0; i < 2; i++ {
+		expr, err := dec.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := expr.(*ast.StructLit).Elts[0].(*ast.Field)
+		lines = append(lines, f.Label.Pos().Line())
+	}
+	if want := []int{1, 4}; !reflect.DeepEqual(lines, want) {
+		t.Errorf("got lines %v; want %v", lines, want)
+	}
+
+	_, err := dec.Decode()
+	want := "test.yaml:6: did not find expected node content"
+	if err == nil || err.Error() != want {
+		t.Errorf("got %v; want %v", err, want)
+	}
+}
+
+func TestPositions(t *testing.T) {
+	data := "a: ü\nb:\n  - x\n  - {c: 1}\n"
+	expr, err := callUnmarshal(t, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	ast.Walk(expr, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.Ident:
+			got = append(got, fmt.Sprintf("%s@%s", x.Name, x.Pos()))
+		case *ast.BasicLit:
+			got = append(got, fmt.Sprintf("%s@%s", x.Value, x.Pos()))
+		}
+		return true
+	}, nil)
+	want := []string{
+		"a@test.yaml:1:1",
+		`"ü"@test.yaml:1:4`,
+		"b@test.yaml:2:1",
+		`"x"@test.yaml:3:5`,
+		"c@test.yaml:4:6",
+		"1@test.yaml:4:9",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\n got: %v;\nwant: %v", got, want)
+	}
+}
+
 type errReader struct{}
 
 func (errReader) Read([]byte) (int, error) {
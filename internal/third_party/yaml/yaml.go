@@ -7,6 +7,8 @@
 package yaml // import "cuelang.org/go/internal/third_party/yaml"
 
 import (
+	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -127,6 +129,126 @@ func (dec *Decoder) Decode() (expr ast.Expr, err error) {
 	return expr, nil
 }
 
+// A StreamDecoder reads and decodes YAML values from an input stream, like
+// Decoder. Unlike Decoder, which reads the entire input upfront, it reads the
+// input one document at a time, so that its memory use is bounded by the
+// size of the largest document rather than that of the stream.
+type StreamDecoder struct {
+	filename string
+	r        *bufio.Reader
+	dec      *Decoder
+	line     int    // number of lines read so far
+	next     []byte // first line of the next document, if any
+	done     bool
+	emitted  bool
+	err      error
+}
+
+// NewStreamDecoder returns a new StreamDecoder that reads from r.
+func NewStreamDecoder(filename string, r io.Reader) *StreamDecoder {
+	return &StreamDecoder{filename: filename, r: bufio.NewReader(r)}
+}
+
+// Decode reads the next YAML-encoded value from its input. It returns io.EOF
+// if there are no more values in the stream.
+func (d *StreamDecoder) Decode() (ast.Expr, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+	expr, err := d.decode()
+	if err != nil && err != io.EOF {
+		// The parser cannot recover from errors.
+		d.err = err
+	}
+	return expr, err
+}
+
+func (d *StreamDecoder) decode() (ast.Expr, error) {
+	for {
+		if d.dec != nil {
+			expr, err := d.dec.Decode()
+			if err != io.EOF {
+				d.emitted = d.emitted || err == nil
+				return expr, err
+			}
+			d.dec = nil
+		}
+		if d.done {
+			if !d.emitted {
+				// Like Decoder, report an empty stream as a single null value.
+				d.emitted = true
+				return ast.NewNull(), io.EOF
+			}
+			return nil, io.EOF
+		}
+		start := d.line
+		src, err := d.readDocument()
+		if err != nil {
+			return nil, err
+		}
+		if len(src) == 0 {
+			continue
+		}
+		p, err := newParser(d.filename, src)
+		if err != nil {
+			return nil, err
+		}
+		p.lineOffset = start
+		p.info.AddLineInfo(0, d.filename, start+1)
+		d.dec = &Decoder{parser: p, firstDone: true}
+	}
+}
+
+// readDocument reads the lines up to the start of the next document.
+// Documents are delimited by a "---" marker at the start of a line, which
+// YAML does not allow within the content of a document. Comments and
+// directives preceding a marker are part of the document that follows it.
+func (d *StreamDecoder) readDocument() ([]byte, error) {
+	var buf bytes.Buffer
+	hasContent := false
+	if d.next != nil {
+		buf.Write(d.next)
+		d.next = nil
+		d.line++
+		hasContent = true
+	}
+	for {
+		line, err := d.r.ReadBytes('\n')
+		if len(line) > 0 {
+			isStart := isDocumentStart(line)
+			if isStart && hasContent {
+				d.next = line
+				return buf.Bytes(), nil
+			}
+			buf.Write(line)
+			d.line++
+			if isStart || !isIgnorable(line) {
+				hasContent = true
+			}
+		}
+		if err == io.EOF {
+			d.done = true
+			return buf.Bytes(), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func isDocumentStart(line []byte) bool {
+	if !bytes.HasPrefix(line, []byte("---")) {
+		return false
+	}
+	return len(line) == 3 || strings.IndexByte(" \t\r\n", line[3]) >= 0
+}
+
+// isIgnorable reports whether line is blank, a comment, or a directive.
+func isIgnorable(line []byte) bool {
+	line = bytes.TrimSpace(line)
+	return len(line) == 0 || line[0] == '#' || line[0] == '%'
+}
+
 func unmarshal(filename string, in []byte) (expr ast.Expr, err error) {
 	defer handleErr(&err)
 	p, err := newParser(filename, in)
@@ -161,7 +283,7 @@ type yamlError struct {
 
 func (p *parser) failf(line int, format string, args ...interface{}) {
 	where := p.parser.filename + ":"
-	line++
+	line += 1 + p.lineOffset
 	where += strconv.Itoa(line) + ": "
 	panic(yamlError{fmt.Errorf(where+format, args...)})
 }
@@ -0,0 +1,115 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hkdf implements the HMAC-based Extract-and-Expand Key Derivation
+// Function (HKDF) with SHA256, as defined in RFC 5869.
+//
+// Besides deriving keys from an explicit secret, it can derive stable
+// pseudo-random values from the seed of the evaluation context, which is set
+// with the cuecontext.Seed option. This allows configurations to generate
+// identifiers that are unique per environment, but that remain the same
+// across evaluations with the same seed:
+//
+//	id: hkdf.Hex({env: "prod", service: "db"}, 8)
+//
+// The info argument of these functions may be any concrete value. Strings
+// are used as is; other values are used in their canonical JSON encoding, so
+// that, for instance, the order of fields does not matter.
+package hkdf
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/internal/value"
+	"cuelang.org/go/pkg/encoding/json"
+)
+
+// Key derives length bytes from secret, salt, and info. A nil or empty salt
+// is equivalent to a salt of 32 zero bytes. The length may be at most 8160.
+func Key(secret, salt, info []byte, length int) ([]byte, error) {
+	if length < 0 || length > 255*sha256.Size {
+		return nil, fmt.Errorf("invalid length %d: must be between 0 and %d",
+			length, 255*sha256.Size)
+	}
+	if len(salt) == 0 {
+		salt = make([]byte, sha256.Size)
+	}
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(secret)
+	prk := extract.Sum(nil)
+
+	var out, t []byte
+	for i := byte(1); len(out) < length; i++ {
+		expand := hmac.New(sha256.New, prk)
+		expand.Write(t)
+		expand.Write(info)
+		expand.Write([]byte{i})
+		t = expand.Sum(t[:0])
+		out = append(out, t...)
+	}
+	return out[:length], nil
+}
+
+// Derive derives length bytes from info and the seed of the evaluation
+// context. It is an error if the context has no seed.
+func Derive(info cue.Value, length int) ([]byte, error) {
+	r, _ := value.ToInternal(info)
+	seed := r.Seed()
+	if seed == nil {
+		return nil, fmt.Errorf("no seed set for evaluation")
+	}
+	b, err := infoBytes(info)
+	if err != nil {
+		return nil, err
+	}
+	return Key(seed, nil, b, length)
+}
+
+// Hex is like Derive, but returns the derived bytes as a hexadecimal string
+// of 2*length characters.
+func Hex(info cue.Value, length int) (string, error) {
+	b, err := Derive(info, length)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Int derives a pseudo-random integer in [0, n) from info and the seed of the
+// evaluation context. It is an error if n is not positive.
+func Int(info cue.Value, n *big.Int) (*big.Int, error) {
+	if n.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid bound %v: must be positive", n)
+	}
+	// Derive 8 more bytes than needed to make the modulo bias negligible.
+	b, err := Derive(info, (n.BitLen()+7)/8+8)
+	if err != nil {
+		return nil, err
+	}
+	x := new(big.Int).SetBytes(b)
+	return x.Mod(x, n), nil
+}
+
+func infoBytes(v cue.Value) ([]byte, error) {
+	if s, err := v.String(); err == nil {
+		return []byte(s), nil
+	}
+	s, err := json.Canonical(v)
+	return []byte(s), err
+}
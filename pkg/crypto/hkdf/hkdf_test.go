@@ -0,0 +1,62 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hkdf_test
+
+import (
+	"fmt"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/pkg/internal/builtintest"
+)
+
+func TestBuiltin(t *testing.T) {
+	builtintest.Run("hkdf", t)
+}
+
+func TestSeed(t *testing.T) {
+	const src = `
+import "crypto/hkdf"
+
+hex:        hkdf.Hex("db", 8)
+fieldOrder: hkdf.Hex({a: 1, b: 2}, 4) == hkdf.Hex({b: 2, a: 1}, 4)
+info:       hkdf.Hex("db", 8) != hkdf.Hex("web", 8)
+int:        hkdf.Int("port", 1000)
+`
+	eval := func(seed string) string {
+		v := cuecontext.New(cuecontext.Seed([]byte(seed))).CompileString(src)
+		if err := v.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		return fmt.Sprint(v)
+	}
+
+	a, b := eval("staging"), eval("prod")
+	if a != eval("staging") {
+		t.Errorf("evaluations with the same seed differ")
+	}
+	if a == b {
+		t.Errorf("evaluations with different seeds are equal:\n%s", a)
+	}
+	want := `{
+	hex:        "3b966413b1eef3a8"
+	fieldOrder: true
+	info:       true
+	int:        776
+}`
+	if a != want {
+		t.Errorf("got %s; want %s", a, want)
+	}
+}
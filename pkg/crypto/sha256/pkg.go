@@ -50,5 +50,17 @@ var pkg = &internal.Package{
 				c.Ret = Sum224(data)
 			}
 		},
+	}, {
+		Name: "Digest",
+		Params: []internal.Param{
+			{Kind: adt.TopKind},
+		},
+		Result: adt.StringKind,
+		Func: func(c *internal.CallCtxt) {
+			x := c.Value(0)
+			if c.Do() {
+				c.Ret, c.Err = Digest(x)
+			}
+		},
 	}},
 }
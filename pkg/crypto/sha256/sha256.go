@@ -18,7 +18,13 @@
 
 package sha256
 
-import "crypto/sha256"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/pkg/encoding/json"
+)
 
 // The size of a SHA256 checksum in bytes.
 const Size = 32
@@ -40,3 +46,20 @@ func Sum224(data []byte) (sum224 []byte) {
 	a := sha256.Sum224(data)
 	return a[:]
 }
+
+// Digest returns the hexadecimal SHA256 checksum of the canonical JSON
+// encoding of x, as returned by json.Canonical. Values that are equal as JSON
+// have the same digest, regardless of field order or number formatting,
+// which makes it suitable for content-addressed names, as in
+//
+//	name: "config-\(strings.SliceRunes(sha256.Digest(data), 0, 10))"
+//
+// It is an error if x is not concrete.
+func Digest(x cue.Value) (string, error) {
+	s, err := json.Canonical(x)
+	if err != nil {
+		return "", err
+	}
+	a := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(a[:]), nil
+}
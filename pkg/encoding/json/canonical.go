@@ -0,0 +1,125 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"cuelang.org/go/cue"
+)
+
+// Canonical returns the canonical JSON encoding of x, as defined by the JSON
+// Canonicalization Scheme (JCS) of RFC 8785. Values that are equal as JSON
+// have the same canonical encoding, which makes it suitable for hashing and
+// signing, as in
+//
+//	sha256.Sum256(json.Canonical(x))
+//
+// Object members are sorted by their names and numbers are formatted as
+// IEEE 754 double precision numbers, as in JavaScript.
+func Canonical(x cue.Value) (string, error) {
+	v, err := decodeValue(x)
+	if err != nil {
+		return "", err
+	}
+	b := &bytes.Buffer{}
+	if err := encode(b, v, true); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// canonicalNumber formats f as JavaScript's Number.prototype.toString does.
+func canonicalNumber(f float64) string {
+	if f == 0 {
+		return "0" // also for -0
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs < 1e-6 || abs >= 1e21 {
+		format = 'e'
+	}
+	s := strconv.FormatFloat(f, format, -1, 64)
+	if format == 'e' {
+		// Go uses at least two digits for the exponent, as in 1e-07.
+		if i := strings.IndexByte(s, 'e'); s[i+2] == '0' {
+			s = s[:i+2] + s[i+3:]
+		}
+	}
+	return s
+}
+
+// canonicalString writes s as a JSON string, escaping only the characters
+// that must be escaped.
+func canonicalString(b *bytes.Buffer, s string) {
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(b, `\u%04x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+}
+
+// sortedKeys returns a sorted copy of keys, comparing UTF-16 code units as
+// required by RFC 8785.
+func sortedKeys(keys []string) []string {
+	type key struct {
+		s     string
+		units []uint16
+	}
+	a := make([]key, len(keys))
+	for i, k := range keys {
+		a[i] = key{k, utf16.Encode([]rune(k))}
+	}
+	sort.Slice(a, func(i, j int) bool {
+		x, y := a[i].units, a[j].units
+		for k := 0; k < len(x) && k < len(y); k++ {
+			if x[k] != y[k] {
+				return x[k] < y[k]
+			}
+		}
+		return len(x) < len(y)
+	})
+	sorted := make([]string, len(a))
+	for i, k := range a {
+		sorted[i] = k.s
+	}
+	return sorted
+}
@@ -24,6 +24,7 @@ import (
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/value"
 )
 
@@ -105,6 +106,31 @@ func Unmarshal(b []byte) (ast.Expr, error) {
 	return expr, nil
 }
 
+// Check reports whether data is a valid JSON encoding. Unlike Valid, it
+// reports the position and cause of the first syntax error when used as a
+// validator, as in json.Check & s.
+func Check(data []byte) (bool, error) {
+	var x json.RawMessage
+	err := json.Unmarshal(data, &x)
+	se, ok := err.(*json.SyntaxError)
+	if !ok {
+		return err == nil, err
+	}
+	// The error occurred after reading se.Offset bytes.
+	end := int(se.Offset) - 1
+	if end < 0 {
+		end = 0
+	}
+	line, col := 1, 1
+	for _, c := range data[:end] {
+		col++
+		if c == '\n' {
+			line, col = line+1, 1
+		}
+	}
+	return false, internal.Invalidf("invalid JSON at line %d, column %d: %v", line, col, se)
+}
+
 // Validate validates JSON and confirms it matches the constraints
 // specified by v.
 func Validate(b []byte, v cue.Value) (bool, error) {
@@ -0,0 +1,460 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+)
+
+// Pointer returns the value within x referred to by the JSON Pointer p, as
+// defined in RFC 6901. For instance, the pointer "/a/0" refers to the first
+// element of the list in field a. The empty pointer refers to x itself.
+func Pointer(x cue.Value, p string) (ast.Expr, error) {
+	doc, err := decodeValue(x)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := parsePointer(p)
+	if err != nil {
+		return nil, err
+	}
+	v, err := lookup(doc, tokens)
+	if err != nil {
+		return nil, fmt.Errorf("pointer %q: %v", p, err)
+	}
+	return toExpr(v)
+}
+
+// Patch applies the JSON Patch patch, as defined in RFC 6902, to x. A patch
+// is a list of operations, such as
+//
+//	[{op: "replace", path: "/replicas", value: 3},
+//	 {op: "add", path: "/ports/-", value: 8080}]
+//
+// The supported operations are add, remove, replace, move, copy, and test.
+// It is an error if any of the operations fails, including a test.
+func Patch(x, patch cue.Value) (ast.Expr, error) {
+	doc, err := decodeValue(x)
+	if err != nil {
+		return nil, err
+	}
+	ops, err := decodeValue(patch)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := ops.(*array)
+	if !ok {
+		return nil, fmt.Errorf("patch must be a list of operations")
+	}
+	for i, op := range list.elems {
+		if doc, err = applyOp(doc, op); err != nil {
+			return nil, fmt.Errorf("operation %d: %v", i, err)
+		}
+	}
+	return toExpr(doc)
+}
+
+func applyOp(doc, x interface{}) (interface{}, error) {
+	op, ok := x.(*object)
+	if !ok {
+		return nil, fmt.Errorf("operation must be an object")
+	}
+	str := func(name string) (string, []string, error) {
+		s, ok := op.values[name].(string)
+		if !ok {
+			return "", nil, fmt.Errorf("missing or invalid %s", name)
+		}
+		tokens, err := parsePointer(s)
+		return s, tokens, err
+	}
+	name, ok := op.values["op"].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing or invalid op")
+	}
+	path, tokens, err := str("path")
+	if err != nil {
+		return nil, err
+	}
+	value, hasValue := op.values["value"]
+	switch name {
+	case "add", "replace", "test":
+		if !hasValue {
+			return nil, fmt.Errorf("%s: missing value", name)
+		}
+	}
+
+	switch name {
+	case "add":
+		doc, err = add(doc, tokens, value)
+
+	case "remove":
+		doc, err = remove(doc, tokens)
+
+	case "replace":
+		doc, err = replace(doc, tokens, value)
+
+	case "move", "copy":
+		var fromTokens []string
+		var from string
+		if from, fromTokens, err = str("from"); err != nil {
+			return nil, err
+		}
+		if name == "move" && strings.HasPrefix(path, from+"/") {
+			return nil, fmt.Errorf("move: cannot move %q into itself", from)
+		}
+		var v interface{}
+		if v, err = lookup(doc, fromTokens); err != nil {
+			return nil, fmt.Errorf("%s: from %q: %v", name, from, err)
+		}
+		if name == "move" {
+			doc, err = remove(doc, fromTokens)
+		} else {
+			v = clone(v)
+		}
+		if err == nil {
+			doc, err = add(doc, tokens, v)
+		}
+
+	case "test":
+		var v interface{}
+		if v, err = lookup(doc, tokens); err == nil {
+			a, b := &bytes.Buffer{}, &bytes.Buffer{}
+			if err = encode(a, v, true); err == nil {
+				err = encode(b, value, true)
+			}
+			if err == nil && a.String() != b.String() {
+				return nil, fmt.Errorf("test: value at %q is %s, not %s", path, a, b)
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown op %q", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: path %q: %v", name, path, err)
+	}
+	return doc, nil
+}
+
+// An object is a decoded JSON object that retains the order of its members.
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (o *object) set(key string, v interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = v
+}
+
+func (o *object) delete(key string) {
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// An array is a decoded JSON array.
+type array struct {
+	elems []interface{}
+}
+
+// decodeValue decodes the JSON representation of v into a tree of nil,
+// bool, json.Number, string, *array, and *object values.
+func decodeValue(v cue.Value) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	return decode(d)
+}
+
+func decode(d *json.Decoder) (interface{}, error) {
+	t, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t {
+	case json.Delim('{'):
+		o := &object{values: map[string]interface{}{}}
+		for d.More() {
+			t, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := decode(d)
+			if err != nil {
+				return nil, err
+			}
+			o.set(t.(string), v)
+		}
+		_, err = d.Token()
+		return o, err
+
+	case json.Delim('['):
+		a := &array{elems: []interface{}{}}
+		for d.More() {
+			v, err := decode(d)
+			if err != nil {
+				return nil, err
+			}
+			a.elems = append(a.elems, v)
+		}
+		_, err = d.Token()
+		return a, err
+	}
+	return t, nil
+}
+
+func clone(v interface{}) interface{} {
+	switch x := v.(type) {
+	case *object:
+		o := &object{values: map[string]interface{}{}}
+		for _, k := range x.keys {
+			o.set(k, clone(x.values[k]))
+		}
+		return o
+	case *array:
+		a := &array{elems: make([]interface{}, len(x.elems))}
+		for i, e := range x.elems {
+			a.elems[i] = clone(e)
+		}
+		return a
+	}
+	return v
+}
+
+func toExpr(v interface{}) (ast.Expr, error) {
+	b := &bytes.Buffer{}
+	if err := encode(b, v, false); err != nil {
+		return nil, err
+	}
+	return Unmarshal(b.Bytes())
+}
+
+// encode writes the JSON encoding of v to b. If canonical is set, it uses
+// the JSON Canonicalization Scheme of RFC 8785.
+func encode(b *bytes.Buffer, v interface{}, canonical bool) error {
+	switch x := v.(type) {
+	case nil:
+		b.WriteString("null")
+
+	case bool:
+		b.WriteString(strconv.FormatBool(x))
+
+	case json.Number:
+		if !canonical {
+			b.WriteString(string(x))
+			break
+		}
+		f, err := strconv.ParseFloat(string(x), 64)
+		if err != nil {
+			return fmt.Errorf("cannot canonicalize number %s: %v", x, err)
+		}
+		b.WriteString(canonicalNumber(f))
+
+	case string:
+		if canonical {
+			canonicalString(b, x)
+			break
+		}
+		s, _ := json.Marshal(x)
+		b.Write(s)
+
+	case *array:
+		b.WriteByte('[')
+		for i, e := range x.elems {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := encode(b, e, canonical); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+
+	case *object:
+		keys := x.keys
+		if canonical {
+			keys = sortedKeys(keys)
+		}
+		b.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := encode(b, k, canonical); err != nil {
+				return err
+			}
+			b.WriteByte(':')
+			if err := encode(b, x.values[k], canonical); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	}
+	return nil
+}
+
+var unescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if p[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must be empty or start with /", p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = unescaper.Replace(t)
+	}
+	return tokens, nil
+}
+
+// index returns the index of an array of length n denoted by token t. The
+// token "-" denotes the index n if end is set.
+func index(t string, n int, end bool) (int, error) {
+	if t == "-" && end {
+		return n, nil
+	}
+	i, err := strconv.Atoi(t)
+	if err != nil || t[0] < '0' || '9' < t[0] || (len(t) > 1 && t[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", t)
+	}
+	if i > n || (i == n && !end) {
+		return 0, fmt.Errorf("index %d out of range", i)
+	}
+	return i, nil
+}
+
+func lookup(v interface{}, tokens []string) (interface{}, error) {
+	for _, t := range tokens {
+		switch x := v.(type) {
+		case *object:
+			var ok bool
+			if v, ok = x.values[t]; !ok {
+				return nil, fmt.Errorf("member %q not found", t)
+			}
+		case *array:
+			i, err := index(t, len(x.elems), false)
+			if err != nil {
+				return nil, err
+			}
+			v = x.elems[i]
+		default:
+			return nil, fmt.Errorf("cannot select %q from a scalar", t)
+		}
+	}
+	return v, nil
+}
+
+// parent returns the container of the value denoted by tokens and the last
+// token.
+func parent(doc interface{}, tokens []string) (interface{}, string, error) {
+	p, err := lookup(doc, tokens[:len(tokens)-1])
+	return p, tokens[len(tokens)-1], err
+}
+
+func add(doc interface{}, tokens []string, v interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return v, nil
+	}
+	p, t, err := parent(doc, tokens)
+	if err != nil {
+		return nil, err
+	}
+	switch x := p.(type) {
+	case *object:
+		x.set(t, v)
+	case *array:
+		i, err := index(t, len(x.elems), true)
+		if err != nil {
+			return nil, err
+		}
+		x.elems = append(x.elems, nil)
+		copy(x.elems[i+1:], x.elems[i:])
+		x.elems[i] = v
+	default:
+		return nil, fmt.Errorf("cannot add %q to a scalar", t)
+	}
+	return doc, nil
+}
+
+func remove(doc interface{}, tokens []string) (interface{}, error) {
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("cannot remove the root")
+	}
+	p, t, err := parent(doc, tokens)
+	if err != nil {
+		return nil, err
+	}
+	switch x := p.(type) {
+	case *object:
+		if _, ok := x.values[t]; !ok {
+			return nil, fmt.Errorf("member %q not found", t)
+		}
+		x.delete(t)
+	case *array:
+		i, err := index(t, len(x.elems), false)
+		if err != nil {
+			return nil, err
+		}
+		x.elems = append(x.elems[:i], x.elems[i+1:]...)
+	default:
+		return nil, fmt.Errorf("cannot select %q from a scalar", t)
+	}
+	return doc, nil
+}
+
+func replace(doc interface{}, tokens []string, v interface{}) (interface{}, error) {
+	if len(tokens) == 0 {
+		return v, nil
+	}
+	p, t, err := parent(doc, tokens)
+	if err != nil {
+		return nil, err
+	}
+	switch x := p.(type) {
+	case *object:
+		if _, ok := x.values[t]; !ok {
+			return nil, fmt.Errorf("member %q not found", t)
+		}
+		x.set(t, v)
+	case *array:
+		i, err := index(t, len(x.elems), false)
+		if err != nil {
+			return nil, err
+		}
+		x.elems[i] = v
+	default:
+		return nil, fmt.Errorf("cannot select %q from a scalar", t)
+	}
+	return doc, nil
+}
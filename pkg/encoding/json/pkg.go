@@ -18,6 +18,18 @@ var _ = adt.TopKind // in case the adt package isn't used
 
 var pkg = &internal.Package{
 	Native: []*internal.Builtin{{
+		Name: "Canonical",
+		Params: []internal.Param{
+			{Kind: adt.TopKind},
+		},
+		Result: adt.StringKind,
+		Func: func(c *internal.CallCtxt) {
+			x := c.Value(0)
+			if c.Do() {
+				c.Ret, c.Err = Canonical(x)
+			}
+		},
+	}, {
 		Name: "Valid",
 		Params: []internal.Param{
 			{Kind: adt.BytesKind | adt.StringKind},
@@ -103,6 +115,18 @@ var pkg = &internal.Package{
 				c.Ret, c.Err = Unmarshal(b)
 			}
 		},
+	}, {
+		Name: "Check",
+		Params: []internal.Param{
+			{Kind: adt.BytesKind | adt.StringKind},
+		},
+		Result: adt.BoolKind,
+		Func: func(c *internal.CallCtxt) {
+			data := c.Bytes(0)
+			if c.Do() {
+				c.Ret, c.Err = Check(data)
+			}
+		},
 	}, {
 		Name: "Validate",
 		Params: []internal.Param{
@@ -116,5 +140,31 @@ var pkg = &internal.Package{
 				c.Ret, c.Err = Validate(b, v)
 			}
 		},
+	}, {
+		Name: "Pointer",
+		Params: []internal.Param{
+			{Kind: adt.TopKind},
+			{Kind: adt.StringKind},
+		},
+		Result: adt.TopKind,
+		Func: func(c *internal.CallCtxt) {
+			x, p := c.Value(0), c.String(1)
+			if c.Do() {
+				c.Ret, c.Err = Pointer(x, p)
+			}
+		},
+	}, {
+		Name: "Patch",
+		Params: []internal.Param{
+			{Kind: adt.TopKind},
+			{Kind: adt.TopKind},
+		},
+		Result: adt.TopKind,
+		Func: func(c *internal.CallCtxt) {
+			x, patch := c.Value(0), c.Value(1)
+			if c.Do() {
+				c.Ret, c.Err = Patch(x, patch)
+			}
+		},
 	}},
 }
@@ -117,8 +117,11 @@ func toBuiltin(ctx *adt.OpContext, b *Builtin) *adt.Builtin {
 	}
 
 	x := &adt.Builtin{
-		Params:  params,
-		Result:  b.Result,
+		Params: params,
+		Result: b.Result,
+		// Native builtins only compute a result from their arguments. Side
+		// effects are left to the tasks of the tool packages.
+		Pure:    true,
 		Package: b.Pkg,
 		Name:    b.Name,
 	}
@@ -137,6 +140,14 @@ func toBuiltin(ctx *adt.OpContext, b *Builtin) *adt.Builtin {
 			ret = processErr(c, errVal, ret)
 		}()
 		b.Func(c)
+		if err, ok := c.Err.(*internal.ValidationError); ok {
+			// The explanation of a failed predicate is only reported if the
+			// predicate is used as a validator.
+			c.Err = nil
+			if ctx.IsValidating() {
+				return &adt.Bottom{Err: errors.Newf(c.Pos(), "%s", err.Msg)}
+			}
+		}
 		switch v := c.Ret.(type) {
 		case nil:
 			// Validators may return a nil in case validation passes.
@@ -18,12 +18,12 @@ import (
 	"fmt"
 	"testing"
 
+	"cuelang.org/go/cue/cuetxtar"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/internal/core/eval"
 	"cuelang.org/go/internal/core/export"
 	"cuelang.org/go/internal/core/runtime"
 	"cuelang.org/go/internal/core/validate"
-	"cuelang.org/go/internal/cuetxtar"
 )
 
 func Run(name string, t *testing.T) {
@@ -20,6 +20,7 @@ import (
 	"net"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/internal"
 )
 
 // IP address lengths (bytes).
@@ -96,46 +97,74 @@ func netToList(ip net.IP) []uint {
 	return a
 }
 
+// parseIP returns the IP address ip, which may be a string or list of bytes,
+// or an error explaining why it is not valid.
+func parseIP(ip cue.Value) (net.IP, error) {
+	goip := netGetIP(ip)
+	if len(goip) != IPv4len && len(goip) != IPv6len {
+		return nil, internal.Invalidf("invalid IP address")
+	}
+	return goip, nil
+}
+
+// checkIP reports whether ip is a valid IP address for which it reports true
+// and otherwise explains why it is not.
+func checkIP(ip cue.Value, is func(net.IP) bool, reason string) (bool, error) {
+	goip, err := parseIP(ip)
+	if err != nil {
+		return false, err
+	}
+	if !is(goip) {
+		return false, internal.Invalidf("%s", reason)
+	}
+	return true, nil
+}
+
 // IPv4 reports whether s is a valid IPv4 address.
 //
 // The address may be a string or list of bytes.
-func IPv4(ip cue.Value) bool {
+func IPv4(ip cue.Value) (bool, error) {
 	// TODO: convert to native CUE.
-	return netGetIP(ip).To4() != nil
+	return checkIP(ip, func(ip net.IP) bool { return ip.To4() != nil },
+		"not an IPv4 address")
 }
 
 // IP reports whether s is a valid IPv4 or IPv6 address.
 //
 // The address may be a string or list of bytes.
-func IP(ip cue.Value) bool {
+func IP(ip cue.Value) (bool, error) {
 	// TODO: convert to native CUE.
-	return netGetIP(ip) != nil
+	_, err := parseIP(ip)
+	return err == nil, err
 }
 
 // LoopbackIP reports whether ip is a loopback address.
-func LoopbackIP(ip cue.Value) bool {
-	return netGetIP(ip).IsLoopback()
+func LoopbackIP(ip cue.Value) (bool, error) {
+	return checkIP(ip, net.IP.IsLoopback, "not a loopback address")
 }
 
 // MulticastIP reports whether ip is a multicast address.
-func MulticastIP(ip cue.Value) bool {
-	return netGetIP(ip).IsMulticast()
+func MulticastIP(ip cue.Value) (bool, error) {
+	return checkIP(ip, net.IP.IsMulticast, "not a multicast address")
 }
 
 // InterfaceLocalMulticastIP reports whether ip is an interface-local multicast
 // address.
-func InterfaceLocalMulticastIP(ip cue.Value) bool {
-	return netGetIP(ip).IsInterfaceLocalMulticast()
+func InterfaceLocalMulticastIP(ip cue.Value) (bool, error) {
+	return checkIP(ip, net.IP.IsInterfaceLocalMulticast,
+		"not an interface-local multicast address")
 }
 
 // LinkLocalMulticast reports whether ip is a link-local multicast address.
-func LinkLocalMulticastIP(ip cue.Value) bool {
-	return netGetIP(ip).IsLinkLocalMulticast()
+func LinkLocalMulticastIP(ip cue.Value) (bool, error) {
+	return checkIP(ip, net.IP.IsLinkLocalMulticast,
+		"not a link-local multicast address")
 }
 
 // LinkLocalUnicastIP reports whether ip is a link-local unicast address.
-func LinkLocalUnicastIP(ip cue.Value) bool {
-	return netGetIP(ip).IsLinkLocalUnicast()
+func LinkLocalUnicastIP(ip cue.Value) (bool, error) {
+	return checkIP(ip, net.IP.IsLinkLocalUnicast,
+		"not a link-local unicast address")
 }
 
 // GlobalUnicastIP reports whether ip is a global unicast address.
@@ -144,14 +173,63 @@ func LinkLocalUnicastIP(ip cue.Value) bool {
 // identification as defined in RFC 1122, RFC 4632 and RFC 4291 with the
 // exception of IPv4 directed broadcast addresses. It returns true even if ip is
 // in IPv4 private address space or local IPv6 unicast address space.
-func GlobalUnicastIP(ip cue.Value) bool {
-	return netGetIP(ip).IsGlobalUnicast()
+func GlobalUnicastIP(ip cue.Value) (bool, error) {
+	return checkIP(ip, net.IP.IsGlobalUnicast, "not a global unicast address")
 }
 
 // UnspecifiedIP reports whether ip is an unspecified address, either the IPv4
 // address "0.0.0.0" or the IPv6 address "::".
-func UnspecifiedIP(ip cue.Value) bool {
-	return netGetIP(ip).IsUnspecified()
+func UnspecifiedIP(ip cue.Value) (bool, error) {
+	return checkIP(ip, net.IP.IsUnspecified, "not an unspecified address")
+}
+
+// PrivateIP reports whether ip is a private address, according to RFC 1918
+// for IPv4 addresses and RFC 4193 for IPv6 addresses.
+func PrivateIP(ip cue.Value) (bool, error) {
+	return checkIP(ip, isPrivate, "not a private address")
+}
+
+func isPrivate(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4[0] == 10 ||
+			(ip4[0] == 172 && ip4[1]&0xf0 == 16) ||
+			(ip4[0] == 192 && ip4[1] == 168)
+	}
+	return len(ip) == IPv6len && ip[0]&0xfe == 0xfc
+}
+
+// IPInfo reports properties of the IP address ip, which may be a string or a
+// list of bytes. The result is a struct with the fields
+//
+//	family:             4 or 6
+//	ip:                 the canonical string form of ip, as given by IPString
+//	loopback:           whether ip is a loopback address
+//	private:            whether ip is a private address
+//	multicast:          whether ip is a multicast address
+//	linkLocalUnicast:   whether ip is a link-local unicast address
+//	linkLocalMulticast: whether ip is a link-local multicast address
+//	globalUnicast:      whether ip is a global unicast address
+//	unspecified:        whether ip is an unspecified address
+func IPInfo(ip cue.Value) (map[string]interface{}, error) {
+	goip, err := parseIP(ip)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP address %v", ip)
+	}
+	family := 6
+	if goip.To4() != nil {
+		family = 4
+	}
+	return map[string]interface{}{
+		"family":             family,
+		"ip":                 goip.String(),
+		"loopback":           goip.IsLoopback(),
+		"private":            isPrivate(goip),
+		"multicast":          goip.IsMulticast(),
+		"linkLocalUnicast":   goip.IsLinkLocalUnicast(),
+		"linkLocalMulticast": goip.IsLinkLocalMulticast(),
+		"globalUnicast":      goip.IsGlobalUnicast(),
+		"unspecified":        goip.IsUnspecified(),
+	}, nil
 }
 
 // ToIP4 converts a given IP address, which may be a string or a list, to its
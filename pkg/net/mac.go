@@ -0,0 +1,46 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package net
+
+import (
+	"fmt"
+	"net"
+
+	"cuelang.org/go/internal"
+)
+
+// MAC reports whether s is a valid IEEE 802 MAC-48, EUI-48, EUI-64, or
+// 20-octet IP over InfiniBand link-layer address, using one of the formats
+//
+//	00:00:5e:00:53:01
+//	00-00-5e-00-53-01
+//	0000.5e00.5301
+func MAC(s string) (bool, error) {
+	if _, err := net.ParseMAC(s); err != nil {
+		return false, internal.Invalidf("invalid MAC address")
+	}
+	return true, nil
+}
+
+// MACString returns the canonical form of the MAC address s, which uses
+// lower-case hexadecimal digits separated by colons, as in
+// "00:00:5e:00:53:01".
+func MACString(s string) (string, error) {
+	mac, err := net.ParseMAC(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid MAC address %q", s)
+	}
+	return mac.String(), nil
+}
@@ -81,7 +81,7 @@ var pkg = &internal.Package{
 		Func: func(c *internal.CallCtxt) {
 			ip := c.Value(0)
 			if c.Do() {
-				c.Ret = IPv4(ip)
+				c.Ret, c.Err = IPv4(ip)
 			}
 		},
 	}, {
@@ -93,7 +93,7 @@ var pkg = &internal.Package{
 		Func: func(c *internal.CallCtxt) {
 			ip := c.Value(0)
 			if c.Do() {
-				c.Ret = IP(ip)
+				c.Ret, c.Err = IP(ip)
 			}
 		},
 	}, {
@@ -105,7 +105,7 @@ var pkg = &internal.Package{
 		Func: func(c *internal.CallCtxt) {
 			ip := c.Value(0)
 			if c.Do() {
-				c.Ret = LoopbackIP(ip)
+				c.Ret, c.Err = LoopbackIP(ip)
 			}
 		},
 	}, {
@@ -117,7 +117,7 @@ var pkg = &internal.Package{
 		Func: func(c *internal.CallCtxt) {
 			ip := c.Value(0)
 			if c.Do() {
-				c.Ret = MulticastIP(ip)
+				c.Ret, c.Err = MulticastIP(ip)
 			}
 		},
 	}, {
@@ -129,7 +129,7 @@ var pkg = &internal.Package{
 		Func: func(c *internal.CallCtxt) {
 			ip := c.Value(0)
 			if c.Do() {
-				c.Ret = InterfaceLocalMulticastIP(ip)
+				c.Ret, c.Err = InterfaceLocalMulticastIP(ip)
 			}
 		},
 	}, {
@@ -141,7 +141,7 @@ var pkg = &internal.Package{
 		Func: func(c *internal.CallCtxt) {
 			ip := c.Value(0)
 			if c.Do() {
-				c.Ret = LinkLocalMulticastIP(ip)
+				c.Ret, c.Err = LinkLocalMulticastIP(ip)
 			}
 		},
 	}, {
@@ -153,7 +153,7 @@ var pkg = &internal.Package{
 		Func: func(c *internal.CallCtxt) {
 			ip := c.Value(0)
 			if c.Do() {
-				c.Ret = LinkLocalUnicastIP(ip)
+				c.Ret, c.Err = LinkLocalUnicastIP(ip)
 			}
 		},
 	}, {
@@ -165,7 +165,7 @@ var pkg = &internal.Package{
 		Func: func(c *internal.CallCtxt) {
 			ip := c.Value(0)
 			if c.Do() {
-				c.Ret = GlobalUnicastIP(ip)
+				c.Ret, c.Err = GlobalUnicastIP(ip)
 			}
 		},
 	}, {
@@ -177,7 +177,31 @@ var pkg = &internal.Package{
 		Func: func(c *internal.CallCtxt) {
 			ip := c.Value(0)
 			if c.Do() {
-				c.Ret = UnspecifiedIP(ip)
+				c.Ret, c.Err = UnspecifiedIP(ip)
+			}
+		},
+	}, {
+		Name: "PrivateIP",
+		Params: []internal.Param{
+			{Kind: adt.TopKind},
+		},
+		Result: adt.BoolKind,
+		Func: func(c *internal.CallCtxt) {
+			ip := c.Value(0)
+			if c.Do() {
+				c.Ret, c.Err = PrivateIP(ip)
+			}
+		},
+	}, {
+		Name: "IPInfo",
+		Params: []internal.Param{
+			{Kind: adt.TopKind},
+		},
+		Result: adt.StructKind,
+		Func: func(c *internal.CallCtxt) {
+			ip := c.Value(0)
+			if c.Do() {
+				c.Ret, c.Err = IPInfo(ip)
 			}
 		},
 	}, {
@@ -216,5 +240,29 @@ var pkg = &internal.Package{
 				c.Ret, c.Err = IPString(ip)
 			}
 		},
+	}, {
+		Name: "MAC",
+		Params: []internal.Param{
+			{Kind: adt.StringKind},
+		},
+		Result: adt.BoolKind,
+		Func: func(c *internal.CallCtxt) {
+			s := c.String(0)
+			if c.Do() {
+				c.Ret, c.Err = MAC(s)
+			}
+		},
+	}, {
+		Name: "MACString",
+		Params: []internal.Param{
+			{Kind: adt.StringKind},
+		},
+		Result: adt.StringKind,
+		Func: func(c *internal.CallCtxt) {
+			s := c.String(0)
+			if c.Do() {
+				c.Ret, c.Err = MACString(s)
+			}
+		},
 	}},
 }
@@ -0,0 +1,303 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package quantity provides functions for quantities as used by Kubernetes
+// for resource requests and limits, such as "500Mi", "2.5Gi", or "250m".
+//
+// A quantity is a decimal number followed by an optional suffix. The suffix
+// is one of the binary SI suffixes Ki, Mi, Gi, Ti, Pi, and Ei, the decimal SI
+// suffixes n, u, m, k, M, G, T, P, and E, or a decimal exponent, as in 1e3.
+// Quantities compare by their numeric value, so "1Gi" is greater than "1G"
+// and "1000m" equals "1".
+//
+// Quantities are formatted in their canonical form, which uses the largest
+// suffix for which the number is an integer, using the same kind of suffix
+// as the original quantity. Numbers are rounded up to a precision of 1n.
+// Binary SI quantities that are less than 1Ki or that are not integers are
+// formatted with decimal SI suffixes.
+//
+// Durations are supported by package time.
+package quantity
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/apd/v2"
+
+	"cuelang.org/go/internal"
+)
+
+var apdContext = apd.BaseContext.WithPrecision(100)
+
+type format int
+
+const (
+	decimalSI format = iota
+	binarySI
+	decimalExponent
+)
+
+var formats = map[string]format{
+	"DecimalSI":       decimalSI,
+	"BinarySI":        binarySI,
+	"DecimalExponent": decimalExponent,
+}
+
+var binarySuffixes = []string{"", "Ki", "Mi", "Gi", "Ti", "Pi", "Ei"}
+
+var decimalSuffixes = map[int32]string{
+	-9: "n",
+	-6: "u",
+	-3: "m",
+	0:  "",
+	3:  "k",
+	6:  "M",
+	9:  "G",
+	12: "T",
+	15: "P",
+	18: "E",
+}
+
+type quantity struct {
+	d      apd.Decimal
+	format format
+}
+
+func parse(s string) (*quantity, error) {
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return (r < '0' || '9' < r) && r != '.' && r != '+' && r != '-'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+	num, suffix := s[:i], s[i:]
+	if num == "" || strings.ContainsAny(num[1:], "+-") {
+		return nil, fmt.Errorf("invalid quantity %q", s)
+	}
+
+	q := &quantity{}
+	if _, _, err := q.d.SetString(num); err != nil {
+		return nil, fmt.Errorf("invalid quantity %q", s)
+	}
+
+	for i, x := range binarySuffixes[1:] {
+		if suffix == x {
+			q.format = binarySI
+			m := apd.NewWithBigInt(new(big.Int).Lsh(big.NewInt(1), 10*uint(i+1)), 0)
+			if _, err := apdContext.Mul(&q.d, &q.d, m); err != nil {
+				return nil, err
+			}
+			return q, nil
+		}
+	}
+	for exp, x := range decimalSuffixes {
+		if suffix == x {
+			q.d.Exponent += exp
+			return q, nil
+		}
+	}
+	if len(suffix) > 1 && (suffix[0] == 'e' || suffix[0] == 'E') {
+		exp, err := strconv.ParseInt(suffix[1:], 10, 32)
+		if err == nil {
+			q.format = decimalExponent
+			q.d.Exponent += int32(exp)
+			return q, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid quantity %q", s)
+}
+
+func (q *quantity) String() string {
+	var x apd.Decimal
+	x.Reduce(&q.d)
+	if x.Exponent < -9 {
+		// Round up to a precision of 1n.
+		x.Exponent += 9
+		_, _ = apdContext.Ceil(&x, &x)
+		x.Exponent -= 9
+		x.Reduce(&x)
+	}
+	if x.IsZero() {
+		return "0"
+	}
+
+	sign := ""
+	if x.Negative {
+		sign = "-"
+	}
+
+	if q.format == binarySI && x.Exponent >= 0 {
+		n := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(x.Exponent)), nil)
+		n.Mul(n, &x.Coeff)
+		if n.Cmp(big.NewInt(1024)) >= 0 {
+			i := 0
+			m := new(big.Int)
+			for ; i < len(binarySuffixes)-1; i++ {
+				if m.And(n, big.NewInt(1023)).Sign() != 0 {
+					break
+				}
+				n.Rsh(n, 10)
+			}
+			return sign + n.String() + binarySuffixes[i]
+		}
+	}
+
+	exp := x.Exponent - (x.Exponent%3+3)%3
+	if exp > 18 {
+		exp = 18
+	}
+	n := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(x.Exponent-exp)), nil)
+	n.Mul(n, &x.Coeff)
+
+	suffix := decimalSuffixes[exp]
+	if q.format == decimalExponent {
+		suffix = ""
+		if exp != 0 {
+			suffix = fmt.Sprintf("e%d", exp)
+		}
+	}
+	return sign + n.String() + suffix
+}
+
+// Valid reports whether s is a valid quantity.
+func Valid(s string) (bool, error) {
+	if _, err := parse(s); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Parse reports the numeric value of quantity s. For instance, the value of
+// "500Mi" is 524288000 and the value of "250m" is 0.25.
+func Parse(s string) (*internal.Decimal, error) {
+	q, err := parse(s)
+	if err != nil {
+		return nil, err
+	}
+	var d internal.Decimal
+	d.Reduce(&q.d)
+	if d.Exponent > 0 {
+		// Represent integers as such.
+		_, err = apdContext.Quantize(&d, &d, 0)
+	}
+	return &d, err
+}
+
+// Format formats the number x as a quantity. The format is one of
+// "DecimalSI", as in "1500k", "BinarySI", as in "512Mi", or
+// "DecimalExponent", as in "15e5".
+func Format(x *internal.Decimal, format string) (string, error) {
+	f, ok := formats[format]
+	if !ok {
+		return "", fmt.Errorf("unknown quantity format %q", format)
+	}
+	q := &quantity{format: f}
+	q.d.Set(x)
+	return q.String(), nil
+}
+
+// Canonical reports the canonical form of quantity s. For instance, the
+// canonical form of "1024Mi" is "1Gi" and that of "0.5" is "500m".
+func Canonical(s string) (string, error) {
+	q, err := parse(s)
+	if err != nil {
+		return "", err
+	}
+	return q.String(), nil
+}
+
+// Add reports the sum of the quantities x and y, using the format of x.
+func Add(x, y string) (string, error) {
+	return arith(x, y, apdContext.Add)
+}
+
+// Sub reports the difference of the quantities x and y, using the format of
+// x.
+func Sub(x, y string) (string, error) {
+	return arith(x, y, apdContext.Sub)
+}
+
+func arith(x, y string, op func(d, x, y *apd.Decimal) (apd.Condition, error)) (string, error) {
+	a, err := parse(x)
+	if err != nil {
+		return "", err
+	}
+	b, err := parse(y)
+	if err != nil {
+		return "", err
+	}
+	if _, err := op(&a.d, &a.d, &b.d); err != nil {
+		return "", err
+	}
+	return a.String(), nil
+}
+
+// Mul reports the quantity x multiplied by the number n, using the format of
+// x.
+func Mul(x string, n *internal.Decimal) (string, error) {
+	q, err := parse(x)
+	if err != nil {
+		return "", err
+	}
+	if _, err := apdContext.Mul(&q.d, &q.d, n); err != nil {
+		return "", err
+	}
+	return q.String(), nil
+}
+
+// Cmp compares the quantities x and y and returns:
+//
+//	-1 if x <  y
+//	 0 if x == y
+//	+1 if x >  y
+func Cmp(x, y string) (int, error) {
+	a, err := parse(x)
+	if err != nil {
+		return 0, err
+	}
+	b, err := parse(y)
+	if err != nil {
+		return 0, err
+	}
+	return a.d.Cmp(&b.d), nil
+}
+
+// LessThan reports whether quantity x is less than quantity y. It can be
+// used as a validator, as in quantity.LessThan("1Gi").
+func LessThan(x, y string) (bool, error) {
+	c, err := Cmp(x, y)
+	return c < 0, err
+}
+
+// LessEqual reports whether quantity x is less than or equal to quantity y.
+func LessEqual(x, y string) (bool, error) {
+	c, err := Cmp(x, y)
+	return c <= 0, err
+}
+
+// GreaterThan reports whether quantity x is greater than quantity y.
+func GreaterThan(x, y string) (bool, error) {
+	c, err := Cmp(x, y)
+	return c > 0, err
+}
+
+// GreaterEqual reports whether quantity x is greater than or equal to
+// quantity y.
+func GreaterEqual(x, y string) (bool, error) {
+	c, err := Cmp(x, y)
+	return c >= 0, err
+}
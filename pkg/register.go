@@ -15,6 +15,7 @@
 package pkg
 
 import (
+	_ "cuelang.org/go/pkg/crypto/hkdf"
 	_ "cuelang.org/go/pkg/crypto/hmac"
 	_ "cuelang.org/go/pkg/crypto/md5"
 	_ "cuelang.org/go/pkg/crypto/sha1"
@@ -32,10 +33,12 @@ import (
 	_ "cuelang.org/go/pkg/math/bits"
 	_ "cuelang.org/go/pkg/net"
 	_ "cuelang.org/go/pkg/path"
+	_ "cuelang.org/go/pkg/quantity"
 	_ "cuelang.org/go/pkg/regexp"
 	_ "cuelang.org/go/pkg/strconv"
 	_ "cuelang.org/go/pkg/strings"
 	_ "cuelang.org/go/pkg/struct"
+	_ "cuelang.org/go/pkg/text/diff"
 	_ "cuelang.org/go/pkg/text/tabwriter"
 	_ "cuelang.org/go/pkg/text/template"
 	_ "cuelang.org/go/pkg/time"
@@ -43,7 +46,11 @@ import (
 	_ "cuelang.org/go/pkg/tool/cli"
 	_ "cuelang.org/go/pkg/tool/exec"
 	_ "cuelang.org/go/pkg/tool/file"
+	_ "cuelang.org/go/pkg/tool/helm"
 	_ "cuelang.org/go/pkg/tool/http"
+	_ "cuelang.org/go/pkg/tool/k8s"
 	_ "cuelang.org/go/pkg/tool/os"
+	_ "cuelang.org/go/pkg/tool/sql"
+	_ "cuelang.org/go/pkg/tool/trigger"
 	_ "cuelang.org/go/pkg/uuid"
 )
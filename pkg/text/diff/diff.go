@@ -0,0 +1,331 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package diff computes and applies line-based differences between texts
+// in the unified diff format, as produced by diff -u.
+//
+// For instance, a test may check that a rendered output equals the expected
+// one, reporting the differences if it does not:
+//
+//	check: diff.Diff("want", want, "got", yaml.Marshal(config)) & ""
+package diff
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// context is the number of unchanged lines shown around changes.
+const context = 3
+
+// Diff returns a unified diff of the texts old and new, labeled oldName and
+// newName. It returns the empty string if the texts are equal.
+func Diff(oldName, old, newName, new string) string {
+	if old == new {
+		return ""
+	}
+	a, b := splitLines(old), splitLines(new)
+	edits := shortestEdits(a, b)
+
+	w := &strings.Builder{}
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", oldName, newName)
+
+	// x and y are the line numbers in a and b at edit i.
+	x, y := make([]int, len(edits)+1), make([]int, len(edits)+1)
+	for i, e := range edits {
+		x[i+1], y[i+1] = x[i], y[i]
+		if e.op != '+' {
+			x[i+1]++
+		}
+		if e.op != '-' {
+			y[i+1]++
+		}
+	}
+
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			i++
+			continue
+		}
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+		// Extend the hunk until the next change is more than twice the
+		// context away.
+		end := i
+		for {
+			for end < len(edits) && edits[end].op != ' ' {
+				end++
+			}
+			n := end
+			for n < len(edits) && edits[n].op == ' ' {
+				n++
+			}
+			if n == len(edits) || n-end > 2*context {
+				break
+			}
+			end = n
+		}
+		if end += context; end > len(edits) {
+			end = len(edits)
+		}
+
+		fmt.Fprintf(w, "@@ -%s +%s @@\n",
+			hunkRange(x[start], x[end]-x[start]),
+			hunkRange(y[start], y[end]-y[start]))
+		for _, e := range edits[start:end] {
+			w.WriteByte(e.op)
+			w.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				w.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return w.String()
+}
+
+func hunkRange(start, n int) string {
+	switch n {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return strconv.Itoa(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
+
+// Patch applies the unified diff patch to s. Hunks may apply at an offset
+// from the line numbers in their headers, but their context must match
+// exactly. It is an error if a hunk cannot be applied.
+func Patch(s, patch string) (string, error) {
+	lines := splitLines(s)
+	p := splitLines(patch)
+	w := &strings.Builder{}
+
+	pos := 0 // the first line of s that is not yet written
+	for i := 0; i < len(p); {
+		if !strings.HasPrefix(p[i], "@@ ") {
+			i++
+			continue
+		}
+		h, err := parseHunkHeader(p[i])
+		if err != nil {
+			return "", err
+		}
+		header := strings.TrimSpace(p[i])
+		i++
+
+		var old, new []string
+		for len(old) < h.oldLen || len(new) < h.newLen {
+			if i == len(p) {
+				return "", fmt.Errorf("hunk %s: unexpected end of patch", header)
+			}
+			line := p[i]
+			i++
+			if line == "\n" {
+				line = " \n" // some tools strip trailing space
+			}
+			text := line[1:]
+			if !strings.HasSuffix(text, "\n") {
+				text += "\n"
+			}
+			switch line[0] {
+			case ' ':
+				old = append(old, text)
+				new = append(new, text)
+			case '-':
+				old = append(old, text)
+			case '+':
+				new = append(new, text)
+			default:
+				return "", fmt.Errorf("hunk %s: invalid line %q", header, strings.TrimSuffix(line, "\n"))
+			}
+			if i < len(p) && strings.HasPrefix(p[i], `\`) {
+				// No newline at end of file.
+				i++
+				trimLast := func(a []string) {
+					a[len(a)-1] = strings.TrimSuffix(a[len(a)-1], "\n")
+				}
+				switch line[0] {
+				case ' ':
+					trimLast(old)
+					trimLast(new)
+				case '-':
+					trimLast(old)
+				case '+':
+					trimLast(new)
+				}
+			}
+		}
+
+		start := h.oldStart - 1
+		if h.oldLen == 0 {
+			start++
+		}
+		at, ok := find(lines, old, start, pos)
+		if !ok {
+			return "", fmt.Errorf("hunk %s does not apply", header)
+		}
+		for _, l := range lines[pos:at] {
+			w.WriteString(l)
+		}
+		for _, l := range new {
+			w.WriteString(l)
+		}
+		pos = at + len(old)
+	}
+	for _, l := range lines[pos:] {
+		w.WriteString(l)
+	}
+	return w.String(), nil
+}
+
+type hunkHeader struct {
+	oldStart, oldLen int
+	newStart, newLen int
+}
+
+func parseHunkHeader(line string) (h hunkHeader, err error) {
+	f := strings.Fields(line)
+	if len(f) < 4 || f[3] != "@@" ||
+		!strings.HasPrefix(f[1], "-") || !strings.HasPrefix(f[2], "+") {
+		return h, fmt.Errorf("invalid hunk header %q", strings.TrimSpace(line))
+	}
+	parse := func(s string) (start, n int, err error) {
+		n = 1
+		if i := strings.IndexByte(s, ','); i >= 0 {
+			if n, err = strconv.Atoi(s[i+1:]); err != nil {
+				return 0, 0, err
+			}
+			s = s[:i]
+		}
+		start, err = strconv.Atoi(s)
+		return start, n, err
+	}
+	if h.oldStart, h.oldLen, err = parse(f[1][1:]); err == nil {
+		h.newStart, h.newLen, err = parse(f[2][1:])
+	}
+	if err != nil {
+		return h, fmt.Errorf("invalid hunk header %q", strings.TrimSpace(line))
+	}
+	return h, nil
+}
+
+// find returns the position closest to start, but not before min, at which
+// lines contains the sequence want.
+func find(lines, want []string, start, min int) (int, bool) {
+	matches := func(i int) bool {
+		if i < min || i+len(want) > len(lines) {
+			return false
+		}
+		for j, w := range want {
+			if lines[i+j] != w {
+				return false
+			}
+		}
+		return true
+	}
+	for d := 0; start-d >= min || start+d <= len(lines); d++ {
+		if matches(start - d) {
+			return start - d, true
+		}
+		if matches(start + d) {
+			return start + d, true
+		}
+	}
+	return 0, false
+}
+
+// splitLines splits s into lines that include their terminating newline.
+// Only the last line may lack one.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+type edit struct {
+	op   byte // ' ', '-', or '+'
+	line string
+}
+
+// shortestEdits computes the shortest sequence of edits that transforms a
+// into b, using the algorithm of Myers, "An O(ND) Difference Algorithm and
+// Its Variations", 1986.
+func shortestEdits(a, b []string) []edit {
+	n, m := len(a), len(b)
+	max := n + m
+	// v[max+k] holds the furthest x reached on diagonal k.
+	v := make([]int, 2*max+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[max+k-1] < v[max+k+1]) {
+				x = v[max+k+1]
+			} else {
+				x = v[max+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[max+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var edits []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[max+k-1] < v[max+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[max+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			edits = append(edits, edit{' ', a[x]})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			y--
+			edits = append(edits, edit{'+', b[y]})
+		} else {
+			x--
+			edits = append(edits, edit{'-', a[x]})
+		}
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
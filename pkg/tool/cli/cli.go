@@ -19,9 +19,15 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
 	"strings"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/internal/task"
 )
 
@@ -56,30 +62,189 @@ func newAskCmd(v cue.Value) (task.Runner, error) {
 
 func (c *askCmd) Run(ctx *task.Context) (res interface{}, err error) {
 	str := ctx.String("prompt")
+	secret, _ := ctx.Lookup("secret").Bool()
+	confirm, _ := ctx.Lookup("confirm").Bool()
 	if ctx.Err != nil {
 		return nil, ctx.Err
 	}
-	if str != "" {
-		fmt.Fprint(ctx.Stdout, str+" ")
+
+	v := ctx.Obj.LookupPath(cue.MakePath(cue.Str("response")))
+	options := choices(v)
+	for i, o := range options {
+		fmt.Fprintf(ctx.Stdout, "  %d) %s\n", i+1, label(o))
+	}
+
+	for {
+		answer, err := ask(ctx, str, secret)
+		if err != nil {
+			return nil, err
+		}
+		x, err := parseResponse(v, options, answer)
+		if err == nil && confirm {
+			var again string
+			if again, err = ask(ctx, "Confirm:", secret); err != nil {
+				return nil, err
+			}
+			if again != answer {
+				err = errors.New("responses do not match")
+			}
+		}
+		if err == nil {
+			return map[string]interface{}{"response": x}, nil
+		}
+		fmt.Fprintf(ctx.Stdout, "invalid response: %v\n", err)
+	}
+}
+
+// ask prints prompt and reads a line of input.
+func ask(ctx *task.Context, prompt string, secret bool) (string, error) {
+	if prompt != "" {
+		fmt.Fprint(ctx.Stdout, prompt+" ")
+	}
+	if secret {
+		setEcho(ctx.Stdin, false)
+		defer func() {
+			setEcho(ctx.Stdin, true)
+			fmt.Fprintln(ctx.Stdout)
+		}()
+	}
+	return readLine(ctx.Stdin)
+}
+
+// readLine reads a line from r without reading beyond it, so that r can be
+// read again by subsequent prompts.
+func readLine(r io.Reader) (string, error) {
+	var b strings.Builder
+	var buf [1]byte
+	for {
+		n, err := r.Read(buf[:])
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			b.WriteByte(buf[0])
+		}
+		if err == io.EOF && b.Len() > 0 {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// setEcho turns echoing of input on or off if r is a terminal.
+func setEcho(r io.Reader, on bool) {
+	f, ok := r.(*os.File)
+	if !ok || runtime.GOOS == "windows" {
+		return
+	}
+	if fi, err := f.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return
+	}
+	arg := "-echo"
+	if on {
+		arg = "echo"
 	}
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = f
+	_ = cmd.Run()
+}
+
+// choices reports the values of v if it is a disjunction of concrete values.
+func choices(v cue.Value) []cue.Value {
+	switch op, args := v.Expr(); op {
+	case cue.OrOp:
+		for _, a := range args {
+			if !a.IsConcrete() {
+				return nil
+			}
+		}
+		return args
 
-	var response string
-	if _, err := fmt.Scan(&response); err != nil {
-		return nil, err
+	case cue.AndOp:
+		for _, a := range args {
+			if c := choices(a); c != nil {
+				return c
+			}
+		}
 	}
+	return nil
+}
 
-	update := map[string]interface{}{"response": response}
+func label(v cue.Value) string {
+	if s, err := v.String(); err == nil {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// parseResponse interprets answer according to the type of v and checks it
+// against the constraints of v.
+func parseResponse(v cue.Value, options []cue.Value, answer string) (x interface{}, err error) {
+	switch d, ok := v.Default(); {
+	case answer == "" && ok && d.IsConcrete():
+		err = d.Decode(&x)
+		return x, err
 
-	switch v := ctx.Lookup("response"); v.IncompleteKind() {
-	case cue.BoolKind:
-		switch strings.ToLower(response) {
-		case "yes":
-			update["response"] = true
+	case len(options) > 0:
+		if i, err := strconv.Atoi(answer); err == nil && 0 < i && i <= len(options) {
+			answer = label(options[i-1])
+		}
+		labels := make([]string, len(options))
+		for i, o := range options {
+			if labels[i] = label(o); labels[i] == answer {
+				err = o.Decode(&x)
+				return x, err
+			}
+		}
+		return nil, fmt.Errorf("must be one of %s", strings.Join(labels, ", "))
+	}
+
+	switch k := v.IncompleteKind(); {
+	case k == cue.BoolKind:
+		switch strings.ToLower(answer) {
+		case "yes", "y", "true":
+			x = true
+		case "no", "n", "false":
+			x = false
 		default:
-			update["response"] = false
+			return nil, errors.New("answer yes or no")
+		}
+
+	case k&cue.NumberKind != 0 && k&cue.StringKind == 0:
+		if i, err := strconv.ParseInt(answer, 10, 64); err == nil {
+			x = i
+		} else if f, err := strconv.ParseFloat(answer, 64); err == nil {
+			x = f
+		} else {
+			return nil, fmt.Errorf("%q is not a number", answer)
+		}
+
+	default:
+		x = answer
+	}
+
+	w := v.Unify(v.Context().Encode(x))
+	if err := w.Validate(cue.Concrete(true)); err != nil {
+		return nil, reason(err)
+	}
+	return x, nil
+}
+
+// reason reports the error of err that explains why a response is invalid.
+// As responses are constrained by a disjunction of types, this skips the
+// errors for the types that do not match the response.
+func reason(err error) error {
+	errs := errors.Errors(err)
+	for _, e := range errs {
+		format, args := e.Msg()
+		if !strings.Contains(format, "mismatched types") &&
+			!strings.Contains(format, "empty disjunction") {
+			return fmt.Errorf(format, args...)
 		}
-	case cue.StringKind:
-		// already set above
 	}
-	return update, nil
+	format, args := errs[0].Msg()
+	return fmt.Errorf(format, args...)
 }
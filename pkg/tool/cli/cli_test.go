@@ -0,0 +1,150 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/internal/task"
+	"cuelang.org/go/internal/value"
+)
+
+func parse(t *testing.T, kind, expr string) cue.Value {
+	t.Helper()
+
+	x, err := parser.ParseExpr("test", expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r cue.Runtime
+	i, err := r.CompileExpr(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value.UnifyBuiltin(i.Value(), kind)
+}
+
+func TestAsk(t *testing.T) {
+	testCases := []struct {
+		name     string
+		task     string
+		input    string
+		response interface{}
+		out      string
+	}{{
+		name:     "string",
+		task:     `{prompt: "Name?", response: string}`,
+		input:    "  Jane Doe \nnext\n",
+		response: "Jane Doe",
+		out:      "Name? ",
+	}, {
+		name:     "bool",
+		task:     `{prompt: "Okay?", response: bool}`,
+		input:    "maybe\nY\n",
+		response: true,
+		out:      "Okay? invalid response: answer yes or no\nOkay? ",
+	}, {
+		name:     "number",
+		task:     `{prompt: "Replicas?", response: int & >0}`,
+		input:    "two\n0\n3",
+		response: int64(3),
+		out: `Replicas? invalid response: "two" is not a number
+Replicas? invalid response: invalid value 0 (out of bound >0)
+Replicas? `,
+	}, {
+		name:     "constraint",
+		task:     `{prompt: "Name?", response: =~"^[a-z]+$"}`,
+		input:    "Foo\nfoo\n",
+		response: "foo",
+		out: `Name? invalid response: invalid value "Foo" (out of bound =~"^[a-z]+$")
+Name? `,
+	}, {
+		name:     "select by number",
+		task:     `{prompt: "Environment?", response: "dev" | "prod"}`,
+		input:    "3\n2\n",
+		response: "prod",
+		out: `  1) dev
+  2) prod
+Environment? invalid response: must be one of dev, prod
+Environment? `,
+	}, {
+		name:     "select by value",
+		task:     `{prompt: "Size?", response: 1 | 2 | 4}`,
+		input:    "4\n",
+		response: 4,
+		out:      "  1) 1\n  2) 2\n  3) 4\nSize? ",
+	}, {
+		name:     "default",
+		task:     `{prompt: "Environment?", response: *"dev" | "prod"}`,
+		input:    "\n",
+		response: "dev",
+		out:      "  1) dev\n  2) prod\nEnvironment? ",
+	}, {
+		name: "confirm",
+		task: `{
+			prompt:   "Password?"
+			response: string
+			secret:   true
+			confirm:  true
+		}`,
+		input:    "secret\nsecrte\nsecret\nsecret\n",
+		response: "secret",
+		out: "Password? \nConfirm: \n" +
+			"invalid response: responses do not match\n" +
+			"Password? \nConfirm: \n",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := parse(t, "tool/cli.Ask", tc.task)
+			r, err := newAskCmd(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := &strings.Builder{}
+			res, err := r.Run(&task.Context{
+				Stdin:  strings.NewReader(tc.input),
+				Stdout: out,
+				Obj:    v,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := res.(map[string]interface{})["response"]
+			if fmt.Sprintf("%T %v", got, got) != fmt.Sprintf("%T %v", tc.response, tc.response) {
+				t.Errorf("got response %T %v; want %T %v", got, got, tc.response, tc.response)
+			}
+			if out.String() != tc.out {
+				t.Errorf("got output\n%s\nwant\n%s", out, tc.out)
+			}
+		})
+	}
+}
+
+func TestAskEOF(t *testing.T) {
+	v := parse(t, "tool/cli.Ask", `{prompt: "Name?", response: string}`)
+	r, _ := newAskCmd(v)
+	_, err := r.Run(&task.Context{
+		Stdin:  strings.NewReader(""),
+		Stdout: &strings.Builder{},
+		Obj:    v,
+	})
+	if err == nil {
+		t.Error("expected error")
+	}
+}
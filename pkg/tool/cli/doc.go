@@ -14,11 +14,26 @@
 //
 //     // Ask prompts the current console with a message and waits for input.
 //     //
+//     // The response is validated against the constraints of the response field.
+//     // If it is not valid, the reason is reported and the prompt is repeated.
+//     //
 //     // Example:
 //     //     task: ask: cli.Ask({
 //     //         prompt:   "Are you okay?"
 //     //         repsonse: bool
 //     //     })
+//     //
+//     //     task: env: cli.Ask & {
+//     //         prompt:   "Environment:"
+//     //         response: *"dev" | "staging" | "prod"
+//     //     }
+//     //
+//     //     task: password: cli.Ask & {
+//     //         prompt:   "New password:"
+//     //         response: strings.MinRunes(8)
+//     //         secret:   true
+//     //         confirm:  true
+//     //     }
 //     Ask: {
 //     	kind: "tool/cli.Ask"
 //
@@ -26,8 +41,18 @@
 //     	prompt: string
 //
 //     	// response holds the user's response. If it is a boolean expression it
-//     	// will interpret the answer using textual yes/ no.
-//     	response: string | bool
+//     	// will interpret the answer using textual yes/ no. If it is a number,
+//     	// the answer must be a number. If it is a disjunction of concrete
+//     	// values, these are listed as options, which may be selected by value
+//     	// or by number. An empty answer selects the default value, if any.
+//     	response: string | bool | number
+//
+//     	// secret indicates that the answer is not echoed to the terminal, as
+//     	// is appropriate for passwords.
+//     	secret: *false | bool
+//
+//     	// confirm indicates that the answer must be entered twice.
+//     	confirm: *false | bool
 //     }
 //
 package cli
@@ -26,7 +26,9 @@ var pkg = &internal.Package{
 	Ask: {
 		kind:     "tool/cli.Ask"
 		prompt:   string
-		response: string | bool
+		response: string | bool | number
+		secret:   *false | bool
+		confirm:  *false | bool
 	}
 }`,
 }
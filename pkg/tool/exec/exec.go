@@ -20,6 +20,7 @@ package exec
 import (
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"cuelang.org/go/cue"
@@ -137,7 +138,9 @@ func mkCommand(ctx *task.Context) (c *exec.Cmd, doc string, err error) {
 
 	cmd := exec.CommandContext(ctx.Context, bin, args...)
 
-	cmd.Dir, _ = ctx.Obj.Lookup("dir").String()
+	if dir, err := ctx.Obj.Lookup("dir").String(); err == nil {
+		cmd.Dir = filepath.FromSlash(dir)
+	}
 
 	env := ctx.Obj.Lookup("env")
 
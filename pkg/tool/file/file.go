@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 
 	"cuelang.org/go/cue"
+	"cuelang.org/go/internal"
 	"cuelang.org/go/internal/task"
 )
 
@@ -44,12 +45,12 @@ type cmdCreate struct{}
 type cmdGlob struct{}
 
 func (c *cmdRead) Run(ctx *task.Context) (res interface{}, err error) {
-	filename := ctx.String("filename")
+	filename := filepath.FromSlash(ctx.String("filename"))
 	if ctx.Err != nil {
 		return nil, ctx.Err
 	}
 
-	b, err := ioutil.ReadFile(filename)
+	b, err := ioutil.ReadFile(internal.LongPath(filename))
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +75,7 @@ func (c *cmdAppend) Run(ctx *task.Context) (res interface{}, err error) {
 		return nil, ctx.Err
 	}
 
-	f, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.FileMode(mode))
+	f, err := os.OpenFile(internal.LongPath(filename), os.O_CREATE|os.O_APPEND|os.O_WRONLY, os.FileMode(mode))
 	if err != nil {
 		return nil, err
 	}
@@ -96,7 +97,7 @@ func (c *cmdCreate) Run(ctx *task.Context) (res interface{}, err error) {
 		return nil, ctx.Err
 	}
 
-	return nil, ioutil.WriteFile(filename, b, os.FileMode(mode))
+	return nil, ioutil.WriteFile(internal.LongPath(filename), b, os.FileMode(mode))
 }
 
 func (c *cmdGlob) Run(ctx *task.Context) (res interface{}, err error) {
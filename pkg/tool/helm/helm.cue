@@ -0,0 +1,36 @@
+// Copyright 2021 The CUE Authors
+// 
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// 
+//     http://www.apache.org/licenses/LICENSE-2.0
+// 
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+// Values computes the values of a Helm chart. The default values in the
+// values.yaml file of the chart are combined with the given values as Helm
+// does: structs are merged, null removes a value, and all other values
+// replace the default. The result is validated against schema.
+Values: {
+	$id: "tool/helm.Values"
+
+	// chart is the directory of the chart. A chart without a values.yaml
+	// file has no default values.
+	chart: string
+
+	// values holds the values that override the defaults of the chart.
+	values: {...}
+
+	// schema is the CUE schema that the computed values must satisfy.
+	schema: _
+
+	// result holds the computed values.
+	result: {...}
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+//go:generate go run gen.go
+//go:generate gofmt -s -w .
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/task"
+	"cuelang.org/go/internal/third_party/yaml"
+)
+
+func init() {
+	task.Register("tool/helm.Values", newValuesCmd)
+}
+
+func newValuesCmd(v cue.Value) (task.Runner, error) { return &cmdValues{}, nil }
+
+type cmdValues struct{}
+
+func (c *cmdValues) Run(ctx *task.Context) (res interface{}, err error) {
+	chart := ctx.String("chart")
+	if ctx.Err != nil {
+		return nil, ctx.Err
+	}
+
+	defaults, err := readValues(filepath.Join(chart, "values.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	v := ctx.Lookup("values")
+	if err := v.Decode(&values); err != nil {
+		return nil, errors.Wrapf(err, v.Pos(), "invalid values")
+	}
+	result := mergeValues(defaults, values)
+
+	schema := ctx.Lookup("schema")
+	if err := schema.Fill(result).Validate(cue.Concrete(true)); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"result": result}, nil
+}
+
+// readValues reads the values in the given YAML file. A missing file has
+// no values.
+func readValues(filename string) (map[string]interface{}, error) {
+	b, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	expr, err := yaml.Unmarshal(filename, b)
+	if err != nil {
+		return nil, err
+	}
+	var r cue.Runtime
+	inst, err := r.CompileExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := inst.Value().Decode(&values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// mergeValues returns the result of overlaying override onto base, as Helm
+// does when combining the default values of a chart with user-supplied
+// values. Maps are merged recursively, a null value removes the
+// corresponding key, and all other values in override replace those in
+// base. Neither argument is modified.
+func mergeValues(base, override map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(base))
+	for k, v := range base {
+		result[k] = v
+	}
+	for k, v := range override {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+		src, ok1 := v.(map[string]interface{})
+		dst, ok2 := result[k].(map[string]interface{})
+		if ok1 && ok2 {
+			result[k] = mergeValues(dst, src)
+		} else {
+			result[k] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package helm
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/internal/task"
+	"cuelang.org/go/internal/value"
+)
+
+func parse(t *testing.T, kind, expr string) cue.Value {
+	t.Helper()
+
+	x, err := parser.ParseExpr("test", expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r cue.Runtime
+	i, err := r.CompileExpr(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value.UnifyBuiltin(i.Value(), kind)
+}
+
+func TestValues(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		out  string
+	}{{
+		name: "defaults",
+		in:   `{chart: "testdata/chart"}`,
+		out:  `{"result":{"debug":true,"image":{"repository":"nginx","tag":"stable"},"replicas":1}}`,
+	}, {
+		name: "merge",
+		in: `{
+			chart: "testdata/chart"
+			values: {image: tag: "1.21", debug: null}
+		}`,
+		out: `{"result":{"image":{"repository":"nginx","tag":"1.21"},"replicas":1}}`,
+	}, {
+		name: "missing chart values",
+		in:   `{chart: "testdata", values: a: 1}`,
+		out:  `{"result":{"a":1}}`,
+	}, {
+		name: "schema",
+		in: `{
+			chart: "testdata/chart"
+			values: replicas: 20
+			schema: {
+				replicas: <=10
+				image: {repository: string, tag: string}
+				debug: bool
+			}
+		}`,
+		out: `schema.replicas: invalid value 20 (out of bound <=10)`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := parse(t, "tool/helm.Values", tc.in)
+			res, err := (*cmdValues).Run(nil, &task.Context{Obj: v})
+			var got string
+			if err != nil {
+				got = strings.TrimSpace(errors.Details(err, nil))
+				got = strings.SplitN(got, ":\n", 2)[0]
+			} else {
+				b, err := json.Marshal(res)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = string(b)
+			}
+			if got != tc.out {
+				t.Errorf("\ngot:  %s\nwant: %s", got, tc.out)
+			}
+		})
+	}
+}
+
+func TestMergeValues(t *testing.T) {
+	base := map[string]interface{}{
+		"replicas": 1,
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "stable",
+		},
+		"ports":  []interface{}{80},
+		"debug":  true,
+		"labels": map[string]interface{}{"app": "web"},
+	}
+	override := map[string]interface{}{
+		"image":  map[string]interface{}{"tag": "1.21"},
+		"ports":  []interface{}{8080},
+		"debug":  nil,
+		"labels": "none",
+	}
+	want := map[string]interface{}{
+		"replicas": 1,
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.21",
+		},
+		"ports":  []interface{}{8080},
+		"labels": "none",
+	}
+	got := mergeValues(base, override)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+	if base["image"].(map[string]interface{})["tag"] != "stable" {
+		t.Error("base was modified")
+	}
+}
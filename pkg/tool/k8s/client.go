@@ -0,0 +1,313 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/internal/task"
+	"cuelang.org/go/internal/third_party/yaml"
+)
+
+// A client is a minimal client of the Kubernetes API.
+type client struct {
+	server    string
+	token     string
+	namespace string
+	http      *http.Client
+
+	// resources caches the resources of each group version.
+	resources map[string][]apiResource
+}
+
+type apiResource struct {
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	Namespaced bool   `json:"namespaced"`
+}
+
+// path returns the path of the object with the given name.
+func (r *apiResource) path(apiVersion, namespace, name string) string {
+	p := groupVersionPrefix(apiVersion)
+	if r.Namespaced {
+		p += "/namespaces/" + url.PathEscape(namespace)
+	}
+	return p + "/" + r.Name + "/" + url.PathEscape(name)
+}
+
+// kubeconfig holds the parts of a kubeconfig file used by client.
+type kubeconfig struct {
+	CurrentContext string `json:"current-context"`
+	Clusters       []struct {
+		Name    string `json:"name"`
+		Cluster struct {
+			Server                   string `json:"server"`
+			CertificateAuthority     string `json:"certificate-authority"`
+			CertificateAuthorityData string `json:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `json:"insecure-skip-tls-verify"`
+		} `json:"cluster"`
+	} `json:"clusters"`
+	Contexts []struct {
+		Name    string `json:"name"`
+		Context struct {
+			Cluster   string `json:"cluster"`
+			User      string `json:"user"`
+			Namespace string `json:"namespace"`
+		} `json:"context"`
+	} `json:"contexts"`
+	Users []struct {
+		Name string `json:"name"`
+		User struct {
+			Token                 string `json:"token"`
+			TokenFile             string `json:"tokenFile"`
+			ClientCertificate     string `json:"client-certificate"`
+			ClientCertificateData string `json:"client-certificate-data"`
+			ClientKey             string `json:"client-key"`
+			ClientKeyData         string `json:"client-key-data"`
+		} `json:"user"`
+	} `json:"users"`
+}
+
+// newClient returns a client for the cluster of the given context of the
+// kubeconfig file. Empty arguments select the defaults used by kubectl.
+func newClient(ctx *task.Context, filename, context string) (*client, error) {
+	if filename == "" {
+		filename = defaultKubeconfig()
+	}
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := yaml.Unmarshal(filename, b)
+	if err != nil {
+		return nil, err
+	}
+	var r cue.Runtime
+	inst, err := r.CompileExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	var cfg kubeconfig
+	if err := inst.Value().Decode(&cfg); err != nil {
+		return nil, err
+	}
+	// Relative paths are relative to the kubeconfig file.
+	dir := filepath.Dir(filename)
+	readFile := func(name, data string) ([]byte, error) {
+		if data != "" {
+			return base64.StdEncoding.DecodeString(data)
+		}
+		if name == "" {
+			return nil, nil
+		}
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(dir, name)
+		}
+		return ioutil.ReadFile(name)
+	}
+
+	if context == "" {
+		context = cfg.CurrentContext
+	}
+	c := &client{resources: map[string][]apiResource{}}
+	tlsConfig := &tls.Config{}
+	found := false
+	for _, x := range cfg.Contexts {
+		if x.Name != context {
+			continue
+		}
+		found = true
+		c.namespace = x.Context.Namespace
+
+		for _, cl := range cfg.Clusters {
+			if cl.Name != x.Context.Cluster {
+				continue
+			}
+			c.server = strings.TrimSuffix(cl.Cluster.Server, "/")
+			tlsConfig.InsecureSkipVerify = cl.Cluster.InsecureSkipTLSVerify
+			ca, err := readFile(cl.Cluster.CertificateAuthority, cl.Cluster.CertificateAuthorityData)
+			if err != nil {
+				return nil, err
+			}
+			if ca != nil {
+				tlsConfig.RootCAs = x509.NewCertPool()
+				if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+					return nil, fmt.Errorf("invalid certificate authority for cluster %q", cl.Name)
+				}
+			}
+		}
+
+		for _, u := range cfg.Users {
+			if u.Name != x.Context.User {
+				continue
+			}
+			c.token = u.User.Token
+			if token, err := readFile(u.User.TokenFile, ""); err != nil {
+				return nil, err
+			} else if token != nil {
+				c.token = strings.TrimSpace(string(token))
+			}
+			cert, err := readFile(u.User.ClientCertificate, u.User.ClientCertificateData)
+			if err != nil {
+				return nil, err
+			}
+			key, err := readFile(u.User.ClientKey, u.User.ClientKeyData)
+			if err != nil {
+				return nil, err
+			}
+			if cert != nil {
+				pair, err := tls.X509KeyPair(cert, key)
+				if err != nil {
+					return nil, err
+				}
+				tlsConfig.Certificates = []tls.Certificate{pair}
+			}
+		}
+	}
+	switch {
+	case !found:
+		return nil, fmt.Errorf("context %q not found in %s", context, filename)
+	case c.server == "":
+		return nil, fmt.Errorf("no server defined for context %q in %s", context, filename)
+	}
+	if c.namespace == "" {
+		c.namespace = "default"
+	}
+	c.http = &http.Client{
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}
+	return c, nil
+}
+
+// defaultKubeconfig returns the kubeconfig file used by kubectl by default.
+func defaultKubeconfig() string {
+	if list := os.Getenv("KUBECONFIG"); list != "" {
+		return filepath.SplitList(list)[0]
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".kube", "config")
+}
+
+// resource returns the API resource for the given kind.
+func (c *client) resource(ctx *task.Context, apiVersion, kind string) (*apiResource, error) {
+	list, ok := c.resources[apiVersion]
+	if !ok {
+		var x struct {
+			Resources []apiResource `json:"resources"`
+		}
+		if _, err := c.do(ctx, "GET", groupVersionPrefix(apiVersion), nil, "", &x); err != nil {
+			return nil, err
+		}
+		list = x.Resources
+		c.resources[apiVersion] = list
+	}
+	for i, r := range list {
+		// Skip subresources, like deployments/status.
+		if r.Kind == kind && !strings.Contains(r.Name, "/") {
+			return &list[i], nil
+		}
+	}
+	return nil, fmt.Errorf("unknown kind %s in %s", kind, apiVersion)
+}
+
+// get returns the live object at the given path, or nil if it does not
+// exist.
+func (c *client) get(ctx *task.Context, path string) (object, error) {
+	var obj object
+	status, err := c.do(ctx, "GET", path, nil, "", &obj)
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	return obj, err
+}
+
+// apply applies obj at the given path and returns the resulting object.
+func (c *client) apply(ctx *task.Context, path string, obj object, opts applyOptions) (object, error) {
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	q := url.Values{}
+	q.Set("fieldManager", opts.fieldManager)
+	if opts.force {
+		q.Set("force", "true")
+	}
+	if opts.dryRun {
+		q.Set("dryRun", "All")
+	}
+	var result object
+	_, err = c.do(ctx, "PATCH", path+"?"+q.Encode(), body, "application/apply-patch+yaml", &result)
+	return result, err
+}
+
+// do sends a request to the API server and decodes the response into
+// result. It returns the status code of the response and an error for any
+// unsuccessful status.
+func (c *client) do(ctx *task.Context, method, path string, body []byte, contentType string, result interface{}) (int, error) {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, c.server+path, r)
+	if err != nil {
+		return 0, err
+	}
+	if ctx.Context != nil {
+		req = req.WithContext(ctx.Context)
+	}
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if resp.StatusCode/100 != 2 {
+		// Report the message of the returned Status object, if any.
+		var status struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(b, &status) != nil || status.Message == "" {
+			status.Message = resp.Status
+		}
+		return resp.StatusCode, fmt.Errorf("%s %s: %s", method, req.URL.Path, status.Message)
+	}
+	return resp.StatusCode, json.Unmarshal(b, result)
+}
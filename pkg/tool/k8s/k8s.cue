@@ -0,0 +1,87 @@
+// Copyright 2021 The CUE Authors
+// 
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// 
+//     http://www.apache.org/licenses/LICENSE-2.0
+// 
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+// Apply applies objects to a Kubernetes cluster using server-side apply.
+// The objects are applied in order with the given field manager, which
+// then owns the fields set in the objects. Objects of a namespaced kind
+// without a namespace are applied to the namespace of the task, if set, or
+// otherwise that of the kubeconfig context.
+Apply: {
+	$id: "tool/k8s.Apply"
+
+	// objects holds the manifests to apply. Each object must define
+	// apiVersion, kind, and metadata.name.
+	objects: [...{...}]
+
+	// kubeconfig is the kubeconfig file that describes the cluster. It
+	// defaults to the first file in $KUBECONFIG or ~/.kube/config.
+	kubeconfig: *"" | string
+
+	// context selects the context of the kubeconfig file. It defaults to
+	// its current context.
+	context: *"" | string
+
+	// namespace is the namespace of namespaced objects that do not
+	// specify one.
+	namespace: *"" | string
+
+	// fieldManager is the name of the manager that owns the applied fields.
+	fieldManager: *"cue" | string
+
+	// force takes ownership of fields owned by other managers instead of
+	// reporting a conflict.
+	force: *false | bool
+
+	// dryRun computes the results without persisting any changes.
+	dryRun: *false | bool
+
+	// results reports the outcome for each object, in order.
+	results: [...Result]
+}
+
+// Diff reports the changes that Apply would make to a cluster, without
+// changing it. It uses a server-side dry run, so that defaults, admission
+// controllers, and the fields owned by other managers are accounted for.
+Diff: {
+	$id: "tool/k8s.Diff"
+
+	objects: [...{...}]
+
+	kubeconfig:   *"" | string
+	context:      *"" | string
+	namespace:    *"" | string
+	fieldManager: *"cue" | string
+	force:        *false | bool
+
+	// results reports the outcome for each object, in order, including the
+	// differences between the live and the resulting object.
+	results: [...Result]
+}
+
+// Result describes the outcome of applying an object.
+Result: {
+	apiVersion: string
+	kind:       string
+	namespace?: string
+	name:       string
+
+	// action is created if the object did not exist, configured if it
+	// changed, and unchanged otherwise.
+	action: "created" | "configured" | "unchanged"
+
+	// diff describes the changes to the object for Diff.
+	diff?: string
+}
@@ -0,0 +1,188 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+//go:generate go run gen.go
+//go:generate gofmt -s -w .
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/diff"
+	"cuelang.org/go/internal/task"
+)
+
+func init() {
+	task.Register("tool/k8s.Apply", newApplyCmd)
+	task.Register("tool/k8s.Diff", newDiffCmd)
+}
+
+func newApplyCmd(v cue.Value) (task.Runner, error) { return &cmdApply{}, nil }
+func newDiffCmd(v cue.Value) (task.Runner, error)  { return &cmdApply{diff: true}, nil }
+
+// cmdApply implements both Apply and Diff, as a diff is a dry run of an
+// apply that reports the changes.
+type cmdApply struct {
+	diff bool
+}
+
+type object = map[string]interface{}
+
+func (c *cmdApply) Run(ctx *task.Context) (res interface{}, err error) {
+	var (
+		kubeconfig   = ctx.String("kubeconfig")
+		context      = ctx.String("context")
+		namespace    = ctx.String("namespace")
+		fieldManager = ctx.String("fieldManager")
+	)
+	if ctx.Err != nil {
+		return nil, ctx.Err
+	}
+	force, err := ctx.Lookup("force").Bool()
+	if err != nil {
+		return nil, err
+	}
+	dryRun := c.diff
+	if !c.diff {
+		if dryRun, err = ctx.Lookup("dryRun").Bool(); err != nil {
+			return nil, err
+		}
+	}
+
+	objs := ctx.Lookup("objects")
+	var objects []object
+	if err := objs.Decode(&objects); err != nil {
+		return nil, errors.Wrapf(err, objs.Pos(), "invalid objects")
+	}
+
+	cl, err := newClient(ctx, kubeconfig, context)
+	if err != nil {
+		return nil, err
+	}
+	if namespace == "" {
+		namespace = cl.namespace
+	}
+
+	results := []interface{}{}
+	for i, obj := range objects {
+		r, err := c.apply(ctx, cl, obj, applyOptions{
+			namespace:    namespace,
+			fieldManager: fieldManager,
+			force:        force,
+			dryRun:       dryRun,
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, objs.Pos(), "objects[%d]", i)
+		}
+		results = append(results, r)
+	}
+	return map[string]interface{}{"results": results}, nil
+}
+
+type applyOptions struct {
+	namespace    string
+	fieldManager string
+	force        bool
+	dryRun       bool
+}
+
+// apply applies a single object and reports the result.
+func (c *cmdApply) apply(ctx *task.Context, cl *client, obj object, opts applyOptions) (object, error) {
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+	meta, _ := obj["metadata"].(map[string]interface{})
+	name, _ := meta["name"].(string)
+	if apiVersion == "" || kind == "" || name == "" {
+		return nil, fmt.Errorf("object must define apiVersion, kind, and metadata.name")
+	}
+
+	res, err := cl.resource(ctx, apiVersion, kind)
+	if err != nil {
+		return nil, err
+	}
+	result := object{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"name":       name,
+	}
+	ns := ""
+	if res.Namespaced {
+		ns, _ = meta["namespace"].(string)
+		if ns == "" {
+			ns = opts.namespace
+			meta["namespace"] = ns
+		}
+		result["namespace"] = ns
+	}
+	path := res.path(apiVersion, ns, name)
+
+	live, err := cl.get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := cl.apply(ctx, path, obj, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	normalize(live)
+	normalize(applied)
+	switch {
+	case live == nil:
+		result["action"] = "created"
+	case reflect.DeepEqual(live, applied):
+		result["action"] = "unchanged"
+	default:
+		result["action"] = "configured"
+	}
+
+	if c.diff {
+		if live == nil {
+			live = object{}
+		}
+		cc := ctx.Obj.Context()
+		_, es := diff.Diff(cc.Encode(live), cc.Encode(applied))
+		buf := &bytes.Buffer{}
+		if es != nil && result["action"] != "unchanged" {
+			if err := diff.Print(buf, es); err != nil {
+				return nil, err
+			}
+		}
+		result["diff"] = buf.String()
+	}
+	return result, nil
+}
+
+// normalize removes the fields of obj that change with every update and are
+// not part of its configuration.
+func normalize(obj object) {
+	meta, _ := obj["metadata"].(map[string]interface{})
+	for _, f := range []string{"managedFields", "resourceVersion", "generation"} {
+		delete(meta, f)
+	}
+}
+
+// groupVersionPrefix returns the API path prefix for the given apiVersion.
+func groupVersionPrefix(apiVersion string) string {
+	if !strings.Contains(apiVersion, "/") {
+		return "/api/" + apiVersion // core group
+	}
+	return "/apis/" + apiVersion
+}
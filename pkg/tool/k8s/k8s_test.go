@@ -0,0 +1,251 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/internal/task"
+	"cuelang.org/go/internal/value"
+)
+
+func parse(t *testing.T, kind, expr string) cue.Value {
+	t.Helper()
+
+	x, err := parser.ParseExpr("test", expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r cue.Runtime
+	i, err := r.CompileExpr(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value.UnifyBuiltin(i.Value(), kind)
+}
+
+// fakeServer implements the parts of the Kubernetes API used by client,
+// applying an object by replacing it.
+type fakeServer struct {
+	t       *testing.T
+	objects map[string]object
+	version int
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+		s.t.Errorf("Authorization: got %q; want %q", got, "Bearer secret")
+	}
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/api/v1":
+		fmt.Fprint(w, `{"resources": [
+			{"name": "configmaps", "kind": "ConfigMap", "namespaced": true},
+			{"name": "namespaces", "kind": "Namespace", "namespaced": false},
+			{"name": "namespaces/status", "kind": "Namespace", "namespaced": false}
+		]}`)
+
+	case r.Method == "GET":
+		obj, ok := s.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"message": "%s not found"}`, r.URL.Path)
+			return
+		}
+		json.NewEncoder(w).Encode(obj)
+
+	case r.Method == "PATCH":
+		if got := r.Header.Get("Content-Type"); got != "application/apply-patch+yaml" {
+			s.t.Errorf("Content-Type: got %q", got)
+		}
+		if got := r.URL.Query().Get("fieldManager"); got != "cue" {
+			s.t.Errorf("fieldManager: got %q", got)
+		}
+		var obj object
+		b, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(b, &obj); err != nil {
+			s.t.Fatal(err)
+		}
+		meta := obj["metadata"].(map[string]interface{})
+		meta["managedFields"] = []interface{}{}
+		if live, ok := s.objects[r.URL.Path]; ok {
+			meta["resourceVersion"] = live["metadata"].(map[string]interface{})["resourceVersion"]
+			if !reflect.DeepEqual(withoutServerFields(live), withoutServerFields(obj)) {
+				meta["resourceVersion"] = strconv.Itoa(s.version + 1)
+			}
+		} else {
+			meta["resourceVersion"] = strconv.Itoa(s.version + 1)
+		}
+		if r.URL.Query().Get("dryRun") != "All" {
+			s.version++
+			s.objects[r.URL.Path] = obj
+		}
+		json.NewEncoder(w).Encode(obj)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func copyObject(obj object) object {
+	b, _ := json.Marshal(obj)
+	var x object
+	json.Unmarshal(b, &x)
+	return x
+}
+
+func withoutServerFields(obj object) object {
+	x := copyObject(obj)
+	normalize(x)
+	return x
+}
+
+func TestApply(t *testing.T) {
+	s := &fakeServer{t: t, objects: map[string]object{}}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "k8s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ioutil.WriteFile(filepath.Join(dir, "token"), []byte("secret\n"), 0644)
+	kubeconfig := filepath.Join(dir, "config")
+	ioutil.WriteFile(kubeconfig, []byte(fmt.Sprintf(`
+apiVersion: v1
+kind: Config
+current-context: test
+contexts:
+- name: test
+  context: {cluster: test, user: test, namespace: prod}
+clusters:
+- name: test
+  cluster: {server: %q}
+users:
+- name: test
+  user: {tokenFile: token}
+`, srv.URL)), 0644)
+
+	objects := func(value string) string {
+		return fmt.Sprintf(`objects: [{
+			apiVersion: "v1"
+			kind:       "Namespace"
+			metadata: name: "prod"
+		}, {
+			apiVersion: "v1"
+			kind:       "ConfigMap"
+			metadata: name: "web"
+			data: level: %q
+		}]`, value)
+	}
+
+	testCases := []struct {
+		name    string
+		kind    string
+		in      string
+		actions string
+		diff    string
+		err     string
+	}{{
+		name:    "diff new",
+		kind:    "Diff",
+		in:      objects("info"),
+		actions: "Namespace/prod created, ConfigMap/prod/web created",
+		diff:    `+     data: {`,
+	}, {
+		name:    "apply new",
+		kind:    "Apply",
+		in:      objects("info"),
+		actions: "Namespace/prod created, ConfigMap/prod/web created",
+	}, {
+		name:    "apply unchanged",
+		kind:    "Apply",
+		in:      objects("info"),
+		actions: "Namespace/prod unchanged, ConfigMap/prod/web unchanged",
+	}, {
+		name:    "diff changed",
+		kind:    "Diff",
+		in:      objects("debug"),
+		actions: "Namespace/prod unchanged, ConfigMap/prod/web configured",
+		diff:    `-         level: "info"` + "\n" + `+         level: "debug"`,
+	}, {
+		name:    "dry run",
+		kind:    "Apply",
+		in:      objects("debug") + "\ndryRun: true",
+		actions: "Namespace/prod unchanged, ConfigMap/prod/web configured",
+	}, {
+		name:    "dry run not persisted",
+		kind:    "Apply",
+		in:      objects("info"),
+		actions: "Namespace/prod unchanged, ConfigMap/prod/web unchanged",
+	}, {
+		name: "unknown kind",
+		kind: "Apply",
+		in:   `objects: [{apiVersion: "v1", kind: "Pod", metadata: name: "x"}]`,
+		err:  "objects[0]: unknown kind Pod in v1",
+	}, {
+		name: "missing name",
+		kind: "Apply",
+		in:   `objects: [{apiVersion: "v1", kind: "Pod"}]`,
+		err:  "objects[0]: object must define apiVersion, kind, and metadata.name",
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			in := fmt.Sprintf("{kubeconfig: %q, %s}", kubeconfig, tc.in)
+			v := parse(t, "tool/k8s."+tc.kind, in)
+			c := &cmdApply{diff: tc.kind == "Diff"}
+			res, err := c.Run(&task.Context{Obj: v})
+			if err != nil || tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("got error %v; want %s", err, tc.err)
+				}
+				return
+			}
+			var actions, diffs []string
+			for _, r := range res.(map[string]interface{})["results"].([]interface{}) {
+				r := r.(object)
+				id := r["kind"].(string) + "/"
+				if ns, ok := r["namespace"]; ok {
+					id += ns.(string) + "/"
+				}
+				actions = append(actions, id+r["name"].(string)+" "+r["action"].(string))
+				if d, ok := r["diff"]; ok {
+					diffs = append(diffs, d.(string))
+				}
+			}
+			if got := strings.Join(actions, ", "); got != tc.actions {
+				t.Errorf("actions:\ngot  %s\nwant %s", got, tc.actions)
+			}
+			if got := strings.Join(diffs, ""); !strings.Contains(got, tc.diff) {
+				t.Errorf("diff:\ngot\n%s\nwant substring\n%s", got, tc.diff)
+			}
+			if tc.kind == "Diff" && len(diffs) != len(actions) {
+				t.Errorf("got %d diffs; want %d", len(diffs), len(actions))
+			}
+		})
+	}
+}
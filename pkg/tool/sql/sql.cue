@@ -0,0 +1,62 @@
+// Copyright 2021 The CUE Authors
+// 
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// 
+//     http://www.apache.org/licenses/LICENSE-2.0
+// 
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+// Query runs a query and returns the resulting rows.
+//
+// Example:
+//     users: sql.Query & {
+//         driver: "postgres"
+//         dsn:    "postgres://localhost/app?sslmode=disable"
+//         query:  "SELECT id, name FROM users WHERE team = $1"
+//         args: ["core"]
+//     }
+Query: {
+	$id: "tool/sql.Query"
+
+	// driver is the name of the database driver. The cue tool provides
+	// the postgres driver.
+	driver: string
+
+	// dsn is the data source name, whose format depends on the driver.
+	dsn: string
+
+	// query is the query to run. It may refer to args with placeholders,
+	// whose syntax depends on the driver, like $1 for postgres.
+	query: string
+
+	// args holds the values of the placeholders of query.
+	args: [...null | bool | number | string | bytes]
+
+	// rows holds the resulting rows, with a field for each column.
+	// Timestamps are converted to RFC 3339 strings and binary columns to
+	// bytes.
+	rows: [...{...}]
+}
+
+// Exec runs a statement that does not return rows, like an INSERT or a
+// CREATE TABLE statement.
+Exec: {
+	$id: "tool/sql.Exec"
+
+	driver: string
+	dsn:    string
+	query:  string
+	args: [...null | bool | number | string | bytes]
+
+	// rowsAffected is the number of rows affected by the statement, if
+	// reported by the driver, or -1 otherwise.
+	rowsAffected: int
+}
@@ -0,0 +1,168 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+//go:generate go run gen.go
+//go:generate gofmt -s -w .
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/task"
+)
+
+func init() {
+	task.Register("tool/sql.Query", newQueryCmd)
+	task.Register("tool/sql.Exec", newExecCmd)
+}
+
+func newQueryCmd(v cue.Value) (task.Runner, error) { return &cmdQuery{}, nil }
+func newExecCmd(v cue.Value) (task.Runner, error)  { return &cmdExec{}, nil }
+
+type cmdQuery struct{}
+type cmdExec struct{}
+
+func (c *cmdQuery) Run(ctx *task.Context) (res interface{}, err error) {
+	db, query, args, err := open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(taskContext(ctx), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	result := []interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(types))
+		ptrs := make([]interface{}, len(types))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+		row := map[string]interface{}{}
+		for i, t := range types {
+			row[t.Name()] = convert(values[i], t.DatabaseTypeName())
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"rows": result}, nil
+}
+
+func (c *cmdExec) Run(ctx *task.Context) (res interface{}, err error) {
+	db, query, args, err := open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	r, err := db.ExecContext(taskContext(ctx), query, args...)
+	if err != nil {
+		return nil, err
+	}
+	n, err := r.RowsAffected()
+	if err != nil {
+		n = -1 // not supported by the driver
+	}
+	return map[string]interface{}{"rowsAffected": n}, nil
+}
+
+// open opens the database of the task and returns its query and arguments.
+func open(ctx *task.Context) (db *sql.DB, query string, args []interface{}, err error) {
+	var (
+		driver = ctx.String("driver")
+		dsn    = ctx.String("dsn")
+	)
+	query = ctx.String("query")
+	if ctx.Err != nil {
+		return nil, "", nil, ctx.Err
+	}
+	list, err := ctx.Lookup("args").List()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	for list.Next() {
+		a, err := arg(list.Value())
+		if err != nil {
+			return nil, "", nil, err
+		}
+		args = append(args, a)
+	}
+	db, err = sql.Open(driver, dsn)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return db, query, args, nil
+}
+
+// arg converts v to a value for a placeholder.
+func arg(v cue.Value) (interface{}, error) {
+	switch v.Kind() {
+	case cue.NullKind:
+		return nil, nil
+	case cue.BoolKind:
+		return v.Bool()
+	case cue.IntKind:
+		return v.Int64()
+	case cue.FloatKind:
+		return v.Float64()
+	case cue.StringKind:
+		return v.String()
+	case cue.BytesKind:
+		return v.Bytes()
+	}
+	return nil, errors.Newf(v.Pos(), "invalid argument %v", v)
+}
+
+// convert converts a value scanned from a column of the given database type
+// to a value that can be represented in CUE.
+func convert(v interface{}, typ string) interface{} {
+	switch x := v.(type) {
+	case []byte:
+		// Drivers return the text of many types, like numeric, as bytes.
+		typ = strings.ToUpper(typ)
+		if strings.Contains(typ, "BLOB") || strings.Contains(typ, "BINARY") || typ == "BYTEA" {
+			return x
+		}
+		return string(x)
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	}
+	return v
+}
+
+func taskContext(ctx *task.Context) context.Context {
+	if ctx.Context == nil {
+		return context.Background()
+	}
+	return ctx.Context
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/internal/task"
+	"cuelang.org/go/internal/value"
+)
+
+func parse(t *testing.T, kind, expr string) cue.Value {
+	t.Helper()
+
+	x, err := parser.ParseExpr("test", expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r cue.Runtime
+	i, err := r.CompileExpr(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value.UnifyBuiltin(i.Value(), kind)
+}
+
+func init() {
+	sql.Register("cuetest", fakeDriver{})
+}
+
+// fakeDriver is a database driver that reports the queries it receives and
+// returns a fixed table for each query.
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt(query), nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return nil, fmt.Errorf("not supported") }
+
+type fakeStmt string
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if strings.HasPrefix(string(s), "FAIL") {
+		return nil, fmt.Errorf("syntax error")
+	}
+	return driver.RowsAffected(len(args)), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{rows: [][]driver.Value{
+		{int64(1), []byte("12.50"), []byte{0xff}, time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC), nil},
+		{int64(2), string(s), []byte(fmt.Sprint(args)), time.Time{}, true},
+	}}, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id", "text", "data", "created", "flag"}
+}
+
+func (r *fakeRows) ColumnTypeDatabaseTypeName(i int) string {
+	return []string{"INT8", "NUMERIC", "BYTEA", "TIMESTAMPTZ", "BOOL"}[i]
+}
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestQuery(t *testing.T) {
+	v := parse(t, "tool/sql.Query", `{
+		driver: "cuetest"
+		dsn:    "test"
+		query:  "SELECT $1, $2"
+		args: [1, "a", 2.5, true, null, 'b']
+	}`)
+	res, err := (*cmdQuery).Run(nil, &task.Context{Obj: v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"rows":[` +
+		`{"created":"2021-05-01T12:00:00Z","data":"/w==","flag":null,"id":1,"text":"12.50"},` +
+		`{"created":"0001-01-01T00:00:00Z","data":"WzEgYSAyLjUgdHJ1ZSA8bmlsPiBbOThdXQ==","flag":true,"id":2,"text":"SELECT $1, $2"}]}`
+	if got := string(b); got != want {
+		t.Errorf("\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+func TestExec(t *testing.T) {
+	testCases := []struct {
+		in  string
+		out string
+	}{{
+		in:  `{driver: "cuetest", dsn: "test", query: "DELETE", args: [1, 2]}`,
+		out: `{"rowsAffected":2}`,
+	}, {
+		in:  `{driver: "cuetest", dsn: "test", query: "FAIL"}`,
+		out: `syntax error`,
+	}, {
+		in:  `{driver: "unknown", dsn: "test", query: "DELETE"}`,
+		out: `sql: unknown driver "unknown" (forgotten import?)`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			v := parse(t, "tool/sql.Exec", tc.in)
+			res, err := (*cmdExec).Run(nil, &task.Context{Obj: v})
+			var got string
+			if err != nil {
+				got = err.Error()
+			} else {
+				b, err := json.Marshal(res)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = string(b)
+			}
+			if got != tc.out {
+				t.Errorf("\ngot:  %s\nwant: %s", got, tc.out)
+			}
+		})
+	}
+}
@@ -47,6 +47,15 @@ Command: {
 	// long is a longer description that spans multiple lines and
 	// likely contain examples of usage of the command.
 	$long?: string
+
+	// output optionally declares the results of the command. Its fields
+	// mirror the tasks of the command: each field constrains the value of
+	// the task, or group of tasks, of the same name. A task fails if its
+	// value does not satisfy these constraints once it has completed.
+	//
+	// Example:
+	//     $output: ls: stdout: =~"main.go"
+	$output?: {...}
 }
 
 // TODO:
@@ -72,6 +81,12 @@ Task: {
 	// $after can be used to specify a task is run after another one, when
 	// it does not otherwise refer to an output of that task.
 	$after?: Task | [...Task]
+
+	// $cache indicates that the results of the task depend only on its
+	// value, so that they can be reused from a cache, if one is set with
+	// the --cache flag of the cmd command, when the task is run again with
+	// the same inputs.
+	$cache?: bool
 }
 
 // TODO: consider these options:
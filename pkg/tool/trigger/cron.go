@@ -0,0 +1,193 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A schedule determines when a Cron trigger fires.
+type schedule interface {
+	// next reports the first time after t at which the schedule fires, or
+	// the zero time if there is none within five years.
+	next(t time.Time) time.Time
+}
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = []string{"", "jan", "feb", "mar", "apr", "may", "jun",
+		"jul", "aug", "sep", "oct", "nov", "dec"}
+	dayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// parseSchedule parses a cron expression or descriptor.
+func parseSchedule(s string) (schedule, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(s[len("@every "):]))
+		if err != nil {
+			return nil, err
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("non-positive duration %v", d)
+		}
+		return every(d), nil
+	}
+	if x, ok := descriptors[s]; ok {
+		s = x
+	}
+
+	f := strings.Fields(s)
+	if len(f) != 5 {
+		return nil, fmt.Errorf("expected 5 fields, found %d", len(f))
+	}
+	c := &cron{}
+	var err error
+	if c.minute, err = parseField(f[0], 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if c.hour, err = parseField(f[1], 0, 23, nil); err != nil {
+		return nil, err
+	}
+	if c.dom, err = parseField(f[2], 1, 31, nil); err != nil {
+		return nil, err
+	}
+	if c.month, err = parseField(f[3], 1, 12, monthNames); err != nil {
+		return nil, err
+	}
+	if c.dow, err = parseField(f[4], 0, 7, dayNames); err != nil {
+		return nil, err
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1 // 7 is also Sunday
+	}
+	c.anyDOM = strings.HasPrefix(f[2], "*")
+	c.anyDOW = strings.HasPrefix(f[4], "*")
+	return c, nil
+}
+
+// parseField parses a comma-separated list of values, ranges, and steps, like
+// "1,10-20/5,*/15", into a set of bits.
+func parseField(s string, min, max int, names []string) (bits uint64, err error) {
+	for _, item := range strings.Split(s, ",") {
+		rng, step := item, 1
+		if i := strings.IndexByte(item, '/'); i >= 0 {
+			rng = item[:i]
+			if step, err = strconv.Atoi(item[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %q", item)
+			}
+		}
+		lo, hi := min, max
+		if rng != "*" {
+			a, b := rng, ""
+			if i := strings.IndexByte(rng, '-'); i >= 0 {
+				a, b = rng[:i], rng[i+1:]
+			}
+			if lo, err = parseValue(a, min, max, names); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if b != "" {
+				if hi, err = parseValue(b, min, max, names); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				hi = max // "a/n" means "a-max/n"
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range %q", rng)
+			}
+		}
+		for i := lo; i <= hi; i += step {
+			bits |= 1 << uint(i)
+		}
+	}
+	return bits, nil
+}
+
+func parseValue(s string, min, max int, names []string) (int, error) {
+	for i, n := range names {
+		if n != "" && strings.EqualFold(s, n) {
+			return i, nil
+		}
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil || i < min || i > max {
+		return 0, fmt.Errorf("invalid value %q: must be between %d and %d", s, min, max)
+	}
+	return i, nil
+}
+
+// cron is a schedule defined by a cron expression. Each field holds the set
+// of allowed values as bits.
+type cron struct {
+	minute, hour, dom, month, dow uint64
+
+	// As in other implementations of cron, a day matches if it matches either
+	// of the day of month or day of week, unless one of them is *.
+	anyDOM, anyDOW bool
+}
+
+func (c *cron) next(t time.Time) time.Time {
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, t.Location())
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case !c.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+func (c *cron) matchDay(t time.Time) bool {
+	if c.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case c.anyDOM:
+		return dow
+	case c.anyDOW:
+		return dom
+	}
+	return dom || dow
+}
+
+// every is a schedule that fires at a fixed interval.
+type every time.Duration
+
+func (d every) next(t time.Time) time.Time {
+	return t.Add(time.Duration(d))
+}
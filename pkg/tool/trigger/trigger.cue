@@ -0,0 +1,89 @@
+// Copyright 2021 The CUE Authors
+// 
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+// 
+//     http://www.apache.org/licenses/LICENSE-2.0
+// 
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+package trigger
+
+// Cron fires according to a schedule.
+//
+// Example:
+//     tick: trigger.Cron & {
+//         schedule: "*/15 * * * *"
+//     }
+//     apply: exec.Run & {
+//         $after: tick
+//         cmd:    "kubectl apply -f manifests"
+//     }
+Cron: {
+	$id: "tool/trigger.Cron"
+
+	// schedule is a cron expression with the five fields minute, hour,
+	// day of month, month, and day of week, or one of the descriptors
+	// @yearly, @monthly, @weekly, @daily, @hourly, or @every <duration>.
+	schedule: string
+
+	// utc indicates that schedule is interpreted in UTC rather than in
+	// local time.
+	utc: *false | bool
+
+	// time is the time at which the trigger fired, in RFC 3339 format.
+	time: string
+}
+
+// Watch fires when any of a set of files is created, modified, or removed.
+//
+// Example:
+//     changed: trigger.Watch & {
+//         files: ["config/*.cue"]
+//     }
+Watch: {
+	$id: "tool/trigger.Watch"
+
+	// files holds the glob patterns, as accepted by filepath.Glob, of the
+	// files to watch.
+	files: [...string]
+
+	// interval is the time between checks for changes.
+	interval: *"1s" | string
+
+	// changed holds the sorted names of the files that changed.
+	changed: [...string]
+}
+
+// Webhook fires when an HTTP request is received. The request is answered
+// with 202 Accepted.
+//
+// Example:
+//     push: trigger.Webhook & {
+//         addr: ":8080"
+//         path: "/push"
+//     }
+Webhook: {
+	$id: "tool/trigger.Webhook"
+
+	// addr is the TCP address on which to listen, like ":8080".
+	addr: string
+
+	// path is the URL path on which requests are accepted.
+	path: *"/" | string
+
+	// request holds the received request. Header values that occur more
+	// than once are joined with commas.
+	request: {
+		method: string
+		url:    string
+		header: [string]: string
+		body: string
+	}
+}
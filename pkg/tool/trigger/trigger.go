@@ -0,0 +1,250 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+//go:generate go run gen.go
+//go:generate gofmt -s -w .
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/internal/task"
+)
+
+func init() {
+	task.Register("tool/trigger.Cron", newCronCmd)
+	task.Register("tool/trigger.Watch", newWatchCmd)
+	task.Register("tool/trigger.Webhook", newWebhookCmd)
+}
+
+// The runners of a task are created once and are run each time the trigger
+// is rearmed, which allows them to retain state between events.
+
+type cronCmd struct{}
+
+func newCronCmd(v cue.Value) (task.Runner, error) { return &cronCmd{}, nil }
+
+func (c *cronCmd) Trigger() {}
+
+func (c *cronCmd) Run(ctx *task.Context) (res interface{}, err error) {
+	spec := ctx.String("schedule")
+	utc, _ := ctx.Lookup("utc").Bool()
+	if ctx.Err != nil {
+		return nil, ctx.Err
+	}
+	s, err := parseSchedule(spec)
+	if err != nil {
+		return nil, errors.Wrapf(err, ctx.Obj.Pos(), "invalid schedule %q", spec)
+	}
+
+	now := time.Now()
+	if utc {
+		now = now.UTC()
+	}
+	next := s.next(now)
+	if next.IsZero() {
+		return nil, errors.Newf(ctx.Obj.Pos(), "schedule %q never fires", spec)
+	}
+
+	timer := time.NewTimer(next.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-taskContext(ctx).Done():
+		return nil, taskContext(ctx).Err()
+	}
+	return map[string]interface{}{"time": next.Format(time.RFC3339)}, nil
+}
+
+type watchCmd struct {
+	// files records the state of the watched files after the last event.
+	files map[string]fileState
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+func newWatchCmd(v cue.Value) (task.Runner, error) { return &watchCmd{}, nil }
+
+func (c *watchCmd) Trigger() {}
+
+func (c *watchCmd) Run(ctx *task.Context) (res interface{}, err error) {
+	var patterns []string
+	if err := ctx.Lookup("files").Decode(&patterns); err != nil {
+		ctx.Err = errors.Append(ctx.Err, errors.Promote(err, "files"))
+	}
+	interval := ctx.String("interval")
+	if ctx.Err != nil {
+		return nil, ctx.Err
+	}
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return nil, errors.Wrapf(err, ctx.Obj.Pos(), "invalid interval %q", interval)
+	}
+
+	if c.files == nil {
+		if c.files, err = stat(patterns); err != nil {
+			return nil, err
+		}
+	}
+
+	ticker := time.NewTicker(d)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-taskContext(ctx).Done():
+			return nil, taskContext(ctx).Err()
+		}
+		files, err := stat(patterns)
+		if err != nil {
+			return nil, err
+		}
+		changed := []string{}
+		for name, s := range files {
+			if old, ok := c.files[name]; !ok || old != s {
+				changed = append(changed, name)
+			}
+		}
+		for name := range c.files {
+			if _, ok := files[name]; !ok {
+				changed = append(changed, name)
+			}
+		}
+		if len(changed) > 0 {
+			c.files = files
+			sort.Strings(changed)
+			return map[string]interface{}{"changed": changed}, nil
+		}
+	}
+}
+
+// stat reports the state of the files matching the given glob patterns.
+func stat(patterns []string) (map[string]fileState, error) {
+	files := map[string]fileState{}
+	for _, p := range patterns {
+		names, err := filepath.Glob(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			fi, err := os.Stat(name)
+			if os.IsNotExist(err) {
+				continue // removed since the call to Glob
+			}
+			if err != nil {
+				return nil, err
+			}
+			files[name] = fileState{fi.Size(), fi.ModTime()}
+		}
+	}
+	return files, nil
+}
+
+type webhookCmd struct {
+	once   sync.Once
+	err    error
+	events chan map[string]interface{}
+}
+
+func newWebhookCmd(v cue.Value) (task.Runner, error) { return &webhookCmd{}, nil }
+
+func (c *webhookCmd) Trigger() {}
+
+func (c *webhookCmd) Run(ctx *task.Context) (res interface{}, err error) {
+	addr := ctx.String("addr")
+	path := ctx.String("path")
+	if ctx.Err != nil {
+		return nil, ctx.Err
+	}
+
+	// The server is started once and keeps running between events, so that
+	// requests that come in while the dependent tasks run are not refused.
+	c.once.Do(func() {
+		c.err = c.listen(taskContext(ctx), addr, path)
+	})
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	select {
+	case req := <-c.events:
+		return map[string]interface{}{"request": req}, nil
+	case <-taskContext(ctx).Done():
+		return nil, taskContext(ctx).Err()
+	}
+}
+
+func (c *webhookCmd) listen(ctx context.Context, addr, path string) error {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	c.events = make(chan map[string]interface{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		header := map[string]interface{}{}
+		for k, v := range r.Header {
+			header[k] = strings.Join(v, ",")
+		}
+		req := map[string]interface{}{
+			"method": r.Method,
+			"url":    r.URL.RequestURI(),
+			"header": header,
+			"body":   string(body),
+		}
+		select {
+		case c.events <- req:
+			w.WriteHeader(http.StatusAccepted)
+		case <-r.Context().Done():
+		case <-ctx.Done():
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+		}
+	})}
+	go srv.Serve(l)
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	return nil
+}
+
+func taskContext(ctx *task.Context) context.Context {
+	if ctx.Context != nil {
+		return ctx.Context
+	}
+	return context.Background()
+}
@@ -0,0 +1,272 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/internal/task"
+	"cuelang.org/go/internal/value"
+)
+
+func parse(t *testing.T, kind, expr string) cue.Value {
+	t.Helper()
+
+	x, err := parser.ParseExpr("test", expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r cue.Runtime
+	i, err := r.CompileExpr(x)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return value.UnifyBuiltin(i.Value(), kind)
+}
+
+func TestSchedule(t *testing.T) {
+	// 2021-05-01 is a Saturday.
+	now := time.Date(2021, 5, 1, 12, 34, 56, 0, time.UTC)
+
+	testCases := []struct {
+		schedule string
+		next     string
+	}{{
+		schedule: "* * * * *",
+		next:     "2021-05-01T12:35:00Z",
+	}, {
+		schedule: "*/15 * * * *",
+		next:     "2021-05-01T12:45:00Z",
+	}, {
+		schedule: "5,10 9-17/4 * * *",
+		next:     "2021-05-01T13:05:00Z",
+	}, {
+		schedule: "0 0 * * mon-fri",
+		next:     "2021-05-03T00:00:00Z",
+	}, {
+		schedule: "0 0 * * 7",
+		next:     "2021-05-02T00:00:00Z",
+	}, {
+		// Either the day of month or the day of week must match.
+		schedule: "0 0 15 * wed",
+		next:     "2021-05-05T00:00:00Z",
+	}, {
+		schedule: "30 6 29 feb *",
+		next:     "2024-02-29T06:30:00Z",
+	}, {
+		schedule: "@monthly",
+		next:     "2021-06-01T00:00:00Z",
+	}, {
+		schedule: "@every 1h30m",
+		next:     "2021-05-01T14:04:56Z",
+	}, {
+		schedule: "0 0 31 feb *",
+		next:     "never",
+	}, {
+		schedule: "* * * *",
+		next:     "error: expected 5 fields, found 4",
+	}, {
+		schedule: "60 * * * *",
+		next:     `error: invalid value "60": must be between 0 and 59`,
+	}, {
+		schedule: "5-1 * * * *",
+		next:     `error: invalid range "5-1"`,
+	}, {
+		schedule: "*/0 * * * *",
+		next:     `error: invalid step in "*/0"`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.schedule, func(t *testing.T) {
+			var got string
+			s, err := parseSchedule(tc.schedule)
+			switch {
+			case err != nil:
+				got = "error: " + err.Error()
+			case s.next(now).IsZero():
+				got = "never"
+			default:
+				got = s.next(now).Format(time.RFC3339)
+			}
+			if got != tc.next {
+				t.Errorf("got %s; want %s", got, tc.next)
+			}
+		})
+	}
+}
+
+func TestCron(t *testing.T) {
+	v := parse(t, "tool/trigger.Cron", `{schedule: "@every 10ms"}`)
+	r, err := newCronCmd(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := r.Run(&task.Context{Obj: v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := time.Parse(time.RFC3339, res.(map[string]interface{})["time"].(string)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "watch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, data string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("a.cue", "a: 1")
+	write("b.cue", "b: 1")
+	write("c.txt", "c")
+
+	v := parse(t, "tool/trigger.Watch", fmt.Sprintf(`{
+		files: [%q]
+		interval: "10ms"
+	}`, filepath.Join(dir, "*.cue")))
+	r, err := newWatchCmd(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		change  func()
+		changed []string
+	}{{
+		change: func() {
+			write("a.cue", "a: 12")
+			write("c.txt", "cc")
+		},
+		changed: []string{"a.cue"},
+	}, {
+		change: func() {
+			write("d.cue", "d: 1")
+			os.Remove(filepath.Join(dir, "b.cue"))
+		},
+		changed: []string{"b.cue", "d.cue"},
+	}}
+	for _, tc := range testCases {
+		type result struct {
+			res interface{}
+			err error
+		}
+		done := make(chan result, 1)
+		go func() {
+			res, err := r.Run(&task.Context{Obj: v})
+			done <- result{res, err}
+		}()
+
+		// Wait for the initial state to be recorded.
+		time.Sleep(50 * time.Millisecond)
+		tc.change()
+
+		x := <-done
+		if x.err != nil {
+			t.Fatal(x.err)
+		}
+		var got []string
+		for _, name := range x.res.(map[string]interface{})["changed"].([]string) {
+			got = append(got, filepath.Base(name))
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tc.changed) {
+			t.Errorf("got %v; want %v", got, tc.changed)
+		}
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	v := parse(t, "tool/trigger.Webhook", fmt.Sprintf(`{
+		addr: %q
+		path: "/hook"
+	}`, addr))
+	r, err := newWebhookCmd(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, body := range []string{"first", "second"} {
+		type result struct {
+			res interface{}
+			err error
+		}
+		done := make(chan result, 1)
+		go func() {
+			res, err := r.Run(&task.Context{Context: ctx, Obj: v})
+			done <- result{res, err}
+		}()
+
+		var resp *http.Response
+		for i := 0; i < 50; i++ {
+			resp, err = http.Post("http://"+addr+"/hook?x=1", "text/plain", strings.NewReader(body))
+			if err == nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			t.Errorf("got status %d; want %d", resp.StatusCode, http.StatusAccepted)
+		}
+
+		x := <-done
+		if x.err != nil {
+			t.Fatal(x.err)
+		}
+		req := x.res.(map[string]interface{})["request"].(map[string]interface{})
+		got := fmt.Sprintf("%s %s %s %s", req["method"], req["url"],
+			req["header"].(map[string]interface{})["Content-Type"], req["body"])
+		if want := "POST /hook?x=1 text/plain " + body; got != want {
+			t.Errorf("got %q; want %q", got, want)
+		}
+	}
+
+	resp, err := http.Get("http://" + addr + "/other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("got status %d; want %d", resp.StatusCode, http.StatusNotFound)
+	}
+}
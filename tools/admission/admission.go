@@ -0,0 +1,318 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package admission implements a Kubernetes validating admission webhook
+// that validates objects against CUE schemas.
+//
+// Schemas are selected by the group, version, and kind of the object under
+// review. A schema is any top-level field or definition of the configured
+// value that has concrete apiVersion and kind fields:
+//
+//	#Deployment: apps.#Deployment & {
+//		apiVersion: "apps/v1"
+//		kind:       "Deployment"
+//		spec: replicas: <=10
+//	}
+//
+// Objects are validated as data, meaning all regular fields must be
+// concrete, and all violations are reported as the causes of the response
+// status. Rules from an optional policy bundle are applied in addition:
+// violations of rules with severity error deny the request, while other
+// violations are returned as warnings.
+//
+// The Handler is a reference implementation that only depends on the
+// AdmissionReview wire format. It does not handle TLS, which is required by
+// the Kubernetes API server, or the registration of the webhook.
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	cuejson "cuelang.org/go/encoding/json"
+	"cuelang.org/go/tools/policy"
+	"cuelang.org/go/tools/vet"
+)
+
+// A GVK identifies a type of Kubernetes object.
+type GVK struct {
+	Group   string
+	Version string
+	Kind    string
+}
+
+// parseGVK parses the apiVersion and kind of a Kubernetes object.
+func parseGVK(apiVersion, kind string) GVK {
+	g := GVK{Version: apiVersion, Kind: kind}
+	if p := strings.LastIndexByte(apiVersion, '/'); p >= 0 {
+		g.Group, g.Version = apiVersion[:p], apiVersion[p+1:]
+	}
+	return g
+}
+
+func (g GVK) String() string {
+	if g.Group == "" {
+		return g.Version + ", Kind=" + g.Kind
+	}
+	return g.Group + "/" + g.Version + ", Kind=" + g.Kind
+}
+
+// Config configures a Handler.
+type Config struct {
+	// Policy, if not nil, is applied to all objects in addition to their
+	// schema.
+	Policy *policy.Bundle
+
+	// DenyUnknown denies objects for which there is no schema. By default
+	// they are allowed.
+	DenyUnknown bool
+}
+
+// A Handler is an http.Handler that serves admission reviews.
+type Handler struct {
+	cfg     Config
+	ctx     *cue.Context
+	schemas map[GVK]cue.Value
+}
+
+// NewHandler creates a Handler for the schemas defined in v. It reports an
+// error if v is invalid or if more than one schema is defined for the same
+// type of object.
+func NewHandler(v cue.Value, cfg *Config) (*Handler, error) {
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	h := &Handler{ctx: v.Context(), schemas: map[GVK]cue.Value{}}
+	if cfg != nil {
+		h.cfg = *cfg
+	}
+
+	iter, err := v.Fields(cue.Definitions(true))
+	if err != nil {
+		return nil, err
+	}
+	for iter.Next() {
+		s := iter.Value()
+		apiVersion, err1 := s.LookupPath(cue.ParsePath("apiVersion")).String()
+		kind, err2 := s.LookupPath(cue.ParsePath("kind")).String()
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		gvk := parseGVK(apiVersion, kind)
+		if prev, ok := h.schemas[gvk]; ok {
+			return nil, errors.Newf(s.Pos(),
+				"schema for %v already defined at %v", gvk, prev.Path())
+		}
+		h.schemas[gvk] = s
+	}
+	return h, nil
+}
+
+// Schemas reports the types of objects for which h has a schema.
+func (h *Handler) Schemas() []GVK {
+	a := make([]GVK, 0, len(h.schemas))
+	for g := range h.schemas {
+		a = append(a, g)
+	}
+	return a
+}
+
+// Review is an AdmissionReview of the admission.k8s.io API group. Only the
+// fields used by the Handler are included.
+type Review struct {
+	APIVersion string    `json:"apiVersion"`
+	Kind       string    `json:"kind"`
+	Request    *Request  `json:"request,omitempty"`
+	Response   *Response `json:"response,omitempty"`
+}
+
+// Request is the request of an AdmissionReview.
+type Request struct {
+	UID       string          `json:"uid"`
+	Kind      Kind            `json:"kind"`
+	Operation string          `json:"operation,omitempty"`
+	Namespace string          `json:"namespace,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Object    json.RawMessage `json:"object,omitempty"`
+}
+
+// Kind is the GroupVersionKind of the object of a request.
+type Kind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// Response is the response of an AdmissionReview.
+type Response struct {
+	UID      string   `json:"uid"`
+	Allowed  bool     `json:"allowed"`
+	Status   *Status  `json:"status,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// Status describes why a request was denied. It follows the Status type of
+// the Kubernetes API.
+type Status struct {
+	Code    int            `json:"code,omitempty"`
+	Message string         `json:"message,omitempty"`
+	Reason  string         `json:"reason,omitempty"`
+	Details *StatusDetails `json:"details,omitempty"`
+}
+
+// StatusDetails lists the individual violations of a denied request.
+type StatusDetails struct {
+	Kind   string  `json:"kind,omitempty"`
+	Name   string  `json:"name,omitempty"`
+	Causes []Cause `json:"causes,omitempty"`
+}
+
+// A Cause describes a single violation.
+type Cause struct {
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	Field   string `json:"field,omitempty"`
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var review Review
+	if err := json.Unmarshal(b, &review); err != nil || review.Request == nil {
+		http.Error(w, "invalid AdmissionReview", http.StatusBadRequest)
+		return
+	}
+
+	resp := h.Review(review.Request)
+	out, err := json.Marshal(&Review{
+		APIVersion: review.APIVersion,
+		Kind:       "AdmissionReview",
+		Response:   resp,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
+
+// Review validates the object of req and reports the response.
+func (h *Handler) Review(req *Request) *Response {
+	resp := &Response{UID: req.UID, Allowed: true}
+	if len(req.Object) == 0 {
+		// Deletions do not carry an object.
+		return resp
+	}
+
+	gvk := GVK(req.Kind)
+	schema, ok := h.schemas[gvk]
+	if !ok && !h.cfg.DenyUnknown && h.cfg.Policy == nil {
+		return resp
+	}
+
+	expr, err := cuejson.Extract("object", req.Object)
+	if err != nil {
+		return status(resp, http.StatusBadRequest, "BadRequest", err.Error(), nil)
+	}
+	obj := h.ctx.BuildExpr(expr)
+
+	var causes []Cause
+	deny := func(reason, field, msg string) {
+		resp.Allowed = false
+		causes = append(causes, Cause{Reason: reason, Field: field, Message: msg})
+	}
+
+	switch {
+	case ok:
+		// Paths are reported relative to the root of the schemas.
+		n := len(schema.Path().Selectors())
+		r := vet.Validate(schema.Unify(obj), &vet.Config{Mode: vet.Data})
+		for _, e := range r.Errors {
+			path := e.Path()
+			if len(path) >= n {
+				path = path[n:]
+			}
+			deny("FieldValueInvalid", field(path), message(e))
+		}
+	case h.cfg.DenyUnknown:
+		deny("FieldValueNotSupported", "kind", fmt.Sprintf("no schema for %v", gvk))
+	}
+
+	if h.cfg.Policy != nil {
+		for _, v := range h.cfg.Policy.Evaluate(obj) {
+			if v.Severity() != policy.Error {
+				resp.Warnings = append(resp.Warnings, v.Error())
+				continue
+			}
+			deny("FieldValueForbidden", field(v.Path()), v.Error())
+		}
+	}
+
+	if resp.Allowed {
+		return resp
+	}
+	msg := fmt.Sprintf("%s %q is invalid", req.Kind.Kind, req.Name)
+	return status(resp, http.StatusUnprocessableEntity, "Invalid", msg, &StatusDetails{
+		Kind:   req.Kind.Kind,
+		Name:   req.Name,
+		Causes: causes,
+	})
+}
+
+func status(resp *Response, code int, reason, msg string, d *StatusDetails) *Response {
+	resp.Allowed = false
+	resp.Status = &Status{
+		Code:    code,
+		Message: msg,
+		Reason:  reason,
+		Details: d,
+	}
+	return resp
+}
+
+// field converts an error path to a Kubernetes field path.
+func field(path []string) string {
+	var b strings.Builder
+	for _, s := range path {
+		if s != "" && s[0] >= '0' && s[0] <= '9' {
+			fmt.Fprintf(&b, "[%s]", s)
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(s)
+	}
+	return b.String()
+}
+
+// message reports the message of e without its path.
+func message(e errors.Error) string {
+	format, args := e.Msg()
+	return fmt.Sprintf(format, args...)
+}
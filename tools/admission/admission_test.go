@@ -0,0 +1,165 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package admission
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/tools/policy"
+)
+
+const schemas = `
+#Deployment: {
+	apiVersion: "apps/v1"
+	kind:       "Deployment"
+	metadata: name: string
+	spec: {
+		replicas: int & <=10
+		template: spec: containers: [...{
+			name:  string
+			image: string
+		}]
+	}
+}
+#ConfigMap: {
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+	data: [string]: string
+	...
+}
+`
+
+const policies = `
+spec?: template?: spec?: containers?: [...{
+	image?: =~"^registry.example.com/" @policy(id=IMG001, severity=warning)
+}]
+`
+
+func TestHandler(t *testing.T) {
+	ctx := cuecontext.New()
+	bundle, err := policy.NewBundle(ctx.CompileString(policies))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewHandler(ctx.CompileString(schemas), &Config{Policy: bundle})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name   string
+		kind   Kind
+		object string
+		want   string
+	}{{
+		name: "valid",
+		kind: Kind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		object: `{
+			"apiVersion": "apps/v1",
+			"kind": "Deployment",
+			"metadata": {"name": "web"},
+			"spec": {
+				"replicas": 3,
+				"template": {"spec": {"containers": [
+					{"name": "web", "image": "registry.example.com/web"}
+				]}}
+			}
+		}`,
+		want: `{"uid":"1","allowed":true}`,
+	}, {
+		name: "all violations",
+		kind: Kind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		object: `{
+			"apiVersion": "apps/v1",
+			"kind": "Deployment",
+			"metadata": {"name": "web"},
+			"spec": {
+				"replicas": 30,
+				"template": {"spec": {"containers": [
+					{"image": "docker.io/web"}
+				]}}
+			}
+		}`,
+		want: `{"uid":"1","allowed":false,"status":{"code":422,"message":"Deployment \"web\" is invalid","reason":"Invalid","details":{"kind":"Deployment","name":"web","causes":[` +
+			`{"reason":"FieldValueInvalid","message":"invalid value 30 (out of bound \u003c=10)","field":"spec.replicas"},` +
+			`{"reason":"FieldValueInvalid","message":"incomplete value string","field":"spec.template.spec.containers[0].name"}]}},` +
+			`"warnings":["warning IMG001: invalid value \"docker.io/web\" (out of bound =~\"^registry.example.com/\")"]}`,
+	}, {
+		name:   "core group",
+		kind:   Kind{Version: "v1", Kind: "ConfigMap"},
+		object: `{"apiVersion": "v1", "kind": "ConfigMap", "data": {"a": 1}}`,
+		want: `{"uid":"1","allowed":false,"status":{"code":422,"message":"ConfigMap \"web\" is invalid","reason":"Invalid","details":{"kind":"ConfigMap","name":"web","causes":[` +
+			`{"reason":"FieldValueInvalid","message":"conflicting values 1 and string (mismatched types int and string)","field":"data.a"}]}}}`,
+	}, {
+		name:   "unknown kind",
+		kind:   Kind{Version: "v1", Kind: "Secret"},
+		object: `{"apiVersion": "v1", "kind": "Secret"}`,
+		want:   `{"uid":"1","allowed":true}`,
+	}, {
+		name: "delete",
+		kind: Kind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		want: `{"uid":"1","allowed":true}`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			in, err := json.Marshal(&Review{
+				APIVersion: "admission.k8s.io/v1",
+				Kind:       "AdmissionReview",
+				Request: &Request{
+					UID:    "1",
+					Kind:   tc.kind,
+					Name:   "web",
+					Object: json.RawMessage(tc.object),
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(in))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("unexpected status %d: %s", w.Code, w.Body)
+			}
+			var review Review
+			if err := json.Unmarshal(w.Body.Bytes(), &review); err != nil {
+				t.Fatal(err)
+			}
+			b, _ := json.Marshal(review.Response)
+			if got := string(b); got != tc.want {
+				t.Errorf("\ngot:  %s\nwant: %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewHandler(t *testing.T) {
+	ctx := cuecontext.New()
+	v := ctx.CompileString(`
+		#A: {apiVersion: "v1", kind: "Pod"}
+		#B: {apiVersion: "v1", kind: "Pod"}
+	`)
+	_, err := NewHandler(v, nil)
+	if err == nil || !strings.Contains(err.Error(), "already defined") {
+		t.Errorf("expected duplicate schema error, got %v", err)
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bench measures the time and memory needed to evaluate CUE
+// configurations, allowing performance regressions to be detected.
+//
+// A corpus is a directory of txtar archives. Each archive defines a single
+// benchmark, named after the archive. The CUE files in the root directory
+// of an archive form the configuration that is evaluated. Other files in
+// the archive, such as files in subdirectories, are available for import
+// but are not evaluated directly:
+//
+//	-- cue.mod/module.cue --
+//	module: "example.com"
+//	-- in.cue --
+//	package config
+//
+//	import "example.com/schema"
+//
+//	config: schema.#Config & {replicas: 3}
+//	-- schema/schema.cue --
+//	package schema
+//
+//	#Config: replicas: int
+//
+// A single operation of a benchmark loads, builds, and validates the
+// configuration. Errors in loading or building the configuration are
+// reported, but validation errors are not, so that incomplete or failing
+// configurations may be benchmarked as well.
+//
+// Benchmarks can be run from a Go test with the Benchmark function, for
+// use with go test -bench, or with the cue debug bench command. Both
+// report results in the format of Go benchmarks, which can be compared
+// with tools such as benchstat.
+package bench
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rogpeppe/go-internal/txtar"
+
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+	"cuelang.org/go/cue/token"
+)
+
+// A Case is a single benchmark of a corpus.
+type Case struct {
+	// Name is the name of the archive, without the .txtar extension.
+	Name string
+
+	archive *txtar.Archive
+}
+
+// Load reads the benchmarks of the corpus in dir. If dir is a txtar
+// archive, Load returns the benchmark it defines.
+func Load(dir string) ([]*Case, error) {
+	if filepath.Ext(dir) == ".txtar" {
+		c, err := loadCase(dir)
+		if err != nil {
+			return nil, err
+		}
+		return []*Case{c}, nil
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var cases []*Case
+	for _, fi := range files {
+		if fi.IsDir() || filepath.Ext(fi.Name()) != ".txtar" {
+			continue
+		}
+		c, err := loadCase(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+func loadCase(file string) (*Case, error) {
+	a, err := txtar.ParseFile(file)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSuffix(filepath.Base(file), ".txtar")
+	return &Case{Name: name, archive: a}, nil
+}
+
+// Run performs a single operation of the benchmark.
+func (c *Case) Run() error {
+	const dir = "/cuebench"
+	var args []string
+	overlay := map[string]load.Source{}
+	for _, f := range c.archive.Files {
+		if !strings.Contains(f.Name, "/") && filepath.Ext(f.Name) == ".cue" {
+			args = append(args, f.Name)
+		}
+		overlay[filepath.Join(dir, f.Name)] = load.FromBytes(f.Data)
+	}
+	insts := load.Instances(args, &load.Config{Dir: dir, Overlay: overlay})
+	if len(insts) != 1 {
+		return fmt.Errorf("%s: archive must define a single instance", c.Name)
+	}
+	if err := insts[0].Err; err != nil {
+		return errors.Wrapf(err, token.NoPos, "benchmark %s", c.Name)
+	}
+	values, err := cuecontext.New().BuildInstances(insts)
+	if err != nil {
+		return errors.Wrapf(err, token.NoPos, "benchmark %s", c.Name)
+	}
+	_ = values[0].Validate()
+	return nil
+}
+
+// A Config defines how benchmarks are run.
+type Config struct {
+	// Time is the minimum time to run each benchmark for. The default is
+	// one second.
+	Time time.Duration
+}
+
+// A Result holds the measurements of a benchmark.
+type Result struct {
+	Name string
+
+	// N is the number of operations that were run.
+	N int
+
+	// T is the total time taken by all operations.
+	T time.Duration
+
+	// Allocs and Bytes are the total number of memory allocations and
+	// allocated bytes of all operations.
+	Allocs uint64
+	Bytes  uint64
+}
+
+// NsPerOp reports the average time per operation in nanoseconds.
+func (r *Result) NsPerOp() int64 {
+	return r.T.Nanoseconds() / int64(r.N)
+}
+
+// String formats r as a line of Go benchmark output, such as
+//
+//	BenchmarkEval/name	100	1040522 ns/op	 515424 B/op	 9614 allocs/op
+func (r *Result) String() string {
+	return fmt.Sprintf("BenchmarkEval/%s\t%8d\t%10d ns/op\t%10d B/op\t%8d allocs/op",
+		r.Name, r.N, r.NsPerOp(), r.Bytes/uint64(r.N), r.Allocs/uint64(r.N))
+}
+
+// Run runs the benchmark c until it has run at least for the time given by
+// cfg. A nil Config is valid.
+func (cfg *Config) Run(c *Case) (*Result, error) {
+	d := time.Second
+	if cfg != nil && cfg.Time > 0 {
+		d = cfg.Time
+	}
+	// Run the benchmark once before measuring to report errors and to
+	// initialize any shared state.
+	if err := c.Run(); err != nil {
+		return nil, err
+	}
+
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	r := &Result{Name: c.Name}
+	for r.T < d {
+		if err := c.Run(); err != nil {
+			return nil, err
+		}
+		r.N++
+		r.T = time.Since(start)
+	}
+	runtime.ReadMemStats(&after)
+	r.Allocs = after.Mallocs - before.Mallocs
+	r.Bytes = after.TotalAlloc - before.TotalAlloc
+	return r, nil
+}
+
+// Benchmark runs the benchmarks of the corpus in dir as sub-benchmarks of
+// b. It is intended to be called from a Go benchmark function:
+//
+//	func BenchmarkConfigs(b *testing.B) {
+//		bench.Benchmark(b, "testdata/bench")
+//	}
+func Benchmark(b *testing.B, dir string) {
+	cases, err := Load(dir)
+	if err != nil {
+		b.Fatal(err)
+	}
+	for _, c := range cases {
+		if err := c.Run(); err != nil {
+			b.Fatal(errors.Details(err, nil))
+		}
+		b.Run(c.Name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := c.Run(); err != nil {
+					b.Fatal(errors.Details(err, nil))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bench
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	cases, err := Load("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, c := range cases {
+		names = append(names, c.Name)
+	}
+	if got, want := len(names), 3; got != want {
+		t.Fatalf("got benchmarks %v; want %d", names, want)
+	}
+
+	testCases := []struct {
+		name string
+		err  string
+	}{{
+		name: "conflict", // evaluation errors are not reported
+	}, {
+		name: "import",
+	}, {
+		name: "unresolved",
+		err:  `benchmark unresolved: /cuebench/in.cue:1:4: reference "b" not found`,
+	}}
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := cases[i]
+			if c.Name != tc.name {
+				t.Fatalf("got benchmark %s; want %s", c.Name, tc.name)
+			}
+			r, err := (&Config{Time: time.Millisecond}).Run(c)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Fatalf("got error %v; want %s", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.N == 0 || r.T < time.Millisecond || r.Allocs == 0 {
+				t.Errorf("unexpected result %+v", r)
+			}
+			re := `^BenchmarkEval/` + tc.name + `\t +\d+\t +\d+ ns/op\t +\d+ B/op\t +\d+ allocs/op$`
+			if !regexp.MustCompile(re).MatchString(r.String()) {
+				t.Errorf("unexpected output %q", r.String())
+			}
+		})
+	}
+}
+
+func BenchmarkTestdata(b *testing.B) {
+	Benchmark(b, "testdata/import.txtar")
+}
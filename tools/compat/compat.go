@@ -0,0 +1,285 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compat classifies the changes between two versions of a schema.
+//
+// A new version of a schema is backward compatible with an old version if all
+// data that is valid under the old schema is also valid under the new schema.
+// It is forward compatible if all data that is valid under the new schema is
+// also valid under the old schema. Both properties are determined using
+// subsumption.
+//
+// Aside from an overall verdict, Check reports the individual changes per
+// path, which is useful for reviewing schema changes in publishing workflows.
+package compat
+
+import (
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+)
+
+// A Level indicates the compatibility of a schema change.
+type Level int
+
+const (
+	// Compatible indicates a change does not affect which data is accepted.
+	Compatible Level = iota
+
+	// Backward indicates that all data accepted by the old schema is also
+	// accepted by the new schema, but not vice versa.
+	Backward
+
+	// Forward indicates that all data accepted by the new schema is also
+	// accepted by the old schema, but not vice versa.
+	Forward
+
+	// Breaking indicates that the schemas are neither backward nor forward
+	// compatible.
+	Breaking
+)
+
+func (l Level) String() string {
+	switch l {
+	case Compatible:
+		return "compatible"
+	case Backward:
+		return "backward compatible"
+	case Forward:
+		return "forward compatible"
+	}
+	return "breaking"
+}
+
+// join returns the level that results from applying both l and m.
+func (l Level) join(m Level) Level {
+	switch {
+	case l == m, m == Compatible:
+		return l
+	case l == Compatible:
+		return m
+	}
+	return Breaking
+}
+
+func makeLevel(backward, forward bool) Level {
+	switch {
+	case backward && forward:
+		return Compatible
+	case backward:
+		return Backward
+	case forward:
+		return Forward
+	}
+	return Breaking
+}
+
+// A Change describes a single difference between two schemas.
+type Change struct {
+	// Path is the location of the change relative to the compared values.
+	Path cue.Path
+
+	// Level indicates the compatibility of this individual change.
+	Level Level
+
+	// Msg describes the change.
+	Msg string
+}
+
+func (c Change) String() string {
+	p := c.Path.String()
+	if p == "" {
+		p = "<root>"
+	}
+	return fmt.Sprintf("%s: %s: %s", c.Level, p, c.Msg)
+}
+
+// A Report holds the result of comparing two schemas.
+type Report struct {
+	// Level is the compatibility level of the combined changes.
+	Level Level
+
+	// Changes holds all detected changes in depth-first order.
+	Changes []Change
+}
+
+func (r *Report) String() string {
+	b := &strings.Builder{}
+	for _, c := range r.Changes {
+		fmt.Fprintln(b, c)
+	}
+	fmt.Fprintf(b, "result: %s\n", r.Level)
+	return b.String()
+}
+
+// Check compares schema from with schema to and reports how to relates to
+// from. Both values must be created with the same cue.Context.
+func Check(from, to cue.Value) *Report {
+	c := &checker{}
+	c.compare(nil, from, to)
+
+	// The per-path analysis may miss changes in, for instance, closedness or
+	// validators. Fall back to subsumption on the complete values if no
+	// individual changes were found.
+	if len(c.changes) == 0 {
+		level := makeLevel(to.Subsume(from) == nil, from.Subsume(to) == nil)
+		if level != Compatible {
+			c.add(nil, level, "schema changed")
+		}
+	}
+
+	level := Compatible
+	for _, x := range c.changes {
+		level = level.join(x.Level)
+	}
+	return &Report{Level: level, Changes: c.changes}
+}
+
+type checker struct {
+	changes []Change
+}
+
+func (c *checker) add(path []cue.Selector, l Level, format string, args ...interface{}) {
+	p := make([]cue.Selector, len(path))
+	copy(p, path)
+	c.changes = append(c.changes, Change{
+		Path:  cue.MakePath(p...),
+		Level: l,
+		Msg:   fmt.Sprintf(format, args...),
+	})
+}
+
+type field struct {
+	sel      cue.Selector
+	v        cue.Value
+	optional bool
+}
+
+func fields(v cue.Value) (a []field, m map[string]field) {
+	m = map[string]field{}
+	iter, err := v.Fields(cue.Optional(true), cue.Definitions(true))
+	if err != nil {
+		return nil, m
+	}
+	for iter.Next() {
+		f := field{iter.Selector(), iter.Value(), iter.IsOptional()}
+		if f.sel.PkgPath() != "" {
+			continue // hidden fields are not part of the schema
+		}
+		a = append(a, f)
+		m[f.sel.String()] = f
+	}
+	return a, m
+}
+
+func (c *checker) compare(path []cue.Selector, from, to cue.Value) {
+	if from.IncompleteKind() != cue.StructKind || to.IncompleteKind() != cue.StructKind {
+		c.compareValues(path, from, to)
+		return
+	}
+
+	switch wasOpen, isOpen := from.Allows(cue.AnyString), to.Allows(cue.AnyString); {
+	case wasOpen && !isOpen:
+		c.add(path, Forward, "struct closed")
+	case !wasOpen && isOpen:
+		c.add(path, Backward, "struct opened")
+	}
+
+	fromFields, fromMap := fields(from)
+	toFields, toMap := fields(to)
+
+	for _, f := range fromFields {
+		p := append(path, f.sel)
+		g, ok := toMap[f.sel.String()]
+		if !ok {
+			c.removed(p, f, to)
+			continue
+		}
+		switch {
+		case f.optional && !g.optional:
+			if !isImplied(g.v) {
+				c.add(p, Forward, "field made required")
+			}
+		case !f.optional && g.optional:
+			if !isImplied(f.v) {
+				c.add(p, Backward, "field made optional")
+			}
+		}
+		c.compare(p, f.v, g.v)
+	}
+
+	for _, g := range toFields {
+		if _, ok := fromMap[g.sel.String()]; !ok {
+			c.added(append(path, g.sel), g, from)
+		}
+	}
+}
+
+func (c *checker) compareValues(path []cue.Selector, from, to cue.Value) {
+	backward := to.Subsume(from) == nil
+	forward := from.Subsume(to) == nil
+	switch l := makeLevel(backward, forward); l {
+	case Compatible:
+	case Backward:
+		c.add(path, l, "constraint relaxed")
+	case Forward:
+		c.add(path, l, "constraint tightened")
+	default:
+		c.add(path, l, "constraint changed incompatibly")
+	}
+}
+
+// added records a field that is defined in the new schema only.
+func (c *checker) added(path []cue.Selector, g field, from cue.Value) {
+	if g.sel.IsDefinition() {
+		c.add(path, Backward, "definition added")
+		return
+	}
+	// Data accepted by the old schema does not have this field, which is
+	// only a problem if the new schema requires a value.
+	backward := g.optional || isImplied(g.v)
+	// Data accepted by the new schema may have this field, which the old
+	// schema must allow.
+	forward := from.Allows(g.sel)
+	kind := "field"
+	if g.optional {
+		kind = "optional field"
+	}
+	c.add(path, makeLevel(backward, forward), "%s added", kind)
+}
+
+// removed records a field that is defined in the old schema only.
+func (c *checker) removed(path []cue.Selector, f field, to cue.Value) {
+	if f.sel.IsDefinition() {
+		c.add(path, Breaking, "definition removed")
+		return
+	}
+	backward := to.Allows(f.sel)
+	forward := f.optional || isImplied(f.v)
+	kind := "field"
+	if f.optional {
+		kind = "optional field"
+	}
+	c.add(path, makeLevel(backward, forward), "%s removed", kind)
+}
+
+// isImplied reports whether a schema field need not be specified by data as
+// it is fully determined by the schema itself.
+func isImplied(v cue.Value) bool {
+	if d, ok := v.Default(); ok {
+		v = d
+	}
+	return v.Validate(cue.Concrete(true)) == nil
+}
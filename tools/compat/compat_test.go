@@ -0,0 +1,122 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compat
+
+import (
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestCheck(t *testing.T) {
+	testCases := []struct {
+		name string
+		from string
+		to   string
+		want string
+	}{{
+		name: "equal",
+		from: `a: int`,
+		to:   `a: int`,
+		want: `
+result: compatible`,
+	}, {
+		name: "relax",
+		from: `a: int & <10`,
+		to:   `a: int`,
+		want: `
+backward compatible: a: constraint relaxed
+result: backward compatible`,
+	}, {
+		name: "tighten",
+		from: `a: string`,
+		to:   `a: "foo" | "bar"`,
+		want: `
+forward compatible: a: constraint tightened
+result: forward compatible`,
+	}, {
+		name: "incompatible",
+		from: `a: string`,
+		to:   `a: int`,
+		want: `
+breaking: a: constraint changed incompatibly
+result: breaking`,
+	}, {
+		name: "closedness",
+		from: `#A: {b: int, ...}`,
+		to:   `#A: {b: int}`,
+		want: `
+forward compatible: #A: struct closed
+result: forward compatible`,
+	}, {
+		name: "nested",
+		from: `#A: {b: {c: int, d?: string}}`,
+		to:   `#A: {b: {c: int, d?: string, e?: bool}}`,
+		want: `
+backward compatible: #A.b.e: optional field added
+result: backward compatible`,
+	}, {
+		name: "added required field",
+		from: `#A: {a: int}`,
+		to:   `#A: {a: int, b: string}`,
+		want: `
+breaking: #A.b: field added
+result: breaking`,
+	}, {
+		name: "added field with default",
+		from: `#A: {a: int}`,
+		to:   `#A: {a: int, b: string | *"x"}`,
+		want: `
+backward compatible: #A.b: field added
+result: backward compatible`,
+	}, {
+		name: "removed optional field",
+		from: `#A: {a: int, b?: int}`,
+		to:   `#A: {a: int}`,
+		want: `
+forward compatible: #A.b: optional field removed
+result: forward compatible`,
+	}, {
+		name: "optionality",
+		from: `#A: {a?: int, b: int}`,
+		to:   `#A: {a: int, b?: int}`,
+		want: `
+forward compatible: #A.a: field made required
+backward compatible: #A.b: field made optional
+result: breaking`,
+	}, {
+		name: "definitions",
+		from: `#A: int`,
+		to:   `#B: int`,
+		want: `
+breaking: #A: definition removed
+backward compatible: #B: definition added
+result: breaking`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := cuecontext.New()
+			from := ctx.CompileString(tc.from)
+			to := ctx.CompileString(tc.to)
+
+			got := strings.TrimSpace(Check(from, to).String())
+			want := strings.TrimSpace(tc.want)
+			if got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package doc extracts structured documentation from CUE values.
+//
+// Documentation is taken from the comments preceding a package clause,
+// definition, or field. Examples are given with @example attributes, the
+// contents of which are a CUE expression. An @example attribute may be
+// associated with a field or be a declaration within a struct:
+//
+//	// A Service describes a network service.
+//	#Service: {
+//		@example({name: "web", port: 80})
+//
+//		name: string
+//
+//		// port is the port on which the service listens.
+//		port: int & >0 @example(8080)
+//	}
+//
+// Each example is checked against the value it documents.
+package doc
+
+import (
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+)
+
+// A Package holds the documentation of a package or other top-level value.
+type Package struct {
+	// Doc is the package documentation.
+	Doc string
+
+	// Definitions documents the definitions at the top level, in the order
+	// in which they are declared.
+	Definitions []*Field
+
+	// Fields documents the regular fields at the top level, in the order in
+	// which they are declared.
+	Fields []*Field
+
+	// Examples holds the examples declared at the top level.
+	Examples []*Example
+}
+
+// A Field holds the documentation of a field or definition.
+type Field struct {
+	// Name is the label of the field.
+	Name string
+
+	// Path is the path of the field relative to the documented value.
+	Path cue.Path
+
+	// Pos is the position of the declaration of the field.
+	Pos token.Pos
+
+	// Doc is the documentation of the field. Comments from multiple
+	// declarations are separated by a blank line.
+	Doc string
+
+	// Type describes the values the field allows, in CUE syntax. For a
+	// field that refers to a definition, it is the path of the definition.
+	// For structs it is "{...}": their fields are documented in Fields.
+	Type string
+
+	// Optional reports whether the field is optional.
+	Optional bool
+
+	// Examples holds the examples of the field.
+	Examples []*Example
+
+	// Definitions and Fields document the definitions and regular fields
+	// of a struct value.
+	Definitions []*Field
+	Fields      []*Field
+}
+
+// An Example is a value given in an @example attribute.
+type Example struct {
+	// Value is the example, formatted as CUE.
+	Value string
+
+	// Err is non-nil if the example is not valid CUE or is not an instance
+	// of the documented value.
+	Err error
+}
+
+// Extract returns the documentation of the top-level value v, which is
+// typically the value of a package instance.
+func Extract(v cue.Value) (*Package, error) {
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	x := &extractor{}
+	p := &Package{
+		Doc:      docText(v.Doc()),
+		Examples: examples(v, cue.ValueAttr),
+	}
+	p.Definitions, p.Fields = x.fields(nil, v)
+	return p, nil
+}
+
+// Describe returns the documentation of a single field, excluding the
+// documentation of any fields it contains. This is useful for showing
+// documentation of the field at a cursor position, for instance.
+func Describe(v cue.Value) *Field {
+	f := &Field{}
+	sels := v.Path().Selectors()
+	if len(sels) > 0 {
+		name := sels[len(sels)-1].String()
+		f.Name = strings.TrimSuffix(name, "?")
+		f.Optional = f.Name != name
+	}
+	describe(f, v)
+	return f
+}
+
+type extractor struct {
+	// stack holds the positions of the values being documented to detect
+	// recursive structures.
+	stack []token.Pos
+}
+
+func (x *extractor) fields(path []cue.Selector, v cue.Value) (defs, fields []*Field) {
+	if v.IncompleteKind() != cue.StructKind {
+		return nil, nil
+	}
+	iter, err := v.Fields(cue.Definitions(true), cue.Optional(true))
+	if err != nil {
+		return nil, nil
+	}
+	for iter.Next() {
+		sel := iter.Selector()
+		if sel.PkgPath() != "" {
+			continue // hidden
+		}
+		p := append(path[:len(path):len(path)], sel)
+		f := x.field(p, iter.Value())
+		f.Name = sel.String()
+		f.Optional = iter.IsOptional()
+		if sel.IsDefinition() {
+			defs = append(defs, f)
+		} else {
+			fields = append(fields, f)
+		}
+	}
+	return defs, fields
+}
+
+func (x *extractor) field(path []cue.Selector, v cue.Value) *Field {
+	f := &Field{Path: cue.MakePath(path...)}
+	describe(f, v)
+
+	if f.Type != "{...}" {
+		return f
+	}
+	pos := v.Pos()
+	for _, p := range x.stack {
+		if pos.IsValid() && p == pos {
+			return f
+		}
+	}
+	x.stack = append(x.stack, pos)
+	f.Definitions, f.Fields = x.fields(path, v)
+	x.stack = x.stack[:len(x.stack)-1]
+	return f
+}
+
+func describe(f *Field, v cue.Value) {
+	f.Pos = v.Pos()
+	f.Doc = docText(v.Doc())
+	f.Type = typeString(v)
+
+	// Examples declared within a referenced definition document the
+	// definition, not the field.
+	mask := cue.ValueAttr
+	if _, p := v.ReferencePath(); len(p.Selectors()) > 0 {
+		mask = cue.FieldAttr
+	}
+	f.Examples = examples(v, mask)
+}
+
+func docText(docs []*ast.CommentGroup) string {
+	var a []string
+	for _, c := range docs {
+		if s := strings.TrimSpace(c.Text()); s != "" {
+			a = append(a, s)
+		}
+	}
+	return strings.Join(a, "\n\n")
+}
+
+func typeString(v cue.Value) string {
+	if _, p := v.ReferencePath(); len(p.Selectors()) > 0 {
+		return p.String()
+	}
+	if v.IncompleteKind() == cue.StructKind {
+		return "{...}"
+	}
+	b, err := format.Node(v.Syntax(
+		cue.ResolveReferences(true),
+		cue.Docs(false),
+		cue.Attributes(false),
+	))
+	if err != nil {
+		return v.IncompleteKind().String()
+	}
+	return string(b)
+}
+
+func examples(v cue.Value, mask cue.AttrKind) (a []*Example) {
+	for _, attr := range v.Attributes(mask) {
+		if attr.Name() != "example" {
+			continue
+		}
+		a = append(a, example(v, attr.Contents()))
+	}
+	return a
+}
+
+func example(v cue.Value, src string) *Example {
+	e := &Example{Value: src}
+	expr, err := parser.ParseExpr("example", src)
+	if err != nil {
+		e.Err = err
+		return e
+	}
+	if b, err := format.Node(expr); err == nil {
+		e.Value = string(b)
+	}
+	x := v.Context().BuildExpr(expr)
+	if err := v.Unify(x).Validate(); err != nil {
+		e.Err = err
+	}
+	return e
+}
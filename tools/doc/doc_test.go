@@ -0,0 +1,148 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package doc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestExtract(t *testing.T) {
+	const src = `
+// Package deploy defines deployments.
+package deploy
+
+@example({service: {name: "web"}})
+
+// A Service describes a network service.
+//
+// Services are exposed on a port.
+#Service: {
+	@example({name: "web", port: 80})
+	@example({name: 3})
+
+	name: string
+
+	// port is the port on which the service listens.
+	port?: >0 & int @example(8080) @example("x")
+
+	// next is the next service in a chain.
+	next?: #Service
+
+	_hidden: int
+}
+
+// service is the default service.
+service: #Service & {
+	// name names the service.
+	name: *"default" | string
+}
+
+replicas: 1 @example(3) @example(1 +)
+`
+	v := cuecontext.New().CompileString(src)
+	p, err := Extract(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "doc: %q\n", p.Doc)
+	printExamples(b, "", p.Examples)
+	for _, f := range p.Definitions {
+		printField(b, "", f)
+	}
+	for _, f := range p.Fields {
+		printField(b, "", f)
+	}
+
+	want := `doc: "Package deploy defines deployments."
+example: {service: {name: "web"}}
+#Service: {...}
+	doc: "A Service describes a network service.\n\nServices are exposed on a port."
+	example: {name: "web", port: 80}
+	example: {name: 3} (error: #Service.name: conflicting values string and 3 (mismatched types string and int))
+	name: string
+	port?: >0 & int
+		doc: "port is the port on which the service listens."
+		example: 8080
+		example: "x" (error: #Service.port: conflicting values int and "x" (mismatched types int and string))
+	next?: #Service
+		doc: "next is the next service in a chain."
+service: {...}
+	doc: "service is the default service."
+	example: {name: "web", port: 80}
+	example: {name: 3} (error: service.name: 1 errors in empty disjunction: (and 1 more errors))
+	name: *"default" | string
+		doc: "name names the service."
+	port?: >0 & int
+		doc: "port is the port on which the service listens."
+		example: 8080
+		example: "x" (error: service.port: conflicting values int and "x" (mismatched types int and string))
+	next?: #Service
+		doc: "next is the next service in a chain."
+replicas: 1
+	example: 3 (error: replicas: conflicting values 3 and 1)
+	example: 1 + (error: expected operand, found 'EOF')
+`
+	if got := b.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func printField(b *strings.Builder, indent string, f *Field) {
+	opt := ""
+	if f.Optional {
+		opt = "?"
+	}
+	fmt.Fprintf(b, "%s%s%s: %s\n", indent, f.Name, opt, f.Type)
+	indent += "\t"
+	if f.Doc != "" {
+		fmt.Fprintf(b, "%sdoc: %q\n", indent, f.Doc)
+	}
+	printExamples(b, indent, f.Examples)
+	for _, f := range f.Definitions {
+		printField(b, indent, f)
+	}
+	for _, f := range f.Fields {
+		printField(b, indent, f)
+	}
+}
+
+func printExamples(b *strings.Builder, indent string, a []*Example) {
+	for _, e := range a {
+		fmt.Fprintf(b, "%sexample: %s", indent, e.Value)
+		if e.Err != nil {
+			fmt.Fprintf(b, " (error: %v)", e.Err)
+		}
+		fmt.Fprintln(b)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	v := cuecontext.New().CompileString(`
+	a: {
+		// b is documented.
+		b: int @example(1)
+	}`)
+	f := Describe(v.LookupPath(cue.ParsePath("a.b")))
+	got := fmt.Sprintf("%s %v %s %q %d", f.Name, f.Optional, f.Type, f.Doc, len(f.Examples))
+	if want := `b false int "b is documented." 1`; got != want {
+		t.Errorf("got %s; want %s", got, want)
+	}
+}
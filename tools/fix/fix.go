@@ -15,12 +15,17 @@
 // Package fix contains functionality for writing CUE files with legacy
 // syntax to newer ones.
 //
+// The rewrites are organized as individual fixes, listed by Fixes, which can
+// be selected by name with the Only option. By default, all fixes that are not
+// optional are applied.
+//
 // Note: the transformations that are supported in this package will change
 // over time.
 package fix
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"cuelang.org/go/cue/ast"
@@ -31,8 +36,8 @@ import (
 type Option func(*options)
 
 type options struct {
-	simplify   bool
-	deprecated bool
+	simplify bool
+	only     map[string]bool
 }
 
 // Simplify enables fixes that simplify the code, but are not strictly
@@ -41,6 +46,87 @@ func Simplify() Option {
 	return func(o *options) { o.simplify = true }
 }
 
+// Only restricts the applied fixes to the fixes with the given names.
+// Optional fixes are applied if they are named. Unknown names are ignored;
+// use Lookup to validate names.
+func Only(names ...string) Option {
+	return func(o *options) {
+		if o.only == nil {
+			o.only = map[string]bool{}
+		}
+		for _, name := range names {
+			o.only[name] = true
+		}
+	}
+}
+
+// A Fix is an individual rewrite.
+type Fix struct {
+	// Name identifies the fix.
+	Name string
+
+	// Doc is a one-line description of the fix.
+	Doc string
+
+	// Optional indicates that the fix is not strictly necessary and is only
+	// applied when requested.
+	Optional bool
+
+	apply func(f *ast.File) *ast.File
+}
+
+var fixes = []*Fix{{
+	Name:  "intdiv",
+	Doc:   "rewrite integer division operators div, mod, quo, and rem to builtins",
+	apply: fixIntDiv,
+}, {
+	Name:  "alias",
+	Doc:   "rewrite old-style aliases of the form X = expr to let clauses",
+	apply: fixAlias,
+}, {
+	Name:  "comments",
+	Doc:   "rewrite block comments to line comments",
+	apply: fixComments,
+}, {
+	Name:  "quoted",
+	Doc:   "rewrite backquoted identifiers to identifiers or quoted labels",
+	apply: fixQuoted,
+}, {
+	Name:  "builtins",
+	Doc:   "replace calls to deprecated builtins with their replacements",
+	apply: fixBuiltins,
+}, {
+	Name:     "simplify",
+	Doc:      "simplify expressions, such as x & _ to x, >=0 & >=10 to >=10, and 2 * 1024 to 2048",
+	Optional: true,
+	apply:    simplify,
+}}
+
+// Fixes reports all fixes in the order in which they are applied.
+func Fixes() []*Fix {
+	return append([]*Fix(nil), fixes...)
+}
+
+// Lookup returns the fix with the given name or nil if there is no such fix.
+func Lookup(name string) *Fix {
+	for _, f := range fixes {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+func (o *options) enabled(f *Fix) bool {
+	switch {
+	case o.only != nil:
+		return o.only[f.Name]
+	case f.Optional:
+		return f.Name == "simplify" && o.simplify
+	}
+	return true
+}
+
 // File applies fixes to f and returns it. It alters the original f.
 func File(f *ast.File, o ...Option) *ast.File {
 	var options options
@@ -48,8 +134,17 @@ func File(f *ast.File, o ...Option) *ast.File {
 		f(&options)
 	}
 
-	// Rewrite integer division operations to use builtins.
-	f = astutil.Apply(f, func(c astutil.Cursor) bool {
+	for _, fix := range fixes {
+		if options.enabled(fix) {
+			f = fix.apply(f)
+		}
+	}
+	return f
+}
+
+// fixIntDiv rewrites integer division operations to use builtins.
+func fixIntDiv(f *ast.File) *ast.File {
+	return astutil.Apply(f, func(c astutil.Cursor) bool {
 		n := c.Node()
 		switch x := n.(type) {
 		case *ast.BinaryExpr:
@@ -65,8 +160,10 @@ func File(f *ast.File, o ...Option) *ast.File {
 		}
 		return true
 	}, nil).(*ast.File)
+}
 
-	// Rewrite an old-style alias to a let clause.
+// fixAlias rewrites an old-style alias to a let clause.
+func fixAlias(f *ast.File) *ast.File {
 	ast.Walk(f, func(n ast.Node) bool {
 		var decls []ast.Decl
 		switch x := n.(type) {
@@ -88,8 +185,11 @@ func File(f *ast.File, o ...Option) *ast.File {
 		}
 		return true
 	}, nil)
+	return f
+}
 
-	// Rewrite block comments to regular comments.
+// fixComments rewrites block comments to regular comments.
+func fixComments(f *ast.File) *ast.File {
 	ast.Walk(f, func(n ast.Node) bool {
 		switch x := n.(type) {
 		case *ast.CommentGroup:
@@ -121,7 +221,11 @@ func File(f *ast.File, o ...Option) *ast.File {
 		}
 		return true
 	}, nil)
+	return f
+}
 
+// fixQuoted rewrites quoted identifiers.
+func fixQuoted(f *ast.File) *ast.File {
 	// Referred nodes and used identifiers.
 	referred := map[ast.Node]string{}
 	used := map[string]bool{}
@@ -246,9 +350,42 @@ func File(f *ast.File, o ...Option) *ast.File {
 	// 	return true
 	// }, nil).(*ast.File)
 
-	if options.simplify {
-		f = simplify(f)
-	}
+	return f
+}
 
+// deprecatedBuiltins maps builtin packages to the deprecated functions in
+// these packages and their replacements.
+var deprecatedBuiltins = map[string]map[string]string{
+	"list": {"SortStable": "Sort"},
+}
+
+// fixBuiltins replaces references to deprecated builtins.
+func fixBuiltins(f *ast.File) *ast.File {
+	ast.Walk(f, func(n ast.Node) bool {
+		x, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := x.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		spec, ok := pkg.Node.(*ast.ImportSpec)
+		if !ok {
+			return true
+		}
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return true
+		}
+		sel, ok := x.Sel.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if name, ok := deprecatedBuiltins[path][sel.Name]; ok {
+			sel.Name = name
+		}
+		return true
+	}, nil)
 	return f
 }
@@ -27,6 +27,7 @@ func TestFile(t *testing.T) {
 		in       string
 		out      string
 		simplify bool
+		only     []string
 	}{{
 		name: "rewrite integer division",
 		in: `package foo
@@ -77,6 +78,33 @@ a: {
 		`,
 		out: `
 let y = foo
+`,
+	}, {
+		name: "deprecated builtins",
+		in: `package foo
+
+import "list"
+
+a: list.SortStable([2, 1], list.Ascending)
+`,
+		out: `package foo
+
+import "list"
+
+a: list.Sort([2, 1], list.Ascending)
+`,
+	}, {
+		name: "only",
+		only: []string{"alias", "simplify"},
+		in: `
+		y = foo
+		a: 1 div 2
+		b: 3 & _
+		`,
+		out: `
+let y = foo
+a: 1 div 2
+b: 3
 `,
 	}, {
 		simplify: true,
@@ -94,6 +122,34 @@ x3: 4
 x4: 4
 x5: 9 & 4
 x6: 4 & 9
+`,
+	}, {
+		name:     "simplify constraints",
+		simplify: true,
+		in: `
+		a: int & >=0 & >10 & >=10 & <=100 & <100
+		b: number & int & int
+		c: string | "x" | string | 'x'
+		d: *"x" | "x" | "y" | "y"
+		e: string & "x"
+		f: >=-1 & >-1.5 & >="a"
+		g: (2 * 1024) + 1
+		h: "v" + "1"
+		i: 1 - 3
+		j: 1 / 2
+		k: int & 5.0
+		`,
+		out: `a: int & >10 & <100
+b: int
+c: string | 'x'
+d: *"x" | "y"
+e: "x"
+f: >=-1 & >="a"
+g: 2049
+h: "v1"
+i: -2
+j: 1 / 2
+k: int & 5.0
 `,
 
 		// 	}, {
@@ -151,6 +207,9 @@ x6: 4 & 9
 			if tc.simplify {
 				opts = append(opts, Simplify())
 			}
+			if tc.only != nil {
+				opts = append(opts, Only(tc.only...))
+			}
 			n := File(f, opts...)
 
 			b, err := format.Node(n)
@@ -168,3 +227,14 @@ x6: 4 & 9
 		})
 	}
 }
+
+func TestLookup(t *testing.T) {
+	for _, f := range Fixes() {
+		if Lookup(f.Name) != f {
+			t.Errorf("Lookup(%q) did not return fix", f.Name)
+		}
+	}
+	if Lookup("unknown") != nil {
+		t.Error("Lookup of unknown fix returned a fix")
+	}
+}
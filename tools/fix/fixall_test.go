@@ -18,9 +18,9 @@ import (
 	"fmt"
 	"testing"
 
+	"cuelang.org/go/cue/cuetxtar"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/internal/cuetest"
-	"cuelang.org/go/internal/cuetxtar"
 )
 
 func TestInstances(t *testing.T) {
@@ -15,16 +15,31 @@
 package fix
 
 import (
+	"github.com/cockroachdb/apd/v2"
+
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/literal"
 	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/astinternal"
 )
 
+// simplify rewrites expressions to simpler equivalent ones. It only
+// considers the syntax of expressions and does not resolve references, so
+// that it can be applied to any CUE source, including the output of cue def.
 func simplify(f *ast.File) *ast.File {
-	// Rewrite disjunctions with _ to _.
 	f = astutil.Apply(f, nil, func(c astutil.Cursor) bool {
 		if x, ok := c.Node().(ast.Expr); ok {
-			if y := elideTop(x); x != y {
+			y := elideTop(x)
+			y = foldConstant(y)
+			switch b, ok := y.(*ast.BinaryExpr); {
+			case !ok:
+			case b.Op == token.OR:
+				y = simplifyDisjunction(b)
+			case b.Op == token.AND:
+				y = simplifyConjunction(b)
+			}
+			if x != y {
 				c.Replace(y)
 			}
 		}
@@ -71,3 +86,257 @@ func isTop(x ast.Expr) bool {
 	v, ok := x.(*ast.Ident)
 	return ok && v.Name == "_"
 }
+
+// foldConstant computes the result of adding, subtracting, or multiplying
+// integer literals and of concatenating simple string literals, as in
+// 2 * 1024 and "v" + "1".
+func foldConstant(x ast.Expr) ast.Expr {
+	b, ok := x.(*ast.BinaryExpr)
+	if !ok {
+		return x
+	}
+	if s, ok := stringValue(b.X); ok && b.Op == token.ADD {
+		if t, ok := stringValue(b.Y); ok {
+			return ast.NewString(s + t)
+		}
+	}
+	a, ok := numValue(b.X, true)
+	if !ok {
+		return x
+	}
+	c, ok := numValue(b.Y, true)
+	if !ok {
+		return x
+	}
+	var d apd.Decimal
+	var err error
+	ctx := apd.BaseContext.WithPrecision(1000)
+	switch b.Op {
+	case token.ADD:
+		_, err = ctx.Add(&d, a, c)
+	case token.SUB:
+		_, err = ctx.Sub(&d, a, c)
+	case token.MUL:
+		_, err = ctx.Mul(&d, a, c)
+	default:
+		return x
+	}
+	if err != nil {
+		return x
+	}
+	if d.Negative {
+		d.Negative = false
+		return &ast.UnaryExpr{Op: token.SUB, X: ast.NewLit(token.INT, d.String())}
+	}
+	return ast.NewLit(token.INT, d.String())
+}
+
+// stringValue returns the value of a single-line, double-quoted string
+// literal.
+func stringValue(x ast.Expr) (string, bool) {
+	lit, ok := x.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING ||
+		len(lit.Value) < 2 || lit.Value[0] != '"' || lit.Value[1] == '"' && len(lit.Value) > 2 {
+		return "", false
+	}
+	s, err := literal.Unquote(lit.Value)
+	return s, err == nil
+}
+
+// numValue returns the value of a number literal, which may be negated. If
+// intOnly is set, only integer literals are accepted.
+func numValue(x ast.Expr, intOnly bool) (*apd.Decimal, bool) {
+	neg := false
+	if u, ok := x.(*ast.UnaryExpr); ok && u.Op == token.SUB {
+		neg = true
+		x = u.X
+	}
+	lit, ok := x.(*ast.BasicLit)
+	if !ok || lit.Kind != token.INT && (intOnly || lit.Kind != token.FLOAT) {
+		return nil, false
+	}
+	var info literal.NumInfo
+	var d apd.Decimal
+	if literal.ParseNum(lit.Value, &info) != nil || info.Decimal(&d) != nil {
+		return nil, false
+	}
+	if neg {
+		d.Neg(&d)
+	}
+	return &d, true
+}
+
+// simplifyDisjunction removes duplicate disjuncts, as in "a" | "b" | "a",
+// and, in the absence of defaults, literals subsumed by a type, as in
+// string | "a".
+func simplifyDisjunction(x *ast.BinaryExpr) ast.Expr {
+	a := appendSplit(nil, token.OR, x)
+
+	hasDefault := false
+	for _, e := range a {
+		if isDefault(e) {
+			hasDefault = true
+		}
+	}
+
+	var kept []ast.Expr
+	seen := map[string]int{}
+outer:
+	for _, e := range a {
+		key := astinternal.DebugStr(stripDefault(e))
+		if i, ok := seen[key]; ok {
+			// Retain a default marker, as *a | a is *a.
+			if isDefault(e) {
+				kept[i] = e
+			}
+			continue
+		}
+		if !hasDefault {
+			for _, t := range a {
+				if t != e && subsumesLiteral(t, e) {
+					continue outer
+				}
+			}
+		}
+		seen[key] = len(kept)
+		kept = append(kept, e)
+	}
+	if len(kept) == len(a) {
+		return x
+	}
+	ast.SetRelPos(kept[0], token.NoRelPos)
+	return ast.NewBinExpr(token.OR, kept...)
+}
+
+// simplifyConjunction removes duplicate conjuncts, as in int & int, types
+// implied by literals, as in string & "a", and bounds implied by other
+// bounds, as in >=0 & >=1024.
+func simplifyConjunction(x *ast.BinaryExpr) ast.Expr {
+	a := appendSplit(nil, token.AND, x)
+
+	// The tightest lower and upper bounds.
+	var lower, upper *ast.UnaryExpr
+	for _, e := range a {
+		u, ok := e.(*ast.UnaryExpr)
+		if !ok {
+			continue
+		}
+		switch u.Op {
+		case token.GTR, token.GEQ:
+			if tighter(u, lower, 1) {
+				lower = u
+			}
+		case token.LSS, token.LEQ:
+			if tighter(u, upper, -1) {
+				upper = u
+			}
+		}
+	}
+
+	var kept []ast.Expr
+	seen := map[string]bool{}
+outer:
+	for _, e := range a {
+		key := astinternal.DebugStr(e)
+		if seen[key] {
+			continue
+		}
+		if u, ok := e.(*ast.UnaryExpr); ok && u != lower && u != upper {
+			if _, ok := numValue(u.X, false); ok {
+				switch u.Op {
+				case token.GTR, token.GEQ, token.LSS, token.LEQ:
+					continue
+				}
+			}
+		}
+		for _, t := range a {
+			if t != e && subsumesLiteral(e, t) {
+				continue outer
+			}
+		}
+		seen[key] = true
+		kept = append(kept, e)
+	}
+	if len(kept) == len(a) {
+		return x
+	}
+	ast.SetRelPos(kept[0], token.NoRelPos)
+	return ast.NewBinExpr(token.AND, kept...)
+}
+
+// tighter reports whether the numeric bound u is tighter than the bound
+// cur, where dir is 1 for lower bounds and -1 for upper bounds.
+func tighter(u, cur *ast.UnaryExpr, dir int) bool {
+	a, ok := numValue(u.X, false)
+	if !ok {
+		return false
+	}
+	if cur == nil {
+		return true
+	}
+	b, _ := numValue(cur.X, false)
+	switch c := a.Cmp(b) * dir; {
+	case c > 0:
+		return true
+	case c < 0:
+		return false
+	}
+	// Of equal bounds, the exclusive one is tighter.
+	return u.Op == token.GTR || u.Op == token.LSS
+}
+
+// subsumesLiteral reports whether t is a predeclared type that includes
+// the literal x, as string does "a".
+func subsumesLiteral(t, x ast.Expr) bool {
+	id, ok := t.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		switch x.Kind {
+		case token.STRING:
+			if _, ok := stringValue(x); ok {
+				return id.Name == "string"
+			}
+			return x.Value[0] == '\'' && id.Name == "bytes"
+		case token.INT:
+			return id.Name == "int" || id.Name == "number"
+		case token.FLOAT:
+			return id.Name == "float" || id.Name == "number"
+		case token.TRUE, token.FALSE:
+			return id.Name == "bool"
+		}
+	case *ast.Ident:
+		// int and float are numbers.
+		return id.Name == "number" && (x.Name == "int" || x.Name == "float")
+	}
+	return false
+}
+
+func appendSplit(a []ast.Expr, op token.Token, x ast.Expr) []ast.Expr {
+	switch b := x.(type) {
+	case *ast.BinaryExpr:
+		if b.Op == op {
+			a = appendSplit(a, op, b.X)
+			return appendSplit(a, op, b.Y)
+		}
+	case *ast.ParenExpr:
+		if b, ok := b.X.(*ast.BinaryExpr); ok && b.Op == op {
+			return appendSplit(a, op, b)
+		}
+	}
+	return append(a, x)
+}
+
+func isDefault(x ast.Expr) bool {
+	u, ok := x.(*ast.UnaryExpr)
+	return ok && u.Op == token.MUL
+}
+
+func stripDefault(x ast.Expr) ast.Expr {
+	if isDefault(x) {
+		return x.(*ast.UnaryExpr).X
+	}
+	return x
+}
@@ -0,0 +1,214 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+// This file contains the caching of task results.
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/value"
+)
+
+// A Cache stores the results of tasks under a key that is derived from the
+// value of a task before it runs. Tasks are cached by content: tasks with the
+// same inputs share results, even if they are defined at different paths or
+// in different workflows.
+//
+// A Cache may be used concurrently by multiple tasks.
+type Cache interface {
+	// Get reports the data stored under key, or false if there is none.
+	Get(ctx context.Context, key string) (data []byte, ok bool, err error)
+
+	// Put stores data under key.
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// DirCache is a Cache that stores results as files in the named directory.
+// The directory is created if it does not exist.
+type DirCache string
+
+func (d DirCache) file(key string) string {
+	return filepath.Join(string(d), key[:2], key)
+}
+
+// Get implements Cache.
+func (d DirCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b, err := ioutil.ReadFile(d.file(key))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	return b, err == nil, err
+}
+
+// Put implements Cache.
+func (d DirCache) Put(ctx context.Context, key string, data []byte) error {
+	file := d.file(key)
+	if err := os.MkdirAll(filepath.Dir(file), 0777); err != nil {
+		return err
+	}
+	// Write to a temporary file first so that concurrent readers never
+	// observe partial results.
+	f, err := ioutil.TempFile(filepath.Dir(file), key+".*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), file)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// HTTPCache is a Cache that stores results on an HTTP server, such as a
+// remote build cache or an object store, by means of GET and PUT requests
+// for URL/key. A GET request that returns 404 Not Found is a cache miss.
+type HTTPCache struct {
+	// URL is the base URL of the cache.
+	URL string
+
+	// Header is added to each request. It may be used, for instance, to
+	// set an Authorization header.
+	Header http.Header
+
+	// Client is used to send requests. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+func (h *HTTPCache) do(ctx context.Context, method, key string, body []byte) (*http.Response, error) {
+	url := strings.TrimSuffix(h.URL, "/") + "/" + key
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range h.Header {
+		req.Header[k] = v
+	}
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return client.Do(req)
+}
+
+// Get implements Cache.
+func (h *HTTPCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	resp, err := h.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, false, nil
+	case resp.StatusCode/100 != 2:
+		return nil, false, fmt.Errorf("cache: GET %s: %s", key, resp.Status)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	return b, err == nil, err
+}
+
+// Put implements Cache.
+func (h *HTTPCache) Put(ctx context.Context, key string, data []byte) error {
+	resp, err := h.do(ctx, http.MethodPut, key, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("cache: PUT %s: %s", key, resp.Status)
+	}
+	return nil
+}
+
+// cacheVersion is included in each key to invalidate cached results when
+// their format changes.
+const cacheVersion = "cue-flow-v1"
+
+// runCached runs t, unless its results are cached for the same inputs, in
+// which case the cached results are filled in instead.
+func (c *Controller) runCached(t *Task) error {
+	cache := c.cfg.Cache
+	if cache == nil || c.cfg.Cacheable == nil || !c.cfg.Cacheable(t) {
+		return t.r.Run(t, nil)
+	}
+
+	key, err := t.cacheKey()
+	if err != nil {
+		return err
+	}
+	data, ok, err := cache.Get(t.Context(), key)
+	if err != nil {
+		return err
+	}
+	if ok {
+		expr, err := parser.ParseExpr(key, data)
+		if err != nil {
+			return fmt.Errorf("invalid cached result: %v", err)
+		}
+		t.cached = true
+		return t.Fill(expr)
+	}
+
+	if err := t.r.Run(t, nil); err != nil {
+		return err
+	}
+	if data, err = t.result(); err != nil {
+		return err
+	}
+	return cache.Put(t.Context(), key, data)
+}
+
+// cacheKey computes the key under which the results of t are cached from the
+// value of t before it runs.
+func (t *Task) cacheKey() (string, error) {
+	b, err := format.Node(t.v.Syntax(cue.Final(), cue.Docs(false), cue.Attributes(false)))
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	fmt.Fprintln(h, cacheVersion)
+	h.Write(b)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// result returns the CUE representation of the values filled in by t.
+func (t *Task) result() ([]byte, error) {
+	if t.update == nil {
+		return []byte("{}"), nil
+	}
+	v := &adt.Vertex{Conjuncts: []adt.Conjunct{adt.MakeRootConjunct(nil, t.update)}}
+	v.Finalize(t.ctxt)
+	return format.Node(value.Make(t.ctxt, v).Syntax(cue.Final()))
+}
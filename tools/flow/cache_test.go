@@ -0,0 +1,152 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/tools/flow"
+)
+
+func TestCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flowcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var mu sync.Mutex
+	store := map[string][]byte{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			b, ok := store[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(b)
+		case http.MethodPut:
+			store[r.URL.Path], _ = ioutil.ReadAll(r.Body)
+		}
+	}))
+	defer srv.Close()
+
+	const src = `
+	root: {
+		upper: {
+			$id:    "upper"
+			$cache: true
+			in:     %q
+			out:    string
+		}
+		lower: {
+			$id: "upper"
+			in:  upper.out
+			out: string
+		}
+	}
+	`
+
+	caches := map[string]flow.Cache{
+		"dir":  flow.DirCache(dir),
+		"http": &flow.HTTPCache{URL: srv.URL + "/cache"},
+	}
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			runs := map[string]int{}
+			run := func(in string) (out string, cached []string) {
+				v := cuecontext.New().CompileString(fmt.Sprintf(src, in))
+
+				cfg := &flow.Config{
+					Root:  cue.ParsePath("root"),
+					Cache: cache,
+					Cacheable: func(t *flow.Task) bool {
+						b, _ := t.Value().LookupPath(cue.ParsePath("$cache")).Bool()
+						return b
+					},
+					UpdateFunc: func(c *flow.Controller, task *flow.Task) error {
+						if task != nil && task.Cached() {
+							cached = append(cached, task.Path().String())
+						}
+						return nil
+					},
+				}
+				c := flow.New(cfg, v, func(v cue.Value) (flow.Runner, error) {
+					if !v.LookupPath(cue.ParsePath("$id")).Exists() {
+						return nil, nil
+					}
+					return flow.RunnerFunc(func(t *flow.Task) error {
+						runs[t.Path().String()]++
+						s, err := t.Value().LookupPath(cue.ParsePath("in")).String()
+						if err != nil {
+							return err
+						}
+						return t.Fill(map[string]string{"out": strings.ToUpper(s)})
+					}), nil
+				})
+				if err := c.Run(context.Background()); err != nil {
+					t.Fatal(err)
+				}
+				for _, task := range c.Tasks() {
+					if task.Path().String() == "root.lower" {
+						out, _ = task.Value().LookupPath(cue.ParsePath("out")).String()
+					}
+				}
+				return out, cached
+			}
+
+			testCases := []struct {
+				in     string
+				cached []string
+				runs   map[string]int
+			}{{
+				in:   "foo",
+				runs: map[string]int{"root.upper": 1, "root.lower": 1},
+			}, {
+				in:     "foo",
+				cached: []string{"root.upper"},
+				runs:   map[string]int{"root.upper": 1, "root.lower": 2},
+			}, {
+				in:   "bar",
+				runs: map[string]int{"root.upper": 2, "root.lower": 3},
+			}}
+			for _, tc := range testCases {
+				out, cached := run(tc.in)
+				if want := strings.ToUpper(tc.in); out != want {
+					t.Errorf("%s: got output %q; want %q", tc.in, out, want)
+				}
+				if got, want := fmt.Sprint(cached), fmt.Sprint(tc.cached); got != want {
+					t.Errorf("%s: got cached tasks %v; want %v", tc.in, got, want)
+				}
+				if got, want := fmt.Sprint(runs), fmt.Sprint(tc.runs); got != want {
+					t.Errorf("%s: got runs %v; want %v", tc.in, got, want)
+				}
+			}
+		})
+	}
+}
@@ -37,19 +37,16 @@ package flow
 // Should we allow lists as a shorthand for a sequence of tasks?
 // If so, how do we specify termination behavior?
 
-// TODO:
-// Should we allow tasks to be a child of another task? Currently, the search
-// for tasks end once a task root is found.
-//
-// Semantically it is somewhat unclear to do so: for instance, if an $after
-// is used to refer to an explicit task dependency, it is logically
-// indistinguishable whether this should be a subtask or is a dependency.
-// Using higher-order constructs for analysis is generally undesirable.
+// NOTE: tasks cannot be a child of another task: the search for tasks ends
+// once a task root is found. Semantically it is somewhat unclear to do so: for
+// instance, if an $after is used to refer to an explicit task dependency, it
+// is logically indistinguishable whether this should be a subtask or is a
+// dependency.
 //
-// A possible solution would be to define specific "grouping tasks" whose sole
-// purpose is to define sub tasks. The user of this package would then need
-// to explicitly distinguish between tasks that are dependencies and tasks that
-// are subtasks.
+// Instead, a task may be a "grouping task", as created by Subflow, whose sole
+// purpose is to run the tasks it contains as a workflow of its own. The user
+// of this package thereby explicitly distinguishes between tasks that are
+// dependencies and tasks that are subtasks.
 
 // TODO: streaming tasks/ server applications
 //
@@ -71,6 +68,7 @@ import (
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal/core/adt"
 	"cuelang.org/go/internal/core/convert"
 	"cuelang.org/go/internal/core/eval"
@@ -90,7 +88,7 @@ var (
 // otherwise. It reports an error for illformed tasks.
 //
 // If TaskFunc returns a non-nil Runner the search for task within v stops.
-// That is, subtasks are not supported.
+// That is, subtasks are only supported by means of Subflow.
 type TaskFunc func(v cue.Value) (Runner, error)
 
 // A Runner executes a Task.
@@ -135,6 +133,32 @@ type Config struct {
 	// concrete and cannot change.
 	IgnoreConcrete bool
 
+	// Output optionally indicates the path of a struct that declares the
+	// results of the tasks found under Root. The value of each task, after it
+	// completes, must satisfy the constraints at the path in this struct that
+	// corresponds to its path relative to Root. Tasks for which no constraints
+	// are declared are not checked. The struct itself is not searched for
+	// tasks.
+	Output cue.Path
+
+	// Cache, if non-nil, caches the results of the tasks for which Cacheable
+	// reports true. Such a task is not run if its results are cached for a
+	// task with the same value. Only tasks whose results depend solely on
+	// their value, and that have no other effects, should be cached.
+	Cache Cache
+
+	// Cacheable reports whether the results of t may be cached.
+	Cacheable func(t *Task) bool
+
+	// MaxParallel limits the number of tasks that run at the same time. There
+	// is no limit if it is zero.
+	MaxParallel int
+
+	// Retries is the number of times a failing task is run again before it
+	// is considered to have failed. Tasks that return ErrAbort are not
+	// retried.
+	Retries int
+
 	// UpdateFunc is called whenever the information in the controller is
 	// updated. This includes directly after initialization. The task may be
 	// nil if this call is not the result of a task completing.
@@ -146,6 +170,9 @@ type Controller struct {
 	cfg    Config
 	isTask TaskFunc
 
+	// parent is the task that runs this controller as a subflow, if any.
+	parent *Task
+
 	inst        cue.Value
 	valueSeqNum int64
 
@@ -153,6 +180,7 @@ type Controller struct {
 
 	conjuncts   []adt.Conjunct
 	conjunctSeq int64
+	numInit     int // number of conjuncts of the initial configuration
 
 	taskCh chan *Task
 
@@ -197,11 +225,16 @@ func (c *Controller) addErr(err error, msg string) {
 //
 // The instance value can either be a *cue.Instance or a cue.Value.
 func New(cfg *Config, inst cue.InstanceOrValue, f TaskFunc) *Controller {
+	return newController(cfg, inst, f, nil)
+}
+
+func newController(cfg *Config, inst cue.InstanceOrValue, f TaskFunc, parent *Task) *Controller {
 	v := inst.Value()
 	ctx := eval.NewContext(value.ToInternal(v))
 
 	c := &Controller{
 		isTask: f,
+		parent: parent,
 		inst:   v,
 		opCtx:  ctx,
 
@@ -295,6 +328,7 @@ type Task struct {
 	update   adt.Expr
 	deps     map[*Task]bool
 	pathDeps map[string][]*Task
+	pathPos  map[string]token.Pos // position of a reference to a path
 
 	conjunctSeq int64
 	valueSeq    int64
@@ -302,6 +336,9 @@ type Task struct {
 	err         errors.Error
 	state       State
 	depTasks    []*Task
+	cached      bool
+	sub         *Controller
+	results     adt.Conjunct
 }
 
 // Context reports the Controller's Context.
@@ -339,13 +376,17 @@ func (t *Task) vertex() *adt.Vertex {
 	return x
 }
 
-func (t *Task) addDep(path string, dep *Task) {
+func (t *Task) addDep(path string, pos token.Pos, dep *Task) {
 	if dep == nil || dep == t {
 		return
 	}
 	if t.deps == nil {
 		t.deps = map[*Task]bool{}
 		t.pathDeps = map[string][]*Task{}
+		t.pathPos = map[string]token.Pos{}
+	}
+	if !t.pathPos[path].IsValid() {
+		t.pathPos[path] = pos
 	}
 
 	// Add the dependencies for a given path to the controller. We could compute
@@ -374,17 +415,20 @@ func (t *Task) addDep(path string, dep *Task) {
 //
 // This method may currently only be called by the runner.
 func (t *Task) Fill(x interface{}) error {
-	expr := convert.GoValueToExpr(t.ctxt, true, x)
+	t.fill(convert.GoValueToExpr(t.ctxt, true, x))
+	return nil
+}
+
+func (t *Task) fill(expr adt.Expr) {
 	if t.update == nil {
 		t.update = expr
-		return nil
+		return
 	}
 	t.update = &adt.BinaryExpr{
 		Op: adt.AndOp,
 		X:  t.update,
 		Y:  expr,
 	}
-	return nil
 }
 
 // Value reports the latest value of this task.
@@ -422,6 +466,33 @@ func (t *Task) Err() error {
 	return t.err
 }
 
+// Cached reports whether the results of the Task were obtained from the
+// Cache rather than by running it.
+//
+// This method may currently only be called after a Task completed, or from
+// within a call to UpdateFunc.
+func (t *Task) Cached() bool {
+	return t.cached
+}
+
+// Parent reports the task that runs t as part of a Subflow, or nil if t is
+// not a subtask.
+func (t *Task) Parent() *Task {
+	return t.c.parent
+}
+
+// Subtasks reports the tasks of the Subflow run by t, or nil if t is not run
+// by a Subflow or has not yet started.
+//
+// This method may currently only be called after a Task completed, or from
+// within a call to UpdateFunc.
+func (t *Task) Subtasks() []*Task {
+	if t.sub == nil {
+		return nil
+	}
+	return t.sub.tasks
+}
+
 // State is the current state of the Task.
 //
 // This method may currently only be called before Run is called or after a
@@ -22,13 +22,14 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/cuetxtar"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/internal/cuetest"
-	"cuelang.org/go/internal/cuetxtar"
 	"cuelang.org/go/tools/flow"
 )
 
@@ -78,6 +79,10 @@ func TestFlow(t *testing.T) {
 			UpdateFunc:     updateFunc,
 		}
 
+		if p, ok := t.Value("Output"); ok {
+			cfg.Output = cue.ParsePath(p)
+		}
+
 		c := flow.New(cfg, v, taskFunc)
 
 		w := t.Writer("errors")
@@ -113,6 +118,16 @@ func taskFunc(v cue.Value) (flow.Runner, error) {
 			return nil
 		}), nil
 
+	case "strict":
+		return flow.RunnerFunc(func(t *flow.Task) error {
+			str, err := t.Value().Lookup("val").String()
+			if err != nil {
+				return err
+			}
+			t.Fill(map[string]string{"out": str})
+			return nil
+		}), nil
+
 	case "failure":
 		return flow.RunnerFunc(func(t *flow.Task) error {
 			return errors.New("failure")
@@ -123,6 +138,9 @@ func taskFunc(v cue.Value) (flow.Runner, error) {
 			return flow.ErrAbort
 		}), nil
 
+	case "sub":
+		return flow.Subflow(&flow.Config{MaxParallel: 1}, taskFunc), nil
+
 	case "list":
 		return flow.RunnerFunc(func(t *flow.Task) error {
 			t.Fill(map[string][]int{"out": []int{1, 2}})
@@ -184,10 +202,175 @@ func mermaidGraph(c *flow.Controller) string {
 		for _, t := range t.Dependencies() {
 			fmt.Fprintf(w, "  t%d-->t%d\n", i, t.Index())
 		}
+		if sub := t.Subtasks(); len(sub) > 0 {
+			fmt.Fprintf(w, "  subgraph t%ds\n", i)
+			for j, s := range sub {
+				fmt.Fprintf(w, "    t%ds%d(\"%s [%s]\")\n", i, j, s.Path(), s.State())
+				for _, s := range s.Dependencies() {
+					fmt.Fprintf(w, "    t%ds%d-->t%ds%d\n", i, j, i, s.Index())
+				}
+			}
+			fmt.Fprintln(w, "  end")
+		}
 	}
 	return w.String()
 }
 
+func TestPolicy(t *testing.T) {
+	const src = `
+	root: {
+		a: $id: "flaky"
+		b: $id: "flaky"
+		c: $id: "flaky"
+	}
+	`
+	testCases := []struct {
+		name        string
+		cfg         flow.Config
+		failures    int
+		maxParallel int
+		err         bool
+	}{{
+		name:        "unlimited",
+		maxParallel: 3,
+	}, {
+		name:        "limited",
+		cfg:         flow.Config{MaxParallel: 2},
+		maxParallel: 2,
+	}, {
+		name:        "retried",
+		cfg:         flow.Config{MaxParallel: 1, Retries: 2},
+		failures:    2,
+		maxParallel: 1,
+	}, {
+		name:        "retries exhausted",
+		cfg:         flow.Config{MaxParallel: 1, Retries: 1},
+		failures:    2,
+		maxParallel: 1,
+		err:         true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var mu sync.Mutex
+			running, max := 0, 0
+			attempts := map[string]int{}
+
+			cfg := tc.cfg
+			cfg.Root = cue.ParsePath("root")
+			v := cuecontext.New().CompileString(src)
+			c := flow.New(&cfg, v, func(v cue.Value) (flow.Runner, error) {
+				if !v.LookupPath(cue.ParsePath("$id")).Exists() {
+					return nil, nil
+				}
+				return flow.RunnerFunc(func(t *flow.Task) error {
+					mu.Lock()
+					running++
+					if running > max {
+						max = running
+					}
+					attempts[t.Path().String()]++
+					n := attempts[t.Path().String()]
+					mu.Unlock()
+
+					// Give other tasks the opportunity to start.
+					time.Sleep(20 * time.Millisecond)
+
+					mu.Lock()
+					running--
+					mu.Unlock()
+
+					if n <= tc.failures {
+						return errors.New("flaky")
+					}
+					return nil
+				}), nil
+			})
+			err := c.Run(context.Background())
+			if got := err != nil; got != tc.err {
+				t.Errorf("got error %v; want error: %v", err, tc.err)
+			}
+			if max != tc.maxParallel {
+				t.Errorf("got %d parallel tasks; want %d", max, tc.maxParallel)
+			}
+		})
+	}
+}
+
+func TestTrigger(t *testing.T) {
+	const src = `
+	root: {
+		tick: {
+			$id: "tick"
+			n:   int
+		}
+		once: {
+			$id: "once"
+			out: string
+		}
+		echo: {
+			$id:  "echo"
+			in:   tick.n
+			once: once.out
+		}
+	}
+	`
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ticks := 0
+
+	// The once and echo tasks may run concurrently.
+	var mu sync.Mutex
+	var echoed []int64
+	runs := map[string]int{}
+
+	cfg := &flow.Config{Root: cue.ParsePath("root")}
+	v := cuecontext.New().CompileString(src)
+	c := flow.New(cfg, v, func(v cue.Value) (flow.Runner, error) {
+		switch id, _ := v.LookupPath(cue.ParsePath("$id")).String(); id {
+		case "tick":
+			return flow.Trigger(flow.RunnerFunc(func(t *flow.Task) error {
+				if ticks == 3 {
+					cancel()
+					<-t.Context().Done()
+					return t.Context().Err()
+				}
+				ticks++
+				return t.Fill(map[string]int{"n": ticks})
+			})), nil
+
+		case "once", "echo":
+			return flow.RunnerFunc(func(t *flow.Task) error {
+				mu.Lock()
+				runs[id]++
+				mu.Unlock()
+				if id == "once" {
+					return t.Fill(map[string]string{"out": "once"})
+				}
+				n, err := t.Value().LookupPath(cue.ParsePath("in")).Int64()
+				if err != nil {
+					return err
+				}
+				mu.Lock()
+				echoed = append(echoed, n)
+				mu.Unlock()
+				return nil
+			}), nil
+		}
+		return nil, nil
+	})
+	if err := c.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := fmt.Sprint(echoed), "[1 2 3]"; got != want {
+		t.Errorf("got echoed values %v; want %v", got, want)
+	}
+	if got, want := fmt.Sprint(runs), "map[echo:3 once:1]"; got != want {
+		t.Errorf("got runs %v; want %v", got, want)
+	}
+}
+
 // DO NOT REMOVE: for testing purposes.
 func TestX(t *testing.T) {
 	in := `
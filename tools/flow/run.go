@@ -26,6 +26,9 @@ package flow
 // future tasks may be long running, as discussed above.
 
 import (
+	"sort"
+
+	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/internal/core/adt"
 	"cuelang.org/go/internal/core/eval"
@@ -39,6 +42,7 @@ func (c *Controller) runLoop() {
 	n := len(root.Conjuncts)
 	c.conjuncts = make([]adt.Conjunct, n, n+len(c.tasks))
 	copy(c.conjuncts, root.Conjuncts)
+	c.numInit = n
 
 	c.markReady(nil)
 
@@ -49,6 +53,13 @@ func (c *Controller) runLoop() {
 		waiting := false
 		running := false
 
+		active := 0
+		for _, t := range c.tasks {
+			if t.state == Running {
+				active++
+			}
+		}
+
 		// Mark tasks as Ready.
 		for _, t := range c.tasks {
 			switch t.state {
@@ -58,13 +69,19 @@ func (c *Controller) runLoop() {
 			case Ready:
 				running = true
 
+				if max := c.cfg.MaxParallel; max > 0 && active >= max {
+					// Retry once a running task completes.
+					continue
+				}
+				active++
+
 				t.state = Running
 				c.updateTaskValue(t)
 
 				t.ctxt = eval.NewContext(value.ToInternal(t.v))
 
 				go func(t *Task) {
-					if err := t.r.Run(t, nil); err != nil {
+					if err := c.runTask(t); err != nil {
 						t.err = errors.Promote(err, "task failed")
 					}
 
@@ -103,7 +120,7 @@ func (c *Controller) runLoop() {
 				fallthrough
 
 			default:
-				c.addErr(t.err, "task failure")
+				c.addErr(errors.Append(t.err, c.explain(t)), "task failure")
 				return
 			}
 
@@ -116,11 +133,31 @@ func (c *Controller) runLoop() {
 
 			c.updateTaskValue(t)
 
+			if err := c.checkOutput(t); err != nil {
+				c.addErr(err, "invalid task output")
+				return
+			}
+
 			c.markReady(t)
+			c.rearm()
 		}
 	}
 }
 
+// runTask runs t, retrying it up to Config.Retries times if it fails.
+func (c *Controller) runTask(t *Task) error {
+	err := c.runCached(t)
+	for i := 0; i < c.cfg.Retries; i++ {
+		if err == nil || errors.Is(err, ErrAbort) || t.Context().Err() != nil {
+			break
+		}
+		// Discard the results of the failed attempt.
+		t.update = nil
+		err = c.runCached(t)
+	}
+	return err
+}
+
 func (c *Controller) markReady(t *Task) {
 	for _, x := range c.tasks {
 		if x.state == Waiting && x.isReady() {
@@ -186,25 +223,119 @@ func (c *Controller) updateTaskResults(t *Task) bool {
 		return false
 	}
 
-	expr := t.update
-	for i := len(t.labels) - 1; i >= 0; i-- {
+	// The results of the subtasks of a Subflow are also results of the task
+	// that runs it.
+	if p := c.parent; p != nil {
+		p.fill(wrap(t.update, t.labels[len(p.labels):]))
+	}
+
+	expr := wrap(t.update, t.labels)
+
+	t.update = nil
+
+	// TODO: replace rather than add conjunct if this task already added a
+	// conjunct before. This will allow for serving applications.
+	t.results = adt.MakeRootConjunct(c.env, expr)
+	c.conjuncts = append(c.conjuncts, t.results)
+	c.conjunctSeq++
+	t.conjunctSeq = c.conjunctSeq
+
+	return true
+}
+
+// wrap returns expr as the value of the field at the path of the given labels.
+func wrap(expr adt.Expr, labels []adt.Feature) adt.Expr {
+	for i := len(labels) - 1; i >= 0; i-- {
 		expr = &adt.StructLit{
 			Decls: []adt.Decl{
 				&adt.Field{
-					Label: t.labels[i],
+					Label: labels[i],
 					Value: expr,
 				},
 			},
 		}
 	}
+	return expr
+}
 
-	t.update = nil
+// checkOutput checks the value of the completed task t against the
+// constraints declared for it at Config.Output, if any.
+func (c *Controller) checkOutput(t *Task) errors.Error {
+	if len(c.cfg.Output.Selectors()) == 0 {
+		return nil
+	}
+	rel, ok := relPath(t.path, c.cfg.Root)
+	if !ok {
+		return nil
+	}
+	decl := c.inst.LookupPath(c.cfg.Output).LookupPath(cue.MakePath(rel...))
+	if !decl.Exists() {
+		return nil
+	}
+	return c.checkDecl(t.v, decl)
+}
 
-	// TODO: replace rather than add conjunct if this task already added a
-	// conjunct before. This will allow for serving applications.
-	c.conjuncts = append(c.conjuncts, adt.MakeRootConjunct(c.env, expr))
-	c.conjunctSeq++
-	t.conjunctSeq = c.conjunctSeq
+// checkDecl checks v against the constraints of decl. Structs are checked
+// field by field, so that the resulting values of the fields of v, rather than
+// all the disjuncts from which they were computed, are checked.
+func (c *Controller) checkDecl(v, decl cue.Value) (errs errors.Error) {
+	if decl.IncompleteKind() == cue.StructKind && v.IncompleteKind() == cue.StructKind {
+		for iter, _ := decl.Fields(); iter.Next(); {
+			x := v.LookupPath(cue.MakePath(iter.Selector()))
+			errs = errors.Append(errs, c.checkDecl(x, iter.Value()))
+		}
+		return errs
+	}
+	x := v
+	if d, ok := x.Default(); ok {
+		x = d
+	}
+	if x.IsConcrete() {
+		_, n := value.ToInternal(x)
+		x = value.Make(c.opCtx, n.Value())
+	}
+	if err := x.Unify(decl).Validate(); err != nil {
+		return errors.Wrapf(errors.Promote(err, ""), v.Pos(),
+			"%v: output does not match declaration", v.Path())
+	}
+	return nil
+}
 
-	return true
+// explain reports the inputs of the failed task t that are incomplete
+// because the tasks on which t depends did not set them. As these tasks may
+// in turn have been unable to set them because of their own incomplete
+// inputs, these are reported as well, up to the tasks that are the cause.
+// This distinguishes errors that result from missing task results from
+// genuine configuration errors.
+func (c *Controller) explain(t *Task) (errs errors.Error) {
+	seen := map[*Task]bool{t: true}
+	for queue := []*Task{t}; len(queue) > 0; queue = queue[1:] {
+		t := queue[0]
+
+		paths := make([]string, 0, len(t.pathDeps))
+		for p := range t.pathDeps {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+
+		for _, p := range paths {
+			v := c.inst.LookupPath(cue.ParsePath(p))
+			if !v.Exists() || v.Validate(cue.Concrete(true)) == nil {
+				continue
+			}
+			for _, d := range t.pathDeps[p] {
+				state := "was not set by"
+				if !d.done() {
+					state = "is waiting for"
+				}
+				errs = errors.Append(errs, errors.Newf(t.pathPos[p],
+					"%v: incomplete input %s %s task %v", t.path, p, state, d.path))
+				if !seen[d] {
+					seen[d] = true
+					queue = append(queue, d)
+				}
+			}
+		}
+	}
+	return errs
 }
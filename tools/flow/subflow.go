@@ -0,0 +1,63 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+// This file contains the logic for composing workflows out of subflows.
+
+import (
+	"cuelang.org/go/cue"
+	"cuelang.org/go/internal/value"
+)
+
+// Subflow returns a Runner for a task that runs the tasks defined within its
+// value, as determined by f, as a workflow of its own. The task completes
+// once all of its subtasks have completed and fails if any of them fails.
+//
+// The tasks of the subflow are run according to cfg, which allows a subflow to
+// have, for instance, its own MaxParallel and Retries settings. The Root of
+// cfg is ignored: it is set to the path of the task.
+//
+// For the enclosing workflow, the subflow is a single task: other tasks that
+// refer to any of its subtasks depend on the subflow as a whole. Use
+// Task.Subtasks to inspect the dependency graph of the subflow itself.
+func Subflow(cfg *Config, f TaskFunc) Runner {
+	s := &subflow{f: f}
+	if cfg != nil {
+		s.cfg = *cfg
+	}
+	return s
+}
+
+type subflow struct {
+	cfg Config
+	f   TaskFunc
+}
+
+func (s *subflow) Run(t *Task, err error) error {
+	cfg := s.cfg
+	cfg.Root = t.path
+
+	t.sub = newController(&cfg, t.root(), s.f, t)
+	return t.sub.Run(t.Context())
+}
+
+// root returns the configuration of which the value of t is part.
+func (t *Task) root() cue.Value {
+	n := t.vertex()
+	for n.Parent != nil {
+		n = n.Parent
+	}
+	return value.Make(t.ctxt, n)
+}
@@ -20,6 +20,7 @@ package flow
 import (
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal/core/adt"
 	"cuelang.org/go/internal/core/dep"
 	"cuelang.org/go/internal/value"
@@ -40,8 +41,18 @@ func (c *Controller) initTasks() {
 		return
 	}
 
-	// Mark any task that is located under the root.
-	c.findRootTasks(v)
+	// Mark any task that is located under the root. The root of a subflow is
+	// the task that runs it and is skipped, as are its non-struct fields,
+	// which configure this task rather than define subtasks.
+	if c.parent != nil {
+		for iter, _ := v.Fields(); iter.Next(); {
+			if x := iter.Value(); x.IncompleteKind() == cue.StructKind {
+				c.findRootTasks(x)
+			}
+		}
+	} else {
+		c.findRootTasks(v)
+	}
 
 	// Mark any tasks that are implied by dependencies.
 	// Note that the list of tasks may grow as this loop progresses.
@@ -62,6 +73,10 @@ func (c *Controller) initTasks() {
 
 // findRootTasks finds tasks under the root.
 func (c *Controller) findRootTasks(v cue.Value) {
+	if c.isOutput(v) {
+		return
+	}
+
 	t := c.getTask(nil, v)
 
 	if t != nil {
@@ -225,8 +240,12 @@ func (c *Controller) markTaskDependencies(t *Task, n *adt.Vertex) {
 		depTask := c.findImpliedTask(d)
 		if depTask != nil {
 			if depTask != cycleMarker {
+				var pos token.Pos
+				if src := d.Reference.Source(); src != nil {
+					pos = src.Pos()
+				}
 				v := value.Make(c.opCtx, d.Node)
-				t.addDep(v.Path().String(), depTask)
+				t.addDep(v.Path().String(), pos, depTask)
 			}
 			return nil
 		}
@@ -245,17 +264,33 @@ func (c *Controller) markTaskDependencies(t *Task, n *adt.Vertex) {
 }
 
 func (c *Controller) inRoot(n *adt.Vertex) bool {
-	path := value.Make(c.opCtx, n).Path().Selectors()
-	root := c.cfg.Root.Selectors()
-	if len(path) < len(root) {
+	_, ok := relPath(value.Make(c.opCtx, n).Path(), c.cfg.Root)
+	return ok
+}
+
+// isOutput reports whether v is the declaration of the task results.
+func (c *Controller) isOutput(v cue.Value) bool {
+	if len(c.cfg.Output.Selectors()) == 0 {
 		return false
 	}
-	for i, sel := range root {
+	rel, ok := relPath(v.Path(), c.cfg.Output)
+	return ok && len(rel) == 0
+}
+
+// relPath reports the selectors of p relative to root, or false if p is not
+// within root.
+func relPath(p, root cue.Path) ([]cue.Selector, bool) {
+	path := p.Selectors()
+	prefix := root.Selectors()
+	if len(path) < len(prefix) {
+		return nil, false
+	}
+	for i, sel := range prefix {
 		if path[i] != sel {
-			return false
+			return nil, false
 		}
 	}
-	return true
+	return path[len(prefix):], true
 }
 
 var cycleMarker = &Task{}
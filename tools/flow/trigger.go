@@ -0,0 +1,107 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package flow
+
+// This file contains the logic for rerunning the parts of a workflow that
+// depend on trigger tasks.
+
+import (
+	"cuelang.org/go/internal/core/adt"
+)
+
+// Trigger returns a Runner for a task that waits for an external event, such
+// as a timer expiring, a file changing, or a request coming in, by running r.
+// The results filled in by r, typically the details of the event, are passed
+// to the tasks that depend on the trigger task as usual.
+//
+// Unlike other tasks, a trigger task is run again to wait for the next event
+// once no other tasks are ready or running, apart from other trigger tasks.
+// Each time, the tasks that depend on it, directly or indirectly, are run
+// again as well, on a configuration from which the results of the previous
+// run of these tasks have been removed. A workflow with trigger tasks thus
+// runs until its context is canceled or one of its tasks fails.
+func Trigger(r Runner) Runner {
+	return trigger{r}
+}
+
+type trigger struct {
+	Runner
+}
+
+func (t *Task) isTrigger() bool {
+	_, ok := t.r.(trigger)
+	return ok
+}
+
+// rearm resets the trigger tasks that have completed, and the tasks that
+// depend on them, once the workflow is otherwise idle.
+func (c *Controller) rearm() {
+	if c.errs != nil {
+		return
+	}
+	reset := map[*Task]bool{}
+	for _, t := range c.tasks {
+		switch {
+		case t.state == Ready, t.state == Running && !t.isTrigger():
+			return
+		case t.state == Terminated && t.isTrigger():
+			reset[t] = true
+		}
+	}
+	if len(reset) == 0 {
+		return
+	}
+
+	// Add the tasks that depend on the fired triggers.
+	for changed := true; changed; {
+		changed = false
+		for _, t := range c.tasks {
+			if reset[t] {
+				continue
+			}
+			for _, d := range t.depTasks {
+				if reset[d] {
+					reset[t] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	for t := range reset {
+		t.state = Waiting
+		t.update = nil
+		t.results = adt.Conjunct{}
+		t.err = nil
+		t.cached = false
+		t.sub = nil
+		t.valueSeq = -1
+	}
+
+	// Recompute the configuration without the results of the reset tasks.
+	conjuncts := append([]adt.Conjunct(nil), c.conjuncts[:c.numInit]...)
+	for _, t := range c.tasks {
+		if t.results.Field() != nil {
+			conjuncts = append(conjuncts, t.results)
+		}
+	}
+	c.conjuncts = conjuncts
+	c.conjunctSeq++
+	c.updateValue()
+	c.initTasks()
+
+	c.markReady(nil)
+}
@@ -0,0 +1,217 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package infer generates schemas from example data.
+//
+// The schema of a set of samples is the most specific schema, within the
+// limits of the Config, that all samples satisfy. For instance, the samples
+//
+//	{name: "web", port: 80, tags: ["a"]}
+//	{name: "db", port: 5432, debug: true}
+//
+// result in the schema
+//
+//	{
+//		name: string
+//		port: int
+//		tags?: [...string]
+//		debug?: bool
+//	}
+//
+// Fields that do not occur in all samples are optional. Values of different
+// kinds result in a disjunction, and lists allow any number of elements that
+// satisfy the schema of all observed elements.
+package infer
+
+import (
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// A Config defines how strictly a schema describes its samples.
+type Config struct {
+	// Open allows fields other than the observed ones by ending each
+	// struct with "...". By default, structs only allow the observed
+	// fields when the schema is used as a definition.
+	Open bool
+
+	// Literals describes scalars with a disjunction of the observed values,
+	// instead of with their type.
+	Literals bool
+}
+
+// Schema returns a CUE expression that generalizes the given samples. It
+// reports an error if a sample is not concrete.
+func Schema(samples []cue.Value, c *Config) (ast.Expr, error) {
+	if c == nil {
+		c = &Config{}
+	}
+	s := &shape{}
+	for _, v := range samples {
+		if err := v.Validate(cue.Concrete(true)); err != nil {
+			return nil, errors.Wrapf(err, v.Pos(), "infer: sample is not concrete")
+		}
+		s.add(v)
+	}
+	if s.count == 0 {
+		return nil, errors.Newf(token.NoPos, "infer: no samples")
+	}
+	return s.expr(c), nil
+}
+
+// File returns a file that defines the schema of the given samples as the
+// definition with the given name, which must start with #.
+func File(name string, samples []cue.Value, c *Config) (*ast.File, error) {
+	x, err := Schema(samples, c)
+	if err != nil {
+		return nil, err
+	}
+	return &ast.File{Decls: []ast.Decl{
+		&ast.Field{Label: ast.NewIdent(name), Value: x},
+	}}, nil
+}
+
+// A shape accumulates the values observed at a position within the
+// samples.
+type shape struct {
+	count int
+	kinds cue.Kind
+
+	// literals holds the distinct scalar values in the order observed.
+	literals []ast.Expr
+	seen     map[string]bool
+
+	// structs is the number of structs observed, fields the shapes of
+	// their fields in the order observed.
+	structs int
+	fields  []*field
+
+	// elem is the shape of the elements of all observed lists.
+	elem *shape
+}
+
+type field struct {
+	name  string
+	shape *shape
+}
+
+func (s *shape) add(v cue.Value) {
+	s.count++
+	k := v.Kind()
+	s.kinds |= k
+	switch k {
+	case cue.StructKind:
+		s.structs++
+		iter, _ := v.Fields()
+		for iter.Next() {
+			s.field(iter.Label()).add(iter.Value())
+		}
+
+	case cue.ListKind:
+		if s.elem == nil {
+			s.elem = &shape{}
+		}
+		iter, _ := v.List()
+		for iter.Next() {
+			s.elem.add(iter.Value())
+		}
+
+	default:
+		key := fmt.Sprint(v)
+		if s.seen == nil {
+			s.seen = map[string]bool{}
+		}
+		if !s.seen[key] {
+			s.seen[key] = true
+			if x, ok := v.Syntax().(ast.Expr); ok {
+				s.literals = append(s.literals, x)
+			}
+		}
+	}
+}
+
+func (s *shape) field(name string) *shape {
+	for _, f := range s.fields {
+		if f.name == name {
+			return f.shape
+		}
+	}
+	f := &field{name: name, shape: &shape{}}
+	s.fields = append(s.fields, f)
+	return f.shape
+}
+
+func (s *shape) expr(c *Config) ast.Expr {
+	var a []ast.Expr
+	if s.kinds&cue.StructKind != 0 {
+		a = append(a, s.structExpr(c))
+	}
+	if s.kinds&cue.ListKind != 0 {
+		elem := &ast.Ellipsis{}
+		if s.elem.count > 0 {
+			elem.Type = s.elem.expr(c)
+		}
+		a = append(a, ast.NewList(elem))
+	}
+	if c.Literals {
+		a = append(a, s.literals...)
+	} else {
+		a = append(a, s.types()...)
+	}
+	if len(a) == 0 {
+		return ast.NewIdent("_")
+	}
+	return ast.NewBinExpr(token.OR, a...)
+}
+
+// types returns the types of the observed scalars.
+func (s *shape) types() (a []ast.Expr) {
+	switch k := s.kinds & cue.NumberKind; k {
+	case cue.IntKind:
+		a = append(a, ast.NewIdent("int"))
+	case cue.FloatKind:
+		a = append(a, ast.NewIdent("float"))
+	case cue.NumberKind:
+		a = append(a, ast.NewIdent("number"))
+	}
+	for _, k := range []cue.Kind{cue.StringKind, cue.BytesKind, cue.BoolKind, cue.NullKind} {
+		if s.kinds&k != 0 {
+			a = append(a, ast.NewIdent(k.String()))
+		}
+	}
+	return a
+}
+
+func (s *shape) structExpr(c *Config) ast.Expr {
+	st := &ast.StructLit{}
+	for _, f := range s.fields {
+		var label ast.Label = ast.NewString(f.name)
+		if ast.IsValidIdent(f.name) && f.name[0] != '#' && f.name[0] != '_' {
+			label = ast.NewIdent(f.name)
+		}
+		field := &ast.Field{Label: label, Value: f.shape.expr(c)}
+		if f.shape.count < s.structs {
+			field.Optional = token.NoSpace.Pos()
+		}
+		st.Elts = append(st.Elts, field)
+	}
+	if c.Open {
+		st.Elts = append(st.Elts, &ast.Ellipsis{})
+	}
+	return st
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package infer
+
+import (
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/format"
+)
+
+func TestSchema(t *testing.T) {
+	testCases := []struct {
+		name    string
+		samples []string
+		config  Config
+		want    string
+	}{{
+		name: "required and optional fields",
+		samples: []string{
+			`{name: "web", port: 80, tags: ["a"]}`,
+			`{name: "db", port: 5432, debug: true}`,
+		},
+		want: `{
+	name: string
+	port: int
+	tags?: [...string]
+	debug?: bool
+}`,
+	}, {
+		name: "widened kinds",
+		samples: []string{
+			`{a: 1, b: 1, c: "x", d: [1, "x"], e: []}`,
+			`{a: 1.5, b: null, c: {x: 1}, d: [{y: 2}], e: []}`,
+		},
+		want: `{
+	a: number
+	b: int | null
+	c: {
+		x: int
+	} | string
+	d: [...{
+		y: int
+	} | int | string]
+	e: [...]
+}`,
+	}, {
+		name: "literals",
+		samples: []string{
+			`{kind: "Deployment", replicas: 1}`,
+			`{kind: "StatefulSet", replicas: 1}`,
+			`{kind: "Deployment", replicas: -2}`,
+		},
+		config: Config{Literals: true},
+		want: `{
+	kind:     "Deployment" | "StatefulSet"
+	replicas: 1 | -2
+}`,
+	}, {
+		name: "open",
+		samples: []string{
+			`{a: {b: 1}}`,
+		},
+		config: Config{Open: true},
+		want: `{
+	a: {
+		b: int
+		...
+	}
+	...
+}`,
+	}, {
+		name: "labels",
+		samples: []string{
+			`{"a-b": 1, "#c": 2, "_d": 3}`,
+		},
+		want: `{
+	"a-b": int
+	"#c":  int
+	"_d":  int
+}`,
+	}, {
+		name: "incomplete",
+		samples: []string{
+			`{a: int}`,
+		},
+		want: `infer: sample is not concrete: a: incomplete value int`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := cuecontext.New()
+			var samples []cue.Value
+			for _, s := range tc.samples {
+				samples = append(samples, ctx.CompileString(s))
+			}
+			x, err := Schema(samples, &tc.config)
+			if err != nil {
+				if got := err.Error(); got != tc.want {
+					t.Errorf("got error %q; want %q", got, tc.want)
+				}
+				return
+			}
+			b, err := format.Node(x)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(b); got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+
+			// All samples must be instances of the schema.
+			schema := ctx.CompileString("#Schema: " + string(b)).LookupPath(cue.ParsePath("#Schema"))
+			for _, v := range samples {
+				if err := schema.Unify(v).Validate(cue.Concrete(true)); err != nil {
+					t.Errorf("sample %v does not satisfy schema: %v", v, err)
+				}
+			}
+		})
+	}
+}
+
+func TestFile(t *testing.T) {
+	v := cuecontext.New().CompileString(`{a: 1}`)
+	f, err := File("#Config", []cue.Value{v}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := format.Node(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "#Config: {\n\ta: int\n}\n"
+	if got := string(b); strings.TrimSpace(got) != strings.TrimSpace(want) {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
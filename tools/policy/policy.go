@@ -0,0 +1,256 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package policy applies a bundle of CUE constraints to data and reports
+// violations in terms of the rules that define them.
+//
+// A policy bundle is an ordinary CUE value. Constraints in the bundle are
+// annotated with a policy attribute to associate them with a rule:
+//
+//	spec: containers: [...{
+//		securityContext: privileged: false @policy(id=SEC001, severity=error,
+//			msg="containers must not run privileged")
+//	}]
+//
+// The attribute may be specified as a field attribute or as a declaration
+// attribute within a struct, in which case it applies to all constraints in
+// that struct. The following keys are recognized:
+//
+//	id        a unique identifier of the rule
+//	severity  one of error (default), warning, or info
+//	msg       a human-readable description of the rule
+//
+// Violations of constraints that are not covered by a rule are reported
+// with an empty ID and severity error.
+package policy
+
+import (
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// Severity indicates the importance of a rule.
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+	Info    Severity = "info"
+)
+
+// A Rule describes a single policy constraint.
+type Rule struct {
+	ID       string
+	Severity Severity
+	Msg      string
+
+	// Path is the location of the rule within the bundle.
+	Path cue.Path
+	Pos  token.Pos
+}
+
+// A Bundle is a set of policy rules.
+type Bundle struct {
+	v     cue.Value
+	rules []*Rule
+}
+
+// NewBundle creates a Bundle from the constraints in v. It reports an error if
+// v is invalid or if any of the policy attributes are malformed.
+func NewBundle(v cue.Value) (*Bundle, error) {
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	b := &Bundle{v: v}
+	var errs errors.Error
+	walk(v, func(v cue.Value) {
+		r, err := parseRule(v)
+		if err != nil {
+			errs = errors.Append(errs, errors.Promote(err, ""))
+			return
+		}
+		if r != nil {
+			b.rules = append(b.rules, r)
+		}
+	})
+	if errs != nil {
+		return nil, errs
+	}
+	return b, nil
+}
+
+// Rules reports the rules defined in b in depth-first order. Rules that are
+// defined within pattern constraints or list element types are not included.
+func (b *Bundle) Rules() []*Rule {
+	return b.rules
+}
+
+// walk visits v and all of its regular, optional, and definition fields.
+func walk(v cue.Value, f func(v cue.Value)) {
+	f(v)
+	iter, err := v.Fields(cue.Optional(true), cue.Definitions(true))
+	if err != nil {
+		return
+	}
+	for iter.Next() {
+		walk(iter.Value(), f)
+	}
+}
+
+func parseRule(v cue.Value) (*Rule, error) {
+	for _, a := range v.Attributes(cue.ValueAttr) {
+		if a.Name() != "policy" {
+			continue
+		}
+		if err := a.Err(); err != nil {
+			return nil, err
+		}
+		r := &Rule{Severity: Error, Path: v.Path(), Pos: v.Pos()}
+		for i := 0; i < a.NumArgs(); i++ {
+			key, value := a.Arg(i)
+			switch key {
+			case "id":
+				r.ID = value
+			case "msg":
+				r.Msg = value
+			case "severity":
+				switch s := Severity(value); s {
+				case Error, Warning, Info:
+					r.Severity = s
+				default:
+					return nil, errors.Newf(v.Pos(),
+						"invalid severity %q for policy rule %s", value, r.ID)
+				}
+			default:
+				return nil, errors.Newf(v.Pos(),
+					"unknown key %q in policy attribute", key)
+			}
+		}
+		return r, nil
+	}
+	return nil, nil
+}
+
+// A Violation describes the failure of data to satisfy a policy rule.
+//
+// A Violation implements errors.Error. Its message includes the severity,
+// rule ID, and rule description.
+type Violation struct {
+	// Rule is the violated rule or nil if the violated constraint is not
+	// covered by a rule.
+	Rule *Rule
+
+	// Err is the underlying evaluation error.
+	Err errors.Error
+}
+
+var _ errors.Error = &Violation{}
+
+// ID reports the ID of the violated rule.
+func (v *Violation) ID() string {
+	if v.Rule == nil {
+		return ""
+	}
+	return v.Rule.ID
+}
+
+// Severity reports the severity of the violated rule.
+func (v *Violation) Severity() Severity {
+	if v.Rule == nil {
+		return Error
+	}
+	return v.Rule.Severity
+}
+
+// Message reports the rule description or, if absent, the message of the
+// underlying error.
+func (v *Violation) Message() string {
+	if v.Rule == nil || v.Rule.Msg == "" {
+		format, args := v.Err.Msg()
+		return fmt.Sprintf(format, args...)
+	}
+	return v.Rule.Msg
+}
+
+func (v *Violation) Position() token.Pos         { return v.Err.Position() }
+func (v *Violation) InputPositions() []token.Pos { return v.Err.InputPositions() }
+func (v *Violation) Path() []string              { return v.Err.Path() }
+
+func (v *Violation) Msg() (format string, args []interface{}) {
+	if id := v.ID(); id != "" {
+		return "%s %s: %s", []interface{}{v.Severity(), id, v.Message()}
+	}
+	return "%s: %s", []interface{}{v.Severity(), v.Message()}
+}
+
+func (v *Violation) Error() string {
+	format, args := v.Msg()
+	return fmt.Sprintf(format, args...)
+}
+
+// Evaluate applies the rules of b to data and reports all violations.
+// Multiple violations of the same rule at the same path are reported once.
+func (b *Bundle) Evaluate(data cue.Value) []*Violation {
+	u := b.v.Unify(data)
+	err := u.Validate()
+	if err == nil {
+		return nil
+	}
+
+	type key struct {
+		id   string
+		path string
+	}
+	seen := map[key]bool{}
+
+	var a []*Violation
+	for _, e := range errors.Errors(err) {
+		r := b.ruleFor(u, e.Path())
+		if r != nil {
+			k := key{r.ID, fmt.Sprint(e.Path())}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+		}
+		a = append(a, &Violation{Rule: r, Err: e})
+	}
+	return a
+}
+
+// ruleFor finds the rule that applies to the given path by finding the
+// closest enclosing value annotated with a policy attribute.
+func (b *Bundle) ruleFor(v cue.Value, path []string) *Rule {
+	values := []cue.Value{v}
+	for _, s := range path {
+		p := cue.ParsePath(s)
+		if p.Err() != nil {
+			break
+		}
+		v = v.LookupPath(p)
+		if !v.Exists() {
+			break
+		}
+		values = append(values, v)
+	}
+	for i := len(values) - 1; i >= 0; i-- {
+		if r, _ := parseRule(values[i]); r != nil {
+			return r
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package policy
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue/cuecontext"
+)
+
+const bundle = `
+spec: {
+	replicas?: <=10 @policy(id=OPS001, severity=warning, msg="too many replicas")
+	containers?: [...{
+		privileged?: false @policy(id=SEC001, msg="containers must not run privileged")
+		image?: {
+			@policy(id=SEC002, severity=info)
+			=~"^registry.example.com/"
+		}
+	}]
+}
+metadata: name?: string
+`
+
+func TestRules(t *testing.T) {
+	ctx := cuecontext.New()
+	b, err := NewBundle(ctx.CompileString(bundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range b.Rules() {
+		got = append(got, fmt.Sprintf("%s %s %s %q", r.Path, r.ID, r.Severity, r.Msg))
+	}
+	want := `spec.replicas OPS001 warning "too many replicas"`
+	if s := strings.Join(got, "\n"); s != want {
+		t.Errorf("got:\n%s\nwant:\n%s", s, want)
+	}
+
+	_, err = NewBundle(ctx.CompileString(`a: int @policy(id=X, severity=fatal)`))
+	if err == nil {
+		t.Error("expected error for invalid severity")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	ctx := cuecontext.New()
+	b, err := NewBundle(ctx.CompileString(bundle))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		data string
+		want string
+	}{{
+		data: `spec: replicas: 3`,
+		want: ``,
+	}, {
+		data: `spec: replicas: 30`,
+		want: `spec.replicas: warning OPS001: too many replicas`,
+	}, {
+		data: `spec: containers: [{privileged: true}, {image: "docker.io/foo"}]`,
+		want: `spec.containers.0.privileged: error SEC001: containers must not run privileged
+spec.containers.1.image: info SEC002: invalid value "docker.io/foo" (out of bound =~"^registry.example.com/")`,
+	}, {
+		data: `metadata: name: 3`,
+		want: `metadata.name: error: conflicting values 3 and string (mismatched types int and string)`,
+	}}
+	for _, tc := range testCases {
+		t.Run("", func(t *testing.T) {
+			var got []string
+			for _, v := range b.Evaluate(ctx.CompileString(tc.data)) {
+				got = append(got, fmt.Sprintf("%s: %v", strings.Join(v.Path(), "."), v))
+			}
+			if s := strings.Join(got, "\n"); s != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", s, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provenance
+
+import (
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/core/eval"
+	"cuelang.org/go/internal/value"
+)
+
+// An Explanation describes how a value was derived.
+type Explanation struct {
+	// Conjuncts lists the expressions that were unified to obtain the value.
+	Conjuncts []Conjunct
+
+	// Default holds the default value that was selected, if any.
+	Default *cue.Value
+
+	// Result is the resulting value.
+	Result cue.Value
+}
+
+// A Conjunct describes a single expression that contributed to a value.
+type Conjunct struct {
+	Pos  token.Pos
+	Expr string
+
+	// Disjuncts lists the branches of the conjunct if it is a disjunction.
+	Disjuncts []Disjunct
+}
+
+// A Disjunct describes a single branch of a disjunction.
+type Disjunct struct {
+	Expr    string
+	Default bool
+
+	// Err reports why the branch was eliminated or is nil if the branch
+	// is compatible with the other conjuncts.
+	Err errors.Error
+}
+
+// Explain reports how v was derived from its conjuncts. A disjunction is
+// explained by unifying each of its branches with the remaining conjuncts.
+func Explain(v cue.Value) *Explanation {
+	e := &Explanation{Result: v}
+	if d, ok := v.Default(); ok && !v.IsConcrete() {
+		e.Default = &d
+	}
+
+	r, x := value.ToInternal(v)
+	if x == nil {
+		return e
+	}
+	for i, c := range x.Conjuncts {
+		src := c.Source()
+		if f, ok := src.(*ast.Field); ok {
+			src = f.Value
+		}
+		k := Conjunct{Expr: nodeString(src)}
+		if src != nil {
+			k.Pos = src.Pos()
+		}
+
+		if d, ok := c.Expr().(*adt.DisjunctionExpr); ok {
+			for _, b := range d.Values {
+				k.Disjuncts = append(k.Disjuncts, Disjunct{
+					Expr:    nodeString(b.Val.Source()),
+					Default: b.Default,
+					Err:     tryDisjunct(r, x, i, adt.MakeConjunct(c.Env, b.Val, c.CloseInfo)),
+				})
+			}
+		}
+		e.Conjuncts = append(e.Conjuncts, k)
+	}
+	return e
+}
+
+// tryDisjunct unifies the conjuncts of x, replacing the conjunct at position i
+// with c, and reports the resulting error, if any.
+func tryDisjunct(r adt.Runtime, x *adt.Vertex, i int, c adt.Conjunct) errors.Error {
+	n := &adt.Vertex{Parent: x.Parent, Label: x.Label}
+	for j, d := range x.Conjuncts {
+		if j == i {
+			d = c
+		}
+		n.AddConjunct(d)
+	}
+	ctx := eval.NewContext(r, nil)
+	n.Finalize(ctx)
+
+	if err := value.Make(ctx, n).Validate(); err != nil {
+		return errors.Promote(err, "")
+	}
+	return nil
+}
+
+func nodeString(n ast.Node) string {
+	if n == nil {
+		return "_"
+	}
+	b, err := format.Node(n)
+	if err != nil {
+		return fmt.Sprint(n)
+	}
+	s := string(b)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i] + " ..."
+	}
+	return s
+}
+
+// String returns a human-readable description of e.
+func (e *Explanation) String() string {
+	return e.Format(token.Pos.String)
+}
+
+// Format is like String, but formats positions using the given function.
+func (e *Explanation) Format(pos func(token.Pos) string) string {
+	b := &strings.Builder{}
+	fmt.Fprintln(b, "conjuncts:")
+	for _, c := range e.Conjuncts {
+		fmt.Fprintf(b, "    %s: %s\n", pos(c.Pos), c.Expr)
+		for _, d := range c.Disjuncts {
+			x := d.Expr
+			if d.Default {
+				x = "*" + x
+			}
+			if d.Err == nil {
+				fmt.Fprintf(b, "        %s: accepted\n", x)
+				continue
+			}
+			format, args := d.Err.Msg()
+			fmt.Fprintf(b, "        %s: eliminated: %s\n", x, fmt.Sprintf(format, args...))
+		}
+	}
+	if e.Default != nil {
+		fmt.Fprintf(b, "default: %s\n", nodeString(e.Default.Syntax(cue.Final())))
+	}
+	fmt.Fprintf(b, "result: %s\n", nodeString(e.Result.Syntax(cue.ResolveReferences(true))))
+	return b.String()
+}
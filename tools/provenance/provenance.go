@@ -0,0 +1,103 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package provenance reports where the values of an evaluated configuration
+// were defined.
+//
+// A value in CUE is the result of unifying all of the conjuncts that apply to
+// it, which may be spread across many files and packages. For instance, a
+// field may be declared in a schema, constrained in a policy file, and set in
+// a data file. Provenance information lists the positions of each of these
+// declarations. Explain goes further and shows how the conjuncts were
+// combined, including which branches of disjunctions were eliminated.
+package provenance
+
+import (
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/value"
+)
+
+// An Origin describes the sources of the value at a single path.
+type Origin struct {
+	// Path is the location of the value relative to the traced value.
+	Path cue.Path
+
+	// Positions lists the positions of the declarations that contributed to
+	// the value, in the order in which they were unified.
+	Positions []token.Pos
+}
+
+// Positions reports the positions of the declarations whose conjuncts
+// determined v. Each position is reported once.
+func Positions(v cue.Value) []token.Pos {
+	_, x := value.ToInternal(v)
+	if x == nil {
+		return nil
+	}
+	var a []token.Pos
+	seen := map[token.Pos]bool{}
+	for _, c := range x.Conjuncts {
+		src := c.Source()
+		if src == nil {
+			continue
+		}
+		pos := src.Pos()
+		if !pos.IsValid() || seen[pos] {
+			continue
+		}
+		seen[pos] = true
+		a = append(a, pos)
+	}
+	return a
+}
+
+// Trace reports the origins of v and all values nested within v, including
+// optional fields and definitions, in depth-first order.
+func Trace(v cue.Value) []Origin {
+	t := &tracer{}
+	t.trace(nil, v)
+	return t.origins
+}
+
+type tracer struct {
+	origins []Origin
+}
+
+func (t *tracer) trace(path []cue.Selector, v cue.Value) {
+	t.origins = append(t.origins, Origin{
+		Path:      cue.MakePath(append([]cue.Selector{}, path...)...),
+		Positions: Positions(v),
+	})
+
+	switch v.IncompleteKind() {
+	case cue.StructKind:
+		iter, err := v.Fields(cue.Optional(true), cue.Definitions(true))
+		if err != nil {
+			return
+		}
+		for iter.Next() {
+			t.trace(append(path, iter.Selector()), iter.Value())
+		}
+
+	case cue.ListKind:
+		iter, err := v.List()
+		if err != nil {
+			return
+		}
+		for i := 0; iter.Next(); i++ {
+			t.trace(append(path, cue.Index(i)), iter.Value())
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provenance
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestTrace(t *testing.T) {
+	ctx := cuecontext.New()
+	schema := ctx.CompileString(`
+#Port: int & >0
+service: {
+	name: string
+	port: #Port
+}
+`, cue.Filename("schema.cue"))
+	data := ctx.CompileString(`
+service: name: "web"
+service: port: 8080
+`, cue.Filename("data.cue"))
+
+	v := schema.Unify(data)
+
+	b := &strings.Builder{}
+	for _, o := range Trace(v.LookupPath(cue.ParsePath("service"))) {
+		fmt.Fprintf(b, "%s:", o.Path)
+		for _, p := range o.Positions {
+			fmt.Fprintf(b, " %s", p)
+		}
+		fmt.Fprintln(b)
+	}
+
+	got := b.String()
+	want := `: schema.cue:3:1 data.cue:2:1 data.cue:3:1
+name: schema.cue:4:2 data.cue:2:10
+port: schema.cue:5:2 data.cue:3:10
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	ctx := cuecontext.New()
+	schema := ctx.CompileString(`
+kind: *"Deployment" | "StatefulSet" | int
+mode: *"fast" | "safe"
+`, cue.Filename("schema.cue"))
+	data := ctx.CompileString(`
+kind: string
+`, cue.Filename("data.cue"))
+
+	v := schema.Unify(data)
+
+	testCases := []struct {
+		path string
+		want string
+	}{{
+		path: "kind",
+		want: `
+conjuncts:
+    schema.cue:2:7: *"Deployment" | "StatefulSet" | int
+        *"Deployment": accepted
+        "StatefulSet": accepted
+        int: eliminated: conflicting values int and string (mismatched types int and string)
+    data.cue:2:7: string
+default: "Deployment"
+result: *"Deployment" | "StatefulSet"`,
+	}, {
+		path: "mode",
+		want: `
+conjuncts:
+    schema.cue:3:7: *"fast" | "safe"
+        *"fast": accepted
+        "safe": accepted
+default: "fast"
+result: *"fast" | "safe"`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			e := Explain(v.LookupPath(cue.ParsePath(tc.path)))
+			got := strings.TrimSpace(e.String())
+			want := strings.TrimSpace(tc.want)
+			if got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
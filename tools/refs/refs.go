@@ -0,0 +1,306 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package refs finds the references to a field or definition.
+//
+// References are found by resolving the reference expressions of an
+// evaluated configuration, rather than by matching identifiers, so that
+// references through aliases, let clauses, imports, and selectors are
+// reported as well. This makes the results suitable for renaming a field,
+// detecting unused definitions, and assessing the impact of a change to a
+// schema.
+package refs
+
+import (
+	"fmt"
+	"sort"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/core/adt"
+	"cuelang.org/go/internal/core/dep"
+	"cuelang.org/go/internal/core/eval"
+	"cuelang.org/go/internal/core/runtime"
+	"cuelang.org/go/internal/value"
+)
+
+// A Kind indicates how a reference refers to the target.
+type Kind int
+
+const (
+	// Syntactic indicates that the reference resolves to the target or to a
+	// value nested within it. Renaming the target requires changing the
+	// reference.
+	Syntactic Kind = iota
+
+	// Semantic indicates that the reference resolves to a value that is
+	// defined in terms of the target, such as a field that is unified with
+	// a definition. A change to the target may affect the referenced value,
+	// but renaming the target does not require changing the reference.
+	Semantic
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Syntactic:
+		return "syntactic"
+	case Semantic:
+		return "semantic"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// A Reference is a reference to the target of Find.
+type Reference struct {
+	Kind Kind
+
+	// Path is the path of the value in which the reference occurs, relative
+	// to the value in which it was found.
+	Path cue.Path
+
+	// Target is the path of the referenced value. For a syntactic
+	// reference, this is the path of the target or of a value nested within
+	// it. For a semantic reference, it is the path of the value that is
+	// defined in terms of the target.
+	Target cue.Path
+
+	Pos token.Pos
+}
+
+func (r *Reference) String() string {
+	return fmt.Sprintf("%v: %s reference to %v", r.Path, r.Kind, r.Target)
+}
+
+// Find reports the references to target within each of the given values.
+// The values may be instances of different packages, as long as they were
+// built with the same Context as target, which allows references to be
+// found across the packages of a module. A reference within target to
+// itself or to one of its descendants is reported as well.
+//
+// References are reported once per position, ordered by position.
+func Find(target cue.Value, in ...cue.Value) []*Reference {
+	r, t := value.ToInternal(target)
+	if t == nil {
+		return nil
+	}
+	f := &finder{
+		r:      r,
+		ctx:    eval.NewContext(r, nil),
+		target: t,
+		path:   target.Path(),
+		exprs:  map[adt.Expr]bool{},
+		seen:   map[token.Pos]*Reference{},
+	}
+	f.pkg = f.importPath(t)
+	for _, c := range t.Conjuncts {
+		f.exprs[c.Expr()] = true
+	}
+	for _, v := range in {
+		f.walk(v)
+	}
+
+	refs := make([]*Reference, 0, len(f.seen))
+	for _, ref := range f.seen {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return comparePos(refs[i].Pos, refs[j].Pos) < 0
+	})
+	return refs
+}
+
+type finder struct {
+	r      *runtime.Runtime
+	ctx    *adt.OpContext
+	target *adt.Vertex
+	path   cue.Path
+
+	// pkg is the import path of the package of the target, if any.
+	pkg string
+
+	// exprs holds the conjuncts of the target.
+	exprs map[adt.Expr]bool
+
+	seen map[token.Pos]*Reference
+}
+
+func (f *finder) walk(v cue.Value) {
+	_, n := value.ToInternal(v)
+	if n == nil {
+		return
+	}
+	f.visit(v, n)
+
+	// Pattern constraints and the value of an ellipsis do not correspond to
+	// a field, so they are visited separately.
+	for _, s := range n.Structs {
+		for _, d := range s.Decls {
+			switch x := d.(type) {
+			case *adt.BulkOptionalField:
+				f.visitExpr(v, s.Env, x.Filter)
+				f.visitExpr(v, s.Env, x.Value)
+			case *adt.Ellipsis:
+				f.visitExpr(v, s.Env, x.Value)
+			}
+		}
+	}
+
+	iter, err := v.Fields(
+		cue.Definitions(true),
+		cue.Hidden(true),
+		cue.Optional(true),
+	)
+	if err == nil {
+		for iter.Next() {
+			f.walk(iter.Value())
+		}
+		return
+	}
+	if list, err := v.List(); err == nil {
+		for list.Next() {
+			f.walk(list.Value())
+		}
+	}
+}
+
+func (f *finder) visitExpr(v cue.Value, env *adt.Environment, x adt.Expr) {
+	if x == nil {
+		return
+	}
+	n := &adt.Vertex{}
+	n.AddConjunct(adt.MakeRootConjunct(env, x))
+	f.visit(v, n)
+}
+
+// visit records the references in the conjuncts of n, which corresponds to
+// v, that refer to the target.
+func (f *finder) visit(v cue.Value, n *adt.Vertex) {
+	_ = dep.Visit(f.ctx, n, func(d dep.Dependency) error {
+		src := d.Reference.Source()
+		if src == nil || !src.Pos().IsValid() {
+			return nil
+		}
+		pos := src.Pos()
+
+		ref := &Reference{Path: v.Path(), Pos: pos}
+		if sel, ok := f.within(d.Node); ok {
+			ref.Kind = Syntactic
+			ref.Target = cue.MakePath(append(f.path.Selectors(), sel...)...)
+		} else if f.derived(d.Node) {
+			ref.Kind = Semantic
+			ref.Target = f.pathOf(d.Node)
+		} else {
+			return nil
+		}
+
+		// A syntactic reference may also be found as a semantic one when
+		// the conjunct in which it occurs is copied to another value.
+		if prev, ok := f.seen[pos]; ok && prev.Kind <= ref.Kind {
+			return nil
+		}
+		f.seen[pos] = ref
+		return nil
+	})
+}
+
+// within reports whether n is the target or a value nested within it and,
+// if so, the selectors of n relative to the target.
+func (f *finder) within(n *adt.Vertex) (sel []cue.Selector, ok bool) {
+	for ; n.Parent != nil; n = n.Parent {
+		if n == f.target {
+			reverse(sel)
+			return sel, true
+		}
+		sel = append(sel, f.selector(n.Label))
+	}
+
+	// A package that imports the package of the target refers to a
+	// different instance of that package, so n is compared by path.
+	if f.pkg == "" || f.importPath(n) != f.pkg {
+		return nil, false
+	}
+	reverse(sel)
+	prefix := f.path.Selectors()
+	if len(sel) < len(prefix) {
+		return nil, false
+	}
+	for i, s := range prefix {
+		if s.String() != sel[i].String() {
+			return nil, false
+		}
+	}
+	return sel[len(prefix):], true
+}
+
+// importPath returns the import path of the package of n, if any.
+func (f *finder) importPath(n *adt.Vertex) string {
+	for n.Parent != nil {
+		n = n.Parent
+	}
+	if p := f.r.GetInstanceFromNode(n); p != nil {
+		return p.ImportPath
+	}
+	return ""
+}
+
+func reverse(sel []cue.Selector) {
+	for i, j := 0, len(sel)-1; i < j; i, j = i+1, j-1 {
+		sel[i], sel[j] = sel[j], sel[i]
+	}
+}
+
+// derived reports whether n shares a conjunct with the target or has a
+// conjunct that refers to it.
+func (f *finder) derived(n *adt.Vertex) bool {
+	for _, c := range n.Conjuncts {
+		if f.exprs[c.Expr()] {
+			return true
+		}
+	}
+	found := false
+	_ = dep.Visit(f.ctx, n, func(d dep.Dependency) error {
+		if sel, ok := f.within(d.Node); ok && len(sel) == 0 {
+			found = true
+		}
+		return nil
+	})
+	return found
+}
+
+// pathOf returns the path of n from the root of the configuration.
+func (f *finder) pathOf(n *adt.Vertex) cue.Path {
+	var sel []cue.Selector
+	for ; n != nil && n.Parent != nil; n = n.Parent {
+		sel = append([]cue.Selector{f.selector(n.Label)}, sel...)
+	}
+	return cue.MakePath(sel...)
+}
+
+func (f *finder) selector(l adt.Feature) cue.Selector {
+	s := cue.ParsePath(l.SelectorString(f.ctx)).Selectors()
+	if len(s) != 1 {
+		return cue.Str(l.SelectorString(f.ctx))
+	}
+	return s[0]
+}
+
+func comparePos(a, b token.Pos) int {
+	if a.Filename() != b.Filename() {
+		if a.Filename() < b.Filename() {
+			return -1
+		}
+		return 1
+	}
+	return a.Offset() - b.Offset()
+}
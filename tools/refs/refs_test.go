@@ -0,0 +1,81 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package refs
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+)
+
+func TestFind(t *testing.T) {
+	const src = `
+#A: {a: int, b: a}
+let L = #A
+x: #A & {a: 1}
+y: L
+z: x.b
+w: #A.a
+p: [string]: #A
+o?: #A
+l: [...#A]
+s: {#A}
+u: y
+`
+	v := cuecontext.New().CompileString(src, cue.Filename("in.cue"))
+	if err := v.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		target string
+		want   string
+	}{{
+		target: "#A",
+		want: `in.cue:2:17: #A.b: syntactic reference to #A.a
+in.cue:3:9: y: syntactic reference to #A
+in.cue:4:4: x: syntactic reference to #A
+in.cue:7:4: w: syntactic reference to #A.a
+in.cue:8:14: p: syntactic reference to #A
+in.cue:9:5: o: syntactic reference to #A
+in.cue:10:8: l: syntactic reference to #A
+in.cue:11:5: s: syntactic reference to #A
+in.cue:12:4: u: semantic reference to y`,
+	}, {
+		target: "#A.a",
+		want: `in.cue:2:17: #A.b: syntactic reference to #A.a
+in.cue:7:4: w: syntactic reference to #A.a`,
+	}, {
+		target: "#A.b",
+		want:   `in.cue:6:4: z: semantic reference to x.b`,
+	}, {
+		target: "z",
+		want:   ``,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.target, func(t *testing.T) {
+			var a []string
+			for _, r := range Find(v.LookupPath(cue.ParsePath(tc.target)), v) {
+				a = append(a, fmt.Sprintf("%v: %v", r.Pos, r))
+			}
+			if got := strings.Join(a, "\n"); got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
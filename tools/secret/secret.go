@@ -0,0 +1,442 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secret encrypts and decrypts fields of CUE values, so that secrets
+// can be stored alongside the configuration that uses them.
+//
+// Fields marked with a secret attribute are encrypted when a value is
+// exported:
+//
+//	db: password: "hunter2" @secret()
+//
+// results in
+//
+//	db: password: "ENC[key,ZnNkZmRz...]"
+//
+// The encrypted string records the name of the provider that encrypted it,
+// followed by the base64-encoded ciphertext of the JSON encoding of the
+// value. The attribute may name the provider to use, as in @secret(age). By
+// default, the first provider of a Keyring is used.
+//
+// Decrypting a file replaces each encrypted string with the value it
+// encrypts and marks the field that holds it with a secret attribute, so
+// that exporting the result encrypts the field again.
+//
+// Providers are created from a specification of the form name:arg. The
+// following providers are predefined:
+//
+//	key:FILE       AES-256-GCM with the base64-encoded 32-byte key in FILE
+//	age:FILE       the age tool with the identity in FILE
+//	exec:COMMAND   COMMAND encrypt or COMMAND decrypt, which read their
+//	               input from stdin and write the result to stdout
+//
+// The exec provider allows using key management services, such as a cloud
+// KMS, through a small wrapper script. Other providers may be added with
+// Register.
+package secret
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/shlex"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/literal"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/encoding/json"
+)
+
+// A Provider encrypts and decrypts secrets.
+type Provider interface {
+	Encrypt(plaintext []byte) (ciphertext []byte, err error)
+	Decrypt(ciphertext []byte) (plaintext []byte, err error)
+}
+
+// An OpenFunc creates a Provider from the argument of a provider
+// specification, such as the name of a key file.
+type OpenFunc func(arg string) (Provider, error)
+
+var (
+	mu        sync.RWMutex
+	openFuncs = map[string]OpenFunc{
+		"key":  openKey,
+		"age":  openAge,
+		"exec": openExec,
+	}
+)
+
+// Register makes a provider available by the given name for use in
+// specifications passed to NewKeyring. It overrides any provider previously
+// registered by that name.
+func Register(name string, open OpenFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+	openFuncs[name] = open
+}
+
+// A Keyring holds the providers with which to encrypt and decrypt secrets.
+type Keyring struct {
+	names     []string
+	providers map[string]Provider
+}
+
+// NewKeyring returns a Keyring with the providers for the given
+// specifications, each of the form name:arg.
+func NewKeyring(specs ...string) (*Keyring, error) {
+	k := &Keyring{}
+	for _, spec := range specs {
+		p := strings.IndexByte(spec, ':')
+		if p < 0 {
+			return nil, errors.Newf(token.NoPos,
+				"secret: invalid provider %q: must be of the form name:arg", spec)
+		}
+		name, arg := spec[:p], spec[p+1:]
+		mu.RLock()
+		open := openFuncs[name]
+		mu.RUnlock()
+		if open == nil {
+			return nil, errors.Newf(token.NoPos, "secret: unknown provider %q", name)
+		}
+		provider, err := open(arg)
+		if err != nil {
+			return nil, errors.Wrapf(err, token.NoPos, "secret: %s", name)
+		}
+		k.Add(name, provider)
+	}
+	return k, nil
+}
+
+// Add adds a provider by the given name. The first provider added is the
+// default provider for encryption.
+func (k *Keyring) Add(name string, p Provider) {
+	if k.providers == nil {
+		k.providers = map[string]Provider{}
+	}
+	if _, ok := k.providers[name]; !ok {
+		k.names = append(k.names, name)
+	}
+	k.providers[name] = p
+}
+
+func (k *Keyring) provider(name string, pos token.Pos) (Provider, error) {
+	if name == "" {
+		if len(k.names) == 0 {
+			return nil, errors.Newf(pos, "secret: no provider configured")
+		}
+		name = k.names[0]
+	}
+	p := k.providers[name]
+	if p == nil {
+		return nil, errors.Newf(pos, "secret: no provider %q configured", name)
+	}
+	return p, nil
+}
+
+// Encrypt returns v with the fields marked with a secret attribute replaced
+// by their encrypted value. It returns v itself if it has no such fields.
+func (k *Keyring) Encrypt(v cue.Value) (cue.Value, error) {
+	if !hasSecrets(v) {
+		return v, nil
+	}
+	if a := v.Attribute("secret"); a.Err() == nil {
+		// v itself is a secret field, as when selected with an expression.
+		provider, _ := a.String(0)
+		s, err := k.encrypt(v, provider)
+		if err != nil {
+			return cue.Value{}, errors.Promote(err, "secret")
+		}
+		return v.Context().BuildExpr(ast.NewString(s)), nil
+	}
+	x := v.Syntax(cue.Final(), cue.Concrete(true), cue.Docs(true))
+	expr, ok := x.(ast.Expr)
+	if !ok {
+		return v, nil
+	}
+	e := &encrypter{k: k}
+	expr = e.expr(expr, v)
+	if e.err != nil {
+		return cue.Value{}, e.err
+	}
+	return v.Context().BuildExpr(expr), nil
+}
+
+func hasSecrets(v cue.Value) (found bool) {
+	v.Walk(func(v cue.Value) bool {
+		if a := v.Attribute("secret"); a.Err() == nil {
+			found = true
+		}
+		return !found
+	}, nil)
+	return found
+}
+
+type encrypter struct {
+	k   *Keyring
+	err errors.Error
+}
+
+func (e *encrypter) expr(x ast.Expr, v cue.Value) ast.Expr {
+	switch x := x.(type) {
+	case *ast.StructLit:
+		for _, d := range x.Elts {
+			f, ok := d.(*ast.Field)
+			if !ok {
+				continue
+			}
+			name, _, err := ast.LabelName(f.Label)
+			if err != nil {
+				continue
+			}
+			sel := cue.Str(name)
+			if strings.HasPrefix(name, "#") {
+				if _, ok := f.Label.(*ast.Ident); ok {
+					sel = cue.Def(name)
+				}
+			}
+			fv := v.LookupPath(cue.MakePath(sel))
+			if a := fv.Attribute("secret"); a.Err() == nil {
+				provider, _ := a.String(0)
+				f.Value = e.encrypt(fv, provider)
+				continue
+			}
+			f.Value = e.expr(f.Value, fv)
+		}
+
+	case *ast.ListLit:
+		for i, elem := range x.Elts {
+			x.Elts[i] = e.expr(elem, v.LookupPath(cue.MakePath(cue.Index(i))))
+		}
+	}
+	return x
+}
+
+func (e *encrypter) encrypt(v cue.Value, name string) ast.Expr {
+	s, err := e.k.encrypt(v, name)
+	if err != nil {
+		e.err = errors.Append(e.err, errors.Promote(err, "secret"))
+		return ast.NewNull()
+	}
+	return ast.NewString(s)
+}
+
+func (k *Keyring) encrypt(v cue.Value, name string) (string, error) {
+	b, err := v.MarshalJSON()
+	if err != nil {
+		return "", err
+	}
+	if name == "" && len(k.names) > 0 {
+		name = k.names[0]
+	}
+	p, err := k.provider(name, v.Pos())
+	if err != nil {
+		return "", err
+	}
+	b, err = p.Encrypt(b)
+	if err != nil {
+		return "", errors.Wrapf(err, v.Pos(), "secret: %s: encryption failed", name)
+	}
+	return fmt.Sprintf("ENC[%s,%s]", name, base64.StdEncoding.EncodeToString(b)), nil
+}
+
+var encrypted = regexp.MustCompile(`^ENC\[([a-zA-Z0-9_-]+),([A-Za-z0-9+/]*=*)\]$`)
+
+// DecryptFile replaces the encrypted strings in f with the values they
+// encrypt and adds a secret attribute to the fields that hold them.
+func (k *Keyring) DecryptFile(f *ast.File) error {
+	var errs errors.Error
+	astutil.Apply(f, func(c astutil.Cursor) bool {
+		switch x := c.Node().(type) {
+		case *ast.Field:
+			lit, ok := x.Value.(*ast.BasicLit)
+			if !ok {
+				return true
+			}
+			v, name, err := k.decrypt(lit)
+			if err != nil {
+				errs = errors.Append(errs, err)
+				return false
+			}
+			if v == nil {
+				return true
+			}
+			x.Value = v
+			if !hasAttr(x, "secret") {
+				x.Attrs = append(x.Attrs, &ast.Attribute{
+					Text: fmt.Sprintf("@secret(%s)", name),
+				})
+			}
+			return false
+
+		case *ast.BasicLit:
+			v, _, err := k.decrypt(x)
+			if err != nil {
+				errs = errors.Append(errs, err)
+			}
+			if v != nil {
+				c.Replace(v)
+			}
+		}
+		return true
+	}, nil)
+	return errs
+}
+
+func hasAttr(f *ast.Field, key string) bool {
+	for _, a := range f.Attrs {
+		if k, _ := a.Split(); k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// decrypt returns the value encrypted by lit and the name of the provider
+// that encrypted it, or nil if lit is not an encrypted string.
+func (k *Keyring) decrypt(lit *ast.BasicLit) (ast.Expr, string, errors.Error) {
+	if lit.Kind != token.STRING || !strings.Contains(lit.Value, "ENC[") {
+		return nil, "", nil
+	}
+	s, err := literal.Unquote(lit.Value)
+	if err != nil {
+		return nil, "", nil
+	}
+	m := encrypted.FindStringSubmatch(s)
+	if m == nil {
+		return nil, "", nil
+	}
+	name := m[1]
+	p, err := k.provider(name, lit.Pos())
+	if err != nil {
+		return nil, "", errors.Promote(err, "secret")
+	}
+	b, err := base64.StdEncoding.DecodeString(m[2])
+	if err == nil {
+		b, err = p.Decrypt(b)
+	}
+	if err != nil {
+		return nil, "", errors.Wrapf(err, lit.Pos(), "secret: %s: decryption failed", name)
+	}
+	x, err := json.Extract(lit.Pos().Filename(), b)
+	if err != nil {
+		return nil, "", errors.Wrapf(err, lit.Pos(), "secret: %s: invalid plaintext", name)
+	}
+	ast.SetPos(x, lit.Pos())
+	return x, name, nil
+}
+
+type keyProvider struct {
+	aead cipher.AEAD
+}
+
+func openKey(file string) (Provider, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(b)))
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%s: key must be 32 base64-encoded bytes", file)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &keyProvider{aead: aead}, nil
+}
+
+func (p *keyProvider) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, p.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return p.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (p *keyProvider) Decrypt(ciphertext []byte) ([]byte, error) {
+	n := p.aead.NonceSize()
+	if len(ciphertext) < n {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	return p.aead.Open(nil, ciphertext[:n], ciphertext[n:], nil)
+}
+
+// A commandProvider runs a command for encryption and decryption.
+type commandProvider struct {
+	encrypt []string
+	decrypt []string
+}
+
+func openAge(identity string) (Provider, error) {
+	if identity == "" {
+		return nil, fmt.Errorf("missing identity file")
+	}
+	return &commandProvider{
+		encrypt: []string{"age", "--encrypt", "-i", identity},
+		decrypt: []string{"age", "--decrypt", "-i", identity},
+	}, nil
+}
+
+func openExec(command string) (Provider, error) {
+	args, err := shlex.Split(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("missing command")
+	}
+	return &commandProvider{
+		encrypt: append(args[:len(args):len(args)], "encrypt"),
+		decrypt: append(args[:len(args):len(args)], "decrypt"),
+	}, nil
+}
+
+func (p *commandProvider) Encrypt(plaintext []byte) ([]byte, error) {
+	return run(p.encrypt, plaintext)
+}
+
+func (p *commandProvider) Decrypt(ciphertext []byte) ([]byte, error) {
+	return run(p.decrypt, ciphertext)
+}
+
+func run(args []string, in []byte) ([]byte, error) {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(in)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s: %v: %s", args[0], err, msg)
+		}
+		return nil, fmt.Errorf("%s: %v", args[0], err)
+	}
+	return out, nil
+}
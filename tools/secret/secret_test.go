@@ -0,0 +1,221 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/parser"
+)
+
+// reverse is a Provider for testing that reverses its input.
+type reverse struct{}
+
+func (reverse) Encrypt(b []byte) ([]byte, error) { return rev(b), nil }
+func (reverse) Decrypt(b []byte) ([]byte, error) { return rev(b), nil }
+
+func rev(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i, c := range b {
+		r[len(b)-1-i] = c
+	}
+	return r
+}
+
+func TestEncrypt(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		path string
+		want string
+	}{{
+		name: "no secrets",
+		in:   `a: 1`,
+		want: `{
+	a: 1
+}`,
+	}, {
+		name: "fields",
+		in: `
+		db: {
+			user:     "admin"
+			password: "hunter2" @secret()
+			port:     5432 @secret(rev)
+		}
+		keys: [{id: 1, key: {x: "y"} @secret()}]
+		`,
+		want: `{
+	db: {
+		user:     "admin"
+		password: "ENC[rev,IjJyZXRudWgi]" @secret()
+		port:     "ENC[rev,MjM0NQ==]"     @secret(rev)
+	}
+	keys: [{
+		id:  1
+		key: "ENC[rev,fSJ5IjoieCJ7]" @secret()
+	}]
+}`,
+	}, {
+		name: "selected field",
+		in:   `db: password: "hunter2" @secret()`,
+		path: "db.password",
+		want: `"ENC[rev,IjJyZXRudWgi]"`,
+	}, {
+		name: "unknown provider",
+		in:   `a: "x" @secret(vault)`,
+		want: `secret: no provider "vault" configured`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			k := &Keyring{}
+			k.Add("rev", reverse{})
+			v := cuecontext.New().CompileString(tc.in)
+			if tc.path != "" {
+				v = v.LookupPath(cue.ParsePath(tc.path))
+			}
+			w, err := k.Encrypt(v)
+			if err != nil {
+				if got := errors.Details(err, nil); !strings.Contains(got, tc.want) {
+					t.Errorf("got error %q; want %q", got, tc.want)
+				}
+				return
+			}
+			b, err := format.Node(w.Syntax())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(b); got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecryptFile(t *testing.T) {
+	k := &Keyring{}
+	k.Add("rev", reverse{})
+
+	in := `
+a: "ENC[rev,IjJyZXRudWgi]"
+b: "ENC[rev,MjM0NQ==]" @secret(rev)
+c: ["ENC[rev,fSJ5IjoieCJ7]", "ENC[plain]"]
+`
+	want := `a: "hunter2" @secret(rev)
+b: 5432      @secret(rev)
+c: [{
+	x: "y"
+}, "ENC[plain]"]
+`
+	f, err := parser.ParseFile("in.cue", in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := k.DecryptFile(f); err != nil {
+		t.Fatal(err)
+	}
+	b, err := format.Node(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(b); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	f, _ = parser.ParseFile("in.cue", `a: "ENC[age,MjM0NQ==]"`)
+	err = k.DecryptFile(f)
+	if want := `secret: no provider "age" configured`; err == nil || err.Error() != want {
+		t.Errorf("got error %v; want %s", err, want)
+	}
+}
+
+func TestProviders(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	keyFile := filepath.Join(dir, "key")
+	key := []byte("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=\n")
+	if err := ioutil.WriteFile(keyFile, key, 0600); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, "rot")
+	rot := "#!/bin/sh\ntr 'a-z' 'n-za-m'\n"
+	if err := ioutil.WriteFile(script, []byte(rot), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []string{
+		"key:" + keyFile,
+		"exec:sh " + script,
+	}
+	for _, spec := range testCases {
+		t.Run(spec, func(t *testing.T) {
+			k, err := NewKeyring(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := cuecontext.New().CompileString(`a: "secret" @secret()`)
+			w, err := k.Encrypt(v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, _ := w.LookupPath(cue.ParsePath("a")).String()
+			if !strings.HasPrefix(s, "ENC[") || strings.Contains(s, "secret") {
+				t.Fatalf("value not encrypted: %s", s)
+			}
+
+			b, _ := format.Node(w.Syntax())
+			f, err := parser.ParseFile("enc.cue", b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := k.DecryptFile(f); err != nil {
+				t.Fatal(err)
+			}
+			b, _ = format.Node(f)
+			want := []byte(`a: "secret" @secret()`)
+			if !bytes.Contains(b, want) {
+				t.Errorf("got %s; want %s", b, want)
+			}
+		})
+	}
+
+	errCases := []struct {
+		spec string
+		want string
+	}{
+		{"key", `secret: invalid provider "key": must be of the form name:arg`},
+		{"vault:x", `secret: unknown provider "vault"`},
+		{"exec:", `secret: exec: missing command`},
+		{"key:" + script, "secret: key: " + script + ": key must be 32 base64-encoded bytes"},
+	}
+	for _, tc := range errCases {
+		_, err := NewKeyring(tc.spec)
+		if err == nil || err.Error() != tc.want {
+			t.Errorf("%s: got error %v; want %s", tc.spec, err, tc.want)
+		}
+	}
+}
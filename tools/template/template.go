@@ -0,0 +1,203 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package template instantiates concrete configurations from parameterized
+// CUE definitions.
+//
+// A template is a CUE value in which the fields that are to be provided by
+// the user are marked with a param attribute:
+//
+//	#Service: {
+//		name:     string      @param(doc="name of the service")
+//		port:     *80 | int   @param()
+//		replicas: 1
+//		metadata: labels: app: name
+//	}
+//
+// A parameter without a default value is required. Instantiate fills in the
+// parameters, checks that all required parameters are given and that no
+// unknown parameters are passed, and validates that the result is concrete.
+package template
+
+import (
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/token"
+)
+
+// A Param describes a parameter of a template.
+type Param struct {
+	// Path is the path of the parameter relative to the template.
+	Path cue.Path
+
+	// Doc is the description of the parameter, as given by the doc argument
+	// of the attribute or, if absent, the doc comment of the field.
+	Doc string
+
+	// Value is the constraint of the parameter.
+	Value cue.Value
+
+	// Required reports whether the parameter has no default value.
+	Required bool
+
+	Pos token.Pos
+}
+
+// Params reports the parameters of the template t in depth-first order.
+func Params(t cue.Value) ([]*Param, error) {
+	if err := t.Err(); err != nil {
+		return nil, err
+	}
+	var params []*Param
+	var errs errors.Error
+	var walk func(v cue.Value, path []cue.Selector)
+	walk = func(v cue.Value, path []cue.Selector) {
+		iter, err := v.Fields(cue.Optional(true))
+		if err != nil {
+			return
+		}
+		for iter.Next() {
+			w := iter.Value()
+			p := append(path[:len(path):len(path)], iter.Selector())
+			a := w.Attribute("param")
+			if a.Err() != nil {
+				walk(w, p)
+				continue
+			}
+			param := &Param{
+				Path:  cue.MakePath(p...),
+				Value: w,
+				Pos:   w.Pos(),
+			}
+			_, hasDefault := w.Default()
+			param.Required = !hasDefault && !w.IsConcrete()
+			for i := 0; i < a.NumArgs(); i++ {
+				switch key, value := a.Arg(i); key {
+				case "doc":
+					param.Doc = value
+				case "":
+				default:
+					errs = errors.Append(errs, errors.Newf(w.Pos(),
+						"unknown key %q in param attribute", key))
+				}
+			}
+			if param.Doc == "" {
+				for _, cg := range w.Doc() {
+					param.Doc += cg.Text()
+				}
+				param.Doc = strings.TrimSpace(param.Doc)
+			}
+			params = append(params, param)
+		}
+	}
+	walk(t, nil)
+	if errs != nil {
+		return nil, errs
+	}
+	return params, nil
+}
+
+// Instantiate fills the parameters of t with the values in args, which
+// should be a struct mapping parameter paths to values, and returns the
+// resulting configuration. All missing, unknown, and invalid parameters are
+// reported in a single error.
+func Instantiate(t cue.Value, args cue.Value) (cue.Value, error) {
+	params, err := Params(t)
+	if err != nil {
+		return cue.Value{}, err
+	}
+	if err := args.Err(); err != nil {
+		return cue.Value{}, err
+	}
+
+	isParam := map[string]bool{}
+	for _, p := range params {
+		isParam[p.Path.String()] = true
+	}
+
+	var errs errors.Error
+	checkArgs(t, args, nil, isParam, &errs)
+
+	v := t.Unify(args)
+	for _, p := range params {
+		w := v.LookupPath(p.Path)
+		if w.Err() != nil || !p.Required {
+			continue
+		}
+		if err := w.Validate(cue.Concrete(true)); err != nil {
+			msg := "missing parameter"
+			if p.Doc != "" {
+				msg += " (" + p.Doc + ")"
+			}
+			errs = errors.Append(errs, newParamError(p.Pos, p.Path, msg))
+		}
+	}
+	if errs != nil {
+		return cue.Value{}, errs
+	}
+
+	if err := v.Validate(cue.Concrete(true)); err != nil {
+		return cue.Value{}, err
+	}
+	return v, nil
+}
+
+// checkArgs reports an error for each field in args that does not correspond
+// to a parameter of t.
+func checkArgs(t, args cue.Value, path []cue.Selector, isParam map[string]bool, errs *errors.Error) {
+	iter, err := args.Fields()
+	if err != nil {
+		return
+	}
+	for iter.Next() {
+		p := append(path[:len(path):len(path)], iter.Selector())
+		switch {
+		case isParam[cue.MakePath(p...).String()]:
+		case iter.Value().IncompleteKind() == cue.StructKind &&
+			t.LookupPath(cue.MakePath(p...)).Exists():
+			checkArgs(t, iter.Value(), p, isParam, errs)
+		default:
+			*errs = errors.Append(*errs, newParamError(iter.Value().Pos(),
+				cue.MakePath(p...), "unknown parameter"))
+		}
+	}
+}
+
+// A paramError reports a problem with the parameter at a given path.
+type paramError struct {
+	errors.Message
+	pos  token.Pos
+	path cue.Path
+}
+
+func newParamError(pos token.Pos, path cue.Path, msg string) *paramError {
+	return &paramError{
+		Message: errors.NewMessage("%s", []interface{}{msg}),
+		pos:     pos,
+		path:    path,
+	}
+}
+
+func (e *paramError) Position() token.Pos         { return e.pos }
+func (e *paramError) InputPositions() []token.Pos { return nil }
+
+func (e *paramError) Path() []string {
+	var a []string
+	for _, s := range e.path.Selectors() {
+		a = append(a, s.String())
+	}
+	return a
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package template
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/errors"
+)
+
+const service = `
+#Service: {
+	// name of the service
+	name:  string             @param()
+	port:  *80 | int & <65536 @param(doc="port to listen on")
+	image: {
+		repo: string @param()
+		tag:  *"latest" | string @param()
+	}
+	replicas: 1
+	metadata: labels: app: name
+}
+`
+
+func TestParams(t *testing.T) {
+	tmpl := cuecontext.New().CompileString(service).LookupPath(cue.ParsePath("#Service"))
+	params, err := Params(tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range params {
+		got = append(got, fmt.Sprintf("%v required=%v %q", p.Path, p.Required, strings.TrimSpace(p.Doc)))
+	}
+	want := `name required=true "name of the service"
+port required=false "port to listen on"
+image.repo required=true ""
+image.tag required=false ""`
+	if s := strings.Join(got, "\n"); s != want {
+		t.Errorf("got:\n%s\nwant:\n%s", s, want)
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+	testCases := []struct {
+		name string
+		args string
+		want string
+	}{{
+		name: "defaults",
+		args: `name: "web", image: repo: "nginx"`,
+		want: `{"name":"web","port":80,"image":{"repo":"nginx","tag":"latest"},"replicas":1,"metadata":{"labels":{"app":"web"}}}`,
+	}, {
+		name: "override defaults",
+		args: `name: "web", port: 8080, image: {repo: "nginx", tag: "1.21"}`,
+		want: `{"name":"web","port":8080,"image":{"repo":"nginx","tag":"1.21"},"replicas":1,"metadata":{"labels":{"app":"web"}}}`,
+	}, {
+		name: "missing",
+		args: `port: 8080`,
+		want: `name: missing parameter (name of the service):
+    test:4:2
+image.repo: missing parameter:
+    test:7:3`,
+	}, {
+		name: "unknown",
+		args: `name: "web", image: repo: "nginx", replicas: 3, color: "red"`,
+		want: `replicas: unknown parameter:
+    args:1:36
+color: unknown parameter:
+    args:1:49`,
+	}, {
+		name: "invalid",
+		args: `name: "web", image: repo: "nginx", port: 70000`,
+		want: `#Service.port: 2 errors in empty disjunction:
+#Service.port: conflicting values 80 and 70000:
+    args:1:42
+    test:5:10
+#Service.port: invalid value 70000 (out of bound <65536):
+    test:5:21
+    args:1:42`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := cuecontext.New()
+			tmpl := ctx.CompileString(service, cue.Filename("test")).
+				LookupPath(cue.ParsePath("#Service"))
+			args := ctx.CompileString(tc.args, cue.Filename("args"))
+
+			v, err := Instantiate(tmpl, args)
+			var got string
+			if err != nil {
+				got = strings.TrimSpace(errors.Details(err, nil))
+			} else {
+				b, err := v.MarshalJSON()
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = string(b)
+			}
+			if got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}
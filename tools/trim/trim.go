@@ -50,21 +50,88 @@
 package trim
 
 import (
+	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
 	"cuelang.org/go/cue/ast/astutil"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/token"
 	"cuelang.org/go/internal/core/adt"
 	"cuelang.org/go/internal/core/debug"
 	"cuelang.org/go/internal/core/subsume"
 	"cuelang.org/go/internal/value"
 )
 
+// A Construct is a set of CUE language constructs that may imply the value
+// of a field.
+type Construct uint8
+
+const (
+	// Definition is a field of a definition mixed into a struct.
+	Definition Construct = 1 << iota
+
+	// Comprehension is a field generated by a comprehension.
+	Comprehension
+
+	// Pattern is a value implied by a pattern constraint, as in
+	// [string]: T.
+	Pattern
+
+	// Ellipsis is a list element implied by the type of an ellipsis, as in
+	// [...T].
+	Ellipsis
+
+	// Default is a value that is implied only by the default value of a
+	// disjunction, as in *1 | int.
+	Default
+)
+
+var constructNames = []string{
+	"definition",
+	"comprehension",
+	"pattern",
+	"ellipsis",
+	"default",
+}
+
+func (c Construct) String() string {
+	var a []string
+	for i, name := range constructNames {
+		if c&(1<<uint(i)) != 0 {
+			a = append(a, name)
+		}
+	}
+	return strings.Join(a, "|")
+}
+
+// A Removal describes a field that was removed by trim.
+type Removal struct {
+	// Pos is the position of the removed field.
+	Pos token.Pos
+
+	// Path is the path of the field within the evaluated value.
+	Path cue.Path
+
+	// By reports the constructs that imply the removed value.
+	By Construct
+}
+
 // Config configures trim options.
 type Config struct {
 	Trace bool
+
+	// Keep lists the constructs that may not be used to remove fields. For
+	// instance, setting Keep to Default retains fields that only equal the
+	// default value of a dominating field.
+	Keep Construct
+
+	// Report, if not nil, is called for each removed field in the order in
+	// which they appear in the files.
+	Report func(r Removal)
 }
 
 // Files trims fields in the given files that can be implied from other fields,
@@ -72,24 +139,34 @@ type Config struct {
 // Trimming is done on a best-effort basis and only when the removed field
 // is clearly implied by another field, rather than equal sibling fields.
 func Files(files []*ast.File, inst cue.InstanceOrValue, cfg *Config) error {
+	if cfg == nil {
+		cfg = &Config{}
+	}
 	r, v := value.ToInternal(inst.Value())
 
 	t := &trimmer{
-		Config:  *cfg,
-		ctx:     adt.NewContext(r, v),
-		remove:  map[ast.Node]bool{},
-		exclude: map[ast.Node]bool{},
-		debug:   Debug,
-		w:       os.Stderr,
+		Config:      *cfg,
+		ctx:         adt.NewContext(r, v),
+		remove:      map[ast.Node]bool{},
+		exclude:     map[ast.Node]bool{},
+		removals:    map[ast.Node]Removal{},
+		constraints: map[adt.Node]Construct{},
+		debug:       Debug,
+		w:           os.Stderr,
 	}
 
-	d, _, _, pickedDefault := t.addDominators(nil, v, false)
+	d, _, _, pickedDefault, _ := t.addDominators(nil, v, false)
 	t.findSubordinates(d, v, pickedDefault)
 
 	// Remove subordinate values from files.
 	for _, f := range files {
 		astutil.Apply(f, func(c astutil.Cursor) bool {
 			if f, ok := c.Node().(*ast.Field); ok && t.remove[f.Value] && !t.exclude[f.Value] {
+				if t.Report != nil {
+					r := t.removals[f.Value]
+					r.Pos = f.Pos()
+					t.Report(r)
+				}
 				c.Delete()
 			}
 			return true
@@ -102,12 +179,28 @@ func Files(files []*ast.File, inst cue.InstanceOrValue, cfg *Config) error {
 	return nil
 }
 
+// Instance trims the files of inst, evaluating inst with ctx. The files of
+// inst need not exist on disk: inst may be created with
+// cue/load.Instances using an overlay or directly from parsed files.
+func Instance(ctx *cue.Context, inst *build.Instance, cfg *Config) error {
+	v := ctx.BuildInstance(inst)
+	if err := v.Err(); err != nil {
+		return err
+	}
+	return Files(inst.Files, v, cfg)
+}
+
 type trimmer struct {
 	Config
 
-	ctx     *adt.OpContext
-	remove  map[ast.Node]bool
-	exclude map[ast.Node]bool
+	ctx      *adt.OpContext
+	remove   map[ast.Node]bool
+	exclude  map[ast.Node]bool
+	removals map[ast.Node]Removal
+
+	// constraints maps the location of a constraint to the kind of
+	// constraint from which it originates.
+	constraints map[adt.Node]Construct
 
 	debug  bool
 	indent int
@@ -116,9 +209,10 @@ type trimmer struct {
 
 var Debug bool = false
 
-func (t *trimmer) markRemove(c adt.Conjunct) {
+func (t *trimmer) markRemove(c adt.Conjunct, v *adt.Vertex, by Construct) {
 	if src := c.Expr().Source(); src != nil {
 		t.remove[src] = true
+		t.removals[src] = Removal{Path: t.path(v), By: by}
 		if t.debug {
 			t.logf("removing %s", debug.NodeString(t.ctx, c.Expr(), nil))
 		}
@@ -151,6 +245,63 @@ func isDominator(c adt.Conjunct) (ok, mayRemove bool) {
 	return true, true
 }
 
+// construct reports the language constructs from which the dominator c
+// originates.
+func (t *trimmer) construct(c adt.Conjunct) (by Construct) {
+	if _, ok := c.Expr().(*adt.Top); ok {
+		// Top, as added by an ellipsis in a definition, implies nothing.
+		return 0
+	}
+
+	// Attribute the conjunct to the innermost construct, if known.
+	info := c.CloseInfo
+	span := info.RootSpanType() & dominatorNode
+	if span == 0 {
+		span = info.SpanMask()
+	}
+	if span&adt.DefinitionSpan != 0 {
+		by |= Definition
+	}
+	if span&adt.ComprehensionSpan != 0 {
+		by |= Comprehension
+	}
+	if span&adt.ConstraintSpan != 0 {
+		// Conjuncts derived from a constraint share its location. The kind of
+		// constraint can only be determined at the point where the constraint
+		// is applied, which is always visited before any of its descendants.
+		loc := info.Location()
+		if t.constraints[loc] == 0 {
+			if _, ok := c.Field().(*adt.BulkOptionalField); ok {
+				t.constraints[loc] = Pattern
+			} else if info.RootSpanType() == adt.ConstraintSpan && loc == c.Expr() {
+				t.constraints[loc] = Ellipsis
+			}
+		}
+		if k := t.constraints[loc]; k != 0 {
+			by |= k
+		} else {
+			by |= Pattern | Ellipsis
+		}
+	}
+	return by
+}
+
+// path reports the path of v.
+func (t *trimmer) path(v *adt.Vertex) cue.Path {
+	var b strings.Builder
+	for _, f := range v.Path() {
+		switch {
+		case f.IsInt():
+			fmt.Fprintf(&b, "[%d]", f.Index())
+			continue
+		case b.Len() > 0:
+			b.WriteByte('.')
+		}
+		b.WriteString(f.SelectorString(t.ctx))
+	}
+	return cue.ParsePath(b.String())
+}
+
 // Removable reports whether a non-dominator conjunct can be removed. This is
 // not the case if it has pattern constraints that could turn into dominator
 // nodes.
@@ -199,7 +350,10 @@ const (
 // any value that was instrumental in selecting the default. This is currently
 // hard to do, however, so we just fall back to a stricter mode in the presence
 // of defaults.
-func (t *trimmer) addDominators(d, v *adt.Vertex, hasDisjunction bool) (doms *adt.Vertex, ambiguous, hasSubs, strict bool) {
+//
+// It also reports the constructs from which the dominators originate.
+// Dominators originating from constructs listed in Keep are ignored.
+func (t *trimmer) addDominators(d, v *adt.Vertex, hasDisjunction bool) (doms *adt.Vertex, ambiguous, hasSubs, strict bool, by Construct) {
 	strict = hasDisjunction
 	doms = &adt.Vertex{}
 	if d != nil && hasDisjunction {
@@ -211,6 +365,11 @@ func (t *trimmer) addDominators(d, v *adt.Vertex, hasDisjunction bool) (doms *ad
 		isDom, _ := isDominator(c)
 		switch {
 		case isDom:
+			k := t.construct(c)
+			if k&t.Keep != 0 {
+				break
+			}
+			by |= k
 			doms.AddConjunct(c)
 		default:
 			if r, ok := c.Expr().(adt.Resolver); ok {
@@ -243,7 +402,7 @@ func (t *trimmer) addDominators(d, v *adt.Vertex, hasDisjunction bool) (doms *ad
 	}
 
 	_ = hasDoms
-	return doms, hasSubs, ambiguous, strict || ambiguous
+	return doms, hasSubs, ambiguous, strict || ambiguous, by
 }
 
 func (t *trimmer) findSubordinates(doms, v *adt.Vertex, hasDisjunction bool) (result int) {
@@ -256,11 +415,14 @@ func (t *trimmer) findSubordinates(doms, v *adt.Vertex, hasDisjunction bool) (re
 		}
 	}()
 
-	doms, hasSubs, ambiguous, pickedDefault := t.addDominators(doms, v, hasDisjunction)
+	doms, hasSubs, ambiguous, pickedDefault, by := t.addDominators(doms, v, hasDisjunction)
 
 	if ambiguous {
 		return no
 	}
+	if pickedDefault {
+		by |= Default
+	}
 
 	// TODO(structure sharing): do not descend into vertices whose parent is not
 	// equal to the parent. This is not relevant at this time, but may be so in
@@ -297,6 +459,10 @@ func (t *trimmer) findSubordinates(doms, v *adt.Vertex, hasDisjunction bool) (re
 			return maybe
 		}
 
+		if by&Default != 0 && t.Keep&Default != 0 {
+			return no
+		}
+
 		// This should normally not be necessary, as subsume should catch this.
 		// But as we already take the default value for doms, it doesn't hurt to
 		// do it.
@@ -319,7 +485,7 @@ func (t *trimmer) findSubordinates(doms, v *adt.Vertex, hasDisjunction bool) (re
 	for _, c := range v.Conjuncts {
 		_, allowRemove := isDominator(c)
 		if !allowRemove && removable(c, v) {
-			t.markRemove(c)
+			t.markRemove(c, v, by)
 		}
 	}
 
@@ -15,16 +15,19 @@
 package trim
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/build"
 	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/cuetxtar"
 	"cuelang.org/go/cue/errors"
 	"cuelang.org/go/cue/format"
 	"cuelang.org/go/cue/parser"
 	"cuelang.org/go/internal/cuetest"
-	"cuelang.org/go/internal/cuetxtar"
 	"github.com/rogpeppe/go-internal/txtar"
 )
 
@@ -32,6 +35,7 @@ func TestFiles(t *testing.T) {
 	testCases := []struct {
 		name string
 		in   string
+		keep Construct
 		out  string
 	}{{
 		name: "optional does not remove required",
@@ -59,7 +63,6 @@ a: {
 aFoo:     _
 `,
 	}, {
-		// TODO: make this optional
 		name: "defaults can remove non-defaults",
 		in: `
 		foo: [string]: a: *1 | int
@@ -67,6 +70,62 @@ aFoo:     _
 		`,
 		out: `foo: [string]: a: *1 | int
 foo: b: {}
+`,
+	}, {
+		name: "keep defaults",
+		in: `
+		foo: [string]: {
+			a: *1 | int
+			b: int
+		}
+		foo: b: {
+			a: 1
+			b: int
+		}
+		`,
+		keep: Default,
+		out: `foo: [string]: {
+	a: *1 | int
+	b: int
+}
+foo: b: {
+	a: 1
+}
+`,
+	}, {
+		name: "keep patterns",
+		in: `
+		#D: {a: 1, ...}
+		foo: [string]: b: 2
+		foo: x: #D & {
+			a: 1
+			b: 2
+		}
+		`,
+		keep: Pattern,
+		out: `#D: {a: 1, ...}
+foo: [string]: b: 2
+foo: x: #D & {
+	b: 2
+}
+`,
+	}, {
+		name: "keep ellipsis",
+		in: `
+		service: [string]: {
+			ports: [{a: 1}, ...{ extra: 3 }]
+		}
+		service: a: {
+			ports: [{a: 1}, {extra: 3}]
+		}
+		`,
+		keep: Ellipsis,
+		out: `service: [string]: {
+	ports: [{a: 1}, ...{extra: 3}]
+}
+service: a: {
+	ports: [{}, {extra: 3}]
+}
 `,
 	}, {
 		name: "remove top-level struct",
@@ -261,7 +320,7 @@ foo: entry: {
 			if err := v.Err(); err != nil {
 				t.Fatal(err)
 			}
-			err = Files([]*ast.File{f}, v, &Config{Trace: false})
+			err = Files([]*ast.File{f}, v, &Config{Keep: tc.keep})
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -274,6 +333,51 @@ foo: entry: {
 	}
 }
 
+func TestReport(t *testing.T) {
+	const in = `package foo
+
+#D: {a: *1 | int, ...}
+foo: [string]: {
+	b: 2
+	c: [...{d: 3}]
+}
+foo: x: #D & {
+	a: 1
+}
+foo: x: {
+	b: 2
+	c: [{d: 3}]
+}
+`
+	f, err := parser.ParseFile("in.cue", in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inst := build.NewContext().NewInstance("", nil)
+	if err := inst.AddSyntax(f); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	err = Instance(cuecontext.New(), inst, &Config{
+		Report: func(r Removal) {
+			got = append(got, fmt.Sprintf("%v %v %v", r.Pos, r.Path, r.By))
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"in.cue:9:2 foo.x.a definition|default",
+		"in.cue:12:2 foo.x.b pattern",
+		"in.cue:13:7 foo.x.c[0].d ellipsis",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
 const trace = false
 
 func TestData(t *testing.T) {
@@ -0,0 +1,125 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vet
+
+import (
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/token"
+)
+
+// A Deprecation reports the use of a field or definition that is marked
+// with a deprecated attribute:
+//
+//	oldName: string @deprecated("use name instead")
+type Deprecation struct {
+	// Path is the path of the value that uses the deprecated field.
+	Path cue.Path
+
+	// Target is the path of the deprecated field.
+	Target cue.Path
+
+	// Message is the first argument of the attribute, if any.
+	Message string
+
+	Pos token.Pos
+}
+
+func (d *Deprecation) String() string {
+	msg := fmt.Sprintf("%v is deprecated", d.Target)
+	if d.Message != "" {
+		msg += ": " + d.Message
+	}
+	if d.Path.String() == d.Target.String() {
+		return msg
+	}
+	return fmt.Sprintf("%v: %s", d.Path, msg)
+}
+
+// Deprecations reports all uses of deprecated fields and definitions within
+// v. A field is used if it is set to a concrete value or if it is referred
+// to by another value. Declaring a deprecated field is not a use.
+func Deprecations(v cue.Value) []*Deprecation {
+	var ds []*Deprecation
+	seen := map[string]bool{}
+	add := func(w, target cue.Value, msg string) {
+		d := &Deprecation{
+			Path:    w.Path(),
+			Target:  target.Path(),
+			Message: msg,
+			Pos:     w.Pos(),
+		}
+		if k := d.String(); !seen[k] {
+			seen[k] = true
+			ds = append(ds, d)
+		}
+	}
+
+	var refs func(w, x cue.Value)
+	refs = func(w, x cue.Value) {
+		switch op, a := x.Expr(); op {
+		case cue.AndOp, cue.OrOp:
+			for _, x := range a {
+				refs(w, x)
+			}
+			return
+		}
+		root, path := x.ReferencePath()
+		if !root.Exists() || len(path.Selectors()) == 0 {
+			return
+		}
+		if msg, ok := deprecated(root.LookupPath(path)); ok {
+			add(w, root.LookupPath(path), msg)
+		}
+	}
+
+	var walk func(w cue.Value)
+	walk = func(w cue.Value) {
+		refs(w, w)
+		iter, err := w.Fields(cue.Definitions(true), cue.Optional(true))
+		if err == nil {
+			for iter.Next() {
+				x := iter.Value()
+				if msg, ok := deprecated(x); ok && !iter.IsOptional() &&
+					!iter.IsDefinition() && x.IsConcrete() {
+					add(x, x, msg)
+				}
+				walk(x)
+			}
+			return
+		}
+		if list, err := w.List(); err == nil {
+			for list.Next() {
+				walk(list.Value())
+			}
+		}
+	}
+	walk(v)
+	return ds
+}
+
+// deprecated reports whether v is marked as deprecated and the message of
+// the attribute.
+func deprecated(v cue.Value) (msg string, ok bool) {
+	a := v.Attribute("deprecated")
+	if a.Err() != nil {
+		return "", false
+	}
+	if a.NumArgs() > 0 {
+		msg, _ = a.String(0)
+	}
+	return msg, true
+}
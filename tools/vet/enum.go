@@ -0,0 +1,173 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vet
+
+import (
+	"fmt"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+)
+
+// Enums checks the enum declarations and exhaustive mappings within v.
+//
+// An enum is a disjunction of concrete scalars. A field may be declared to
+// be an enum with an enum attribute, in which case it is an error if its
+// value is not an enum:
+//
+//	#Color: "red" | "green" | "blue" @enum()
+//
+// A struct that maps each member of an enum to a value may be marked with
+// an exhaustive attribute. It is an error if the struct does not have a
+// regular field for each member of the enum, or if it has a regular field
+// that is not a member. The argument of the attribute is the path of the
+// enum relative to v. It may be omitted if the struct has a pattern
+// constraint whose pattern is an enum:
+//
+//	hex: {
+//		[#Color]: string
+//
+//		red:   "#f00"
+//		green: "#0f0"
+//		blue:  "#00f"
+//	} @exhaustive()
+//
+//	names: {red: "Red", green: "Green", blue: "Blue"} @exhaustive(#Color)
+//
+// A member that is not a string matches the field with the label of its
+// CUE representation, such as 1 or true. An attribute only applies to the
+// field on which it is declared, not to values that refer to this field.
+func Enums(v cue.Value) []errors.Error {
+	c := &enumChecker{root: v}
+	c.walk(v)
+	return c.errs
+}
+
+type enumChecker struct {
+	root cue.Value
+	errs []errors.Error
+}
+
+func (c *enumChecker) walk(v cue.Value) {
+	iter, err := v.Fields(cue.Definitions(true), cue.Optional(true))
+	if err == nil {
+		for iter.Next() {
+			x := iter.Value()
+			if _, ok := attribute(x, "enum"); ok {
+				if _, err := x.EnumValues(); err != nil {
+					c.errs = append(c.errs, errors.Wrapf(err, x.Pos(), "invalid enum"))
+				}
+			}
+			if name, ok := attribute(x, "exhaustive"); ok {
+				c.exhaustive(x, name)
+			}
+			c.walk(x)
+		}
+		return
+	}
+	if list, err := v.List(); err == nil {
+		for list.Next() {
+			c.walk(list.Value())
+		}
+	}
+}
+
+// exhaustive checks that struct v has a field for each member of the enum
+// at the given path, or of the enum of its pattern constraint if the path
+// is empty.
+func (c *enumChecker) exhaustive(v cue.Value, name string) {
+	if v.IncompleteKind() != cue.StructKind {
+		c.errs = append(c.errs, errors.Newf(v.Pos(),
+			"%v: exhaustive attribute on value of type %v", v.Path(), v.IncompleteKind()))
+		return
+	}
+
+	var enum cue.Value
+	if name != "" {
+		enum = c.root.LookupPath(cue.ParsePath(name))
+		if !enum.Exists() {
+			c.errs = append(c.errs, errors.Newf(v.Pos(),
+				"%v: enum %s not found", v.Path(), name))
+			return
+		}
+	} else {
+		patterns, _ := v.PatternConstraints()
+		for _, p := range patterns {
+			if _, err := p.Pattern.EnumValues(); err == nil {
+				enum = p.Pattern
+				break
+			}
+		}
+		if !enum.Exists() {
+			c.errs = append(c.errs, errors.Newf(v.Pos(),
+				"%v: cannot determine enum; specify it as in @exhaustive(#Enum)", v.Path()))
+			return
+		}
+		name = fmt.Sprint(enum)
+		if root, p := enum.ReferencePath(); root.Exists() && len(p.Selectors()) > 0 {
+			name = p.String()
+		}
+	}
+
+	members, err := enum.EnumValues()
+	if err != nil {
+		c.errs = append(c.errs, errors.Wrapf(err, v.Pos(), "invalid enum"))
+		return
+	}
+
+	labels := map[string]bool{}
+	iter, _ := v.Fields()
+	for iter.Next() {
+		labels[iter.Label()] = true
+	}
+
+	var missing []string
+	for _, m := range members {
+		label := fmt.Sprint(m)
+		if s, err := m.String(); err == nil {
+			label = s
+		}
+		if labels[label] {
+			delete(labels, label)
+			continue
+		}
+		missing = append(missing, fmt.Sprint(m))
+	}
+	if len(missing) > 0 {
+		c.errs = append(c.errs, errors.Newf(v.Pos(),
+			"%v: missing cases for enum %s: %s", v.Path(), name, strings.Join(missing, ", ")))
+	}
+
+	for iter, _ := v.Fields(); iter.Next(); {
+		if labels[iter.Label()] {
+			x := iter.Value()
+			c.errs = append(c.errs, errors.Newf(x.Pos(),
+				"%v: not a member of enum %s", x.Path(), name))
+		}
+	}
+}
+
+// attribute reports the contents of the field attribute of v with the given
+// name. The contents of the attributes checked here are paths, which are not
+// valid attribute arguments if they start with a #.
+func attribute(v cue.Value, name string) (contents string, ok bool) {
+	for _, a := range v.Attributes(cue.FieldAttr) {
+		if a.Name() == name {
+			return strings.TrimSpace(a.Contents()), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,193 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vet
+
+import (
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/cue/token"
+)
+
+// Examples checks the examples declared in files against the values they
+// document, where v is the value built from these files. It reports an error
+// for each example that is not an instance of the value it documents.
+//
+// The contents of an @example attribute is a CUE expression. A field
+// attribute is checked against its field and a declaration attribute against
+// the struct in which it is declared:
+//
+//	#Service: {
+//		@example({name: "web", port: 80})
+//
+//		name: string
+//		port: int & >0 @example(8080)
+//	}
+//
+// A hidden field whose name starts with _example_ is checked against the
+// definition in which it is declared:
+//
+//	#Service: {
+//		name: string
+//		port: int & >0
+//
+//		_example_web: {name: "web", port: 80}
+//	}
+//
+// Examples need not be concrete. Examples within lists, comprehensions, and
+// pattern constraints are not checked.
+func Examples(v cue.Value, files ...*ast.File) []errors.Error {
+	c := &exampleChecker{root: v}
+	for _, f := range files {
+		c.decls(nil, f.Decls)
+	}
+	return c.errs
+}
+
+type exampleChecker struct {
+	root cue.Value
+	errs []errors.Error
+}
+
+func (c *exampleChecker) decls(path []string, decls []ast.Decl) {
+	for _, d := range decls {
+		switch x := d.(type) {
+		case *ast.Attribute:
+			if key, body := x.Split(); key == "example" {
+				c.checkAttr(path, x.Pos(), body)
+			}
+
+		case *ast.Field:
+			c.field(path, x)
+
+		case *ast.EmbedDecl:
+			c.expr(path, x.Expr)
+		}
+	}
+}
+
+func (c *exampleChecker) field(path []string, f *ast.Field) {
+	name, _, err := ast.LabelName(f.Label)
+	if err != nil {
+		return
+	}
+	if _, ok := f.Label.(*ast.Ident); !ok {
+		name = cue.Str(name).String()
+	} else if strings.HasPrefix(name, "_") {
+		n := len(path)
+		if strings.HasPrefix(name, "_example_") && n > 0 &&
+			strings.HasPrefix(path[n-1], "#") {
+			c.checkField(path, name, f.Pos())
+		}
+		return
+	}
+	p := append(path[:len(path):len(path)], name)
+	for _, a := range f.Attrs {
+		if key, body := a.Split(); key == "example" {
+			c.checkAttr(p, a.Pos(), body)
+		}
+	}
+	c.expr(p, f.Value)
+}
+
+func (c *exampleChecker) expr(path []string, x ast.Expr) {
+	switch x := x.(type) {
+	case *ast.StructLit:
+		c.decls(path, x.Elts)
+
+	case *ast.BinaryExpr:
+		if x.Op == token.AND {
+			c.expr(path, x.X)
+			c.expr(path, x.Y)
+		}
+
+	case *ast.ParenExpr:
+		c.expr(path, x.X)
+	}
+}
+
+func (c *exampleChecker) checkAttr(path []string, pos token.Pos, src string) {
+	v, ok := lookup(c.root, path)
+	if !ok {
+		return
+	}
+	expr, err := parser.ParseExpr("@example", src)
+	if err != nil {
+		c.errs = append(c.errs, errors.Wrapf(err, pos,
+			"invalid example for %s", pathString(path)))
+		return
+	}
+	// Report errors within the example at the position of the attribute.
+	ast.Walk(expr, func(n ast.Node) bool {
+		ast.SetPos(n, pos)
+		return true
+	}, nil)
+	c.check(pos, v, v.Context().BuildExpr(expr))
+}
+
+func (c *exampleChecker) checkField(path []string, name string, pos token.Pos) {
+	v, ok := lookup(c.root, path)
+	if !ok {
+		return
+	}
+	x, ok := lookup(v, []string{name})
+	if !ok {
+		return
+	}
+	c.check(pos, v, x)
+}
+
+func (c *exampleChecker) check(pos token.Pos, v, x cue.Value) {
+	if err := v.Unify(x).Validate(); err != nil {
+		c.errs = append(c.errs, errors.Wrapf(err, pos, "invalid example"))
+	}
+}
+
+// lookup returns the field of v at the given path, where each element is the
+// string representation of a selector. Unlike LookupPath, it also finds
+// optional and hidden fields.
+func lookup(v cue.Value, path []string) (cue.Value, bool) {
+	for _, name := range path {
+		iter, err := v.Fields(
+			cue.Optional(true),
+			cue.Definitions(true),
+			cue.Hidden(true),
+		)
+		if err != nil {
+			return v, false
+		}
+		found := false
+		for iter.Next() {
+			if iter.Selector().String() == name {
+				v, found = iter.Value(), true
+				break
+			}
+		}
+		if !found {
+			return v, false
+		}
+	}
+	return v, true
+}
+
+func pathString(path []string) string {
+	if len(path) == 0 {
+		return "value"
+	}
+	return strings.Join(path, ".")
+}
@@ -0,0 +1,214 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vet validates CUE values with the same semantics as the cue vet
+// command.
+//
+// Unlike Value.Validate, which returns a single, combined error, Validate
+// reports each violation separately, which makes it suitable for services,
+// such as admission webhooks, that need to report all problems with an input
+// in a structured form.
+package vet
+
+import (
+	"fmt"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/errors"
+)
+
+// A Mode determines how a value is validated.
+type Mode int
+
+const (
+	// Schema validates a CUE configuration, as cue vet does for packages.
+	// Values need not be concrete unless requested by Config.
+	Schema Mode = iota
+
+	// Data validates data that has been unified with a schema, as cue vet
+	// does for data files. All regular fields must be concrete.
+	Data
+)
+
+// Config configures validation.
+type Config struct {
+	Mode Mode
+
+	// Concrete requires all regular fields to be concrete in Schema mode. It
+	// has no effect in Data mode, where this is always required.
+	Concrete bool
+
+	// ConcretePaths lists paths, relative to the validated value, of values
+	// that must be concrete even if this is not required of the value as a
+	// whole. A path that does not exist is reported as a violation.
+	ConcretePaths []cue.Path
+
+	// IncompletePaths lists paths, relative to the validated value, of
+	// values that need not be concrete even if this is required of the value
+	// as a whole. Other errors within these values are still reported.
+	IncompletePaths []cue.Path
+}
+
+// A Result holds the outcome of a validation.
+type Result struct {
+	// Errors holds all violations in the order they were found.
+	Errors []errors.Error
+
+	// Incomplete reports whether the validated value has values that are
+	// not concrete but that were not required to be.
+	Incomplete bool
+}
+
+// Err returns all violations combined in a single error or nil if there are
+// none.
+func (r *Result) Err() error {
+	var err errors.Error
+	for _, e := range r.Errors {
+		err = errors.Append(err, e)
+	}
+	if err == nil {
+		return nil
+	}
+	return err
+}
+
+// Validate validates v and reports all violations.
+func Validate(v cue.Value, cfg *Config) *Result {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	opts := []cue.Option{
+		cue.Attributes(true),
+		cue.Definitions(true),
+		cue.Hidden(true),
+	}
+	concrete := cfg.Concrete
+	if cfg.Mode == Data {
+		// Data files are checked against the data model only.
+		opts = nil
+		concrete = true
+	}
+
+	r := &Result{}
+	seen := map[string]bool{}
+	add := func(err error, filter func(e errors.Error) bool) {
+		for _, e := range errors.Errors(err) {
+			if filter != nil && !filter(e) {
+				continue
+			}
+			if k := key(e); !seen[k] {
+				seen[k] = true
+				r.Errors = append(r.Errors, e)
+			}
+		}
+	}
+
+	add(v.Validate(append(opts, cue.Concrete(false))...), nil)
+
+	incomplete := paths(v, cfg.IncompletePaths)
+	errs := concreteErrors(v)
+	if concrete {
+		add(errs, func(e errors.Error) bool {
+			return !hasPrefix(e.Path(), incomplete)
+		})
+	} else {
+		for _, p := range cfg.ConcretePaths {
+			w := v.LookupPath(p)
+			if !w.Exists() {
+				r.Errors = append(r.Errors, errors.Newf(v.Pos(),
+					"required value %v is missing", p))
+				continue
+			}
+			add(concreteErrors(w), nil)
+		}
+	}
+
+	// Any errors for concrete values that were not reported are due to
+	// incomplete values.
+	for _, e := range errors.Errors(errs) {
+		if !seen[key(e)] {
+			r.Incomplete = true
+			break
+		}
+	}
+	return r
+}
+
+// concreteErrors reports errors for all regular values of v that are not
+// concrete. Values are validated individually, as Validate only reports
+// incomplete values if there are no other errors.
+func concreteErrors(v cue.Value) (errs errors.Error) {
+	var walk func(v cue.Value)
+	walk = func(v cue.Value) {
+		// Kind reports an error for structs and lists with erroneous
+		// elements, so the structure is determined by iterating instead.
+		if iter, err := v.Fields(); err == nil {
+			for iter.Next() {
+				walk(iter.Value())
+			}
+			return
+		}
+		if list, err := v.List(); err == nil {
+			for list.Next() {
+				walk(list.Value())
+			}
+			return
+		}
+		if err := v.Validate(cue.Concrete(true)); err != nil {
+			errs = errors.Append(errs, errors.Promote(err, ""))
+		}
+	}
+	walk(v)
+	return errs
+}
+
+// key identifies an error by its path and message.
+func key(e errors.Error) string {
+	format, args := e.Msg()
+	return fmt.Sprintf("%q "+format, append([]interface{}{e.Path()}, args...)...)
+}
+
+// paths converts paths relative to v into the selectors reported by errors.
+func paths(v cue.Value, a []cue.Path) [][]string {
+	var root []string
+	for _, s := range v.Path().Selectors() {
+		root = append(root, s.String())
+	}
+	var paths [][]string
+	for _, p := range a {
+		x := append([]string(nil), root...)
+		for _, s := range p.Selectors() {
+			x = append(x, s.String())
+		}
+		paths = append(paths, x)
+	}
+	return paths
+}
+
+// hasPrefix reports whether path is within any of the given paths.
+func hasPrefix(path []string, prefixes [][]string) bool {
+outer:
+	for _, p := range prefixes {
+		if len(p) > len(path) {
+			continue
+		}
+		for i, s := range p {
+			if path[i] != s {
+				continue outer
+			}
+		}
+		return true
+	}
+	return false
+}
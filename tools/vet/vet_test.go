@@ -0,0 +1,304 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vet
+
+import (
+	"strings"
+	"testing"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/parser"
+)
+
+func TestValidate(t *testing.T) {
+	const schema = `
+#Port: {
+	name?: string
+	port:  int & >0 & <65536
+}
+spec: {
+	replicas: int & <=10
+	image:    string
+	ports: [...#Port]
+}
+`
+	testCases := []struct {
+		name       string
+		data       string
+		cfg        Config
+		want       string
+		incomplete bool
+	}{{
+		name:       "incomplete allowed in schema mode",
+		data:       `spec: replicas: 3`,
+		want:       ``,
+		incomplete: true,
+	}, {
+		name: "all errors",
+		data: `spec: {
+			replicas: 30
+			ports: [{port: 0}, {port: 80}, {port: 70000}]
+		}`,
+		want: `spec.replicas: invalid value 30 (out of bound <=10)
+spec.ports.0.port: invalid value 0 (out of bound >0)
+spec.ports.2.port: invalid value 70000 (out of bound <65536)`,
+		incomplete: true,
+	}, {
+		name: "concrete",
+		data: `spec: replicas: 3`,
+		cfg:  Config{Concrete: true},
+		want: `spec.image: incomplete value string`,
+	}, {
+		name: "data mode",
+		data: `spec: {replicas: 3, ports: [{port: 80}]}`,
+		cfg:  Config{Mode: Data},
+		want: `spec.image: incomplete value string`,
+	}, {
+		name: "concrete paths",
+		data: `spec: replicas: int`,
+		cfg: Config{ConcretePaths: []cue.Path{
+			cue.ParsePath("spec.replicas"),
+			cue.ParsePath("spec.missing"),
+		}},
+		want: `spec.replicas: incomplete value <=10 & int
+required value spec.missing is missing`,
+		incomplete: true,
+	}, {
+		name: "incomplete paths",
+		data: `spec: {replicas: 30, ports: [{port: int}]}`,
+		cfg: Config{
+			Mode:            Data,
+			IncompletePaths: []cue.Path{cue.ParsePath("spec.ports")},
+		},
+		want: `spec.replicas: invalid value 30 (out of bound <=10)
+spec.image: incomplete value string`,
+		incomplete: true,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := cuecontext.New()
+			v := ctx.CompileString(schema).Unify(ctx.CompileString(tc.data))
+
+			r := Validate(v, &tc.cfg)
+
+			var errs []string
+			for _, e := range r.Errors {
+				errs = append(errs, e.Error())
+			}
+			if got := strings.Join(errs, "\n"); got != tc.want {
+				t.Errorf("errors:\ngot:\n%s\nwant:\n%s", got, tc.want)
+			}
+			if r.Incomplete != tc.incomplete {
+				t.Errorf("incomplete: got %v; want %v", r.Incomplete, tc.incomplete)
+			}
+			if (r.Err() != nil) != (tc.want != "") {
+				t.Errorf("Err: got %v", r.Err())
+			}
+		})
+	}
+}
+
+func TestDeprecations(t *testing.T) {
+	const schema = `
+#OldPort: {port: int} @deprecated("use #Port")
+#Port: {port: int}
+#Spec: {
+	name:     string
+	oldName?: string @deprecated("use name")
+	mode:     *"a" | "b" @deprecated()
+}
+`
+	testCases := []struct {
+		name string
+		data string
+		want string
+	}{{
+		name: "declaration only",
+		data: `spec: #Spec & {name: "x"}`,
+		want: ``,
+	}, {
+		name: "set field",
+		data: `spec: #Spec & {name: "x", oldName: "y", mode: "b"}`,
+		want: `spec.oldName is deprecated: use name
+spec.mode is deprecated`,
+	}, {
+		name: "reference",
+		data: `
+spec: #Spec & {name: "x"}
+port: #OldPort & {port: 80}
+mode: spec.mode`,
+		want: `port: #OldPort is deprecated: use #Port
+mode: spec.mode is deprecated`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := cuecontext.New()
+			v := ctx.CompileString(schema + tc.data)
+
+			var got []string
+			for _, d := range Deprecations(v) {
+				got = append(got, d.String())
+			}
+			if s := strings.Join(got, "\n"); s != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", s, tc.want)
+			}
+		})
+	}
+}
+
+func TestExamples(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		want string
+	}{{
+		name: "valid",
+		in: `
+#Service: {
+	@example({name: "web", port: 80})
+	@example({name: "db"})
+
+	name:  string
+	port?: int & >0 @example(8080)
+
+	_example_web: {name: "web", port: 443}
+}
+// Examples of #Service do not apply to values that use it.
+service: #Service & {name: "api"}
+labels: "x-y": string @example("z")
+`,
+	}, {
+		name: "invalid attributes",
+		in: `
+#Service: {
+	@example({name: "web", extra: 1})
+
+	name:  string
+	port?: int & >0 @example(0)
+	sub: {
+		@example({a: "x"})
+		a: int
+	}
+	tags: [...string] @example(["a", 1])
+	bad: int @example(1 +)
+}
+labels: "x-y": int @example("z")
+`,
+		want: `invalid example: #Service: field not allowed: extra
+invalid example: #Service.port: invalid value 0 (out of bound >0)
+invalid example: #Service.sub.a: conflicting values int and "x" (mismatched types int and string)
+invalid example: #Service.tags.1: conflicting values 1 and string (mismatched types int and string)
+invalid example for #Service.bad: expected operand, found 'EOF'
+invalid example: labels."x-y": conflicting values int and "z" (mismatched types int and string)`,
+	}, {
+		name: "invalid fields",
+		in: `
+#Service: #Base & {
+	port: int
+	_example_web: {name: "web", port: "80"}
+}
+#Base: {name: string, ...}
+notDef: {
+	a: int
+	_example_x: {a: "x"}
+}
+`,
+		want: `invalid example: #Service.port: conflicting values int and "80" (mismatched types int and string)`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := parser.ParseFile("in.cue", tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := cuecontext.New().BuildFile(f)
+
+			var got []string
+			for _, err := range Examples(v, f) {
+				got = append(got, err.Error())
+			}
+			if s := strings.Join(got, "\n"); s != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", s, tc.want)
+			}
+		})
+	}
+}
+
+func TestEnums(t *testing.T) {
+	const schema = `
+#Color: "red" | "green" | "blue" @enum()
+#Level: *1 | 2 | 3 @enum()
+`
+	testCases := []struct {
+		name string
+		in   string
+		want string
+	}{{
+		name: "exhaustive",
+		in: `
+hex: {
+	[#Color]: string
+
+	red:   "#f00"
+	green: "#0f0"
+	blue:  "#00f"
+} @exhaustive()
+names: {red: "Red", green: "Green", blue: "Blue"} @exhaustive(#Color)
+levels: {"1": "low", "2": "mid", "3": "high"} @exhaustive(#Level)
+`,
+	}, {
+		name: "missing and unknown cases",
+		in: `
+hex: {
+	[#Color]: string
+
+	red: "#f00"
+} @exhaustive()
+names: {red: "Red", green: "Green", blue: "Blue", pink: "Pink"} @exhaustive(#Color)
+levels: {"1": "low"} @exhaustive(#Level)
+`,
+		want: `hex: missing cases for enum #Color: "green", "blue"
+names.pink: not a member of enum #Color
+levels: missing cases for enum #Level: 2, 3`,
+	}, {
+		name: "invalid",
+		in: `
+#Bad: "a" | string @enum()
+a: {} @exhaustive(#Missing)
+b: {} @exhaustive()
+c: {} @exhaustive(#Bad)
+d: 1 @exhaustive(#Color)
+`,
+		want: `invalid enum: #Bad: value "a" | string is not a disjunction of concrete scalars
+a: enum #Missing not found
+b: cannot determine enum; specify it as in @exhaustive(#Enum)
+invalid enum: #Bad: value "a" | string is not a disjunction of concrete scalars
+d: exhaustive attribute on value of type int`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := cuecontext.New().CompileString(schema + tc.in)
+
+			var got []string
+			for _, err := range Enums(v) {
+				got = append(got, err.Error())
+			}
+			if s := strings.Join(got, "\n"); s != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", s, tc.want)
+			}
+		})
+	}
+}
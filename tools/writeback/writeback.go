@@ -0,0 +1,428 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package writeback writes evaluated values back into the source files from
+// which they originate.
+//
+// For each field defined in a source file, the value at the corresponding path
+// in the evaluated result is written back in place of the field's value if it
+// is concrete and differs from the value in the source. Only literals and
+// placeholders, such as `int` or `>0`, are replaced: expressions that refer to
+// other values, like `len(hosts)`, are preserved, as are values that are only
+// concrete by virtue of a default. The formatting and comments of the remainder
+// of the file are untouched.
+//
+// CUE, JSON, and YAML source files are supported. YAML block scalars and
+// values within YAML flow collections are not updated.
+package writeback
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/format"
+	"cuelang.org/go/cue/token"
+	"cuelang.org/go/internal/encoding/yaml"
+)
+
+// Config configures an update.
+type Config struct {
+	// ReadFile reads the contents of the named source file. It defaults to
+	// ioutil.ReadFile.
+	ReadFile func(filename string) ([]byte, error)
+}
+
+// An Edit describes the replacement of a single value in a source file.
+type Edit struct {
+	// Path is the location of the value in the evaluated result.
+	Path cue.Path
+
+	// Start and End are the byte offsets of the replaced text.
+	Start, End int
+
+	Old, New string
+}
+
+// A File holds the updated contents of a source file.
+type File struct {
+	Filename string
+	Data     []byte
+	Edits    []Edit
+}
+
+// Update computes the updated contents of the source files of the values
+// defined in files with the corresponding concrete values of v. The source
+// file of a value is determined by its position, which means that files
+// converted from other formats, such as YAML, update the original file.
+//
+// Update only reports files that change. The files are not written.
+func Update(v cue.Value, files []*ast.File, cfg *Config) ([]*File, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	u := &updater{
+		cfg:   cfg,
+		v:     v,
+		src:   map[string][]byte{},
+		edits: map[string][]Edit{},
+	}
+	for _, f := range files {
+		u.decls(nil, f.Decls)
+	}
+	if u.errs != nil {
+		return nil, u.errs
+	}
+
+	var a []*File
+	for name, edits := range u.edits {
+		sort.Slice(edits, func(i, j int) bool {
+			return edits[i].Start < edits[j].Start
+		})
+		a = append(a, &File{
+			Filename: name,
+			Data:     apply(u.src[name], edits),
+			Edits:    edits,
+		})
+	}
+	sort.Slice(a, func(i, j int) bool { return a[i].Filename < a[j].Filename })
+	return a, nil
+}
+
+func apply(src []byte, edits []Edit) []byte {
+	var b bytes.Buffer
+	last := 0
+	for _, e := range edits {
+		if e.Start < last {
+			continue // overlapping edit
+		}
+		b.Write(src[last:e.Start])
+		b.WriteString(e.New)
+		last = e.End
+	}
+	b.Write(src[last:])
+	return b.Bytes()
+}
+
+type updater struct {
+	cfg   *Config
+	v     cue.Value
+	src   map[string][]byte
+	edits map[string][]Edit
+	errs  errors.Error
+}
+
+func (u *updater) decls(path []cue.Selector, decls []ast.Decl) {
+	for _, d := range decls {
+		switch x := d.(type) {
+		case *ast.Field:
+			if x.Optional != token.NoPos {
+				continue
+			}
+			switch x.Label.(type) {
+			case *ast.Ident, *ast.BasicLit:
+			default:
+				continue // pattern constraint or interpolation
+			}
+			sel := cue.Label(x.Label)
+			if sel.PkgPath() != "" {
+				continue
+			}
+			u.expr(append(path, sel), x.Value)
+
+		case *ast.EmbedDecl:
+			u.expr(path, x.Expr)
+		}
+	}
+}
+
+func (u *updater) expr(path []cue.Selector, x ast.Expr) {
+	switch x := x.(type) {
+	case *ast.StructLit:
+		u.decls(path, x.Elts)
+
+	case *ast.ListLit:
+		for i, e := range x.Elts {
+			if _, ok := e.(*ast.Ellipsis); ok {
+				break
+			}
+			u.expr(append(path, cue.Index(i)), e)
+		}
+
+	default:
+		u.leaf(path, x)
+	}
+}
+
+func (u *updater) leaf(path []cue.Selector, x ast.Expr) {
+	pos := x.Pos()
+	filename := pos.Filename()
+	if !pos.IsValid() || filename == "" || filename == "-" {
+		return
+	}
+
+	p := cue.MakePath(append([]cue.Selector{}, path...)...)
+	v := u.v.LookupPath(p)
+	if !v.Exists() || !v.IsConcrete() {
+		return
+	}
+	switch v.Kind() {
+	case cue.StructKind, cue.ListKind, cue.BottomKind:
+		return
+	}
+	switch w := v.Context().BuildExpr(x); {
+	case isLiteral(x):
+		if w.Equals(v) {
+			return
+		}
+	case w.Err() != nil, w.IsConcrete():
+		// The value is computed from other values or constants.
+		return
+	}
+
+	src, err := u.source(filename)
+	if err != nil {
+		u.errs = errors.Append(u.errs, errors.Promote(err, "writeback"))
+		return
+	}
+
+	start := offset(src, pos)
+	if start < 0 || start >= len(src) {
+		return
+	}
+	end := offset(src, x.End())
+	var text string
+	switch filepath.Ext(filename) {
+	case ".yaml", ".yml":
+		if inYAMLFlow(src, start) {
+			return // unsupported literal
+		}
+		end = yamlScalarEnd(src, start)
+		text, err = encodeYAML(v)
+	case ".json", ".jsonl", ".ldjson":
+		text, err = encodeJSON(v)
+	default:
+		text, err = encodeCUE(v)
+	}
+	if end <= start || end > len(src) {
+		return // unsupported literal
+	}
+	if err != nil {
+		u.errs = errors.Append(u.errs, errors.Promote(err, "writeback"))
+		return
+	}
+	u.edits[filename] = append(u.edits[filename], Edit{
+		Path:  p,
+		Start: start,
+		End:   end,
+		Old:   string(src[start:end]),
+		New:   text,
+	})
+}
+
+func (u *updater) source(filename string) ([]byte, error) {
+	if b, ok := u.src[filename]; ok {
+		return b, nil
+	}
+	read := u.cfg.ReadFile
+	if read == nil {
+		read = ioutil.ReadFile
+	}
+	b, err := read(filename)
+	if err != nil {
+		return nil, err
+	}
+	u.src[filename] = b
+	return b, nil
+}
+
+// offset returns the offset of pos in src. Positions of values decoded from
+// YAML and JSON streams are relative to the value within the stream, so the
+// offset is computed from the line and column of pos.
+func offset(src []byte, pos token.Pos) int {
+	line, col := pos.Line(), pos.Column()
+	offset := 0
+	for ; line > 1; line-- {
+		i := bytes.IndexByte(src[offset:], '\n')
+		if i < 0 {
+			return -1
+		}
+		offset += i + 1
+	}
+	return offset + col - 1
+}
+
+// isLiteral reports whether x is a literal scalar value.
+func isLiteral(x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.UnaryExpr:
+		_, ok := x.X.(*ast.BasicLit)
+		return ok && (x.Op == token.SUB || x.Op == token.ADD)
+	case *ast.Ident:
+		switch x.Name {
+		case "true", "false", "null":
+			return true
+		}
+	}
+	return false
+}
+
+func encodeCUE(v cue.Value) (string, error) {
+	b, err := format.Node(v.Syntax(cue.Final()))
+	return string(b), err
+}
+
+func encodeJSON(v cue.Value) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func encodeYAML(v cue.Value) (string, error) {
+	b, err := yaml.Encode(v.Syntax(cue.Final()))
+	if err != nil {
+		return "", err
+	}
+	s := strings.TrimSuffix(string(b), "\n")
+	if strings.Contains(s, "\n") {
+		// A JSON scalar is a valid single-line YAML flow scalar.
+		return encodeJSON(v)
+	}
+	return s, nil
+}
+
+// inYAMLFlow reports whether offset start in src lies within a YAML flow
+// collection, such as [1, 2] or {a: 1}.
+func inYAMLFlow(src []byte, start int) bool {
+	depth := 0
+	for i := 0; i < start; i++ {
+		// Indicators only have a special meaning at the start of a token.
+		atToken := i == 0 || strings.IndexByte(" \t\r\n,[{", src[i-1]) >= 0
+		switch c := src[i]; {
+		case c == '#' && atToken:
+			for i < start && src[i] != '\n' {
+				i++
+			}
+
+		case (c == '"' || c == '\'') && atToken:
+			for i++; i < start; i++ {
+				if c == '"' && src[i] == '\\' || c == '\'' && string(src[i:i+2]) == "''" {
+					i++
+				} else if src[i] == c {
+					break
+				}
+			}
+
+		case (c == '[' || c == '{') && (atToken || depth > 0):
+			depth++
+
+		case (c == ']' || c == '}') && depth > 0:
+			depth--
+
+		case (c == '|' || c == '>') && atToken && depth == 0:
+			// Skip the lines of a block scalar, which are indented more
+			// than the line holding the indicator.
+			lineStart := bytes.LastIndexByte(src[:i], '\n') + 1
+			indent := yamlIndent(src[lineStart:])
+			for {
+				j := bytes.IndexByte(src[i:start], '\n')
+				if j < 0 {
+					i = start
+					break
+				}
+				i += j
+				next := src[i+1:]
+				if yamlIndent(next) <= indent && !isBlankLine(next) {
+					break
+				}
+				i++
+			}
+		}
+	}
+	return depth > 0
+}
+
+func yamlIndent(line []byte) int {
+	n := 0
+	for n < len(line) && line[n] == ' ' {
+		n++
+	}
+	return n
+}
+
+func isBlankLine(b []byte) bool {
+	if i := bytes.IndexByte(b, '\n'); i >= 0 {
+		b = b[:i]
+	}
+	return len(bytes.TrimSpace(b)) == 0
+}
+
+// yamlScalarEnd reports the end offset of the YAML scalar starting at offset
+// start in src or -1 if the scalar is not supported.
+func yamlScalarEnd(src []byte, start int) int {
+	if start < 0 || start >= len(src) {
+		return -1
+	}
+	switch src[start] {
+	case '"':
+		for i := start + 1; i < len(src); i++ {
+			switch src[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+		return -1
+
+	case '\'':
+		for i := start + 1; i < len(src); i++ {
+			if src[i] != '\'' {
+				continue
+			}
+			if i+1 < len(src) && src[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+		return -1
+
+	case '|', '>':
+		return -1 // block scalar
+	}
+
+	// Plain scalar.
+	end := start
+	for i := start; i < len(src); i++ {
+		c := src[i]
+		if c == '\n' || c == '\r' {
+			break
+		}
+		if c == '#' && i > start && (src[i-1] == ' ' || src[i-1] == '\t') {
+			break
+		}
+		if c != ' ' && c != '\t' {
+			end = i + 1
+		}
+	}
+	return end
+}
@@ -0,0 +1,183 @@
+// Copyright 2021 CUE Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package writeback
+
+import (
+	"os"
+	"testing"
+
+	"cuelang.org/go/cue/ast"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/parser"
+	"cuelang.org/go/encoding/json"
+	"cuelang.org/go/encoding/yaml"
+)
+
+func TestUpdate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		filename string
+		in       string
+		result   string
+		want     string
+	}{{
+		name:     "cue",
+		filename: "data.cue",
+		in: `// Config
+replicas: 1 // current
+name:     "foo"
+ports: [80, 8080]
+size:  int
+max:   size * 2
+def:   *1 | int
+`,
+		result: `
+replicas: 3
+name:     "foo"
+ports: [80, 443]
+size:  4
+max:   8
+def:   *1 | int
+`,
+		want: `// Config
+replicas: 3 // current
+name:     "foo"
+ports: [80, 443]
+size:  4
+max:   size * 2
+def:   *1 | int
+`,
+	}, {
+		name:     "unchanged",
+		filename: "data.cue",
+		in:       `a: 1, b: "x"`,
+		result:   `a: 1`,
+		want:     ``,
+	}, {
+		name:     "json",
+		filename: "data.json",
+		in: `{
+    "a": 1,
+    "b": {"c": "x"}
+}`,
+		result: `b: c: "y"`,
+		want: `{
+    "a": 1,
+    "b": {"c": "y"}
+}`,
+	}, {
+		name:     "yaml",
+		filename: "data.yaml",
+		in: `# settings
+a: 1 # comment
+b: "quoted"
+c:
+- 1
+- 2
+d: plain text
+`,
+		result: `a: 2, b: "other", c: [1, 3], d: "new: text"`,
+		want: `# settings
+a: 2 # comment
+b: other
+c:
+- 1
+- 3
+d: 'new: text'
+`,
+	}, {
+		// Values within flow collections are not updated, also not if the
+		// collection spans multiple lines.
+		name:     "yaml flow",
+		filename: "data.yaml",
+		in: `a: [1, 2]
+b: {c: "x", d: 1}
+e: [
+  1,
+  {f: 2}
+]
+g: |
+  not [a flow
+h: 1 # [
+i: 'it''s ['
+j: 1
+`,
+		result: `a: [1, 3], b: {c: "y", d: 2}, e: [2, {f: 3}], h: 2, j: 2`,
+		want: `a: [1, 2]
+b: {c: "x", d: 1}
+e: [
+  1,
+  {f: 2}
+]
+g: |
+  not [a flow
+h: 2 # [
+i: 'it''s ['
+j: 2
+`,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var f *ast.File
+			var err error
+			switch tc.filename {
+			case "data.json":
+				var e ast.Expr
+				e, err = json.Extract(tc.filename, []byte(tc.in))
+				f = &ast.File{Filename: tc.filename}
+				if e != nil {
+					f.Decls = []ast.Decl{&ast.EmbedDecl{Expr: e}}
+				}
+			case "data.yaml":
+				f, err = yaml.Extract(tc.filename, tc.in)
+			default:
+				f, err = parser.ParseFile(tc.filename, tc.in, parser.ParseComments)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := cuecontext.New()
+			v := ctx.CompileString(tc.result)
+			if err := v.Err(); err != nil {
+				t.Fatal(err)
+			}
+
+			files, err := Update(v, []*ast.File{f}, &Config{
+				ReadFile: func(filename string) ([]byte, error) {
+					if filename != tc.filename {
+						return nil, os.ErrNotExist
+					}
+					return []byte(tc.in), nil
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := ""
+			switch len(files) {
+			case 0:
+			case 1:
+				got = string(files[0].Data)
+			default:
+				t.Fatalf("got %d files; want at most 1", len(files))
+			}
+			if got != tc.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tc.want)
+			}
+		})
+	}
+}